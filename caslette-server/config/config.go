@@ -1,9 +1,15 @@
 package config
 
 import (
+	"caslette-server/game"
+	"caslette-server/geo"
+	"caslette-server/websocket_v2"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
@@ -14,6 +20,115 @@ type Config struct {
 	DB        *gorm.DB
 	JWTSecret string
 	Port      string
+
+	// Game holds the gameplay defaults used when a table does not set its own
+	Game game.GameDefaults
+
+	// RatholeWindow is how long a departing stack sets the minimum buy-in
+	// at the same stakes; zero disables the rule
+	RatholeWindow time.Duration
+
+	// InterHandDelay is the pause between one hand ending and the next
+	// being dealt at an active table
+	InterHandDelay time.Duration
+
+	// TableApprovalBigBlind is the big blind above which new real-money
+	// tables wait for admin approval before they are listed; zero turns
+	// approval off
+	TableApprovalBigBlind int
+
+	// HouseAccountID is the user whose diamond account rake is credited
+	// to; when empty rake is still taken but credited nowhere
+	HouseAccountID string
+
+	// TableInvitationTTL is how long an invitation lets its user join a
+	// private table without the password
+	TableInvitationTTL time.Duration
+
+	// SeatReservationTTL is how long a seat picked from the lobby is held
+	// for the player to finish buying in
+	SeatReservationTTL time.Duration
+
+	// ReconnectGrace is how long a player whose connection drops keeps
+	// their seats and chips; zero frees them at once
+	ReconnectGrace time.Duration
+
+	// TableSnapshotInterval is how often open tables, including any hand in
+	// progress, are saved so they can be restored after a restart
+	TableSnapshotInterval time.Duration
+
+	// ShutdownTimeout is how long a shutdown waits for hands in progress to
+	// finish and WebSocket clients to be disconnected cleanly; tables still
+	// mid-hand are snapshotted to resume after the restart
+	ShutdownTimeout time.Duration
+
+	// AuditRetention is how long security audit entries are kept; zero
+	// keeps them forever
+	AuditRetention time.Duration
+
+	// LoginEventRetention is how long sign-in history is kept and
+	// ClosedAccountRetention how long a deleted account keeps its personal
+	// data; zero keeps either forever. Purges run every RetentionPurgeEvery.
+	LoginEventRetention    time.Duration
+	ClosedAccountRetention time.Duration
+	RetentionPurgeEvery    time.Duration
+
+	// HandHistoryRetention is how long completed hands are kept; zero keeps
+	// them forever
+	HandHistoryRetention time.Duration
+
+	// WSCompression controls permessage-deflate for WebSocket clients that
+	// opt in
+	WSCompression websocket_v2.CompressionPolicy
+
+	// WSResumeWindow is how long a dropped WebSocket connection's session
+	// can be resumed, re-entering its rooms and replaying missed messages;
+	// zero turns resuming off
+	WSResumeWindow time.Duration
+
+	// WSSessionPolicy is "multiple" to let users stay connected from several
+	// devices, or "single" to have a new login replace their other
+	// connections
+	WSSessionPolicy websocket_v2.SessionPolicy
+
+	// WSBackplane names what WebSocket hubs on several instances relay
+	// broadcasts and presence through: empty for a standalone server or
+	// "redis" for Redis pub/sub at WSRedis
+	WSBackplane string
+	WSRedis     websocket_v2.RedisConfig
+
+	// WSMessageSize limits inbound WebSocket messages and sets the size
+	// over which outbound ones are sent in chunks
+	WSMessageSize websocket_v2.MessageSizePolicy
+
+	// WSSendQueue bounds each WebSocket connection's outbound queue and
+	// decides whether a full one drops messages or disconnects the client
+	WSSendQueue websocket_v2.SendQueuePolicy
+
+	// WSHeartbeat sets how often WebSocket clients are pinged and how long
+	// one may stay silent before its connection is dropped, taking it out
+	// of rooms and seats
+	WSHeartbeat websocket_v2.HeartbeatPolicy
+
+	// WSLoadShedding sets when the WebSocket server counts as overloaded and
+	// which messages and connections it refuses with server_busy meanwhile
+	WSLoadShedding websocket_v2.ShedPolicy
+
+	// TrustedProxies lists the proxy addresses or CIDR ranges whose
+	// forwarding headers are believed; clients reaching the server from
+	// anywhere else are identified by their socket address
+	TrustedProxies []string
+
+	// RateLimitExempt maps the user IDs of system actors, such as the
+	// tournament controller, house bots and admin tools, to the actor their
+	// unlimited WebSocket traffic is counted as
+	RateLimitExempt map[string]string
+
+	// GeoRangesFile lists "cidr,region" lines used to detect client regions;
+	// when empty no region is detected and nothing is blocked
+	GeoRangesFile string
+	// GeoBlocked lists the regions each restricted action is refused in
+	GeoBlocked map[geo.Action][]string
 }
 
 func Load() *Config {
@@ -27,6 +142,92 @@ func Load() *Config {
 		Port:      getEnv("PORT", "8080"),
 	}
 
+	config.Game = loadGameDefaults()
+
+	ratholeWindow, err := time.ParseDuration(getEnv("RATHOLE_WINDOW", "2h"))
+	if err != nil {
+		log.Fatal("Invalid RATHOLE_WINDOW:", err)
+	}
+	config.RatholeWindow = ratholeWindow
+
+	interHandDelay, err := time.ParseDuration(getEnv("INTER_HAND_DELAY", "5s"))
+	if err != nil || interHandDelay < 0 {
+		log.Fatal("Invalid INTER_HAND_DELAY:", getEnv("INTER_HAND_DELAY", ""))
+	}
+	config.InterHandDelay = interHandDelay
+
+	config.TableApprovalBigBlind = getEnvInt("TABLE_APPROVAL_BIG_BLIND", 0)
+	if config.TableApprovalBigBlind < 0 {
+		log.Fatal("Invalid TABLE_APPROVAL_BIG_BLIND:", config.TableApprovalBigBlind)
+	}
+
+	config.HouseAccountID = getEnv("HOUSE_ACCOUNT_ID", "")
+
+	config.TableInvitationTTL = getEnvDuration("TABLE_INVITATION_TTL", game.DefaultInvitationTTL)
+	if config.TableInvitationTTL <= 0 {
+		log.Fatal("Invalid TABLE_INVITATION_TTL: must be positive")
+	}
+
+	config.SeatReservationTTL = getEnvDuration("SEAT_RESERVATION_TTL", game.DefaultSeatReservation)
+	if config.SeatReservationTTL <= 0 {
+		log.Fatal("Invalid SEAT_RESERVATION_TTL: must be positive")
+	}
+
+	config.ReconnectGrace = getEnvDuration("RECONNECT_GRACE", game.DefaultReconnectGrace)
+
+	config.TableSnapshotInterval = getEnvDuration("TABLE_SNAPSHOT_INTERVAL", game.DefaultSnapshotInterval)
+	if config.TableSnapshotInterval <= 0 {
+		log.Fatal("Invalid TABLE_SNAPSHOT_INTERVAL: must be positive")
+	}
+
+	config.ShutdownTimeout = getEnvDuration("SHUTDOWN_TIMEOUT", websocket_v2.DefaultShutdownTimeout)
+	if config.ShutdownTimeout <= 0 {
+		log.Fatal("Invalid SHUTDOWN_TIMEOUT: must be positive")
+	}
+
+	auditRetention, err := time.ParseDuration(getEnv("AUDIT_RETENTION", "2160h"))
+	if err != nil || auditRetention < 0 {
+		log.Fatal("Invalid AUDIT_RETENTION:", getEnv("AUDIT_RETENTION", ""))
+	}
+	config.AuditRetention = auditRetention
+
+	config.LoginEventRetention = getEnvDuration("LOGIN_EVENT_RETENTION", 0)
+	config.ClosedAccountRetention = getEnvDuration("CLOSED_ACCOUNT_RETENTION", 30*24*time.Hour)
+	config.HandHistoryRetention = getEnvDuration("HAND_HISTORY_RETENTION", 0)
+	config.RetentionPurgeEvery = getEnvDuration("RETENTION_PURGE_INTERVAL", time.Hour)
+	if config.RetentionPurgeEvery == 0 {
+		log.Fatal("Invalid RETENTION_PURGE_INTERVAL: must be positive")
+	}
+
+	config.WSCompression = loadCompressionPolicy()
+	config.WSMessageSize = loadMessageSizePolicy()
+	config.WSSendQueue = loadSendQueuePolicy()
+	config.WSHeartbeat = loadHeartbeatPolicy()
+	config.WSResumeWindow = getEnvDuration("WS_RESUME_WINDOW", websocket_v2.DefaultResumeWindow)
+	config.WSSessionPolicy = websocket_v2.SessionPolicy(getEnv("WS_SESSION_POLICY", string(websocket_v2.SessionsMultiple)))
+	if err := config.WSSessionPolicy.Validate(); err != nil {
+		log.Fatal("Invalid WS_SESSION_POLICY: ", err)
+	}
+	config.WSLoadShedding = loadShedPolicy()
+	config.WSBackplane = getEnv("WS_BACKPLANE", "")
+	config.WSRedis = websocket_v2.RedisConfig{
+		Addr:     getEnv("WS_REDIS_ADDR", "localhost:6379"),
+		Password: getEnv("WS_REDIS_PASSWORD", ""),
+		Channel:  getEnv("WS_REDIS_CHANNEL", websocket_v2.DefaultRedisChannel),
+	}
+	if config.WSBackplane != "" && config.WSBackplane != "redis" {
+		log.Fatal("Invalid WS_BACKPLANE: must be empty or redis")
+	}
+
+	config.TrustedProxies = parseList(getEnv("TRUSTED_PROXIES", ""))
+	config.RateLimitExempt = parseRateLimitExempt(getEnv("WS_RATE_LIMIT_EXEMPT", ""))
+
+	config.GeoRangesFile = getEnv("GEO_IP_RANGES_FILE", "")
+	config.GeoBlocked = map[geo.Action][]string{
+		geo.ActionRegister:  geo.ParseRegionList(getEnv("GEO_BLOCK_REGISTRATION", "")),
+		geo.ActionRealMoney: geo.ParseRegionList(getEnv("GEO_BLOCK_REAL_MONEY", "")),
+	}
+
 	// Database connection
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "3306")
@@ -37,7 +238,6 @@ func Load() *Config {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		dbUser, dbPassword, dbHost, dbPort, dbName)
 
-	var err error
 	config.DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
@@ -47,9 +247,168 @@ func Load() *Config {
 	return config
 }
 
+// loadGameDefaults reads the GAME_* settings over the built-in defaults
+func loadGameDefaults() game.GameDefaults {
+	defaults := game.DefaultGameDefaults()
+	defaults.StartingChips = getEnvInt("GAME_STARTING_CHIPS", defaults.StartingChips)
+	defaults.MaxPlayers = getEnvInt("GAME_MAX_PLAYERS", defaults.MaxPlayers)
+	defaults.SmallBlind = getEnvInt("GAME_SMALL_BLIND", defaults.SmallBlind)
+	defaults.BigBlind = getEnvInt("GAME_BIG_BLIND", defaults.BigBlind)
+	defaults.RakePercent = getEnvFloat("GAME_RAKE_PERCENT", defaults.RakePercent)
+	defaults.MaxRake = getEnvInt("GAME_MAX_RAKE", defaults.MaxRake)
+	if err := defaults.Validate(); err != nil {
+		log.Fatal("Invalid game defaults: ", err)
+	}
+	return defaults
+}
+
+// loadCompressionPolicy reads the WS_COMPRESSION* settings over the defaults
+func loadCompressionPolicy() websocket_v2.CompressionPolicy {
+	policy := websocket_v2.DefaultCompressionPolicy()
+	policy.Enabled = getEnvBool("WS_COMPRESSION", policy.Enabled)
+	policy.MinBytes = getEnvInt("WS_COMPRESSION_MIN_BYTES", policy.MinBytes)
+	policy.Level = getEnvInt("WS_COMPRESSION_LEVEL", policy.Level)
+	if err := policy.Validate(); err != nil {
+		log.Fatal("Invalid WebSocket compression settings: ", err)
+	}
+	return policy
+}
+
+// loadMessageSizePolicy reads WS_MAX_MESSAGE_BYTES and WS_CHUNK_BYTES over the defaults
+func loadMessageSizePolicy() websocket_v2.MessageSizePolicy {
+	policy := websocket_v2.DefaultMessageSizePolicy()
+	policy.MaxInboundBytes = getEnvInt("WS_MAX_MESSAGE_BYTES", policy.MaxInboundBytes)
+	policy.ChunkBytes = getEnvInt("WS_CHUNK_BYTES", policy.ChunkBytes)
+	if err := policy.Validate(); err != nil {
+		log.Fatal("Invalid WebSocket message size settings: ", err)
+	}
+	return policy
+}
+
+// loadSendQueuePolicy reads the WS_SEND_QUEUE_* settings over the defaults
+func loadSendQueuePolicy() websocket_v2.SendQueuePolicy {
+	policy := websocket_v2.DefaultSendQueuePolicy()
+	policy.Size = getEnvInt("WS_SEND_QUEUE_SIZE", policy.Size)
+	policy.Overflow = websocket_v2.OverflowPolicy(getEnv("WS_SEND_QUEUE_OVERFLOW", string(policy.Overflow)))
+	policy.MaxDrops = getEnvInt("WS_SEND_QUEUE_MAX_DROPS", policy.MaxDrops)
+	if err := policy.Validate(); err != nil {
+		log.Fatal("Invalid WebSocket send queue settings: ", err)
+	}
+	return policy
+}
+
+// loadHeartbeatPolicy reads WS_PING_INTERVAL and WS_PONG_TIMEOUT over the defaults
+func loadHeartbeatPolicy() websocket_v2.HeartbeatPolicy {
+	policy := websocket_v2.DefaultHeartbeatPolicy()
+	policy.PingInterval = getEnvDuration("WS_PING_INTERVAL", policy.PingInterval)
+	policy.PongTimeout = getEnvDuration("WS_PONG_TIMEOUT", policy.PongTimeout)
+	if err := policy.Validate(); err != nil {
+		log.Fatal("Invalid WebSocket heartbeat settings: ", err)
+	}
+	return policy
+}
+
+// loadShedPolicy reads the WS_SHED_* and WS_LOAD_SHEDDING settings over the defaults
+func loadShedPolicy() websocket_v2.ShedPolicy {
+	policy := websocket_v2.DefaultShedPolicy()
+	policy.Enabled = getEnvBool("WS_LOAD_SHEDDING", policy.Enabled)
+	policy.MaxMailboxDepth = getEnvInt("WS_SHED_MAX_MAILBOX", policy.MaxMailboxDepth)
+	policy.MaxCPU = getEnvFloat("WS_SHED_MAX_CPU", policy.MaxCPU)
+	policy.MaxDBLatency = getEnvDuration("WS_SHED_MAX_DB_LATENCY", policy.MaxDBLatency)
+	policy.ShedPriority = websocket_v2.MessagePriority(getEnv("WS_SHED_PRIORITY", string(policy.ShedPriority)))
+	policy.RejectConnections = getEnvBool("WS_SHED_REJECT_CONNECTIONS", policy.RejectConnections)
+	policy.CheckInterval = getEnvDuration("WS_SHED_CHECK_INTERVAL", policy.CheckInterval)
+	if err := policy.Validate(); err != nil {
+		log.Fatal("Invalid WebSocket load shedding settings: ", err)
+	}
+	return policy
+}
+
+// parseRateLimitExempt reads "userID=actor" entries; a bare user ID is
+// counted as a system actor
+func parseRateLimitExempt(value string) map[string]string {
+	exempt := make(map[string]string)
+	for _, item := range parseList(value) {
+		userID, actor, found := strings.Cut(item, "=")
+		userID, actor = strings.TrimSpace(userID), strings.TrimSpace(actor)
+		if !found || actor == "" {
+			actor = websocket_v2.SystemActorOther
+		}
+		if userID == "" {
+			log.Fatal("Invalid WS_RATE_LIMIT_EXEMPT entry: ", item)
+		}
+		exempt[userID] = actor
+	}
+	return exempt
+}
+
+// parseList splits a comma-separated setting, dropping empty entries
+func parseList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// getEnvInt reads an integer setting, refusing to start on a malformed value
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return parsed
+}
+
+// getEnvDuration reads a non-negative duration setting, refusing to start on
+// a malformed value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		log.Fatalf("Invalid %s: %q", key, value)
+	}
+	return parsed
+}
+
+// getEnvBool reads a true/false setting, refusing to start on a malformed value
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return parsed
+}
+
+// getEnvFloat reads a decimal setting, refusing to start on a malformed value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return parsed
+}
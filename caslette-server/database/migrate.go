@@ -22,6 +22,27 @@ func Migrate(db *gorm.DB) {
 		&models.UserRole{},
 		&models.RolePermission{},
 		&models.UserPermission{},
+		&models.HandDispute{},
+		&models.RakeRecord{},
+		&models.UserTag{},
+		&models.PlayMoneyAccount{},
+		&models.BotToken{},
+		&models.LoginEvent{},
+		&models.CosmeticItem{},
+		&models.UserCosmetic{},
+		&models.UserPreference{},
+		&models.SystemStatus{},
+		&models.AuditLog{},
+		&models.PurgeRecord{},
+		&models.RateLimitState{},
+		&models.HandHistory{},
+		&models.HandHistoryPlayer{},
+		&models.HandEvent{},
+		&models.PlayerStats{},
+		&models.TableSnapshot{},
+		&models.CertificationEntry{},
+		&models.CollusionFlag{},
+		&models.TableChatMessage{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
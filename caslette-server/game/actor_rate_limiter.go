@@ -88,6 +88,7 @@ func (cmd *RecordTableCreatedCommand) Execute(rl *RateLimiterState) interface{}
 	userState := rl.getUserState(cmd.UserID)
 	userState.CreatedTables = append(userState.CreatedTables, cmd.TableID)
 	userState.LastActivity = time.Now()
+	rl.persist(cmd.UserID)
 	return nil
 }
 
@@ -101,6 +102,7 @@ func (cmd *RecordTableClosedCommand) Execute(rl *RateLimiterState) interface{} {
 	userState := rl.getUserState(cmd.UserID)
 	userState.CreatedTables = rl.removeFromSlice(userState.CreatedTables, cmd.TableID)
 	userState.LastActivity = time.Now()
+	rl.persist(cmd.UserID)
 	return nil
 }
 
@@ -116,6 +118,7 @@ func (cmd *RecordPlayerJoinedCommand) Execute(rl *RateLimiterState) interface{}
 		userState.ActiveTables = append(userState.ActiveTables, cmd.TableID)
 	}
 	userState.LastActivity = time.Now()
+	rl.persist(cmd.UserID)
 	return nil
 }
 
@@ -129,6 +132,7 @@ func (cmd *RecordPlayerLeftCommand) Execute(rl *RateLimiterState) interface{} {
 	userState := rl.getUserState(cmd.UserID)
 	userState.ActiveTables = rl.removeFromSlice(userState.ActiveTables, cmd.TableID)
 	userState.LastActivity = time.Now()
+	rl.persist(cmd.UserID)
 	return nil
 }
 
@@ -224,6 +228,10 @@ type RateLimiterState struct {
 	maxJoinsPerWindow   int           // Max join attempts per window
 	maxObserverTables   int           // Max tables a user can observe simultaneously
 	cleanupInterval     time.Duration // How often to clean up old entries
+
+	// store persists the tables each user has created and sits at; nil
+	// keeps state in memory only
+	store RateLimitStore
 }
 
 // getUserState returns the rate limit state for a user, creating it if needed
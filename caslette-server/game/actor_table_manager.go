@@ -5,26 +5,108 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"log"
+	"strings"
 	"sync"
+	"time"
 )
 
 // ActorTableManager manages tables using the actor pattern
 type ActorTableManager struct {
-	actors            map[string]*TableActor
-	gameEngineFactory GameEngineFactory
-	rateLimiter       *ActorRateLimiter
-	validator         *TableValidator
-	mu                sync.RWMutex // Protects the actors map only
+	actors             map[string]*TableActor
+	gameEngineFactory  GameEngineFactory
+	rateLimiter        *ActorRateLimiter
+	validator          *TableValidator
+	eventBroadcaster   GameEventBroadcaster
+	escrow             *ChipEscrow // Buy-ins backing the chips in play
+	ratholes           *RatholeGuard
+	cosmetics          CosmeticsProvider
+	handStats          *HandStats
+	chat               *TableChat
+	invitations        *TableInvitations
+	lobbyStats         *LobbyStats
+	handListeners      map[int]HandListener
+	gameListeners      map[int]GameEventListener
+	tableListeners     map[int]TableListener
+	nextListenerID     int
+	ledger             DiamondLedger
+	sitAndGos          map[string]*SitAndGo
+	reconnectGrace     time.Duration
+	seatReservation    time.Duration          // How long seats picked from the lobby are held
+	graceTimers        map[string]*time.Timer // Player ID -> pending seat release
+	reconnectMessenger PlayerMessenger        // Sends reconnecting players their tables' state
+	interHandDelay     time.Duration
+	handTimers         map[string]*time.Timer // Table ID -> pending next hand
+	draining           bool                   // Set by Drain; no more hands are dealt
+	approvalBigBlind   int                    // Big blind above which new tables need approval; zero for none
+	approvalNotifier   ApprovalNotifier       // Tells creators what an admin decided
+	headsUp            *HeadsUpQueue          // Pairs players for heads-up matches
+	handHistory        HandHistoryStore       // Persists completed hands; nil keeps none
+	handEventStore     HandEventStore         // Appends each hand's event log; nil keeps none
+	playerStats        PlayerStatsStore       // Accumulates lifetime stats; nil keeps none
+	houseAccount       string                 // Player ID credited with rake
+	rakeStore          RakeStore              // Rake ledger; nil keeps none
+	certification      CertificationLog       // Hash-chained log of game outcomes; nil certifies none
+	snapshots          TableSnapshotStore     // Crash recovery snapshots; nil keeps none
+	snapshotStop       chan struct{}          // Closed to stop periodic snapshots
+	collusion          *CollusionDetector     // Analyzes completed hands for collusion; nil analyzes none
+	tags               *TableTagIndex         // Open tables by tag, for the lobby's tag filter
+	joinGate           *JoinGate              // Holds off players whose account is being changed
+	mu                 sync.RWMutex           // Protects the actors map and event broadcaster
 }
 
 // NewActorTableManager creates a new actor-based table manager
 func NewActorTableManager(factory GameEngineFactory) *ActorTableManager {
-	return &ActorTableManager{
+	tm := &ActorTableManager{
 		actors:            make(map[string]*TableActor),
 		gameEngineFactory: factory,
 		rateLimiter:       NewActorRateLimiter(),
 		validator:         NewTableValidator(),
+		escrow:            NewChipEscrow(),
+		ratholes:          NewRatholeGuard(DefaultRatholeWindow),
+		handStats:         NewHandStats(),
+		chat:              NewTableChat(),
+		invitations:       NewTableInvitations(),
+		tags:              NewTableTagIndex(),
+		joinGate:          NewJoinGate(),
+		lobbyStats:        NewLobbyStats(),
+		handListeners:     make(map[int]HandListener),
+		gameListeners:     make(map[int]GameEventListener),
+		tableListeners:    make(map[int]TableListener),
+		sitAndGos:         make(map[string]*SitAndGo),
+		reconnectGrace:    DefaultReconnectGrace,
+		seatReservation:   DefaultSeatReservation,
+		graceTimers:       make(map[string]*time.Timer),
+		interHandDelay:    DefaultInterHandDelay,
+		handTimers:        make(map[string]*time.Timer),
 	}
+	tm.headsUp = NewHeadsUpQueue(tm)
+	return tm
+}
+
+// Escrow returns the buy-in escrow for the manager's tables
+func (tm *ActorTableManager) Escrow() *ChipEscrow {
+	return tm.escrow
+}
+
+// JoinGate returns the gate that can hold off a player's joins
+func (tm *ActorTableManager) JoinGate() *JoinGate {
+	return tm.joinGate
+}
+
+// RatholeGuard returns the anti-ratholing rule applied to buy-ins
+func (tm *ActorTableManager) RatholeGuard() *RatholeGuard {
+	return tm.ratholes
+}
+
+// HandStats returns the tracker pushing end-of-hand summaries to players
+func (tm *ActorTableManager) HandStats() *HandStats {
+	return tm.handStats
+}
+
+// TableChat returns the chat run at the manager's tables
+func (tm *ActorTableManager) TableChat() *TableChat {
+	return tm.chat
 }
 
 // generateTableID generates a unique table ID
@@ -45,6 +127,10 @@ func (tm *ActorTableManager) CreateTable(ctx context.Context, req *TableCreateRe
 	if err := tm.validateCreateRequest(req); err != nil {
 		return nil, err
 	}
+	req.Settings = withDefaultRake(req.Settings)
+	if err := hashTablePassword(&req.Settings); err != nil {
+		return nil, err
+	}
 
 	// Generate table ID
 	tableID := tm.generateTableID()
@@ -53,6 +139,9 @@ func (tm *ActorTableManager) CreateTable(ctx context.Context, req *TableCreateRe
 	table := NewGameTable(tableID, req.Name, req.GameType, req.CreatedBy, req.Settings)
 	table.Description = req.Description
 	table.Tags = req.Tags
+	if !req.Sanctioned && tm.requiresApproval(table) {
+		table.Approval = &TableApproval{Status: ApprovalPending, RequestedAt: table.CreatedAt}
+	}
 
 	// Create game engine
 	if tm.gameEngineFactory != nil {
@@ -61,6 +150,7 @@ func (tm *ActorTableManager) CreateTable(ctx context.Context, req *TableCreateRe
 			return nil, fmt.Errorf("failed to create game engine: %w", err)
 		}
 		table.GameEngine = engine
+		tm.attachEngine(table, engine)
 	}
 
 	// Create actor for this table
@@ -69,10 +159,32 @@ func (tm *ActorTableManager) CreateTable(ctx context.Context, req *TableCreateRe
 	tm.mu.Lock()
 	tm.actors[table.ID] = actor
 	tm.mu.Unlock()
+	tm.tags.Add(table)
+	tm.rateLimiter.RecordTableCreated(req.CreatedBy, table.ID)
+	tm.persistTable(actor)
+	tm.notifyTableListeners(table, TableCreated)
 
 	return table, nil
 }
 
+// attachEngine forwards engine events (cards, pots, turns) to table
+// subscribers and feeds each hand's summary to stats and hand history
+func (tm *ActorTableManager) attachEngine(table *GameTable, engine GameEngine) {
+	engine.SubscribeToEvents(func(event *GameEvent) {
+		tm.BroadcastGameEvent(table, event)
+		tm.notifyGameEventListeners(table, event)
+		if event.Type == HandSummaryEvent {
+			if results, ok := event.Data["results"].([]HandResult); ok {
+				tm.handStats.RecordHand(table, results)
+				tm.lobbyStats.RecordHand(table, results)
+				tm.notifyHandListeners(table, results)
+			}
+			tm.recordHandHistory(table)
+			tm.scheduleNextHand(table)
+		}
+	})
+}
+
 // JoinTable handles a player joining a table
 func (tm *ActorTableManager) JoinTable(ctx context.Context, req *TableJoinRequest) error {
 	// Rate limiting check
@@ -89,24 +201,71 @@ func (tm *ActorTableManager) JoinTable(ctx context.Context, req *TableJoinReques
 		return ErrTableNotFound
 	}
 
-	// Check password for private tables
+	// Check password for private tables; invited players need none
 	table, err := tm.GetTable(req.TableID)
 	if err != nil {
 		return err
 	}
 
-	if table.Settings.Private && table.Settings.Password != "" {
-		if req.Password != table.Settings.Password {
+	if tm.passwordRequired(table, req.PlayerID) {
+		if !table.CheckPassword(req.Password) {
 			return &TableError{"INVALID_PASSWORD", "Incorrect password for private table"}
 		}
 	}
 
+	// Only the creator may sit at a table an admin has yet to approve
+	if table.AwaitingApproval() && req.PlayerID != table.CreatedBy {
+		return &TableError{"APPROVAL_PENDING", "Table is waiting for admin approval"}
+	}
+
 	// Send command to actor based on join mode
 	switch req.Mode {
 	case JoinModePlayer:
-		return actor.JoinPlayer(ctx, req.PlayerID, req.Username, req.Position)
+		done, err := tm.joinGate.enter(req.PlayerID)
+		if err != nil {
+			return err
+		}
+		defer done()
+
+		chips, err := tm.ratholes.resolveBuyIn(req.PlayerID, table, req.BuyIn)
+		if err != nil {
+			return err
+		}
+		if err := actor.JoinPlayerWithChips(ctx, req.PlayerID, req.Username, req.Position, chips); err != nil {
+			return err
+		}
+		// The buy-in is paid for and escrowed to back the chips brought to the seat
+		if chips > 0 {
+			if !req.Prepaid {
+				if err := tm.chargeChips(table, req.PlayerID, chips, "Buy-in"); err != nil {
+					actor.LeavePlayer(ctx, req.PlayerID)
+					return err
+				}
+			}
+			if err := tm.depositEscrow(table, req.PlayerID, int64(chips)); err != nil {
+				actor.LeavePlayer(ctx, req.PlayerID)
+				if !req.Prepaid {
+					tm.refundChips(table, req.PlayerID, chips, "Buy-in")
+				}
+				return err
+			}
+			if req.Prepaid {
+				tm.escrow.MarkPrepaid(table.ID, req.PlayerID)
+			}
+		}
+		tm.ratholes.Clear(req.PlayerID, table)
+		tm.rateLimiter.RecordPlayerJoined(req.PlayerID, table.ID)
+		tm.handStats.ResetSession(table.ID, req.PlayerID)
+		tm.applyCosmetics(ctx, actor, req.PlayerID)
+		tm.invitations.Accept(table.ID, req.PlayerID)
+		tm.tableChanged(actor)
+		return nil
 	case JoinModeObserver:
-		return actor.JoinObserver(ctx, req.PlayerID, req.Username)
+		if err := actor.JoinObserver(ctx, req.PlayerID, req.Username); err != nil {
+			return err
+		}
+		tm.tableChanged(actor)
+		return nil
 	default:
 		return &TableError{"INVALID_JOIN_MODE", "Invalid join mode"}
 	}
@@ -114,16 +273,159 @@ func (tm *ActorTableManager) JoinTable(ctx context.Context, req *TableJoinReques
 
 // LeaveTable handles a player leaving a table
 func (tm *ActorTableManager) LeaveTable(ctx context.Context, req *TableLeaveRequest) error {
+	_, err := tm.leaveTable(ctx, req)
+	return err
+}
+
+// leaveTable unseats a player or stops an observer watching, returning the
+// stack a player left with
+func (tm *ActorTableManager) leaveTable(ctx context.Context, req *TableLeaveRequest) (int, error) {
 	// Get table actor
 	tm.mu.RLock()
 	actor, exists := tm.actors[req.TableID]
 	tm.mu.RUnlock()
 
 	if !exists {
+		return 0, ErrTableNotFound
+	}
+
+	// Observers just stop watching, freeing their place for another
+	if actor.table.IsObserver(req.PlayerID) && !actor.table.IsPlayerAtTable(req.PlayerID) {
+		if err := actor.LeaveObserver(ctx, req.PlayerID); err != nil {
+			return 0, err
+		}
+		tm.tableChanged(actor)
+		return 0, nil
+	}
+
+	// The actor reads the stack as it frees the seat, so a hand settling
+	// meanwhile cannot leave it stale
+	stack, err := actor.LeavePlayer(ctx, req.PlayerID)
+	if err != nil {
+		return 0, err
+	}
+	tm.seatReleased(ctx, actor.table, req.PlayerID, stack)
+	tm.tableChanged(actor)
+	return stack, nil
+}
+
+// seatReleased settles a player's departure from a seat they left holding
+// the given stack
+func (tm *ActorTableManager) seatReleased(ctx context.Context, table *GameTable, playerID string, stack int) {
+	tm.cashOut(table, playerID, stack)
+	tm.ratholes.RecordDeparture(playerID, table, stack)
+	tm.rateLimiter.RecordPlayerLeft(playerID, table.ID)
+	tm.handStats.ResetSession(table.ID, playerID)
+	tm.headsUp.playerLeft(ctx, table.ID, playerID, stack)
+}
+
+// depositEscrow escrows chips brought to a table, noting whether diamonds
+// paid for them. Tournament chips are bought with the entry fee, not the buy-in.
+func (tm *ActorTableManager) depositEscrow(table *GameTable, playerID string, amount int64) error {
+	if err := tm.escrow.Deposit(table.ID, playerID, amount); err != nil {
+		return err
+	}
+	tm.escrow.SetLedgerBacked(table.ID, cashTable(table))
+	return nil
+}
+
+// cashTable reports whether players buy a table's chips with diamonds
+func cashTable(table *GameTable) bool {
+	return table.UsesDiamondLedger() && !table.Settings.TournamentMode
+}
+
+// chargeChips takes diamonds for chips brought to a cash table. Without a
+// ledger the manager deals in chips alone and charges nothing.
+func (tm *ActorTableManager) chargeChips(table *GameTable, playerID string, amount int, what string) error {
+	ledger := tm.diamondLedger()
+	if ledger == nil || !cashTable(table) {
+		return nil
+	}
+	if err := ledger.Debit(playerID, amount, what+" at table "+table.Name); err != nil {
+		return &TableError{"BUY_IN_FAILED", "Could not take the " + strings.ToLower(what) + ": " + err.Error()}
+	}
+	return nil
+}
+
+// refundChips gives back diamonds taken by chargeChips for chips that never
+// reached the table
+func (tm *ActorTableManager) refundChips(table *GameTable, playerID string, amount int, what string) {
+	ledger := tm.diamondLedger()
+	if ledger == nil || !cashTable(table) {
+		return
+	}
+	if err := ledger.Credit(playerID, amount, what+" refund at table "+table.Name); err != nil {
+		log.Printf("Table %s: failed to refund %d diamonds to %s: %v", table.ID, amount, playerID, err)
+	}
+}
+
+// cashOut releases a departing player's escrow and, at cash tables, pays
+// their stack back in diamonds. Prepaid seats are paid back by whoever took
+// the buy-in.
+func (tm *ActorTableManager) cashOut(table *GameTable, playerID string, stack int) {
+	owed, prepaid := tm.escrow.CashOut(table.ID, playerID, int64(stack))
+	ledger := tm.diamondLedger()
+	if prepaid || owed <= 0 || ledger == nil || !cashTable(table) {
+		return
+	}
+	if err := ledger.Credit(playerID, int(owed), "Cash-out at table "+table.Name); err != nil {
+		log.Printf("Table %s: failed to cash out %d diamonds to %s: %v", table.ID, owed, playerID, err)
+	}
+}
+
+// stackAt returns the chips a player holds at a table, read through its
+// actor; zero when they are not seated there
+func (tm *ActorTableManager) stackAt(ctx context.Context, tableID, playerID string) int {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return 0
+	}
+	stack, _ := actor.SeatStack(ctx, playerID)
+	return stack
+}
+
+// MovePlayer reseats a player from one table to another. The player keeps
+// their original seat if the destination refuses them.
+func (tm *ActorTableManager) MovePlayer(ctx context.Context, playerID, username, fromTableID, toTableID string) error {
+	tm.mu.RLock()
+	fromActor, fromExists := tm.actors[fromTableID]
+	toActor, toExists := tm.actors[toTableID]
+	tm.mu.RUnlock()
+
+	if !fromExists || !toExists {
 		return ErrTableNotFound
 	}
+	if fromTableID == toTableID {
+		return &TableError{"SAME_TABLE", "Player is already at this table"}
+	}
+
+	position := fromActor.table.GetPlayerPosition(playerID)
+	if position == -1 {
+		return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+	}
 
-	return actor.LeavePlayer(ctx, req.PlayerID)
+	// A move is not a departure, so the stack travels with the player
+	chips, err := fromActor.LeavePlayer(ctx, playerID)
+	if err != nil {
+		return err
+	}
+	if err := toActor.JoinPlayerWithChips(ctx, playerID, username, 0, chips); err != nil {
+		// Positions are 1-based for JoinPlayer
+		if rollbackErr := fromActor.JoinPlayerWithChips(ctx, playerID, username, position+1, chips); rollbackErr != nil {
+			return fmt.Errorf("failed to move player: %v (and could not restore seat: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	// The escrowed buy-in follows the stack to the new table
+	if escrowed := tm.escrow.Release(fromTableID, playerID); escrowed > 0 {
+		tm.depositEscrow(toActor.table, playerID, escrowed)
+	}
+	tm.handStats.ResetSession(fromTableID, playerID)
+	tm.handStats.ResetSession(toTableID, playerID)
+	tm.applyCosmetics(ctx, toActor, playerID)
+	return nil
 }
 
 // GetTable returns table information
@@ -169,6 +471,22 @@ func (tm *ActorTableManager) CloseTable(tableID string) error {
 	delete(tm.actors, tableID)
 	tm.mu.Unlock()
 
+	tm.stopHandLoop(tableID)
+
+	// Neither the creator nor anyone still seated is counted for the table
+	tm.rateLimiter.RecordTableClosed(actor.table.CreatedBy, tableID)
+	for _, slot := range actor.table.PlayerSlots {
+		if slot.PlayerID != "" {
+			tm.rateLimiter.RecordPlayerLeft(slot.PlayerID, tableID)
+		}
+	}
+
+	tm.handStats.RemoveTable(tableID)
+	tm.chat.RemoveTable(tableID)
+	tm.invitations.RemoveTable(tableID)
+	tm.tags.Remove(actor.table)
+	tm.deleteSnapshot(tableID)
+	tm.notifyTableListeners(actor.table, TableClosed)
 	return nil
 }
 
@@ -179,6 +497,18 @@ func (tm *ActorTableManager) Stop() {
 		actor.Stop()
 	}
 	tm.actors = make(map[string]*TableActor)
+	for playerID, timer := range tm.graceTimers {
+		timer.Stop()
+		delete(tm.graceTimers, playerID)
+	}
+	for tableID, timer := range tm.handTimers {
+		timer.Stop()
+		delete(tm.handTimers, tableID)
+	}
+	if tm.snapshotStop != nil {
+		close(tm.snapshotStop)
+		tm.snapshotStop = nil
+	}
 	tm.mu.Unlock()
 }
 
@@ -187,12 +517,15 @@ func (tm *ActorTableManager) validateCreateRequest(req *TableCreateRequest) erro
 	return tm.validator.ValidateTableCreateRequest(req)
 }
 
-// ListTables returns a filtered list of tables
+// ListTables returns a filtered list of tables. Tables waiting for approval
+// are left out unless the list is filtered to their creator.
 func (tm *ActorTableManager) ListTables(filters map[string]interface{}) []*GameTable {
-	tables := tm.GetTables()
-
-	if len(filters) == 0 {
-		return tables
+	// Tagged lobbies read only the tables the tag index points to
+	var tables []*GameTable
+	if tags, ok := tagsFilter(filters); ok {
+		tables = tm.tablesTagged(tags)
+	} else {
+		tables = tm.GetTables()
 	}
 
 	var filteredTables []*GameTable
@@ -200,6 +533,21 @@ func (tm *ActorTableManager) ListTables(filters map[string]interface{}) []*GameT
 	for _, table := range tables {
 		matchesFilter := true
 
+		// Check name filter: a substring of the name, ignoring case
+		if name, exists := filters["name"]; exists {
+			if nameStr, ok := name.(string); ok {
+				if !strings.Contains(strings.ToLower(table.Name), strings.ToLower(strings.TrimSpace(nameStr))) {
+					matchesFilter = false
+				}
+			}
+		}
+
+		if table.AwaitingApproval() {
+			if createdBy, _ := filters["created_by"].(string); createdBy != table.CreatedBy {
+				continue
+			}
+		}
+
 		// Check game_type filter
 		if gameType, exists := filters["game_type"]; exists {
 			if gameTypeStr, ok := gameType.(string); ok {
@@ -227,6 +575,33 @@ func (tm *ActorTableManager) ListTables(filters map[string]interface{}) []*GameT
 			}
 		}
 
+		// Check bots_allowed filter
+		if botsAllowed, exists := filters["bots_allowed"]; exists {
+			if botsAllowedBool, ok := botsAllowed.(bool); ok {
+				if table.Settings.BotsAllowed != botsAllowedBool {
+					matchesFilter = false
+				}
+			}
+		}
+
+		// Check currency filter
+		if currency, exists := filters["currency"]; exists {
+			if currencyStr, ok := currency.(string); ok {
+				if string(table.GetCurrency()) != currencyStr {
+					matchesFilter = false
+				}
+			}
+		}
+
+		// Check practice filter
+		if practice, exists := filters["practice"]; exists {
+			if practiceBool, ok := practice.(bool); ok {
+				if table.IsPractice() != practiceBool {
+					matchesFilter = false
+				}
+			}
+		}
+
 		if matchesFilter {
 			filteredTables = append(filteredTables, table)
 		}
@@ -235,25 +610,49 @@ func (tm *ActorTableManager) ListTables(filters map[string]interface{}) []*GameT
 	return filteredTables
 }
 
-// GetStats returns statistics about the table manager
+// MailboxDepth returns how many commands wait in the busiest table's mailbox
+func (tm *ActorTableManager) MailboxDepth() int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	depth := 0
+	for _, actor := range tm.actors {
+		depth = max(depth, len(actor.commands))
+	}
+	return depth
+}
+
+// GetStats returns statistics about the table manager. Top-level totals cover
+// real-money tables only; practice tables are reported separately.
 func (tm *ActorTableManager) GetStats() map[string]interface{} {
 	tables := tm.GetTables()
 
 	stats := map[string]interface{}{
-		"total_tables":    len(tables),
+		"total_tables":    0,
+		"active_tables":   0,
+		"total_players":   0,
+		"total_observers": 0,
+	}
+	practice := map[string]interface{}{
+		"total_tables":    0,
 		"active_tables":   0,
 		"total_players":   0,
 		"total_observers": 0,
 	}
 
 	for _, table := range tables {
+		bucket := stats
+		if table.IsPractice() {
+			bucket = practice
+		}
+		bucket["total_tables"] = bucket["total_tables"].(int) + 1
 		if table.Status == TableStatusActive {
-			stats["active_tables"] = stats["active_tables"].(int) + 1
+			bucket["active_tables"] = bucket["active_tables"].(int) + 1
 		}
-		stats["total_players"] = stats["total_players"].(int) + table.GetPlayerCount()
-		stats["total_observers"] = stats["total_observers"].(int) + table.GetObserverCount()
+		bucket["total_players"] = bucket["total_players"].(int) + table.GetPlayerCount()
+		bucket["total_observers"] = bucket["total_observers"].(int) + table.GetObserverCount()
 	}
 
+	stats["practice"] = practice
 	return stats
 }
 
@@ -275,11 +674,14 @@ func (tm *ActorTableManager) tryStartGame(table *GameTable) error {
 		return &TableError{"NOT_ENOUGH_PLAYERS", "Not enough players to start game"}
 	}
 
+	// Engines that can deal hand after hand keep the table running
+	if _, ok := table.GameEngine.(HandLoopEngine); ok {
+		return tm.startHandLoop(context.Background(), table.ID)
+	}
+
 	// Update table status to active
 	table.Status = TableStatusActive
-
-	// Initialize the game engine if needed
-	// The actual game start logic would be handled by the game engine
+	tm.notifyTableListeners(table, TableUpdated)
 	return nil
 }
 
@@ -0,0 +1,44 @@
+package game
+
+// SetAnte sets the ante every player posts before the deal; zero disables it
+func (the *TexasHoldemEngine) SetAnte(amount int) {
+	the.ante = max(amount, 0)
+}
+
+// postAntes takes the ante from every player before the deal. Antes are dead
+// money: they go into the pot without counting toward the bet to call. A
+// player who cannot cover the ante puts in what they have and is all in.
+func (the *TexasHoldemEngine) postAntes(activePlayers []*Player) {
+	if the.ante <= 0 {
+		return
+	}
+
+	antes := make([]map[string]interface{}, 0, len(activePlayers))
+	for _, player := range activePlayers {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		if holdemPlayer == nil {
+			continue
+		}
+		amount := min(the.ante, holdemPlayer.Chips)
+		holdemPlayer.Chips -= amount
+		holdemPlayer.TotalBet += amount
+		the.pot += amount
+		if holdemPlayer.Chips == 0 {
+			holdemPlayer.IsAllIn = true
+		}
+		antes = append(antes, map[string]interface{}{
+			"playerID": holdemPlayer.ID,
+			"amount":   amount,
+			"allIn":    holdemPlayer.IsAllIn,
+		})
+	}
+
+	the.emitEvent(&GameEvent{
+		Type: "antes_posted",
+		Data: map[string]interface{}{
+			"ante":  the.ante,
+			"antes": antes,
+			"pot":   the.pot,
+		},
+	})
+}
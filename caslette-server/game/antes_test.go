@@ -0,0 +1,99 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startAnteHand starts a three-handed hand at 10/20 with the given ante;
+// seat 0 is the button, seat 1 the small blind and seat 2 the big blind
+func startAnteHand(t *testing.T, ante int, chips ...int) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("ante_game")
+	engine.SetSmallBlind(10)
+	engine.SetBigBlind(20)
+	engine.SetAnte(ante)
+	for i, stack := range chips {
+		playerID := string(rune('a' + i))
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Position: i, Data: map[string]interface{}{"chips": stack}}))
+	}
+	require.NoError(t, engine.Start())
+	return engine
+}
+
+func eventOfType(engine *TexasHoldemEngine, eventType string) *GameEvent {
+	for _, event := range engine.GetEvents() {
+		if event.Type == eventType {
+			return event
+		}
+	}
+	return nil
+}
+
+func TestAntesAreCollectedFromEveryPlayer(t *testing.T) {
+	engine := startAnteHand(t, 5, 1000, 1000, 1000)
+
+	button, small, big := engine.getHoldemPlayer("a"), engine.getHoldemPlayer("b"), engine.getHoldemPlayer("c")
+	assert.Equal(t, 995, button.Chips)
+	assert.Equal(t, 5, button.TotalBet)
+	assert.Zero(t, button.CurrentBet, "antes do not count toward the bet to call")
+	assert.Equal(t, 985, small.Chips)
+	assert.Equal(t, 15, small.TotalBet)
+	assert.Equal(t, 975, big.Chips)
+	assert.Equal(t, 25, big.TotalBet)
+	assert.Equal(t, 45, engine.pot)
+	assert.Equal(t, 20, engine.currentBet)
+
+	event := eventOfType(engine, "antes_posted")
+	require.NotNil(t, event)
+	assert.Equal(t, 5, event.Data["ante"])
+	assert.Len(t, event.Data["antes"], 3)
+}
+
+func TestShortStackIsAllInForTheAnte(t *testing.T) {
+	engine := startAnteHand(t, 5, 1000, 3, 1000)
+
+	small := engine.getHoldemPlayer("b")
+	assert.Zero(t, small.Chips)
+	assert.True(t, small.IsAllIn)
+	assert.Equal(t, 3, small.TotalBet, "a short player antes what they have")
+	assert.Zero(t, small.CurrentBet, "nothing is left for the small blind")
+	assert.Equal(t, 5+3+25, engine.pot)
+	assert.Equal(t, 20, engine.currentBet)
+
+	// The short stack can only win the antes it matched
+	pots := buildPots([]potContribution{
+		{PlayerID: "a", Position: 0, Amount: 5},
+		{PlayerID: "b", Position: 1, Amount: small.TotalBet},
+		{PlayerID: "c", Position: 2, Amount: 25},
+	}, engine.pot)
+	require.Len(t, pots, 3)
+	assert.Equal(t, 9, pots[0].Amount)
+	assert.Equal(t, []string{"a", "b", "c"}, pots[0].Eligible)
+}
+
+func TestBigBlindShortAfterAnteKeepsSmallBlindToCall(t *testing.T) {
+	engine := startAnteHand(t, 5, 1000, 1000, 4)
+
+	big := engine.getHoldemPlayer("c")
+	assert.True(t, big.IsAllIn)
+	assert.Equal(t, 4, big.TotalBet)
+	assert.Equal(t, 10, engine.currentBet, "the small blind still has to be called")
+}
+
+func TestAnteSettings(t *testing.T) {
+	validator := NewTableValidator()
+	settings := DefaultTableSettings()
+	settings.Ante = settings.BigBlind + 1
+	assert.Error(t, validator.ValidateTableSettings(settings))
+	settings.Ante = -1
+	assert.Error(t, validator.ValidateTableSettings(settings))
+	settings.Ante = 5
+	assert.NoError(t, validator.ValidateTableSettings(settings))
+
+	engine, err := (&TexasHoldemEngineFactory{}).CreateEngine(GameTypeTexasHoldem, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 5, engine.(*TexasHoldemEngine).ante)
+	assert.Equal(t, 5, engine.(*TexasHoldemEngine).GetPublicGameState()["ante"])
+}
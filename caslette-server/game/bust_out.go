@@ -0,0 +1,100 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// BustOutCommand frees the seat of a player left with no chips, moving them
+// to the observers when the table allows them
+type BustOutCommand struct {
+	PlayerID string
+	Response chan interface{}
+}
+
+func (cmd *BustOutCommand) Execute(table *GameTable) interface{} {
+	for i := range table.PlayerSlots {
+		slot := &table.PlayerSlots[i]
+		if slot.PlayerID != cmd.PlayerID {
+			continue
+		}
+		// Chips added since the deal keep the player seated
+		if slot.Chips > 0 {
+			return &TableError{"PLAYER_NOT_BUSTED", "Player still has chips"}
+		}
+		username := slot.Username
+		*slot = PlayerSlot{Position: slot.Position}
+		table.UpdatedAt = time.Now()
+
+		if !table.Settings.ObserversAllowed || table.ObserversFull() || table.IsObserver(cmd.PlayerID) {
+			return table.IsObserver(cmd.PlayerID)
+		}
+		table.Observers = append(table.Observers, TableObserver{
+			PlayerID: cmd.PlayerID,
+			Username: username,
+			JoinedAt: time.Now(),
+		})
+		return true
+	}
+	return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+}
+
+// BustOut sends a busted player's seat to the table actor to free, reporting
+// whether they stay on as an observer
+func (ta *TableActor) BustOut(ctx context.Context, playerID string) (bool, error) {
+	cmd := &BustOutCommand{
+		PlayerID: playerID,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		switch result := result.(type) {
+		case bool:
+			return result, nil
+		case *TableError:
+			return false, result
+		}
+		return false, fmt.Errorf("unexpected response type")
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// bustOut takes a player who lost their stack and did not rebuy out of their
+// seat and tells the table. Tournament tables leave busted players to the
+// tournament to eliminate.
+func (tm *ActorTableManager) bustOut(ctx context.Context, actor *TableActor, slot PlayerSlot) {
+	table := actor.table
+	data := map[string]interface{}{
+		"table_id":  table.ID,
+		"player_id": slot.PlayerID,
+		"seat":      slot.Position,
+	}
+	if !table.Settings.TournamentMode {
+		observing, err := actor.BustOut(ctx, slot.PlayerID)
+		if err != nil {
+			log.Printf("Table %s: failed to free busted player %s's seat: %v", table.ID, slot.PlayerID, err)
+			return
+		}
+		tm.seatReleased(ctx, table, slot.PlayerID, 0)
+		data["seat_freed"] = true
+		data["observing"] = observing
+	}
+
+	tm.BroadcastGameEvent(table, &GameEvent{
+		Type:      "player_busted",
+		PlayerID:  slot.PlayerID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
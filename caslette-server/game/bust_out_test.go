@@ -0,0 +1,60 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBustedPlayerLeavesWithoutObservers(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1", "p2")
+	table.Settings.ObserversAllowed = false
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	require.NoError(t, engine.AdjustChips("p2", -stacks["p2"]))
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 2 }, time.Second, 5*time.Millisecond)
+
+	busted := broadcaster.ofType("player_busted")
+	require.Len(t, busted, 1)
+	assert.Equal(t, "p2", busted[0].PlayerID)
+	assert.Equal(t, true, busted[0].Data["seat_freed"])
+	assert.Equal(t, false, busted[0].Data["observing"])
+	assert.Equal(t, -1, table.GetPlayerPosition("p2"))
+	assert.False(t, table.IsObserver("p2"))
+	assert.Contains(t, table.GetAvailableSlots(), busted[0].Data["seat"], "the seat is open to others")
+	assert.Zero(t, manager.escrow.Balances(table.ID)["p2"])
+}
+
+func TestBustedTournamentPlayerKeepsSeat(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1", "p2")
+	table.Settings.TournamentMode = true
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	require.NoError(t, engine.AdjustChips("p2", -stacks["p2"]))
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 2 }, time.Second, 5*time.Millisecond)
+
+	busted := broadcaster.ofType("player_busted")
+	require.Len(t, busted, 1)
+	assert.Nil(t, busted[0].Data["seat_freed"], "the tournament eliminates its own players")
+	assert.NotEqual(t, -1, table.GetPlayerPosition("p2"))
+}
+
+func TestHandRecordMarksBustedPlayers(t *testing.T) {
+	engine := newRiverTable(t)
+	require.NoError(t, act(engine, ActionAllIn, 0))
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	record := engine.CompletedHand()
+	require.NotNil(t, record)
+	for _, player := range record.Players {
+		assert.Equal(t, player.PlayerID == "2", player.Busted, "only the player who lost their stack busted")
+	}
+}
@@ -0,0 +1,129 @@
+package game
+
+import "sort"
+
+// noSeat marks a blind that nobody posts this hand
+const noSeat = -1
+
+// dealtInSeats returns the seats of the players dealt into the current hand,
+// in seat order
+func (the *TexasHoldemEngine) dealtInSeats() []int {
+	seats := make([]int, 0, len(the.players))
+	for _, player := range the.getActivePlayers() {
+		seats = append(seats, player.Position)
+	}
+	return seats
+}
+
+// playersWithChips counts the seated players able to play a hand
+func (the *TexasHoldemEngine) playersWithChips() int {
+	count := 0
+	for _, player := range the.players {
+		if holdemPlayer := the.getHoldemPlayer(player.ID); holdemPlayer != nil && holdemPlayer.Chips > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// nextSeat returns the first of seats after seat, wrapping around the table
+func nextSeat(seats []int, seat int) int {
+	index := sort.SearchInts(seats, seat+1)
+	if index == len(seats) {
+		index = 0
+	}
+	return seats[index]
+}
+
+// containsSeat reports whether seat is one of seats
+func containsSeat(seats []int, seat int) bool {
+	index := sort.SearchInts(seats, seat)
+	return index < len(seats) && seats[index] == seat
+}
+
+// setPositions places the button and blinds for the hand by seat. The first
+// hand puts the button on the lowest seat. After that the big blind always
+// moves to the next player dealt in, the small blind goes to the seat that
+// had the big blind and the button to the seat that had the small blind.
+// When the player who had the big blind is gone the small blind is dead, and
+// when the seat that had the small blind is empty the button is dead; either
+// way no player pays a blind twice or skips one.
+func (the *TexasHoldemEngine) setPositions() {
+	seats := the.dealtInSeats()
+	defer func() { the.handsDealt++ }()
+
+	if the.handsDealt == 0 {
+		the.dealerPos = seats[0]
+		if len(seats) == 2 {
+			the.smallBlindPos = the.dealerPos
+		} else {
+			the.smallBlindPos = nextSeat(seats, the.dealerPos)
+		}
+		the.smallBlindSeat = the.smallBlindPos
+		the.bigBlindPos = nextSeat(seats, the.smallBlindPos)
+		return
+	}
+
+	previousSmallBlind, previousBigBlind := the.smallBlindSeat, the.bigBlindPos
+	the.bigBlindPos = nextSeat(seats, previousBigBlind)
+
+	if len(seats) == 2 {
+		// Heads up: the button posts the small blind
+		the.dealerPos = nextSeat(seats, the.bigBlindPos)
+		the.smallBlindPos = the.dealerPos
+		the.smallBlindSeat = the.dealerPos
+		return
+	}
+
+	the.dealerPos = previousSmallBlind
+	the.smallBlindSeat = previousBigBlind
+	if containsSeat(seats, previousBigBlind) {
+		the.smallBlindPos = previousBigBlind
+	} else {
+		the.smallBlindPos = noSeat
+	}
+}
+
+// firstToActAfter returns the index in the active players of the first
+// player seated after seat who can still bet, so action order follows the
+// seats rather than the slice
+func (the *TexasHoldemEngine) firstToActAfter(seat int) int {
+	activePlayers := the.getActivePlayers()
+	if len(activePlayers) == 0 {
+		return 0
+	}
+
+	first := sort.Search(len(activePlayers), func(i int) bool {
+		return activePlayers[i].Position > seat
+	})
+	for offset := range activePlayers {
+		index := (first + offset) % len(activePlayers)
+		if player := the.getHoldemPlayer(activePlayers[index].ID); player != nil && !player.IsAllIn {
+			return index
+		}
+	}
+	return first % len(activePlayers)
+}
+
+// playerAtSeat returns the player dealt in at seat, or nil
+func (the *TexasHoldemEngine) playerAtSeat(seat int) *TexasHoldemPlayer {
+	for _, player := range the.getActivePlayers() {
+		if player.Position == seat {
+			return the.getHoldemPlayer(player.ID)
+		}
+	}
+	return nil
+}
+
+// postBlind takes up to amount from player as a blind and returns what was posted
+func (the *TexasHoldemEngine) postBlind(player *TexasHoldemPlayer, amount int) int {
+	amount = min(amount, player.Chips)
+	player.Chips -= amount
+	player.CurrentBet = amount
+	player.TotalBet += amount
+	the.pot += amount
+	if player.Chips == 0 {
+		player.IsAllIn = true
+	}
+	return amount
+}
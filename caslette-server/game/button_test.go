@@ -0,0 +1,136 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newButtonTable seats players a, b, c... in seats 0, 1, 2... at 5/10
+func newButtonTable(t *testing.T, players int) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("button_game")
+	for i := 0; i < players; i++ {
+		playerID := string(rune('a' + i))
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Data: map[string]interface{}{"chips": 1000}}))
+	}
+	return engine
+}
+
+// foldHand folds whoever is to act until the hand is over
+func foldHand(t *testing.T, engine *TexasHoldemEngine) {
+	for engine.GetState() != GameStateFinished {
+		playerID := engine.getCurrentActionPlayerID()
+		require.NotEmpty(t, playerID)
+		_, err := engine.ProcessAction(context.Background(), &GameAction{
+			PlayerID: playerID,
+			Type:     "poker_action",
+			Data:     map[string]interface{}{"action": string(ActionFold)},
+		})
+		require.NoError(t, err)
+	}
+}
+
+// bust takes all chips from the player at seat
+func bust(engine *TexasHoldemEngine, seat int) {
+	for _, player := range engine.players {
+		if player.Position == seat {
+			engine.getHoldemPlayer(player.ID).Chips = 0
+		}
+	}
+}
+
+func lastEventOfType(engine *TexasHoldemEngine, eventType string) *GameEvent {
+	events := engine.GetEvents()
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type == eventType {
+			return events[i]
+		}
+	}
+	return nil
+}
+
+func assertPositions(t *testing.T, engine *TexasHoldemEngine, button, smallBlind, bigBlind int) {
+	t.Helper()
+	assert.Equal(t, button, engine.dealerPos, "button seat")
+	assert.Equal(t, smallBlind, engine.smallBlindPos, "small blind seat")
+	assert.Equal(t, bigBlind, engine.bigBlindPos, "big blind seat")
+}
+
+func TestButtonMovesOneSeatEachHand(t *testing.T) {
+	engine := newButtonTable(t, 4)
+
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 0, 1, 2)
+	assert.Equal(t, "d", engine.getCurrentActionPlayerID(), "action starts left of the big blind")
+	foldHand(t, engine)
+
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 1, 2, 3)
+	assert.Equal(t, "a", engine.getCurrentActionPlayerID())
+	foldHand(t, engine)
+
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 2, 3, 0)
+}
+
+func TestBigBlindSkipsBustedSeat(t *testing.T) {
+	engine := newButtonTable(t, 5)
+	require.NoError(t, engine.Start())
+	foldHand(t, engine)
+
+	bust(engine, 3)
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 1, 2, 4)
+	assert.Empty(t, engine.getHoldemPlayer("d").Hand.Cards, "a busted player is not dealt in")
+	assert.Len(t, engine.getActivePlayers(), 4)
+}
+
+func TestDeadSmallBlindAndDeadButton(t *testing.T) {
+	engine := newButtonTable(t, 5)
+	require.NoError(t, engine.Start())
+	foldHand(t, engine)
+
+	// The big blind busts, so nobody owes the small blind next hand
+	bust(engine, 2)
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 1, noSeat, 3)
+	assert.Equal(t, 10, engine.pot, "only the big blind is posted")
+	assert.Equal(t, 10, engine.currentBet)
+	blinds := lastEventOfType(engine, "blinds_posted")
+	require.NotNil(t, blinds)
+	assert.Equal(t, true, blinds.Data["deadSmallBlind"])
+	assert.Nil(t, blinds.Data["smallBlind"])
+	foldHand(t, engine)
+
+	// The button then lands on the empty seat that had the dead small blind
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 2, 3, 4)
+	assert.Equal(t, "a", engine.getCurrentActionPlayerID())
+}
+
+func TestButtonGoingHeadsUp(t *testing.T) {
+	engine := newButtonTable(t, 3)
+	require.NoError(t, engine.Start())
+	foldHand(t, engine)
+
+	bust(engine, 0)
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 2, 2, 1)
+	assert.Equal(t, "c", engine.getCurrentActionPlayerID(), "heads up the button acts first preflop")
+	foldHand(t, engine)
+
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 1, 1, 2)
+}
+
+func TestStartNeedsTwoPlayersWithChips(t *testing.T) {
+	engine := newButtonTable(t, 3)
+	require.NoError(t, engine.Start())
+	foldHand(t, engine)
+
+	bust(engine, 0)
+	bust(engine, 1)
+	assert.Error(t, engine.Start())
+}
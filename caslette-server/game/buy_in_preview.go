@@ -0,0 +1,102 @@
+package game
+
+// BuyInBlocker is a reason a join would be refused
+type BuyInBlocker struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BuyInPreview describes what sitting down at a table with a given buy-in
+// would cost, so clients can confirm before joining. Cash table buy-ins carry
+// no fee; the house takes its share as rake from pots instead.
+type BuyInPreview struct {
+	TableID      string         `json:"table_id"`
+	Currency     TableCurrency  `json:"currency"`
+	Chips        int            `json:"chips"`         // Chips the seat would hold
+	MinBuyIn     int            `json:"min_buy_in"`    // Including any anti-ratholing minimum
+	MaxBuyIn     int            `json:"max_buy_in"`    // Zero when there is no maximum
+	DiamondCost  int64          `json:"diamond_cost"`  // Diamonds taken for the chips
+	Fee          int64          `json:"fee"`           // Diamonds charged on top of the chips
+	Total        int64          `json:"total"`         // Diamond cost plus fee
+	Balance      int64          `json:"balance"`       // Diamond balance before the join
+	BalanceAfter int64          `json:"balance_after"` // Diamond balance once the buy-in is taken
+	Blockers     []BuyInBlocker `json:"blockers"`
+	CanJoin      bool           `json:"can_join"`
+}
+
+// Block records a reason the join would be refused
+func (p *BuyInPreview) Block(code, message string) {
+	p.Blockers = append(p.Blockers, BuyInBlocker{Code: code, Message: message})
+	p.CanJoin = false
+}
+
+// SetBalance fills in the player's diamond balance before and after the
+// buy-in, blocking the join when the balance does not cover it
+func (p *BuyInPreview) SetBalance(balance int64) {
+	p.Balance = balance
+	p.BalanceAfter = balance - p.Total
+	if p.BalanceAfter < 0 {
+		p.Block("INSUFFICIENT_BALANCE", "Insufficient diamond balance")
+	}
+}
+
+// PreviewBuyIn works out what joining a table as a player with the given
+// buy-in would cost and lists every table rule that would refuse the seat,
+// without taking it. A zero amount previews the smallest buy-in allowed. The
+// caller fills in the balance, which lives outside the game package.
+func (tm *ActorTableManager) PreviewBuyIn(playerID, tableID string, amount int, password string) (*BuyInPreview, error) {
+	table, err := tm.GetTable(tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	minimum, maximum, _, _ := tm.ratholes.buyInRange(playerID, table)
+	preview := &BuyInPreview{
+		TableID:  table.ID,
+		Currency: table.GetCurrency(),
+		Chips:    amount,
+		MinBuyIn: minimum,
+		MaxBuyIn: maximum,
+		Blockers: []BuyInBlocker{},
+		CanJoin:  true,
+	}
+	if preview.Chips == 0 {
+		preview.Chips = minimum
+	}
+	if table.UsesDiamondLedger() {
+		preview.DiamondCost = int64(preview.Chips)
+	}
+	preview.Total = preview.DiamondCost + preview.Fee
+
+	if _, err := tm.ratholes.resolveBuyIn(playerID, table, amount); err != nil {
+		preview.blockWith(err)
+	}
+	if tm.passwordRequired(table, playerID) {
+		if password == "" {
+			preview.Block("PASSWORD_REQUIRED", "Table requires a password")
+		} else if !table.CheckPassword(password) {
+			preview.Block("INVALID_PASSWORD", "Incorrect password for private table")
+		}
+	}
+	if table.AwaitingApproval() && playerID != table.CreatedBy {
+		preview.Block("APPROVAL_PENDING", "Table is waiting for admin approval")
+	}
+	switch {
+	case table.IsPlayerAtTable(playerID):
+		preview.Block("PLAYER_ALREADY_AT_TABLE", "Player is already at this table")
+	case table.Status != TableStatusWaiting && table.Status != TableStatusPaused:
+		preview.Block("TABLE_NOT_JOINABLE", "Table is not in a joinable state")
+	case len(table.GetAvailableSlots()) == 0:
+		preview.Block("TABLE_FULL", "No available positions")
+	}
+	return preview, nil
+}
+
+// blockWith records a table error as a blocker
+func (p *BuyInPreview) blockWith(err error) {
+	if tableErr, ok := err.(*TableError); ok {
+		p.Block(tableErr.Code, tableErr.Message)
+		return
+	}
+	p.Block("JOIN_REFUSED", err.Error())
+}
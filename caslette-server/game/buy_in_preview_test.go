@@ -0,0 +1,100 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockerCodes lists the codes of a preview's blockers
+func blockerCodes(preview *BuyInPreview) []string {
+	codes := make([]string, 0, len(preview.Blockers))
+	for _, blocker := range preview.Blockers {
+		codes = append(codes, blocker.Code)
+	}
+	return codes
+}
+
+func TestPreviewBuyInCostsAndBalance(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	table, _, _ := newRatholeTables(t, manager)
+
+	preview, err := manager.PreviewBuyIn("p1", table.ID, 0, "")
+	require.NoError(t, err)
+	assert.True(t, preview.CanJoin)
+	assert.Empty(t, preview.Blockers)
+	assert.Equal(t, CurrencyDiamonds, preview.Currency)
+	assert.Equal(t, 1000, preview.Chips, "no amount previews the minimum")
+	assert.Equal(t, 2000, preview.MaxBuyIn)
+	assert.Equal(t, int64(1000), preview.DiamondCost)
+	assert.Equal(t, int64(0), preview.Fee)
+	assert.Equal(t, int64(1000), preview.Total)
+
+	preview.SetBalance(1500)
+	assert.Equal(t, int64(500), preview.BalanceAfter)
+	assert.True(t, preview.CanJoin)
+	preview.SetBalance(900)
+	assert.Equal(t, int64(-100), preview.BalanceAfter)
+	assert.False(t, preview.CanJoin)
+	assert.Equal(t, []string{"INSUFFICIENT_BALANCE"}, blockerCodes(preview))
+
+	_, err = manager.PreviewBuyIn("p1", "missing", 0, "")
+	assert.Equal(t, ErrTableNotFound, err)
+}
+
+func TestPreviewBuyInListsBlockers(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	first, second, _ := newRatholeTables(t, manager)
+	ctx := context.Background()
+
+	preview, err := manager.PreviewBuyIn("p1", first.ID, 2500, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BUY_IN_TOO_LARGE"}, blockerCodes(preview))
+	assert.Equal(t, int64(2500), preview.DiamondCost, "the cost is shown for the amount asked for")
+
+	// A returning player sees the stack they must bring back
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 1800))
+	preview, err = manager.PreviewBuyIn("p1", first.ID, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PLAYER_ALREADY_AT_TABLE"}, blockerCodes(preview))
+	require.NoError(t, manager.LeaveTable(ctx, &TableLeaveRequest{TableID: first.ID, PlayerID: "p1"}))
+
+	preview, err = manager.PreviewBuyIn("p1", second.ID, 1000, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1800, preview.MinBuyIn)
+	assert.Equal(t, []string{"RATHOLE_MINIMUM"}, blockerCodes(preview))
+	preview, err = manager.PreviewBuyIn("p1", second.ID, 0, "")
+	require.NoError(t, err)
+	assert.True(t, preview.CanJoin)
+	assert.Equal(t, int64(1800), preview.DiamondCost)
+	assert.False(t, second.IsPlayerAtTable("p1"), "previews never take a seat")
+}
+
+func TestPreviewBuyInChecksPasswordAndPracticeTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	settings := DefaultTableSettings()
+	settings.Private = true
+	settings.Password = "secret"
+	private := newBalancingTable(t, manager, "private", settings, 0)
+
+	preview, err := manager.PreviewBuyIn("p1", private.ID, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PASSWORD_REQUIRED"}, blockerCodes(preview))
+	preview, err = manager.PreviewBuyIn("p1", private.ID, 0, "wrong")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"INVALID_PASSWORD"}, blockerCodes(preview))
+	preview, err = manager.PreviewBuyIn("p1", private.ID, 0, "secret")
+	require.NoError(t, err)
+	assert.True(t, preview.CanJoin)
+
+	settings = DefaultTableSettings()
+	settings.Currency = CurrencyPlayMoney
+	practice := newBalancingTable(t, manager, "practice", settings, 0)
+	preview, err = manager.PreviewBuyIn("p1", practice.ID, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), preview.DiamondCost, "play money never touches diamonds")
+	preview.SetBalance(0)
+	assert.True(t, preview.CanJoin)
+}
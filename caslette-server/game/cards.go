@@ -1,10 +1,9 @@
 package game
 
 import (
+	"encoding/hex"
 	"fmt"
-	"math/rand"
 	"sort"
-	"time"
 )
 
 // Suit represents a playing card suit
@@ -75,33 +74,49 @@ func NewCard(suit Suit, rank Rank) Card {
 
 // Deck represents a deck of playing cards
 type Deck struct {
-	cards []Card
-	rng   *rand.Rand
+	cards    []Card
+	seed     []byte   // Secret behind the last shuffle
+	shuffler Shuffler // Source of shuffle seeds; nil draws from crypto/rand
 }
 
 // NewDeck creates a new standard 52-card deck
 func NewDeck() *Deck {
-	deck := &Deck{
-		cards: make([]Card, 0, 52),
-		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
-	}
+	return &Deck{cards: standardDeck()}
+}
 
-	// Create all 52 cards
-	suits := []Suit{Hearts, Diamonds, Clubs, Spades}
-	for _, suit := range suits {
-		for rank := Two; rank <= Ace; rank++ {
-			deck.cards = append(deck.cards, Card{Suit: suit, Rank: rank})
-		}
-	}
+// NewDeckWithShuffler creates a standard deck whose shuffles draw their
+// seeds from shuffler
+func NewDeckWithShuffler(shuffler Shuffler) *Deck {
+	return &Deck{cards: standardDeck(), shuffler: shuffler}
+}
 
-	return deck
+// SetShuffler changes where the deck's shuffles draw their seeds; nil goes
+// back to crypto/rand
+func (d *Deck) SetShuffler(shuffler Shuffler) {
+	d.shuffler = shuffler
 }
 
-// Shuffle shuffles the deck
+// Shuffle shuffles the deck with a Fisher-Yates shuffle driven by a fresh
+// seed from the deck's Shuffler, or crypto/rand without one
 func (d *Deck) Shuffle() {
-	d.rng.Shuffle(len(d.cards), func(i, j int) {
-		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
-	})
+	if d.shuffler != nil {
+		d.seed = d.shuffler.NextSeed()
+	} else {
+		d.seed = newShuffleSeed()
+	}
+	shuffleCards(d.cards, d.seed)
+}
+
+// Commitment returns the hash of the last shuffle's seed, which can be
+// published before any card is dealt
+func (d *Deck) Commitment() string {
+	return ShuffleCommitment(d.seed)
+}
+
+// RevealSeed returns the last shuffle's seed, hex-encoded; it must not be
+// shown until the hand is over
+func (d *Deck) RevealSeed() string {
+	return hex.EncodeToString(d.seed)
 }
 
 // Deal deals a card from the top of the deck
@@ -140,13 +155,7 @@ func (d *Deck) Remaining() int {
 
 // Reset resets the deck to a full 52-card deck and shuffles it
 func (d *Deck) Reset() {
-	d.cards = make([]Card, 0, 52)
-	suits := []Suit{Hearts, Diamonds, Clubs, Spades}
-	for _, suit := range suits {
-		for rank := Two; rank <= Ace; rank++ {
-			d.cards = append(d.cards, Card{Suit: suit, Rank: rank})
-		}
-	}
+	d.cards = standardDeck()
 	d.Shuffle()
 }
 
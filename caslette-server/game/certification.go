@@ -0,0 +1,99 @@
+package game
+
+import (
+	"log"
+	"time"
+)
+
+// CertifiedHandResult is the kind of certification entry made for each
+// completed hand
+const CertifiedHandResult = "hand_result"
+
+// CertifiedOutcome is a game outcome entered into the certification log
+type CertifiedOutcome struct {
+	Kind       string      `json:"kind"`
+	Subject    string      `json:"subject"` // What the outcome is of, such as the hand ID
+	TableID    string      `json:"table_id"`
+	Outcome    interface{} `json:"outcome"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// HandOutcome is the certified result of a hand: who was dealt in, the
+// board, the pots and what each player won or lost. Only hole cards shown
+// at showdown are included.
+type HandOutcome struct {
+	HandID     string            `json:"hand_id"`
+	TableID    string            `json:"table_id"`
+	GameType   GameType          `json:"game_type"`
+	HandNumber int               `json:"hand_number"`
+	Players    []string          `json:"players"`
+	Shown      map[string][]Card `json:"shown,omitempty"` // Hole cards turned face up, by player
+	Board      []Card            `json:"board"`
+	Pots       []Pot             `json:"pots"`
+	Winners    []string          `json:"winners"`
+	Results    []HandResult      `json:"results"`
+	TotalPot   int               `json:"total_pot"`
+	Rake       int               `json:"rake"`
+	EndedAt    time.Time         `json:"ended_at"`
+}
+
+// CertificationLog appends game outcomes to a tamper-evident log
+type CertificationLog interface {
+	Certify(outcome CertifiedOutcome) error
+}
+
+// SetCertificationLog sets where game outcomes are certified; nil certifies none
+func (tm *ActorTableManager) SetCertificationLog(certification CertificationLog) {
+	tm.mu.Lock()
+	tm.certification = certification
+	tm.mu.Unlock()
+}
+
+// NewHandOutcome builds the certified result of a completed hand
+func NewHandOutcome(record HandRecord) HandOutcome {
+	outcome := HandOutcome{
+		HandID:     record.HandID,
+		TableID:    record.TableID,
+		GameType:   record.GameType,
+		HandNumber: record.HandNumber,
+		Players:    make([]string, 0, len(record.Players)),
+		Board:      record.Board,
+		Pots:       record.Pots,
+		Winners:    record.Winners,
+		Results:    record.Results,
+		TotalPot:   record.TotalPot,
+		Rake:       record.Rake,
+		EndedAt:    record.EndedAt,
+	}
+	for _, player := range record.Players {
+		outcome.Players = append(outcome.Players, player.PlayerID)
+		if player.Shown {
+			if outcome.Shown == nil {
+				outcome.Shown = make(map[string][]Card)
+			}
+			outcome.Shown[player.PlayerID] = player.HoleCards
+		}
+	}
+	return outcome
+}
+
+// certifyHand enters a completed hand's result into the certification log
+func (tm *ActorTableManager) certifyHand(table *GameTable, record HandRecord) {
+	tm.mu.RLock()
+	certification := tm.certification
+	tm.mu.RUnlock()
+	if certification == nil {
+		return
+	}
+
+	err := certification.Certify(CertifiedOutcome{
+		Kind:       CertifiedHandResult,
+		Subject:    record.HandID,
+		TableID:    table.ID,
+		Outcome:    NewHandOutcome(record),
+		OccurredAt: record.EndedAt,
+	})
+	if err != nil {
+		log.Printf("Table %s: failed to certify hand %d: %v", table.ID, record.HandNumber, err)
+	}
+}
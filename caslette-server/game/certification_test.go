@@ -0,0 +1,44 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type certifiedOutcomes struct {
+	outcomes []CertifiedOutcome
+}
+
+func (c *certifiedOutcomes) Certify(outcome CertifiedOutcome) error {
+	c.outcomes = append(c.outcomes, outcome)
+	return nil
+}
+
+func TestManagerCertifiesHandResults(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	certification := &certifiedOutcomes{}
+	manager.SetCertificationLog(certification)
+
+	engine := newRiverTable(t)
+	table := &GameTable{ID: "certified-table", GameType: GameTypeTexasHoldem, GameEngine: engine}
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	manager.recordHandHistory(table)
+
+	require.Len(t, certification.outcomes, 1)
+	certified := certification.outcomes[0]
+	assert.Equal(t, CertifiedHandResult, certified.Kind)
+	assert.Equal(t, engine.handID, certified.Subject)
+	assert.Equal(t, "certified-table", certified.TableID)
+
+	outcome := certified.Outcome.(HandOutcome)
+	assert.Equal(t, certified.OccurredAt, outcome.EndedAt)
+	assert.Equal(t, []string{"1", "2"}, outcome.Players)
+	assert.Equal(t, []string{"1"}, outcome.Winners)
+	assert.Len(t, outcome.Board, 5)
+	assert.Len(t, outcome.Results, 2)
+	assert.Len(t, outcome.Shown["1"], 2, "the winner's shown hand is certified")
+	assert.NotContains(t, outcome.Shown, "2", "mucked hole cards stay private")
+}
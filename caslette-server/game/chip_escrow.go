@@ -0,0 +1,207 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ChipEscrow holds the buy-ins taken for each seated player. The chips in
+// play at a table, stacks plus pot, must always add up to its escrowed total.
+type ChipEscrow struct {
+	mu     sync.Mutex
+	tables map[string]map[string]int64 // Table ID -> player ID -> escrowed amount
+	ledger map[string]bool             // Tables whose escrow was paid for in diamonds
+	// Table ID -> players whose buy-in was taken, and is paid back, by
+	// someone other than the table manager, such as the heads-up queue
+	prepaid map[string]map[string]bool
+}
+
+// NewChipEscrow creates an empty escrow
+func NewChipEscrow() *ChipEscrow {
+	return &ChipEscrow{
+		tables:  make(map[string]map[string]int64),
+		ledger:  make(map[string]bool),
+		prepaid: make(map[string]map[string]bool),
+	}
+}
+
+// Deposit escrows a buy-in or top-up for a player at a table
+func (e *ChipEscrow) Deposit(tableID, playerID string, amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("escrow deposit must be positive")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	players, exists := e.tables[tableID]
+	if !exists {
+		players = make(map[string]int64)
+		e.tables[tableID] = players
+	}
+	players[playerID] += amount
+	return nil
+}
+
+// Release removes a player's escrow when they cash out, returning the amount held
+func (e *ChipEscrow) Release(tableID, playerID string) int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	players, exists := e.tables[tableID]
+	if !exists {
+		return 0
+	}
+	amount := players[playerID]
+	delete(players, playerID)
+	e.forget(tableID, playerID)
+	return amount
+}
+
+// CashOut releases the escrow of a player leaving with stack chips and
+// returns what they are owed: their stack, up to the table's escrow. Chips
+// they won come out of the other players' escrow and chips they lost go to
+// it, in proportion to what each holds, so the escrow still backs the chips
+// left in play. prepaid reports a buy-in someone else pays back.
+func (e *ChipEscrow) CashOut(tableID, playerID string, stack int64) (owed int64, prepaid bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	players, exists := e.tables[tableID]
+	if !exists {
+		return 0, false
+	}
+	held := players[playerID]
+	delete(players, playerID)
+	prepaid = e.prepaid[tableID][playerID]
+
+	weights := players
+	var others int64
+	for _, amount := range players {
+		others += amount
+	}
+	if others == 0 {
+		weights = make(map[string]int64, len(players))
+		for id := range players {
+			weights[id] = 1
+		}
+	}
+	owed = stack
+	if owed > held+others {
+		owed = held + others
+	}
+	if owed < 0 {
+		owed = 0
+	}
+	if won := owed - held; won > 0 {
+		for id, share := range allocateChips(won, players) {
+			players[id] -= share
+		}
+	} else if lost := held - owed; lost > 0 && len(players) > 0 {
+		for id, share := range allocateChips(lost, weights) {
+			players[id] += share
+		}
+	}
+	e.forget(tableID, playerID)
+	return owed, prepaid
+}
+
+// MarkPrepaid records that a seated player's buy-in was taken by someone
+// other than the table manager, who pays it back when the player leaves
+func (e *ChipEscrow) MarkPrepaid(tableID, playerID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.prepaid[tableID] == nil {
+		e.prepaid[tableID] = make(map[string]bool)
+	}
+	e.prepaid[tableID][playerID] = true
+}
+
+// forget drops what is kept about a player who left, and about the table
+// once nobody holds escrow there; the caller holds e.mu
+func (e *ChipEscrow) forget(tableID, playerID string) {
+	delete(e.prepaid[tableID], playerID)
+	if len(e.tables[tableID]) == 0 {
+		delete(e.tables, tableID)
+		delete(e.ledger, tableID)
+		delete(e.prepaid, tableID)
+	}
+}
+
+// SetLedgerBacked records whether a table's buy-ins were paid for in
+// diamonds, and so must be refunded to the ledger if the table is lost
+func (e *ChipEscrow) SetLedgerBacked(tableID string, backed bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if backed {
+		e.ledger[tableID] = true
+	} else {
+		delete(e.ledger, tableID)
+	}
+}
+
+// LedgerBacked reports whether a table's escrow was paid for in diamonds
+func (e *ChipEscrow) LedgerBacked(tableID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ledger[tableID]
+}
+
+// Withdraw takes chips that left play, such as rake, out of a table's
+// escrow. The amount is spread over the players in proportion to what each
+// has escrowed; the amount actually withdrawn is returned.
+func (e *ChipEscrow) Withdraw(tableID string, amount int64) int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	players := e.tables[tableID]
+	var total int64
+	for _, escrowed := range players {
+		total += escrowed
+	}
+	if amount > total {
+		amount = total
+	}
+	for playerID, share := range allocateChips(amount, players) {
+		players[playerID] -= share
+	}
+	return amount
+}
+
+// Balances returns a copy of the escrowed amount per player at a table
+func (e *ChipEscrow) Balances(tableID string) map[string]int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	balances := make(map[string]int64, len(e.tables[tableID]))
+	for playerID, amount := range e.tables[tableID] {
+		balances[playerID] = amount
+	}
+	return balances
+}
+
+// Total returns the escrowed amount for a table
+func (e *ChipEscrow) Total(tableID string) int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var total int64
+	for _, amount := range e.tables[tableID] {
+		total += amount
+	}
+	return total
+}
+
+// Tables returns the IDs of tables holding escrow, sorted
+func (e *ChipEscrow) Tables() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ids := make([]string, 0, len(e.tables))
+	for id := range e.tables {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,398 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Chip reconciliation defaults
+const (
+	DefaultReconcileInterval = 5 * time.Minute
+	ChipReconcileAuditAction = "chip_reconciliation"
+	reconcileTimeout         = 5 * time.Second // How long a table's actor may take to answer a check
+)
+
+// ChipHolder is implemented by engines that track chip stacks
+type ChipHolder interface {
+	ChipCounts() (stacks map[string]int, pot int)
+	AdjustChips(playerID string, delta int) error
+	RepairChips(deltas map[string]int) error // Refused while a hand is being played
+}
+
+// AuditLogger records reconciliation findings; SecurityAuditor satisfies it
+type AuditLogger interface {
+	LogAction(userID, tableID, action, result, details string)
+}
+
+// ChipRepair is a stack adjustment made to restore a table to its escrow
+type ChipRepair struct {
+	PlayerID string `json:"player_id"`
+	Before   int64  `json:"before"`
+	After    int64  `json:"after"`
+}
+
+// ChipReconciliation compares a table's chips in play against its escrow.
+// Delta is stacks plus pot minus escrowed: negative means chips were lost,
+// positive means chips appeared that no buy-in paid for.
+type ChipReconciliation struct {
+	TableID           string       `json:"table_id"`
+	Escrowed          int64        `json:"escrowed"`
+	Stacks            int64        `json:"stacks"`
+	Pot               int64        `json:"pot"`
+	Delta             int64        `json:"delta"`
+	Balanced          bool         `json:"balanced"`
+	TableMissing      bool         `json:"table_missing,omitempty"`       // Escrow held for a table that no longer exists
+	Unescrowed        []string     `json:"unescrowed,omitempty"`          // Players in the engine without a buy-in
+	MissingFromEngine []string     `json:"missing_from_engine,omitempty"` // Escrowed players the engine lost
+	Repairs           []ChipRepair `json:"repairs,omitempty"`
+	RepairSkipped     string       `json:"repair_skipped,omitempty"`
+	CheckedAt         time.Time    `json:"checked_at"`
+}
+
+// ChipReconciler checks that every table's chips in play match the buy-ins
+// escrowed for it, flagging discrepancies left by crashes or bugs and
+// optionally repairing them between hands
+type ChipReconciler struct {
+	tableManager *ActorTableManager
+	escrow       *ChipEscrow
+	auditor      AuditLogger
+	now          func() time.Time
+
+	mu   sync.Mutex
+	stop chan struct{}
+	last []ChipReconciliation
+}
+
+// NewChipReconciler creates a reconciler for the manager's tables and escrow
+func NewChipReconciler(tableManager *ActorTableManager, auditor AuditLogger) *ChipReconciler {
+	return &ChipReconciler{
+		tableManager: tableManager,
+		escrow:       tableManager.Escrow(),
+		auditor:      auditor,
+		now:          time.Now,
+	}
+}
+
+// Reconcile checks every table holding chips or escrow. With repair set,
+// balanced seating but unbalanced totals are corrected on tables between
+// hands; everything else is only flagged for review.
+func (r *ChipReconciler) Reconcile(repair bool) []ChipReconciliation {
+	tables := make(map[string]*GameTable)
+	for _, table := range r.tableManager.GetTables() {
+		tables[table.ID] = table
+	}
+
+	ids := r.escrow.Tables()
+	for id := range tables {
+		if r.escrow.Total(id) == 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	results := make([]ChipReconciliation, 0, len(ids))
+	for _, id := range ids {
+		if result, checked := r.reconcileTable(id, tables[id], repair); checked {
+			results = append(results, result)
+		}
+	}
+
+	r.mu.Lock()
+	r.last = results
+	r.mu.Unlock()
+	return results
+}
+
+// LastResults returns the findings of the most recent run
+func (r *ChipReconciler) LastResults() []ChipReconciliation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ChipReconciliation(nil), r.last...)
+}
+
+// reconcileTable checks one table. Tables with neither escrow nor chip
+// stacks are not reported.
+func (r *ChipReconciler) reconcileTable(tableID string, table *GameTable, repair bool) (ChipReconciliation, bool) {
+	escrowed := r.escrow.Balances(tableID)
+	result := ChipReconciliation{TableID: tableID, CheckedAt: r.now()}
+	for _, amount := range escrowed {
+		result.Escrowed += amount
+	}
+
+	r.tableManager.mu.RLock()
+	actor := r.tableManager.actors[tableID]
+	r.tableManager.mu.RUnlock()
+	if table == nil || actor == nil {
+		if len(escrowed) == 0 {
+			return result, false
+		}
+		// Escrow without a table: the chips it backed were lost with it
+		result.TableMissing = true
+		result.Delta = -result.Escrowed
+		result.MissingFromEngine = sortedKeys(escrowed)
+		result.RepairSkipped = "no engine holds these chips; refund the escrow manually"
+		r.flag(result)
+		return result, true
+	}
+
+	// The table's actor counts and repairs the chips, so seats do not change
+	// under the check
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+	cmd := &ReconcileChipsCommand{
+		Reconciler: r,
+		Result:     result,
+		Escrowed:   escrowed,
+		Repair:     repair,
+		Response:   make(chan interface{}, 1),
+	}
+	select {
+	case actor.commands <- cmd:
+	case <-ctx.Done():
+		log.Printf("ChipReconciler: table %s did not take the check: %v", tableID, ctx.Err())
+		return result, false
+	}
+	select {
+	case answer := <-cmd.Response:
+		checked, _ := answer.(reconciledTable)
+		return checked.result, checked.report
+	case <-ctx.Done():
+		log.Printf("ChipReconciler: table %s did not answer the check: %v", tableID, ctx.Err())
+		return result, false
+	}
+}
+
+// ReconcileChipsCommand compares a table's chips against its escrow and,
+// with Repair set, corrects the stacks between hands
+type ReconcileChipsCommand struct {
+	Reconciler *ChipReconciler
+	Result     ChipReconciliation // Filled in with the escrow
+	Escrowed   map[string]int64
+	Repair     bool
+	Response   chan interface{}
+}
+
+// reconciledTable is a ReconcileChipsCommand's answer; report is false for
+// tables with neither escrow nor chips
+type reconciledTable struct {
+	result ChipReconciliation
+	report bool
+}
+
+func (cmd *ReconcileChipsCommand) Execute(table *GameTable) interface{} {
+	result, report := cmd.Reconciler.checkTable(table, cmd.Result, cmd.Escrowed, cmd.Repair)
+	return reconciledTable{result: result, report: report}
+}
+
+// checkTable counts a live table's chips against its escrow; it runs on the
+// table's actor
+func (r *ChipReconciler) checkTable(table *GameTable, result ChipReconciliation, escrowed map[string]int64, repair bool) (ChipReconciliation, bool) {
+	holder, _ := table.GameEngine.(ChipHolder)
+	stacks, pot := tableChips(table, holder)
+	for playerID, chips := range stacks {
+		result.Stacks += int64(chips)
+		if _, ok := escrowed[playerID]; !ok {
+			result.Unescrowed = append(result.Unescrowed, playerID)
+		}
+	}
+	for playerID := range escrowed {
+		if _, ok := stacks[playerID]; !ok {
+			result.MissingFromEngine = append(result.MissingFromEngine, playerID)
+		}
+	}
+	sort.Strings(result.Unescrowed)
+	sort.Strings(result.MissingFromEngine)
+	result.Pot = int64(pot)
+	result.Delta = result.Stacks + result.Pot - result.Escrowed
+	result.Balanced = result.Delta == 0 && len(result.Unescrowed) == 0 && len(result.MissingFromEngine) == 0
+
+	if len(stacks) == 0 && len(escrowed) == 0 {
+		return result, false
+	}
+	if result.Balanced {
+		return result, true
+	}
+
+	r.flag(result)
+	if !repair {
+		return result, true
+	}
+
+	switch {
+	case holder == nil:
+		result.RepairSkipped = "no engine holds these chips; refund the escrow manually"
+	case len(result.Unescrowed) > 0 || len(result.MissingFromEngine) > 0:
+		result.RepairSkipped = "seated players do not match the escrow"
+	case table.GameEngine.GetState() == GameStateInProgress || table.GameEngine.GetState() == GameStatePaused || pot > 0:
+		result.RepairSkipped = "hand in progress"
+	default:
+		r.repairStacks(&result, holder, stacks, escrowed)
+	}
+	return result, true
+}
+
+// tableChips returns the stack of everyone holding chips at a table: the
+// engine's players plus seated players it does not deal to yet, who hold
+// the stack they sat down with
+func tableChips(table *GameTable, holder ChipHolder) (map[string]int, int) {
+	stacks := make(map[string]int)
+	pot := 0
+	if holder != nil {
+		stacks, pot = holder.ChipCounts()
+	}
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == "" {
+			continue
+		}
+		if _, dealt := stacks[slot.PlayerID]; !dealt {
+			stacks[slot.PlayerID] = slot.Chips
+		}
+	}
+	return stacks, pot
+}
+
+// repairStacks spreads the delta over the seated players: a shortfall is
+// credited in proportion to each player's escrow, a surplus is removed in
+// proportion to each stack. Every adjustment is written to the audit trail.
+func (r *ChipReconciler) repairStacks(result *ChipReconciliation, holder ChipHolder, stacks map[string]int, escrowed map[string]int64) {
+	weights := escrowed
+	if result.Delta > 0 {
+		weights = make(map[string]int64, len(stacks))
+		for playerID, chips := range stacks {
+			weights[playerID] = int64(chips)
+		}
+	}
+
+	shares := allocateChips(-result.Delta, weights)
+	deltas := make(map[string]int, len(shares))
+	for playerID, delta := range shares {
+		if delta != 0 {
+			deltas[playerID] = int(delta)
+		}
+	}
+	// The engine applies every adjustment or none, refusing once a hand is dealt
+	if err := holder.RepairChips(deltas); err != nil {
+		log.Printf("ChipReconciler: failed to repair table %s: %v", result.TableID, err)
+		for _, playerID := range sortedKeys(shares) {
+			if shares[playerID] != 0 {
+				r.audit(playerID, result.TableID, "repair_failed", err.Error())
+			}
+		}
+		result.RepairSkipped = "repair failed; see audit trail"
+		return
+	}
+	for _, playerID := range sortedKeys(shares) {
+		delta := shares[playerID]
+		if delta == 0 {
+			continue
+		}
+		before := int64(stacks[playerID])
+		repair := ChipRepair{PlayerID: playerID, Before: before, After: before + delta}
+		result.Repairs = append(result.Repairs, repair)
+		result.Stacks += delta
+		result.Delta += delta
+		r.audit(playerID, result.TableID, "adjusted",
+			fmt.Sprintf("stack %d -> %d to match escrow of %d", repair.Before, repair.After, result.Escrowed))
+	}
+	result.Balanced = result.Delta == 0
+}
+
+// allocateChips splits amount between players by weight, flooring each share
+// and giving the remainder to the heaviest player (lowest ID on ties)
+func allocateChips(amount int64, weights map[string]int64) map[string]int64 {
+	shares := make(map[string]int64, len(weights))
+	var totalWeight int64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	if totalWeight == 0 || amount == 0 {
+		return shares
+	}
+
+	heaviest := ""
+	var allocated int64
+	for _, playerID := range sortedKeys(weights) {
+		share := amount * weights[playerID] / totalWeight
+		shares[playerID] = share
+		allocated += share
+		if heaviest == "" || weights[playerID] > weights[heaviest] {
+			heaviest = playerID
+		}
+	}
+	shares[heaviest] += amount - allocated
+	return shares
+}
+
+// flag records an unbalanced table in the log and audit trail
+func (r *ChipReconciler) flag(result ChipReconciliation) {
+	details := fmt.Sprintf("escrowed %d, stacks %d, pot %d, delta %d", result.Escrowed, result.Stacks, result.Pot, result.Delta)
+	if len(result.Unescrowed) > 0 {
+		details += fmt.Sprintf(", unescrowed players %v", result.Unescrowed)
+	}
+	if len(result.MissingFromEngine) > 0 {
+		details += fmt.Sprintf(", players missing from engine %v", result.MissingFromEngine)
+	}
+	log.Printf("ChipReconciler: table %s out of balance: %s", result.TableID, details)
+	r.audit("system", result.TableID, "discrepancy", details)
+}
+
+// audit writes a reconciliation entry when an auditor is configured
+func (r *ChipReconciler) audit(userID, tableID, outcome, details string) {
+	if r.auditor != nil {
+		r.auditor.LogAction(userID, tableID, ChipReconcileAuditAction, outcome, details)
+	}
+}
+
+// Start reconciles periodically without repairing, so discrepancies surface
+// for review even when nobody asks
+func (r *ChipReconciler) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReconcileInterval
+	}
+
+	r.mu.Lock()
+	if r.stop != nil {
+		r.mu.Unlock()
+		return
+	}
+	r.stop = make(chan struct{})
+	stop := r.stop
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.Reconcile(false)
+			}
+		}
+	}()
+}
+
+// Stop halts periodic reconciliation
+func (r *ChipReconciler) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+// sortedKeys returns a map's player IDs in order
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
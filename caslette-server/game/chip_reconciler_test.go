@@ -0,0 +1,195 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEscrowedTable creates a table whose engine seats the given stacks
+func newEscrowedTable(t *testing.T, manager *ActorTableManager, name string, stacks map[string]int) *GameTable {
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: name, GameType: GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: DefaultTableSettings(),
+	})
+	require.NoError(t, err)
+
+	engine := NewTexasHoldemEngine(table.ID)
+	for playerID, chips := range stacks {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Data: map[string]interface{}{"chips": chips}}))
+	}
+	table.GameEngine = engine
+	return table
+}
+
+func TestChipReconcilerBalancedTable(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	auditor := NewSecurityAuditor()
+	table := newEscrowedTable(t, manager, "balanced", map[string]int{"p1": 1500, "p2": 500})
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p1", 1000))
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p2", 1000))
+
+	results := NewChipReconciler(manager, auditor).Reconcile(false)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Balanced)
+	assert.Equal(t, int64(2000), results[0].Stacks)
+	assert.Empty(t, auditor.GetAuditLogs(0))
+}
+
+func TestChipReconcilerFlagsWithoutRepairing(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	auditor := NewSecurityAuditor()
+	table := newEscrowedTable(t, manager, "short", map[string]int{"p1": 900, "p2": 1000})
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p1", 1000))
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p2", 1000))
+
+	results := NewChipReconciler(manager, auditor).Reconcile(false)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Balanced)
+	assert.Equal(t, int64(-100), results[0].Delta)
+	assert.Empty(t, results[0].Repairs)
+
+	logs := auditor.GetAuditLogs(0)
+	require.Len(t, logs, 1)
+	assert.Equal(t, ChipReconcileAuditAction, logs[0].Action)
+	assert.Equal(t, "discrepancy", logs[0].Result)
+
+	stacks, _ := table.GameEngine.(ChipHolder).ChipCounts()
+	assert.Equal(t, 900, stacks["p1"])
+}
+
+func TestChipReconcilerRepairsShortfallByEscrowShare(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	auditor := NewSecurityAuditor()
+	table := newEscrowedTable(t, manager, "repair", map[string]int{"p1": 1000, "p2": 1899})
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p1", 1000))
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p2", 2000))
+
+	results := NewChipReconciler(manager, auditor).Reconcile(true)
+	require.Len(t, results, 1)
+	result := results[0]
+	assert.True(t, result.Balanced)
+	assert.Equal(t, int64(0), result.Delta)
+	require.Len(t, result.Repairs, 2)
+
+	// 101 missing chips split 1:2, the odd chip going to the larger buy-in
+	stacks, _ := table.GameEngine.(ChipHolder).ChipCounts()
+	assert.Equal(t, 1033, stacks["p1"])
+	assert.Equal(t, 1967, stacks["p2"])
+
+	adjusted := 0
+	for _, entry := range auditor.GetAuditLogs(0) {
+		if entry.Result == "adjusted" {
+			adjusted++
+		}
+	}
+	assert.Equal(t, 2, adjusted)
+}
+
+func TestChipReconcilerRemovesSurplus(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	table := newEscrowedTable(t, manager, "surplus", map[string]int{"p1": 1500, "p2": 700})
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p1", 1000))
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p2", 1000))
+
+	results := NewChipReconciler(manager, nil).Reconcile(true)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Balanced)
+
+	stacks, pot := table.GameEngine.(ChipHolder).ChipCounts()
+	assert.Equal(t, int64(2000), int64(stacks["p1"]+stacks["p2"]+pot))
+}
+
+func TestChipReconcilerSkipsRepairMidHand(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	table := newEscrowedTable(t, manager, "in_hand", map[string]int{"p1": 1000, "p2": 1000})
+	require.NoError(t, table.GameEngine.Start())
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p1", 1000))
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p2", 1100))
+
+	results := NewChipReconciler(manager, nil).Reconcile(true)
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(-100), results[0].Delta)
+	assert.Equal(t, "hand in progress", results[0].RepairSkipped)
+	assert.Empty(t, results[0].Repairs)
+}
+
+func TestRepairChipsOnlyBetweenHandsAndAllOrNone(t *testing.T) {
+	engine := NewTexasHoldemEngine("repair")
+	for _, playerID := range []string{"p1", "p2"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Data: map[string]interface{}{"chips": 1000}}))
+	}
+	assert.Error(t, engine.RepairChips(map[string]int{"p1": 50, "p2": -1001}), "no stack may go negative")
+	stacks, _ := engine.ChipCounts()
+	assert.Equal(t, map[string]int{"p1": 1000, "p2": 1000}, stacks, "a refused repair changes nothing")
+
+	require.NoError(t, engine.Start())
+	assert.Error(t, engine.RepairChips(map[string]int{"p1": 50}), "stacks are not repaired while chips are in the pot")
+}
+
+func TestChipReconcilerFlagsSeatingMismatchAndOrphanedEscrow(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	table := newEscrowedTable(t, manager, "mismatch", map[string]int{"p1": 1000, "p3": 1000})
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p1", 1000))
+	require.NoError(t, manager.Escrow().Deposit(table.ID, "p2", 1000))
+	require.NoError(t, manager.Escrow().Deposit("gone_table", "p9", 500))
+
+	results := NewChipReconciler(manager, nil).Reconcile(true)
+	require.Len(t, results, 2)
+
+	byTable := map[string]ChipReconciliation{results[0].TableID: results[0], results[1].TableID: results[1]}
+	orphaned := byTable["gone_table"]
+	assert.True(t, orphaned.TableMissing)
+	assert.Equal(t, int64(-500), orphaned.Delta)
+	assert.Equal(t, []string{"p9"}, orphaned.MissingFromEngine)
+
+	mismatch := byTable[table.ID]
+	assert.Equal(t, []string{"p3"}, mismatch.Unescrowed)
+	assert.Equal(t, []string{"p2"}, mismatch.MissingFromEngine)
+	assert.Equal(t, "seated players do not match the escrow", mismatch.RepairSkipped)
+}
+
+func TestTexasHoldemFoldCreditsPotToWinner(t *testing.T) {
+	engine := NewTexasHoldemEngine("fold_win")
+	require.NoError(t, engine.AddPlayer(&Player{ID: "p1", Name: "p1", Position: 1, Data: map[string]interface{}{"chips": 1000}}))
+	require.NoError(t, engine.AddPlayer(&Player{ID: "p2", Name: "p2", Position: 2, Data: map[string]interface{}{"chips": 1000}}))
+	require.NoError(t, engine.Start())
+
+	_, err := engine.ProcessAction(context.Background(), &GameAction{Type: "texas_holdem_action", PlayerID: engine.GetCurrentPlayerID(), Data: map[string]interface{}{"action": "fold"}})
+	require.NoError(t, err)
+
+	stacks, pot := engine.ChipCounts()
+	assert.Equal(t, 0, pot)
+	assert.Equal(t, 2000, stacks["p1"]+stacks["p2"])
+}
+
+func TestChipReconcilerBalancesRealSeating(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	reconciler := NewChipReconciler(manager, NewSecurityAuditor())
+	first := newBalancingTable(t, manager, "first", DefaultTableSettings(), 0)
+	second := newBalancingTable(t, manager, "second", DefaultTableSettings(), 0)
+	ctx := context.Background()
+
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 1500))
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p2", 0))
+	require.NoError(t, joinWithBuyIn(manager, second.ID, "p3", 0))
+	assert.Equal(t, int64(1500+first.Settings.BuyIn), manager.Escrow().Total(first.ID))
+
+	results := reconciler.Reconcile(false)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.True(t, result.Balanced, "seated buy-ins are escrowed: %+v", result)
+	}
+
+	require.NoError(t, manager.MovePlayer(ctx, "p1", "p1", first.ID, second.ID))
+	require.NoError(t, manager.LeaveTable(ctx, &TableLeaveRequest{TableID: first.ID, PlayerID: "p2"}))
+	assert.Zero(t, manager.Escrow().Total(first.ID), "leaving releases the buy-in")
+	assert.Equal(t, int64(1500+second.Settings.BuyIn), manager.Escrow().Total(second.ID), "moves carry the escrow")
+
+	results = reconciler.Reconcile(true)
+	require.Len(t, results, 1)
+	assert.Equal(t, second.ID, results[0].TableID)
+	assert.True(t, results[0].Balanced)
+	assert.Empty(t, results[0].RepairSkipped)
+}
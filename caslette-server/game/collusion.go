@@ -0,0 +1,478 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Collusion flag kinds and the audit action they are logged under
+const (
+	CollusionSoftPlay    = "soft_play"
+	CollusionFoldPattern = "improbable_folds"
+	CollusionChipDumping = "chip_dumping"
+	CollusionAuditAction = "collusion_flag"
+)
+
+// Collusion tracking limits
+const (
+	MaxCollusionEvidence = 20                 // Hand IDs kept as evidence for each pattern
+	MaxCollusionPairs    = 100000             // Pairs tracked before idle ones are dropped
+	CollusionPairIdle    = 7 * 24 * time.Hour // How long a pair may go unseen before it can be dropped
+)
+
+// CollusionThresholds tune when a pattern between two players is flagged
+type CollusionThresholds struct {
+	SoftPlayHands int      // Strong heads-up hands checked down before soft play is flagged
+	SoftPlayRate  float64  // Share of the pair's strong heads-up hands checked down
+	SoftPlayRank  HandRank // Weakest hand that should have bet
+
+	FoldSample int     // Bets faced from the opponent, and from everyone else, before folds are judged
+	FoldRate   float64 // Share of the opponent's bets folded to
+	FoldMargin float64 // How much more often than against everyone else
+
+	DumpBigBlinds float64 // Big blinds lost to one opponent before chip dumping is flagged
+	DumpHands     int     // Hands those chips were lost over
+	DumpOneWay    float64 // Share of the chips moved between the pair that went one way
+}
+
+// DefaultCollusionThresholds returns the thresholds the detector starts with
+func DefaultCollusionThresholds() CollusionThresholds {
+	return CollusionThresholds{
+		SoftPlayHands: 3,
+		SoftPlayRate:  0.6,
+		SoftPlayRank:  ThreeOfAKind,
+		FoldSample:    10,
+		FoldRate:      0.9,
+		FoldMargin:    0.4,
+		DumpBigBlinds: 100,
+		DumpHands:     3,
+		DumpOneWay:    0.9,
+	}
+}
+
+// CollusionFlag is a suspicious pattern between two players, raised for an
+// admin to review. Players lists the folder before the bettor for folds and
+// the loser before the winner for chip dumping.
+type CollusionFlag struct {
+	Kind     string    `json:"kind"`
+	Players  []string  `json:"players"`
+	TableID  string    `json:"table_id"` // Where the hand that raised the flag was played
+	HandIDs  []string  `json:"hand_ids"` // The most recent hands showing the pattern
+	Hands    int       `json:"hands"`    // Hands or decisions the pattern was judged over
+	Score    float64   `json:"score"`    // The rate or big blinds that crossed the threshold
+	Details  string    `json:"details"`
+	RaisedAt time.Time `json:"raised_at"`
+}
+
+// CollusionReviewQueue holds flags until an admin has reviewed them
+type CollusionReviewQueue interface {
+	QueueCollusionFlag(flag CollusionFlag) error
+}
+
+// foldCounts are the bets a player faced and how many they folded to
+type foldCounts struct {
+	faced  int
+	folded int
+}
+
+// rate is the share of bets folded to
+func (c foldCounts) rate() float64 {
+	if c.faced == 0 {
+		return 0
+	}
+	return float64(c.folded) / float64(c.faced)
+}
+
+// playerHistory is one player's record against everyone they play
+type playerHistory struct {
+	folds    foldCounts
+	lastSeen time.Time
+}
+
+// pairHistory is what two players have done to each other. Directed counts
+// index by side: 0 is the first player of the pair acting against the second.
+type pairHistory struct {
+	strongHeadsUp int // Heads-up showdowns where either held a strong hand
+	checkedDown   int // Of those, checked through every street after the first
+	folds         [2]foldCounts
+	moved         [2]float64 // Big blinds lost to the other player
+	dumpHands     [2]int     // Hands those were lost over
+	evidence      map[string][]string
+	flagged       map[string]bool // Patterns already raised, so each is raised once
+	lastSeen      time.Time
+}
+
+// note keeps a hand as evidence of a pattern
+func (p *pairHistory) note(pattern, handID string) {
+	hands := append(p.evidence[pattern], handID)
+	if len(hands) > MaxCollusionEvidence {
+		hands = hands[len(hands)-MaxCollusionEvidence:]
+	}
+	p.evidence[pattern] = hands
+}
+
+// CollusionDetector analyzes completed hands for patterns suggesting two
+// players are working together: checking down strong hands against each
+// other, folding to one opponent far more than to anyone else, and losing
+// chips to one opponent that rarely flow back. Pairs are tracked across every
+// table they meet at. Each pattern is flagged once per pair, into the audit
+// log and the admin review queue.
+type CollusionDetector struct {
+	auditor   AuditLogger
+	evaluator *PokerEvaluator
+	now       func() time.Time
+
+	mu         sync.Mutex
+	thresholds CollusionThresholds
+	queue      CollusionReviewQueue
+	players    map[string]*playerHistory
+	pairs      map[[2]string]*pairHistory // Keyed by the pair's player IDs in order
+}
+
+// NewCollusionDetector creates a detector logging flags to the auditor
+func NewCollusionDetector(auditor AuditLogger) *CollusionDetector {
+	return &CollusionDetector{
+		auditor:    auditor,
+		evaluator:  NewPokerEvaluator(),
+		now:        time.Now,
+		thresholds: DefaultCollusionThresholds(),
+		players:    make(map[string]*playerHistory),
+		pairs:      make(map[[2]string]*pairHistory),
+	}
+}
+
+// SetThresholds changes when patterns are flagged
+func (d *CollusionDetector) SetThresholds(thresholds CollusionThresholds) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.thresholds = thresholds
+}
+
+// SetReviewQueue sets where flags wait for review; nil only logs them
+func (d *CollusionDetector) SetReviewQueue(queue CollusionReviewQueue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queue = queue
+}
+
+// Analyze adds a completed hand to the players' histories, returning the
+// flags it raised
+func (d *CollusionDetector) Analyze(table *GameTable, record HandRecord) []CollusionFlag {
+	d.mu.Lock()
+	now := d.now()
+	for _, player := range record.Players {
+		d.player(player.PlayerID).lastSeen = now
+	}
+	touched := make(map[[2]string]bool)
+	d.countFolds(record, touched)
+	d.countSoftPlay(record, touched)
+	d.countTransfers(record, touched)
+
+	flags := make([]CollusionFlag, 0)
+	for _, key := range sortedPairs(touched) {
+		flags = append(flags, d.judge(table.ID, key, now)...)
+	}
+	if len(d.pairs) > MaxCollusionPairs {
+		d.prune(now)
+	}
+	queue := d.queue
+	d.mu.Unlock()
+
+	for _, flag := range flags {
+		if d.auditor != nil {
+			d.auditor.LogAction(flag.Players[0], flag.TableID, CollusionAuditAction, flag.Kind, flag.Details)
+		}
+		if queue != nil {
+			if err := queue.QueueCollusionFlag(flag); err != nil {
+				log.Printf("CollusionDetector: failed to queue %s flag for %v: %v", flag.Kind, flag.Players, err)
+			}
+		}
+	}
+	return flags
+}
+
+// player returns a player's history, starting one if needed
+func (d *CollusionDetector) player(playerID string) *playerHistory {
+	history := d.players[playerID]
+	if history == nil {
+		history = &playerHistory{}
+		d.players[playerID] = history
+	}
+	return history
+}
+
+// pair returns the history between two players and the side the first plays
+func (d *CollusionDetector) pair(a, b string) (*pairHistory, [2]string, int) {
+	key, side := [2]string{a, b}, 0
+	if b < a {
+		key, side = [2]string{b, a}, 1
+	}
+	history := d.pairs[key]
+	if history == nil {
+		history = &pairHistory{evidence: make(map[string][]string), flagged: make(map[string]bool)}
+		d.pairs[key] = history
+	}
+	history.lastSeen = d.now()
+	return history, key, side
+}
+
+// countFolds counts every bet a player faced and whether they folded to it.
+// A bet is any action leaving its player with more in on the street than
+// anyone had; blinds and antes are not bets.
+func (d *CollusionDetector) countFolds(record HandRecord, touched map[[2]string]bool) {
+	var street TexasHoldemState
+	committed := make(map[string]int)
+	highest, aggressor := 0, ""
+	for _, action := range record.Actions {
+		if action.Street != street {
+			street, highest, aggressor = action.Street, 0, ""
+			committed = make(map[string]int)
+		}
+		if action.Action != HandActionPost && aggressor != "" && aggressor != action.PlayerID {
+			folded := action.Action == string(ActionFold)
+			d.player(action.PlayerID).folds.add(folded)
+			pair, key, side := d.pair(action.PlayerID, aggressor)
+			pair.folds[side].add(folded)
+			if folded {
+				pair.note(foldPattern(side), record.HandID)
+			}
+			touched[key] = true
+		}
+		committed[action.PlayerID] += action.Amount
+		if committed[action.PlayerID] > highest {
+			highest = committed[action.PlayerID]
+			if action.Action != HandActionPost {
+				aggressor = action.PlayerID
+			}
+		}
+	}
+}
+
+// add counts one bet faced
+func (c *foldCounts) add(folded bool) {
+	c.faced++
+	if folded {
+		c.folded++
+	}
+}
+
+// countSoftPlay counts hands two players took to showdown heads up after the
+// first street where either held a strong hand, and whether nobody bet
+func (d *CollusionDetector) countSoftPlay(record HandRecord, touched map[[2]string]bool) {
+	if len(record.Actions) == 0 {
+		return
+	}
+	opening := record.Actions[0].Street
+	live := make(map[string]bool)
+	for _, result := range record.Results {
+		if result.Showdown != "" {
+			live[result.PlayerID] = true
+		}
+	}
+	if len(live) != 2 {
+		return
+	}
+
+	laterStreets, checkedDown := false, true
+	for _, action := range record.Actions {
+		if action.Street == opening {
+			if action.Action == string(ActionFold) && live[action.PlayerID] {
+				return
+			}
+			continue
+		}
+		if !live[action.PlayerID] {
+			return // A third player was still in after the first street
+		}
+		laterStreets = true
+		if action.Action != string(ActionCheck) {
+			checkedDown = false
+		}
+	}
+	if !laterStreets {
+		return // All in on the first street; there was nothing to bet
+	}
+
+	players := make([]string, 0, 2)
+	strong := false
+	for _, player := range record.Players {
+		if !live[player.PlayerID] {
+			continue
+		}
+		players = append(players, player.PlayerID)
+		if hand := d.bestHand(record, player.HoleCards); hand != nil && hand.Rank >= d.thresholds.SoftPlayRank {
+			strong = true
+		}
+	}
+	if !strong || len(players) != 2 {
+		return
+	}
+	pair, key, _ := d.pair(players[0], players[1])
+	pair.strongHeadsUp++
+	if checkedDown {
+		pair.checkedDown++
+		pair.note(CollusionSoftPlay, record.HandID)
+	}
+	touched[key] = true
+}
+
+// bestHand evaluates a player's hand under the rules of the hand's game
+func (d *CollusionDetector) bestHand(record HandRecord, holeCards []Card) *PokerHand {
+	if len(holeCards) == 0 {
+		return nil
+	}
+	if record.GameType == GameTypeOmaha {
+		return d.evaluator.FindBestOmahaHand(holeCards, record.Board)
+	}
+	cards := append(append([]Card(nil), holeCards...), record.Board...)
+	if len(cards) < 5 {
+		return nil
+	}
+	return d.evaluator.FindBestHand(cards)
+}
+
+// countTransfers shares each loser's loss among the winners by what they
+// won, in big blinds
+func (d *CollusionDetector) countTransfers(record HandRecord, touched map[[2]string]bool) {
+	won := 0
+	for _, result := range record.Results {
+		if result.Net > 0 {
+			won += result.Net
+		}
+	}
+	if won == 0 {
+		return
+	}
+	bigBlind := float64(max(record.BigBlind, 1))
+	for _, loser := range record.Results {
+		if loser.Net >= 0 {
+			continue
+		}
+		for _, winner := range record.Results {
+			if winner.Net <= 0 {
+				continue
+			}
+			pair, key, side := d.pair(loser.PlayerID, winner.PlayerID)
+			pair.moved[side] += float64(-loser.Net) * float64(winner.Net) / float64(won) / bigBlind
+			pair.dumpHands[side]++
+			pair.note(dumpPattern(side), record.HandID)
+			touched[key] = true
+		}
+	}
+}
+
+// foldPattern names a side's folding pattern for its evidence and flag
+func foldPattern(side int) string {
+	return fmt.Sprintf("%s:%d", CollusionFoldPattern, side)
+}
+
+// dumpPattern names a side's chip dumping pattern for its evidence and flag
+func dumpPattern(side int) string {
+	return fmt.Sprintf("%s:%d", CollusionChipDumping, side)
+}
+
+// judge checks a pair against the thresholds, returning the patterns it
+// crossed for the first time
+func (d *CollusionDetector) judge(tableID string, key [2]string, now time.Time) []CollusionFlag {
+	pair := d.pairs[key]
+	limits := d.thresholds
+	flags := make([]CollusionFlag, 0)
+	raise := func(pattern, kind string, players []string, hands int, score float64, details string) {
+		if pair.flagged[pattern] {
+			return
+		}
+		pair.flagged[pattern] = true
+		flags = append(flags, CollusionFlag{
+			Kind:     kind,
+			Players:  players,
+			TableID:  tableID,
+			HandIDs:  append([]string(nil), pair.evidence[pattern]...),
+			Hands:    hands,
+			Score:    score,
+			Details:  details,
+			RaisedAt: now,
+		})
+	}
+
+	if pair.checkedDown >= limits.SoftPlayHands && pair.strongHeadsUp > 0 {
+		rate := float64(pair.checkedDown) / float64(pair.strongHeadsUp)
+		if rate >= limits.SoftPlayRate {
+			raise(CollusionSoftPlay, CollusionSoftPlay, []string{key[0], key[1]}, pair.strongHeadsUp, rate,
+				fmt.Sprintf("%s and %s checked down %d of %d strong heads-up hands", key[0], key[1], pair.checkedDown, pair.strongHeadsUp))
+		}
+	}
+
+	for side := range 2 {
+		folder, bettor := key[side], key[1-side]
+		against := pair.folds[side]
+		overall := d.player(folder).folds
+		others := foldCounts{faced: overall.faced - against.faced, folded: overall.folded - against.folded}
+		if against.faced >= limits.FoldSample && others.faced >= limits.FoldSample &&
+			against.rate() >= limits.FoldRate && against.rate()-others.rate() >= limits.FoldMargin {
+			raise(foldPattern(side), CollusionFoldPattern, []string{folder, bettor}, against.faced, against.rate(),
+				fmt.Sprintf("%s folded to %d of %d bets from %s but %d of %d from everyone else",
+					folder, against.folded, against.faced, bettor, others.folded, others.faced))
+		}
+
+		moved, returned := pair.moved[side], pair.moved[1-side]
+		if moved >= limits.DumpBigBlinds && pair.dumpHands[side] >= limits.DumpHands && moved/(moved+returned) >= limits.DumpOneWay {
+			raise(dumpPattern(side), CollusionChipDumping, []string{folder, bettor}, pair.dumpHands[side], moved,
+				fmt.Sprintf("%s lost %.0f big blinds to %s over %d hands and won back %.0f",
+					folder, moved, bettor, pair.dumpHands[side], returned))
+		}
+	}
+	return flags
+}
+
+// prune drops pairs and players not seen for a while
+func (d *CollusionDetector) prune(now time.Time) {
+	cutoff := now.Add(-CollusionPairIdle)
+	for key, pair := range d.pairs {
+		if pair.lastSeen.Before(cutoff) {
+			delete(d.pairs, key)
+		}
+	}
+	for playerID, player := range d.players {
+		if player.lastSeen.Before(cutoff) {
+			delete(d.players, playerID)
+		}
+	}
+}
+
+// sortedPairs orders the pairs a hand touched so flags come out stably
+func sortedPairs(pairs map[[2]string]bool) [][2]string {
+	keys := make([][2]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+// SetCollusionDetector sets the detector completed hands are analyzed by;
+// nil analyzes none
+func (tm *ActorTableManager) SetCollusionDetector(detector *CollusionDetector) {
+	tm.mu.Lock()
+	tm.collusion = detector
+	tm.mu.Unlock()
+}
+
+// detectCollusion analyzes a completed hand for collusion. Practice tables
+// are left out: their chips are worth nothing to pass between accounts.
+func (tm *ActorTableManager) detectCollusion(table *GameTable, record HandRecord) {
+	tm.mu.RLock()
+	detector := tm.collusion
+	tm.mu.RUnlock()
+	if detector == nil || table.IsPractice() {
+		return
+	}
+	detector.Analyze(table, record)
+}
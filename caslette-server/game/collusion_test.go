@@ -0,0 +1,197 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingReviewQueue struct {
+	flags []CollusionFlag
+}
+
+func (q *recordingReviewQueue) QueueCollusionFlag(flag CollusionFlag) error {
+	q.flags = append(q.flags, flag)
+	return nil
+}
+
+func newTestCollusionDetector() (*CollusionDetector, *SecurityAuditor, *recordingReviewQueue) {
+	auditor := NewSecurityAuditor()
+	queue := &recordingReviewQueue{}
+	detector := NewCollusionDetector(auditor)
+	detector.SetReviewQueue(queue)
+	return detector, auditor, queue
+}
+
+var collusionTable = &GameTable{ID: "collusion-table"}
+
+// raiseFoldHand is a hand where bettor raises before the flop and responder
+// folds or calls, conceding the blinds either way to keep the records simple
+func raiseFoldHand(n int, bettor, responder string, fold bool) HandRecord {
+	response := HandAction{Street: PreFlop, PlayerID: responder, Action: string(ActionFold)}
+	if !fold {
+		response = HandAction{Street: PreFlop, PlayerID: responder, Action: string(ActionCall), Amount: 30}
+	}
+	return HandRecord{
+		HandID:   fmt.Sprintf("fold-%d", n),
+		BigBlind: 10,
+		Players:  []HandPlayer{{PlayerID: bettor}, {PlayerID: responder}},
+		Actions: []HandAction{
+			{Street: PreFlop, PlayerID: responder, Action: HandActionPost, Amount: 10},
+			{Street: PreFlop, PlayerID: bettor, Action: string(ActionRaise), Amount: 30},
+			response,
+		},
+	}
+}
+
+// checkDownHand is a heads-up hand checked from the flop to showdown, with
+// "a" holding a set of aces and "b" a pair of kings
+func checkDownHand(n int, betRiver bool) HandRecord {
+	river := HandAction{Street: River, PlayerID: "a", Action: string(ActionCheck)}
+	if betRiver {
+		river = HandAction{Street: River, PlayerID: "a", Action: string(ActionBet), Amount: 20}
+	}
+	actions := []HandAction{
+		{Street: PreFlop, PlayerID: "a", Action: HandActionPost, Amount: 5},
+		{Street: PreFlop, PlayerID: "b", Action: HandActionPost, Amount: 10},
+		{Street: PreFlop, PlayerID: "c", Action: string(ActionFold)},
+		{Street: PreFlop, PlayerID: "a", Action: string(ActionCall), Amount: 5},
+		{Street: PreFlop, PlayerID: "b", Action: string(ActionCheck)},
+	}
+	for _, street := range []TexasHoldemState{Flop, Turn} {
+		actions = append(actions,
+			HandAction{Street: street, PlayerID: "a", Action: string(ActionCheck)},
+			HandAction{Street: street, PlayerID: "b", Action: string(ActionCheck)})
+	}
+	actions = append(actions, river, HandAction{Street: River, PlayerID: "b", Action: string(ActionCheck)})
+	if betRiver {
+		actions[len(actions)-1] = HandAction{Street: River, PlayerID: "b", Action: string(ActionCall), Amount: 20}
+	}
+	return HandRecord{
+		HandID:   fmt.Sprintf("check-%d", n),
+		GameType: GameTypeTexasHoldem,
+		BigBlind: 10,
+		Players: []HandPlayer{
+			{PlayerID: "a", HoleCards: []Card{NewCard(Hearts, Ace), NewCard(Spades, Ace)}},
+			{PlayerID: "b", HoleCards: []Card{NewCard(Hearts, King), NewCard(Spades, Two)}},
+			{PlayerID: "c", HoleCards: []Card{NewCard(Hearts, Four), NewCard(Spades, Six)}},
+		},
+		Actions: actions,
+		Board:   []Card{NewCard(Clubs, Ace), NewCard(Diamonds, King), NewCard(Hearts, Nine), NewCard(Spades, Seven), NewCard(Clubs, Three)},
+		Results: []HandResult{
+			{PlayerID: "a", Invested: 10, Collected: 20, Net: 10, Showdown: ShowdownWon},
+			{PlayerID: "b", Invested: 10, Net: -10, Showdown: ShowdownLost},
+			{PlayerID: "c"},
+		},
+	}
+}
+
+// transferHand is a hand in which loser lost chips to winner
+func transferHand(n int, loser, winner string, chips int) HandRecord {
+	return HandRecord{
+		HandID:   fmt.Sprintf("transfer-%d", n),
+		BigBlind: 10,
+		Players:  []HandPlayer{{PlayerID: loser}, {PlayerID: winner}},
+		Results: []HandResult{
+			{PlayerID: loser, Invested: chips, Net: -chips},
+			{PlayerID: winner, Invested: chips, Collected: 2 * chips, Net: chips},
+		},
+	}
+}
+
+func TestCollusionDetectorFlagsSoftPlay(t *testing.T) {
+	detector, auditor, queue := newTestCollusionDetector()
+
+	assert.Empty(t, detector.Analyze(collusionTable, checkDownHand(1, true)), "a bet strong hand is not soft play")
+	assert.Empty(t, detector.Analyze(collusionTable, checkDownHand(2, false)))
+	assert.Empty(t, detector.Analyze(collusionTable, checkDownHand(3, false)))
+	flags := detector.Analyze(collusionTable, checkDownHand(4, false))
+
+	require.Len(t, flags, 1)
+	flag := flags[0]
+	assert.Equal(t, CollusionSoftPlay, flag.Kind)
+	assert.Equal(t, []string{"a", "b"}, flag.Players)
+	assert.Equal(t, collusionTable.ID, flag.TableID)
+	assert.Equal(t, []string{"check-2", "check-3", "check-4"}, flag.HandIDs)
+	assert.Equal(t, 4, flag.Hands)
+	assert.InDelta(t, 0.75, flag.Score, 0.001)
+
+	assert.Equal(t, flags, queue.flags, "flags wait for review")
+	logs := auditor.GetAuditLogs(0)
+	require.Len(t, logs, 1)
+	assert.Equal(t, CollusionAuditAction, logs[0].Action)
+	assert.Equal(t, CollusionSoftPlay, logs[0].Result)
+
+	assert.Empty(t, detector.Analyze(collusionTable, checkDownHand(5, false)), "each pattern is flagged once")
+}
+
+func TestCollusionDetectorIgnoresWeakHandsCheckedDown(t *testing.T) {
+	detector, _, _ := newTestCollusionDetector()
+	for i := range 5 {
+		hand := checkDownHand(i, false)
+		hand.Players[0].HoleCards = []Card{NewCard(Hearts, Queen), NewCard(Spades, Jack)}
+		assert.Empty(t, detector.Analyze(collusionTable, hand))
+	}
+}
+
+func TestCollusionDetectorFlagsFoldsToOneOpponent(t *testing.T) {
+	detector, _, _ := newTestCollusionDetector()
+
+	// "a" folds to every raise from "b" but calls "c"; "d" folds to both
+	var flags []CollusionFlag
+	for i := range 10 {
+		flags = append(flags, detector.Analyze(collusionTable, raiseFoldHand(4*i, "c", "a", false))...)
+		flags = append(flags, detector.Analyze(collusionTable, raiseFoldHand(4*i+1, "c", "d", true))...)
+		flags = append(flags, detector.Analyze(collusionTable, raiseFoldHand(4*i+2, "b", "d", true))...)
+		flags = append(flags, detector.Analyze(collusionTable, raiseFoldHand(4*i+3, "b", "a", true))...)
+	}
+
+	require.Len(t, flags, 1)
+	flag := flags[0]
+	assert.Equal(t, CollusionFoldPattern, flag.Kind)
+	assert.Equal(t, []string{"a", "b"}, flag.Players, "the folder comes first")
+	assert.Equal(t, 10, flag.Hands)
+	assert.Equal(t, 1.0, flag.Score)
+	assert.Len(t, flag.HandIDs, 10)
+	assert.Contains(t, flag.Details, "0 of 10 from everyone else")
+}
+
+func TestCollusionDetectorFlagsChipDumping(t *testing.T) {
+	detector, _, queue := newTestCollusionDetector()
+
+	// Chips that change hands both ways are not dumped
+	for i := range 3 {
+		assert.Empty(t, detector.Analyze(collusionTable, transferHand(2*i, "c", "d", 500)))
+		assert.Empty(t, detector.Analyze(collusionTable, transferHand(2*i+1, "d", "c", 500)))
+	}
+
+	assert.Empty(t, detector.Analyze(collusionTable, transferHand(10, "a", "b", 400)))
+	assert.Empty(t, detector.Analyze(collusionTable, transferHand(11, "a", "b", 400)), "two hands are not enough")
+	assert.Empty(t, detector.Analyze(collusionTable, transferHand(12, "b", "a", 50)))
+	flags := detector.Analyze(collusionTable, transferHand(13, "a", "b", 400))
+
+	require.Len(t, flags, 1)
+	flag := flags[0]
+	assert.Equal(t, CollusionChipDumping, flag.Kind)
+	assert.Equal(t, []string{"a", "b"}, flag.Players, "the loser comes first")
+	assert.Equal(t, 3, flag.Hands)
+	assert.InDelta(t, 120, flag.Score, 0.001)
+	assert.Equal(t, []string{"transfer-10", "transfer-11", "transfer-13"}, flag.HandIDs)
+	assert.Equal(t, flags, queue.flags)
+}
+
+func TestCollusionDetectorSkipsPracticeTables(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	detector, _, queue := newTestCollusionDetector()
+	detector.SetThresholds(CollusionThresholds{DumpBigBlinds: 1, DumpHands: 1, DumpOneWay: 0.5, SoftPlayHands: 1, FoldSample: 1})
+	manager.SetCollusionDetector(detector)
+
+	practice := &GameTable{ID: "practice", Settings: TableSettings{Currency: CurrencyPlayMoney}}
+	manager.detectCollusion(practice, transferHand(1, "a", "b", 100))
+	assert.Empty(t, queue.flags)
+
+	manager.detectCollusion(collusionTable, transferHand(2, "a", "b", 100))
+	assert.Len(t, queue.flags, 1)
+}
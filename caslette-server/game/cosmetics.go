@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"time"
+)
+
+// CosmeticsProvider looks up the cosmetics a player has equipped, keyed by
+// kind (e.g. "deck" or "table") with the item SKU as value
+type CosmeticsProvider func(playerID string) map[string]string
+
+// SetPlayerCosmeticsCommand replaces the cosmetics shown on a player's seat
+type SetPlayerCosmeticsCommand struct {
+	PlayerID  string
+	Cosmetics map[string]string
+	Response  chan interface{}
+}
+
+func (cmd *SetPlayerCosmeticsCommand) Execute(table *GameTable) interface{} {
+	for i := range table.PlayerSlots {
+		if table.PlayerSlots[i].PlayerID == cmd.PlayerID {
+			table.PlayerSlots[i].Cosmetics = copyCosmetics(cmd.Cosmetics)
+			table.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+}
+
+// copyCosmetics copies a cosmetics map, returning nil when it is empty
+func copyCosmetics(cosmetics map[string]string) map[string]string {
+	if len(cosmetics) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(cosmetics))
+	for kind, sku := range cosmetics {
+		copied[kind] = sku
+	}
+	return copied
+}
+
+// SetPlayerCosmetics sends a cosmetics update to the table actor
+func (ta *TableActor) SetPlayerCosmetics(ctx context.Context, playerID string, cosmetics map[string]string) error {
+	cmd := &SetPlayerCosmeticsCommand{
+		PlayerID:  playerID,
+		Cosmetics: cosmetics,
+		Response:  make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return err
+		}
+		return nil // Success
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetCosmeticsProvider sets how equipped cosmetics are looked up when a
+// player sits down
+func (tm *ActorTableManager) SetCosmeticsProvider(provider CosmeticsProvider) {
+	tm.mu.Lock()
+	tm.cosmetics = provider
+	tm.mu.Unlock()
+}
+
+// applyCosmetics shows a newly seated player's equipped cosmetics. Failing
+// to load them never blocks the join.
+func (tm *ActorTableManager) applyCosmetics(ctx context.Context, actor *TableActor, playerID string) {
+	tm.mu.RLock()
+	provider := tm.cosmetics
+	tm.mu.RUnlock()
+	if provider == nil {
+		return
+	}
+
+	if cosmetics := provider(playerID); len(cosmetics) > 0 {
+		actor.SetPlayerCosmetics(ctx, playerID, cosmetics)
+	}
+}
+
+// UpdatePlayerCosmetics refreshes a player's cosmetics at every table they
+// are seated at and tells those tables, returning how many were updated
+func (tm *ActorTableManager) UpdatePlayerCosmetics(ctx context.Context, playerID string, cosmetics map[string]string) int {
+	tm.mu.RLock()
+	actors := make([]*TableActor, 0, len(tm.actors))
+	for _, actor := range tm.actors {
+		actors = append(actors, actor)
+	}
+	tm.mu.RUnlock()
+
+	updated := 0
+	for _, actor := range actors {
+		if !actor.table.IsPlayerAtTable(playerID) {
+			continue
+		}
+		if err := actor.SetPlayerCosmetics(ctx, playerID, cosmetics); err != nil {
+			continue
+		}
+		updated++
+		tm.BroadcastGameEvent(actor.table, &GameEvent{
+			Type:     "player_cosmetics_updated",
+			PlayerID: playerID,
+			Data: map[string]interface{}{
+				"table_id":  actor.table.ID,
+				"player_id": playerID,
+				"cosmetics": copyCosmetics(cosmetics),
+			},
+			Timestamp: time.Now(),
+		})
+	}
+	return updated
+}
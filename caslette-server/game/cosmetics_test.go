@@ -0,0 +1,93 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingBroadcaster struct {
+	mu     sync.Mutex
+	events []*GameEvent
+}
+
+func (b *recordingBroadcaster) OnGameEvent(table *GameTable, event *GameEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+}
+
+func seatCosmetics(table *GameTable, playerID string) map[string]string {
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == playerID {
+			return slot.Cosmetics
+		}
+	}
+	return nil
+}
+
+func TestJoinTableAppliesEquippedCosmetics(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	manager.SetCosmeticsProvider(func(playerID string) map[string]string {
+		if playerID == "styled" {
+			return map[string]string{"deck": "deck_neon", "table": "felt_royal"}
+		}
+		return nil
+	})
+	table := newBalancingTable(t, manager, "cosmetic", DefaultTableSettings(), 0)
+
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "styled", 0))
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "plain", 0))
+
+	assert.Equal(t, map[string]string{"deck": "deck_neon", "table": "felt_royal"}, seatCosmetics(table, "styled"))
+	assert.Nil(t, seatCosmetics(table, "plain"))
+
+	info := NewDataFilter().FilterTableInfo(table, "plain", "user")
+	slots, ok := info["player_slots"].([]map[string]interface{})
+	require.True(t, ok)
+	var shown map[string]string
+	for _, slot := range slots {
+		if slot["player_id"] == "styled" {
+			shown, _ = slot["cosmetics"].(map[string]string)
+		}
+		if slot["player_id"] == "plain" {
+			assert.NotContains(t, slot, "cosmetics")
+		}
+	}
+	assert.Equal(t, "deck_neon", shown["deck"], "other clients see the equipped deck")
+}
+
+func TestUpdatePlayerCosmeticsRefreshesSeatedTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	broadcaster := &recordingBroadcaster{}
+	manager.SetEventBroadcaster(broadcaster)
+	first := newBalancingTable(t, manager, "first", DefaultTableSettings(), 0)
+	second := newBalancingTable(t, manager, "second", DefaultTableSettings(), 0)
+	newBalancingTable(t, manager, "elsewhere", DefaultTableSettings(), 1)
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 0))
+	require.NoError(t, joinWithBuyIn(manager, second.ID, "p1", 0))
+
+	updated := manager.UpdatePlayerCosmetics(context.Background(), "p1", map[string]string{"deck": "deck_gold"})
+	assert.Equal(t, 2, updated)
+	assert.Equal(t, "deck_gold", seatCosmetics(first, "p1")["deck"])
+	assert.Equal(t, "deck_gold", seatCosmetics(second, "p1")["deck"])
+
+	broadcaster.mu.Lock()
+	events := broadcaster.events
+	broadcaster.mu.Unlock()
+	cosmeticEvents := 0
+	for _, event := range events {
+		if event.Type == "player_cosmetics_updated" {
+			cosmeticEvents++
+			assert.Equal(t, "p1", event.Data["player_id"])
+		}
+	}
+	assert.Equal(t, 2, cosmeticEvents)
+
+	assert.Equal(t, 2, manager.UpdatePlayerCosmetics(context.Background(), "p1", nil))
+	assert.Nil(t, seatCosmetics(first, "p1"), "unequipping everything clears the seat")
+	assert.Zero(t, manager.UpdatePlayerCosmetics(context.Background(), "nobody", map[string]string{"deck": "deck_gold"}))
+}
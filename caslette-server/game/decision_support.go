@@ -0,0 +1,142 @@
+package game
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// EquitySamples is how many random run-outs an all-in equity estimate deals
+const EquitySamples = 1000
+
+// DecisionSupport is the heads-up display data given to the player to act
+// at tables that turn it on
+type DecisionSupport struct {
+	ToCall  int      `json:"to_call"`
+	Pot     int      `json:"pot"`
+	PotOdds float64  `json:"pot_odds"`         // The call's share of the pot after calling: the equity needed to break even
+	Equity  *float64 `json:"equity,omitempty"` // Chance to win at showdown against random hands, when a player is all in
+	Samples int      `json:"samples,omitempty"`
+}
+
+// equityKey identifies the situation an equity estimate was made for
+type equityKey struct {
+	hand      int
+	board     int
+	playerID  string
+	opponents int
+}
+
+// equityCache keeps the last estimate, since a Monte Carlo run is too slow
+// to repeat for every state request
+type equityCache struct {
+	mu     sync.Mutex
+	key    equityKey
+	equity float64
+	valid  bool
+}
+
+// SetDecisionSupport turns pot odds and all-in equity for the player to act
+// on or off
+func (the *TexasHoldemEngine) SetDecisionSupport(enabled bool) {
+	the.hud = enabled
+}
+
+// DecisionSupport returns pot odds for the player to act and, once someone
+// is all in or the call would put the player all in, an estimate of their
+// equity. Opponents' hole cards are treated as unknown, so the estimate
+// gives away nothing the player could not work out at the table. It returns
+// nil when the table has it off or it is not the player's turn.
+func (the *TexasHoldemEngine) DecisionSupport(playerID string) *DecisionSupport {
+	if !the.hud || the.GetState() != GameStateInProgress || the.getCurrentActionPlayerID() != playerID {
+		return nil
+	}
+	hero := the.getHoldemPlayer(playerID)
+	if hero == nil || hero.HasFolded || hero.IsAllIn {
+		return nil
+	}
+
+	support := &DecisionSupport{
+		ToCall: min(max(the.currentBet-hero.CurrentBet, 0), hero.Chips),
+		Pot:    the.pot,
+	}
+	if support.ToCall > 0 {
+		support.PotOdds = float64(support.ToCall) / float64(the.pot+support.ToCall)
+	}
+
+	opponents := make([]*TexasHoldemPlayer, 0)
+	allIn := support.ToCall > 0 && support.ToCall == hero.Chips
+	for _, player := range the.getActivePlayers() {
+		opponent := the.getHoldemPlayer(player.ID)
+		if opponent == nil || opponent.ID == playerID || opponent.HasFolded {
+			continue
+		}
+		opponents = append(opponents, opponent)
+		allIn = allIn || opponent.IsAllIn
+	}
+	// Stud's up cards change what the opponents can hold, which random
+	// hold'em hands do not capture
+	if allIn && len(opponents) > 0 && the.variant == nil {
+		equity := the.estimateEquity(hero, len(opponents))
+		support.Equity = &equity
+		support.Samples = EquitySamples
+	}
+	return support
+}
+
+// estimateEquity returns the hero's share of the pots over random run-outs
+// with each opponent dealt random hole cards, reusing the last estimate while
+// the hand, board and opponents are the same
+func (the *TexasHoldemEngine) estimateEquity(hero *TexasHoldemPlayer, opponents int) float64 {
+	key := equityKey{hand: the.handsDealt, board: len(the.communityCards.Cards), playerID: hero.ID, opponents: opponents}
+	the.equity.mu.Lock()
+	defer the.equity.mu.Unlock()
+	if the.equity.valid && the.equity.key == key {
+		return the.equity.equity
+	}
+
+	board := the.communityCards.Cards
+	known := make(map[Card]bool, len(hero.Hand.Cards)+len(board))
+	for _, card := range append(append([]Card(nil), hero.Hand.Cards...), board...) {
+		known[card] = true
+	}
+	unseen := make([]Card, 0, 52)
+	for _, card := range standardDeck() {
+		if !known[card] {
+			unseen = append(unseen, card)
+		}
+	}
+
+	needed := opponents*the.holeCardCount + 5 - len(board)
+	if needed > len(unseen) {
+		return 0
+	}
+	runout := make([]Card, 0, 5)
+	share := 0.0
+	for sample := 0; sample < EquitySamples; sample++ {
+		// Partial Fisher-Yates: the first cards needed are a random draw
+		for i := 0; i < needed; i++ {
+			j := i + rand.IntN(len(unseen)-i)
+			unseen[i], unseen[j] = unseen[j], unseen[i]
+		}
+		drawn := unseen[:needed]
+		runout = append(append(runout[:0], board...), drawn[opponents*the.holeCardCount:]...)
+
+		best := the.evaluateBestHand(hero.Hand.Cards, runout)
+		tied := 1
+		for i := 0; i < opponents && best != nil; i++ {
+			hand := the.evaluateBestHand(drawn[i*the.holeCardCount:(i+1)*the.holeCardCount], runout)
+			switch compare := hand.Compare(best); {
+			case compare > 0:
+				best = nil
+			case compare == 0:
+				tied++
+			}
+		}
+		if best != nil {
+			share += 1 / float64(tied)
+		}
+	}
+
+	the.equity.key, the.equity.equity, the.equity.valid = key, share/EquitySamples, true
+	return the.equity.equity
+}
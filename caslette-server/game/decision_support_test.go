@@ -0,0 +1,73 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDecisionSupportTable(t *testing.T) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("hud-game")
+	engine.SetDecisionSupport(true)
+	for i, playerID := range []string{"1", "2"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+	require.NoError(t, engine.Start())
+	return engine
+}
+
+func TestDecisionSupportGivesPotOddsToThePlayerToAct(t *testing.T) {
+	engine := newDecisionSupportTable(t)
+	acting := engine.getCurrentActionPlayerID()
+	player := engine.getHoldemPlayer(acting)
+
+	support := engine.DecisionSupport(acting)
+	require.NotNil(t, support)
+	toCall := engine.currentBet - player.CurrentBet
+	require.Greater(t, toCall, 0)
+	assert.Equal(t, toCall, support.ToCall)
+	assert.Equal(t, engine.pot, support.Pot)
+	assert.InDelta(t, float64(toCall)/float64(engine.pot+toCall), support.PotOdds, 1e-9)
+	assert.Nil(t, support.Equity, "nobody is all in")
+	assert.Equal(t, support, engine.GetPlayerState(acting)["decision_support"])
+
+	for _, playerID := range []string{"1", "2"} {
+		if playerID != acting {
+			assert.Nil(t, engine.DecisionSupport(playerID), "only the player to act gets it")
+			assert.NotContains(t, engine.GetPlayerState(playerID), "decision_support")
+		}
+	}
+
+	engine.SetDecisionSupport(false)
+	assert.Nil(t, engine.DecisionSupport(acting))
+}
+
+func TestDecisionSupportEstimatesAllInEquity(t *testing.T) {
+	engine := newDecisionSupportTable(t)
+	shover := engine.getCurrentActionPlayerID()
+	require.NoError(t, act(engine, ActionAllIn, 0))
+
+	hero := engine.getCurrentActionPlayerID()
+	require.NotEqual(t, shover, hero)
+	engine.getHoldemPlayer(hero).Hand.Cards = []Card{NewCard(Hearts, Ace), NewCard(Spades, Ace)}
+
+	support := engine.DecisionSupport(hero)
+	require.NotNil(t, support)
+	require.NotNil(t, support.Equity)
+	assert.Equal(t, EquitySamples, support.Samples)
+	assert.InDelta(t, 0.85, *support.Equity, 0.05, "aces win about 85% against a random hand")
+	assert.Equal(t, *support.Equity, *engine.DecisionSupport(hero).Equity, "the estimate is reused until the situation changes")
+}
+
+func TestEngineFactoryAppliesDecisionSupport(t *testing.T) {
+	settings := DefaultTableSettings()
+	settings.DecisionSupport = true
+	engine, err := (&TexasHoldemEngineFactory{}).CreateEngine(GameTypeOmaha, settings)
+	require.NoError(t, err)
+	assert.True(t, engine.(*OmahaEngine).hud)
+
+	engine, err = (&TexasHoldemEngineFactory{}).CreateEngine(GameTypeTexasHoldem, DefaultTableSettings())
+	require.NoError(t, err)
+	assert.False(t, engine.(*TexasHoldemEngine).hud, "off unless the table turns it on")
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Bounds on operator-configured game defaults
+const (
+	MaxEnginePlayers = 10   // Most players a single hand can be dealt to
+	MaxRakePercent   = 10.0 // Highest share of a pot the house may take
+)
+
+// GameDefaults are the gameplay values used when a table or engine does not
+// set its own. Operators configure them at startup.
+type GameDefaults struct {
+	StartingChips int     `json:"starting_chips"` // Stack for players added without chips
+	MaxPlayers    int     `json:"max_players"`    // Most players an engine seats
+	SmallBlind    int     `json:"small_blind"`
+	BigBlind      int     `json:"big_blind"`
+	RakePercent   float64 `json:"rake_percent"` // Share of each pot taken as rake
+	MaxRake       int     `json:"max_rake"`     // Most rake taken from one hand; zero for no cap
+}
+
+// DefaultGameDefaults returns the built-in defaults
+func DefaultGameDefaults() GameDefaults {
+	return GameDefaults{
+		StartingChips: 1000,
+		MaxPlayers:    MaxEnginePlayers,
+		SmallBlind:    5,
+		BigBlind:      10,
+		RakePercent:   5,
+	}
+}
+
+var (
+	gameDefaultsMu sync.RWMutex
+	gameDefaults   = DefaultGameDefaults()
+)
+
+// Validate checks that the defaults describe a playable game
+func (d GameDefaults) Validate() error {
+	if d.StartingChips <= 0 {
+		return fmt.Errorf("starting chips must be positive")
+	}
+	if d.MaxPlayers < 2 || d.MaxPlayers > MaxEnginePlayers {
+		return fmt.Errorf("max players must be between 2 and %d", MaxEnginePlayers)
+	}
+	if d.SmallBlind <= 0 || d.BigBlind <= d.SmallBlind {
+		return fmt.Errorf("blinds must be positive with the big blind above the small blind")
+	}
+	if d.RakePercent < 0 || d.RakePercent > MaxRakePercent {
+		return fmt.Errorf("rake must be between 0 and %g percent", MaxRakePercent)
+	}
+	if d.MaxRake < 0 {
+		return fmt.Errorf("rake cap cannot be negative")
+	}
+	return nil
+}
+
+// SetGameDefaults replaces the defaults used by engines created afterwards
+func SetGameDefaults(defaults GameDefaults) error {
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+	gameDefaultsMu.Lock()
+	defer gameDefaultsMu.Unlock()
+	gameDefaults = defaults
+	return nil
+}
+
+// CurrentGameDefaults returns the defaults in effect
+func CurrentGameDefaults() GameDefaults {
+	gameDefaultsMu.RLock()
+	defer gameDefaultsMu.RUnlock()
+	return gameDefaults
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameDefaultsValidate(t *testing.T) {
+	assert.NoError(t, DefaultGameDefaults().Validate())
+
+	for name, change := range map[string]func(*GameDefaults){
+		"no chips":        func(d *GameDefaults) { d.StartingChips = 0 },
+		"one player":      func(d *GameDefaults) { d.MaxPlayers = 1 },
+		"too many":        func(d *GameDefaults) { d.MaxPlayers = MaxEnginePlayers + 1 },
+		"inverted blinds": func(d *GameDefaults) { d.BigBlind = d.SmallBlind },
+		"negative rake":   func(d *GameDefaults) { d.RakePercent = -1 },
+		"excessive rake":  func(d *GameDefaults) { d.RakePercent = MaxRakePercent + 0.5 },
+		"negative cap":    func(d *GameDefaults) { d.MaxRake = -1 },
+	} {
+		defaults := DefaultGameDefaults()
+		change(&defaults)
+		assert.Error(t, defaults.Validate(), name)
+		assert.Error(t, SetGameDefaults(defaults), name)
+	}
+	assert.Equal(t, DefaultGameDefaults(), CurrentGameDefaults(), "rejected defaults are not applied")
+}
+
+func TestEnginesUseConfiguredDefaults(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetGameDefaults(DefaultGameDefaults())) })
+	require.NoError(t, SetGameDefaults(GameDefaults{StartingChips: 500, MaxPlayers: 2, SmallBlind: 25, BigBlind: 50, RakePercent: 2.5}))
+
+	engine := NewTexasHoldemEngine("configured")
+	assert.Equal(t, 25, engine.smallBlind)
+	assert.Equal(t, 50, engine.bigBlind)
+	require.NoError(t, engine.AddPlayer(&Player{ID: "p1", Name: "p1", Position: 0}))
+	require.NoError(t, engine.AddPlayer(&Player{ID: "p2", Name: "p2", Position: 1}))
+	assert.Equal(t, 500, engine.getHoldemPlayer("p1").Chips)
+	assert.Error(t, engine.AddPlayer(&Player{ID: "p3", Name: "p3", Position: 2}), "the configured seat limit applies")
+
+	assert.Error(t, NewTableValidator().ValidatePosition(2))
+	assert.Equal(t, 25, NewOmahaEngine("omaha").smallBlind)
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// drainPollInterval is how often Drain checks for hands still being played
+const drainPollInterval = 100 * time.Millisecond
+
+// ErrServerDraining refuses to deal hands while the server shuts down
+var ErrServerDraining = &TableError{"SERVER_DRAINING", "The server is shutting down; no new hands are dealt"}
+
+// DrainResult reports how Drain left the open tables
+type DrainResult struct {
+	Finished    int `json:"finished"`    // Tables whose hand ended while draining
+	MidHand     int `json:"mid_hand"`    // Tables still in a hand when time ran out
+	Snapshotted int `json:"snapshotted"` // Cash tables saved to reopen after the restart
+}
+
+// Drain stops dealing hands and waits for the hands being played to end,
+// until ctx is done. Every cash table is then snapshotted, so tables reopen
+// after a restart and hands cut short resume where they were.
+func (tm *ActorTableManager) Drain(ctx context.Context) DrainResult {
+	tm.mu.Lock()
+	tm.draining = true
+	for tableID, timer := range tm.handTimers {
+		timer.Stop()
+		delete(tm.handTimers, tableID)
+	}
+	tm.mu.Unlock()
+
+	playing := tm.tablesMidHand()
+	started := len(playing)
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for len(playing) > 0 {
+		select {
+		case <-ctx.Done():
+			log.Printf("Drain: %d tables still mid-hand, snapshotting them", len(playing))
+			return DrainResult{Finished: started - len(playing), MidHand: len(playing), Snapshotted: tm.SnapshotTables()}
+		case <-ticker.C:
+			playing = tm.tablesMidHand()
+		}
+	}
+	return DrainResult{Finished: started, Snapshotted: tm.SnapshotTables()}
+}
+
+// Draining reports whether Drain has stopped new hands
+func (tm *ActorTableManager) Draining() bool {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.draining
+}
+
+// tablesMidHand lists the tables dealing a hand. Only engines keeping hand
+// logs say so safely while players act, so only they are waited for.
+func (tm *ActorTableManager) tablesMidHand() []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	var playing []string
+	for tableID, actor := range tm.actors {
+		if identifier, ok := actor.table.GameEngine.(LiveHandIdentifier); ok && identifier.LiveHandID() != "" {
+			playing = append(playing, tableID)
+		}
+	}
+	return playing
+}
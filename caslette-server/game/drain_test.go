@@ -0,0 +1,55 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainLetsTheHandFinishAndDealsNoMore(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 10*time.Millisecond, "p0", "p1", "p2")
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+	require.NoError(t, manager.tryStartGame(table))
+	engine := table.GameEngine.(*TexasHoldemEngine)
+
+	drained := make(chan DrainResult, 1)
+	go func() { drained <- manager.Drain(context.Background()) }()
+	require.Eventually(t, manager.Draining, time.Second, time.Millisecond)
+	foldHand(t, engine)
+
+	select {
+	case result := <-drained:
+		assert.Equal(t, DrainResult{Finished: 1, Snapshotted: 1}, result)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Drain did not return once the hand ended")
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 1, handsStarted(broadcaster), "no hand is dealt after draining")
+	assert.Contains(t, store.saved, table.ID)
+	assert.ErrorIs(t, manager.tryStartGame(table), ErrServerDraining)
+}
+
+func TestDrainSnapshotsHandsStillBeingPlayed(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1", "p2")
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+	require.NoError(t, manager.tryStartGame(table))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, DrainResult{MidHand: 1, Snapshotted: 1}, manager.Drain(ctx))
+
+	restarted := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(restarted.Stop)
+	_, err = restarted.RestoreTables(store)
+	require.NoError(t, err)
+	reopened, err := restarted.GetTable(table.ID)
+	require.NoError(t, err)
+	assert.Equal(t, GameStateInProgress, reopened.GameEngine.GetState(), "the hand resumes after the restart")
+}
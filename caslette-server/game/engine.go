@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 )
 
@@ -33,6 +34,8 @@ type GameEvent struct {
 	PlayerID  string                 `json:"playerId,omitempty"`
 	Data      map[string]interface{} `json:"data"`
 	Timestamp time.Time              `json:"timestamp"`
+	Sequence  uint64                 `json:"sequence,omitempty"` // Monotonic per engine, assigned on emit
+	HandID    string                 `json:"handId,omitempty"`   // The hand the event belongs to, assigned on emit
 }
 
 // GameAction represents an action a player can take
@@ -86,6 +89,17 @@ type GameEngine interface {
 	GetGameState() map[string]interface{}
 	GetHandHistory(limit int) []map[string]interface{}
 	GetPlayerStats(playerID string) map[string]interface{}
+
+	// Crash recovery: the engine's state, loaded back into a fresh engine
+	// of the same game type after a restart
+	Serialize() ([]byte, error)
+	Deserialize(data []byte) error
+}
+
+// PlayerState is a game's own typed state for one seated player, kept by
+// the base engine alongside the player it belongs to
+type PlayerState interface {
+	BasePlayer() *Player
 }
 
 // BaseGameEngine provides common functionality for all game engines
@@ -93,11 +107,20 @@ type BaseGameEngine struct {
 	gameID      string
 	state       GameState
 	players     map[string]*Player
+	states      map[string]PlayerState
 	gameData    map[string]interface{}
 	events      []*GameEvent
 	callbacks   []func(*GameEvent)
+	eventSeq    uint64
 	currentTurn int
 	config      map[string]interface{}
+	handLog     []GameEvent // Events of the hand being played
+	handLogOpen bool
+	lastHandLog []GameEvent // Events of the last hand completed
+	logMu       sync.Mutex  // Protects lastHandLog, handID and handLive
+
+	handID   string // The hand being played, or the last one played
+	handLive bool   // Whether that hand is still being played
 }
 
 // NewBaseGameEngine creates a new base game engine
@@ -106,6 +129,7 @@ func NewBaseGameEngine(gameID string) *BaseGameEngine {
 		gameID:    gameID,
 		state:     GameStateWaiting,
 		players:   make(map[string]*Player),
+		states:    make(map[string]PlayerState),
 		gameData:  make(map[string]interface{}),
 		events:    make([]*GameEvent, 0),
 		callbacks: make([]func(*GameEvent), 0),
@@ -134,7 +158,8 @@ func (b *BaseGameEngine) SetState(state GameState) {
 
 // AddPlayer adds a player to the game
 func (b *BaseGameEngine) AddPlayer(player *Player) error {
-	if b.state != GameStateWaiting {
+	// Players join before the first hand or between hands
+	if b.state != GameStateWaiting && b.state != GameStateFinished {
 		return fmt.Errorf("cannot add player when game state is %s", b.state)
 	}
 
@@ -166,6 +191,7 @@ func (b *BaseGameEngine) RemovePlayer(playerID string) error {
 	}
 
 	delete(b.players, playerID)
+	delete(b.states, playerID)
 
 	b.emitEvent(&GameEvent{
 		Type:     "player_left",
@@ -188,6 +214,21 @@ func (b *BaseGameEngine) GetPlayer(playerID string) (*Player, error) {
 	return player, nil
 }
 
+// SetPlayerState stores a game's typed state for a seated player
+func (b *BaseGameEngine) SetPlayerState(state PlayerState) error {
+	player := state.BasePlayer()
+	if _, exists := b.players[player.ID]; !exists {
+		return fmt.Errorf("player %s not found", player.ID)
+	}
+	b.states[player.ID] = state
+	return nil
+}
+
+// PlayerState returns the typed state stored for a player, nil if none
+func (b *BaseGameEngine) PlayerState(playerID string) PlayerState {
+	return b.states[playerID]
+}
+
 // GetPlayers returns all players
 func (b *BaseGameEngine) GetPlayers() []*Player {
 	players := make([]*Player, 0, len(b.players))
@@ -252,7 +293,18 @@ func (b *BaseGameEngine) SubscribeToEvents(callback func(*GameEvent)) {
 
 // emitEvent emits an event to all subscribers
 func (b *BaseGameEngine) emitEvent(event *GameEvent) {
+	// Sequence numbers let subscribers restore emission order, since
+	// callbacks run on their own goroutines
+	b.eventSeq++
+	event.Sequence = b.eventSeq
+	if event.HandID == "" {
+		event.HandID = b.handID
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 	b.events = append(b.events, event)
+	b.logHandEvent(event)
 	for _, callback := range b.callbacks {
 		go callback(event)
 	}
@@ -0,0 +1,179 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Escrow sweeper defaults
+const (
+	DefaultEscrowSweepInterval = 10 * time.Minute
+	EscrowSweepAuditAction     = "escrow_sweep"
+	MaxEscrowSweepHistory      = 50 // Sweeps that found orphaned escrow kept for the admin report
+)
+
+// EscrowRefund is one player's orphaned escrow handled by a sweep
+type EscrowRefund struct {
+	TableID  string `json:"table_id"`
+	PlayerID string `json:"player_id"`
+	Amount   int64  `json:"amount"`
+	Refunded bool   `json:"refunded"`        // Credited back in diamonds; practice and tournament chips are only released
+	Error    string `json:"error,omitempty"` // Why the escrow is still held
+}
+
+// EscrowSweep reports the orphaned escrow one sweep found
+type EscrowSweep struct {
+	SweptAt  time.Time      `json:"swept_at"`
+	Tables   []string       `json:"tables"`
+	Refunds  []EscrowRefund `json:"refunds"`
+	Refunded int64          `json:"refunded"` // Diamonds credited back
+	Failed   int            `json:"failed"`   // Refunds left for the next sweep
+}
+
+// EscrowSweeper releases escrow held for tables that were closed or lost,
+// e.g. in a crash, paying diamond buy-ins back to the players' wallets. Each
+// refund is written to the audit trail and sweeps that found anything are
+// kept for admins.
+type EscrowSweeper struct {
+	tableManager *ActorTableManager
+	escrow       *ChipEscrow
+	auditor      AuditLogger
+	now          func() time.Time
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	history []EscrowSweep
+}
+
+// NewEscrowSweeper creates a sweeper for the manager's escrow
+func NewEscrowSweeper(tableManager *ActorTableManager, auditor AuditLogger) *EscrowSweeper {
+	return &EscrowSweeper{
+		tableManager: tableManager,
+		escrow:       tableManager.Escrow(),
+		auditor:      auditor,
+		now:          time.Now,
+	}
+}
+
+// Sweep refunds the escrow of every table that is closed or no longer
+// managed. Refunds that fail stay escrowed and are retried next sweep.
+func (s *EscrowSweeper) Sweep() EscrowSweep {
+	sweep := EscrowSweep{SweptAt: s.now(), Tables: make([]string, 0), Refunds: make([]EscrowRefund, 0)}
+	ledger := s.tableManager.diamondLedger()
+	for _, tableID := range s.escrow.Tables() {
+		if !s.orphaned(tableID) {
+			continue
+		}
+		sweep.Tables = append(sweep.Tables, tableID)
+		backed := s.escrow.LedgerBacked(tableID)
+		balances := s.escrow.Balances(tableID)
+		for _, playerID := range sortedKeys(balances) {
+			refund := s.refund(ledger, backed, tableID, playerID, balances[playerID])
+			if refund.Error != "" {
+				sweep.Failed++
+			} else if refund.Refunded {
+				sweep.Refunded += refund.Amount
+			}
+			sweep.Refunds = append(sweep.Refunds, refund)
+		}
+	}
+
+	if len(sweep.Tables) > 0 {
+		log.Printf("EscrowSweeper: swept %d orphaned tables, refunded %d diamonds, %d refunds failed",
+			len(sweep.Tables), sweep.Refunded, sweep.Failed)
+		s.mu.Lock()
+		s.history = append(s.history, sweep)
+		if len(s.history) > MaxEscrowSweepHistory {
+			s.history = s.history[len(s.history)-MaxEscrowSweepHistory:]
+		}
+		s.mu.Unlock()
+	}
+	return sweep
+}
+
+// orphaned reports whether escrow held for a table has nothing left to back
+func (s *EscrowSweeper) orphaned(tableID string) bool {
+	table, err := s.tableManager.GetTable(tableID)
+	return err != nil || table.Status == TableStatusClosed
+}
+
+// refund pays one player's escrow back and releases it
+func (s *EscrowSweeper) refund(ledger DiamondLedger, backed bool, tableID, playerID string, amount int64) EscrowRefund {
+	refund := EscrowRefund{TableID: tableID, PlayerID: playerID, Amount: amount}
+	if backed {
+		if ledger == nil {
+			refund.Error = "no diamond ledger configured"
+			s.audit(playerID, tableID, "failed", refund.Error)
+			return refund
+		}
+		if err := ledger.Credit(playerID, int(amount), "Escrow refund: table "+tableID); err != nil {
+			refund.Error = err.Error()
+			s.audit(playerID, tableID, "failed", fmt.Sprintf("refund of %d diamonds failed: %v", amount, err))
+			return refund
+		}
+		refund.Refunded = true
+	}
+
+	s.escrow.Release(tableID, playerID)
+	if refund.Refunded {
+		s.audit(playerID, tableID, "refunded", fmt.Sprintf("refunded %d diamonds escrowed for a closed or missing table", amount))
+	} else {
+		s.audit(playerID, tableID, "released", fmt.Sprintf("released %d chips not paid for in diamonds", amount))
+	}
+	return refund
+}
+
+// audit writes a sweep entry when an auditor is configured
+func (s *EscrowSweeper) audit(userID, tableID, outcome, details string) {
+	if s.auditor != nil {
+		s.auditor.LogAction(userID, tableID, EscrowSweepAuditAction, outcome, details)
+	}
+}
+
+// History returns the recent sweeps that found orphaned escrow, oldest first
+func (s *EscrowSweeper) History() []EscrowSweep {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]EscrowSweep(nil), s.history...)
+}
+
+// Start sweeps periodically until Stop is called
+func (s *EscrowSweeper) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultEscrowSweepInterval
+	}
+
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	stop := s.stop
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.Sweep()
+			}
+		}
+	}()
+}
+
+// Stop ends periodic sweeps
+func (s *EscrowSweeper) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
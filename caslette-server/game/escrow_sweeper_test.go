@@ -0,0 +1,115 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingCredits is a ledger whose refunds are refused
+type failingCredits struct {
+	*fakeLedger
+}
+
+func (l failingCredits) Credit(playerID string, amount int, description string) error {
+	return errors.New("wallet unavailable")
+}
+
+func TestEscrowSweeperRefundsClosedTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ledger := newFakeLedger(map[string]int{"p1": 1200, "p2": 1000, "p3": 1000})
+	manager.SetDiamondLedger(ledger)
+	auditor := NewSecurityAuditor()
+	closed := newBalancingTable(t, manager, "closed", DefaultTableSettings(), 0)
+	open := newBalancingTable(t, manager, "open", DefaultTableSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, closed.ID, "p1", 1200))
+	require.NoError(t, joinWithBuyIn(manager, closed.ID, "p2", 1000))
+	require.NoError(t, joinWithBuyIn(manager, open.ID, "p3", 1000))
+	require.NoError(t, manager.CloseTable(closed.ID))
+
+	sweeper := NewEscrowSweeper(manager, auditor)
+	sweep := sweeper.Sweep()
+	assert.Equal(t, []string{closed.ID}, sweep.Tables, "open tables keep their escrow")
+	require.Len(t, sweep.Refunds, 2)
+	assert.Equal(t, EscrowRefund{TableID: closed.ID, PlayerID: "p1", Amount: 1200, Refunded: true}, sweep.Refunds[0])
+	assert.Equal(t, int64(2200), sweep.Refunded)
+	assert.Zero(t, sweep.Failed)
+
+	assert.Equal(t, 1200, ledger.balance("p1"))
+	assert.Equal(t, 1000, ledger.balance("p2"))
+	assert.Zero(t, manager.Escrow().Total(closed.ID))
+	assert.Equal(t, int64(1000), manager.Escrow().Total(open.ID))
+
+	logs := auditor.GetAuditLogs(0)
+	require.Len(t, logs, 2)
+	assert.Equal(t, EscrowSweepAuditAction, logs[0].Action)
+	assert.Equal(t, "refunded", logs[0].Result)
+
+	// Nothing is refunded twice
+	assert.Empty(t, sweeper.Sweep().Tables)
+	assert.Len(t, sweeper.History(), 1, "only sweeps that found escrow are kept")
+}
+
+func TestEscrowSweeperReleasesChipsNotPaidInDiamonds(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ledger := newFakeLedger(map[string]int{})
+	manager.SetDiamondLedger(ledger)
+	settings := DefaultTableSettings()
+	settings.Currency = CurrencyPlayMoney
+	practice := newBalancingTable(t, manager, "practice", settings, 0)
+	require.NoError(t, joinWithBuyIn(manager, practice.ID, "p1", 1000))
+	require.NoError(t, manager.CloseTable(practice.ID))
+
+	sweep := NewEscrowSweeper(manager, nil).Sweep()
+	require.Len(t, sweep.Refunds, 1)
+	assert.False(t, sweep.Refunds[0].Refunded)
+	assert.Zero(t, sweep.Refunded)
+	assert.Zero(t, ledger.balance("p1"), "play money is never credited as diamonds")
+	assert.Zero(t, manager.Escrow().Total(practice.ID))
+}
+
+func TestEscrowSweeperKeepsEscrowWhenRefundFails(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	manager.SetDiamondLedger(failingCredits{newFakeLedger(map[string]int{"p1": 1000})})
+	auditor := NewSecurityAuditor()
+	table := newBalancingTable(t, manager, "crashed", DefaultTableSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 1000))
+	require.NoError(t, manager.CloseTable(table.ID))
+
+	sweeper := NewEscrowSweeper(manager, auditor)
+	sweep := sweeper.Sweep()
+	assert.Equal(t, 1, sweep.Failed)
+	assert.Equal(t, "wallet unavailable", sweep.Refunds[0].Error)
+	assert.Equal(t, int64(1000), manager.Escrow().Total(table.ID), "the escrow waits for the next sweep")
+	assert.Equal(t, "failed", auditor.GetAuditLogs(0)[0].Result)
+
+	manager.SetDiamondLedger(newFakeLedger(map[string]int{}))
+	assert.Equal(t, int64(1000), sweeper.Sweep().Refunded)
+	assert.Len(t, sweeper.History(), 2)
+}
+
+func TestEscrowSweeperRefundsTablesNotRestored(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	table := newBalancingTable(t, manager, "lost", DefaultTableSettings(), 0)
+	tableJSON, err := json.Marshal(table)
+	require.NoError(t, err)
+	store := newMemorySnapshots()
+	require.NoError(t, store.SaveSnapshot(TableSnapshot{
+		TableID: table.ID, Table: tableJSON, Engine: []byte("corrupt"), Escrow: map[string]int64{"p1": 1000},
+	}))
+
+	restarted := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(restarted.Stop)
+	ledger := newFakeLedger(map[string]int{})
+	restarted.SetDiamondLedger(ledger)
+	restored, err := restarted.RestoreTables(store)
+	require.NoError(t, err)
+	assert.Zero(t, restored)
+	assert.NotContains(t, store.saved, table.ID, "the buy-ins are not held twice")
+
+	assert.Equal(t, int64(1000), NewEscrowSweeper(restarted, nil).Sweep().Refunded)
+	assert.Equal(t, 1000, ledger.balance("p1"))
+}
@@ -0,0 +1,304 @@
+package game
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCoalesceInterval is how long bursty table events are held for batching
+const DefaultCoalesceInterval = 100 * time.Millisecond
+
+// defaultCoalescibleEvents are the board and pot updates that are batched per
+// tick. Any other event flushes the table's pending batch immediately.
+var defaultCoalescibleEvents = []string{
+	"flop_dealt", "turn_dealt", "river_dealt", "state_changed", "blinds_posted",
+	"player_called", "player_bet", "player_raised", "player_checked", "player_all_in",
+}
+
+// GameEventFrame is a batch of table events broadcast as one message.
+// Events are in emission order and frames are numbered per table.
+type GameEventFrame struct {
+	TableID string       `json:"table_id"`
+	Frame   uint64       `json:"frame"`
+	Events  []*GameEvent `json:"events"`
+}
+
+// tableEventBuffer holds one table's events awaiting broadcast
+type tableEventBuffer struct {
+	roomID       string
+	pending      []*GameEvent
+	lastSequence uint64
+	frames       uint64
+	gapSince     time.Time
+
+	// Frames sent to the players and held back from the observer room
+	// until the table's spectator delay has passed
+	observerRoom string
+	delay        time.Duration
+	delayed      []delayedFrame
+}
+
+// delayedFrame is a frame due to reach a table's observers
+type delayedFrame struct {
+	due   time.Time
+	frame GameEventFrame
+}
+
+// EventCoalescer batches game events into per-table broadcast frames so that a
+// burst, such as an all-in board runout, reaches clients as one frame rather
+// than one message per card and pot update
+type EventCoalescer struct {
+	hub         WebSocketHub
+	interval    time.Duration
+	coalescible map[string]bool
+
+	mu     sync.Mutex
+	sendMu sync.Mutex // Serializes broadcasts so frames leave in order
+	tables map[string]*tableEventBuffer
+	stop   chan struct{}
+}
+
+// NewEventCoalescer creates a coalescer flushing at the given interval
+func NewEventCoalescer(hub WebSocketHub, interval time.Duration) *EventCoalescer {
+	if interval <= 0 {
+		interval = DefaultCoalesceInterval
+	}
+	coalescible := make(map[string]bool, len(defaultCoalescibleEvents))
+	for _, eventType := range defaultCoalescibleEvents {
+		coalescible[eventType] = true
+	}
+	return &EventCoalescer{
+		hub:         hub,
+		interval:    interval,
+		coalescible: coalescible,
+		tables:      make(map[string]*tableEventBuffer),
+	}
+}
+
+// Start begins flushing pending batches every interval
+func (ec *EventCoalescer) Start() {
+	ec.mu.Lock()
+	if ec.stop != nil {
+		ec.mu.Unlock()
+		return
+	}
+	ec.stop = make(chan struct{})
+	stop := ec.stop
+	ec.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(ec.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				ec.FlushAll(now)
+			}
+		}
+	}()
+}
+
+// Stop halts the flush loop; pending events are flushed first
+func (ec *EventCoalescer) Stop() {
+	ec.mu.Lock()
+	if ec.stop != nil {
+		close(ec.stop)
+		ec.stop = nil
+	}
+	ec.mu.Unlock()
+	ec.FlushAll(time.Now().Add(ec.interval))
+}
+
+// OnGameEvent queues an event for the table (GameEventBroadcaster interface).
+// Sequenced events are deduplicated and reordered by sequence number.
+func (ec *EventCoalescer) OnGameEvent(table *GameTable, event *GameEvent) {
+	if table == nil || event == nil {
+		return
+	}
+
+	ec.mu.Lock()
+	buffer, exists := ec.tables[table.ID]
+	if !exists {
+		buffer = &tableEventBuffer{}
+		ec.tables[table.ID] = buffer
+	}
+	buffer.roomID, buffer.observerRoom, buffer.delay = table.RoomID, table.ObserverRoomID(), table.SpectatorDelay()
+
+	if event.Sequence != 0 {
+		if event.Sequence <= buffer.lastSequence || buffer.hasSequence(event.Sequence) {
+			ec.mu.Unlock()
+			return
+		}
+	}
+	buffer.insert(event)
+	immediate := !ec.coalescible[event.Type]
+	ec.mu.Unlock()
+
+	if immediate {
+		ec.flushTable(table.ID, time.Now(), false)
+	}
+}
+
+// FlushAll broadcasts every table's ready events, and to observers the
+// delayed frames now due
+func (ec *EventCoalescer) FlushAll(now time.Time) {
+	ec.mu.Lock()
+	tableIDs := make([]string, 0, len(ec.tables))
+	delayedIDs := make([]string, 0)
+	for tableID, buffer := range ec.tables {
+		if len(buffer.pending) > 0 {
+			tableIDs = append(tableIDs, tableID)
+		}
+		if len(buffer.delayed) > 0 && !buffer.delayed[0].due.After(now) {
+			delayedIDs = append(delayedIDs, tableID)
+		}
+	}
+	ec.mu.Unlock()
+
+	for _, tableID := range tableIDs {
+		ec.flushTable(tableID, now, true)
+	}
+	for _, tableID := range delayedIDs {
+		ec.flushDelayed(tableID, now)
+	}
+}
+
+// Forget flushes and drops a table's buffer, e.g. when the table closes.
+// Observers are sent the frames still held back, as no hand is left to
+// protect.
+func (ec *EventCoalescer) Forget(tableID string) {
+	ec.flushTable(tableID, time.Now().Add(ec.interval), true)
+	ec.flushDelayed(tableID, time.Time{})
+	ec.mu.Lock()
+	delete(ec.tables, tableID)
+	ec.mu.Unlock()
+}
+
+// flushTable broadcasts the table's contiguous run of pending events. A gap in
+// sequence numbers holds later events back for up to one interval in case
+// the missing event is still in flight; on a tick the gap is then skipped.
+func (ec *EventCoalescer) flushTable(tableID string, now time.Time, tick bool) {
+	ec.sendMu.Lock()
+	defer ec.sendMu.Unlock()
+
+	ec.mu.Lock()
+	buffer, exists := ec.tables[tableID]
+	if !exists || len(buffer.pending) == 0 {
+		ec.mu.Unlock()
+		return
+	}
+
+	ready := buffer.takeReady(now, tick, ec.interval)
+	if len(ready) == 0 {
+		ec.mu.Unlock()
+		return
+	}
+	buffer.frames++
+	frame := GameEventFrame{TableID: tableID, Frame: buffer.frames, Events: ready}
+	roomID := buffer.roomID
+	if buffer.delay > 0 {
+		buffer.delayed = append(buffer.delayed, delayedFrame{due: now.Add(buffer.delay), frame: frame})
+	}
+	ec.mu.Unlock()
+
+	ec.broadcastFrame(roomID, frame)
+}
+
+// flushDelayed sends a table's observers the delayed frames due by now; a
+// zero time sends them all
+func (ec *EventCoalescer) flushDelayed(tableID string, now time.Time) {
+	ec.sendMu.Lock()
+	defer ec.sendMu.Unlock()
+
+	ec.mu.Lock()
+	buffer, exists := ec.tables[tableID]
+	if !exists {
+		ec.mu.Unlock()
+		return
+	}
+	due := 0
+	for due < len(buffer.delayed) && (now.IsZero() || !buffer.delayed[due].due.After(now)) {
+		due++
+	}
+	frames := buffer.delayed[:due:due]
+	buffer.delayed = append([]delayedFrame(nil), buffer.delayed[due:]...)
+	roomID := buffer.observerRoom
+	ec.mu.Unlock()
+
+	for _, delayed := range frames {
+		ec.broadcastFrame(roomID, delayed.frame)
+	}
+}
+
+// broadcastFrame sends a frame to a room
+func (ec *EventCoalescer) broadcastFrame(roomID string, frame GameEventFrame) {
+	if ec.hub == nil {
+		return
+	}
+	msg := &WebSocketMessage{
+		Type:    "game_events",
+		Data:    frame,
+		Success: true,
+		Room:    roomID,
+	}
+	if err := ec.hub.BroadcastToRoom(roomID, msg); err != nil {
+		log.Printf("EventCoalescer: failed to broadcast to room %s: %v", roomID, err)
+	}
+}
+
+// hasSequence reports whether an event with the sequence is already pending
+func (b *tableEventBuffer) hasSequence(sequence uint64) bool {
+	for _, event := range b.pending {
+		if event.Sequence == sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// insert adds an event in sequence order. Unsequenced events keep their
+// arrival position and sequenced events never move ahead of them.
+func (b *tableEventBuffer) insert(event *GameEvent) {
+	b.pending = append(b.pending, event)
+	if event.Sequence == 0 {
+		return
+	}
+	i := len(b.pending) - 1
+	for i > 0 && b.pending[i-1].Sequence > event.Sequence {
+		b.pending[i] = b.pending[i-1]
+		i--
+	}
+	b.pending[i] = event
+}
+
+// takeReady removes and returns the events that can be sent now in order
+func (b *tableEventBuffer) takeReady(now time.Time, tick bool, maxGap time.Duration) []*GameEvent {
+	ready := 0
+	for ready < len(b.pending) {
+		sequence := b.pending[ready].Sequence
+		if sequence != 0 && sequence != b.lastSequence+1 {
+			// Gap: wait for the missing event unless it is overdue
+			if b.gapSince.IsZero() {
+				b.gapSince = now
+			}
+			if !tick || now.Sub(b.gapSince) < maxGap {
+				break
+			}
+			b.gapSince = time.Time{}
+		}
+		if sequence != 0 {
+			b.lastSequence = sequence
+		}
+		ready++
+	}
+	if ready == len(b.pending) {
+		b.gapSince = time.Time{}
+	}
+
+	events := b.pending[:ready:ready]
+	b.pending = append([]*GameEvent(nil), b.pending[ready:]...)
+	return events
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func coalescedFrames(t *testing.T, hub *recordingHub, roomID string) []GameEventFrame {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	frames := make([]GameEventFrame, 0, len(hub.messages[roomID]))
+	for _, msg := range hub.messages[roomID] {
+		wsMsg := msg.(*WebSocketMessage)
+		require.Equal(t, "game_events", wsMsg.Type)
+		frames = append(frames, wsMsg.Data.(GameEventFrame))
+	}
+	return frames
+}
+
+func frameEventTypes(frame GameEventFrame) []string {
+	types := make([]string, len(frame.Events))
+	for i, event := range frame.Events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func TestEventCoalescerBatchesBoardRunout(t *testing.T) {
+	hub := newRecordingHub()
+	coalescer := NewEventCoalescer(hub, time.Second)
+	table := NewGameTable("t1", "Runout", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+
+	coalescer.OnGameEvent(table, &GameEvent{Type: "player_all_in", Sequence: 1})
+	coalescer.OnGameEvent(table, &GameEvent{Type: "flop_dealt", Sequence: 2})
+	coalescer.OnGameEvent(table, &GameEvent{Type: "turn_dealt", Sequence: 3})
+	coalescer.OnGameEvent(table, &GameEvent{Type: "river_dealt", Sequence: 4})
+	assert.Empty(t, coalescedFrames(t, hub, table.RoomID), "board updates wait for the tick")
+
+	// A non-coalescible event flushes the whole burst as one frame
+	coalescer.OnGameEvent(table, &GameEvent{Type: "showdown", Sequence: 5})
+
+	frames := coalescedFrames(t, hub, table.RoomID)
+	require.Len(t, frames, 1)
+	assert.Equal(t, uint64(1), frames[0].Frame)
+	assert.Equal(t, []string{"player_all_in", "flop_dealt", "turn_dealt", "river_dealt", "showdown"}, frameEventTypes(frames[0]))
+}
+
+func TestEventCoalescerPreservesOrderAndDeduplicates(t *testing.T) {
+	hub := newRecordingHub()
+	coalescer := NewEventCoalescer(hub, time.Second)
+	table := NewGameTable("t1", "Order", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+
+	now := time.Now()
+	// Callbacks may deliver out of order
+	coalescer.OnGameEvent(table, &GameEvent{Type: "turn_dealt", Sequence: 3})
+	coalescer.OnGameEvent(table, &GameEvent{Type: "player_called", Sequence: 1})
+	coalescer.FlushAll(now)
+
+	// Sequence 2 is missing, so only sequence 1 goes out
+	frames := coalescedFrames(t, hub, table.RoomID)
+	require.Len(t, frames, 1)
+	assert.Equal(t, []string{"player_called"}, frameEventTypes(frames[0]))
+
+	coalescer.OnGameEvent(table, &GameEvent{Type: "flop_dealt", Sequence: 2})
+	coalescer.OnGameEvent(table, &GameEvent{Type: "player_called", Sequence: 1}) // duplicate
+	coalescer.FlushAll(now.Add(10 * time.Millisecond))
+
+	frames = coalescedFrames(t, hub, table.RoomID)
+	require.Len(t, frames, 2)
+	assert.Equal(t, []string{"flop_dealt", "turn_dealt"}, frameEventTypes(frames[1]))
+	assert.Equal(t, uint64(2), frames[1].Frame)
+}
+
+func TestEventCoalescerSkipsOverdueGap(t *testing.T) {
+	hub := newRecordingHub()
+	coalescer := NewEventCoalescer(hub, 100*time.Millisecond)
+	table := NewGameTable("t1", "Gap", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+
+	now := time.Now()
+	coalescer.OnGameEvent(table, &GameEvent{Type: "river_dealt", Sequence: 2})
+	coalescer.FlushAll(now)
+	assert.Empty(t, coalescedFrames(t, hub, table.RoomID))
+
+	coalescer.FlushAll(now.Add(150 * time.Millisecond))
+	frames := coalescedFrames(t, hub, table.RoomID)
+	require.Len(t, frames, 1)
+	assert.Equal(t, []string{"river_dealt"}, frameEventTypes(frames[0]))
+
+	// The late event is dropped rather than delivered out of order
+	coalescer.OnGameEvent(table, &GameEvent{Type: "turn_dealt", Sequence: 1})
+	coalescer.FlushAll(now.Add(300 * time.Millisecond))
+	assert.Len(t, coalescedFrames(t, hub, table.RoomID), 1)
+}
+
+func TestEngineEventsCarrySequenceInEmissionOrder(t *testing.T) {
+	engine := NewTexasHoldemEngine("seq")
+	require.NoError(t, engine.AddPlayer(&Player{ID: "p1", Name: "p1", Data: map[string]interface{}{"chips": 1000}}))
+	require.NoError(t, engine.AddPlayer(&Player{ID: "p2", Name: "p2", Data: map[string]interface{}{"chips": 1000}}))
+	require.NoError(t, engine.Start())
+
+	events := engine.GetEvents()
+	require.NotEmpty(t, events)
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.Sequence)
+	}
+}
+
+func TestEventCoalescerDelaysObserverFrames(t *testing.T) {
+	hub := newRecordingHub()
+	coalescer := NewEventCoalescer(hub, 100*time.Millisecond)
+	settings := DefaultTableSettings()
+	settings.SpectatorDelay = 30
+	table := NewGameTable("t1", "Delayed", GameTypeTexasHoldem, "creator", settings)
+
+	now := time.Now()
+	coalescer.OnGameEvent(table, &GameEvent{Type: "player_raised", Sequence: 1})
+	coalescer.FlushAll(now)
+	coalescer.OnGameEvent(table, &GameEvent{Type: "flop_dealt", Sequence: 2})
+	coalescer.FlushAll(now.Add(time.Second))
+
+	require.Len(t, coalescedFrames(t, hub, table.RoomID), 2, "players see events as they happen")
+	assert.Empty(t, coalescedFrames(t, hub, table.ObserverRoomID()))
+
+	coalescer.FlushAll(now.Add(30 * time.Second))
+	observed := coalescedFrames(t, hub, table.ObserverRoomID())
+	require.Len(t, observed, 1)
+	assert.Equal(t, []string{"player_raised"}, frameEventTypes(observed[0]))
+
+	// Closing the table releases what is still held back
+	coalescer.Forget(table.ID)
+	observed = coalescedFrames(t, hub, table.ObserverRoomID())
+	require.Len(t, observed, 2)
+	assert.Equal(t, uint64(2), observed[1].Frame)
+}
+
+func TestEventCoalescerWithoutDelayUsesOneRoom(t *testing.T) {
+	hub := newRecordingHub()
+	coalescer := NewEventCoalescer(hub, 100*time.Millisecond)
+	table := NewGameTable("t1", "Live", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+
+	coalescer.OnGameEvent(table, &GameEvent{Type: "showdown", Sequence: 1})
+	coalescer.FlushAll(time.Now().Add(time.Hour))
+	assert.Len(t, coalescedFrames(t, hub, table.RoomID), 1)
+	assert.Empty(t, coalescedFrames(t, hub, table.ObserverRoomID()))
+}
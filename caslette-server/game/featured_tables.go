@@ -0,0 +1,173 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits on the branding an admin puts on a featured table
+const (
+	MaxBrandingKeys        = 10
+	MaxBrandingKeyLength   = 32
+	MaxBrandingValueLength = 256
+)
+
+// Branding keys are letters, digits, hyphens and underscores
+var brandingKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,32}$`)
+
+// TableFeature marks a table an admin pinned to the top of the lobby.
+// Branding is free-form metadata for clients to dress the table in, such as
+// a sponsor, a banner or colors.
+type TableFeature struct {
+	FeaturedBy string            `json:"featured_by"`
+	FeaturedAt time.Time         `json:"featured_at"`
+	Branding   map[string]string `json:"branding,omitempty"`
+}
+
+// IsFeatured reports whether an admin pinned the table in the lobby.
+// Featured tables are closed only by admins.
+func (t *GameTable) IsFeatured() bool {
+	return t.Feature != nil
+}
+
+// ValidateBranding checks the branding metadata of a featured table
+func (v *TableValidator) ValidateBranding(branding map[string]string) error {
+	if len(branding) > MaxBrandingKeys {
+		return fmt.Errorf("too many branding entries (max %d)", MaxBrandingKeys)
+	}
+	for key, value := range branding {
+		if !brandingKeyRegex.MatchString(key) {
+			return fmt.Errorf("branding key %q must be 1-%d letters, digits, hyphens or underscores", v.SanitizeInput(key), MaxBrandingKeyLength)
+		}
+		if len(value) > MaxBrandingValueLength || !utf8.ValidString(value) {
+			return fmt.Errorf("branding %s must be valid text of at most %d characters", key, MaxBrandingValueLength)
+		}
+		if v.containsSQLInjectionPatterns(value) {
+			return fmt.Errorf("branding %s contains invalid patterns", key)
+		}
+	}
+	return nil
+}
+
+// FeatureTableCommand pins a table in the lobby with the given branding,
+// replacing the branding of a table already featured, or unpins it
+type FeatureTableCommand struct {
+	Featured   bool
+	FeaturedBy string
+	Branding   map[string]string
+	Response   chan interface{}
+}
+
+func (cmd *FeatureTableCommand) Execute(table *GameTable) interface{} {
+	if table.Status == TableStatusClosed {
+		return &TableError{"TABLE_CLOSED", "Table is closed"}
+	}
+	now := time.Now()
+	table.UpdatedAt = now
+	if !cmd.Featured {
+		if table.Feature == nil {
+			return &TableError{"NOT_FEATURED", "Table is not featured"}
+		}
+		table.Feature = nil
+		return TableFeature{}
+	}
+
+	feature := TableFeature{FeaturedBy: cmd.FeaturedBy, FeaturedAt: now, Branding: cmd.Branding}
+	if table.Feature != nil {
+		// Re-branding keeps the table's place among the featured
+		feature.FeaturedAt = table.Feature.FeaturedAt
+	}
+	table.Feature = &feature
+	return feature
+}
+
+// Feature sends a feature change to the table actor
+func (ta *TableActor) Feature(ctx context.Context, featured bool, featuredBy string, branding map[string]string) (TableFeature, error) {
+	cmd := &FeatureTableCommand{
+		Featured:   featured,
+		FeaturedBy: featuredBy,
+		Branding:   branding,
+		Response:   make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return TableFeature{}, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return TableFeature{}, err
+		}
+		if feature, ok := result.(TableFeature); ok {
+			return feature, nil
+		}
+		return TableFeature{}, &TableError{"UNEXPECTED_RESPONSE", "Unexpected response type"}
+	case <-ctx.Done():
+		return TableFeature{}, ctx.Err()
+	}
+}
+
+// FeatureTable pins a table at the top of the lobby with the given branding,
+// or updates the branding of a table already featured. Only admins may.
+func (tm *ActorTableManager) FeatureTable(ctx context.Context, tableID, adminID string, branding map[string]string) (TableFeature, error) {
+	if err := tm.validator.ValidateBranding(branding); err != nil {
+		return TableFeature{}, &TableError{"INVALID_BRANDING", err.Error()}
+	}
+	return tm.setFeatured(ctx, tableID, true, adminID, branding)
+}
+
+// UnfeatureTable unpins a featured table, which its creator may then close
+func (tm *ActorTableManager) UnfeatureTable(ctx context.Context, tableID string) error {
+	_, err := tm.setFeatured(ctx, tableID, false, "", nil)
+	return err
+}
+
+// setFeatured changes a table's feature through its actor and tells the
+// lobby
+func (tm *ActorTableManager) setFeatured(ctx context.Context, tableID string, featured bool, adminID string, branding map[string]string) (TableFeature, error) {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return TableFeature{}, ErrTableNotFound
+	}
+
+	feature, err := actor.Feature(ctx, featured, adminID, branding)
+	if err != nil {
+		return TableFeature{}, err
+	}
+	tm.tableChanged(actor)
+	return feature, nil
+}
+
+// FeaturedTables lists the featured tables in the order they were pinned
+func (tm *ActorTableManager) FeaturedTables() []*GameTable {
+	featured := make([]*GameTable, 0)
+	for _, table := range tm.GetTables() {
+		if table.IsFeatured() {
+			featured = append(featured, table)
+		}
+	}
+	sortFeaturedFirst(featured)
+	return featured
+}
+
+// sortFeaturedFirst moves featured tables to the front in the order they
+// were pinned, keeping the order of the rest
+func sortFeaturedFirst(tables []*GameTable) {
+	sort.SliceStable(tables, func(i, j int) bool {
+		a, b := tables[i].Feature, tables[j].Feature
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.FeaturedAt.Before(b.FeaturedAt)
+	})
+}
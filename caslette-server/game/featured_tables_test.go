@@ -0,0 +1,89 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeaturedTablesArePinnedFirst(t *testing.T) {
+	manager, tables := newListingTables(t)
+	ctx := context.Background()
+
+	feature, err := manager.FeatureTable(ctx, tables["mid"].ID, "admin1", map[string]string{"sponsor": "Acme", "banner_color": "#ff0000"})
+	require.NoError(t, err)
+	assert.Equal(t, "admin1", feature.FeaturedBy)
+	_, err = manager.FeatureTable(ctx, tables["low"].ID, "admin1", nil)
+	require.NoError(t, err)
+
+	page, err := manager.ListTablesPage(TableListQuery{Sort: TableSortStakes, Descending: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mid", "low", "high"}, listedNames(page), "featured in the order pinned, then the sort")
+
+	public := manager.PublicTables()
+	require.Len(t, public, 3)
+	assert.Equal(t, "mid", public[0].Name)
+	assert.Equal(t, "Acme", public[0].Branding["sponsor"])
+	assert.False(t, public[2].Featured)
+
+	info := tables["mid"].GetTableInfo()
+	assert.Equal(t, true, info["featured"])
+	listed := NewDataFilter().FilterTableList([]*GameTable{tables["mid"]}, "")
+	assert.Equal(t, "Acme", listed[0]["branding"].(map[string]string)["sponsor"])
+
+	// Re-branding keeps the table's place
+	pinnedAt := tables["mid"].Feature.FeaturedAt
+	_, err = manager.FeatureTable(ctx, tables["mid"].ID, "admin2", map[string]string{"sponsor": "Globex"})
+	require.NoError(t, err)
+	assert.Equal(t, pinnedAt, tables["mid"].Feature.FeaturedAt)
+	assert.Equal(t, []string{"mid", "low"}, []string{manager.FeaturedTables()[0].Name, manager.FeaturedTables()[1].Name})
+
+	require.NoError(t, manager.UnfeatureTable(ctx, tables["mid"].ID))
+	assert.False(t, tables["mid"].IsFeatured())
+	require.Error(t, manager.UnfeatureTable(ctx, tables["mid"].ID), "no longer featured")
+}
+
+func TestFeatureTableValidatesBranding(t *testing.T) {
+	manager, tables := newListingTables(t)
+	feature := func(branding map[string]string) error {
+		_, err := manager.FeatureTable(context.Background(), tables["low"].ID, "admin1", branding)
+		return err
+	}
+
+	assert.Error(t, feature(map[string]string{"bad key": "x"}))
+	assert.Error(t, feature(map[string]string{"sponsor": strings.Repeat("x", MaxBrandingValueLength+1)}))
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxBrandingKeys; i++ {
+		tooMany[string(rune('a'+i))] = "x"
+	}
+	assert.Error(t, feature(tooMany))
+	assert.False(t, tables["low"].IsFeatured())
+
+	_, err := manager.FeatureTable(context.Background(), "missing", "admin1", nil)
+	assert.Equal(t, ErrTableNotFound, err)
+}
+
+func TestCreatorCannotCloseFeaturedTable(t *testing.T) {
+	manager, tables := newListingTables(t)
+	handler := NewTableWebSocketHandler(manager, &MockWebSocketHub{})
+	t.Cleanup(handler.events.Stop)
+	table := tables["low"]
+	_, err := manager.FeatureTable(context.Background(), table.ID, "admin1", nil)
+	require.NoError(t, err)
+
+	closeTable := func() *WebSocketMessage {
+		return handler.GetMessageHandlers()["table_close"](context.Background(), NewMockConnection(table.CreatedBy, table.CreatedBy),
+			&WebSocketMessage{Type: "table_close", RequestID: "r1", Data: map[string]interface{}{"table_id": table.ID}})
+	}
+	response := closeTable()
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Error, "admin")
+	_, err = manager.GetTable(table.ID)
+	require.NoError(t, err, "still open")
+
+	require.NoError(t, manager.UnfeatureTable(context.Background(), table.ID))
+	assert.True(t, closeTable().Success, "unpinned tables close as usual")
+}
@@ -0,0 +1,216 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// How a showdown comparison between two hands was decided
+const (
+	DecidedByHandRank  = "hand_rank"
+	DecidedByHighCards = "high_cards"
+	DecidedByKicker    = "kicker"
+	DecidedByTie       = "tie"
+)
+
+// rankNames holds singular and plural names for each card rank
+var rankNames = map[Rank][2]string{
+	Two:   {"two", "twos"},
+	Three: {"three", "threes"},
+	Four:  {"four", "fours"},
+	Five:  {"five", "fives"},
+	Six:   {"six", "sixes"},
+	Seven: {"seven", "sevens"},
+	Eight: {"eight", "eights"},
+	Nine:  {"nine", "nines"},
+	Ten:   {"ten", "tens"},
+	Jack:  {"jack", "jacks"},
+	Queen: {"queen", "queens"},
+	King:  {"king", "kings"},
+	Ace:   {"ace", "aces"},
+}
+
+// Name returns the lowercase name of a rank, e.g. "king"
+func (r Rank) Name() string {
+	if names, ok := rankNames[r]; ok {
+		return names[0]
+	}
+	return r.String()
+}
+
+// PluralName returns the lowercase plural name of a rank, e.g. "sixes"
+func (r Rank) PluralName() string {
+	if names, ok := rankNames[r]; ok {
+		return names[1]
+	}
+	return r.String() + "s"
+}
+
+// Description returns a human readable description of the hand,
+// e.g. "Flush, king high" or "Full house, queens full of sevens"
+func (ph *PokerHand) Description() string {
+	if len(ph.HighCards) == 0 {
+		return ph.Rank.String()
+	}
+
+	primary := ph.HighCards[0]
+	switch ph.Rank {
+	case RoyalFlush:
+		return "Royal flush"
+	case StraightFlush:
+		return fmt.Sprintf("Straight flush, %s high", primary.Name())
+	case FourOfAKind:
+		return fmt.Sprintf("Four of a kind, %s", primary.PluralName())
+	case FullHouse:
+		if len(ph.HighCards) > 1 {
+			return fmt.Sprintf("Full house, %s full of %s", primary.PluralName(), ph.HighCards[1].PluralName())
+		}
+	case Flush:
+		return fmt.Sprintf("Flush, %s high", primary.Name())
+	case Straight:
+		return fmt.Sprintf("Straight, %s high", primary.Name())
+	case ThreeOfAKind:
+		return fmt.Sprintf("Three of a kind, %s", primary.PluralName())
+	case TwoPair:
+		if len(ph.HighCards) > 1 {
+			return fmt.Sprintf("Two pair, %s and %s", primary.PluralName(), ph.HighCards[1].PluralName())
+		}
+	case OnePair:
+		return fmt.Sprintf("Pair of %s", primary.PluralName())
+	case HighCard:
+		return fmt.Sprintf("High card, %s", primary.Name())
+	}
+	return ph.Rank.String()
+}
+
+// WinningHand names a pot winner's hand and the five cards it plays, so
+// clients need no evaluator of their own
+type WinningHand struct {
+	PlayerID    string `json:"playerId"`
+	Description string `json:"description"`
+	BestCards   []Card `json:"bestCards"`
+}
+
+// winningHands describes the showdown hands of a pot's winners; winners
+// who took the pot without a showdown have none
+func (the *TexasHoldemEngine) winningHands(winners []*TexasHoldemPlayer) []WinningHand {
+	hands := make([]WinningHand, 0, len(winners))
+	for _, winner := range winners {
+		if hand, ok := the.showdownHands[winner.ID]; ok {
+			hands = append(hands, WinningHand{
+				PlayerID:    winner.ID,
+				Description: hand.Description(),
+				BestCards:   hand.OrderedCards(),
+			})
+		}
+	}
+	return hands
+}
+
+// OrderedCards returns the five cards of the hand ordered by significance:
+// made cards first (e.g. the pair), then kickers. A wheel lists the ace last.
+func (ph *PokerHand) OrderedCards() []Card {
+	priority := make(map[Rank]int)
+	for i, rank := range append(append([]Rank{}, ph.HighCards...), ph.Kickers...) {
+		if _, seen := priority[rank]; !seen {
+			priority[rank] = i
+		}
+	}
+
+	wheel := (ph.Rank == Straight || ph.Rank == StraightFlush) && len(ph.HighCards) > 0 && ph.HighCards[0] == Five
+	value := func(card Card) int {
+		if wheel && card.Rank == Ace {
+			return 1
+		}
+		return int(card.Rank)
+	}
+
+	cards := make([]Card, len(ph.Cards))
+	copy(cards, ph.Cards)
+	sort.SliceStable(cards, func(i, j int) bool {
+		pi, iok := priority[cards[i].Rank]
+		pj, jok := priority[cards[j].Rank]
+		if wheel || !iok || !jok || pi == pj {
+			return value(cards[i]) > value(cards[j])
+		}
+		return pi < pj
+	})
+	return cards
+}
+
+// HandComparison explains the outcome of comparing two hands
+type HandComparison struct {
+	Result      int    `json:"result"`    // 1 if the first hand wins, -1 if it loses, 0 on a tie
+	DecidedBy   string `json:"decidedBy"` // hand_rank, high_cards, kicker or tie
+	Explanation string `json:"explanation"`
+	WinningCard Rank   `json:"winningCard,omitempty"` // Deciding rank of the better hand
+	LosingCard  Rank   `json:"losingCard,omitempty"`  // Deciding rank of the worse hand
+}
+
+// Explain compares the hand with another and describes what decided it,
+// e.g. "Pair of aces, queen kicker beats pair of aces, jack kicker"
+func (ph *PokerHand) Explain(other *PokerHand) *HandComparison {
+	result := ph.Compare(other)
+	if result == 0 {
+		return &HandComparison{
+			DecidedBy:   DecidedByTie,
+			Explanation: fmt.Sprintf("%s ties %s", ph.Description(), lowerFirst(other.Description())),
+		}
+	}
+
+	better, worse := ph, other
+	if result < 0 {
+		better, worse = other, ph
+	}
+	comparison := &HandComparison{Result: result}
+
+	if better.Rank != worse.Rank {
+		comparison.DecidedBy = DecidedByHandRank
+		comparison.Explanation = fmt.Sprintf("%s beats %s", better.Description(), lowerFirst(worse.Description()))
+		return comparison
+	}
+
+	for i := 0; i < len(better.HighCards) && i < len(worse.HighCards); i++ {
+		if better.HighCards[i] == worse.HighCards[i] {
+			continue
+		}
+		comparison.WinningCard = better.HighCards[i]
+		comparison.LosingCard = worse.HighCards[i]
+		// Past the first card, high card and flush hands are decided by kickers
+		if i > 0 && (better.Rank == HighCard || better.Rank == Flush) {
+			comparison.DecidedBy = DecidedByKicker
+			comparison.Explanation = kickerExplanation(better, comparison.WinningCard, comparison.LosingCard)
+		} else {
+			comparison.DecidedBy = DecidedByHighCards
+			comparison.Explanation = fmt.Sprintf("%s beats %s", better.Description(), lowerFirst(worse.Description()))
+		}
+		return comparison
+	}
+
+	for i := 0; i < len(better.Kickers) && i < len(worse.Kickers); i++ {
+		if better.Kickers[i] == worse.Kickers[i] {
+			continue
+		}
+		comparison.WinningCard = better.Kickers[i]
+		comparison.LosingCard = worse.Kickers[i]
+		comparison.DecidedBy = DecidedByKicker
+		comparison.Explanation = kickerExplanation(better, comparison.WinningCard, comparison.LosingCard)
+		return comparison
+	}
+
+	return comparison
+}
+
+// kickerExplanation describes a win on kickers between hands of equal strength
+func kickerExplanation(hand *PokerHand, winning, losing Rank) string {
+	return fmt.Sprintf("%s, %s kicker beats %s kicker", hand.Description(), winning.Name(), losing.Name())
+}
+
+// lowerFirst lowercases the first letter so a description can follow "beats"
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
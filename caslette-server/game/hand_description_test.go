@@ -0,0 +1,195 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPokerHandDescription(t *testing.T) {
+	evaluator := NewPokerEvaluator()
+
+	tests := []struct {
+		name     string
+		cards    []Card
+		expected string
+	}{
+		{"RoyalFlush", []Card{NewCard(Hearts, Ace), NewCard(Hearts, King), NewCard(Hearts, Queen), NewCard(Hearts, Jack), NewCard(Hearts, Ten)}, "Royal flush"},
+		{"StraightFlush", []Card{NewCard(Spades, Nine), NewCard(Spades, Eight), NewCard(Spades, Seven), NewCard(Spades, Six), NewCard(Spades, Five)}, "Straight flush, nine high"},
+		{"FourOfAKind", []Card{NewCard(Hearts, Nine), NewCard(Spades, Nine), NewCard(Diamonds, Nine), NewCard(Clubs, Nine), NewCard(Hearts, Two)}, "Four of a kind, nines"},
+		{"FullHouse", []Card{NewCard(Hearts, Queen), NewCard(Spades, Queen), NewCard(Diamonds, Queen), NewCard(Clubs, Seven), NewCard(Hearts, Seven)}, "Full house, queens full of sevens"},
+		{"Flush", []Card{NewCard(Clubs, King), NewCard(Clubs, Ten), NewCard(Clubs, Eight), NewCard(Clubs, Four), NewCard(Clubs, Two)}, "Flush, king high"},
+		{"Wheel", []Card{NewCard(Hearts, Ace), NewCard(Spades, Five), NewCard(Diamonds, Four), NewCard(Clubs, Three), NewCard(Hearts, Two)}, "Straight, five high"},
+		{"ThreeOfAKind", []Card{NewCard(Hearts, Jack), NewCard(Spades, Jack), NewCard(Diamonds, Jack), NewCard(Clubs, Four), NewCard(Hearts, Two)}, "Three of a kind, jacks"},
+		{"TwoPair", []Card{NewCard(Hearts, King), NewCard(Spades, King), NewCard(Diamonds, Four), NewCard(Clubs, Four), NewCard(Hearts, Two)}, "Two pair, kings and fours"},
+		{"OnePair", []Card{NewCard(Hearts, Six), NewCard(Spades, Six), NewCard(Diamonds, Ace), NewCard(Clubs, Four), NewCard(Hearts, Two)}, "Pair of sixes"},
+		{"HighCard", []Card{NewCard(Hearts, Ace), NewCard(Spades, Jack), NewCard(Diamonds, Nine), NewCard(Clubs, Four), NewCard(Hearts, Two)}, "High card, ace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hand := evaluator.EvaluateHand(tt.cards)
+			if got := hand.Description(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWheelLosesToSixHighStraight(t *testing.T) {
+	evaluator := NewPokerEvaluator()
+
+	wheel := evaluator.EvaluateHand([]Card{
+		NewCard(Hearts, Ace), NewCard(Spades, Five), NewCard(Diamonds, Four), NewCard(Clubs, Three), NewCard(Hearts, Two),
+	})
+	sixHigh := evaluator.EvaluateHand([]Card{
+		NewCard(Hearts, Six), NewCard(Spades, Five), NewCard(Diamonds, Four), NewCard(Clubs, Three), NewCard(Hearts, Two),
+	})
+
+	if wheel.Compare(sixHigh) != -1 {
+		t.Errorf("Expected the wheel to lose to a six-high straight")
+	}
+
+	ordered := wheel.OrderedCards()
+	if ordered[0].Rank != Five || ordered[4].Rank != Ace {
+		t.Errorf("Expected the wheel ordered five to ace, got %v", ordered)
+	}
+}
+
+func TestPokerHandOrderedCards(t *testing.T) {
+	evaluator := NewPokerEvaluator()
+
+	hand := evaluator.EvaluateHand([]Card{
+		NewCard(Hearts, Ace), NewCard(Spades, Four), NewCard(Diamonds, King), NewCard(Clubs, Four), NewCard(Hearts, Nine),
+	})
+
+	expected := []Rank{Four, Four, Ace, King, Nine}
+	for i, card := range hand.OrderedCards() {
+		if card.Rank != expected[i] {
+			t.Errorf("Position %d: expected %v, got %v", i, expected[i], card.Rank)
+		}
+	}
+}
+
+func TestPokerHandExplain(t *testing.T) {
+	evaluator := NewPokerEvaluator()
+
+	t.Run("HandRank", func(t *testing.T) {
+		flush := evaluator.EvaluateHand([]Card{NewCard(Clubs, King), NewCard(Clubs, Ten), NewCard(Clubs, Eight), NewCard(Clubs, Four), NewCard(Clubs, Two)})
+		straight := evaluator.EvaluateHand([]Card{NewCard(Hearts, Ten), NewCard(Spades, Nine), NewCard(Diamonds, Eight), NewCard(Clubs, Seven), NewCard(Hearts, Six)})
+
+		comparison := flush.Explain(straight)
+		if comparison.Result != 1 || comparison.DecidedBy != DecidedByHandRank {
+			t.Errorf("Expected flush to win on hand rank, got %+v", comparison)
+		}
+		if comparison.Explanation != "Flush, king high beats straight, ten high" {
+			t.Errorf("Unexpected explanation %q", comparison.Explanation)
+		}
+
+		if straight.Explain(flush).Result != -1 {
+			t.Errorf("Expected straight to lose")
+		}
+	})
+
+	t.Run("HighCards", func(t *testing.T) {
+		aces := evaluator.EvaluateHand([]Card{NewCard(Hearts, Ace), NewCard(Spades, Ace), NewCard(Diamonds, Four), NewCard(Clubs, Three), NewCard(Hearts, Two)})
+		kings := evaluator.EvaluateHand([]Card{NewCard(Hearts, King), NewCard(Spades, King), NewCard(Diamonds, Queen), NewCard(Clubs, Jack), NewCard(Hearts, Nine)})
+
+		comparison := aces.Explain(kings)
+		if comparison.DecidedBy != DecidedByHighCards || comparison.WinningCard != Ace || comparison.LosingCard != King {
+			t.Errorf("Expected aces over kings, got %+v", comparison)
+		}
+		if comparison.Explanation != "Pair of aces beats pair of kings" {
+			t.Errorf("Unexpected explanation %q", comparison.Explanation)
+		}
+	})
+
+	t.Run("Kicker", func(t *testing.T) {
+		queenKicker := evaluator.EvaluateHand([]Card{NewCard(Hearts, Ace), NewCard(Spades, Ace), NewCard(Diamonds, Queen), NewCard(Clubs, Three), NewCard(Hearts, Two)})
+		jackKicker := evaluator.EvaluateHand([]Card{NewCard(Diamonds, Ace), NewCard(Clubs, Ace), NewCard(Hearts, Jack), NewCard(Spades, Three), NewCard(Diamonds, Two)})
+
+		comparison := jackKicker.Explain(queenKicker)
+		if comparison.Result != -1 || comparison.DecidedBy != DecidedByKicker {
+			t.Errorf("Expected jack kicker to lose on kicker, got %+v", comparison)
+		}
+		if comparison.Explanation != "Pair of aces, queen kicker beats jack kicker" {
+			t.Errorf("Unexpected explanation %q", comparison.Explanation)
+		}
+	})
+
+	t.Run("FlushSecondCard", func(t *testing.T) {
+		better := evaluator.EvaluateHand([]Card{NewCard(Clubs, King), NewCard(Clubs, Jack), NewCard(Clubs, Eight), NewCard(Clubs, Four), NewCard(Clubs, Two)})
+		worse := evaluator.EvaluateHand([]Card{NewCard(Hearts, King), NewCard(Hearts, Ten), NewCard(Hearts, Eight), NewCard(Hearts, Four), NewCard(Hearts, Two)})
+
+		comparison := better.Explain(worse)
+		if comparison.DecidedBy != DecidedByKicker || comparison.WinningCard != Jack {
+			t.Errorf("Expected flush decided by the jack, got %+v", comparison)
+		}
+	})
+
+	t.Run("Tie", func(t *testing.T) {
+		first := evaluator.EvaluateHand([]Card{NewCard(Hearts, Ten), NewCard(Spades, Nine), NewCard(Diamonds, Eight), NewCard(Clubs, Seven), NewCard(Hearts, Six)})
+		second := evaluator.EvaluateHand([]Card{NewCard(Clubs, Ten), NewCard(Diamonds, Nine), NewCard(Hearts, Eight), NewCard(Spades, Seven), NewCard(Clubs, Six)})
+
+		comparison := first.Explain(second)
+		if comparison.Result != 0 || comparison.DecidedBy != DecidedByTie {
+			t.Errorf("Expected a tie, got %+v", comparison)
+		}
+	})
+}
+
+func TestTexasHoldemShowdownExplainsWinner(t *testing.T) {
+	engine := NewTexasHoldemEngine("showdown-game")
+	for i := 1; i <= 2; i++ {
+		engine.AddPlayer(&Player{ID: string(rune('0' + i)), Name: "Player " + string(rune('0'+i)), Position: i})
+	}
+	engine.Start()
+
+	holeCards := map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Queen)},
+		"2": {NewCard(Diamonds, Ace), NewCard(Clubs, Jack)},
+	}
+	for playerID, cards := range holeCards {
+		holdemPlayer := engine.getHoldemPlayer(playerID)
+		holdemPlayer.Hand.Cards = cards
+	}
+	engine.communityCards.Cards = []Card{
+		NewCard(Clubs, Ace), NewCard(Hearts, Seven), NewCard(Spades, Four), NewCard(Diamonds, Three), NewCard(Clubs, Two),
+	}
+	// The loser chooses to show rather than muck
+	if _, err := engine.ProcessAction(context.Background(), &GameAction{PlayerID: "2", Data: map[string]interface{}{"action": "show_cards"}}); err != nil {
+		t.Fatalf("Expected player 2 to choose to show: %v", err)
+	}
+
+	engine.showdown()
+
+	var showdown *GameEvent
+	for _, event := range engine.GetEvents() {
+		if event.Type == "showdown" {
+			showdown = event
+		}
+	}
+
+	if showdown == nil {
+		t.Fatal("Expected a showdown event")
+	}
+	if showdown.Data["winningHand"] != "Pair of aces" {
+		t.Errorf("Unexpected winning hand %v", showdown.Data["winningHand"])
+	}
+	if showdown.Data["explanation"] != "Pair of aces, queen kicker beats jack kicker" {
+		t.Errorf("Unexpected explanation %v", showdown.Data["explanation"])
+	}
+
+	hands, ok := showdown.Data["hands"].([]ShowdownHand)
+	if !ok || len(hands) != 2 {
+		t.Fatalf("Expected two showdown hands, got %v", showdown.Data["hands"])
+	}
+	if !hands[0].IsWinner || hands[0].PlayerID != "1" || hands[0].Comparison != nil {
+		t.Errorf("Expected player 1 to win, got %+v", hands[0])
+	}
+	if hands[1].IsWinner || hands[1].Comparison == nil || hands[1].Comparison.DecidedBy != DecidedByKicker {
+		t.Errorf("Expected player 2 to lose on the kicker, got %+v", hands[1])
+	}
+	if len(hands[0].BestCards) != 5 || hands[0].BestCards[0].Rank != Ace || hands[0].BestCards[2].Rank != Queen {
+		t.Errorf("Unexpected best cards %v", hands[0].BestCards)
+	}
+}
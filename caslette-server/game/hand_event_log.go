@@ -0,0 +1,123 @@
+package game
+
+import (
+	"log"
+	"time"
+)
+
+// HandEventStore keeps the append-only log of the events each completed hand
+// emitted, for replays
+type HandEventStore interface {
+	AppendHandEvents(tableID, handID string, events []GameEvent) error
+}
+
+// HandEventLogger is implemented by engines that log the events of each hand
+// from the deal to its summary
+type HandEventLogger interface {
+	HandEvents() []GameEvent
+}
+
+// beginHandLog starts logging the events of a hand being dealt, under a
+// new hand ID
+func (b *BaseGameEngine) beginHandLog() {
+	b.handLog = make([]GameEvent, 0, 64)
+	b.handLogOpen = true
+	b.logMu.Lock()
+	b.handID = NewHandID()
+	b.handLive = true
+	b.logMu.Unlock()
+}
+
+// logHandEvent adds an emitted event to the open hand log; the hand summary
+// closes it
+func (b *BaseGameEngine) logHandEvent(event *GameEvent) {
+	if !b.handLogOpen {
+		return
+	}
+	b.handLog = append(b.handLog, *event)
+	if event.Type == HandSummaryEvent {
+		b.logMu.Lock()
+		b.lastHandLog = b.handLog
+		b.handLive = false
+		b.logMu.Unlock()
+		b.handLog = nil
+		b.handLogOpen = false
+	}
+}
+
+// HandEvents returns the events of the last hand completed, in the order
+// they were emitted
+func (b *BaseGameEngine) HandEvents() []GameEvent {
+	b.logMu.Lock()
+	defer b.logMu.Unlock()
+	return append([]GameEvent(nil), b.lastHandLog...)
+}
+
+// LiveHandID returns the ID of the hand being played, or "" between hands
+func (b *BaseGameEngine) LiveHandID() string {
+	b.logMu.Lock()
+	defer b.logMu.Unlock()
+	if !b.handLive {
+		return ""
+	}
+	return b.handID
+}
+
+// SetHandEventStore sets where each completed hand's event log is appended
+func (tm *ActorTableManager) SetHandEventStore(store HandEventStore) {
+	tm.mu.Lock()
+	tm.handEventStore = store
+	tm.mu.Unlock()
+}
+
+// recordHandEvents appends the events of the hand a table just completed to
+// the event log
+func (tm *ActorTableManager) recordHandEvents(table *GameTable, handID string) {
+	tm.mu.RLock()
+	store := tm.handEventStore
+	tm.mu.RUnlock()
+
+	logger, ok := table.GameEngine.(HandEventLogger)
+	if store == nil || !ok {
+		return
+	}
+	events := logger.HandEvents()
+	if len(events) == 0 {
+		return
+	}
+	if err := store.AppendHandEvents(table.ID, handID, events); err != nil {
+		log.Printf("Table %s: failed to save the event log of hand %s: %v", table.ID, handID, err)
+	}
+}
+
+// ReplayEvent is a logged event with its offset from the start of the hand,
+// so clients can animate a replay at the original pace
+type ReplayEvent struct {
+	GameEvent
+	OffsetMs int64 `json:"offset_ms"`
+}
+
+// HandReplay is the event log of a completed hand in the order it was played
+type HandReplay struct {
+	HandID    string        `json:"hand_id"`
+	TableID   string        `json:"table_id"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  int64         `json:"duration_ms"`
+	Events    []ReplayEvent `json:"events"`
+}
+
+// NewHandReplay builds the replay of a hand from its logged events, timing
+// each one from the first
+func NewHandReplay(tableID, handID string, events []GameEvent) HandReplay {
+	replay := HandReplay{HandID: handID, TableID: tableID, Events: make([]ReplayEvent, 0, len(events))}
+	if len(events) == 0 {
+		return replay
+	}
+	replay.StartedAt = events[0].Timestamp
+	for _, event := range events {
+		offset := event.Timestamp.Sub(replay.StartedAt).Milliseconds()
+		replay.Events = append(replay.Events, ReplayEvent{GameEvent: event, OffsetMs: offset})
+		replay.Duration = offset
+	}
+	return replay
+}
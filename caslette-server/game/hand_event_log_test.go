@@ -0,0 +1,84 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type loggedHands struct {
+	tableID string
+	handID  string
+	events  []GameEvent
+}
+
+func (l *loggedHands) AppendHandEvents(tableID, handID string, events []GameEvent) error {
+	l.tableID, l.handID, l.events = tableID, handID, events
+	return nil
+}
+
+func TestEngineLogsEachHandFromDealToSummary(t *testing.T) {
+	engine := newRiverTable(t)
+	assert.Empty(t, engine.HandEvents(), "nothing is logged before a hand completes")
+
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+
+	events := engine.HandEvents()
+	require.NotEmpty(t, events)
+	assert.Equal(t, HandSummaryEvent, events[len(events)-1].Type, "the summary closes the log")
+	types := make([]string, 0, len(events))
+	for i, event := range events {
+		types = append(types, event.Type)
+		if i > 0 {
+			assert.Greater(t, event.Sequence, events[i-1].Sequence, "events are kept in emission order")
+		}
+	}
+	assert.Contains(t, types, "blinds_posted")
+	assert.Contains(t, types, "hand_started")
+	assert.Contains(t, types, "river_dealt")
+	assert.Contains(t, types, "pot_awarded")
+
+	// Events after the summary belong to no hand
+	engine.emitEvent(&GameEvent{Type: "player_left"})
+	assert.Len(t, engine.HandEvents(), len(events))
+}
+
+func TestManagerAppendsHandEventLogs(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	store := &loggedHands{}
+	manager.SetHandEventStore(store)
+
+	engine := newRiverTable(t)
+	table := &GameTable{ID: "event-log-table", GameType: GameTypeTexasHoldem, GameEngine: engine}
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	manager.recordHandHistory(table)
+
+	assert.Equal(t, "event-log-table", store.tableID)
+	assert.Equal(t, engine.handID, store.handID, "the log is kept under the hand history's ID")
+	assert.Equal(t, engine.HandEvents(), store.events)
+	for _, event := range store.events {
+		assert.Equal(t, store.handID, event.HandID, "%s carries the hand ID", event.Type)
+	}
+}
+
+func TestHandReplayTimesEventsFromTheDeal(t *testing.T) {
+	dealt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	replay := NewHandReplay("t1", "t1-7", []GameEvent{
+		{Type: "hand_started", Timestamp: dealt},
+		{Type: "player_called", Timestamp: dealt.Add(1500 * time.Millisecond)},
+		{Type: HandSummaryEvent, Timestamp: dealt.Add(4 * time.Second)},
+	})
+
+	assert.Equal(t, "t1-7", replay.HandID)
+	assert.Equal(t, dealt, replay.StartedAt)
+	assert.Equal(t, int64(4000), replay.Duration)
+	require.Len(t, replay.Events, 3)
+	assert.Equal(t, []int64{0, 1500, 4000}, []int64{replay.Events[0].OffsetMs, replay.Events[1].OffsetMs, replay.Events[2].OffsetMs})
+	assert.Equal(t, "player_called", replay.Events[1].Type)
+
+	assert.Empty(t, NewHandReplay("t1", "t1-8", nil).Events)
+}
@@ -0,0 +1,263 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// HandActionPost is the action recorded for blinds and antes posted when a
+// hand is dealt
+const HandActionPost = "post"
+
+// HandAction is one step of a hand's betting
+type HandAction struct {
+	Street    TexasHoldemState `json:"street"`
+	PlayerID  string           `json:"player_id"`
+	Action    string           `json:"action"` // HandActionPost for blinds and antes, otherwise the betting action
+	Amount    int              `json:"amount"` // Chips put in by the action
+	Pot       int              `json:"pot"`    // Pot after the action
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// HandPlayer is a player dealt into a hand
+type HandPlayer struct {
+	PlayerID      string `json:"player_id"`
+	Seat          int    `json:"seat"`
+	StartingStack int    `json:"starting_stack"` // Stack before blinds and antes
+	HoleCards     []Card `json:"hole_cards,omitempty"`
+	Shown         bool   `json:"shown"`            // Hole cards were turned face up at showdown
+	Busted        bool   `json:"busted,omitempty"` // Lost every chip in the hand
+}
+
+// HandRecord is the history of a completed hand: who was dealt in, every
+// action, the board, the pots and who won them
+type HandRecord struct {
+	HandID     string       `json:"hand_id"`
+	TableID    string       `json:"table_id"`
+	GameType   GameType     `json:"game_type"`
+	HandNumber int          `json:"hand_number"` // Hands dealt at the table, counting this one
+	SmallBlind int          `json:"small_blind"`
+	BigBlind   int          `json:"big_blind"`
+	Ante       int          `json:"ante"`
+	ButtonSeat int          `json:"button_seat"`
+	Players    []HandPlayer `json:"players"`
+	Actions    []HandAction `json:"actions"`
+	Board      []Card       `json:"board"`
+	Pots       []Pot        `json:"pots"`
+	Winners    []string     `json:"winners"`
+	Results    []HandResult `json:"results"`
+	TotalPot   int          `json:"total_pot"`
+	Rake       int          `json:"rake"` // Chips taken by the house
+	StartedAt  time.Time    `json:"started_at"`
+	EndedAt    time.Time    `json:"ended_at"`
+}
+
+// Dealt reports whether a player was dealt into the hand
+func (r *HandRecord) Dealt(playerID string) bool {
+	for _, player := range r.Players {
+		if player.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleTo returns a copy of the record with the hole cards a viewer may
+// not see removed: everyone sees the hands shown at showdown, and players
+// also see their own
+func (r *HandRecord) VisibleTo(viewerID string) HandRecord {
+	visible := *r
+	visible.Players = make([]HandPlayer, len(r.Players))
+	for i, player := range r.Players {
+		if !player.Shown && player.PlayerID != viewerID {
+			player.HoleCards = nil
+		}
+		visible.Players[i] = player
+	}
+	return visible
+}
+
+// ReassignPlayer rewrites every reference to a player in the record to
+// another ID, as when two accounts are merged
+func (r *HandRecord) ReassignPlayer(fromID, toID string) {
+	rename := func(ids []string) {
+		for i, id := range ids {
+			if id == fromID {
+				ids[i] = toID
+			}
+		}
+	}
+	for i := range r.Players {
+		if r.Players[i].PlayerID == fromID {
+			r.Players[i].PlayerID = toID
+		}
+	}
+	for i := range r.Actions {
+		if r.Actions[i].PlayerID == fromID {
+			r.Actions[i].PlayerID = toID
+		}
+	}
+	for i := range r.Results {
+		if r.Results[i].PlayerID == fromID {
+			r.Results[i].PlayerID = toID
+		}
+	}
+	for i := range r.Pots {
+		rename(r.Pots[i].Eligible)
+		rename(r.Pots[i].Winners)
+	}
+	rename(r.Winners)
+}
+
+// HandRecorder is implemented by engines that keep the history of the last
+// hand they completed
+type HandRecorder interface {
+	CompletedHand() *HandRecord
+}
+
+// LiveHandIdentifier is implemented by engines that name the hand being
+// played, so events raised outside the engine can be tied to it
+type LiveHandIdentifier interface {
+	LiveHandID() string
+}
+
+// NewHandID returns a new globally unique hand ID, assigned when a hand is
+// dealt and carried by its events and history
+func NewHandID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// HandHistoryStore persists completed hands
+type HandHistoryStore interface {
+	SaveHand(record HandRecord) error
+}
+
+// beginHandRecord starts the record of a hand being dealt from the stacks
+// players had before posting
+func (the *TexasHoldemEngine) beginHandRecord(stacks map[string]int) {
+	the.hand = &HandRecord{
+		HandID:     the.handID,
+		HandNumber: the.handsDealt,
+		SmallBlind: the.smallBlind,
+		BigBlind:   the.bigBlind,
+		Ante:       the.ante,
+		ButtonSeat: the.dealerPos,
+		Players:    make([]HandPlayer, 0, len(stacks)),
+		Actions:    make([]HandAction, 0),
+		StartedAt:  time.Now(),
+	}
+	for _, player := range the.getActivePlayers() {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		the.hand.Players = append(the.hand.Players, HandPlayer{
+			PlayerID:      player.ID,
+			Seat:          player.Position,
+			StartingStack: stacks[player.ID],
+			HoleCards:     append([]Card(nil), holdemPlayer.Hand.Cards...),
+		})
+		if holdemPlayer.TotalBet > 0 {
+			the.recordHandAction(player.ID, HandActionPost, holdemPlayer.TotalBet)
+		}
+	}
+}
+
+// recordHandAction adds a betting action to the hand being played
+func (the *TexasHoldemEngine) recordHandAction(playerID, action string, amount int) {
+	if the.hand == nil {
+		return
+	}
+	the.hand.Actions = append(the.hand.Actions, HandAction{
+		Street:    the.roundState,
+		PlayerID:  playerID,
+		Action:    action,
+		Amount:    amount,
+		Pot:       the.pot,
+		Timestamp: time.Now(),
+	})
+}
+
+// finishHandRecord completes the record of the hand once its pots are paid
+func (the *TexasHoldemEngine) finishHandRecord(pots []Pot, totalPot, rake int, results []HandResult) {
+	if the.hand == nil {
+		return
+	}
+	record := the.hand
+	record.Board = append([]Card(nil), the.communityCards.Cards...)
+	record.Pots = pots
+	record.TotalPot = totalPot
+	record.Rake = rake
+	record.Results = results
+	record.Winners = make([]string, 0, len(the.winners))
+	for _, winner := range the.winners {
+		record.Winners = append(record.Winners, winner.ID)
+	}
+	for i := range record.Players {
+		playerID := record.Players[i].PlayerID
+		record.Players[i].Shown = the.revealed[playerID]
+		// Stud deals more cards after the hand starts
+		if holdemPlayer := the.getHoldemPlayer(playerID); holdemPlayer != nil {
+			record.Players[i].HoleCards = append([]Card(nil), holdemPlayer.Hand.Cards...)
+			record.Players[i].Busted = holdemPlayer.Chips <= 0
+		}
+	}
+	record.EndedAt = time.Now()
+	the.lastHand = record
+	the.hand = nil
+}
+
+// CompletedHand returns the history of the last hand completed, once any
+// action being processed has finished, or nil before the first
+func (the *TexasHoldemEngine) CompletedHand() *HandRecord {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if the.lastHand == nil {
+		return nil
+	}
+	record := *the.lastHand
+	return &record
+}
+
+// SetHandHistoryStore sets where completed hands are persisted
+func (tm *ActorTableManager) SetHandHistoryStore(store HandHistoryStore) {
+	tm.mu.Lock()
+	tm.handHistory = store
+	tm.mu.Unlock()
+}
+
+// recordHandHistory persists the hand a table just completed and its event
+// log, certifies its result, collects its rake, adds it to its players'
+// lifetime stats and checks it for collusion
+func (tm *ActorTableManager) recordHandHistory(table *GameTable) {
+	tm.mu.RLock()
+	store := tm.handHistory
+	tm.mu.RUnlock()
+
+	recorder, ok := table.GameEngine.(HandRecorder)
+	if !ok {
+		return
+	}
+	record := recorder.CompletedHand()
+	if record == nil {
+		return
+	}
+	record.TableID = table.ID
+	record.GameType = table.GameType
+	if record.HandID == "" {
+		record.HandID = fmt.Sprintf("%s-%d", table.ID, record.HandNumber)
+	}
+	tm.recordHandEvents(table, record.HandID)
+	tm.certifyHand(table, *record)
+	tm.collectRake(table, *record)
+	tm.recordPlayerStats(table, *record)
+	tm.detectCollusion(table, *record)
+	if store == nil {
+		return
+	}
+	if err := store.SaveHand(*record); err != nil {
+		log.Printf("Table %s: failed to save hand %d: %v", table.ID, record.HandNumber, err)
+	}
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type savedHands struct {
+	hands []HandRecord
+}
+
+func (s *savedHands) SaveHand(record HandRecord) error {
+	s.hands = append(s.hands, record)
+	return nil
+}
+
+func TestCompletedHandRecordsTheWholeHand(t *testing.T) {
+	engine := newRiverTable(t)
+	assert.Nil(t, engine.CompletedHand(), "nothing is recorded before a hand completes")
+
+	require.NoError(t, act(engine, ActionBet, 20))
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	record := engine.CompletedHand()
+	require.NotNil(t, record)
+	assert.Equal(t, 1, record.HandNumber)
+	assert.Equal(t, 5, record.SmallBlind)
+	assert.Equal(t, 10, record.BigBlind)
+	require.Len(t, record.Players, 2)
+	for _, player := range record.Players {
+		assert.Equal(t, 1000, player.StartingStack, "stacks are taken before the blinds")
+		assert.Len(t, player.HoleCards, 2)
+	}
+
+	require.NotEmpty(t, record.Actions)
+	assert.Equal(t, HandActionPost, record.Actions[0].Action)
+	assert.Equal(t, HandActionPost, record.Actions[1].Action)
+	last := record.Actions[len(record.Actions)-1]
+	assert.Equal(t, River, last.Street)
+	assert.Equal(t, string(ActionCall), last.Action)
+	assert.Equal(t, 20, last.Amount)
+	assert.Equal(t, 60, last.Pot)
+
+	assert.Len(t, record.Board, 5)
+	assert.Equal(t, 60, record.TotalPot)
+	assert.Equal(t, []string{"1"}, record.Winners)
+	require.Len(t, record.Results, 2)
+	assert.Equal(t, 30, record.Results[0].Net)
+	assert.False(t, record.StartedAt.After(record.EndedAt))
+}
+
+func TestHandRecordHidesMuckedHoleCards(t *testing.T) {
+	engine := newRiverTable(t)
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+
+	record := engine.CompletedHand()
+	require.NotNil(t, record)
+	assert.True(t, record.Dealt("2"))
+	assert.False(t, record.Dealt("3"))
+
+	observer := record.VisibleTo("3")
+	assert.Len(t, observer.Players[0].HoleCards, 2, "the winner's hand was shown")
+	assert.Empty(t, observer.Players[1].HoleCards, "the loser mucked")
+	assert.Len(t, record.Players[1].HoleCards, 2, "the stored record keeps every hand")
+
+	loser := record.VisibleTo("2")
+	assert.Len(t, loser.Players[1].HoleCards, 2, "players see their own cards")
+}
+
+func TestManagerSavesCompletedHands(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	store := &savedHands{}
+	manager.SetHandHistoryStore(store)
+
+	engine := newRiverTable(t)
+	table := &GameTable{ID: "history-table", GameType: GameTypeTexasHoldem, GameEngine: engine}
+	manager.recordHandHistory(table)
+	assert.Empty(t, store.hands, "no hand has completed yet")
+
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	manager.recordHandHistory(table)
+
+	require.Len(t, store.hands, 1)
+	assert.Equal(t, engine.handID, store.hands[0].HandID, "hands keep the ID they were dealt under")
+	assert.Equal(t, "history-table", store.hands[0].TableID)
+	assert.Equal(t, GameTypeTexasHoldem, store.hands[0].GameType)
+}
+
+func TestEveryHandGetsUniqueID(t *testing.T) {
+	engine := newRiverTable(t)
+	first := engine.handID
+	assert.Len(t, first, 32)
+	assert.Equal(t, first, engine.LiveHandID())
+	require.NoError(t, act(engine, ActionFold, 0))
+	assert.Empty(t, engine.LiveHandID(), "no hand is live between hands")
+	assert.Equal(t, first, engine.CompletedHand().HandID)
+	assert.Equal(t, first, lastEventOfType(engine, "pot_distributed").HandID)
+
+	require.NoError(t, engine.startNewHand())
+	second := engine.LiveHandID()
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, second, lastEventOfType(engine, "hand_started").HandID)
+
+	// The hand keeps its ID across a restart
+	data, err := engine.Serialize()
+	require.NoError(t, err)
+	restored := NewTexasHoldemEngine("show-muck-game")
+	require.NoError(t, restored.Deserialize(data))
+	assert.Equal(t, second, restored.LiveHandID())
+}
+
+func TestManagerEventsCarryLiveHandID(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	broadcaster := &recordingBroadcaster{}
+	manager.SetEventBroadcaster(broadcaster)
+	engine := newRiverTable(t)
+	table := &GameTable{ID: "hand-id-table", GameEngine: engine}
+
+	manager.BroadcastGameEvent(table, &GameEvent{Type: "player_straddling"})
+	require.NoError(t, act(engine, ActionFold, 0))
+	manager.BroadcastGameEvent(table, &GameEvent{Type: "player_busted"})
+
+	require.Len(t, broadcaster.events, 2)
+	assert.Equal(t, engine.handID, broadcaster.events[0].HandID)
+	assert.Empty(t, broadcaster.events[1].HandID, "events between hands belong to none")
+}
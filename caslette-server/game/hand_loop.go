@@ -0,0 +1,321 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultInterHandDelay is how long the result of a hand stays on the table
+// before the next hand is dealt
+const DefaultInterHandDelay = 5 * time.Second
+
+// HandLoopEngine is an engine that deals hand after hand to a table whose
+// players come and go between hands
+type HandLoopEngine interface {
+	GameEngine
+	ChipHolder
+	SeatPlayer(player *Player, seat int) error
+	MoveSeats(seats map[string]int) error
+	StartNextHand() error
+}
+
+// SeatPlayer adds a player to the engine at a table seat, so the button and
+// blinds follow the seats rather than the order players sat down in
+func (the *TexasHoldemEngine) SeatPlayer(player *Player, seat int) error {
+	for _, seated := range the.players {
+		if seated.Position == seat {
+			return fmt.Errorf("seat %d is taken by %s", seat, seated.ID)
+		}
+	}
+	if err := the.AddPlayer(player); err != nil {
+		return err
+	}
+	player.Position = seat
+	return nil
+}
+
+// StartNextHand deals a new hand once the previous one is over; the button
+// moves on from the last hand
+func (the *TexasHoldemEngine) StartNextHand() error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+
+	if state := the.GetState(); state == GameStateInProgress || state == GameStatePaused {
+		return fmt.Errorf("a hand is already in progress")
+	}
+	if the.playersToDeal() < 2 {
+		return fmt.Errorf("need at least 2 players with chips to deal a hand")
+	}
+	if err := the.BaseGameEngine.Start(); err != nil {
+		return err
+	}
+	return the.startNewHand()
+}
+
+// SyncHandSeatsCommand writes the engine's stacks back to the seats after a
+// hand and returns the seats the next hand is dealt from
+type SyncHandSeatsCommand struct {
+	Stacks   map[string]int
+	Response chan interface{}
+}
+
+func (cmd *SyncHandSeatsCommand) Execute(table *GameTable) interface{} {
+	seats := make([]PlayerSlot, len(table.PlayerSlots))
+	for i := range table.PlayerSlots {
+		slot := &table.PlayerSlots[i]
+		if chips, inPlay := cmd.Stacks[slot.PlayerID]; inPlay && slot.PlayerID != "" {
+			slot.Chips = chips
+		}
+		seats[i] = *slot
+	}
+	table.UpdatedAt = time.Now()
+	return seats
+}
+
+// SyncHandSeats sends the engine's stacks to the table actor
+func (ta *TableActor) SyncHandSeats(ctx context.Context, stacks map[string]int) ([]PlayerSlot, error) {
+	cmd := &SyncHandSeatsCommand{
+		Stacks:   stacks,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if seats, ok := result.([]PlayerSlot); ok {
+			return seats, nil
+		}
+		return nil, fmt.Errorf("unexpected response type")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SetTableStatusCommand moves a table to a new status when it is in one of
+// the expected statuses
+type SetTableStatusCommand struct {
+	Status   TableStatus
+	From     []TableStatus
+	Response chan interface{}
+}
+
+func (cmd *SetTableStatusCommand) Execute(table *GameTable) interface{} {
+	for _, from := range cmd.From {
+		if table.Status == from {
+			table.Status = cmd.Status
+			table.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return &TableError{"INVALID_TABLE_STATUS", fmt.Sprintf("Table is %s", table.Status)}
+}
+
+// SetStatus sends a status change to the table actor
+func (ta *TableActor) SetStatus(ctx context.Context, status TableStatus, from ...TableStatus) error {
+	cmd := &SetTableStatusCommand{
+		Status:   status,
+		From:     from,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetInterHandDelay changes the pause between hands; zero deals the next
+// hand as soon as the pot is paid
+func (tm *ActorTableManager) SetInterHandDelay(delay time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if delay >= 0 {
+		tm.interHandDelay = delay
+	}
+}
+
+// startHandLoop marks the table active and deals its first hand
+func (tm *ActorTableManager) startHandLoop(ctx context.Context, tableID string) error {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return ErrTableNotFound
+	}
+	if tm.Draining() {
+		return ErrServerDraining
+	}
+
+	if err := actor.SetStatus(ctx, TableStatusActive, TableStatusWaiting, TableStatusPaused); err != nil {
+		return err
+	}
+	if err := tm.dealHand(ctx, actor); err != nil {
+		actor.SetStatus(ctx, TableStatusWaiting, TableStatusActive)
+		return err
+	}
+	tm.notifyTableListeners(actor.table, TableUpdated)
+	return nil
+}
+
+// scheduleNextHand deals the table's next hand after the inter-hand delay
+func (tm *ActorTableManager) scheduleNextHand(table *GameTable) {
+	tm.mu.Lock()
+	actor, exists := tm.actors[table.ID]
+	if !exists || table.Status != TableStatusActive || tm.draining {
+		tm.mu.Unlock()
+		return
+	}
+	if timer, pending := tm.handTimers[table.ID]; pending {
+		timer.Stop()
+	}
+
+	delay := tm.interHandDelay
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		tm.mu.Lock()
+		current := tm.handTimers[table.ID] == timer
+		if current {
+			delete(tm.handTimers, table.ID)
+		}
+		tm.mu.Unlock()
+		if !current || table.Status != TableStatusActive {
+			return
+		}
+		if err := tm.dealHand(context.Background(), actor); err != nil {
+			log.Printf("Table %s: next hand not dealt: %v", table.ID, err)
+		}
+	})
+	tm.handTimers[table.ID] = timer
+	tm.mu.Unlock()
+
+	now := time.Now()
+	tm.BroadcastGameEvent(table, &GameEvent{
+		Type: "next_hand_scheduled",
+		Data: map[string]interface{}{
+			"table_id":      table.ID,
+			"delay_seconds": delay.Seconds(),
+			"starts_at":     now.Add(delay),
+		},
+		Timestamp: now,
+	})
+}
+
+// stopHandLoop cancels a table's pending hand
+func (tm *ActorTableManager) stopHandLoop(tableID string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if timer, pending := tm.handTimers[tableID]; pending {
+		timer.Stop()
+		delete(tm.handTimers, tableID)
+	}
+}
+
+// dealHand brings the engine in line with the seats and deals. Short stacks
+// are topped up at auto-rebuy tables, players who left are dropped, busted
+// players give up their seat, players who sat down since the last hand
+// are dealt in and players sitting out are skipped. With fewer than two
+// stacks left the table goes back to waiting for players.
+func (tm *ActorTableManager) dealHand(ctx context.Context, actor *TableActor) error {
+	table := actor.table
+	engine, ok := table.GameEngine.(HandLoopEngine)
+	if !ok {
+		return &TableError{"NO_ENGINE", "Game engine cannot deal hands"}
+	}
+
+	stacks, _ := engine.ChipCounts()
+	seats, err := actor.SyncHandSeats(ctx, stacks)
+	if err != nil {
+		return err
+	}
+	seats = tm.autoRebuy(ctx, actor, seats)
+
+	seated := make(map[string]PlayerSlot, len(seats))
+	for _, slot := range seats {
+		if slot.PlayerID != "" {
+			seated[slot.PlayerID] = slot
+		}
+	}
+
+	for _, player := range engine.GetPlayers() {
+		slot, stillSeated := seated[player.ID]
+		if stillSeated && slot.Chips > 0 {
+			continue
+		}
+		if err := engine.RemovePlayer(player.ID); err != nil {
+			return err
+		}
+		if stillSeated {
+			tm.bustOut(ctx, actor, slot)
+		}
+	}
+
+	// Players who changed seats since the last hand are dealt from there
+	moved := make(map[string]int)
+	for _, player := range engine.GetPlayers() {
+		if slot := seated[player.ID]; slot.Position != player.Position {
+			moved[player.ID] = slot.Position
+		}
+	}
+	if len(moved) > 0 {
+		if err := engine.MoveSeats(moved); err != nil {
+			return err
+		}
+	}
+
+	dealtIn := 0
+	for _, slot := range seats {
+		if slot.PlayerID == "" || slot.Chips <= 0 {
+			continue
+		}
+		if !slot.SittingOut {
+			dealtIn++
+		}
+		if _, err := engine.GetPlayer(slot.PlayerID); err != nil {
+			player := &Player{ID: slot.PlayerID, Name: slot.Username, Data: map[string]interface{}{"chips": slot.Chips}}
+			if err := engine.SeatPlayer(player, slot.Position); err != nil {
+				return err
+			}
+		}
+		if sitOut, ok := engine.(SitOutEngine); ok {
+			if err := sitOut.SetSittingOut(slot.PlayerID, slot.SittingOut); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dealtIn < 2 {
+		actor.SetStatus(ctx, TableStatusWaiting, TableStatusActive)
+		tm.notifyTableListeners(table, TableUpdated)
+		tm.BroadcastGameEvent(table, &GameEvent{
+			Type: "waiting_for_players",
+			Data: map[string]interface{}{
+				"table_id": table.ID,
+				"players":  dealtIn,
+			},
+			Timestamp: time.Now(),
+		})
+		return &TableError{"NOT_ENOUGH_PLAYERS", "Not enough players with chips to deal a hand"}
+	}
+
+	return engine.StartNextHand()
+}
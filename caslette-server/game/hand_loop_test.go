@@ -0,0 +1,107 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHandLoopTable seats players p0, p1... with 1000 chips each at a table
+// whose hands are dealt by a real engine
+func newHandLoopTable(t *testing.T, delay time.Duration, players ...string) (*ActorTableManager, *GameTable, *recordingBroadcaster) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	manager.SetInterHandDelay(delay)
+	broadcaster := &recordingBroadcaster{}
+	manager.SetEventBroadcaster(broadcaster)
+	t.Cleanup(manager.Stop)
+
+	settings := DefaultTableSettings()
+	settings.TimeLimit = 0
+	table := newBalancingTable(t, manager, "loop", settings, 0)
+	for _, playerID := range players {
+		require.NoError(t, joinWithBuyIn(manager, table.ID, playerID, 1000))
+	}
+	return manager, table, broadcaster
+}
+
+// handsStarted counts the hands broadcast so far; the broadcaster is safe to
+// read while the loop deals on its own goroutine
+func handsStarted(broadcaster *recordingBroadcaster) int {
+	return len(broadcaster.ofType("hand_started"))
+}
+
+func TestHandLoopDealsNextHandAfterDelay(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1", "p2")
+	engine := table.GameEngine.(*TexasHoldemEngine)
+
+	require.NoError(t, manager.tryStartGame(table))
+	assert.Equal(t, TableStatusActive, table.Status)
+	assert.Equal(t, GameStateInProgress, engine.GetState())
+	assert.Len(t, engine.GetPlayers(), 3)
+	assert.Equal(t, 0, engine.dealerPos)
+
+	foldHand(t, engine)
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, engine.dealerPos, "the button moves for the next hand")
+	assert.Len(t, broadcaster.ofType("next_hand_scheduled"), 1)
+
+	total := 0
+	for _, playerID := range []string{"p0", "p1", "p2"} {
+		total += seatChips(table, playerID)
+	}
+	assert.Equal(t, 3000, total, "seats carry the stacks from the last hand")
+}
+
+func TestHandLoopRemovesBustedAndDepartedPlayers(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 50*time.Millisecond, "p0", "p1", "p2", "p3")
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	require.NoError(t, engine.AdjustChips("p1", -stacks["p1"]))
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: "p3"}))
+
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 2 }, time.Second, 5*time.Millisecond)
+	busted := broadcaster.ofType("player_busted")
+	require.Len(t, busted, 1)
+	assert.Equal(t, "p1", busted[0].PlayerID)
+	assert.Equal(t, -1, table.GetPlayerPosition("p1"), "the busted player's seat is freed")
+	assert.True(t, table.IsObserver("p1"), "and they stay on to watch")
+	_, err := engine.GetPlayer("p1")
+	assert.Error(t, err)
+	_, err = engine.GetPlayer("p3")
+	assert.Error(t, err, "players who left are not dealt in")
+	assert.Len(t, engine.GetPlayers(), 2)
+}
+
+func TestHandLoopWaitsWhenTooFewPlayersHaveChips(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1")
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	for playerID, chips := range stacks {
+		if chips < 1000 {
+			require.NoError(t, engine.AdjustChips(playerID, -chips))
+		}
+	}
+
+	require.Eventually(t, func() bool { return len(broadcaster.ofType("waiting_for_players")) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, TableStatusWaiting, table.Status)
+	assert.Equal(t, 1, handsStarted(broadcaster))
+}
+
+func TestStartNextHandRefusesWhileHandInProgress(t *testing.T) {
+	engine := newButtonTable(t, 2)
+	require.NoError(t, engine.StartNextHand())
+	assert.Error(t, engine.StartNextHand())
+
+	foldHand(t, engine)
+	require.NoError(t, engine.StartNextHand())
+	assert.Error(t, engine.SeatPlayer(&Player{ID: "late"}, 5), "players cannot sit down mid-hand")
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Showdown results reported in hand results
+const (
+	ShowdownWon   = "won"
+	ShowdownSplit = "split"
+	ShowdownLost  = "lost"
+)
+
+// HandResult is one player's outcome of a finished hand
+type HandResult struct {
+	PlayerID  string `json:"playerId"`
+	Invested  int    `json:"invested"`  // Chips put into the pot
+	Collected int    `json:"collected"` // Chips won from the pots, including uncalled bets returned
+	Net       int    `json:"net"`
+	Showdown  string `json:"showdown,omitempty"`  // Empty when the player did not reach showdown
+	Hand      string `json:"hand,omitempty"`      // Best hand shown at showdown
+	BestCards []Card `json:"bestCards,omitempty"` // The five cards that hand plays
+}
+
+// HandSummary is the private end-of-hand summary pushed to a seated player
+type HandSummary struct {
+	TableID    string    `json:"table_id"`
+	HandNumber int       `json:"hand_number"` // Hands completed at the table, counting this one
+	PlayerID   string    `json:"player_id"`
+	Invested   int       `json:"invested"`
+	Collected  int       `json:"collected"`
+	Net        int       `json:"net"`
+	Showdown   string    `json:"showdown,omitempty"`
+	Hand       string    `json:"hand,omitempty"`
+	SessionNet int       `json:"session_net"` // Net over the hands since the player sat down
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// PlayerMessenger delivers a message privately to one player's connections
+type PlayerMessenger interface {
+	SendToPlayer(playerID string, msg interface{}) error
+}
+
+// HandListener is told the results of every hand finished at a managed table
+type HandListener func(table *GameTable, results []HandResult)
+
+// HandStatsPreference reports whether a player wants hand summaries pushed
+type HandStatsPreference func(playerID string) bool
+
+// HandStats tracks players' session results per table and pushes each
+// seated player a private summary after every hand
+type HandStats struct {
+	mu         sync.Mutex
+	sessions   map[string]map[string]int // Table ID -> player ID -> session net
+	hands      map[string]int            // Table ID -> hands completed
+	messenger  PlayerMessenger
+	preference HandStatsPreference
+}
+
+// NewHandStats creates a hand stats tracker that delivers nothing until a
+// messenger is set
+func NewHandStats() *HandStats {
+	return &HandStats{
+		sessions: make(map[string]map[string]int),
+		hands:    make(map[string]int),
+	}
+}
+
+// SetMessenger sets how summaries reach players
+func (hs *HandStats) SetMessenger(messenger PlayerMessenger) {
+	hs.mu.Lock()
+	hs.messenger = messenger
+	hs.mu.Unlock()
+}
+
+// SetPreference sets the per-player opt-out check. Without one every seated
+// player receives summaries.
+func (hs *HandStats) SetPreference(preference HandStatsPreference) {
+	hs.mu.Lock()
+	hs.preference = preference
+	hs.mu.Unlock()
+}
+
+// RecordHand adds a finished hand to the players' sessions and pushes the
+// summaries to players still seated. Delivery is asynchronous so preference
+// lookups never hold up the table.
+func (hs *HandStats) RecordHand(table *GameTable, results []HandResult) []HandSummary {
+	now := time.Now()
+
+	hs.mu.Lock()
+	hs.hands[table.ID]++
+	handNumber := hs.hands[table.ID]
+	sessions := hs.sessions[table.ID]
+	if sessions == nil {
+		sessions = make(map[string]int)
+		hs.sessions[table.ID] = sessions
+	}
+	summaries := make([]HandSummary, 0, len(results))
+	for _, result := range results {
+		sessions[result.PlayerID] += result.Net
+		summaries = append(summaries, HandSummary{
+			TableID:    table.ID,
+			HandNumber: handNumber,
+			PlayerID:   result.PlayerID,
+			Invested:   result.Invested,
+			Collected:  result.Collected,
+			Net:        result.Net,
+			Showdown:   result.Showdown,
+			Hand:       result.Hand,
+			SessionNet: sessions[result.PlayerID],
+			Timestamp:  now,
+		})
+	}
+	messenger := hs.messenger
+	preference := hs.preference
+	hs.mu.Unlock()
+
+	if messenger == nil {
+		return summaries
+	}
+
+	recipients := make([]HandSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if table.IsPlayerAtTable(summary.PlayerID) {
+			recipients = append(recipients, summary)
+		}
+	}
+	go deliverHandSummaries(messenger, preference, recipients)
+
+	return summaries
+}
+
+// deliverHandSummaries sends each summary to its player unless they opted out
+func deliverHandSummaries(messenger PlayerMessenger, preference HandStatsPreference, summaries []HandSummary) {
+	for _, summary := range summaries {
+		if preference != nil && !preference(summary.PlayerID) {
+			continue
+		}
+		msg := &WebSocketMessage{Type: "hand_stats", Data: summary, Success: true}
+		if err := messenger.SendToPlayer(summary.PlayerID, msg); err != nil {
+			log.Printf("HandStats: failed to send summary to player %s: %v", summary.PlayerID, err)
+		}
+	}
+}
+
+// SessionNet returns a player's net over the hands since they sat down
+func (hs *HandStats) SessionNet(tableID, playerID string) int {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.sessions[tableID][playerID]
+}
+
+// ResetSession starts a new session for a player sitting down or leaving
+func (hs *HandStats) ResetSession(tableID, playerID string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if sessions := hs.sessions[tableID]; sessions != nil {
+		delete(sessions, playerID)
+		if len(sessions) == 0 {
+			delete(hs.sessions, tableID)
+		}
+	}
+}
+
+// RemoveTable forgets a closed table
+func (hs *HandStats) RemoveTable(tableID string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	delete(hs.sessions, tableID)
+	delete(hs.hands, tableID)
+}
+
+// AddHandListener registers a listener for finished hands and returns a
+// function that removes it
+func (tm *ActorTableManager) AddHandListener(listener HandListener) func() {
+	tm.mu.Lock()
+	id := tm.nextListenerID
+	tm.nextListenerID++
+	tm.handListeners[id] = listener
+	tm.mu.Unlock()
+
+	return func() {
+		tm.mu.Lock()
+		delete(tm.handListeners, id)
+		tm.mu.Unlock()
+	}
+}
+
+// notifyHandListeners passes a finished hand's results to every listener
+func (tm *ActorTableManager) notifyHandListeners(table *GameTable, results []HandResult) {
+	tm.mu.RLock()
+	listeners := make([]HandListener, 0, len(tm.handListeners))
+	for _, listener := range tm.handListeners {
+		listeners = append(listeners, listener)
+	}
+	tm.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(table, results)
+	}
+}
@@ -0,0 +1,181 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMessenger struct {
+	sent chan *WebSocketMessage
+	to   chan string
+}
+
+func newRecordingMessenger() *recordingMessenger {
+	return &recordingMessenger{sent: make(chan *WebSocketMessage, 10), to: make(chan string, 10)}
+}
+
+func (m *recordingMessenger) SendToPlayer(playerID string, msg interface{}) error {
+	wsMsg, ok := msg.(*WebSocketMessage)
+	if !ok {
+		return errors.New("unexpected message type")
+	}
+	m.to <- playerID
+	m.sent <- wsMsg
+	return nil
+}
+
+func (m *recordingMessenger) next(t *testing.T) (string, HandSummary) {
+	select {
+	case playerID := <-m.to:
+		msg := <-m.sent
+		assert.Equal(t, "hand_stats", msg.Type)
+		summary, ok := msg.Data.(HandSummary)
+		require.True(t, ok)
+		return playerID, summary
+	case <-time.After(time.Second):
+		t.Fatal("no hand summary delivered")
+		return "", HandSummary{}
+	}
+}
+
+func (m *recordingMessenger) assertNothingSent(t *testing.T) {
+	select {
+	case playerID := <-m.to:
+		t.Fatalf("unexpected hand summary for %s", playerID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func handResultsOf(t *testing.T, engine *TexasHoldemEngine) []HandResult {
+	for _, event := range engine.GetEvents() {
+		if event.Type == "pot_distributed" {
+			results, ok := event.Data["results"].([]HandResult)
+			require.True(t, ok)
+			return results
+		}
+	}
+	t.Fatal("no pot_distributed event")
+	return nil
+}
+
+func TestPotDistributedReportsHandResults(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 100, "2": 300, "3": 300}, nil)
+
+	require.NoError(t, engine.showdown())
+
+	results := handResultsOf(t, engine)
+	require.Len(t, results, 3)
+	assert.Equal(t, HandResult{PlayerID: "1", Invested: 100, Collected: 300, Net: 200, Showdown: ShowdownWon, Hand: results[0].Hand, BestCards: results[0].BestCards}, results[0])
+	assert.Equal(t, 100, results[1].Net, "side pot winner")
+	assert.Equal(t, ShowdownWon, results[1].Showdown)
+	assert.Equal(t, -300, results[2].Net)
+	assert.Equal(t, ShowdownLost, results[2].Showdown)
+	assert.NotEmpty(t, results[0].Hand)
+	assert.Len(t, results[0].BestCards, 5)
+}
+
+func TestPotDistributedReportsSplitsAndFoldWins(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, Queen)},
+		"3": {NewCard(Diamonds, King), NewCard(Clubs, Queen)},
+	}, map[string]int{"1": 100, "2": 250, "3": 250}, nil)
+	require.NoError(t, engine.showdown())
+	results := handResultsOf(t, engine)
+	assert.Equal(t, ShowdownSplit, results[1].Showdown)
+	assert.Equal(t, -100, results[1].Net)
+
+	engine = setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+	}, map[string]int{"1": 50, "2": 20}, map[string]bool{"2": true})
+	engine.winners = []*TexasHoldemPlayer{engine.getHoldemPlayer("1")}
+	engine.distributePot()
+	results = handResultsOf(t, engine)
+	assert.Equal(t, 20, results[0].Net)
+	assert.Empty(t, results[0].Showdown, "no showdown when everyone else folds")
+	assert.Equal(t, -20, results[1].Net)
+}
+
+func TestHandStatsTracksSessionNet(t *testing.T) {
+	stats := NewHandStats()
+	table := NewGameTable("stats_table", "Stats", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+
+	stats.RecordHand(table, []HandResult{{PlayerID: "a", Net: 40}, {PlayerID: "b", Net: -40}})
+	summaries := stats.RecordHand(table, []HandResult{{PlayerID: "a", Net: -10}, {PlayerID: "b", Net: 10}})
+
+	assert.Equal(t, 2, summaries[0].HandNumber)
+	assert.Equal(t, -10, summaries[0].Net)
+	assert.Equal(t, 30, summaries[0].SessionNet)
+	assert.Equal(t, -30, stats.SessionNet(table.ID, "b"))
+
+	stats.ResetSession(table.ID, "a")
+	assert.Zero(t, stats.SessionNet(table.ID, "a"))
+	assert.Equal(t, -30, stats.SessionNet(table.ID, "b"))
+}
+
+func TestHandStatsPushesOnlyToSeatedPlayersWhoOptIn(t *testing.T) {
+	stats := NewHandStats()
+	messenger := newRecordingMessenger()
+	stats.SetMessenger(messenger)
+	stats.SetPreference(func(playerID string) bool { return playerID != "quiet" })
+
+	table := NewGameTable("stats_table", "Stats", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+	table.PlayerSlots[0].PlayerID = "seated"
+	table.PlayerSlots[1].PlayerID = "quiet"
+
+	stats.RecordHand(table, []HandResult{
+		{PlayerID: "seated", Invested: 10, Collected: 30, Net: 20},
+		{PlayerID: "quiet", Invested: 10, Net: -10},
+		{PlayerID: "left", Invested: 10, Net: -10},
+	})
+
+	playerID, summary := messenger.next(t)
+	assert.Equal(t, "seated", playerID)
+	assert.Equal(t, "stats_table", summary.TableID)
+	assert.Equal(t, 20, summary.SessionNet)
+	messenger.assertNothingSent(t)
+}
+
+func TestTableManagerPushesHandStatsAfterHand(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	messenger := newRecordingMessenger()
+	manager.HandStats().SetMessenger(messenger)
+
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "Stats Table", GameType: GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: DefaultTableSettings(),
+	})
+	require.NoError(t, err)
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	for i, playerID := range []string{"p1", "p2"} {
+		require.NoError(t, joinWithBuyIn(manager, table.ID, playerID, 0))
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Position: i + 1}))
+	}
+	require.NoError(t, engine.Start())
+
+	folder := engine.getCurrentActionPlayerID()
+	_, err = engine.ProcessAction(context.Background(), &GameAction{
+		Type: "texas_holdem_action", PlayerID: folder, Data: map[string]interface{}{"action": "fold"},
+	})
+	require.NoError(t, err)
+
+	received := map[string]HandSummary{}
+	for i := 0; i < 2; i++ {
+		playerID, summary := messenger.next(t)
+		received[playerID] = summary
+	}
+	assert.Less(t, received[folder].Net, 0)
+	assert.Equal(t, received[folder].Net, manager.HandStats().SessionNet(table.ID, folder))
+
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: folder}))
+	assert.Zero(t, manager.HandStats().SessionNet(table.ID, folder), "leaving ends the session")
+}
@@ -0,0 +1,44 @@
+package game
+
+import "sort"
+
+// HandSummaryEvent is the game event closing every hand. It is the
+// canonical result of the hand: clients draw the end-of-hand view from
+// it and the table manager feeds it to hand stats, lobby stats, hand
+// history and hand listeners.
+const HandSummaryEvent = "hand_summary"
+
+// PotContribution is what one player put into a hand's pots
+type PotContribution struct {
+	PlayerID string `json:"playerId"`
+	Seat     int    `json:"seat"`
+	Amount   int    `json:"amount"`
+	Folded   bool   `json:"folded"`
+}
+
+// emitHandSummary announces where every chip of the hand just settled went:
+// who put in what, how the pots were split, the rake and each player's net
+func (the *TexasHoldemEngine) emitHandSummary(contributions []potContribution, pots []Pot, totalPot, rake int, results []HandResult) {
+	summary := make([]PotContribution, 0, len(contributions))
+	for _, contribution := range contributions {
+		summary = append(summary, PotContribution{
+			PlayerID: contribution.PlayerID,
+			Seat:     contribution.Position,
+			Amount:   contribution.Amount,
+			Folded:   contribution.Folded,
+		})
+	}
+	sort.SliceStable(summary, func(i, j int) bool { return summary[i].Seat < summary[j].Seat })
+
+	the.emitEvent(&GameEvent{
+		Type: HandSummaryEvent,
+		Data: map[string]interface{}{
+			"handNumber":    the.handsDealt,
+			"totalPot":      totalPot, // Before rake
+			"rake":          rake,
+			"contributions": summary, // In seat order
+			"pots":          pots,
+			"results":       results,
+		},
+	})
+}
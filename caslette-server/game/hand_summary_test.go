@@ -0,0 +1,45 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandSummaryBreaksDownTheHand(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 100, "2": 300, "3": 300}, map[string]bool{"3": true})
+	engine.SetRake(5, 0)
+	engine.handsDealt = 4
+
+	require.NoError(t, engine.showdown())
+
+	summary := lastEventOfType(engine, HandSummaryEvent)
+	require.NotNil(t, summary)
+	assert.Equal(t, 4, summary.Data["handNumber"])
+	assert.Equal(t, 700, summary.Data["totalPot"])
+	assert.Equal(t, 35, summary.Data["rake"])
+	assert.Equal(t, []PotContribution{
+		{PlayerID: "1", Seat: 0, Amount: 100},
+		{PlayerID: "2", Seat: 1, Amount: 300},
+		{PlayerID: "3", Seat: 2, Amount: 300, Folded: true},
+	}, summary.Data["contributions"])
+
+	pots := summary.Data["pots"].([]Pot)
+	require.Len(t, pots, 2)
+	assert.Equal(t, Pot{Name: "main", Amount: 265, Eligible: []string{"1", "2"}, Winners: []string{"1"}, Share: 265, Rake: 35}, pots[0])
+	assert.Equal(t, []string{"2"}, pots[1].Winners)
+
+	results := summary.Data["results"].([]HandResult)
+	require.Len(t, results, 3)
+	net := 0
+	for _, result := range results {
+		net += result.Net
+	}
+	assert.Equal(t, -35, net, "players lose exactly the rake between them")
+	assert.Equal(t, handResultsOf(t, engine), results, "the summary carries the same results as pot_distributed")
+}
@@ -0,0 +1,471 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HeadsUpStakes is a stake level players can queue for. Every match at it
+// buys both players in for the same stack.
+type HeadsUpStakes struct {
+	ID         string `json:"id"`
+	SmallBlind int    `json:"small_blind"`
+	BigBlind   int    `json:"big_blind"`
+	BuyIn      int    `json:"buy_in"` // Diamonds escrowed from each player for the match
+}
+
+// DefaultHeadsUpStakes are the queues offered when the operator sets none,
+// each buying in for 100 big blinds
+func DefaultHeadsUpStakes() []HeadsUpStakes {
+	return []HeadsUpStakes{
+		{ID: "1/2", SmallBlind: 1, BigBlind: 2, BuyIn: 200},
+		{ID: "5/10", SmallBlind: 5, BigBlind: 10, BuyIn: 1000},
+		{ID: "25/50", SmallBlind: 25, BigBlind: 50, BuyIn: 5000},
+	}
+}
+
+// HeadsUpQueueInfo is the lobby view of one stake level's queue
+type HeadsUpQueueInfo struct {
+	HeadsUpStakes
+	Waiting int `json:"waiting"`
+	Matches int `json:"matches"` // Matches in play at these stakes
+}
+
+// HeadsUpMatch is a two-player table created by pairing two queued players
+type HeadsUpMatch struct {
+	TableID   string        `json:"table_id"`
+	Stakes    HeadsUpStakes `json:"stakes"`
+	Players   []string      `json:"players"`
+	StartedAt time.Time     `json:"started_at"`
+}
+
+// headsUpEntry is a player waiting for an opponent; their buy-in is already
+// taken
+type headsUpEntry struct {
+	PlayerID string
+	Username string
+	Stakes   HeadsUpStakes // The level their buy-in was taken at
+	JoinedAt time.Time
+}
+
+// HeadsUpQueue pairs players who want a heads-up match at the same stakes.
+// Joining takes the buy-in; the next player to join at those stakes is
+// matched with whoever has waited longest, and the two are seated at a new
+// two-seat table. The table is torn down and the stacks paid back out once a
+// player busts or leaves.
+type HeadsUpQueue struct {
+	tableManager *ActorTableManager
+	messenger    PlayerMessenger
+
+	mu      sync.Mutex
+	stakes  map[string]HeadsUpStakes
+	waiting map[string][]headsUpEntry // Stakes ID -> players, longest waiting first
+	queued  map[string]string         // Player ID -> stakes ID
+	matches map[string]*HeadsUpMatch  // Table ID -> match
+	playing map[string]string         // Player ID -> match table ID
+}
+
+// NewHeadsUpQueue creates a queue offering the default stakes and starts
+// watching the manager's hands for the end of matches
+func NewHeadsUpQueue(tableManager *ActorTableManager) *HeadsUpQueue {
+	q := &HeadsUpQueue{
+		tableManager: tableManager,
+		waiting:      make(map[string][]headsUpEntry),
+		queued:       make(map[string]string),
+		matches:      make(map[string]*HeadsUpMatch),
+		playing:      make(map[string]string),
+	}
+	q.SetStakes(DefaultHeadsUpStakes())
+	tableManager.AddHandListener(q.onHand)
+	return q
+}
+
+// SetMessenger sets how matched and finished players are told
+func (q *HeadsUpQueue) SetMessenger(messenger PlayerMessenger) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.messenger = messenger
+}
+
+// SetStakes replaces the stake levels offered. Players waiting at levels no
+// longer offered keep their place until they leave.
+func (q *HeadsUpQueue) SetStakes(stakes []HeadsUpStakes) error {
+	levels := make(map[string]HeadsUpStakes, len(stakes))
+	for _, level := range stakes {
+		if level.ID == "" {
+			return fmt.Errorf("stakes need an ID")
+		}
+		if level.SmallBlind <= 0 || level.BigBlind <= level.SmallBlind {
+			return fmt.Errorf("stakes %s: blinds must be positive with the big blind above the small blind", level.ID)
+		}
+		if level.BuyIn < level.BigBlind {
+			return fmt.Errorf("stakes %s: buy-in must cover at least the big blind", level.ID)
+		}
+		if _, duplicate := levels[level.ID]; duplicate {
+			return fmt.Errorf("stakes %s are listed twice", level.ID)
+		}
+		levels[level.ID] = level
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stakes = levels
+	return nil
+}
+
+// Queues lists the stake levels with how many players are waiting, lowest
+// stakes first
+func (q *HeadsUpQueue) Queues() []HeadsUpQueueInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	infos := make([]HeadsUpQueueInfo, 0, len(q.stakes))
+	for id, level := range q.stakes {
+		info := HeadsUpQueueInfo{HeadsUpStakes: level, Waiting: len(q.waiting[id])}
+		for _, match := range q.matches {
+			if match.Stakes.ID == id {
+				info.Matches++
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].BigBlind != infos[j].BigBlind {
+			return infos[i].BigBlind < infos[j].BigBlind
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// Join takes the player's buy-in and queues them at the stakes. If someone is
+// already waiting there the two are matched and the match is returned;
+// otherwise the player waits and the match is nil.
+func (q *HeadsUpQueue) Join(ctx context.Context, playerID, username, stakesID string) (*HeadsUpMatch, error) {
+	q.mu.Lock()
+	level, ok := q.stakes[stakesID]
+	if !ok {
+		q.mu.Unlock()
+		return nil, &TableError{"UNKNOWN_STAKES", "No heads-up queue at these stakes"}
+	}
+	if _, waiting := q.queued[playerID]; waiting {
+		q.mu.Unlock()
+		return nil, &TableError{"ALREADY_QUEUED", "Player is already waiting for a heads-up match"}
+	}
+	if _, inMatch := q.playing[playerID]; inMatch {
+		q.mu.Unlock()
+		return nil, &TableError{"ALREADY_IN_MATCH", "Player is already playing a heads-up match"}
+	}
+	if err := q.debit(playerID, level); err != nil {
+		q.mu.Unlock()
+		return nil, err
+	}
+
+	entry := headsUpEntry{PlayerID: playerID, Username: username, Stakes: level, JoinedAt: time.Now()}
+	waiting := q.waiting[stakesID]
+	if len(waiting) == 0 {
+		q.waiting[stakesID] = append(waiting, entry)
+		q.queued[playerID] = stakesID
+		q.mu.Unlock()
+		return nil, nil
+	}
+
+	// Both players are held out of the queue while their table is set up
+	opponent := waiting[0]
+	q.waiting[stakesID] = waiting[1:]
+	delete(q.queued, opponent.PlayerID)
+	q.playing[opponent.PlayerID] = ""
+	q.playing[playerID] = ""
+	q.mu.Unlock()
+
+	match, err := q.startMatch(ctx, level, opponent, entry)
+	if err != nil {
+		q.mu.Lock()
+		delete(q.playing, opponent.PlayerID)
+		delete(q.playing, playerID)
+		q.mu.Unlock()
+
+		// Neither player is charged for a match that could not be set up
+		q.refund(playerID, level)
+		q.refund(opponent.PlayerID, level)
+		q.notify(opponent.PlayerID, "heads_up_cancelled", map[string]interface{}{
+			"stakes": level,
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+	q.notify(opponent.PlayerID, "heads_up_matched", match)
+	return match, nil
+}
+
+// Leave takes a waiting player out of the queue and refunds their buy-in
+func (q *HeadsUpQueue) Leave(playerID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stakesID, waiting := q.queued[playerID]
+	if !waiting {
+		return &TableError{"NOT_QUEUED", "Player is not waiting for a heads-up match"}
+	}
+	entries := q.waiting[stakesID]
+	for i, entry := range entries {
+		if entry.PlayerID == playerID {
+			q.waiting[stakesID] = append(entries[:i:i], entries[i+1:]...)
+			delete(q.queued, playerID)
+			q.refund(playerID, entry.Stakes)
+			break
+		}
+	}
+	return nil
+}
+
+// Match returns the match being played at a table
+func (q *HeadsUpQueue) Match(tableID string) (*HeadsUpMatch, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	match, ok := q.matches[tableID]
+	return match, ok
+}
+
+// startMatch creates the two-seat table, seats both players with their
+// escrowed buy-ins and deals the first hand
+func (q *HeadsUpQueue) startMatch(ctx context.Context, level HeadsUpStakes, first, second headsUpEntry) (*HeadsUpMatch, error) {
+	tm := q.tableManager
+	table, err := tm.CreateTable(ctx, &TableCreateRequest{
+		Name:       fmt.Sprintf("Heads-up %d-%d", level.SmallBlind, level.BigBlind),
+		GameType:   GameTypeTexasHoldem,
+		CreatedBy:  "heads_up_" + tm.generateTableID(),
+		Username:   "heads_up",
+		Settings:   headsUpSettings(level),
+		Tags:       []string{"heads-up"},
+		Sanctioned: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heads-up table: %w", err)
+	}
+
+	for _, entry := range []headsUpEntry{first, second} {
+		if err := tm.JoinTable(ctx, &TableJoinRequest{
+			TableID:  table.ID,
+			PlayerID: entry.PlayerID,
+			Username: entry.Username,
+			Mode:     JoinModePlayer,
+			BuyIn:    level.BuyIn,
+			Prepaid:  true,
+		}); err != nil {
+			q.closeTable(ctx, table)
+			return nil, fmt.Errorf("failed to seat %s: %w", entry.PlayerID, err)
+		}
+	}
+
+	match := &HeadsUpMatch{
+		TableID:   table.ID,
+		Stakes:    level,
+		Players:   []string{first.PlayerID, second.PlayerID},
+		StartedAt: time.Now(),
+	}
+	q.mu.Lock()
+	q.matches[table.ID] = match
+	q.playing[first.PlayerID] = table.ID
+	q.playing[second.PlayerID] = table.ID
+	q.mu.Unlock()
+
+	if err := tm.tryStartGame(table); err != nil {
+		q.mu.Lock()
+		delete(q.matches, table.ID)
+		q.mu.Unlock()
+		q.closeTable(ctx, table)
+		return nil, fmt.Errorf("failed to start heads-up match: %w", err)
+	}
+	return match, nil
+}
+
+// headsUpSettings are the table settings for a match at the stakes
+func headsUpSettings(level HeadsUpStakes) TableSettings {
+	settings := DefaultTableSettings()
+	settings.SmallBlind = level.SmallBlind
+	settings.BigBlind = level.BigBlind
+	settings.BuyIn = level.BuyIn
+	settings.MaxBuyIn = level.BuyIn
+	settings.MinPlayers = 2
+	settings.MaxPlayers = 2
+	settings.AutoStart = false
+	settings.ObserversAllowed = true
+	return settings
+}
+
+// onHand ends a match once a hand leaves one of the players without chips
+func (q *HeadsUpQueue) onHand(table *GameTable, results []HandResult) {
+	q.mu.Lock()
+	_, isMatch := q.matches[table.ID]
+	q.mu.Unlock()
+	if !isMatch {
+		return
+	}
+
+	holder, ok := table.GameEngine.(ChipHolder)
+	if !ok {
+		return
+	}
+	stacks, _ := holder.ChipCounts()
+	for _, chips := range stacks {
+		if chips <= 0 {
+			q.endMatch(context.Background(), table.ID, "busted", nil)
+			return
+		}
+	}
+}
+
+// playerLeft ends a match when one of its players leaves the table; their
+// stack is paid back along with their opponent's
+func (q *HeadsUpQueue) playerLeft(ctx context.Context, tableID, playerID string, stack int) {
+	q.mu.Lock()
+	_, isMatch := q.matches[tableID]
+	q.mu.Unlock()
+	if !isMatch {
+		return
+	}
+	q.endMatch(ctx, tableID, "player_left", map[string]int{playerID: stack})
+}
+
+// endMatch pays out every player's stack, closes the table and tells the
+// players how it ended. departed holds the stacks of players already gone.
+func (q *HeadsUpQueue) endMatch(ctx context.Context, tableID, reason string, departed map[string]int) {
+	q.mu.Lock()
+	match, exists := q.matches[tableID]
+	if !exists {
+		q.mu.Unlock()
+		return
+	}
+	delete(q.matches, tableID)
+	for _, playerID := range match.Players {
+		delete(q.playing, playerID)
+	}
+	q.mu.Unlock()
+
+	stacks := make(map[string]int, len(match.Players))
+	pot := 0
+	if table, err := q.tableManager.GetTable(tableID); err == nil {
+		if holder, ok := table.GameEngine.(ChipHolder); ok {
+			_, pot = holder.ChipCounts()
+		}
+		stacks = q.closeTable(ctx, table)
+		for playerID := range departed {
+			q.tableManager.ratholes.Clear(playerID, table)
+		}
+	}
+	for playerID, stack := range departed {
+		stacks[playerID] = stack
+	}
+
+	// A player who leaves forfeits the match, and with it any pot in play
+	winner := ""
+	for _, playerID := range match.Players {
+		if _, left := departed[playerID]; !left && (len(departed) > 0 || stacks[playerID] > 0) {
+			winner = playerID
+		}
+	}
+	if winner != "" {
+		stacks[winner] += pot
+	}
+	for _, playerID := range match.Players {
+		q.cashOut(playerID, stacks[playerID], match.Stakes)
+	}
+
+	for _, playerID := range match.Players {
+		q.notify(playerID, "heads_up_finished", map[string]interface{}{
+			"table_id": tableID,
+			"stakes":   match.Stakes,
+			"reason":   reason,
+			"winner":   winner,
+			"stacks":   stacks,
+		})
+	}
+}
+
+// closeTable unseats the players still at a match table and closes it,
+// returning the stacks they left with. Matches buy in for a fixed stack, so
+// cashing out of one does not count against the player's next buy-in.
+func (q *HeadsUpQueue) closeTable(ctx context.Context, table *GameTable) map[string]int {
+	tm := q.tableManager
+	stacks := make(map[string]int)
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == "" {
+			continue
+		}
+		stack, err := tm.leaveTable(ctx, &TableLeaveRequest{TableID: table.ID, PlayerID: slot.PlayerID})
+		if err != nil {
+			log.Printf("Heads-up %s: failed to unseat %s: %v", table.ID, slot.PlayerID, err)
+			continue
+		}
+		stacks[slot.PlayerID] = stack
+		tm.ratholes.Clear(slot.PlayerID, table)
+	}
+	if err := tm.CloseTable(table.ID); err != nil && err != ErrTableNotFound {
+		log.Printf("Heads-up %s: failed to close table: %v", table.ID, err)
+	}
+	return stacks
+}
+
+// debit takes a player's buy-in for the stakes
+func (q *HeadsUpQueue) debit(playerID string, level HeadsUpStakes) error {
+	ledger := q.tableManager.diamondLedger()
+	if ledger == nil {
+		return &TableError{"NO_LEDGER", "Heads-up matches need a diamond ledger"}
+	}
+	if err := ledger.Debit(playerID, level.BuyIn, "Heads-up buy-in: "+level.ID); err != nil {
+		return &TableError{"BUY_IN_FAILED", "Could not take the buy-in: " + err.Error()}
+	}
+	return nil
+}
+
+// refund gives an unused buy-in back
+func (q *HeadsUpQueue) refund(playerID string, level HeadsUpStakes) {
+	if ledger := q.tableManager.diamondLedger(); ledger != nil && level.BuyIn > 0 {
+		if err := ledger.Credit(playerID, level.BuyIn, "Heads-up refund: "+level.ID); err != nil {
+			log.Printf("Heads-up: failed to refund %d diamonds to %s: %v", level.BuyIn, playerID, err)
+		}
+	}
+}
+
+// cashOut pays a finished player's stack back in diamonds
+func (q *HeadsUpQueue) cashOut(playerID string, stack int, level HeadsUpStakes) {
+	if stack <= 0 {
+		return
+	}
+	if ledger := q.tableManager.diamondLedger(); ledger != nil {
+		if err := ledger.Credit(playerID, stack, "Heads-up cash-out: "+level.ID); err != nil {
+			log.Printf("Heads-up: failed to pay %d diamonds to %s: %v", stack, playerID, err)
+		}
+	}
+}
+
+// notify sends a heads-up message privately to a player
+func (q *HeadsUpQueue) notify(playerID, msgType string, data interface{}) {
+	q.mu.Lock()
+	messenger := q.messenger
+	q.mu.Unlock()
+	if messenger == nil {
+		return
+	}
+	msg := &WebSocketMessage{Type: msgType, Data: data, Success: true}
+	if err := messenger.SendToPlayer(playerID, msg); err != nil {
+		log.Printf("Heads-up: failed to notify %s: %v", playerID, err)
+	}
+}
+
+// HeadsUp returns the manager's heads-up matchmaking queue
+func (tm *ActorTableManager) HeadsUp() *HeadsUpQueue {
+	return tm.headsUp
+}
+
+// diamondLedger returns the ledger paid games charge and pay through
+func (tm *ActorTableManager) diamondLedger() DiamondLedger {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.ledger
+}
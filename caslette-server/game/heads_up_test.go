@@ -0,0 +1,171 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHeadsUpManager deals real hands, holds each next hand back so tests
+// control the pace, and gives every player 1000 diamonds
+func newHeadsUpManager(t *testing.T, players ...string) (*ActorTableManager, *fakeLedger, *recordingMessenger) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	manager.SetInterHandDelay(time.Hour)
+	t.Cleanup(manager.Stop)
+
+	balances := make(map[string]int)
+	for _, playerID := range players {
+		balances[playerID] = 1000
+	}
+	ledger := newFakeLedger(balances)
+	manager.SetDiamondLedger(ledger)
+	messenger := newRecordingMessenger()
+	manager.HeadsUp().SetMessenger(messenger)
+	return manager, ledger, messenger
+}
+
+// nextHeadsUpMessage waits for the next message sent to a player
+func nextHeadsUpMessage(t *testing.T, messenger *recordingMessenger) (string, *WebSocketMessage) {
+	t.Helper()
+	select {
+	case playerID := <-messenger.to:
+		return playerID, <-messenger.sent
+	case <-time.After(time.Second):
+		t.Fatal("no message sent")
+		return "", nil
+	}
+}
+
+// startHeadsUpMatch pairs a and b at 1/2
+func startHeadsUpMatch(t *testing.T, manager *ActorTableManager, messenger *recordingMessenger) (*HeadsUpMatch, *GameTable) {
+	ctx := context.Background()
+	match, err := manager.HeadsUp().Join(ctx, "a", "a", "1/2")
+	require.NoError(t, err)
+	require.Nil(t, match, "the first player waits for an opponent")
+
+	match, err = manager.HeadsUp().Join(ctx, "b", "b", "1/2")
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	playerID, msg := nextHeadsUpMessage(t, messenger)
+	assert.Equal(t, "a", playerID, "the waiting player hears about the match")
+	assert.Equal(t, "heads_up_matched", msg.Type)
+
+	table, err := manager.GetTable(match.TableID)
+	require.NoError(t, err)
+	return match, table
+}
+
+func TestHeadsUpQueuePairsNextEntrants(t *testing.T) {
+	manager, ledger, messenger := newHeadsUpManager(t, "a", "b", "c")
+	manager.SetApprovalThreshold(1)
+
+	match, table := startHeadsUpMatch(t, manager, messenger)
+	assert.Equal(t, []string{"a", "b"}, match.Players)
+	assert.Equal(t, 2, table.MaxPlayers)
+	assert.Equal(t, 2, table.GetPlayerCount())
+	assert.False(t, table.AwaitingApproval(), "matchmaking tables need no approval")
+	assert.Equal(t, TableStatusActive, table.Status)
+	assert.Equal(t, GameStateInProgress, table.GameEngine.(*TexasHoldemEngine).GetState())
+	assert.Equal(t, 800, ledger.balance("a"))
+	assert.Equal(t, 800, ledger.balance("b"))
+	assert.Equal(t, int64(400), manager.Escrow().Total(table.ID))
+
+	_, err := manager.HeadsUp().Join(context.Background(), "a", "a", "1/2")
+	assert.Error(t, err, "a player in a match cannot queue again")
+
+	match, err = manager.HeadsUp().Join(context.Background(), "c", "c", "1/2")
+	require.NoError(t, err)
+	assert.Nil(t, match)
+	queues := manager.HeadsUp().Queues()
+	require.Len(t, queues, 3)
+	assert.Equal(t, "1/2", queues[0].ID)
+	assert.Equal(t, 1, queues[0].Waiting)
+	assert.Equal(t, 1, queues[0].Matches)
+}
+
+func TestHeadsUpLeaveRefundsBuyIn(t *testing.T) {
+	manager, ledger, _ := newHeadsUpManager(t, "a")
+	ledger.balances["broke"] = 100
+	ctx := context.Background()
+
+	_, err := manager.HeadsUp().Join(ctx, "a", "a", "5/10")
+	require.NoError(t, err)
+	assert.Equal(t, 0, ledger.balance("a"))
+	_, err = manager.HeadsUp().Join(ctx, "a", "a", "1/2")
+	assert.Error(t, err, "a player waits in one queue at a time")
+
+	require.NoError(t, manager.HeadsUp().Leave("a"))
+	assert.Equal(t, 1000, ledger.balance("a"))
+	assert.Zero(t, manager.HeadsUp().Queues()[1].Waiting)
+	assert.Error(t, manager.HeadsUp().Leave("a"))
+
+	_, err = manager.HeadsUp().Join(ctx, "a", "a", "2/4")
+	assert.Error(t, err, "unknown stakes")
+	_, err = manager.HeadsUp().Join(ctx, "broke", "broke", "1/2")
+	assert.Error(t, err)
+	assert.Equal(t, 100, ledger.balance("broke"))
+}
+
+func TestHeadsUpMatchEndsWhenPlayerLeaves(t *testing.T) {
+	manager, ledger, messenger := newHeadsUpManager(t, "a", "b")
+	match, _ := startHeadsUpMatch(t, manager, messenger)
+
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: match.TableID, PlayerID: "a"}))
+
+	_, err := manager.GetTable(match.TableID)
+	assert.ErrorIs(t, err, ErrTableNotFound, "the match table is torn down")
+	assert.Zero(t, manager.Escrow().Total(match.TableID))
+	assert.Equal(t, 2000, ledger.balance("a")+ledger.balance("b"), "both stacks and the blinds in the pot are paid out")
+	assert.Greater(t, ledger.balance("b"), ledger.balance("a"), "the player who stays wins the pot")
+
+	for i := 0; i < 2; i++ {
+		_, msg := nextHeadsUpMessage(t, messenger)
+		assert.Equal(t, "heads_up_finished", msg.Type)
+		data := msg.Data.(map[string]interface{})
+		assert.Equal(t, "player_left", data["reason"])
+		assert.Equal(t, "b", data["winner"])
+	}
+	_, err = manager.HeadsUp().Join(context.Background(), "a", "a", "1/2")
+	assert.NoError(t, err, "a finished match frees both players to queue again")
+}
+
+func TestHeadsUpMatchEndsWhenPlayerBusts(t *testing.T) {
+	manager, ledger, messenger := newHeadsUpManager(t, "a", "b")
+	match, table := startHeadsUpMatch(t, manager, messenger)
+	engine := table.GameEngine.(*TexasHoldemEngine)
+
+	// The player to act loses everything behind, then folds the blind
+	loser := engine.getCurrentActionPlayerID()
+	winner := "a"
+	if loser == "a" {
+		winner = "b"
+	}
+	stacks, _ := engine.ChipCounts()
+	require.NoError(t, engine.AdjustChips(loser, -stacks[loser]))
+	require.NoError(t, act(engine, ActionFold, 0))
+
+	_, msg := nextHeadsUpMessage(t, messenger)
+	assert.Equal(t, "heads_up_finished", msg.Type)
+	data := msg.Data.(map[string]interface{})
+	assert.Equal(t, "busted", data["reason"])
+	assert.Equal(t, winner, data["winner"])
+
+	_, err := manager.GetTable(match.TableID)
+	assert.ErrorIs(t, err, ErrTableNotFound)
+	assert.Equal(t, 800, ledger.balance(loser))
+	assert.Greater(t, ledger.balance(winner), 1000)
+	_, ok := manager.HeadsUp().Match(match.TableID)
+	assert.False(t, ok)
+}
+
+func TestHeadsUpStakesAreValidated(t *testing.T) {
+	queue := NewActorTableManager(nil).HeadsUp()
+	assert.Error(t, queue.SetStakes([]HeadsUpStakes{{ID: "bad", SmallBlind: 2, BigBlind: 1, BuyIn: 100}}))
+	assert.Error(t, queue.SetStakes([]HeadsUpStakes{{ID: "short", SmallBlind: 1, BigBlind: 2, BuyIn: 1}}))
+	assert.Error(t, queue.SetStakes([]HeadsUpStakes{{SmallBlind: 1, BigBlind: 2, BuyIn: 200}}))
+	require.NoError(t, queue.SetStakes([]HeadsUpStakes{{ID: "10/20", SmallBlind: 10, BigBlind: 20, BuyIn: 2000}}))
+	assert.Len(t, queue.Queues(), 1)
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	"context"
+)
+
+// headsUpJoinRequest names the stakes a player queues for
+type headsUpJoinRequest struct {
+	Stakes string `json:"stakes"`
+}
+
+// handleListHeadsUp lists the heads-up stakes and how many are waiting at each
+func (h *TableWebSocketHandler) handleListHeadsUp(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	return h.successResponse(msg.RequestID, "heads_up_list", map[string]interface{}{
+		"queues": h.tableManager.HeadsUp().Queues(),
+	})
+}
+
+// handleJoinHeadsUp takes the caller's buy-in and queues them, matching them
+// straight away when an opponent is already waiting
+func (h *TableWebSocketHandler) handleJoinHeadsUp(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req headsUpJoinRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	match, err := h.tableManager.HeadsUp().Join(ctx, conn.GetUserID(), conn.GetUsername(), req.Stakes)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "JOIN_FAILED", err.Error())
+	}
+	if match != nil {
+		return h.successResponse(msg.RequestID, "heads_up_matched", match)
+	}
+	return h.successResponse(msg.RequestID, "heads_up_queued", map[string]interface{}{
+		"stakes": req.Stakes,
+	})
+}
+
+// handleLeaveHeadsUp takes the caller out of the queue and refunds them
+func (h *TableWebSocketHandler) handleLeaveHeadsUp(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	if err := h.tableManager.HeadsUp().Leave(conn.GetUserID()); err != nil {
+		return h.errorResponse(msg.RequestID, "LEAVE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "heads_up_left", nil)
+}
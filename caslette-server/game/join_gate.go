@@ -0,0 +1,62 @@
+package game
+
+import "sync"
+
+// JoinGate keeps players from taking seats while something that must not
+// race a seat runs against their account, such as merging it into another
+type JoinGate struct {
+	mu      sync.Mutex
+	idle    *sync.Cond
+	joining map[string]int  // Player ID -> joins under way
+	blocked map[string]bool // Players who may not sit down
+}
+
+// NewJoinGate creates a gate letting everyone join
+func NewJoinGate() *JoinGate {
+	g := &JoinGate{
+		joining: make(map[string]int),
+		blocked: make(map[string]bool),
+	}
+	g.idle = sync.NewCond(&g.mu)
+	return g
+}
+
+// Block refuses the player's joins from now on and waits for joins already
+// under way to finish, so once it returns the player's seats only change
+// by leaving. The returned release lets them join again.
+func (g *JoinGate) Block(playerID string) (release func()) {
+	g.mu.Lock()
+	g.blocked[playerID] = true
+	for g.joining[playerID] > 0 {
+		g.idle.Wait()
+	}
+	g.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			delete(g.blocked, playerID)
+			g.mu.Unlock()
+		})
+	}
+}
+
+// enter admits a join unless the player is blocked; the caller must call
+// the returned done once the seat is taken or refused
+func (g *JoinGate) enter(playerID string) (done func(), err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.blocked[playerID] {
+		return nil, &TableError{"ACCOUNT_BUSY", "Your account is being updated; try again shortly"}
+	}
+	g.joining[playerID]++
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.joining[playerID]--; g.joining[playerID] == 0 {
+			delete(g.joining, playerID)
+			g.idle.Broadcast()
+		}
+	}, nil
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinGateHoldsOffBlockedPlayers(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	table := newBalancingTable(t, manager, "gate", DefaultTableSettings(), 0)
+
+	release := manager.JoinGate().Block("p1")
+	var tableErr *TableError
+	require.True(t, errors.As(joinWithBuyIn(manager, table.ID, "p1", 1000), &tableErr))
+	assert.Equal(t, "ACCOUNT_BUSY", tableErr.Code)
+	assert.NoError(t, joinWithBuyIn(manager, table.ID, "p2", 1000), "other players join as usual")
+
+	release()
+	release()
+	assert.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 1000))
+	assert.Empty(t, manager.JoinGate().blocked)
+	assert.Empty(t, manager.JoinGate().joining)
+}
+
+func TestJoinGateBlockWaitsForJoinsUnderWay(t *testing.T) {
+	gate := NewJoinGate()
+	done, err := gate.enter("p1")
+	require.NoError(t, err)
+
+	blocked := make(chan struct{})
+	go func() {
+		gate.Block("p1")
+		close(blocked)
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("Block returned while a join was under way")
+	case <-time.After(20 * time.Millisecond):
+	}
+	done()
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Fatal("Block did not return once the join finished")
+	}
+	_, err = gate.enter("p1")
+	assert.Error(t, err)
+}
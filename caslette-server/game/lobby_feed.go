@@ -0,0 +1,178 @@
+package game
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// LobbyRoomID is the room lobby subscribers join to be pushed table changes
+const LobbyRoomID = "lobby"
+
+// DefaultLobbyFeedInterval is the least time between two lobby updates
+const DefaultLobbyFeedInterval = 500 * time.Millisecond
+
+// TableChange is how a table changed as far as the lobby is concerned
+type TableChange string
+
+const (
+	TableCreated TableChange = "table_created" // Opened, or approved for the lobby
+	TableUpdated TableChange = "table_updated" // Seats, observers, status or settings changed
+	TableClosed  TableChange = "table_closed"
+)
+
+// TableListener is told whenever a managed table opens, changes in a way
+// the lobby shows, or closes
+type TableListener func(table *GameTable, change TableChange)
+
+// AddTableListener registers a listener for table changes and returns a
+// function that removes it
+func (tm *ActorTableManager) AddTableListener(listener TableListener) func() {
+	tm.mu.Lock()
+	id := tm.nextListenerID
+	tm.nextListenerID++
+	tm.tableListeners[id] = listener
+	tm.mu.Unlock()
+
+	return func() {
+		tm.mu.Lock()
+		delete(tm.tableListeners, id)
+		tm.mu.Unlock()
+	}
+}
+
+// notifyTableListeners passes a table change to every listener
+func (tm *ActorTableManager) notifyTableListeners(table *GameTable, change TableChange) {
+	tm.mu.RLock()
+	listeners := make([]TableListener, 0, len(tm.tableListeners))
+	for _, listener := range tm.tableListeners {
+		listeners = append(listeners, listener)
+	}
+	tm.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(table, change)
+	}
+}
+
+// tableChanged saves a table whose seats, observers or settings changed and
+// tells the table listeners
+func (tm *ActorTableManager) tableChanged(actor *TableActor) {
+	tm.persistTable(actor)
+	tm.notifyTableListeners(actor.table, TableUpdated)
+}
+
+// LobbyDelta is one table's change in a lobby update. Table is the table as
+// the lobby lists it, left out for closed tables.
+type LobbyDelta struct {
+	Type    TableChange            `json:"type"`
+	TableID string                 `json:"table_id"`
+	Table   map[string]interface{} `json:"table,omitempty"`
+}
+
+// pendingLobbyChange is a table change waiting for the next lobby update
+type pendingLobbyChange struct {
+	table  *GameTable
+	change TableChange
+}
+
+// LobbyFeed pushes table changes to the lobby room. Changes are held for an
+// interval and folded per table, so a table filling up seat by seat costs
+// subscribers one update rather than one per join, and a busy lobby at most
+// one message per interval.
+type LobbyFeed struct {
+	hub      WebSocketHub
+	interval time.Duration
+	filter   *DataFilter
+
+	mu      sync.Mutex
+	sendMu  sync.Mutex // Serializes broadcasts so updates leave in order
+	pending map[string]*pendingLobbyChange
+	order   []string // Table IDs in the order they first changed
+	timer   *time.Timer
+}
+
+// NewLobbyFeed creates a lobby feed sending at most one update per interval
+func NewLobbyFeed(hub WebSocketHub, interval time.Duration) *LobbyFeed {
+	if interval <= 0 {
+		interval = DefaultLobbyFeedInterval
+	}
+	return &LobbyFeed{
+		hub:      hub,
+		interval: interval,
+		filter:   NewDataFilter(),
+		pending:  make(map[string]*pendingLobbyChange),
+	}
+}
+
+// Record queues a table change for the next update (TableListener). Tables
+// awaiting approval are not in the lobby, so their changes are dropped.
+func (lf *LobbyFeed) Record(table *GameTable, change TableChange) {
+	if table == nil || (change != TableClosed && table.AwaitingApproval()) {
+		return
+	}
+
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	pending, exists := lf.pending[table.ID]
+	switch {
+	case !exists:
+		lf.pending[table.ID] = &pendingLobbyChange{table: table, change: change}
+		lf.order = append(lf.order, table.ID)
+	case pending.change == TableCreated && change == TableClosed:
+		// Subscribers never saw the table, so they need not hear of it
+		delete(lf.pending, table.ID)
+		for i, tableID := range lf.order {
+			if tableID == table.ID {
+				lf.order = append(lf.order[:i], lf.order[i+1:]...)
+				break
+			}
+		}
+	case pending.change == TableCreated:
+		// Still new to subscribers; the update sends its latest state
+	default:
+		pending.change = change
+	}
+
+	if lf.timer == nil {
+		lf.timer = time.AfterFunc(lf.interval, lf.Flush)
+	}
+}
+
+// Flush sends the queued changes to the lobby room as one lobby_update
+func (lf *LobbyFeed) Flush() {
+	lf.sendMu.Lock()
+	defer lf.sendMu.Unlock()
+
+	lf.mu.Lock()
+	pending, order := lf.pending, lf.order
+	lf.pending, lf.order = make(map[string]*pendingLobbyChange), nil
+	if lf.timer != nil {
+		lf.timer.Stop()
+		lf.timer = nil
+	}
+	lf.mu.Unlock()
+
+	// Nobody is watching the lobby, so there is nobody to tell
+	if len(order) == 0 || lf.hub == nil || len(lf.hub.GetRoomUsers(LobbyRoomID)) == 0 {
+		return
+	}
+	deltas := make([]LobbyDelta, 0, len(order))
+	for _, tableID := range order {
+		change := pending[tableID]
+		delta := LobbyDelta{Type: change.change, TableID: tableID}
+		if change.change != TableClosed {
+			delta.Table = lf.filter.FilterTableList([]*GameTable{change.table}, "")[0]
+		}
+		deltas = append(deltas, delta)
+	}
+
+	msg := &WebSocketMessage{
+		Type: "lobby_update",
+		Data: map[string]interface{}{"changes": deltas},
+		Room: LobbyRoomID,
+	}
+	if err := lf.hub.BroadcastToRoom(LobbyRoomID, msg); err != nil {
+		log.Printf("Failed to broadcast lobby update: %v", err)
+	}
+}
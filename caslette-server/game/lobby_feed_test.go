@@ -0,0 +1,120 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lobbyHub is a recording hub with someone in the lobby room
+type lobbyHub struct {
+	*recordingHub
+}
+
+func (h lobbyHub) GetRoomUsers(roomID string) []map[string]interface{} {
+	return []map[string]interface{}{{"user_id": "watcher"}}
+}
+
+// lobbyUpdates returns the changes of every lobby update sent so far
+func lobbyUpdates(hub lobbyHub) [][]LobbyDelta {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	updates := make([][]LobbyDelta, 0)
+	for _, msg := range hub.messages[LobbyRoomID] {
+		data := msg.(*WebSocketMessage).Data.(map[string]interface{})
+		updates = append(updates, data["changes"].([]LobbyDelta))
+	}
+	return updates
+}
+
+func TestLobbyFeedFoldsChangesPerTable(t *testing.T) {
+	hub := lobbyHub{newRecordingHub()}
+	feed := NewLobbyFeed(hub, time.Hour)
+	table := func(id string) *GameTable {
+		return NewGameTable(id, "table "+id, GameTypeTexasHoldem, "creator", DefaultTableSettings())
+	}
+	opened, busy, brief, closing, pending := table("opened"), table("busy"), table("brief"), table("closing"), table("pending")
+	pending.Approval = &TableApproval{Status: ApprovalPending}
+
+	feed.Record(opened, TableCreated)
+	feed.Record(busy, TableUpdated)
+	feed.Record(opened, TableUpdated)
+	feed.Record(brief, TableCreated)
+	feed.Record(busy, TableUpdated)
+	feed.Record(closing, TableUpdated)
+	feed.Record(brief, TableClosed)
+	feed.Record(closing, TableClosed)
+	feed.Record(pending, TableCreated)
+	feed.Flush()
+
+	updates := lobbyUpdates(hub)
+	require.Len(t, updates, 1, "one update for the whole burst")
+	changes := updates[0]
+	require.Len(t, changes, 3)
+	assert.Equal(t, LobbyDelta{Type: TableCreated, TableID: "opened", Table: changes[0].Table}, changes[0], "still new to subscribers")
+	assert.Equal(t, "table opened", changes[0].Table["name"])
+	assert.Equal(t, TableUpdated, changes[1].Type)
+	assert.Equal(t, "busy", changes[1].TableID)
+	assert.Equal(t, LobbyDelta{Type: TableClosed, TableID: "closing"}, changes[2])
+
+	feed.Flush()
+	assert.Len(t, lobbyUpdates(hub), 1, "nothing left to send")
+}
+
+func TestLobbyFeedThrottlesUpdates(t *testing.T) {
+	hub := lobbyHub{newRecordingHub()}
+	feed := NewLobbyFeed(hub, 20*time.Millisecond)
+	table := NewGameTable("t1", "Throttled", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+
+	feed.Record(table, TableCreated)
+	assert.Empty(t, lobbyUpdates(hub), "held until the interval passes")
+	assert.Eventually(t, func() bool { return len(lobbyUpdates(hub)) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestLobbyFeedSkipsAnEmptyLobby(t *testing.T) {
+	hub := &MockWebSocketHub{}
+	feed := NewLobbyFeed(hub, time.Hour)
+	feed.Record(NewGameTable("t1", "Nobody", GameTypeTexasHoldem, "creator", DefaultTableSettings()), TableCreated)
+	feed.Flush()
+	assert.Empty(t, hub.broadcastCalls)
+}
+
+func TestLobbySubscribeReceivesTableChanges(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	hub := lobbyHub{newRecordingHub()}
+	handler := NewTableWebSocketHandler(manager, hub)
+	t.Cleanup(handler.events.Stop)
+	newBalancingTable(t, manager, "early", DefaultTableSettings(), 1)
+	handler.lobby.Flush()
+
+	conn := NewMockConnection("watcher", "watcher")
+	response := handler.GetMessageHandlers()["lobby_subscribe"](context.Background(), conn, &WebSocketMessage{Type: "lobby_subscribe", RequestID: "r1"})
+	require.True(t, response.Success, response.Error)
+	assert.Equal(t, []string{LobbyRoomID}, conn.rooms)
+	tables := response.Data.(map[string]interface{})["tables"].([]map[string]interface{})
+	require.Len(t, tables, 1)
+	assert.Equal(t, "early", tables[0]["name"])
+
+	table := newBalancingTable(t, manager, "later", DefaultTableSettings(), 2)
+	handler.lobby.Flush()
+	updates := lobbyUpdates(hub)
+	require.Len(t, updates, 2)
+	require.Len(t, updates[1], 1, "the joins fold into the creation")
+	assert.Equal(t, TableCreated, updates[1][0].Type)
+	assert.Equal(t, 2, updates[1][0].Table["player_count"])
+
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: "later_p0"}))
+	require.NoError(t, manager.CloseTable(table.ID))
+	handler.lobby.Flush()
+	updates = lobbyUpdates(hub)
+	require.Len(t, updates, 3)
+	assert.Equal(t, []LobbyDelta{{Type: TableClosed, TableID: table.ID}}, updates[2])
+
+	response = handler.GetMessageHandlers()["lobby_unsubscribe"](context.Background(), conn, &WebSocketMessage{Type: "lobby_unsubscribe", RequestID: "r2"})
+	require.True(t, response.Success, response.Error)
+	assert.Empty(t, conn.rooms)
+}
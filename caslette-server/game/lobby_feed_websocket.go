@@ -0,0 +1,32 @@
+package game
+
+import (
+	"context"
+)
+
+// handleLobbySubscribe joins the caller to the lobby room and returns the
+// tables open now; lobby_update messages carry the changes from then on
+func (h *TableWebSocketHandler) handleLobbySubscribe(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	if err := conn.JoinRoom(LobbyRoomID); err != nil {
+		return h.errorResponse(msg.RequestID, "SUBSCRIBE_FAILED", err.Error())
+	}
+
+	tables := NewDataFilter().FilterTableList(h.tableManager.ListTables(nil), conn.GetUserID())
+	if tables == nil {
+		tables = make([]map[string]interface{}, 0)
+	}
+	return h.successResponse(msg.RequestID, "lobby_subscribed", map[string]interface{}{
+		"room_id": LobbyRoomID,
+		"tables":  tables,
+	})
+}
+
+// handleLobbyUnsubscribe leaves the lobby room
+func (h *TableWebSocketHandler) handleLobbyUnsubscribe(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	if err := conn.LeaveRoom(LobbyRoomID); err != nil {
+		return h.errorResponse(msg.RequestID, "UNSUBSCRIBE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "lobby_unsubscribed", map[string]interface{}{
+		"room_id": LobbyRoomID,
+	})
+}
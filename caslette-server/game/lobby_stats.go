@@ -0,0 +1,144 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultLobbyPotWindow is how many recent hands at a stake level its
+// average pot is taken over
+const DefaultLobbyPotWindow = 50
+
+// StakeLevelStats is the lobby view of the action at one stake level, so
+// players can see where games are running before picking a table
+type StakeLevelStats struct {
+	Stakes         string        `json:"stakes"` // "small/big"
+	SmallBlind     int           `json:"small_blind"`
+	BigBlind       int           `json:"big_blind"`
+	Currency       TableCurrency `json:"currency"`
+	TablesRunning  int           `json:"tables_running"`  // Tables with a game in play
+	AveragePlayers float64       `json:"average_players"` // Seated per running table
+	AveragePot     float64       `json:"average_pot"`     // Over the last DefaultLobbyPotWindow hands
+	Waitlist       int           `json:"waitlist"`        // Players queued for heads-up matches at these blinds
+}
+
+// stakeLevel identifies a stake level across tables
+type stakeLevel struct {
+	currency   TableCurrency
+	smallBlind int
+	bigBlind   int
+}
+
+// LobbyStats keeps the recent pots played at each stake level
+type LobbyStats struct {
+	mu     sync.Mutex
+	window int
+	pots   map[stakeLevel][]int // Most recent last
+}
+
+// NewLobbyStats creates a tracker averaging pots over DefaultLobbyPotWindow
+// hands
+func NewLobbyStats() *LobbyStats {
+	return &LobbyStats{
+		window: DefaultLobbyPotWindow,
+		pots:   make(map[stakeLevel][]int),
+	}
+}
+
+// RecordHand adds a finished hand's pot to its table's stake level
+func (ls *LobbyStats) RecordHand(table *GameTable, results []HandResult) {
+	pot := 0
+	for _, result := range results {
+		pot += result.Invested
+	}
+	level := tableStakeLevel(table)
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	pots := append(ls.pots[level], pot)
+	if len(pots) > ls.window {
+		pots = pots[len(pots)-ls.window:]
+	}
+	ls.pots[level] = pots
+}
+
+// averagePot returns the mean of the recent pots at a level
+func (ls *LobbyStats) averagePot(level stakeLevel) float64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	pots := ls.pots[level]
+	if len(pots) == 0 {
+		return 0
+	}
+	total := 0
+	for _, pot := range pots {
+		total += pot
+	}
+	return float64(total) / float64(len(pots))
+}
+
+// tableStakeLevel returns the stake level a table plays at
+func tableStakeLevel(table *GameTable) stakeLevel {
+	return stakeLevel{
+		currency:   table.GetCurrency(),
+		smallBlind: table.Settings.SmallBlind,
+		bigBlind:   table.Settings.BigBlind,
+	}
+}
+
+// LobbyStats returns the action at every stake level with a listed table or
+// a heads-up queue, lowest stakes first. Tables awaiting approval are left
+// out as they are from the lobby.
+func (tm *ActorTableManager) LobbyStats() []StakeLevelStats {
+	levels := make(map[stakeLevel]*StakeLevelStats)
+	seated := make(map[stakeLevel]int)
+	entry := func(level stakeLevel) *StakeLevelStats {
+		stats, ok := levels[level]
+		if !ok {
+			stats = &StakeLevelStats{
+				Stakes:     fmt.Sprintf("%d/%d", level.smallBlind, level.bigBlind),
+				SmallBlind: level.smallBlind,
+				BigBlind:   level.bigBlind,
+				Currency:   level.currency,
+				AveragePot: tm.lobbyStats.averagePot(level),
+			}
+			levels[level] = stats
+		}
+		return stats
+	}
+
+	for _, table := range tm.GetTables() {
+		if table.AwaitingApproval() {
+			continue
+		}
+		level := tableStakeLevel(table)
+		stats := entry(level)
+		if table.Status == TableStatusActive {
+			stats.TablesRunning++
+			seated[level] += table.GetPlayerCount()
+		}
+	}
+	for _, queue := range tm.HeadsUp().Queues() {
+		level := stakeLevel{currency: CurrencyDiamonds, smallBlind: queue.SmallBlind, bigBlind: queue.BigBlind}
+		entry(level).Waitlist += queue.Waiting
+	}
+
+	lobby := make([]StakeLevelStats, 0, len(levels))
+	for level, stats := range levels {
+		if stats.TablesRunning > 0 {
+			stats.AveragePlayers = float64(seated[level]) / float64(stats.TablesRunning)
+		}
+		lobby = append(lobby, *stats)
+	}
+	sort.Slice(lobby, func(i, j int) bool {
+		if lobby[i].BigBlind != lobby[j].BigBlind {
+			return lobby[i].BigBlind < lobby[j].BigBlind
+		}
+		if lobby[i].SmallBlind != lobby[j].SmallBlind {
+			return lobby[i].SmallBlind < lobby[j].SmallBlind
+		}
+		return lobby[i].Currency < lobby[j].Currency
+	})
+	return lobby
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLobbyTable opens a table at the default 10/20 blinds, running with the
+// given number of seated players when any
+func newLobbyTable(t *testing.T, manager *ActorTableManager, settings TableSettings, seated int) *GameTable {
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "lobby", GameType: GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: settings,
+	})
+	require.NoError(t, err)
+	for i := 0; i < seated; i++ {
+		table.PlayerSlots[i].PlayerID = string(rune('a' + i))
+	}
+	if seated > 0 {
+		table.Status = TableStatusActive
+	}
+	return table
+}
+
+func findStakeLevel(lobby []StakeLevelStats, stakes string, currency TableCurrency) *StakeLevelStats {
+	for i := range lobby {
+		if lobby[i].Stakes == stakes && lobby[i].Currency == currency {
+			return &lobby[i]
+		}
+	}
+	return nil
+}
+
+func TestLobbyStatsAggregatesByStakeLevel(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	settings := DefaultTableSettings()
+	running := newLobbyTable(t, manager, settings, 2)
+	newLobbyTable(t, manager, settings, 4)
+	newLobbyTable(t, manager, settings, 0)
+
+	practice := settings
+	practice.Currency = CurrencyPlayMoney
+	newLobbyTable(t, manager, practice, 3)
+
+	manager.lobbyStats.RecordHand(running, []HandResult{{Invested: 20}, {Invested: 40}})
+	manager.lobbyStats.RecordHand(running, []HandResult{{Invested: 10}, {Invested: 10}})
+
+	lobby := manager.LobbyStats()
+	diamonds := findStakeLevel(lobby, "10/20", CurrencyDiamonds)
+	require.NotNil(t, diamonds)
+	assert.Equal(t, 2, diamonds.TablesRunning, "tables without a game are listed but not running")
+	assert.Equal(t, 3.0, diamonds.AveragePlayers)
+	assert.Equal(t, 40.0, diamonds.AveragePot)
+
+	playMoney := findStakeLevel(lobby, "10/20", CurrencyPlayMoney)
+	require.NotNil(t, playMoney)
+	assert.Equal(t, 1, playMoney.TablesRunning)
+	assert.Zero(t, playMoney.AveragePot, "practice pots are kept apart")
+
+	// Heads-up stakes show up with their queues even before any table opens
+	headsUp := findStakeLevel(lobby, "1/2", CurrencyDiamonds)
+	require.NotNil(t, headsUp)
+	assert.Zero(t, headsUp.TablesRunning)
+	assert.Zero(t, headsUp.Waitlist)
+	assert.Equal(t, "1/2", lobby[0].Stakes, "lowest stakes come first")
+}
+
+func TestLobbyStatsAveragesRecentPots(t *testing.T) {
+	lobbyStats := NewLobbyStats()
+	lobbyStats.window = 2
+	table := &GameTable{Settings: DefaultTableSettings()}
+	for _, pot := range []int{100, 20, 40} {
+		lobbyStats.RecordHand(table, []HandResult{{Invested: pot}})
+	}
+	assert.Equal(t, 30.0, lobbyStats.averagePot(tableStakeLevel(table)), "only the last hands count")
+}
+
+func TestLobbyStatsCountsHeadsUpWaitlist(t *testing.T) {
+	manager, _, _ := newHeadsUpManager(t, "a")
+	match, err := manager.HeadsUp().Join(context.Background(), "a", "a", "1/2")
+	require.NoError(t, err)
+	require.Nil(t, match)
+
+	level := findStakeLevel(manager.LobbyStats(), "1/2", CurrencyDiamonds)
+	require.NotNil(t, level)
+	assert.Equal(t, 1, level.Waitlist)
+}
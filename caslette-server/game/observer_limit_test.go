@@ -0,0 +1,78 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// watch joins a table as an observer
+func watch(manager *ActorTableManager, tableID, playerID string) error {
+	return manager.JoinTable(context.Background(), &TableJoinRequest{
+		TableID: tableID, PlayerID: playerID, Username: playerID, Mode: JoinModeObserver,
+	})
+}
+
+func TestObserverLimitTurnsAwayExtraObservers(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	settings := DefaultTableSettings()
+	settings.MaxObservers = 2
+	table := newBalancingTable(t, manager, "watched", settings, 1)
+
+	require.NoError(t, watch(manager, table.ID, "watcher1"))
+	require.NoError(t, watch(manager, table.ID, "watcher2"))
+	assert.True(t, table.ObserversFull())
+	assert.False(t, table.CanJoinAsObserver("watcher3"))
+
+	err := watch(manager, table.ID, "watcher3")
+	require.Error(t, err)
+	assert.Equal(t, "OBSERVERS_FULL", err.(*TableError).Code)
+
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: "watcher1"}))
+	assert.False(t, table.IsObserver("watcher1"))
+	require.NoError(t, watch(manager, table.ID, "watcher3"), "a leaving observer frees a place")
+	assert.Equal(t, 2, table.GetTableInfo()["max_observers"])
+}
+
+func TestValidateTableSettingsChecksObserverLimit(t *testing.T) {
+	validator := NewTableValidator()
+	settings := DefaultTableSettings()
+
+	settings.MaxObservers = MaxObserverLimit
+	assert.NoError(t, validator.ValidateTableSettings(settings))
+	settings.MaxObservers = MaxObserverLimit + 1
+	assert.Error(t, validator.ValidateTableSettings(settings))
+	settings.MaxObservers = -1
+	assert.Error(t, validator.ValidateTableSettings(settings))
+}
+
+func TestGetObserversHandler(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	handler := NewTableWebSocketHandler(manager, &MockWebSocketHub{})
+	t.Cleanup(handler.events.Stop)
+	getObservers := func(playerID, tableID string) *WebSocketMessage {
+		return handler.GetMessageHandlers()["table_get_observers"](context.Background(), NewMockConnection(playerID, playerID),
+			&WebSocketMessage{Type: "table_get_observers", RequestID: "r1", Data: map[string]interface{}{"table_id": tableID}})
+	}
+
+	public := newBalancingTable(t, manager, "public", DefaultTableSettings(), 0)
+	require.NoError(t, watch(manager, public.ID, "watcher1"))
+	response := getObservers("stranger", public.ID)
+	require.True(t, response.Success, response.Error)
+	data := response.Data.(map[string]interface{})
+	observers := data["observers"].([]map[string]interface{})
+	require.Len(t, observers, 1)
+	assert.Equal(t, "watcher1", observers[0]["username"])
+	assert.NotContains(t, observers[0], "joined_at", "join times are for observers")
+	assert.Equal(t, 1, data["count"])
+
+	private := newBalancingTable(t, manager, "private", PrivateTableSettings("secret"), 0)
+	assert.False(t, getObservers("stranger", private.ID).Success)
+	response = getObservers(private.CreatedBy, private.ID)
+	require.True(t, response.Success, response.Error)
+	assert.Empty(t, response.Data.(map[string]interface{})["observers"])
+}
@@ -0,0 +1,27 @@
+package game
+
+// OmahaHoleCards is the number of hole cards each Omaha player is dealt
+const OmahaHoleCards = 4
+
+// OmahaEngine implements Omaha Hold'em. Betting, blinds and pots follow
+// Texas Hold'em; players get four hole cards and must make their hand from
+// exactly two of them and exactly three community cards.
+type OmahaEngine struct {
+	*TexasHoldemEngine
+}
+
+// NewOmahaEngine creates a new Omaha Hold'em game engine
+func NewOmahaEngine(gameID string) *OmahaEngine {
+	engine := NewTexasHoldemEngine(gameID)
+	engine.holeCardCount = OmahaHoleCards
+	engine.bestHand = engine.evaluator.FindBestOmahaHand
+	return &OmahaEngine{TexasHoldemEngine: engine}
+}
+
+// GetPublicGameState returns public game state, identifying the variant so
+// clients render four hole cards
+func (oe *OmahaEngine) GetPublicGameState() map[string]interface{} {
+	state := oe.TexasHoldemEngine.GetPublicGameState()
+	state["variant"] = GameTypeOmaha
+	return state
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOmahaDealsFourHoleCards(t *testing.T) {
+	engine := NewOmahaEngine("omaha-game")
+	for i, playerID := range []string{"1", "2", "3"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+	require.NoError(t, engine.Start())
+
+	for _, playerID := range []string{"1", "2", "3"} {
+		assert.Len(t, engine.getHoldemPlayer(playerID).Hand.Cards, OmahaHoleCards)
+	}
+	assert.Equal(t, GameTypeOmaha, engine.GetPublicGameState()["variant"])
+}
+
+func TestFindBestOmahaHandUsesExactlyTwoHoleCards(t *testing.T) {
+	evaluator := NewPokerEvaluator()
+
+	// Four hearts on board and one in hand: no flush, since Omaha needs two hole hearts
+	board := []Card{
+		NewCard(Hearts, Two), NewCard(Hearts, Seven), NewCard(Hearts, Nine), NewCard(Hearts, Jack), NewCard(Clubs, Four),
+	}
+	hole := []Card{NewCard(Hearts, Ace), NewCard(Spades, King), NewCard(Diamonds, Queen), NewCard(Clubs, Three)}
+
+	hand := evaluator.FindBestOmahaHand(hole, board)
+	assert.NotEqual(t, Flush, hand.Rank)
+	assert.Equal(t, Flush, evaluator.FindBestHand(append(append([]Card{}, hole...), board...)).Rank, "hold'em rules would allow the flush")
+
+	// Four of a kind in hand plays as a pair at most
+	quads := []Card{NewCard(Hearts, Ace), NewCard(Spades, Ace), NewCard(Diamonds, Ace), NewCard(Clubs, Ace)}
+	hand = evaluator.FindBestOmahaHand(quads, board)
+	assert.Equal(t, OnePair, hand.Rank)
+
+	// Two suited hole cards complete the flush
+	suited := []Card{NewCard(Hearts, Ace), NewCard(Hearts, King), NewCard(Spades, Two), NewCard(Clubs, Three)}
+	hand = evaluator.FindBestOmahaHand(suited, board)
+	assert.Equal(t, Flush, hand.Rank)
+}
+
+func TestOmahaShowdownAppliesMustUseTwo(t *testing.T) {
+	engine := NewOmahaEngine("omaha-showdown")
+	for i, playerID := range []string{"1", "2"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+
+	hands := map[string][]Card{
+		// One heart only: best Omaha hand is a pair of kings
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, King), NewCard(Diamonds, King), NewCard(Clubs, Three)},
+		// Two pair, jacks and nines
+		"2": {NewCard(Spades, Jack), NewCard(Diamonds, Nine), NewCard(Clubs, Five), NewCard(Clubs, Six)},
+	}
+	for playerID, cards := range hands {
+		holdemPlayer := engine.getHoldemPlayer(playerID)
+		holdemPlayer.Hand.Cards = cards
+		holdemPlayer.TotalBet = 100
+		holdemPlayer.Chips = 0
+		engine.pot += 100
+	}
+	engine.communityCards.Cards = []Card{
+		NewCard(Hearts, Two), NewCard(Hearts, Seven), NewCard(Hearts, Nine), NewCard(Hearts, Jack), NewCard(Clubs, Four),
+	}
+
+	require.NoError(t, engine.showdown())
+
+	require.Len(t, engine.winners, 1)
+	assert.Equal(t, "2", engine.winners[0].ID)
+	assert.Equal(t, 200, engine.getHoldemPlayer("2").Chips)
+}
+
+func TestEngineFactoryCreatesOmaha(t *testing.T) {
+	factory := &TexasHoldemEngineFactory{}
+	engine, err := factory.CreateEngine(GameTypeOmaha, DefaultTableSettings())
+	require.NoError(t, err)
+	_, ok := engine.(*OmahaEngine)
+	assert.True(t, ok)
+
+	assert.NoError(t, NewTableValidator().ValidateGameType(GameTypeOmaha))
+
+	manager := NewActorTableManager(factory)
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "Omaha Night", GameType: GameTypeOmaha, CreatedBy: "creator", Username: "creator", Settings: DefaultTableSettings(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, GameTypeOmaha, table.GameType)
+	_, ok = table.GameEngine.(*OmahaEngine)
+	assert.True(t, ok)
+}
@@ -0,0 +1,48 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTexasHoldemKeepsTypedPlayerState(t *testing.T) {
+	engine := NewTexasHoldemEngine("typed-state")
+	require.NoError(t, engine.AddPlayer(&Player{ID: "seeded", Name: "Seeded", Data: map[string]interface{}{"chips": 2500}}))
+	require.NoError(t, engine.AddPlayer(&Player{ID: "plain", Name: "Plain"}))
+
+	seeded := engine.getHoldemPlayer("seeded")
+	require.NotNil(t, seeded)
+	assert.Equal(t, 2500, seeded.Chips)
+	assert.Equal(t, engine.startingChips, engine.getHoldemPlayer("plain").Chips)
+
+	seeded.Chips = 900
+	assert.Same(t, seeded, engine.getHoldemPlayer("seeded"), "the engine hands out the state it holds")
+	assert.Equal(t, 900, engine.getHoldemPlayer("seeded").Chips)
+
+	require.NoError(t, engine.RemovePlayer("plain"))
+	assert.Nil(t, engine.getHoldemPlayer("plain"))
+	assert.Nil(t, engine.PlayerState("plain"))
+}
+
+func TestTexasHoldemGameStateHidesHoleCards(t *testing.T) {
+	engine := newButtonTable(t, 3)
+	require.NoError(t, engine.Start())
+	require.Len(t, engine.getHoldemPlayer("a").Hand.Cards, 2)
+
+	state := engine.GetGameState()
+	players, ok := state["players"].([]holdemPlayerView)
+	require.True(t, ok)
+	require.Len(t, players, 3)
+	for i, player := range players {
+		assert.Equal(t, i, player.Position)
+		assert.Equal(t, engine.getHoldemPlayer(player.ID).Chips, player.Chips)
+	}
+
+	encoded, err := json.Marshal(state)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), `"hand"`)
+	assert.Contains(t, string(encoded), `"chips"`)
+}
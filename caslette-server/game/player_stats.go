@@ -0,0 +1,113 @@
+package game
+
+import (
+	"log"
+)
+
+// PlayerStats are a player's lifetime results at tables of one currency, so
+// practice hands never mix with real-money ones
+type PlayerStats struct {
+	PlayerID      string        `json:"player_id"`
+	Currency      TableCurrency `json:"currency"`
+	HandsPlayed   int64         `json:"hands_played"`
+	VPIPHands     int64         `json:"vpip_hands"` // Hands with chips put in voluntarily before the flop
+	PFRHands      int64         `json:"pfr_hands"`  // Hands raised before the flop
+	Showdowns     int64         `json:"showdowns"`
+	ShowdownsWon  int64         `json:"showdowns_won"` // Including split pots
+	TotalWinnings int64         `json:"total_winnings"`
+	BiggestPot    int64         `json:"biggest_pot"` // Most chips collected in one hand
+	VPIP          float64       `json:"vpip"`        // Percent of hands played
+	PFR           float64       `json:"pfr"`         // Percent of hands played
+}
+
+// WithRates returns the stats with VPIP and PFR worked out from the counts
+func (s PlayerStats) WithRates() PlayerStats {
+	if s.HandsPlayed > 0 {
+		s.VPIP = float64(s.VPIPHands) * 100 / float64(s.HandsPlayed)
+		s.PFR = float64(s.PFRHands) * 100 / float64(s.HandsPlayed)
+	}
+	return s
+}
+
+// PlayerStatsStore accumulates players' lifetime stats
+type PlayerStatsStore interface {
+	// AddPlayerStats adds one hand's stats to each player's totals, keeping
+	// the larger biggest pot
+	AddPlayerStats(stats []PlayerStats) error
+}
+
+// HandPlayerStats works out what a completed hand adds to the lifetime
+// stats of each player dealt into it
+func HandPlayerStats(record HandRecord, currency TableCurrency) []PlayerStats {
+	stats := make(map[string]*PlayerStats, len(record.Players))
+	ordered := make([]*PlayerStats, 0, len(record.Players))
+	for _, player := range record.Players {
+		entry := &PlayerStats{PlayerID: player.PlayerID, Currency: currency, HandsPlayed: 1}
+		stats[player.PlayerID] = entry
+		ordered = append(ordered, entry)
+	}
+
+	// A preflop action raises when it leaves the player with more in than
+	// anyone had before it; posting blinds and antes is never voluntary
+	committed := make(map[string]int, len(record.Players))
+	highest := 0
+	for _, action := range record.Actions {
+		if action.Street != PreFlop {
+			break
+		}
+		committed[action.PlayerID] += action.Amount
+		entry := stats[action.PlayerID]
+		if action.Action != HandActionPost && action.Amount > 0 && entry != nil {
+			entry.VPIPHands = 1
+			if committed[action.PlayerID] > highest {
+				entry.PFRHands = 1
+			}
+		}
+		if committed[action.PlayerID] > highest {
+			highest = committed[action.PlayerID]
+		}
+	}
+
+	for _, result := range record.Results {
+		entry := stats[result.PlayerID]
+		if entry == nil {
+			continue
+		}
+		entry.TotalWinnings = int64(result.Net)
+		if result.Showdown != "" {
+			entry.Showdowns = 1
+			if result.Showdown != ShowdownLost {
+				entry.ShowdownsWon = 1
+			}
+		}
+		if result.Net > 0 {
+			entry.BiggestPot = int64(result.Collected)
+		}
+	}
+
+	hand := make([]PlayerStats, 0, len(ordered))
+	for _, entry := range ordered {
+		hand = append(hand, *entry)
+	}
+	return hand
+}
+
+// SetPlayerStatsStore sets where players' lifetime stats are accumulated
+func (tm *ActorTableManager) SetPlayerStatsStore(store PlayerStatsStore) {
+	tm.mu.Lock()
+	tm.playerStats = store
+	tm.mu.Unlock()
+}
+
+// recordPlayerStats adds a completed hand to its players' lifetime stats
+func (tm *ActorTableManager) recordPlayerStats(table *GameTable, record HandRecord) {
+	tm.mu.RLock()
+	store := tm.playerStats
+	tm.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	if err := store.AddPlayerStats(HandPlayerStats(record, table.GetCurrency())); err != nil {
+		log.Printf("Table %s: failed to record player stats for hand %d: %v", table.ID, record.HandNumber, err)
+	}
+}
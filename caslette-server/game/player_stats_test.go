@@ -0,0 +1,101 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type addedStats struct {
+	stats []PlayerStats
+}
+
+func (s *addedStats) AddPlayerStats(stats []PlayerStats) error {
+	s.stats = append(s.stats, stats...)
+	return nil
+}
+
+func statsFor(stats []PlayerStats, playerID string) PlayerStats {
+	for _, entry := range stats {
+		if entry.PlayerID == playerID {
+			return entry
+		}
+	}
+	return PlayerStats{}
+}
+
+func TestHandPlayerStatsCountsPreflopActionAndShowdown(t *testing.T) {
+	engine := newRiverTable(t)
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	record := engine.CompletedHand()
+	require.NotNil(t, record)
+
+	stats := HandPlayerStats(*record, CurrencyDiamonds)
+	require.Len(t, stats, 2)
+	winner, loser := statsFor(stats, "1"), statsFor(stats, "2")
+	for _, entry := range stats {
+		assert.Equal(t, int64(1), entry.HandsPlayed)
+		assert.Equal(t, int64(1), entry.Showdowns)
+		assert.Equal(t, CurrencyDiamonds, entry.Currency)
+	}
+	assert.Equal(t, int64(1), winner.ShowdownsWon)
+	assert.Zero(t, loser.ShowdownsWon)
+	assert.Equal(t, int64(10), winner.TotalWinnings)
+	assert.Equal(t, int64(-10), loser.TotalWinnings)
+	assert.Equal(t, int64(20), winner.BiggestPot)
+	assert.Zero(t, loser.BiggestPot)
+
+	// The small blind completed and the big blind checked: only the call is
+	// voluntary and nobody raised
+	assert.Equal(t, int64(1), statsFor(stats, record.Actions[0].PlayerID).VPIPHands)
+	assert.Zero(t, statsFor(stats, record.Actions[1].PlayerID).VPIPHands)
+	assert.Zero(t, winner.PFRHands+loser.PFRHands)
+}
+
+func TestHandPlayerStatsCountsPreflopRaise(t *testing.T) {
+	record := HandRecord{
+		Players: []HandPlayer{{PlayerID: "1"}, {PlayerID: "2"}, {PlayerID: "3"}},
+		Actions: []HandAction{
+			{Street: PreFlop, PlayerID: "1", Action: HandActionPost, Amount: 5},
+			{Street: PreFlop, PlayerID: "2", Action: HandActionPost, Amount: 10},
+			{Street: PreFlop, PlayerID: "3", Action: string(ActionRaise), Amount: 30},
+			{Street: PreFlop, PlayerID: "1", Action: string(ActionFold)},
+			{Street: PreFlop, PlayerID: "2", Action: string(ActionCall), Amount: 20},
+			{Street: Flop, PlayerID: "2", Action: string(ActionBet), Amount: 40},
+		},
+		Results: []HandResult{{PlayerID: "3", Net: -30}, {PlayerID: "2", Collected: 95, Net: 55}, {PlayerID: "1", Net: -5}},
+	}
+
+	stats := HandPlayerStats(record, CurrencyPlayMoney)
+	assert.Equal(t, int64(1), statsFor(stats, "3").PFRHands)
+	assert.Equal(t, int64(1), statsFor(stats, "3").VPIPHands)
+	assert.Equal(t, int64(1), statsFor(stats, "2").VPIPHands)
+	assert.Zero(t, statsFor(stats, "2").PFRHands, "a flop bet is not a preflop raise")
+	assert.Zero(t, statsFor(stats, "1").VPIPHands)
+	assert.Equal(t, int64(95), statsFor(stats, "2").BiggestPot)
+	assert.Zero(t, statsFor(stats, "2").Showdowns)
+}
+
+func TestPlayerStatsWithRates(t *testing.T) {
+	stats := PlayerStats{HandsPlayed: 8, VPIPHands: 2, PFRHands: 1}.WithRates()
+	assert.Equal(t, 25.0, stats.VPIP)
+	assert.Equal(t, 12.5, stats.PFR)
+	assert.Zero(t, PlayerStats{}.WithRates().VPIP)
+}
+
+func TestManagerRecordsPlayerStatsByCurrency(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	store := &addedStats{}
+	manager.SetPlayerStatsStore(store)
+
+	engine := newRiverTable(t)
+	table := &GameTable{ID: "stats-table", GameEngine: engine, Settings: TableSettings{Currency: CurrencyPlayMoney}}
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	manager.recordHandHistory(table)
+
+	require.Len(t, store.stats, 2)
+	assert.Equal(t, CurrencyPlayMoney, store.stats[0].Currency)
+}
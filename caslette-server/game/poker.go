@@ -172,6 +172,27 @@ func (pe *PokerEvaluator) FindBestHand(cards []Card) *PokerHand {
 	return bestHand
 }
 
+// FindBestOmahaHand finds the best hand using exactly two hole cards and
+// exactly three board cards, as Omaha requires
+func (pe *PokerEvaluator) FindBestOmahaHand(holeCards, board []Card) *PokerHand {
+	if len(holeCards) < 2 || len(board) < 3 {
+		cards := append(append([]Card{}, holeCards...), board...)
+		return &PokerHand{Rank: HighCard, Cards: cards}
+	}
+
+	var bestHand *PokerHand
+	pe.generateCombinations(holeCards, 2, 0, []Card{}, func(hole []Card) {
+		pe.generateCombinations(board, 3, 0, []Card{}, func(common []Card) {
+			hand := pe.EvaluateHand(append(hole, common...))
+			if bestHand == nil || hand.Compare(bestHand) > 0 {
+				bestHand = hand
+			}
+		})
+	})
+
+	return bestHand
+}
+
 // generateCombinations generates all combinations of k cards from the given cards
 func (pe *PokerEvaluator) generateCombinations(cards []Card, k, start int, current []Card, callback func([]Card)) {
 	if len(current) == k {
@@ -219,7 +240,7 @@ func (pe *PokerEvaluator) checkStraightFlush(cards []Card) *PokerHand {
 	return &PokerHand{
 		Rank:      StraightFlush,
 		Cards:     cards,
-		HighCards: []Rank{cards[0].Rank},
+		HighCards: []Rank{pe.straightHighCard(cards)},
 		Kickers:   []Rank{},
 	}
 }
@@ -305,7 +326,7 @@ func (pe *PokerEvaluator) checkStraight(cards []Card) *PokerHand {
 	return &PokerHand{
 		Rank:      Straight,
 		Cards:     cards,
-		HighCards: []Rank{cards[0].Rank},
+		HighCards: []Rank{pe.straightHighCard(cards)},
 		Kickers:   []Rank{},
 	}
 }
@@ -457,6 +478,15 @@ func (pe *PokerEvaluator) isStraight(cards []Card) bool {
 	return true
 }
 
+// straightHighCard returns the top card of a sorted straight; the ace plays
+// low in a wheel, so A-2-3-4-5 is five high
+func (pe *PokerEvaluator) straightHighCard(cards []Card) Rank {
+	if cards[0].Rank == Ace && cards[1].Rank == Five {
+		return Five
+	}
+	return cards[0].Rank
+}
+
 func (pe *PokerEvaluator) getRankCounts(cards []Card) map[Rank]int {
 	counts := make(map[Rank]int)
 	for _, card := range cards {
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPracticeTableCurrency(t *testing.T) {
+	practice := NewGameTable("p1", "Practice", GameTypeTexasHoldem, "creator", PracticeTableSettings())
+	assert.True(t, practice.IsPractice())
+	assert.False(t, practice.UsesDiamondLedger())
+	assert.Equal(t, CurrencyPlayMoney, practice.GetTableInfo()["currency"])
+
+	real := NewGameTable("r1", "Real", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+	assert.False(t, real.IsPractice())
+	assert.True(t, real.UsesDiamondLedger())
+	assert.Equal(t, CurrencyDiamonds, real.GetCurrency())
+}
+
+func TestValidateTableSettingsCurrency(t *testing.T) {
+	validator := NewTableValidator()
+	assert.NoError(t, validator.ValidateTableSettings(PracticeTableSettings()))
+
+	settings := DefaultTableSettings()
+	settings.Currency = "gold"
+	assert.Error(t, validator.ValidateTableSettings(settings))
+
+	settings = TournamentSettings()
+	settings.Currency = CurrencyPlayMoney
+	assert.Error(t, validator.ValidateTableSettings(settings))
+}
+
+func TestPracticeTablesSegregatedInListingAndStats(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	ctx := context.Background()
+
+	practice, err := manager.CreateTable(ctx, &TableCreateRequest{
+		Name: "Practice", GameType: GameTypeTexasHoldem, CreatedBy: "creator1", Username: "creator1", Settings: PracticeTableSettings(),
+	})
+	require.NoError(t, err)
+	_, err = manager.CreateTable(ctx, &TableCreateRequest{
+		Name: "Real", GameType: GameTypeTexasHoldem, CreatedBy: "creator2", Username: "creator2", Settings: DefaultTableSettings(),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.JoinTable(ctx, &TableJoinRequest{
+		TableID: practice.ID, PlayerID: "learner", Username: "learner", Mode: JoinModePlayer,
+	}))
+
+	practiceTables := manager.ListTables(map[string]interface{}{"practice": true})
+	require.Len(t, practiceTables, 1)
+	assert.Equal(t, practice.ID, practiceTables[0].ID)
+	assert.Len(t, manager.ListTables(map[string]interface{}{"currency": "diamonds"}), 1)
+
+	stats := manager.GetStats()
+	assert.Equal(t, 1, stats["total_tables"])
+	assert.Equal(t, 0, stats["total_players"])
+	practiceStats := stats["practice"].(map[string]interface{})
+	assert.Equal(t, 1, practiceStats["total_tables"])
+	assert.Equal(t, 1, practiceStats["total_players"])
+}
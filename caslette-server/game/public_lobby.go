@@ -0,0 +1,136 @@
+package game
+
+import (
+	"sort"
+	"time"
+)
+
+// PublicTable is what the public lobby shows of a table to visitors who are
+// not signed in: no players, creator or settings beyond the stakes
+type PublicTable struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	GameType   GameType      `json:"game_type"`
+	Status     TableStatus   `json:"status"`
+	Currency   TableCurrency `json:"currency"`
+	SmallBlind int           `json:"small_blind"`
+	BigBlind   int           `json:"big_blind"`
+	Players    int           `json:"players"`
+	MaxPlayers int           `json:"max_players"`
+	Observers  int           `json:"observers"`
+	Locked     bool          `json:"locked"` // A password is needed to join
+	Tags       []string      `json:"tags,omitempty"`
+
+	// Featured tables are pinned first, in the branding an admin gave them
+	Featured bool              `json:"featured,omitempty"`
+	Branding map[string]string `json:"branding,omitempty"`
+}
+
+// PublicTournament is what the public lobby shows of a tournament or Sit&Go
+// that is registering or running
+type PublicTournament struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Kind      string           `json:"kind"` // tournament or sit_and_go
+	GameType  GameType         `json:"game_type"`
+	Status    TournamentStatus `json:"status"`
+	Entrants  int              `json:"entrants"`
+	Seats     int              `json:"seats,omitempty"` // Sit&Go seats; tournaments have no cap
+	EntryFee  int              `json:"entry_fee"`
+	PrizePool int              `json:"prize_pool"`
+}
+
+// PublicTables lists the tables anyone may see in the lobby, featured ones
+// first and then the busiest. Private tables, closed tables and tables
+// awaiting approval are left out.
+func (tm *ActorTableManager) PublicTables() []PublicTable {
+	tables := make([]PublicTable, 0)
+	pinned := make(map[string]time.Time)
+	for _, table := range tm.GetTables() {
+		if table.Settings.Private || table.Status == TableStatusClosed || table.AwaitingApproval() {
+			continue
+		}
+		public := PublicTable{
+			ID:         table.ID,
+			Name:       table.Name,
+			GameType:   table.GameType,
+			Status:     table.Status,
+			Currency:   table.GetCurrency(),
+			SmallBlind: table.Settings.SmallBlind,
+			BigBlind:   table.Settings.BigBlind,
+			Players:    table.GetPlayerCount(),
+			MaxPlayers: table.MaxPlayers,
+			Observers:  len(table.Observers),
+			Locked:     table.HasPassword(),
+			Tags:       table.Tags,
+		}
+		if table.IsFeatured() {
+			public.Featured, public.Branding = true, table.Feature.Branding
+			pinned[table.ID] = table.Feature.FeaturedAt
+		}
+		tables = append(tables, public)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Featured != tables[j].Featured {
+			return tables[i].Featured
+		}
+		if tables[i].Featured && !pinned[tables[i].ID].Equal(pinned[tables[j].ID]) {
+			return pinned[tables[i].ID].Before(pinned[tables[j].ID])
+		}
+		if tables[i].Players != tables[j].Players {
+			return tables[i].Players > tables[j].Players
+		}
+		return tables[i].ID < tables[j].ID
+	})
+	return tables
+}
+
+// PublicTournaments lists the tournaments and Sit&Gos registering or
+// running, running ones first
+func (h *TableWebSocketHandler) PublicTournaments() []PublicTournament {
+	h.tournamentsMu.RLock()
+	tournaments := make([]*Tournament, 0, len(h.tournaments))
+	for _, tournament := range h.tournaments {
+		tournaments = append(tournaments, tournament)
+	}
+	h.tournamentsMu.RUnlock()
+
+	public := make([]PublicTournament, 0)
+	for _, tournament := range tournaments {
+		if status := tournament.Status(); status != TournamentFinished {
+			public = append(public, PublicTournament{
+				ID:        tournament.ID(),
+				Name:      tournament.config.Name,
+				Kind:      "tournament",
+				GameType:  tournament.config.GameType,
+				Status:    status,
+				Entrants:  len(tournament.Standings()),
+				EntryFee:  tournament.config.EntryFee,
+				PrizePool: tournament.PrizePool(),
+			})
+		}
+	}
+	for _, sitAndGo := range h.tableManager.ListSitAndGos() {
+		if sitAndGo.Status != TournamentFinished {
+			public = append(public, PublicTournament{
+				ID:        sitAndGo.ID,
+				Name:      sitAndGo.Name,
+				Kind:      "sit_and_go",
+				GameType:  sitAndGo.GameType,
+				Status:    sitAndGo.Status,
+				Entrants:  sitAndGo.Registered,
+				Seats:     sitAndGo.Seats,
+				EntryFee:  sitAndGo.EntryFee,
+				PrizePool: sitAndGo.PrizePool,
+			})
+		}
+	}
+
+	sort.SliceStable(public, func(i, j int) bool {
+		if running := public[i].Status == TournamentRunning; running != (public[j].Status == TournamentRunning) {
+			return running
+		}
+		return public[i].ID < public[j].ID
+	})
+	return public
+}
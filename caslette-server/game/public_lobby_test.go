@@ -0,0 +1,33 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicTablesHideWhatVisitorsMayNotSee(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	settings := DefaultTableSettings()
+	busy := newLobbyTable(t, manager, settings, 3)
+
+	locked := settings
+	locked.Password = "secret"
+	passworded := newLobbyTable(t, manager, locked, 0)
+
+	private := settings
+	private.Private = true
+	newLobbyTable(t, manager, private, 2)
+	newLobbyTable(t, manager, settings, 2).Status = TableStatusClosed
+	newLobbyTable(t, manager, settings, 2).Approval = &TableApproval{Status: ApprovalPending}
+
+	tables := manager.PublicTables()
+	require.Len(t, tables, 2)
+	assert.Equal(t, []string{busy.ID, passworded.ID}, []string{tables[0].ID, tables[1].ID}, "busiest first")
+	assert.Equal(t, 3, tables[0].Players)
+	assert.Equal(t, 20, tables[0].BigBlind)
+	assert.False(t, tables[0].Locked)
+	assert.True(t, tables[1].Locked, "the password itself is never shown")
+}
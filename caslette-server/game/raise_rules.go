@@ -0,0 +1,97 @@
+package game
+
+import "fmt"
+
+// RaiseRange is the legal size of a bet or raise for the player to act,
+// counted in chips on top of the bet to call. Amounts above Max are taken as
+// all in.
+type RaiseRange struct {
+	Action string `json:"action"` // "bet" when nobody has bet this round, otherwise "raise"
+	Min    int    `json:"min"`
+	Max    int    `json:"max"` // The player's whole stack after calling
+}
+
+// resetRaiseRules starts a betting round: the first bet must be at least the
+// big blind and nobody has acted yet
+func (the *TexasHoldemEngine) resetRaiseRules() {
+	the.lastRaise = the.bigBlind
+	the.fullRaises = 0
+	the.actedAtRaise = make(map[string]int)
+}
+
+// recordRaise notes that the bet to call went up from previousBet. Only a
+// raise of at least the previous raise size is a full raise that sets the new
+// minimum and reopens the betting; a short all-in does neither.
+func (the *TexasHoldemEngine) recordRaise(previousBet int) {
+	increment := the.currentBet - previousBet
+	if increment >= the.lastRaise {
+		the.lastRaise = increment
+		the.fullRaises++
+	}
+}
+
+// markActed remembers how many full raises the player had seen when they acted
+func (the *TexasHoldemEngine) markActed(playerID string) {
+	if the.actedAtRaise == nil {
+		the.actedAtRaise = make(map[string]int)
+	}
+	the.actedAtRaise[playerID] = the.fullRaises
+}
+
+// raiseReopened reports whether the player may raise: they have not acted
+// this round, or someone made a full raise since they did
+func (the *TexasHoldemEngine) raiseReopened(playerID string) bool {
+	acted, ok := the.actedAtRaise[playerID]
+	return !ok || acted < the.fullRaises
+}
+
+// raiseRange works out the legal bet or raise for a player; a player whose
+// stack falls short of the minimum may still put it all in
+func (the *TexasHoldemEngine) raiseRange(player *TexasHoldemPlayer) RaiseRange {
+	if the.variant != nil {
+		return the.variant.betLimits(player)
+	}
+	callAmount := the.currentBet - player.CurrentBet
+	remaining := max(player.Chips-callAmount, 0)
+	if the.currentBet == 0 {
+		return RaiseRange{Action: string(ActionBet), Min: min(the.bigBlind, remaining), Max: remaining}
+	}
+	return RaiseRange{Action: string(ActionRaise), Min: min(the.lastRaise, remaining), Max: remaining}
+}
+
+// validateRaise checks a bet or raise amount against the no-limit rules, or
+// the variant's limits
+func (the *TexasHoldemEngine) validateRaise(player *TexasHoldemPlayer, amount float64) error {
+	if !the.raiseReopened(player.ID) {
+		return fmt.Errorf("betting was not reopened by a short all-in; you may only call or fold")
+	}
+	if the.variant != nil {
+		if err := the.variant.checkBetSize(player, amount); err != nil {
+			return err
+		}
+	}
+	legal := the.raiseRange(player)
+	if legal.Max <= 0 {
+		return fmt.Errorf("not enough chips to %s; call instead", legal.Action)
+	}
+	if amount < float64(legal.Min) {
+		return fmt.Errorf("minimum %s is %d (legal %ss are %d to %d, all in)", legal.Action, legal.Min, legal.Action, legal.Min, legal.Max)
+	}
+	return nil
+}
+
+// GetRaiseRange returns the legal bet or raise for the player to act
+func (the *TexasHoldemEngine) GetRaiseRange(playerID string) (*RaiseRange, error) {
+	player := the.getHoldemPlayer(playerID)
+	if player == nil {
+		return nil, fmt.Errorf("player not found")
+	}
+	if the.getCurrentActionPlayerID() != playerID {
+		return nil, fmt.Errorf("not player's turn")
+	}
+	if err := the.validateRaise(player, float64(the.raiseRange(player).Min)); err != nil {
+		return nil, err
+	}
+	legal := the.raiseRange(player)
+	return &legal, nil
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// act sends an action for whoever is to act; amount is left out when zero
+func act(engine *TexasHoldemEngine, action TexasHoldemAction, amount int) error {
+	data := map[string]interface{}{"action": string(action)}
+	if amount > 0 {
+		data["amount"] = amount
+	}
+	_, err := engine.ProcessAction(context.Background(), &GameAction{
+		PlayerID: engine.getCurrentActionPlayerID(),
+		Type:     "poker_action",
+		Data:     data,
+	})
+	return err
+}
+
+// newStackTable seats players a, b, c... in seats 0, 1, 2... with the given stacks
+func newStackTable(t *testing.T, stacks ...int) *TexasHoldemEngine {
+	engine := newButtonTable(t, len(stacks))
+	for i, chips := range stacks {
+		engine.getHoldemPlayer(string(rune('a' + i))).Chips = chips
+	}
+	return engine
+}
+
+func TestRaiseMustMatchPreviousRaise(t *testing.T) {
+	engine := newButtonTable(t, 3)
+	require.NoError(t, engine.Start())
+	require.Equal(t, "a", engine.getCurrentActionPlayerID())
+
+	legal, err := engine.GetRaiseRange("a")
+	require.NoError(t, err)
+	assert.Equal(t, RaiseRange{Action: "raise", Min: 10, Max: 990}, *legal)
+
+	err = act(engine, ActionRaise, 5)
+	require.Error(t, err)
+	assert.Equal(t, "minimum raise is 10 (legal raises are 10 to 990, all in)", err.Error())
+	require.NoError(t, act(engine, ActionRaise, 10))
+	assert.Equal(t, 20, engine.currentBet)
+
+	require.NoError(t, act(engine, ActionRaise, 30), "b raises to 50")
+	err = act(engine, ActionRaise, 20)
+	require.Error(t, err, "the minimum is now the 30 raise")
+	assert.Contains(t, err.Error(), "minimum raise is 30")
+	require.NoError(t, act(engine, ActionRaise, 5000), "amounts above the stack are all in")
+	assert.True(t, engine.getHoldemPlayer("c").IsAllIn)
+}
+
+func TestFirstBetMustBeBigBlind(t *testing.T) {
+	engine := newButtonTable(t, 2)
+	require.NoError(t, engine.Start())
+	require.NoError(t, act(engine, ActionCall, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.Equal(t, Flop, engine.roundState)
+
+	err := act(engine, ActionBet, 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "minimum bet is 10")
+	assert.Error(t, act(engine, ActionRaise, 10), "there is nothing to raise")
+	require.NoError(t, act(engine, ActionBet, 10))
+}
+
+func TestShortAllInDoesNotReopenBetting(t *testing.T) {
+	engine := newStackTable(t, 1000, 1000, 70)
+	require.NoError(t, engine.Start())
+
+	require.NoError(t, act(engine, ActionRaise, 40), "a raises to 50")
+	require.NoError(t, act(engine, ActionCall, 0))
+	require.NoError(t, act(engine, ActionAllIn, 0), "c goes all in for 70, 20 short of a full raise")
+	assert.Equal(t, 70, engine.currentBet)
+	assert.Equal(t, 40, engine.lastRaise, "a short all-in does not change the minimum")
+
+	require.Equal(t, "a", engine.getCurrentActionPlayerID())
+	assert.NotContains(t, engine.GetValidActions("a"), string(ActionRaise))
+	_, err := engine.GetRaiseRange("a")
+	assert.Error(t, err)
+	err = act(engine, ActionRaise, 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not reopened")
+	require.NoError(t, act(engine, ActionCall, 0))
+	assert.NotContains(t, engine.GetValidActions("b"), string(ActionRaise))
+}
+
+func TestFullAllInReopensBetting(t *testing.T) {
+	engine := newStackTable(t, 1000, 1000, 90)
+	require.NoError(t, engine.Start())
+
+	require.NoError(t, act(engine, ActionRaise, 40))
+	require.NoError(t, act(engine, ActionCall, 0))
+	require.NoError(t, act(engine, ActionAllIn, 0), "c's all in for 90 is a full 40 raise")
+
+	assert.Contains(t, engine.GetValidActions("a"), string(ActionRaise))
+	legal, err := engine.GetRaiseRange("a")
+	require.NoError(t, err)
+	assert.Equal(t, 40, legal.Min)
+	require.NoError(t, act(engine, ActionRaise, 40))
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"fmt"
+	"log"
+)
+
+// RakeStore writes the rake taken from each hand to the rake ledger
+type RakeStore interface {
+	RecordRake(tableID, handID, stakeLevel string, amount int) error
+}
+
+// SetRake sets the share of each pot the house takes and the most it takes
+// from one hand, zero for no cap. A zero percent takes no rake.
+func (the *TexasHoldemEngine) SetRake(percent float64, maxRake int) {
+	the.rakePercent = max(percent, 0)
+	the.maxRake = max(maxRake, 0)
+}
+
+// takeRake works out the house's rake for the hand and removes it from the
+// pots, main pot first, before they are awarded. Hands that end before the
+// flop are not raked (no flop, no drop), and neither is a bet nobody called.
+func (the *TexasHoldemEngine) takeRake(pots []Pot, contributions []potContribution) int {
+	if the.rakePercent <= 0 || !the.sawFlop() {
+		return 0
+	}
+
+	// The part of the largest contribution nobody matched goes back to its bettor
+	highest, called := 0, 0
+	for _, contribution := range contributions {
+		switch {
+		case contribution.Amount > highest:
+			highest, called = contribution.Amount, highest
+		case contribution.Amount > called:
+			called = contribution.Amount
+		}
+	}
+	raked := the.pot - (highest - called)
+
+	rake := int(float64(raked) * the.rakePercent / 100)
+	if the.maxRake > 0 {
+		rake = min(rake, the.maxRake)
+	}
+
+	remaining := rake
+	for i := range pots {
+		if remaining == 0 {
+			break
+		}
+		taken := min(remaining, pots[i].Amount)
+		pots[i].Amount -= taken
+		pots[i].Rake = taken
+		remaining -= taken
+	}
+	return rake - remaining
+}
+
+// sawFlop reports whether the hand got past its first betting round, the
+// flop in hold'em
+func (the *TexasHoldemEngine) sawFlop() bool {
+	if the.variant != nil {
+		return the.variant.pastFirstStreet()
+	}
+	return len(the.communityCards.Cards) >= 3
+}
+
+// raked reports whether tables with these settings pay rake. Practice and
+// tournament tables never do.
+func (s TableSettings) raked() bool {
+	return s.Currency != CurrencyPlayMoney && !s.TournamentMode
+}
+
+// tableRake returns the rake an engine for a table takes
+func tableRake(settings TableSettings) (float64, int) {
+	if !settings.raked() {
+		return 0, 0
+	}
+	return settings.RakePercent, settings.MaxRake
+}
+
+// withDefaultRake gives cash tables that set no rake the operator default
+func withDefaultRake(settings TableSettings) TableSettings {
+	if settings.raked() && settings.RakePercent == 0 {
+		defaults := CurrentGameDefaults()
+		settings.RakePercent = defaults.RakePercent
+		if settings.MaxRake == 0 {
+			settings.MaxRake = defaults.MaxRake
+		}
+	}
+	return settings
+}
+
+// SetHouseAccount sets the player ID whose diamond account rake is credited
+// to. Without one, or without a diamond ledger, rake leaves play uncredited.
+func (tm *ActorTableManager) SetHouseAccount(playerID string) {
+	tm.mu.Lock()
+	tm.houseAccount = playerID
+	tm.mu.Unlock()
+}
+
+// SetRakeStore sets where the rake taken from each hand is recorded
+func (tm *ActorTableManager) SetRakeStore(store RakeStore) {
+	tm.mu.Lock()
+	tm.rakeStore = store
+	tm.mu.Unlock()
+}
+
+// collectRake takes a completed hand's rake out of the table's escrow,
+// records it in the rake ledger and credits it to the house account
+func (tm *ActorTableManager) collectRake(table *GameTable, record HandRecord) {
+	rake, handNumber := record.Rake, record.HandNumber
+	if rake <= 0 || !table.UsesDiamondLedger() {
+		return
+	}
+	tm.escrow.Withdraw(table.ID, int64(rake))
+
+	tm.mu.RLock()
+	ledger, house, store := tm.ledger, tm.houseAccount, tm.rakeStore
+	tm.mu.RUnlock()
+	if store != nil {
+		stakeLevel := fmt.Sprintf("%d/%d", record.SmallBlind, record.BigBlind)
+		if err := store.RecordRake(table.ID, record.HandID, stakeLevel, rake); err != nil {
+			log.Printf("Table %s: failed to record %d rake from hand %d: %v", table.ID, rake, handNumber, err)
+		}
+	}
+	if ledger == nil || house == "" {
+		log.Printf("Table %s: no house account to credit %d rake from hand %d", table.ID, rake, handNumber)
+		return
+	}
+	description := fmt.Sprintf("Rake: table %s hand %d", table.ID, handNumber)
+	if err := ledger.Credit(house, rake, description); err != nil {
+		log.Printf("Table %s: failed to credit %d rake from hand %d: %v", table.ID, rake, handNumber, err)
+	}
+}
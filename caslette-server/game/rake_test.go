@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRake is a RakeStore keeping ledger entries in memory
+type recordedRake struct {
+	entries []string
+}
+
+func (r *recordedRake) RecordRake(tableID, handID, stakeLevel string, amount int) error {
+	r.entries = append(r.entries, fmt.Sprintf("%s %s %s %d", tableID, handID, stakeLevel, amount))
+	return nil
+}
+
+func potDistributedEvent(t *testing.T, engine *TexasHoldemEngine) *GameEvent {
+	for _, event := range engine.GetEvents() {
+		if event.Type == "pot_distributed" {
+			return event
+		}
+	}
+	require.Fail(t, "no pot_distributed event")
+	return nil
+}
+
+func TestTexasHoldemTakesRakeFromMainPotFirst(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 100, "2": 300, "3": 300}, nil)
+	engine.SetRake(5, 0)
+
+	require.NoError(t, engine.showdown())
+
+	assert.Equal(t, 265, engine.getHoldemPlayer("1").Chips, "5% of the 700 pot comes out of the main pot")
+	assert.Equal(t, 400, engine.getHoldemPlayer("2").Chips)
+	assert.Equal(t, 35, potDistributedEvent(t, engine).Data["rake"])
+
+	events := potEvents(engine)
+	require.Len(t, events, 2)
+	assert.Equal(t, 265, events[0].Data["amount"])
+	assert.Equal(t, 35, events[0].Data["rake"])
+	assert.Equal(t, 0, events[1].Data["rake"])
+}
+
+func TestTexasHoldemRakeRules(t *testing.T) {
+	hands := map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}
+
+	capped := setupSidePotShowdown(t, hands, map[string]int{"1": 300, "2": 300, "3": 300}, nil)
+	capped.SetRake(5, 10)
+	require.NoError(t, capped.showdown())
+	assert.Equal(t, 890, capped.getHoldemPlayer("1").Chips, "rake stops at the cap")
+
+	noFlop := setupSidePotShowdown(t, hands, map[string]int{"1": 300, "2": 300, "3": 300}, nil)
+	noFlop.SetRake(5, 0)
+	noFlop.communityCards.Cards = nil
+	noFlop.winners = []*TexasHoldemPlayer{noFlop.getHoldemPlayer("1")}
+	noFlop.distributePot()
+	assert.Equal(t, 900, noFlop.getHoldemPlayer("1").Chips, "no flop, no drop")
+	assert.Equal(t, 0, potDistributedEvent(t, noFlop).Data["rake"])
+
+	uncalled := setupSidePotShowdown(t, hands, map[string]int{"1": 50, "2": 200, "3": 20}, map[string]bool{"1": true, "3": true})
+	uncalled.SetRake(5, 0)
+	uncalled.winners = []*TexasHoldemPlayer{uncalled.getHoldemPlayer("2")}
+	uncalled.distributePot()
+	assert.Equal(t, 264, uncalled.getHoldemPlayer("2").Chips, "the 150 nobody called is not raked")
+}
+
+func TestManagerCollectsRakeForTheHouse(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ledger := newFakeLedger(map[string]int{})
+	manager.SetDiamondLedger(ledger)
+	manager.SetHouseAccount("house")
+	store := &recordedRake{}
+	manager.SetRakeStore(store)
+	require.NoError(t, manager.escrow.Deposit("rake-table", "a", 100))
+	require.NoError(t, manager.escrow.Deposit("rake-table", "b", 300))
+
+	table := &GameTable{ID: "rake-table", Settings: DefaultTableSettings()}
+	manager.collectRake(table, HandRecord{HandID: "rake-table-3", HandNumber: 3, SmallBlind: 10, BigBlind: 20, Rake: 20})
+
+	assert.Equal(t, 20, ledger.balance("house"))
+	assert.Equal(t, []string{"rake-table rake-table-3 10/20 20"}, store.entries)
+	assert.Equal(t, int64(380), manager.escrow.Total("rake-table"), "raked chips leave the escrow")
+	assert.Equal(t, map[string]int64{"a": 95, "b": 285}, manager.escrow.Balances("rake-table"))
+
+	practice := &GameTable{ID: "practice-table", Settings: DefaultTableSettings()}
+	practice.Settings.Currency = CurrencyPlayMoney
+	manager.collectRake(practice, HandRecord{HandNumber: 1, Rake: 20})
+	assert.Equal(t, 20, ledger.balance("house"), "play money is never credited to the house")
+	assert.Len(t, store.entries, 1)
+}
+
+func TestCashTablesTakeTheDefaultRake(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+
+	create := func(settings TableSettings) (*GameTable, *TexasHoldemEngine) {
+		table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+			Name: "raked", GameType: GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: settings,
+		})
+		require.NoError(t, err)
+		return table, table.GameEngine.(*TexasHoldemEngine)
+	}
+
+	cash, engine := create(DefaultTableSettings())
+	assert.Equal(t, CurrentGameDefaults().RakePercent, cash.Settings.RakePercent)
+	assert.Equal(t, CurrentGameDefaults().RakePercent, engine.rakePercent)
+
+	own := DefaultTableSettings()
+	own.RakePercent, own.MaxRake = 2.5, 30
+	_, engine = create(own)
+	assert.Equal(t, 2.5, engine.rakePercent)
+	assert.Equal(t, 30, engine.maxRake)
+
+	practiceSettings := DefaultTableSettings()
+	practiceSettings.Currency = CurrencyPlayMoney
+	practice, engine := create(practiceSettings)
+	assert.Zero(t, practice.Settings.RakePercent)
+	assert.Zero(t, engine.rakePercent)
+
+	tooHigh := DefaultTableSettings()
+	tooHigh.RakePercent = MaxRakePercent + 1
+	_, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "greedy", GameType: GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: tooHigh,
+	})
+	assert.Error(t, err)
+}
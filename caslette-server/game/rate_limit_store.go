@@ -0,0 +1,212 @@
+package game
+
+import (
+	"log"
+	"time"
+)
+
+// RateLimitRecord is the part of a user's rate-limit state that outlives a
+// restart: the tables counting against their caps
+type RateLimitRecord struct {
+	UserID        string    `json:"user_id"`
+	CreatedTables []string  `json:"created_tables"`
+	ActiveTables  []string  `json:"active_tables"`
+	LastActivity  time.Time `json:"last_activity"`
+}
+
+// RateLimitStore persists rate-limit records. Saving a record with no tables
+// removes it.
+type RateLimitStore interface {
+	LoadRateLimits() ([]RateLimitRecord, error)
+	SaveRateLimits(record RateLimitRecord) error
+}
+
+// RateLimitReconciliation reports the corrections made when persisted
+// rate-limit state was checked against the tables actually open
+type RateLimitReconciliation struct {
+	Users          int `json:"users"`           // Users whose state was corrected
+	CreatedDropped int `json:"created_dropped"` // Created tables that no longer exist
+	CreatedAdded   int `json:"created_added"`   // Open tables missing from their creator's count
+	ActiveDropped  int `json:"active_dropped"`  // Tables the user no longer sits at
+	ActiveAdded    int `json:"active_added"`    // Seats missing from the user's count
+}
+
+// openTable is what reconciliation needs to know about a live table
+type openTable struct {
+	CreatedBy string
+	Players   map[string]bool
+}
+
+// persist saves a user's tables to the store, if there is one
+func (rl *RateLimiterState) persist(userID string) {
+	if rl.store == nil {
+		return
+	}
+	state := rl.getUserState(userID)
+	record := RateLimitRecord{
+		UserID:        userID,
+		CreatedTables: append([]string(nil), state.CreatedTables...),
+		ActiveTables:  append([]string(nil), state.ActiveTables...),
+		LastActivity:  state.LastActivity,
+	}
+	if err := rl.store.SaveRateLimits(record); err != nil {
+		log.Printf("ActorRateLimiter: failed to persist state for %s: %v", userID, err)
+	}
+}
+
+// RestoreRateLimitsCommand loads persisted state, merging it with what is
+// already held, and persists every change from then on
+type RestoreRateLimitsCommand struct {
+	Store  RateLimitStore
+	Result chan error
+}
+
+func (cmd *RestoreRateLimitsCommand) Execute(rl *RateLimiterState) interface{} {
+	records, err := cmd.Store.LoadRateLimits()
+	if err != nil {
+		cmd.Result <- err
+		return nil
+	}
+	for _, record := range records {
+		state := rl.getUserState(record.UserID)
+		for _, tableID := range record.CreatedTables {
+			if !rl.containsString(state.CreatedTables, tableID) {
+				state.CreatedTables = append(state.CreatedTables, tableID)
+			}
+		}
+		for _, tableID := range record.ActiveTables {
+			if !rl.containsString(state.ActiveTables, tableID) {
+				state.ActiveTables = append(state.ActiveTables, tableID)
+			}
+		}
+		if record.LastActivity.After(state.LastActivity) {
+			state.LastActivity = record.LastActivity
+		}
+	}
+
+	// The store now holds everything, including tables recorded before it
+	// was attached
+	rl.store = cmd.Store
+	for userID, state := range rl.userLimits {
+		if len(state.CreatedTables) > 0 || len(state.ActiveTables) > 0 {
+			rl.persist(userID)
+		}
+	}
+	cmd.Result <- nil
+	return nil
+}
+
+// ReconcileRateLimitsCommand brings every user's created and active tables
+// in line with the tables actually open
+type ReconcileRateLimitsCommand struct {
+	Tables map[string]openTable // Table ID -> table
+	Result chan RateLimitReconciliation
+}
+
+func (cmd *ReconcileRateLimitsCommand) Execute(rl *RateLimiterState) interface{} {
+	var report RateLimitReconciliation
+
+	// Open tables every user should be counted for
+	created := make(map[string][]string)
+	active := make(map[string][]string)
+	for tableID, table := range cmd.Tables {
+		created[table.CreatedBy] = append(created[table.CreatedBy], tableID)
+		for playerID := range table.Players {
+			active[playerID] = append(active[playerID], tableID)
+		}
+	}
+	for userID := range created {
+		rl.getUserState(userID)
+	}
+	for userID := range active {
+		rl.getUserState(userID)
+	}
+
+	for userID, state := range rl.userLimits {
+		changed := false
+		kept := make([]string, 0, len(state.CreatedTables))
+		for _, tableID := range state.CreatedTables {
+			if table, open := cmd.Tables[tableID]; open && table.CreatedBy == userID {
+				kept = append(kept, tableID)
+			} else {
+				report.CreatedDropped++
+				changed = true
+			}
+		}
+		for _, tableID := range created[userID] {
+			if !rl.containsString(kept, tableID) {
+				kept = append(kept, tableID)
+				report.CreatedAdded++
+				changed = true
+			}
+		}
+		state.CreatedTables = kept
+
+		seated := make([]string, 0, len(state.ActiveTables))
+		for _, tableID := range state.ActiveTables {
+			if table, open := cmd.Tables[tableID]; open && table.Players[userID] {
+				seated = append(seated, tableID)
+			} else {
+				report.ActiveDropped++
+				changed = true
+			}
+		}
+		for _, tableID := range active[userID] {
+			if !rl.containsString(seated, tableID) {
+				seated = append(seated, tableID)
+				report.ActiveAdded++
+				changed = true
+			}
+		}
+		state.ActiveTables = seated
+
+		if changed {
+			report.Users++
+			rl.persist(userID)
+		}
+	}
+
+	cmd.Result <- report
+	return nil
+}
+
+// SetStore loads the state persisted in store and saves every change to the
+// tables a user has created or sits at from then on
+func (arl *ActorRateLimiter) SetStore(store RateLimitStore) error {
+	result := make(chan error, 1)
+	arl.commands <- &RestoreRateLimitsCommand{Store: store, Result: result}
+	return <-result
+}
+
+// Reconcile corrects every user's created and active tables against the
+// tables actually open
+func (arl *ActorRateLimiter) Reconcile(tables map[string]openTable) RateLimitReconciliation {
+	result := make(chan RateLimitReconciliation, 1)
+	arl.commands <- &ReconcileRateLimitsCommand{Tables: tables, Result: result}
+	return <-result
+}
+
+// RestoreRateLimits loads the rate-limit state persisted before a restart,
+// persists it from now on and reconciles it against the tables open now
+func (tm *ActorTableManager) RestoreRateLimits(store RateLimitStore) (RateLimitReconciliation, error) {
+	if err := tm.rateLimiter.SetStore(store); err != nil {
+		return RateLimitReconciliation{}, err
+	}
+	return tm.ReconcileRateLimits(), nil
+}
+
+// ReconcileRateLimits corrects the rate limiter's view of who created and sits
+// at which tables against the tables open now
+func (tm *ActorTableManager) ReconcileRateLimits() RateLimitReconciliation {
+	tables := make(map[string]openTable)
+	for _, table := range tm.GetTables() {
+		open := openTable{CreatedBy: table.CreatedBy, Players: make(map[string]bool)}
+		for _, slot := range table.PlayerSlots {
+			if slot.PlayerID != "" {
+				open.Players[slot.PlayerID] = true
+			}
+		}
+		tables[table.ID] = open
+	}
+	return tm.rateLimiter.Reconcile(tables)
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRateLimitStore keeps records the way a database would between restarts
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	records map[string]RateLimitRecord
+}
+
+func newMemoryRateLimitStore(records ...RateLimitRecord) *memoryRateLimitStore {
+	store := &memoryRateLimitStore{records: make(map[string]RateLimitRecord)}
+	for _, record := range records {
+		store.records[record.UserID] = record
+	}
+	return store
+}
+
+func (s *memoryRateLimitStore) LoadRateLimits() ([]RateLimitRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]RateLimitRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (s *memoryRateLimitStore) SaveRateLimits(record RateLimitRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(record.CreatedTables) == 0 && len(record.ActiveTables) == 0 {
+		delete(s.records, record.UserID)
+		return nil
+	}
+	s.records[record.UserID] = record
+	return nil
+}
+
+func (s *memoryRateLimitStore) record(userID string) (RateLimitRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[userID]
+	return record, ok
+}
+
+func TestRateLimiterRestoresPersistedTableCaps(t *testing.T) {
+	store := newMemoryRateLimitStore(RateLimitRecord{UserID: "u1", CreatedTables: []string{"t1", "t2"}})
+	limiter := NewActorRateLimiterWithLimits(map[string]interface{}{"max_tables_per_user": 2})
+	defer limiter.Stop()
+
+	require.NoError(t, limiter.SetStore(store))
+	err := limiter.CanCreateTable("u1")
+	require.Error(t, err, "tables created before the restart still count")
+	assert.Equal(t, 2, limiter.GetUserStats("u1")["tables_created"])
+
+	limiter.RecordTableClosed("u1", "t1")
+	limiter.RecordPlayerJoined("u1", "t2")
+	assert.NoError(t, limiter.CanCreateTable("u1"))
+	record, ok := store.record("u1")
+	require.True(t, ok)
+	assert.Equal(t, []string{"t2"}, record.CreatedTables)
+	assert.Equal(t, []string{"t2"}, record.ActiveTables)
+
+	limiter.RecordTableClosed("u1", "t2")
+	limiter.RecordPlayerLeft("u1", "t2")
+	limiter.GetUserStats("u1") // Wait for the records to be applied
+	_, ok = store.record("u1")
+	assert.False(t, ok, "users with no tables are removed from the store")
+}
+
+func TestManagerPersistsAndReconcilesTableCounts(t *testing.T) {
+	store := newMemoryRateLimitStore(
+		RateLimitRecord{UserID: "creator_gone", CreatedTables: []string{"closed_before_restart"}},
+		RateLimitRecord{UserID: "seated", ActiveTables: []string{"closed_before_restart"}},
+	)
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	table := newBalancingTable(t, manager, "live", QuickGameSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "seated", 0))
+
+	report, err := manager.RestoreRateLimits(store)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Users)
+	assert.Equal(t, 1, report.CreatedDropped)
+	assert.Equal(t, 1, report.ActiveDropped)
+	assert.Zero(t, report.CreatedAdded)
+
+	record, ok := store.record("creator_live")
+	require.True(t, ok, "tables opened before the store was attached are persisted")
+	assert.Equal(t, []string{table.ID}, record.CreatedTables)
+	record, _ = store.record("seated")
+	assert.Equal(t, []string{table.ID}, record.ActiveTables)
+	_, ok = store.record("creator_gone")
+	assert.False(t, ok)
+
+	require.NoError(t, manager.CloseTable(table.ID))
+	assert.Zero(t, manager.ReconcileRateLimits().Users, "closing the table already released its counts")
+	_, ok = store.record("seated")
+	assert.False(t, ok)
+}
@@ -0,0 +1,174 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultRatholeWindow is how long a departing stack sets the minimum buy-in
+// for the same stakes
+const DefaultRatholeWindow = 2 * time.Hour
+
+// departure remembers the stack a player took away from a stake level
+type departure struct {
+	Stack   int
+	TableID string
+	LeftAt  time.Time
+}
+
+// RatholeGuard enforces the anti-ratholing rule: a player who leaves a cash
+// game and sits back down at the same stakes within the window must bring at
+// least the stack they left with, so winnings can't be pocketed between
+// sessions while keeping a short-stack seat.
+type RatholeGuard struct {
+	mu         sync.Mutex
+	window     time.Duration
+	departures map[string]departure // Player ID and stakes key -> last departure
+	now        func() time.Time
+}
+
+// NewRatholeGuard creates a guard with the given window; zero disables it
+func NewRatholeGuard(window time.Duration) *RatholeGuard {
+	return &RatholeGuard{
+		window:     window,
+		departures: make(map[string]departure),
+		now:        time.Now,
+	}
+}
+
+// SetWindow changes how long departures are remembered; zero disables the rule
+func (g *RatholeGuard) SetWindow(window time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.window = window
+}
+
+// Window returns how long departures are remembered
+func (g *RatholeGuard) Window() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.window
+}
+
+// stakesKey groups tables a player could rathole between: the same game,
+// currency and blinds
+func stakesKey(table *GameTable) string {
+	return fmt.Sprintf("%s:%s:%s", table.GameType, table.GetCurrency(), tableStakes(table))
+}
+
+// applies reports whether a table's buy-ins are subject to the rule.
+// Tournament entries are fixed, so only cash games are guarded.
+func (g *RatholeGuard) applies(table *GameTable) bool {
+	return !table.Settings.TournamentMode
+}
+
+// RecordDeparture remembers the stack a player left a table with
+func (g *RatholeGuard) RecordDeparture(playerID string, table *GameTable, stack int) {
+	if !g.applies(table) || stack <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.window <= 0 {
+		return
+	}
+	g.prune()
+	g.departures[playerID+"|"+stakesKey(table)] = departure{Stack: stack, TableID: table.ID, LeftAt: g.now()}
+}
+
+// Requirement returns the stack a player must bring back to a table's
+// stakes, and when that requirement lapses. A zero stack means no
+// requirement applies.
+func (g *RatholeGuard) Requirement(playerID string, table *GameTable) (int, time.Time) {
+	if !g.applies(table) {
+		return 0, time.Time{}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.window <= 0 {
+		return 0, time.Time{}
+	}
+	left, exists := g.departures[playerID+"|"+stakesKey(table)]
+	if !exists {
+		return 0, time.Time{}
+	}
+	expires := left.LeftAt.Add(g.window)
+	if !g.now().Before(expires) {
+		return 0, time.Time{}
+	}
+	return left.Stack, expires
+}
+
+// Clear forgets a departure once the player has bought back in at the stakes
+func (g *RatholeGuard) Clear(playerID string, table *GameTable) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.departures, playerID+"|"+stakesKey(table))
+}
+
+// prune drops departures older than the window; callers hold the lock
+func (g *RatholeGuard) prune() {
+	cutoff := g.now().Add(-g.window)
+	for key, left := range g.departures {
+		if left.LeftAt.Before(cutoff) {
+			delete(g.departures, key)
+		}
+	}
+}
+
+// resolveBuyIn picks the chips a player sits down with and checks them
+// against the table's buy-in range and any anti-ratholing minimum. A zero
+// amount means the smallest buy-in allowed.
+func (g *RatholeGuard) resolveBuyIn(playerID string, table *GameTable, amount int) (int, error) {
+	minimum, maximum, required, expires := g.buyInRange(playerID, table)
+	ratholed := required > table.Settings.BuyIn
+
+	if amount == 0 {
+		amount = minimum
+	}
+
+	if amount < minimum {
+		if ratholed {
+			return 0, &TableError{"RATHOLE_MINIMUM", fmt.Sprintf(
+				"You left these stakes with %d chips and must bring at least that back until %s",
+				required, expires.UTC().Format(time.RFC3339))}
+		}
+		return 0, &TableError{"BUY_IN_TOO_SMALL", fmt.Sprintf("Minimum buy-in is %d", minimum)}
+	}
+	if maximum > 0 && amount > maximum {
+		return 0, &TableError{"BUY_IN_TOO_LARGE", fmt.Sprintf("Maximum buy-in is %d", maximum)}
+	}
+	return amount, nil
+}
+
+// buyInRange returns the smallest and largest buy-in a player may bring to a
+// table, along with any anti-ratholing requirement and when it lapses. A zero
+// maximum means there is no upper limit.
+func (g *RatholeGuard) buyInRange(playerID string, table *GameTable) (minimum, maximum, required int, expires time.Time) {
+	minimum = table.Settings.BuyIn
+	maximum = table.Settings.MaxBuyIn
+	required, expires = g.Requirement(playerID, table)
+
+	// Returning players may bring their previous stack even above the maximum
+	if required > minimum {
+		minimum = required
+		if maximum > 0 && maximum < required {
+			maximum = required
+		}
+	}
+	return minimum, maximum, required, expires
+}
+
+// ResolveBuyIn returns the chips a player would sit down with at a table for
+// the requested amount, applying the buy-in range and any anti-ratholing
+// minimum, so callers can charge for the seat before taking it
+func (tm *ActorTableManager) ResolveBuyIn(playerID, tableID string, amount int) (int, error) {
+	table, err := tm.GetTable(tableID)
+	if err != nil {
+		return 0, err
+	}
+	return tm.ratholes.resolveBuyIn(playerID, table, amount)
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRatholeTables creates two tables at the same stakes and one at different stakes
+func newRatholeTables(t *testing.T, manager *ActorTableManager) (*GameTable, *GameTable, *GameTable) {
+	first := newBalancingTable(t, manager, "first", DefaultTableSettings(), 0)
+	second := newBalancingTable(t, manager, "second", DefaultTableSettings(), 0)
+	other := newBalancingTable(t, manager, "other", QuickGameSettings(), 0)
+	return first, second, other
+}
+
+func joinWithBuyIn(manager *ActorTableManager, tableID, playerID string, buyIn int) error {
+	return manager.JoinTable(context.Background(), &TableJoinRequest{
+		TableID: tableID, PlayerID: playerID, Username: playerID, Mode: JoinModePlayer, BuyIn: buyIn,
+	})
+}
+
+func seatChips(table *GameTable, playerID string) int {
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == playerID {
+			return slot.Chips
+		}
+	}
+	return -1
+}
+
+func TestJoinTableEnforcesBuyInRange(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	table, _, _ := newRatholeTables(t, manager)
+
+	err := joinWithBuyIn(manager, table.ID, "p1", 500)
+	require.Error(t, err)
+	assert.Equal(t, "BUY_IN_TOO_SMALL", err.(*TableError).Code)
+
+	err = joinWithBuyIn(manager, table.ID, "p1", 2500)
+	require.Error(t, err)
+	assert.Equal(t, "BUY_IN_TOO_LARGE", err.(*TableError).Code)
+
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 0))
+	assert.Equal(t, 1000, seatChips(table, "p1"))
+}
+
+func TestRatholeGuardRequiresPreviousStackAtSameStakes(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	first, second, other := newRatholeTables(t, manager)
+	ctx := context.Background()
+
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 1800))
+	require.NoError(t, manager.LeaveTable(ctx, &TableLeaveRequest{TableID: first.ID, PlayerID: "p1"}))
+
+	// Coming back short at another table with the same blinds is refused
+	err := joinWithBuyIn(manager, second.ID, "p1", 1000)
+	require.Error(t, err)
+	assert.Equal(t, "RATHOLE_MINIMUM", err.(*TableError).Code)
+
+	// Different stakes are unaffected
+	require.NoError(t, joinWithBuyIn(manager, other.ID, "p1", 0))
+
+	// Without an explicit amount the player sits with the required stack
+	require.NoError(t, joinWithBuyIn(manager, second.ID, "p1", 0))
+	assert.Equal(t, 1800, seatChips(second, "p1"))
+}
+
+func TestRatholeGuardAllowsPreviousStackAboveMaximum(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	first, _, _ := newRatholeTables(t, manager)
+	manager.RatholeGuard().RecordDeparture("p1", first, 3500)
+
+	err := joinWithBuyIn(manager, first.ID, "p1", 3600)
+	require.Error(t, err)
+	assert.Equal(t, "BUY_IN_TOO_LARGE", err.(*TableError).Code)
+
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 3500))
+	assert.Equal(t, 3500, seatChips(first, "p1"))
+}
+
+func TestRatholeGuardRequirementLapses(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	first, _, _ := newRatholeTables(t, manager)
+	guard := manager.RatholeGuard()
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+
+	guard.RecordDeparture("p1", first, 1900)
+	required, expires := guard.Requirement("p1", first)
+	assert.Equal(t, 1900, required)
+	assert.Equal(t, now.Add(DefaultRatholeWindow), expires)
+
+	now = now.Add(DefaultRatholeWindow)
+	required, _ = guard.Requirement("p1", first)
+	assert.Equal(t, 0, required)
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 1000))
+}
+
+func TestRatholeGuardDisabledAndTournaments(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	first, _, _ := newRatholeTables(t, manager)
+	guard := manager.RatholeGuard()
+
+	guard.SetWindow(0)
+	guard.RecordDeparture("p1", first, 1900)
+	required, _ := guard.Requirement("p1", first)
+	assert.Equal(t, 0, required)
+
+	guard.SetWindow(time.Hour)
+	tournament := NewGameTable("t1", "tournament", GameTypeTexasHoldem, "creator", TournamentSettings())
+	guard.RecordDeparture("p1", tournament, 5000)
+	required, _ = guard.Requirement("p1", tournament)
+	assert.Equal(t, 0, required)
+}
+
+func TestMovePlayerKeepsStackWithoutRecordingDeparture(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	first, second, _ := newRatholeTables(t, manager)
+
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 1500))
+	require.NoError(t, manager.MovePlayer(context.Background(), "p1", "p1", first.ID, second.ID))
+
+	assert.Equal(t, 1500, seatChips(second, "p1"))
+	required, _ := manager.RatholeGuard().Requirement("p1", first)
+	assert.Equal(t, 0, required)
+}
+
+func TestLeavingACashTablePaysTheStackBack(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	ledger := newFakeLedger(map[string]int{"p1": 1500})
+	manager.SetDiamondLedger(ledger)
+	table := newBalancingTable(t, manager, "cash", DefaultTableSettings(), 0)
+
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 1200))
+	assert.Equal(t, 300, ledger.balance("p1"), "the buy-in is charged on joining")
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: "p1"}))
+	assert.Equal(t, 1500, ledger.balance("p1"), "a player who neither won nor lost leaves with what they had")
+	assert.Zero(t, manager.escrow.Total(table.ID))
+
+	err := joinWithBuyIn(manager, table.ID, "p2", 1000)
+	var tableErr *TableError
+	require.ErrorAs(t, err, &tableErr)
+	assert.Equal(t, "BUY_IN_FAILED", tableErr.Code)
+	assert.False(t, table.IsPlayerAtTable("p2"), "a seat that cannot be paid for is given up")
+}
+
+func TestCashOutPaysWinningsFromTheOtherPlayersEscrow(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	manager.SetInterHandDelay(time.Hour)
+	t.Cleanup(manager.Stop)
+	ledger := newFakeLedger(map[string]int{"p0": 1000, "p1": 1000})
+	manager.SetDiamondLedger(ledger)
+	settings := DefaultTableSettings()
+	settings.TimeLimit = 0
+	table := newBalancingTable(t, manager, "cash", settings, 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p0", 1000))
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 1000))
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	require.NotEqual(t, 1000, stacks["p0"])
+
+	for _, playerID := range []string{"p0", "p1"} {
+		require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: playerID}))
+		assert.Equal(t, stacks[playerID], ledger.balance(playerID))
+	}
+	assert.Equal(t, 2000, ledger.balance("p0")+ledger.balance("p1"), "no diamonds are made or lost")
+	assert.Empty(t, manager.escrow.Tables())
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// TopUpEngine is an engine that adds chips to a stack between hands
+type TopUpEngine interface {
+	TopUp(playerID string, amount int) error
+}
+
+// AddSeatChipsCommand adds bought chips to a seated player's stack. Stack,
+// when set, is the engine's count after the top-up and replaces the seat's.
+type AddSeatChipsCommand struct {
+	PlayerID string
+	Amount   int
+	Stack    int
+	Response chan interface{}
+}
+
+func (cmd *AddSeatChipsCommand) Execute(table *GameTable) interface{} {
+	for i := range table.PlayerSlots {
+		slot := &table.PlayerSlots[i]
+		if slot.PlayerID != cmd.PlayerID {
+			continue
+		}
+		if cmd.Stack > 0 {
+			slot.Chips = cmd.Stack
+		} else {
+			slot.Chips += cmd.Amount
+		}
+		table.UpdatedAt = time.Now()
+		return slot.Chips
+	}
+	return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+}
+
+// AddSeatChips sends bought chips to the table actor, returning the new stack
+func (ta *TableActor) AddSeatChips(ctx context.Context, playerID string, amount, stack int) (int, error) {
+	cmd := &AddSeatChipsCommand{
+		PlayerID: playerID,
+		Amount:   amount,
+		Stack:    stack,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		switch result := result.(type) {
+		case int:
+			return result, nil
+		case *TableError:
+			return 0, result
+		}
+		return 0, fmt.Errorf("unexpected response type")
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// topUpEngine adds chips to the engine's stack for a player it deals to,
+// returning the new stack, or zero when the engine does not hold the player
+func topUpEngine(table *GameTable, playerID string, amount int) (int, error) {
+	holder, ok := table.GameEngine.(ChipHolder)
+	if !ok {
+		return 0, nil
+	}
+	if stacks, _ := holder.ChipCounts(); !hasStack(stacks, playerID) {
+		return 0, nil
+	}
+	engine, ok := table.GameEngine.(TopUpEngine)
+	if !ok {
+		return 0, &TableError{"TOP_UP_UNSUPPORTED", "This game cannot add chips to a stack"}
+	}
+	if err := engine.TopUp(playerID, amount); err != nil {
+		return 0, &TableError{"HAND_IN_PROGRESS", err.Error()}
+	}
+	stacks, _ := holder.ChipCounts()
+	return stacks[playerID], nil
+}
+
+// hasStack reports whether the engine deals to a player
+func hasStack(stacks map[string]int, playerID string) bool {
+	_, ok := stacks[playerID]
+	return ok
+}
+
+// topUpTarget is the stack a rebuy fills up to: the maximum buy-in, or the
+// buy-in at tables without one
+func topUpTarget(table *GameTable) int {
+	if table.Settings.MaxBuyIn > 0 {
+		return table.Settings.MaxBuyIn
+	}
+	return table.Settings.BuyIn
+}
+
+// AddChips buys a seated player more chips between hands, taking diamonds
+// for them at diamond tables. The stack may not go above the maximum buy-in.
+func (tm *ActorTableManager) AddChips(ctx context.Context, tableID, playerID string, amount int) (int, error) {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return 0, ErrTableNotFound
+	}
+	if amount <= 0 {
+		return 0, &TableError{"INVALID_AMOUNT", "Amount must be positive"}
+	}
+	return tm.addChips(ctx, actor, playerID, amount, false)
+}
+
+// addChips charges for and adds chips to a player's stack, escrowing them
+// and announcing the rebuy
+func (tm *ActorTableManager) addChips(ctx context.Context, actor *TableActor, playerID string, amount int, automatic bool) (int, error) {
+	table := actor.table
+	if table.Settings.TournamentMode {
+		return 0, &TableError{"REBUY_NOT_ALLOWED", "Tournament stacks cannot be topped up"}
+	}
+	if table.GetPlayerPosition(playerID) == -1 {
+		return 0, &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+	}
+	if target := topUpTarget(table); tm.stackAt(ctx, table.ID, playerID)+amount > target {
+		return 0, &TableError{"BUY_IN_TOO_LARGE", fmt.Sprintf("Stacks may be topped up to at most %d", target)}
+	}
+
+	// Top-ups are paid for and escrowed like the buy-in, so leaving the
+	// table pays them back with the rest of the stack
+	if table.UsesDiamondLedger() && tm.diamondLedger() == nil {
+		return 0, &TableError{"NO_LEDGER", "Diamond tables need a diamond ledger to add chips"}
+	}
+	if err := tm.chargeChips(table, playerID, amount, "Top-up"); err != nil {
+		return 0, err
+	}
+
+	stack, err := topUpEngine(table, playerID, amount)
+	if err == nil {
+		engineStack := stack
+		if stack, err = actor.AddSeatChips(ctx, playerID, amount, engineStack); err != nil && engineStack > 0 {
+			table.GameEngine.(ChipHolder).AdjustChips(playerID, -amount)
+		}
+	}
+	if err != nil {
+		tm.refundChips(table, playerID, amount, "Top-up")
+		return 0, err
+	}
+	if err := tm.depositEscrow(table, playerID, int64(amount)); err != nil {
+		log.Printf("Table %s: failed to escrow top-up for %s: %v", table.ID, playerID, err)
+	}
+
+	now := time.Now()
+	tm.BroadcastGameEvent(table, &GameEvent{
+		Type:     "player_rebuy",
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"table_id":  table.ID,
+			"player_id": playerID,
+			"amount":    amount,
+			"chips":     stack,
+			"automatic": automatic,
+		},
+		Timestamp: now,
+	})
+	return stack, nil
+}
+
+// autoRebuy tops up the seated players whose stacks fell below the table's
+// auto-rebuy threshold, returning the seats with their new stacks. A player
+// who cannot pay keeps the stack they have.
+func (tm *ActorTableManager) autoRebuy(ctx context.Context, actor *TableActor, seats []PlayerSlot) []PlayerSlot {
+	table := actor.table
+	if !table.Settings.AutoRebuy || table.Settings.TournamentMode {
+		return seats
+	}
+	threshold := max(table.Settings.AutoRebuyBelow, 1)
+	target := topUpTarget(table)
+	for i := range seats {
+		slot := &seats[i]
+		if slot.PlayerID == "" || slot.Chips >= threshold || slot.Chips >= target {
+			continue
+		}
+		chips, err := tm.addChips(ctx, actor, slot.PlayerID, target-slot.Chips, true)
+		if err != nil {
+			log.Printf("Table %s: auto-rebuy for %s failed: %v", table.ID, slot.PlayerID, err)
+			continue
+		}
+		slot.Chips = chips
+	}
+	return seats
+}
+
+// TopUp adds bought chips to a player's stack; chips cannot be added while a
+// hand is being played
+func (the *TexasHoldemEngine) TopUp(playerID string, amount int) error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if state := the.GetState(); state == GameStateInProgress || state == GameStatePaused {
+		return fmt.Errorf("chips can only be added between hands")
+	}
+	holdemPlayer := the.getHoldemPlayer(playerID)
+	if holdemPlayer == nil {
+		return fmt.Errorf("player %s not found", playerID)
+	}
+	holdemPlayer.Chips += amount
+	return nil
+}
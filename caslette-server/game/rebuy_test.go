@@ -0,0 +1,100 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoRebuyTopsUpShortStacksBetweenHands(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1", "p2")
+	ledger := newFakeLedger(map[string]int{"p1": 5000})
+	manager.SetDiamondLedger(ledger)
+	table.Settings.AutoRebuy = true
+	table.Settings.AutoRebuyBelow = 500
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	require.NoError(t, engine.AdjustChips("p1", -stacks["p1"]))
+	require.NoError(t, engine.AdjustChips("p2", -stacks["p2"]))
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 2 }, time.Second, 5*time.Millisecond)
+
+	rebuys := broadcaster.ofType("player_rebuy")
+	require.Len(t, rebuys, 1)
+	assert.Equal(t, "p1", rebuys[0].PlayerID)
+	assert.Equal(t, 2000, rebuys[0].Data["amount"], "topped up to the maximum buy-in")
+	assert.Equal(t, true, rebuys[0].Data["automatic"])
+	assert.Equal(t, 3000, ledger.balances["p1"])
+	assert.Equal(t, int64(3750), manager.escrow.Balances(table.ID)["p1"], "the top-up is escrowed, with a share of the busted player's buy-in")
+	_, err := engine.GetPlayer("p1")
+	assert.NoError(t, err, "the rebought player is dealt in")
+
+	busted := broadcaster.ofType("player_busted")
+	require.Len(t, busted, 1, "a player who cannot pay busts as before")
+	assert.Equal(t, "p2", busted[0].PlayerID)
+}
+
+func TestAddChipsOnlyBetweenHands(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, time.Hour, "p0", "p1")
+	ledger := newFakeLedger(map[string]int{"p0": 5000})
+	manager.SetDiamondLedger(ledger)
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	var tableErr *TableError
+	_, err := manager.AddChips(context.Background(), table.ID, "p0", 100)
+	require.True(t, errors.As(err, &tableErr))
+	assert.Equal(t, "HAND_IN_PROGRESS", tableErr.Code)
+	assert.Equal(t, 5000, ledger.balances["p0"], "a refused top-up is refunded")
+
+	foldHand(t, engine)
+	stacks, _ := engine.ChipCounts()
+	chips, err := manager.AddChips(context.Background(), table.ID, "p0", 500)
+	require.NoError(t, err)
+	assert.Equal(t, stacks["p0"]+500, chips)
+	assert.Equal(t, chips, seatChips(table, "p0"))
+	after, _ := engine.ChipCounts()
+	assert.Equal(t, chips, after["p0"])
+	assert.Equal(t, 4500, ledger.balances["p0"])
+	rebuys := broadcaster.ofType("player_rebuy")
+	require.Len(t, rebuys, 1)
+	assert.Equal(t, false, rebuys[0].Data["automatic"])
+
+	_, err = manager.AddChips(context.Background(), table.ID, "p0", 2000)
+	require.True(t, errors.As(err, &tableErr))
+	assert.Equal(t, "BUY_IN_TOO_LARGE", tableErr.Code)
+	_, err = manager.AddChips(context.Background(), table.ID, "p1", 100)
+	require.True(t, errors.As(err, &tableErr))
+	assert.Equal(t, "BUY_IN_FAILED", tableErr.Code, "p1 has no diamonds")
+	_, err = manager.AddChips(context.Background(), table.ID, "p0", 0)
+	assert.Error(t, err)
+
+	table.Settings.TournamentMode = true
+	_, err = manager.AddChips(context.Background(), table.ID, "p0", 100)
+	require.True(t, errors.As(err, &tableErr))
+	assert.Equal(t, "REBUY_NOT_ALLOWED", tableErr.Code)
+}
+
+func TestLeavingPaysBackTopUps(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	ledger := newFakeLedger(map[string]int{"p1": 3000})
+	manager.SetDiamondLedger(ledger)
+	table := newBalancingTable(t, manager, "cash", DefaultTableSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 1000))
+
+	chips, err := manager.AddChips(context.Background(), table.ID, "p1", 800)
+	require.NoError(t, err)
+	assert.Equal(t, 1800, chips)
+	assert.Equal(t, 1200, ledger.balance("p1"))
+	assert.Equal(t, int64(1800), manager.escrow.Balances(table.ID)["p1"])
+
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: "p1"}))
+	assert.Equal(t, 3000, ledger.balance("p1"), "the top-up is cashed out with the buy-in")
+}
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultReconnectGrace is how long a disconnected player keeps their seats
+const DefaultReconnectGrace = 2 * time.Minute
+
+// connectionAwareEngine is implemented by engines that act sooner for
+// players whose connection dropped
+type connectionAwareEngine interface {
+	SetPlayerDisconnected(playerID string, disconnected bool)
+}
+
+// SetPlayerConnectedCommand marks a seated player as disconnected or back
+type SetPlayerConnectedCommand struct {
+	PlayerID     string
+	Disconnected bool
+	At           time.Time
+	Response     chan interface{}
+}
+
+func (cmd *SetPlayerConnectedCommand) Execute(table *GameTable) interface{} {
+	for i := range table.PlayerSlots {
+		slot := &table.PlayerSlots[i]
+		if slot.PlayerID != cmd.PlayerID {
+			continue
+		}
+		if slot.Disconnected == cmd.Disconnected {
+			return &TableError{"CONNECTION_UNCHANGED", "Player connection state is unchanged"}
+		}
+		slot.Disconnected = cmd.Disconnected
+		slot.DisconnectedAt = time.Time{}
+		if cmd.Disconnected {
+			slot.DisconnectedAt = cmd.At
+		}
+		if engine, ok := table.GameEngine.(connectionAwareEngine); ok {
+			engine.SetPlayerDisconnected(cmd.PlayerID, cmd.Disconnected)
+		}
+		table.UpdatedAt = time.Now()
+		return nil
+	}
+	return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+}
+
+// SetPlayerConnected sends a connection state change to the table actor
+func (ta *TableActor) SetPlayerConnected(ctx context.Context, playerID string, disconnected bool, at time.Time) error {
+	cmd := &SetPlayerConnectedCommand{
+		PlayerID:     playerID,
+		Disconnected: disconnected,
+		At:           at,
+		Response:     make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetReconnectGrace changes how long disconnected players keep their seats;
+// zero frees them as soon as the connection drops
+func (tm *ActorTableManager) SetReconnectGrace(grace time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if grace >= 0 {
+		tm.reconnectGrace = grace
+	}
+}
+
+// SetReconnectMessenger sets how reconnecting players are sent the state of
+// the tables they kept their seats at
+func (tm *ActorTableManager) SetReconnectMessenger(messenger PlayerMessenger) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.reconnectMessenger = messenger
+}
+
+// seatedActors returns the actors of every table the player is seated at
+func (tm *ActorTableManager) seatedActors(playerID string) []*TableActor {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	actors := make([]*TableActor, 0)
+	for _, actor := range tm.actors {
+		if actor.table.IsPlayerAtTable(playerID) {
+			actors = append(actors, actor)
+		}
+	}
+	return actors
+}
+
+// PlayerDisconnected marks the player disconnected at every table they sit
+// at and frees those seats unless they reconnect within the grace period.
+// Meanwhile their chips stay in play and their turns are checked or folded
+// for them by the turn timer. Tournament seats are kept, since the entry
+// stays in play until the player is eliminated. It returns how many tables
+// were told.
+func (tm *ActorTableManager) PlayerDisconnected(ctx context.Context, playerID string) int {
+	tm.mu.RLock()
+	grace := tm.reconnectGrace
+	tm.mu.RUnlock()
+
+	now := time.Now()
+	marked := 0
+	for _, actor := range tm.seatedActors(playerID) {
+		if err := actor.SetPlayerConnected(ctx, playerID, true, now); err != nil {
+			continue
+		}
+		marked++
+		tm.BroadcastGameEvent(actor.table, &GameEvent{
+			Type:     "player_disconnected",
+			PlayerID: playerID,
+			Data: map[string]interface{}{
+				"table_id":      actor.table.ID,
+				"player_id":     playerID,
+				"grace_seconds": int(grace / time.Second),
+				"expires_at":    now.Add(grace),
+			},
+			Timestamp: now,
+		})
+	}
+	if marked == 0 {
+		return 0
+	}
+
+	tm.mu.Lock()
+	if timer, pending := tm.graceTimers[playerID]; pending {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(grace, func() {
+		tm.mu.Lock()
+		current := tm.graceTimers[playerID] == timer
+		if current {
+			delete(tm.graceTimers, playerID)
+		}
+		tm.mu.Unlock()
+		if current {
+			tm.freeDisconnectedSeats(context.Background(), playerID)
+		}
+	})
+	tm.graceTimers[playerID] = timer
+	tm.mu.Unlock()
+	return marked
+}
+
+// PlayerReconnected cancels a pending seat release and marks the player
+// connected again at every table still holding their seat, sending them each
+// table's full state to pick up from. It returns how many tables were told.
+func (tm *ActorTableManager) PlayerReconnected(ctx context.Context, playerID string) int {
+	tm.mu.Lock()
+	if timer, pending := tm.graceTimers[playerID]; pending {
+		timer.Stop()
+		delete(tm.graceTimers, playerID)
+	}
+	tm.mu.Unlock()
+
+	tm.mu.RLock()
+	messenger := tm.reconnectMessenger
+	tm.mu.RUnlock()
+
+	restored := 0
+	for _, actor := range tm.seatedActors(playerID) {
+		if err := actor.SetPlayerConnected(ctx, playerID, false, time.Time{}); err != nil {
+			continue
+		}
+		restored++
+		if messenger != nil {
+			msg := &WebSocketMessage{Type: "table_state_restored", Data: restoredTableState(actor.table, playerID), Success: true}
+			if err := messenger.SendToPlayer(playerID, msg); err != nil {
+				log.Printf("Failed to restore table %s for player %s: %v", actor.table.ID, playerID, err)
+			}
+		}
+		tm.BroadcastGameEvent(actor.table, &GameEvent{
+			Type:     "player_reconnected",
+			PlayerID: playerID,
+			Data: map[string]interface{}{
+				"table_id":  actor.table.ID,
+				"player_id": playerID,
+			},
+			Timestamp: time.Now(),
+		})
+	}
+	return restored
+}
+
+// restoredTableState is everything a reconnecting player needs to carry on
+// at a table: the table, the hand and their own cards. The room is the one to
+// rejoin for the table's updates.
+func restoredTableState(table *GameTable, playerID string) map[string]interface{} {
+	state := map[string]interface{}{
+		"table_id": table.ID,
+		"room_id":  table.RoomFor(playerID),
+		"table":    table.GetDetailedInfo(),
+	}
+	if table.GameEngine != nil {
+		state["game_state"] = table.GameEngine.GetGameState()
+		state["player_state"] = table.GameEngine.GetPlayerState(playerID)
+	}
+	return state
+}
+
+// freeDisconnectedSeats releases the cash game seats a player never came back to
+func (tm *ActorTableManager) freeDisconnectedSeats(ctx context.Context, playerID string) {
+	for _, actor := range tm.seatedActors(playerID) {
+		table := actor.table
+		if table.Settings.TournamentMode || !isDisconnected(table, playerID) {
+			continue
+		}
+		if err := tm.LeaveTable(ctx, &TableLeaveRequest{TableID: table.ID, PlayerID: playerID}); err != nil {
+			continue
+		}
+		tm.BroadcastGameEvent(table, &GameEvent{
+			Type:     "player_seat_freed",
+			PlayerID: playerID,
+			Data: map[string]interface{}{
+				"table_id":  table.ID,
+				"player_id": playerID,
+				"reason":    "disconnected",
+			},
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// isDisconnected reports whether a seated player's connection is gone
+func isDisconnected(table *GameTable, playerID string) bool {
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == playerID {
+			return slot.Disconnected
+		}
+	}
+	return false
+}
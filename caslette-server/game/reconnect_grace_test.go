@@ -0,0 +1,138 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (b *recordingBroadcaster) ofType(eventType string) []*GameEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	found := make([]*GameEvent, 0)
+	for _, event := range b.events {
+		if event.Type == eventType {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+func TestDisconnectedPlayerLosesSeatAfterGrace(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	manager.SetReconnectGrace(30 * time.Millisecond)
+	broadcaster := &recordingBroadcaster{}
+	manager.SetEventBroadcaster(broadcaster)
+	first := newBalancingTable(t, manager, "first", DefaultTableSettings(), 0)
+	second := newBalancingTable(t, manager, "second", DefaultTableSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p1", 0))
+	require.NoError(t, joinWithBuyIn(manager, second.ID, "p1", 0))
+	require.NoError(t, joinWithBuyIn(manager, first.ID, "p2", 0))
+
+	assert.Equal(t, 2, manager.PlayerDisconnected(context.Background(), "p1"))
+	assert.True(t, isDisconnected(first, "p1"))
+	assert.False(t, isDisconnected(first, "p2"))
+	disconnected := broadcaster.ofType("player_disconnected")
+	require.Len(t, disconnected, 2)
+	assert.Equal(t, 0, disconnected[0].Data["grace_seconds"])
+
+	require.Eventually(t, func() bool { return len(broadcaster.ofType("player_seat_freed")) == 2 }, time.Second, 5*time.Millisecond)
+	assert.False(t, first.IsPlayerAtTable("p1"), "seats are freed once the grace period runs out")
+	assert.False(t, second.IsPlayerAtTable("p1"))
+	assert.True(t, first.IsPlayerAtTable("p2"))
+}
+
+func TestReconnectingPlayerKeepsSeat(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	manager.SetReconnectGrace(40 * time.Millisecond)
+	broadcaster := &recordingBroadcaster{}
+	manager.SetEventBroadcaster(broadcaster)
+	table := newBalancingTable(t, manager, "held", DefaultTableSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 0))
+	ctx := context.Background()
+
+	require.Equal(t, 1, manager.PlayerDisconnected(ctx, "p1"))
+	assert.Equal(t, 1, manager.PlayerReconnected(ctx, "p1"))
+	assert.False(t, isDisconnected(table, "p1"))
+	assert.Len(t, broadcaster.ofType("player_reconnected"), 1)
+
+	time.Sleep(80 * time.Millisecond)
+	assert.True(t, table.IsPlayerAtTable("p1"), "the pending release was cancelled")
+	assert.Empty(t, broadcaster.ofType("player_seat_freed"))
+
+	assert.Zero(t, manager.PlayerReconnected(ctx, "p1"), "an online player is not announced again")
+	assert.Zero(t, manager.PlayerDisconnected(ctx, "stranger"), "players without seats are ignored")
+}
+
+func TestDisconnectKeepsTournamentSeat(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	manager.SetReconnectGrace(0)
+	settings := DefaultTableSettings()
+	settings.TournamentMode = true
+	table := newBalancingTable(t, manager, "tourney", settings, 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 0))
+
+	require.Equal(t, 1, manager.PlayerDisconnected(context.Background(), "p1"))
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, table.IsPlayerAtTable("p1"), "tournament entries stay in play until eliminated")
+	assert.True(t, isDisconnected(table, "p1"))
+}
+
+func TestDisconnectedPlayerIsTimedOutWithoutTurnTimer(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, time.Hour, "p0", "p1")
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	engine.offlineLimit = 30 * time.Millisecond
+	require.NoError(t, manager.tryStartGame(table))
+
+	engine.actionMu.Lock()
+	toAct := engine.getCurrentActionPlayerID()
+	engine.actionMu.Unlock()
+	require.Equal(t, 1, manager.PlayerDisconnected(context.Background(), toAct))
+
+	require.Eventually(t, func() bool { return len(broadcaster.ofType("turn_timed_out")) == 1 }, time.Second, 5*time.Millisecond,
+		"the hand does not wait for a player who is gone")
+	assert.Equal(t, toAct, broadcaster.ofType("turn_timed_out")[0].PlayerID)
+	assert.True(t, table.IsPlayerAtTable(toAct), "the seat and chips are held")
+}
+
+func TestDisconnectedPlayerSkipsTimeBank(t *testing.T) {
+	engine, events := newTimedHeadsUp(t, time.Hour)
+	engine.SetTimeBank(time.Hour)
+	engine.offlineLimit = 30 * time.Millisecond
+
+	engine.actionMu.Lock()
+	toAct := engine.getCurrentActionPlayerID()
+	engine.actionMu.Unlock()
+	engine.SetPlayerDisconnected(toAct, true)
+
+	waitForEvent(t, events, "turn_timed_out", forPlayer(toAct))
+	assert.Empty(t, events.find("time_bank_activated", nil), "the time bank is kept for when they return")
+	assert.Equal(t, time.Hour, engine.TimeBank(toAct))
+}
+
+func TestReconnectingPlayerIsSentTableState(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1")
+	messenger := newRecordingMessenger()
+	manager.SetReconnectMessenger(messenger)
+	require.NoError(t, manager.tryStartGame(table))
+	ctx := context.Background()
+
+	require.Equal(t, 1, manager.PlayerDisconnected(ctx, "p1"))
+	require.Equal(t, 1, manager.PlayerReconnected(ctx, "p1"))
+
+	assert.Equal(t, "p1", <-messenger.to)
+	msg := <-messenger.sent
+	assert.Equal(t, "table_state_restored", msg.Type)
+	state := msg.Data.(map[string]interface{})
+	assert.Equal(t, table.ID, state["table_id"])
+	assert.Equal(t, table.RoomID, state["room_id"])
+	assert.NotNil(t, state["game_state"])
+	playerState := state["player_state"].(map[string]interface{})
+	current := table.GameEngine.GetPlayerState("p1")
+	assert.NotEmpty(t, playerState["hand"])
+	assert.Equal(t, current["hand"], playerState["hand"], "their hole cards come back")
+	assert.Equal(t, current["chips"], playerState["chips"])
+}
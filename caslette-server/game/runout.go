@@ -0,0 +1,177 @@
+package game
+
+import (
+	"log"
+	"time"
+)
+
+// MaxRunoutDelay is the longest a table may pause before each street of an
+// all-in runout
+const MaxRunoutDelay = 10 * time.Second
+
+// RunoutPacing is how long an all-in runout pauses before dealing each
+// street, so players and spectators see the board come one street at a time.
+// Stud's fourth to sixth streets take the turn's delay and seventh street the
+// river's. Zero deals the street at once.
+type RunoutPacing struct {
+	Flop  time.Duration `json:"flop"`
+	Turn  time.Duration `json:"turn"`
+	River time.Duration `json:"river"`
+}
+
+// before returns the pause before dealing a street
+func (p RunoutPacing) before(street TexasHoldemState) time.Duration {
+	switch street {
+	case Flop:
+		return p.Flop
+	case Turn, FourthStreet, FifthStreet, SixthStreet:
+		return p.Turn
+	case River, SeventhStreet:
+		return p.River
+	}
+	return 0
+}
+
+// tableRunoutPacing returns the runout pacing a table's settings ask for
+func tableRunoutPacing(settings TableSettings) RunoutPacing {
+	return RunoutPacing{
+		Flop:  time.Duration(settings.RunoutFlopDelayMs) * time.Millisecond,
+		Turn:  time.Duration(settings.RunoutTurnDelayMs) * time.Millisecond,
+		River: time.Duration(settings.RunoutRiverDelayMs) * time.Millisecond,
+	}
+}
+
+// runoutTimer waits to deal the next street of a runout
+type runoutTimer struct {
+	stop chan struct{}
+}
+
+// SetRunoutPacing sets the pauses between the streets of an all-in runout
+func (the *TexasHoldemEngine) SetRunoutPacing(pacing RunoutPacing) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	the.runoutPacing = pacing
+}
+
+// needsRunout reports whether the rest of the board is dealt without
+// betting: two or more players are still in the hand but no more than one of
+// them can bet, and nobody owes chips to the pot
+func (the *TexasHoldemEngine) needsRunout() bool {
+	if the.GetState() != GameStateInProgress || the.roundState == Showdown {
+		return false
+	}
+	players, bettors := 0, 0
+	for _, player := range the.getActivePlayers() {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		players++
+		if holdemPlayer.IsAllIn {
+			continue
+		}
+		if holdemPlayer.CurrentBet < the.currentBet {
+			return false
+		}
+		bettors++
+	}
+	return players >= 2 && bettors < 2
+}
+
+// nextStreet returns the street dealt after the current one
+func (the *TexasHoldemEngine) nextStreet() TexasHoldemState {
+	if street, ok := studStreets[the.roundState]; ok {
+		return street.next
+	}
+	switch the.roundState {
+	case PreFlop:
+		return Flop
+	case Flop:
+		return Turn
+	case Turn:
+		return River
+	}
+	return Showdown
+}
+
+// beginRunout announces that the hand is being run out and starts dealing
+// it; the caller must hold actionMu
+func (the *TexasHoldemEngine) beginRunout() error {
+	the.stopTurnTimer()
+	the.emitEvent(&GameEvent{
+		Type: "runout_started",
+		Data: map[string]interface{}{
+			"roundState": the.roundState,
+			"pot":        the.pot,
+			"delays": map[string]int64{
+				"flop":  the.runoutPacing.Flop.Milliseconds(),
+				"turn":  the.runoutPacing.Turn.Milliseconds(),
+				"river": the.runoutPacing.River.Milliseconds(),
+			},
+		},
+	})
+	return the.advanceRunout()
+}
+
+// advanceRunout deals streets until one is due a pause, which is waited out
+// on a timer; the caller must hold actionMu
+func (the *TexasHoldemEngine) advanceRunout() error {
+	the.stopRunout()
+	for the.needsRunout() {
+		if delay := the.runoutPacing.before(the.nextStreet()); delay > 0 {
+			runout := &runoutTimer{stop: make(chan struct{})}
+			the.runout = runout
+			go the.runRunout(runout, delay)
+			return nil
+		}
+		if err := the.nextBettingRound(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runRunout deals the next street of a runout once its pause is over
+func (the *TexasHoldemEngine) runRunout(runout *runoutTimer, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-runout.stop:
+		return
+	case <-timer.C:
+	}
+
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if the.runout != runout {
+		return
+	}
+	the.runout = nil
+	if !the.needsRunout() {
+		return
+	}
+	if err := the.nextBettingRound(); err != nil {
+		log.Printf("TexasHoldemEngine: runout failed: %v", err)
+		return
+	}
+	if err := the.advanceRunout(); err != nil {
+		log.Printf("TexasHoldemEngine: runout failed: %v", err)
+	}
+}
+
+// stopRunout cancels a runout waiting to deal; the caller must hold actionMu
+func (the *TexasHoldemEngine) stopRunout() {
+	if the.runout == nil {
+		return
+	}
+	close(the.runout.stop)
+	the.runout = nil
+}
+
+// continueHand picks the hand back up after a pause or restart: the runout
+// carries on, or the player to act gets a fresh timer. The caller must hold
+// actionMu.
+func (the *TexasHoldemEngine) continueHand() error {
+	if the.needsRunout() {
+		return the.advanceRunout()
+	}
+	the.startTurnTimer()
+	return nil
+}
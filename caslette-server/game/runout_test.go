@@ -0,0 +1,85 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRunoutTable starts a hand between the players at seats 1, 2...
+func newRunoutTable(t *testing.T, pacing RunoutPacing, players ...string) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("runout-game")
+	engine.SetRunoutPacing(pacing)
+	for i, playerID := range players {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+	require.NoError(t, engine.Start())
+	t.Cleanup(func() { engine.Pause() })
+	return engine
+}
+
+// boardDealt returns the street and board cards, safe to read while a
+// runout deals on its own goroutine
+func boardDealt(engine *TexasHoldemEngine) (TexasHoldemState, int) {
+	engine.actionMu.Lock()
+	defer engine.actionMu.Unlock()
+	return engine.roundState, len(engine.communityCards.Cards)
+}
+
+// runoutEvents lists the runout and street events the engine has emitted
+func runoutEvents(engine *TexasHoldemEngine) []string {
+	engine.actionMu.Lock()
+	defer engine.actionMu.Unlock()
+	types := make([]string, 0)
+	for _, event := range engine.GetEvents() {
+		switch event.Type {
+		case "runout_started", "flop_dealt", "turn_dealt", "river_dealt", "showdown":
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+func TestAllInRunsBoardOutWithoutPacing(t *testing.T) {
+	engine := newRunoutTable(t, RunoutPacing{}, "1", "2")
+	require.NoError(t, act(engine, ActionAllIn, 0))
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	assert.Equal(t, GameStateFinished, engine.GetState(), "nobody can bet, so the hand is dealt to the showdown")
+	assert.Len(t, engine.communityCards.Cards, 5)
+	assert.Equal(t, []string{"runout_started", "flop_dealt", "turn_dealt", "river_dealt", "showdown"}, runoutEvents(engine))
+}
+
+func TestAllInRunoutIsPaced(t *testing.T) {
+	pacing := RunoutPacing{Flop: 40 * time.Millisecond, Turn: 40 * time.Millisecond, River: 40 * time.Millisecond}
+	engine := newRunoutTable(t, pacing, "1", "2")
+	require.NoError(t, act(engine, ActionAllIn, 0))
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	street, cards := boardDealt(engine)
+	assert.Equal(t, PreFlop, street, "the flop waits for its delay")
+	assert.Zero(t, cards)
+	require.Eventually(t, func() bool { _, cards := boardDealt(engine); return cards == 3 }, time.Second, time.Millisecond)
+
+	require.NoError(t, engine.Pause())
+	time.Sleep(100 * time.Millisecond)
+	_, cards = boardDealt(engine)
+	assert.Equal(t, 3, cards, "a paused game stops the runout")
+
+	require.NoError(t, engine.Resume())
+	require.Eventually(t, func() bool { street, _ := boardDealt(engine); return street == Showdown }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"runout_started", "flop_dealt", "turn_dealt", "river_dealt", "showdown"}, runoutEvents(engine))
+}
+
+func TestRunoutWithOneBettorLeft(t *testing.T) {
+	engine := newRunoutTable(t, RunoutPacing{}, "1", "2", "3")
+	require.NoError(t, act(engine, ActionAllIn, 0))
+	require.NoError(t, act(engine, ActionCall, 0))
+	assert.False(t, engine.needsRunout(), "the last player still owes the all-in")
+	require.NoError(t, act(engine, ActionFold, 0))
+
+	assert.Equal(t, GameStateFinished, engine.GetState(), "a lone bettor has nobody left to bet against")
+	assert.Len(t, engine.communityCards.Cards, 5)
+}
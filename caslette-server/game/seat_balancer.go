@@ -0,0 +1,276 @@
+package game
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Seat balancing defaults
+const (
+	DefaultShortHandedThreshold = 3           // Tables with this many players or fewer are short-handed
+	DefaultBalanceOfferTTL      = time.Minute // How long volunteers have to accept an offer
+)
+
+// BalanceOffer invites players at a short-handed table to move to another
+// short-handed table at the same stakes. Moving is always opt-in.
+type BalanceOffer struct {
+	ID          string    `json:"offer_id"`
+	FromTableID string    `json:"from_table_id"`
+	ToTableID   string    `json:"to_table_id"`
+	ToTableName string    `json:"to_table_name"`
+	Stakes      string    `json:"stakes"`
+	OpenSeats   int       `json:"open_seats"`
+	ExpiresAt   time.Time `json:"expires_at"`
+
+	fromRoomID string
+}
+
+// SeatBalancer consolidates short-handed cash tables by offering their players
+// a move to the busiest short-handed table at the same stakes
+type SeatBalancer struct {
+	tableManager *ActorTableManager
+	hub          WebSocketHub
+	threshold    int
+	offerTTL     time.Duration
+	now          func() time.Time
+
+	mu     sync.Mutex
+	offers map[string]*BalanceOffer
+}
+
+// NewSeatBalancer creates a balancer using the default threshold and offer lifetime
+func NewSeatBalancer(tableManager *ActorTableManager, hub WebSocketHub) *SeatBalancer {
+	return &SeatBalancer{
+		tableManager: tableManager,
+		hub:          hub,
+		threshold:    DefaultShortHandedThreshold,
+		offerTTL:     DefaultBalanceOfferTTL,
+		now:          time.Now,
+		offers:       make(map[string]*BalanceOffer),
+	}
+}
+
+// SetShortHandedThreshold changes the player count at or below which tables are balanced
+func (sb *SeatBalancer) SetShortHandedThreshold(threshold int) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if threshold > 0 {
+		sb.threshold = threshold
+	}
+}
+
+// balanceKey groups tables that players can be moved between. Only public
+// cash tables take part.
+func balanceKey(table *GameTable) (string, bool) {
+	if table.Settings.TournamentMode || table.Settings.Private || table.Settings.BotsAllowed {
+		return "", false
+	}
+	if table.Status == TableStatusClosed || table.Status == TableStatusFinished {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%s:%s", table.GameType, table.GetCurrency(), tableStakes(table)), true
+}
+
+// tableStakes formats a table's blinds, e.g. "10/20"
+func tableStakes(table *GameTable) string {
+	return fmt.Sprintf("%d/%d", table.Settings.SmallBlind, table.Settings.BigBlind)
+}
+
+// balanceMoves pairs each short-handed table with the busiest short-handed
+// table at the same stakes that still has open seats
+func (sb *SeatBalancer) balanceMoves(threshold int) []BalanceOffer {
+	groups := make(map[string][]*GameTable)
+	for _, table := range sb.tableManager.GetTables() {
+		count := table.GetPlayerCount()
+		if count == 0 || count > threshold {
+			continue
+		}
+		if key, ok := balanceKey(table); ok {
+			groups[key] = append(groups[key], table)
+		}
+	}
+
+	var moves []BalanceOffer
+	for _, tables := range groups {
+		if len(tables) < 2 {
+			continue
+		}
+		// Busiest first, oldest breaking ties, so players converge on one table
+		sort.Slice(tables, func(i, j int) bool {
+			ci, cj := tables[i].GetPlayerCount(), tables[j].GetPlayerCount()
+			if ci != cj {
+				return ci > cj
+			}
+			if !tables[i].CreatedAt.Equal(tables[j].CreatedAt) {
+				return tables[i].CreatedAt.Before(tables[j].CreatedAt)
+			}
+			return tables[i].ID < tables[j].ID
+		})
+
+		var target *GameTable
+		for _, table := range tables {
+			if (table.Status == TableStatusWaiting || table.Status == TableStatusPaused) &&
+				table.GetPlayerCount() < table.MaxPlayers {
+				target = table
+				break
+			}
+		}
+		if target == nil {
+			continue
+		}
+
+		for _, source := range tables {
+			if source.ID == target.ID {
+				continue
+			}
+			moves = append(moves, BalanceOffer{
+				FromTableID: source.ID,
+				ToTableID:   target.ID,
+				ToTableName: target.Name,
+				Stakes:      tableStakes(target),
+				OpenSeats:   target.MaxPlayers - target.GetPlayerCount(),
+				fromRoomID:  source.RoomID,
+			})
+		}
+	}
+
+	sort.Slice(moves, func(i, j int) bool { return moves[i].FromTableID < moves[j].FromTableID })
+	return moves
+}
+
+// Evaluate drops stale offers and offers a move to every short-handed table
+// that has a better-populated table at the same stakes. New offers are
+// broadcast to the source table's room.
+func (sb *SeatBalancer) Evaluate() []*BalanceOffer {
+	sb.mu.Lock()
+	now := sb.now()
+	moves := sb.balanceMoves(sb.threshold)
+
+	wanted := make(map[string]BalanceOffer, len(moves))
+	for _, move := range moves {
+		wanted[move.FromTableID] = move
+	}
+	existing := make(map[string]bool)
+	for id, offer := range sb.offers {
+		move, ok := wanted[offer.FromTableID]
+		if !ok || move.ToTableID != offer.ToTableID || !now.Before(offer.ExpiresAt) {
+			delete(sb.offers, id)
+			continue
+		}
+		offer.OpenSeats = move.OpenSeats
+		existing[offer.FromTableID] = true
+	}
+
+	created := make([]*BalanceOffer, 0)
+	for _, move := range moves {
+		if existing[move.FromTableID] {
+			continue
+		}
+		offer := move
+		offer.ID = generateBalanceOfferID()
+		offer.ExpiresAt = now.Add(sb.offerTTL)
+		sb.offers[offer.ID] = &offer
+		created = append(created, &offer)
+	}
+	sb.mu.Unlock()
+
+	for _, offer := range created {
+		sb.broadcast(offer.fromRoomID, "seat_balance_offer", offer)
+	}
+	return created
+}
+
+// Offers returns the active offers for a table
+func (sb *SeatBalancer) Offers(tableID string) []BalanceOffer {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	now := sb.now()
+	offers := make([]BalanceOffer, 0)
+	for _, offer := range sb.offers {
+		if offer.FromTableID == tableID && now.Before(offer.ExpiresAt) {
+			offers = append(offers, *offer)
+		}
+	}
+	return offers
+}
+
+// Accept moves a volunteer seated at the offer's source table to its
+// destination. The offer stays open for other volunteers while seats remain.
+func (sb *SeatBalancer) Accept(ctx context.Context, offerID, playerID, username string) (*GameTable, error) {
+	sb.mu.Lock()
+	offer, exists := sb.offers[offerID]
+	if exists && !sb.now().Before(offer.ExpiresAt) {
+		delete(sb.offers, offerID)
+		exists = false
+	}
+	var fromTableID, toTableID string
+	if exists {
+		fromTableID, toTableID = offer.FromTableID, offer.ToTableID
+	}
+	sb.mu.Unlock()
+
+	if !exists {
+		return nil, &TableError{"OFFER_NOT_FOUND", "Balance offer not found or expired"}
+	}
+
+	from, err := sb.tableManager.GetTable(fromTableID)
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsPlayerAtTable(playerID) {
+		return nil, &TableError{"NOT_AT_TABLE", "Only players at the short-handed table can accept"}
+	}
+	to, err := sb.tableManager.GetTable(toTableID)
+	if err != nil {
+		return nil, err
+	}
+	fromKey, _ := balanceKey(from)
+	if toKey, ok := balanceKey(to); !ok || toKey != fromKey {
+		return nil, &TableError{"OFFER_STALE", "Destination table no longer matches these stakes"}
+	}
+
+	if err := sb.tableManager.MovePlayer(ctx, playerID, username, fromTableID, toTableID); err != nil {
+		return nil, err
+	}
+
+	sb.mu.Lock()
+	if from.GetPlayerCount() == 0 || to.GetPlayerCount() >= to.MaxPlayers {
+		delete(sb.offers, offerID)
+	}
+	sb.mu.Unlock()
+
+	moved := map[string]interface{}{
+		"player_id":     playerID,
+		"username":      username,
+		"from_table_id": fromTableID,
+		"to_table_id":   toTableID,
+	}
+	sb.broadcast(from.RoomID, "seat_balanced", moved)
+	sb.broadcast(to.RoomID, "seat_balanced", moved)
+
+	return to, nil
+}
+
+// broadcast sends a balancing notice to a table room
+func (sb *SeatBalancer) broadcast(roomID, msgType string, data interface{}) {
+	if sb.hub == nil {
+		return
+	}
+	msg := &WebSocketMessage{Type: msgType, Data: data, Room: roomID}
+	if err := sb.hub.BroadcastToRoom(roomID, msg); err != nil {
+		log.Printf("SeatBalancer: failed to broadcast to room %s: %v", roomID, err)
+	}
+}
+
+// generateBalanceOfferID creates a random offer ID
+func generateBalanceOfferID() string {
+	bytes := make([]byte, 8)
+	rand.Read(bytes)
+	return "bal_" + hex.EncodeToString(bytes)
+}
@@ -0,0 +1,128 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBalancingTable(t *testing.T, manager *ActorTableManager, name string, settings TableSettings, players int) *GameTable {
+	ctx := context.Background()
+	table, err := manager.CreateTable(ctx, &TableCreateRequest{
+		Name: name, GameType: GameTypeTexasHoldem, CreatedBy: "creator_" + name, Username: "creator_" + name, Settings: settings,
+	})
+	require.NoError(t, err)
+	for i := 0; i < players; i++ {
+		playerID := fmt.Sprintf("%s_p%d", name, i)
+		require.NoError(t, manager.JoinTable(ctx, &TableJoinRequest{
+			TableID: table.ID, PlayerID: playerID, Username: playerID, Mode: JoinModePlayer,
+		}))
+	}
+	return table
+}
+
+func TestSeatBalancerOffersMoveToBusiestTable(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	hub := newRecordingHub()
+	balancer := NewSeatBalancer(manager, hub)
+
+	busy := newBalancingTable(t, manager, "busy", DefaultTableSettings(), 3)
+	quiet := newBalancingTable(t, manager, "quiet", DefaultTableSettings(), 2)
+	newBalancingTable(t, manager, "full", DefaultTableSettings(), 6)
+	newBalancingTable(t, manager, "other_stakes", QuickGameSettings(), 2)
+	newBalancingTable(t, manager, "private", PrivateTableSettings("secret"), 0)
+
+	offers := balancer.Evaluate()
+	require.Len(t, offers, 1)
+	assert.Equal(t, quiet.ID, offers[0].FromTableID)
+	assert.Equal(t, busy.ID, offers[0].ToTableID)
+	assert.Equal(t, "10/20", offers[0].Stakes)
+	assert.Equal(t, busy.MaxPlayers-3, offers[0].OpenSeats)
+
+	hub.mu.Lock()
+	messages := hub.messages[quiet.RoomID]
+	hub.mu.Unlock()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "seat_balance_offer", messages[0].(*WebSocketMessage).Type)
+
+	// Re-evaluating keeps the open offer instead of prompting again
+	assert.Empty(t, balancer.Evaluate())
+	assert.Len(t, balancer.Offers(quiet.ID), 1)
+}
+
+func TestSeatBalancerIgnoresTournamentsAndHealthyTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	balancer := NewSeatBalancer(manager, nil)
+
+	newBalancingTable(t, manager, "tourney1", TournamentSettings(), 2)
+	newBalancingTable(t, manager, "tourney2", TournamentSettings(), 2)
+	newBalancingTable(t, manager, "healthy", DefaultTableSettings(), 5)
+	newBalancingTable(t, manager, "short", DefaultTableSettings(), 2)
+
+	assert.Empty(t, balancer.Evaluate())
+}
+
+func TestSeatBalancerAcceptMovesVolunteer(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	balancer := NewSeatBalancer(manager, newRecordingHub())
+	ctx := context.Background()
+
+	busy := newBalancingTable(t, manager, "busy", DefaultTableSettings(), 3)
+	quiet := newBalancingTable(t, manager, "quiet", DefaultTableSettings(), 2)
+
+	offers := balancer.Evaluate()
+	require.Len(t, offers, 1)
+
+	_, err := balancer.Accept(ctx, offers[0].ID, "busy_p0", "busy_p0")
+	assert.Error(t, err, "only players at the source table may accept")
+
+	table, err := balancer.Accept(ctx, offers[0].ID, "quiet_p0", "quiet_p0")
+	require.NoError(t, err)
+	assert.Equal(t, busy.ID, table.ID)
+	assert.True(t, busy.IsPlayerAtTable("quiet_p0"))
+	assert.False(t, quiet.IsPlayerAtTable("quiet_p0"))
+	assert.Len(t, balancer.Offers(quiet.ID), 1, "offer stays open for the remaining player")
+
+	_, err = balancer.Accept(ctx, offers[0].ID, "quiet_p1", "quiet_p1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, quiet.GetPlayerCount())
+	assert.Empty(t, balancer.Offers(quiet.ID), "offer closes once the source table is empty")
+}
+
+func TestSeatBalancerOffersExpire(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	balancer := NewSeatBalancer(manager, nil)
+	now := time.Now()
+	balancer.now = func() time.Time { return now }
+
+	newBalancingTable(t, manager, "busy", DefaultTableSettings(), 3)
+	newBalancingTable(t, manager, "quiet", DefaultTableSettings(), 2)
+
+	offers := balancer.Evaluate()
+	require.Len(t, offers, 1)
+
+	now = now.Add(DefaultBalanceOfferTTL)
+	_, err := balancer.Accept(context.Background(), offers[0].ID, "quiet_p0", "quiet_p0")
+	assert.Error(t, err)
+
+	// A fresh prompt goes out once the old one has lapsed
+	assert.Len(t, balancer.Evaluate(), 1)
+}
+
+func TestMovePlayerRestoresSeatOnFailure(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ctx := context.Background()
+
+	from := newBalancingTable(t, manager, "from", DefaultTableSettings(), 2)
+	to := newBalancingTable(t, manager, "dest", DefaultTableSettings(), 1)
+	to.Status = TableStatusActive
+
+	position := from.GetPlayerPosition("from_p1")
+	assert.Error(t, manager.MovePlayer(ctx, "from_p1", "from_p1", from.ID, to.ID))
+	assert.Equal(t, position, from.GetPlayerPosition("from_p1"))
+	assert.False(t, to.IsPlayerAtTable("from_p1"))
+}
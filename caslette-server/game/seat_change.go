@@ -0,0 +1,317 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultSeatReservation is how long a seat picked from the lobby is held
+// for the player to finish buying in
+const DefaultSeatReservation = 15 * time.Second
+
+// SeatReservation holds a seat for a player about to buy in. Position is
+// 1-based, as clients give it when joining.
+type SeatReservation struct {
+	TableID   string    `json:"table_id"`
+	PlayerID  string    `json:"player_id"`
+	Position  int       `json:"position"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// heldFor reports whether the empty seat is reserved for someone other than
+// playerID
+func (s PlayerSlot) heldFor(playerID string, now time.Time) bool {
+	return s.ReservedFor != "" && s.ReservedFor != playerID && now.Before(s.ReservedUntil)
+}
+
+// openTo reports whether playerID may sit in the seat
+func (s PlayerSlot) openTo(playerID string, now time.Time) bool {
+	return s.PlayerID == "" && !s.heldFor(playerID, now)
+}
+
+// releaseReservations drops reservations that have run out and, when
+// playerID is set, the ones held for that player
+func (t *GameTable) releaseReservations(playerID string, now time.Time) {
+	for i := range t.PlayerSlots {
+		slot := &t.PlayerSlots[i]
+		if slot.ReservedFor == "" {
+			continue
+		}
+		if slot.ReservedFor == playerID || !now.Before(slot.ReservedUntil) {
+			slot.ReservedFor = ""
+			slot.ReservedUntil = time.Time{}
+		}
+	}
+}
+
+// reservedSeat returns the seat held for the player, or -1
+func (t *GameTable) reservedSeat(playerID string, now time.Time) int {
+	for _, slot := range t.PlayerSlots {
+		if slot.PlayerID == "" && slot.ReservedFor == playerID && now.Before(slot.ReservedUntil) {
+			return slot.Position
+		}
+	}
+	return -1
+}
+
+// handInProgress reports whether the table's engine is in the middle of a
+// hand
+func (t *GameTable) handInProgress() bool {
+	if t.GameEngine == nil {
+		return false
+	}
+	state := t.GameEngine.GetState()
+	return state == GameStateInProgress || state == GameStatePaused
+}
+
+// ReserveSeatCommand holds an empty seat for a player who is not seated,
+// replacing any seat already held for them. Position is 1-based; zero takes
+// the first open seat.
+type ReserveSeatCommand struct {
+	PlayerID string
+	Position int
+	Until    time.Time
+	Response chan interface{}
+}
+
+func (cmd *ReserveSeatCommand) Execute(table *GameTable) interface{} {
+	if table.Status == TableStatusClosed {
+		return &TableError{"TABLE_CLOSED", "Table is closed"}
+	}
+	if table.IsPlayerAtTable(cmd.PlayerID) {
+		return ErrPlayerAlreadyAtTable
+	}
+
+	now := time.Now()
+	table.releaseReservations(cmd.PlayerID, now)
+
+	position := -1
+	if cmd.Position <= 0 {
+		for _, slot := range table.PlayerSlots {
+			if slot.openTo(cmd.PlayerID, now) {
+				position = slot.Position
+				break
+			}
+		}
+		if position == -1 {
+			return &TableError{"TABLE_FULL", "No available positions"}
+		}
+	} else {
+		position = cmd.Position - 1
+		if position >= len(table.PlayerSlots) {
+			return &TableError{"INVALID_POSITION", "Invalid position"}
+		}
+		if table.PlayerSlots[position].PlayerID != "" {
+			return &TableError{"POSITION_OCCUPIED", "Position is already occupied"}
+		}
+		if table.PlayerSlots[position].heldFor(cmd.PlayerID, now) {
+			return &TableError{"SEAT_RESERVED", "Seat is reserved for another player"}
+		}
+	}
+
+	slot := &table.PlayerSlots[position]
+	slot.ReservedFor = cmd.PlayerID
+	slot.ReservedUntil = cmd.Until
+	table.UpdatedAt = now
+	return *slot
+}
+
+// ReserveSeat sends a seat reservation to the table actor
+func (ta *TableActor) ReserveSeat(ctx context.Context, playerID string, position int, until time.Time) (PlayerSlot, error) {
+	cmd := &ReserveSeatCommand{
+		PlayerID: playerID,
+		Position: position,
+		Until:    until,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return PlayerSlot{}, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return PlayerSlot{}, err
+		}
+		if slot, ok := result.(PlayerSlot); ok {
+			return slot, nil
+		}
+		return PlayerSlot{}, fmt.Errorf("unexpected response type")
+	case <-ctx.Done():
+		return PlayerSlot{}, ctx.Err()
+	}
+}
+
+// ChangeSeatCommand moves a seated player to an empty seat between hands.
+// Position is 1-based.
+type ChangeSeatCommand struct {
+	PlayerID string
+	Position int
+	Response chan interface{}
+}
+
+func (cmd *ChangeSeatCommand) Execute(table *GameTable) interface{} {
+	if table.handInProgress() {
+		return &TableError{"HAND_IN_PROGRESS", "Seats can only be changed between hands"}
+	}
+
+	from := -1
+	for i, slot := range table.PlayerSlots {
+		if slot.PlayerID == cmd.PlayerID {
+			from = i
+			break
+		}
+	}
+	if from == -1 {
+		return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+	}
+
+	to := cmd.Position - 1
+	if to < 0 || to >= len(table.PlayerSlots) {
+		return &TableError{"INVALID_POSITION", "Invalid position"}
+	}
+	if to == from {
+		return &TableError{"SAME_SEAT", "Player is already in that seat"}
+	}
+	now := time.Now()
+	if !table.PlayerSlots[to].openTo(cmd.PlayerID, now) {
+		if table.PlayerSlots[to].PlayerID != "" {
+			return &TableError{"POSITION_OCCUPIED", "Position is already occupied"}
+		}
+		return &TableError{"SEAT_RESERVED", "Seat is reserved for another player"}
+	}
+
+	moved := table.PlayerSlots[from]
+	moved.Position = table.PlayerSlots[to].Position
+	moved.ReservedFor, moved.ReservedUntil = "", time.Time{}
+	table.PlayerSlots[to] = moved
+	table.PlayerSlots[from] = PlayerSlot{Position: table.PlayerSlots[from].Position}
+	table.UpdatedAt = now
+	return moved
+}
+
+// ChangeSeat sends a seat change to the table actor
+func (ta *TableActor) ChangeSeat(ctx context.Context, playerID string, position int) (PlayerSlot, error) {
+	cmd := &ChangeSeatCommand{
+		PlayerID: playerID,
+		Position: position,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return PlayerSlot{}, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return PlayerSlot{}, err
+		}
+		if slot, ok := result.(PlayerSlot); ok {
+			return slot, nil
+		}
+		return PlayerSlot{}, fmt.Errorf("unexpected response type")
+	case <-ctx.Done():
+		return PlayerSlot{}, ctx.Err()
+	}
+}
+
+// MoveSeats moves players to other seats between hands, all at once so two
+// players may trade places, and the button and blinds follow them there from
+// the next hand. Seats maps player IDs to their new seats.
+func (the *TexasHoldemEngine) MoveSeats(seats map[string]int) error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+
+	if state := the.GetState(); state == GameStateInProgress || state == GameStatePaused {
+		return fmt.Errorf("a hand is in progress")
+	}
+	taken := make(map[int]string, len(the.players))
+	for _, player := range the.players {
+		seat := player.Position
+		if moved, ok := seats[player.ID]; ok {
+			seat = moved
+		}
+		if other, clash := taken[seat]; clash {
+			return fmt.Errorf("seat %d is taken by %s", seat, other)
+		}
+		taken[seat] = player.ID
+	}
+	for _, player := range the.players {
+		if moved, ok := seats[player.ID]; ok {
+			player.Position = moved
+		}
+	}
+	return nil
+}
+
+// SetSeatReservation changes how long seats picked from the lobby are held;
+// non-positive values keep the default
+func (tm *ActorTableManager) SetSeatReservation(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultSeatReservation
+	}
+	tm.mu.Lock()
+	tm.seatReservation = ttl
+	tm.mu.Unlock()
+}
+
+// ReserveSeat holds a seat at the table for a player picking it from the
+// lobby, so nobody else takes it while they buy in. It is checked like a
+// join: private tables want their password and tables awaiting approval
+// seat only their creator. Position is 1-based; zero takes the first open
+// seat.
+func (tm *ActorTableManager) ReserveSeat(ctx context.Context, tableID, playerID string, position int, password string) (*SeatReservation, error) {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	ttl := tm.seatReservation
+	tm.mu.RUnlock()
+	if !exists {
+		return nil, ErrTableNotFound
+	}
+
+	table := actor.table
+	if tm.passwordRequired(table, playerID) && !table.CheckPassword(password) {
+		return nil, &TableError{"INVALID_PASSWORD", "Incorrect password for private table"}
+	}
+	if table.AwaitingApproval() && playerID != table.CreatedBy {
+		return nil, &TableError{"APPROVAL_PENDING", "Table is waiting for admin approval"}
+	}
+
+	slot, err := actor.ReserveSeat(ctx, playerID, position, time.Now().Add(ttl))
+	if err != nil {
+		return nil, err
+	}
+	return &SeatReservation{
+		TableID:   tableID,
+		PlayerID:  playerID,
+		Position:  slot.Position + 1,
+		ExpiresAt: slot.ReservedUntil,
+	}, nil
+}
+
+// ChangeSeat moves a seated player to an empty seat between hands.
+// Position is 1-based.
+func (tm *ActorTableManager) ChangeSeat(ctx context.Context, tableID, playerID string, position int) (PlayerSlot, error) {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return PlayerSlot{}, ErrTableNotFound
+	}
+
+	slot, err := actor.ChangeSeat(ctx, playerID, position)
+	if err != nil {
+		return PlayerSlot{}, err
+	}
+	tm.tableChanged(actor)
+	return slot, nil
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func joinAt(manager *ActorTableManager, tableID, playerID string, position int) error {
+	return manager.JoinTable(context.Background(), &TableJoinRequest{
+		TableID: tableID, PlayerID: playerID, Username: playerID, Mode: JoinModePlayer, Position: position,
+	})
+}
+
+func seatOf(table *GameTable, playerID string) int {
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == playerID {
+			return slot.Position + 1
+		}
+	}
+	return 0
+}
+
+func TestReservedSeatIsHeldForItsPlayer(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	table := newBalancingTable(t, manager, "reserved", DefaultTableSettings(), 0)
+	ctx := context.Background()
+
+	reservation, err := manager.ReserveSeat(ctx, table.ID, "alice", 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, reservation.Position)
+	assert.WithinDuration(t, time.Now().Add(DefaultSeatReservation), reservation.ExpiresAt, time.Second)
+	assert.NotContains(t, table.GetAvailableSlots(), 0, "the lobby no longer offers the seat")
+
+	err = joinAt(manager, table.ID, "bob", 1)
+	require.Error(t, err)
+	assert.Equal(t, "SEAT_RESERVED", err.(*TableError).Code)
+	_, err = manager.ReserveSeat(ctx, table.ID, "carol", 1, "")
+	assert.Error(t, err, "nobody else can reserve it either")
+	require.NoError(t, joinAt(manager, table.ID, "bob", 0))
+	assert.Equal(t, 2, seatOf(table, "bob"), "others are seated around it")
+
+	require.NoError(t, joinAt(manager, table.ID, "alice", 0))
+	assert.Equal(t, 1, seatOf(table, "alice"), "the player joining takes the seat held for them")
+	for _, slot := range table.PlayerSlots {
+		assert.Empty(t, slot.ReservedFor, "the reservation is used up")
+	}
+	_, err = manager.ReserveSeat(ctx, table.ID, "alice", 0, "")
+	assert.Equal(t, ErrPlayerAlreadyAtTable, err)
+}
+
+func TestSeatReservationRunsOut(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	manager.SetSeatReservation(time.Millisecond)
+	table := newBalancingTable(t, manager, "expiring", DefaultTableSettings(), 0)
+
+	_, err := manager.ReserveSeat(context.Background(), table.ID, "alice", 3, "")
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, joinAt(manager, table.ID, "bob", 3), "the seat is released once the reservation runs out")
+}
+
+func TestSeatReservationChecksPrivateTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	table := newBalancingTable(t, manager, "private", PrivateTableSettings("secret"), 0)
+
+	_, err := manager.ReserveSeat(context.Background(), table.ID, "alice", 0, "guess")
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_PASSWORD", err.(*TableError).Code)
+	_, err = manager.ReserveSeat(context.Background(), table.ID, "alice", 0, "secret")
+	assert.NoError(t, err)
+}
+
+func TestChangeSeatBetweenHands(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1", "p2")
+	hub := &MockWebSocketHub{}
+	handler := NewTableWebSocketHandler(manager, hub)
+	t.Cleanup(handler.events.Stop)
+	changeSeat := func(playerID string, position int) *WebSocketMessage {
+		return handler.GetMessageHandlers()["table_change_seat"](context.Background(), NewMockConnection(playerID, playerID),
+			&WebSocketMessage{Type: "table_change_seat", RequestID: "r1", Data: map[string]interface{}{"table_id": table.ID, "position": position}})
+	}
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	response := changeSeat("p0", 6)
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Error, "between hands")
+
+	foldHand(t, engine)
+	assert.False(t, changeSeat("p0", seatOf(table, "p1")).Success, "only empty seats")
+	assert.False(t, changeSeat("stranger", 6).Success)
+
+	hub.broadcastCalls = nil
+	response = changeSeat("p0", 6)
+	require.True(t, response.Success, response.Error)
+	assert.Equal(t, "seat_changed", response.Type)
+	assert.Equal(t, 6, seatOf(table, "p0"))
+	assert.Equal(t, "", table.PlayerSlots[0].PlayerID, "the old seat is free")
+	require.Len(t, hub.broadcastCalls, 1)
+	assert.Equal(t, "player_changed_seat", hub.broadcastCalls[0].Message.(*WebSocketMessage).Type)
+
+	manager.mu.RLock()
+	actor := manager.actors[table.ID]
+	manager.mu.RUnlock()
+	require.NoError(t, manager.dealHand(context.Background(), actor))
+	player, err := engine.GetPlayer("p0")
+	require.NoError(t, err)
+	assert.Equal(t, 5, player.Position, "the next hand is dealt to the new seat")
+}
+
+func TestMoveSeatsLetsPlayersTradePlaces(t *testing.T) {
+	engine := newButtonTable(t, 3)
+	require.NoError(t, engine.MoveSeats(map[string]int{"a": 1, "b": 0}))
+	a, _ := engine.GetPlayer("a")
+	b, _ := engine.GetPlayer("b")
+	assert.Equal(t, 1, a.Position)
+	assert.Equal(t, 0, b.Position)
+
+	assert.Error(t, engine.MoveSeats(map[string]int{"a": 2}), "seat 2 is still taken")
+}
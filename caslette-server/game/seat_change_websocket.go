@@ -0,0 +1,56 @@
+package game
+
+import (
+	"context"
+)
+
+// seatRequest names a table and a 1-based seat at it
+type seatRequest struct {
+	TableID  string `json:"table_id"`
+	Position int    `json:"position"`
+	Password string `json:"password,omitempty"`
+}
+
+// handleChangeSeat moves the caller to an empty seat at their table between
+// hands and tells the table
+func (h *TableWebSocketHandler) handleChangeSeat(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req seatRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+	if req.Position <= 0 {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "position is required")
+	}
+
+	slot, err := h.tableManager.ChangeSeat(ctx, req.TableID, conn.GetUserID(), req.Position)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "SEAT_CHANGE_FAILED", err.Error())
+	}
+
+	if table, err := h.tableManager.GetTable(req.TableID); err == nil {
+		h.broadcastTableUpdate(table, "player_changed_seat", map[string]interface{}{
+			"player_id": slot.PlayerID,
+			"position":  slot.Position + 1,
+			"table":     table.GetDetailedInfo(),
+		})
+	}
+	return h.successResponse(msg.RequestID, "seat_changed", map[string]interface{}{
+		"table_id": req.TableID,
+		"position": slot.Position + 1,
+	})
+}
+
+// handleReserveSeat holds a seat for the caller while they buy in; joining
+// with no position, or with the reserved one, takes it
+func (h *TableWebSocketHandler) handleReserveSeat(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req seatRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	reservation, err := h.tableManager.ReserveSeat(ctx, req.TableID, conn.GetUserID(), req.Position, req.Password)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "RESERVE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "seat_reserved", reservation)
+}
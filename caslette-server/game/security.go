@@ -1,6 +1,8 @@
 package game
 
 import (
+	"log"
+	"sync"
 	"time"
 )
 
@@ -28,6 +30,10 @@ func (df *DataFilter) FilterTableInfo(table *GameTable, requesterID string, requ
 		"observer_count": table.GetObserverCount(),
 		"description":    table.Description,
 		"tags":           table.Tags,
+		"featured":       table.IsFeatured(),
+	}
+	if table.IsFeatured() {
+		info["branding"] = table.Feature.Branding
 	}
 
 	// Check if user is at the table
@@ -84,6 +90,13 @@ func (df *DataFilter) FilterTableList(tables []*GameTable, requesterID string) [
 			"player_count": table.GetPlayerCount(),
 			"description":  table.Description,
 			"tags":         table.Tags,
+			"currency":     table.GetCurrency(),
+			"practice":     table.IsPractice(),
+			"bots_allowed": table.Settings.BotsAllowed,
+			"featured":     table.IsFeatured(),
+		}
+		if table.IsFeatured() {
+			tableInfo["branding"] = table.Feature.Branding
 		}
 
 		// Add buy-in info for public tables or if user is at table
@@ -97,7 +110,7 @@ func (df *DataFilter) FilterTableList(tables []*GameTable, requesterID string) [
 		}
 
 		// Indicate if table requires password (but don't expose the password)
-		if table.Settings.Private && table.Settings.Password != "" {
+		if table.Settings.Private && table.HasPassword() {
 			tableInfo["requires_password"] = true
 		}
 
@@ -114,13 +127,31 @@ func (df *DataFilter) FilterTableList(tables []*GameTable, requesterID string) [
 // filterTableSettings filters table settings based on permissions
 func (df *DataFilter) filterTableSettings(settings TableSettings, hasAccess bool) map[string]interface{} {
 	filtered := map[string]interface{}{
-		"small_blind":       settings.SmallBlind,
-		"big_blind":         settings.BigBlind,
-		"buy_in":            settings.BuyIn,
-		"auto_start":        settings.AutoStart,
-		"time_limit":        settings.TimeLimit,
-		"observers_allowed": settings.ObserversAllowed,
-		"private":           settings.Private,
+		"small_blind":           settings.SmallBlind,
+		"big_blind":             settings.BigBlind,
+		"ante":                  settings.Ante,
+		"buy_in":                settings.BuyIn,
+		"auto_start":            settings.AutoStart,
+		"time_limit":            settings.TimeLimit,
+		"time_bank":             settings.TimeBank,
+		"rake_percent":          settings.RakePercent,
+		"max_rake":              settings.MaxRake,
+		"observers_allowed":     settings.ObserversAllowed,
+		"max_observers":         settings.MaxObservers,
+		"private":               settings.Private,
+		"currency":              settings.Currency,
+		"bots_allowed":          settings.BotsAllowed,
+		"decision_support":      settings.DecisionSupport,
+		"auto_rebuy":            settings.AutoRebuy,
+		"auto_rebuy_below":      settings.AutoRebuyBelow,
+		"straddle":              settings.Straddle,
+		"runout_flop_delay_ms":  settings.RunoutFlopDelayMs,
+		"runout_turn_delay_ms":  settings.RunoutTurnDelayMs,
+		"runout_river_delay_ms": settings.RunoutRiverDelayMs,
+		"spectator_delay":       settings.SpectatorDelay,
+	}
+	if settings.Currency == "" {
+		filtered["currency"] = CurrencyDiamonds
 	}
 
 	// Only add sensitive fields if user has access
@@ -129,7 +160,7 @@ func (df *DataFilter) filterTableSettings(settings TableSettings, hasAccess bool
 		filtered["tournament_mode"] = settings.TournamentMode
 
 		// Never expose the actual password, only indicate if one exists
-		if settings.Password != "" {
+		if settings.PasswordHash != "" {
 			filtered["has_password"] = true
 		}
 	}
@@ -150,6 +181,17 @@ func (df *DataFilter) filterPlayerSlots(slots []PlayerSlot, requesterID string,
 			slotInfo["player_id"] = slot.PlayerID
 			slotInfo["username"] = slot.Username
 			slotInfo["is_ready"] = slot.IsReady
+			if len(slot.Cosmetics) > 0 {
+				slotInfo["cosmetics"] = slot.Cosmetics
+			}
+			if slot.Disconnected {
+				slotInfo["disconnected"] = true
+				slotInfo["disconnected_at"] = slot.DisconnectedAt
+			}
+			if slot.SittingOut {
+				slotInfo["sitting_out"] = true
+				slotInfo["sat_out_at"] = slot.SatOutAt
+			}
 
 			// Only show join time to the player themselves or other players
 			if isPlayer || slot.PlayerID == requesterID {
@@ -272,9 +314,19 @@ type AuditLogEntry struct {
 	Details   string    `json:"details,omitempty"`
 }
 
+// MaxRecentAuditEntries bounds the audit entries kept in memory
+const MaxRecentAuditEntries = 10000
+
+// AuditStore persists audit entries so they can be queried later
+type AuditStore interface {
+	SaveAuditEntry(entry AuditLogEntry) error
+}
+
 // SecurityAuditor handles security audit logging
 type SecurityAuditor struct {
-	logs []AuditLogEntry
+	mu    sync.Mutex
+	logs  []AuditLogEntry
+	store AuditStore
 }
 
 // NewSecurityAuditor creates a new security auditor
@@ -284,6 +336,13 @@ func NewSecurityAuditor() *SecurityAuditor {
 	}
 }
 
+// SetStore persists every entry logged from now on
+func (sa *SecurityAuditor) SetStore(store AuditStore) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	sa.store = store
+}
+
 // LogAction logs a security-relevant action
 func (sa *SecurityAuditor) LogAction(userID, tableID, action, result, details string) {
 	entry := AuditLogEntry{
@@ -295,19 +354,32 @@ func (sa *SecurityAuditor) LogAction(userID, tableID, action, result, details st
 		Details:   details,
 	}
 
+	sa.mu.Lock()
 	sa.logs = append(sa.logs, entry)
+	if len(sa.logs) > MaxRecentAuditEntries {
+		sa.logs = append([]AuditLogEntry(nil), sa.logs[len(sa.logs)-MaxRecentAuditEntries:]...)
+	}
+	store := sa.store
+	sa.mu.Unlock()
 
-	// In production, this would log to a proper audit system
-	// For now, we just keep in memory (not suitable for production)
+	// The in-memory copy only covers recent entries; the store keeps them all
+	if store != nil {
+		if err := store.SaveAuditEntry(entry); err != nil {
+			log.Printf("SecurityAuditor: failed to persist %s entry for %s: %v", action, userID, err)
+		}
+	}
 }
 
 // GetAuditLogs returns recent audit logs (admin only)
 func (sa *SecurityAuditor) GetAuditLogs(limit int) []AuditLogEntry {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
 	if limit <= 0 || limit > len(sa.logs) {
 		limit = len(sa.logs)
 	}
 
 	// Return most recent entries
 	start := len(sa.logs) - limit
-	return sa.logs[start:]
+	return append([]AuditLogEntry(nil), sa.logs[start:]...)
 }
@@ -0,0 +1,137 @@
+package game
+
+import "fmt"
+
+// ActionShowCards reveals a player's hole cards. During a hand it records
+// whether the player shows or mucks at showdown, "show": false choosing to
+// muck; once the hand is over it turns their cards face up straight away.
+const ActionShowCards TexasHoldemAction = "show_cards"
+
+// isShowCards reports whether an action is a show or muck choice rather than
+// a betting action
+func isShowCards(action *GameAction) bool {
+	actionType, _ := action.Data["action"].(string)
+	return actionType == string(ActionShowCards)
+}
+
+// showChoice reads the "show" flag of a show_cards action, true when absent
+func showChoice(action *GameAction) (bool, error) {
+	value, ok := action.Data["show"]
+	if !ok {
+		return true, nil
+	}
+	show, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("show must be true or false")
+	}
+	return show, nil
+}
+
+// validateShowCards checks a show_cards action. Players choose out of turn;
+// during the hand only players still in it choose, and afterwards only
+// players who were dealt cards and have not shown them yet.
+func (the *TexasHoldemEngine) validateShowCards(action *GameAction) error {
+	player := the.getHoldemPlayer(action.PlayerID)
+	if player == nil {
+		return fmt.Errorf("player not found")
+	}
+	show, err := showChoice(action)
+	if err != nil {
+		return err
+	}
+	if len(player.Hand.Cards) == 0 {
+		return fmt.Errorf("player has no cards to show")
+	}
+
+	switch the.GetState() {
+	case GameStateInProgress:
+		if player.HasFolded {
+			return fmt.Errorf("player has folded; cards can be shown once the hand is over")
+		}
+	case GameStateFinished:
+		if !show {
+			return fmt.Errorf("the hand is over; cards not shown are already mucked")
+		}
+		if the.revealed[player.ID] {
+			return fmt.Errorf("cards are already shown")
+		}
+	default:
+		return fmt.Errorf("game is not in progress")
+	}
+	return nil
+}
+
+// processShowCards applies a show_cards action; the caller must hold actionMu
+func (the *TexasHoldemEngine) processShowCards(action *GameAction) (*GameEvent, error) {
+	if err := the.validateShowCards(action); err != nil {
+		return nil, err
+	}
+	player := the.getHoldemPlayer(action.PlayerID)
+	show, _ := showChoice(action)
+
+	// The choice is kept until showdown and confirmed only to the player
+	if the.GetState() == GameStateInProgress {
+		if the.showChoices == nil {
+			the.showChoices = make(map[string]bool)
+		}
+		the.showChoices[player.ID] = show
+		return &GameEvent{
+			Type:     "show_choice",
+			PlayerID: player.ID,
+			Data: map[string]interface{}{
+				"playerID": player.ID,
+				"show":     show,
+			},
+		}, nil
+	}
+
+	if the.revealed == nil {
+		the.revealed = make(map[string]bool)
+	}
+	the.revealed[player.ID] = true
+	event := &GameEvent{
+		Type:     "cards_shown",
+		PlayerID: player.ID,
+		Data: map[string]interface{}{
+			"playerID":  player.ID,
+			"holeCards": player.Hand.Cards,
+		},
+	}
+	if hand, ok := the.showdownHands[player.ID]; ok {
+		event.Data["rankName"] = hand.Rank.String()
+		event.Data["description"] = hand.Description()
+	}
+	the.emitEvent(event)
+	return event, nil
+}
+
+// revealShowdownHands decides whose hole cards the showdown turns face up.
+// Players who win a contested pot must show, as must the last player to bet
+// or raise on the river and everyone when a player is all in; the others show
+// only if they chose to and muck otherwise.
+func (the *TexasHoldemEngine) revealShowdownHands(won map[string]bool) {
+	the.revealed = make(map[string]bool, len(the.showdownHands))
+	allIn := false
+	for playerID := range the.showdownHands {
+		if player := the.getHoldemPlayer(playerID); player != nil && player.IsAllIn {
+			allIn = true
+		}
+	}
+	for playerID := range the.showdownHands {
+		if allIn || won[playerID] || playerID == the.lastAggressor || the.showChoices[playerID] {
+			the.revealed[playerID] = true
+		}
+	}
+}
+
+// muckedHands lists the players who reached showdown without showing, in
+// seat order
+func (the *TexasHoldemEngine) muckedHands() []string {
+	mucked := make([]string, 0)
+	for _, player := range the.getActivePlayers() {
+		if _, ok := the.showdownHands[player.ID]; ok && !the.revealed[player.ID] {
+			mucked = append(mucked, player.ID)
+		}
+	}
+	return mucked
+}
@@ -0,0 +1,217 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRiverTable deals players 1 and 2 a hand and checks it down to the river,
+// where player 1's aces beat player 2's king high on a dry board
+func newRiverTable(t *testing.T) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("show-muck-game")
+	for i, playerID := range []string{"1", "2"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+	require.NoError(t, engine.Start())
+	for engine.roundState != River {
+		action := ActionCheck
+		for _, valid := range engine.GetValidActions(engine.getCurrentActionPlayerID()) {
+			if valid == string(ActionCall) {
+				action = ActionCall
+			}
+		}
+		require.NoError(t, act(engine, action, 0))
+	}
+
+	for playerID, cards := range map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, Queen)},
+	} {
+		holdemPlayer := engine.getHoldemPlayer(playerID)
+		holdemPlayer.Hand.Cards = cards
+	}
+	engine.communityCards.Cards = []Card{
+		NewCard(Clubs, Two), NewCard(Diamonds, Five), NewCard(Hearts, Nine), NewCard(Spades, Seven), NewCard(Clubs, Three),
+	}
+	return engine
+}
+
+func showCards(engine *TexasHoldemEngine, playerID string, data map[string]interface{}) (*GameEvent, error) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["action"] = string(ActionShowCards)
+	action := &GameAction{PlayerID: playerID, Type: "poker_action", Data: data}
+	if err := engine.IsValidAction(action); err != nil {
+		return nil, err
+	}
+	return engine.ProcessAction(context.Background(), action)
+}
+
+func showdownPlayers(event *GameEvent) []string {
+	players := make([]string, 0)
+	for _, hand := range event.Data["hands"].([]ShowdownHand) {
+		players = append(players, hand.PlayerID)
+	}
+	return players
+}
+
+func TestShowdownMucksLosingHandByDefault(t *testing.T) {
+	engine := newRiverTable(t)
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+
+	showdown := lastEventOfType(engine, "showdown")
+	require.NotNil(t, showdown)
+	assert.Equal(t, []string{"1"}, showdownPlayers(showdown), "only the winner is forced to show")
+	assert.Equal(t, []string{"2"}, showdown.Data["mucked"])
+	assert.Equal(t, "Pair of aces", showdown.Data["explanation"], "the explanation gives nothing of a mucked hand away")
+
+	results := lastEventOfType(engine, "pot_distributed").Data["results"].([]HandResult)
+	require.Len(t, results, 2)
+	assert.NotEmpty(t, results[0].Hand)
+	assert.Empty(t, results[1].Hand)
+	assert.Equal(t, ShowdownLost, results[1].Showdown)
+
+	// Until the next deal the loser may still turn their cards over
+	_, err := showCards(engine, "2", map[string]interface{}{"show": false})
+	assert.Error(t, err, "the hand is over; there is nothing left to muck")
+	event, err := showCards(engine, "2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "cards_shown", event.Type)
+	assert.Equal(t, engine.getHoldemPlayer("2").Hand.Cards, event.Data["holeCards"])
+	assert.Equal(t, "High card, king", event.Data["description"])
+	assert.Same(t, event, lastEventOfType(engine, "cards_shown"), "the table sees the cards")
+
+	_, err = showCards(engine, "2", nil)
+	assert.Error(t, err, "cards are shown once")
+	_, err = showCards(engine, "1", nil)
+	assert.Error(t, err, "the winner's cards are already face up")
+}
+
+func TestShowdownHonoursShowChoice(t *testing.T) {
+	engine := newRiverTable(t)
+
+	event, err := showCards(engine, "2", nil)
+	require.NoError(t, err, "players choose out of turn")
+	assert.Equal(t, "show_choice", event.Type)
+	assert.Equal(t, true, event.Data["show"])
+	assert.Nil(t, lastEventOfType(engine, "show_choice"), "the choice is not broadcast")
+
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	showdown := lastEventOfType(engine, "showdown")
+	assert.Equal(t, []string{"1", "2"}, showdownPlayers(showdown))
+	assert.Empty(t, showdown.Data["mucked"])
+	assert.Equal(t, "Pair of aces beats high card, king", showdown.Data["explanation"])
+}
+
+func TestShowdownMuckChoiceOverridesEarlierShow(t *testing.T) {
+	engine := newRiverTable(t)
+	_, err := showCards(engine, "2", nil)
+	require.NoError(t, err)
+	_, err = showCards(engine, "2", map[string]interface{}{"show": false})
+	require.NoError(t, err)
+	_, err = showCards(engine, "2", map[string]interface{}{"show": "yes"})
+	assert.Error(t, err)
+
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+	assert.Equal(t, []string{"2"}, lastEventOfType(engine, "showdown").Data["mucked"])
+}
+
+func TestShowdownRiverAggressorMustShow(t *testing.T) {
+	engine := newRiverTable(t)
+	if engine.getCurrentActionPlayerID() == "1" {
+		require.NoError(t, act(engine, ActionCheck, 0))
+	}
+	require.Equal(t, "2", engine.getCurrentActionPlayerID())
+	require.NoError(t, act(engine, ActionBet, 20))
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	showdown := lastEventOfType(engine, "showdown")
+	assert.Equal(t, []string{"1", "2"}, showdownPlayers(showdown), "the losing bettor was called and shows")
+	assert.Empty(t, showdown.Data["mucked"])
+}
+
+func TestShowdownAllInShowsEveryHand(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 100, "2": 300, "3": 300}, nil)
+	require.NoError(t, engine.showdown())
+
+	assert.Equal(t, []string{"1", "2", "3"}, showdownPlayers(lastEventOfType(engine, "showdown")))
+}
+
+func TestShowCardsAfterFoldingWaitsForHandToEnd(t *testing.T) {
+	engine := newRiverTable(t)
+	folder := engine.getCurrentActionPlayerID()
+	require.NoError(t, act(engine, ActionFold, 0))
+	require.Equal(t, GameStateFinished, engine.GetState())
+
+	// Folding ends this heads-up hand, so the folder may now show
+	event, err := showCards(engine, folder, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "cards_shown", event.Type)
+	assert.NotContains(t, event.Data, "description", "a folded hand never reached showdown")
+
+	engine = NewTexasHoldemEngine("three-way")
+	for i, playerID := range []string{"1", "2", "3"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+	require.NoError(t, engine.Start())
+	folder = engine.getCurrentActionPlayerID()
+	require.NoError(t, act(engine, ActionFold, 0))
+	_, err = showCards(engine, folder, nil)
+	assert.Error(t, err, "a folded player waits for the hand to end")
+}
+
+func TestPotEventsDescribeWinningHand(t *testing.T) {
+	engine := newRiverTable(t)
+	require.NoError(t, act(engine, ActionCheck, 0))
+	require.NoError(t, act(engine, ActionCheck, 0))
+
+	bestCards := []Card{
+		NewCard(Hearts, Ace), NewCard(Spades, Ace), NewCard(Hearts, Nine), NewCard(Spades, Seven), NewCard(Diamonds, Five),
+	}
+	awarded := lastEventOfType(engine, "pot_awarded")
+	require.NotNil(t, awarded)
+	assert.Equal(t, []WinningHand{{PlayerID: "1", Description: "Pair of aces", BestCards: bestCards}}, awarded.Data["winningHands"])
+
+	results := lastEventOfType(engine, "pot_distributed").Data["results"].([]HandResult)
+	require.Len(t, results, 2)
+	assert.Equal(t, bestCards, results[0].BestCards)
+	assert.Empty(t, results[1].BestCards, "mucked cards stay hidden")
+}
+
+func TestUncontestedPotHasNoWinningHand(t *testing.T) {
+	engine := newRiverTable(t)
+	require.NoError(t, act(engine, ActionFold, 0))
+
+	awarded := lastEventOfType(engine, "pot_awarded")
+	require.NotNil(t, awarded)
+	assert.NotContains(t, awarded.Data, "winningHands")
+}
+
+func TestFoldWinDoesNotBroadcastHoleCards(t *testing.T) {
+	engine := newRiverTable(t)
+	folder := engine.getCurrentActionPlayerID()
+	require.NoError(t, act(engine, ActionFold, 0))
+	winner := "1"
+	if folder == "1" {
+		winner = "2"
+	}
+
+	event := lastEventOfType(engine, "pot_distributed")
+	require.NotNil(t, event)
+	assert.Equal(t, []string{winner}, event.Data["winners"])
+	payload, err := json.Marshal(event.Data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "suit", "nobody's hole cards are sent when the hand was not shown down")
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand/v2"
+)
+
+// ShuffleSeedSize is the length in bytes of the secret seed behind a shuffle
+const ShuffleSeedSize = 32
+
+// seedStream expands a shuffle seed into an endless stream of unbiased
+// random numbers: SHA-256 over the seed and a block counter
+type seedStream struct {
+	seed    []byte
+	counter uint64
+	block   [sha256.Size]byte
+	offset  int
+}
+
+func newSeedStream(seed []byte) *seedStream {
+	return &seedStream{seed: seed, offset: sha256.Size}
+}
+
+func (s *seedStream) uint64() uint64 {
+	if s.offset+8 > sha256.Size {
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], s.counter)
+		s.block = sha256.Sum256(append(append([]byte{}, s.seed...), counter[:]...))
+		s.counter++
+		s.offset = 0
+	}
+	value := binary.BigEndian.Uint64(s.block[s.offset:])
+	s.offset += 8
+	return value
+}
+
+// uniform returns a number in [0, n), rejecting draws that would favour
+// the low values
+func (s *seedStream) uniform(n int) int {
+	bound := uint64(n)
+	limit := ^uint64(0) - ^uint64(0)%bound
+	for {
+		if value := s.uint64(); value < limit {
+			return int(value % bound)
+		}
+	}
+}
+
+// shuffleCards puts cards in the order a Fisher-Yates shuffle driven by seed
+// gives; the same seed always gives the same order
+func shuffleCards(cards []Card, seed []byte) {
+	stream := newSeedStream(seed)
+	for i := len(cards) - 1; i > 0; i-- {
+		j := stream.uniform(i + 1)
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+}
+
+// newShuffleSeed draws a seed from the operating system's secure generator
+func newShuffleSeed() []byte {
+	seed := make([]byte, ShuffleSeedSize)
+	rand.Read(seed)
+	return seed
+}
+
+// Shuffler supplies the seed of each shuffle. Decks draw from crypto/rand
+// unless given one; tests and simulations inject a seeded Shuffler to deal
+// the same hands every run. Shufflers need not be safe for concurrent use,
+// so give each deck its own.
+type Shuffler interface {
+	NextSeed() []byte // ShuffleSeedSize bytes
+}
+
+// sourceShuffler draws shuffle seeds from a math/rand source
+type sourceShuffler struct {
+	source mathrand.Source
+}
+
+// NewSourceShuffler returns a Shuffler whose seeds come from a math/rand
+// source. It is predictable, so it must never deal real games.
+func NewSourceShuffler(source mathrand.Source) Shuffler {
+	return &sourceShuffler{source: source}
+}
+
+// NewSeededShuffler returns a Shuffler that deals the same sequence of
+// shuffles for the same seed, for reproducing hands in tests and bug reports
+func NewSeededShuffler(seed uint64) Shuffler {
+	return NewSourceShuffler(mathrand.NewPCG(seed, seed))
+}
+
+func (s *sourceShuffler) NextSeed() []byte {
+	seed := make([]byte, ShuffleSeedSize)
+	for i := 0; i < ShuffleSeedSize; i += 8 {
+		binary.BigEndian.PutUint64(seed[i:], s.source.Uint64())
+	}
+	return seed
+}
+
+// ShuffleCommitment is the published hash of a shuffle seed
+func ShuffleCommitment(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyShuffle checks a revealed seed against the commitment published at
+// the start of the hand and returns the deck order it produced, so a player
+// can compare it with the cards that were dealt
+func VerifyShuffle(seedHex, commitment string) ([]Card, error) {
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil || len(seed) != ShuffleSeedSize {
+		return nil, fmt.Errorf("seed must be %d hex-encoded bytes", ShuffleSeedSize)
+	}
+	if ShuffleCommitment(seed) != commitment {
+		return nil, fmt.Errorf("seed does not match the commitment")
+	}
+	cards := standardDeck()
+	shuffleCards(cards, seed)
+	return cards, nil
+}
+
+// standardDeck returns the 52 cards in the order a new deck holds them
+// before it is shuffled
+func standardDeck() []Card {
+	cards := make([]Card, 0, 52)
+	for _, suit := range []Suit{Hearts, Diamonds, Clubs, Spades} {
+		for rank := Two; rank <= Ace; rank++ {
+			cards = append(cards, Card{Suit: suit, Rank: rank})
+		}
+	}
+	return cards
+}
+
+// SetShuffler has the engine's deck draw its shuffles from shuffler from the
+// next hand on; nil goes back to crypto/rand
+func (the *TexasHoldemEngine) SetShuffler(shuffler Shuffler) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	the.deck.SetShuffler(shuffler)
+}
+
+// SetShuffleCommitments turns on commit-reveal shuffling: the hash of each
+// hand's seed is published when the hand starts and the seed when it ends
+func (the *TexasHoldemEngine) SetShuffleCommitments(enabled bool) {
+	the.commitShuffle = enabled
+}
+
+// revealShuffle publishes the seed of the hand that just ended so players
+// can check it against the commitment
+func (the *TexasHoldemEngine) revealShuffle() {
+	if !the.commitShuffle {
+		return
+	}
+	the.emitEvent(&GameEvent{
+		Type: "shuffle_revealed",
+		Data: map[string]interface{}{
+			"seed":       the.deck.RevealSeed(),
+			"commitment": the.deck.Commitment(),
+		},
+	})
+}
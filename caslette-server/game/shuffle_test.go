@@ -0,0 +1,128 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffleIsReproducibleFromItsSeed(t *testing.T) {
+	deck := NewDeck()
+	deck.Shuffle()
+	dealt := append([]Card(nil), deck.cards...)
+
+	verified, err := VerifyShuffle(deck.RevealSeed(), deck.Commitment())
+	require.NoError(t, err)
+	assert.Equal(t, dealt, verified)
+	assert.NotEqual(t, standardDeck(), verified)
+
+	other := NewDeck()
+	other.Shuffle()
+	assert.NotEqual(t, deck.RevealSeed(), other.RevealSeed(), "every shuffle draws a new seed")
+}
+
+func TestVerifyShuffleRejectsMismatchedSeed(t *testing.T) {
+	deck := NewDeck()
+	deck.Shuffle()
+	other := NewDeck()
+	other.Shuffle()
+
+	_, err := VerifyShuffle(other.RevealSeed(), deck.Commitment())
+	assert.Error(t, err)
+	_, err = VerifyShuffle("not hex", deck.Commitment())
+	assert.Error(t, err)
+	_, err = VerifyShuffle("abcd", ShuffleCommitment([]byte{0xab, 0xcd}))
+	assert.Error(t, err, "seeds have a fixed size")
+}
+
+func TestShuffleCardsIsUniform(t *testing.T) {
+	counts := make(map[string]int)
+	const runs = 6000
+	for i := 0; i < runs; i++ {
+		cards := []Card{NewCard(Spades, Two), NewCard(Spades, Three), NewCard(Spades, Four)}
+		seed := bytes.Repeat([]byte{byte(i), byte(i >> 8)}, ShuffleSeedSize/2)
+		shuffleCards(cards, seed)
+		counts[cards[0].String()+cards[1].String()+cards[2].String()]++
+	}
+	require.Len(t, counts, 6, "every order of three cards comes up")
+	for order, count := range counts {
+		assert.InDelta(t, runs/6, count, 150, order)
+	}
+}
+
+func TestEngineCommitsToShuffleAndRevealsSeed(t *testing.T) {
+	engine := newButtonTable(t, 2)
+	engine.SetShuffleCommitments(true)
+	require.NoError(t, engine.Start())
+
+	started := lastEventOfType(engine, "hand_started")
+	require.NotNil(t, started)
+	commitment, _ := started.Data["shuffleCommitment"].(string)
+	require.NotEmpty(t, commitment)
+	assert.Nil(t, lastEventOfType(engine, "shuffle_revealed"), "the seed stays secret during the hand")
+
+	var holeCards []Card
+	for _, playerID := range []string{"a", "b"} {
+		holeCards = append(holeCards, engine.getHoldemPlayer(playerID).Hand.Cards...)
+	}
+	foldHand(t, engine)
+
+	revealed := lastEventOfType(engine, "shuffle_revealed")
+	require.NotNil(t, revealed)
+	assert.Equal(t, commitment, revealed.Data["commitment"])
+	deck, err := VerifyShuffle(revealed.Data["seed"].(string), commitment)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, deck[:len(holeCards)], holeCards, "the hole cards came off the top of the verified deck")
+}
+
+func TestEngineWithoutCommitmentsPublishesNoSeed(t *testing.T) {
+	engine := newButtonTable(t, 2)
+	require.NoError(t, engine.Start())
+	foldHand(t, engine)
+
+	assert.NotContains(t, lastEventOfType(engine, "hand_started").Data, "shuffleCommitment")
+	assert.Nil(t, lastEventOfType(engine, "shuffle_revealed"))
+}
+
+func TestSeededShufflerReplaysTheSameDeals(t *testing.T) {
+	first, second := NewDeckWithShuffler(NewSeededShuffler(42)), NewDeckWithShuffler(NewSeededShuffler(42))
+	for i := 0; i < 3; i++ {
+		first.Reset()
+		second.Reset()
+		assert.Equal(t, first.cards, second.cards, "shuffle %d", i)
+	}
+	verified, err := VerifyShuffle(first.RevealSeed(), first.Commitment())
+	require.NoError(t, err)
+	assert.Equal(t, first.cards, verified, "seeded shuffles still verify")
+
+	other := NewDeckWithShuffler(NewSeededShuffler(43))
+	other.Reset()
+	assert.NotEqual(t, first.cards, other.cards)
+}
+
+func TestEngineDealsReproducibleHandsWithSeededShuffler(t *testing.T) {
+	deal := func(seed uint64) ([]Card, []Card) {
+		engine := newButtonTable(t, 3)
+		engine.SetShuffler(NewSeededShuffler(seed))
+		require.NoError(t, engine.Start())
+		holeCards := append([]Card(nil), engine.getHoldemPlayer("a").Hand.Cards...)
+		for engine.GetState() != GameStateFinished {
+			action := ActionCheck
+			for _, valid := range engine.GetValidActions(engine.getCurrentActionPlayerID()) {
+				if valid == string(ActionCall) {
+					action = ActionCall
+				}
+			}
+			require.NoError(t, act(engine, action, 0))
+		}
+		return holeCards, engine.communityCards.Cards
+	}
+
+	holeCards, board := deal(7)
+	replayedHoleCards, replayedBoard := deal(7)
+	assert.Equal(t, holeCards, replayedHoleCards)
+	assert.Equal(t, board, replayedBoard)
+	assert.Len(t, board, 5)
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pot is one pot of a hand: the main pot, or a side pot capped by a
+// player's all-in. Only eligible players can win it.
+type Pot struct {
+	Name     string   `json:"name"`     // "main", "side 1", "side 2", ...
+	Amount   int      `json:"amount"`   // After rake
+	Eligible []string `json:"eligible"` // Player IDs in seat order
+	Winners  []string `json:"winners,omitempty"`
+	Share    int      `json:"share,omitempty"`    // Chips paid to each winner
+	OddChips int      `json:"oddChips,omitempty"` // Remainder paid to the earliest seated winner
+	Rake     int      `json:"rake,omitempty"`     // Chips the house took from the pot
+}
+
+// potContribution is what one player put into the pot during a hand
+type potContribution struct {
+	PlayerID string
+	Position int
+	Amount   int
+	Folded   bool
+}
+
+// buildPots splits a hand's contributions into a main pot and side pots.
+// Each level at which a live player is all-in caps a pot; players who put
+// in less than the cap cannot win it. Chips folded players put in beyond the
+// last cap go to the last pot, and chips in the total that no contribution
+// accounts for go to the main pot.
+func buildPots(contributions []potContribution, total int) []Pot {
+	sorted := append([]potContribution(nil), contributions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	levelSet := make(map[int]bool)
+	for _, contribution := range sorted {
+		if !contribution.Folded && contribution.Amount > 0 {
+			levelSet[contribution.Amount] = true
+		}
+	}
+	levels := make([]int, 0, len(levelSet))
+	for level := range levelSet {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+
+	// Nobody live put chips in: one pot shared by the live players
+	if len(levels) == 0 {
+		pot := Pot{Name: "main", Amount: total, Eligible: []string{}}
+		for _, contribution := range sorted {
+			if !contribution.Folded {
+				pot.Eligible = append(pot.Eligible, contribution.PlayerID)
+			}
+		}
+		return []Pot{pot}
+	}
+
+	pots := make([]Pot, 0, len(levels))
+	accounted := 0
+	previous := 0
+	for i, level := range levels {
+		last := i == len(levels)-1
+		pot := Pot{Eligible: []string{}}
+		for _, contribution := range sorted {
+			upper := min(contribution.Amount, level)
+			if last {
+				upper = contribution.Amount
+			}
+			if upper > previous {
+				pot.Amount += upper - previous
+			}
+			if !contribution.Folded && contribution.Amount >= level {
+				pot.Eligible = append(pot.Eligible, contribution.PlayerID)
+			}
+		}
+		previous = level
+		accounted += pot.Amount
+		if pot.Amount > 0 {
+			pots = append(pots, pot)
+		}
+	}
+
+	if dead := total - accounted; dead > 0 {
+		pots[0].Amount += dead
+	}
+
+	for i := range pots {
+		pots[i].Name = potName(i)
+	}
+	return pots
+}
+
+// potName labels the main pot and numbers the side pots
+func potName(index int) string {
+	if index == 0 {
+		return "main"
+	}
+	return fmt.Sprintf("side %d", index)
+}
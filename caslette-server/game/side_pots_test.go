@@ -0,0 +1,180 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPotsSingleLevel(t *testing.T) {
+	pots := buildPots([]potContribution{
+		{PlayerID: "a", Position: 1, Amount: 100},
+		{PlayerID: "b", Position: 2, Amount: 100},
+	}, 200)
+
+	require.Len(t, pots, 1)
+	assert.Equal(t, "main", pots[0].Name)
+	assert.Equal(t, 200, pots[0].Amount)
+	assert.Equal(t, []string{"a", "b"}, pots[0].Eligible)
+}
+
+func TestBuildPotsCapsAllInPlayers(t *testing.T) {
+	pots := buildPots([]potContribution{
+		{PlayerID: "big", Position: 3, Amount: 500},
+		{PlayerID: "short", Position: 1, Amount: 100},
+		{PlayerID: "mid", Position: 2, Amount: 300},
+	}, 900)
+
+	require.Len(t, pots, 3)
+	assert.Equal(t, Pot{Name: "main", Amount: 300, Eligible: []string{"short", "mid", "big"}}, pots[0])
+	assert.Equal(t, Pot{Name: "side 1", Amount: 400, Eligible: []string{"mid", "big"}}, pots[1])
+	assert.Equal(t, Pot{Name: "side 2", Amount: 200, Eligible: []string{"big"}}, pots[2], "uncalled chips return to their owner")
+}
+
+func TestBuildPotsFoldedChipsAreDeadMoney(t *testing.T) {
+	pots := buildPots([]potContribution{
+		{PlayerID: "short", Position: 1, Amount: 50},
+		{PlayerID: "folder", Position: 2, Amount: 400, Folded: true},
+		{PlayerID: "caller", Position: 3, Amount: 200},
+	}, 650)
+
+	require.Len(t, pots, 2)
+	assert.Equal(t, 150, pots[0].Amount)
+	assert.Equal(t, []string{"short", "caller"}, pots[0].Eligible)
+	assert.Equal(t, 500, pots[1].Amount, "folded chips beyond the last cap join the last pot")
+	assert.Equal(t, []string{"caller"}, pots[1].Eligible)
+}
+
+func TestBuildPotsUnaccountedChipsJoinMainPot(t *testing.T) {
+	pots := buildPots([]potContribution{
+		{PlayerID: "a", Position: 1, Amount: 100},
+		{PlayerID: "b", Position: 2, Amount: 40},
+	}, 160)
+
+	require.Len(t, pots, 2)
+	assert.Equal(t, 100, pots[0].Amount)
+	assert.Equal(t, 60, pots[1].Amount)
+}
+
+func TestBuildPotsWithoutLiveContributions(t *testing.T) {
+	pots := buildPots([]potContribution{
+		{PlayerID: "a", Position: 1},
+		{PlayerID: "b", Position: 2, Folded: true},
+	}, 30)
+
+	require.Len(t, pots, 1)
+	assert.Equal(t, 30, pots[0].Amount)
+	assert.Equal(t, []string{"a"}, pots[0].Eligible)
+}
+
+// setupSidePotShowdown seats players with the given hole cards and hand
+// contributions over a dry board, ready for showdown
+func setupSidePotShowdown(t *testing.T, hands map[string][]Card, bets map[string]int, folded map[string]bool) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("side-pot-game")
+	for i, playerID := range []string{"1", "2", "3"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i + 1}))
+	}
+
+	for playerID, cards := range hands {
+		holdemPlayer := engine.getHoldemPlayer(playerID)
+		holdemPlayer.Hand.Cards = cards
+		holdemPlayer.Chips = 0
+		holdemPlayer.TotalBet = bets[playerID]
+		holdemPlayer.HasFolded = folded[playerID]
+		holdemPlayer.IsAllIn = !folded[playerID]
+		engine.pot += bets[playerID]
+	}
+	engine.communityCards.Cards = []Card{
+		NewCard(Clubs, Two), NewCard(Diamonds, Five), NewCard(Hearts, Nine), NewCard(Spades, Seven), NewCard(Clubs, Three),
+	}
+	return engine
+}
+
+func potEvents(engine *TexasHoldemEngine) []*GameEvent {
+	events := make([]*GameEvent, 0)
+	for _, event := range engine.GetEvents() {
+		if event.Type == "pot_awarded" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestTexasHoldemShortStackWinsOnlyMainPot(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 100, "2": 300, "3": 300}, nil)
+
+	require.NoError(t, engine.showdown())
+
+	assert.Equal(t, 300, engine.getHoldemPlayer("1").Chips, "short stack wins three times its all-in")
+	assert.Equal(t, 400, engine.getHoldemPlayer("2").Chips, "second best hand wins the side pot")
+	assert.Equal(t, 0, engine.getHoldemPlayer("3").Chips)
+	assert.Equal(t, 0, engine.pot)
+
+	events := potEvents(engine)
+	require.Len(t, events, 2)
+	assert.Equal(t, "main", events[0].Data["pot"])
+	assert.Equal(t, []string{"1"}, events[0].Data["winners"])
+	assert.Equal(t, "side 1", events[1].Data["pot"])
+	assert.Equal(t, []string{"2", "3"}, events[1].Data["eligible"])
+	assert.Equal(t, []string{"2"}, events[1].Data["winners"])
+}
+
+func TestTexasHoldemSplitsSidePotWithOddChip(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, Queen)},
+		"3": {NewCard(Diamonds, King), NewCard(Clubs, Queen)},
+	}, map[string]int{"1": 100, "2": 251, "3": 250}, nil)
+
+	require.NoError(t, engine.showdown())
+
+	// Main 300 to player 1; side 1 of 300 split; side 2 of 1 returned to player 2
+	assert.Equal(t, 300, engine.getHoldemPlayer("1").Chips)
+	assert.Equal(t, 151, engine.getHoldemPlayer("2").Chips)
+	assert.Equal(t, 150, engine.getHoldemPlayer("3").Chips)
+
+	events := potEvents(engine)
+	require.Len(t, events, 3)
+	assert.Equal(t, 150, events[1].Data["share"])
+	assert.Equal(t, []string{"2", "3"}, events[1].Data["winners"])
+}
+
+func TestTexasHoldemFoldWinCollectsAllPots(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 50, "2": 200, "3": 20}, map[string]bool{"1": true, "3": true})
+
+	engine.winners = []*TexasHoldemPlayer{engine.getHoldemPlayer("2")}
+	engine.distributePot()
+
+	assert.Equal(t, 270, engine.getHoldemPlayer("2").Chips)
+	assert.Equal(t, 0, engine.getHoldemPlayer("1").Chips)
+}
+
+func TestTexasHoldemOrphanedPotGoesToBestRemainingHand(t *testing.T) {
+	engine := setupSidePotShowdown(t, map[string][]Card{
+		"1": {NewCard(Hearts, Ace), NewCard(Spades, Ace)},
+		"2": {NewCard(Hearts, King), NewCard(Spades, King)},
+		"3": {NewCard(Hearts, Queen), NewCard(Spades, Jack)},
+	}, map[string]int{"1": 100, "2": 300, "3": 300}, map[string]bool{"3": true})
+
+	// The side pot's only eligible player holds neither a showdown hand nor the win
+	engine.winners = []*TexasHoldemPlayer{engine.getHoldemPlayer("1")}
+	engine.distributePot()
+
+	assert.Equal(t, 700, engine.getHoldemPlayer("1").Chips, "the orphaned side pot is not lost")
+	assert.Equal(t, 0, engine.getHoldemPlayer("2").Chips)
+	assert.Equal(t, 0, engine.pot)
+
+	events := potEvents(engine)
+	require.Len(t, events, 2)
+	assert.Equal(t, []string{"2"}, events[1].Data["eligible"])
+	assert.Equal(t, []string{"1"}, events[1].Data["winners"])
+}
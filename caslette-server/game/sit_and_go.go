@@ -0,0 +1,335 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DiamondLedger moves diamonds in and out of players' accounts for paid games
+type DiamondLedger interface {
+	Debit(playerID string, amount int, description string) error
+	Credit(playerID string, amount int, description string) error
+}
+
+// SitAndGoConfig describes a single-table tournament that starts as soon as
+// every seat is taken
+type SitAndGoConfig struct {
+	ID            string                `json:"id"`
+	Name          string                `json:"name"`
+	GameType      GameType              `json:"game_type"`
+	Settings      TableSettings         `json:"settings"`
+	Seats         int                   `json:"seats"`
+	EntryFee      int                   `json:"entry_fee"` // Diamonds charged to each entrant
+	StartingStack int                   `json:"starting_stack"`
+	Payouts       []int                 `json:"payouts"` // Percent of the entry fees by finishing position
+	Clock         TournamentClockConfig `json:"clock"`
+}
+
+// SitAndGoInfo is the lobby view of a Sit&Go
+type SitAndGoInfo struct {
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	GameType      GameType         `json:"game_type"`
+	Status        TournamentStatus `json:"status"`
+	Seats         int              `json:"seats"`
+	Registered    int              `json:"registered"`
+	EntryFee      int              `json:"entry_fee"`
+	StartingStack int              `json:"starting_stack"`
+	PrizePool     int              `json:"prize_pool"`
+	Payouts       []int            `json:"payouts"`
+}
+
+// DefaultSitAndGoLevels is the blind structure used when a Sit&Go names none
+func DefaultSitAndGoLevels() []TournamentLevel {
+	blinds := [][2]int{{10, 20}, {15, 30}, {25, 50}, {50, 100}, {75, 150}, {100, 200}, {200, 400}, {300, 600}}
+	levels := make([]TournamentLevel, len(blinds))
+	for i, level := range blinds {
+		levels[i] = TournamentLevel{Level: i + 1, SmallBlind: level[0], BigBlind: level[1], Duration: 5 * time.Minute}
+	}
+	return levels
+}
+
+// DefaultSitAndGoPayouts pays the winner alone at small tables, the top two
+// at six seats and the top three beyond that
+func DefaultSitAndGoPayouts(seats int) []int {
+	switch {
+	case seats <= 3:
+		return []int{100}
+	case seats <= 6:
+		return []int{65, 35}
+	default:
+		return []int{50, 30, 20}
+	}
+}
+
+// SitAndGo charges each entrant a diamond entry fee, starts its table once it
+// is full, plays until one player holds every chip and then pays the entry
+// fees out in diamonds by finishing position
+type SitAndGo struct {
+	config       SitAndGoConfig
+	tableManager *ActorTableManager
+	tournament   *Tournament
+	ledger       DiamondLedger
+	events       TournamentEventSink
+
+	mu         sync.Mutex // Serializes entries so the table starts exactly when full
+	registered int
+}
+
+// NewSitAndGo validates the configuration and opens the Sit&Go for entries
+func NewSitAndGo(config SitAndGoConfig, tableManager *ActorTableManager, hub WebSocketHub, ledger DiamondLedger, events TournamentEventSink) (*SitAndGo, error) {
+	if config.Seats == 0 {
+		config.Seats = DefaultTournamentSeats
+	}
+	if config.EntryFee < 0 {
+		return nil, fmt.Errorf("entry fee cannot be negative")
+	}
+	if config.EntryFee > 0 && ledger == nil {
+		return nil, fmt.Errorf("paid Sit&Go requires a diamond ledger")
+	}
+	if len(config.Payouts) == 0 {
+		config.Payouts = DefaultSitAndGoPayouts(config.Seats)
+	}
+	if len(config.Payouts) > config.Seats {
+		return nil, fmt.Errorf("cannot pay more positions than there are seats")
+	}
+	if len(config.Clock.Levels) == 0 {
+		config.Clock.Levels = DefaultSitAndGoLevels()
+	}
+	if config.Settings.BigBlind == 0 {
+		config.Settings = TournamentSettings()
+	}
+
+	s := &SitAndGo{tableManager: tableManager, ledger: ledger, events: events}
+	tournament, err := NewTournament(TournamentConfig{
+		ID:            config.ID,
+		Name:          config.Name,
+		GameType:      config.GameType,
+		Settings:      config.Settings,
+		StartingStack: config.StartingStack,
+		SeatsPerTable: config.Seats,
+		EntryFee:      config.EntryFee,
+		Payouts:       config.Payouts,
+		MinEntrants:   config.Seats,
+		Clock:         config.Clock,
+	}, tableManager, hub, s)
+	if err != nil {
+		return nil, err
+	}
+	s.config = config
+	s.tournament = tournament
+	return s, nil
+}
+
+// ID returns the Sit&Go ID
+func (s *SitAndGo) ID() string {
+	return s.config.ID
+}
+
+// Tournament returns the tournament running the Sit&Go's table
+func (s *SitAndGo) Tournament() *Tournament {
+	return s.tournament
+}
+
+// Join charges the entry fee and takes a seat. The entry that fills the last
+// seat starts the game.
+func (s *SitAndGo) Join(ctx context.Context, playerID, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tournament.Status() != TournamentRegistering {
+		return &TableError{"SIT_AND_GO_STARTED", "Sit&Go has already started"}
+	}
+	if s.registered >= s.config.Seats {
+		return &TableError{"SIT_AND_GO_FULL", "Sit&Go is full"}
+	}
+
+	if err := s.debit(playerID); err != nil {
+		return err
+	}
+	if err := s.tournament.Register(playerID, username); err != nil {
+		s.refund(playerID)
+		return err
+	}
+	s.registered++
+
+	if s.registered < s.config.Seats {
+		return nil
+	}
+	if err := s.tournament.Start(ctx); err != nil {
+		// Give the seat back so the next entry can try again
+		if unregisterErr := s.tournament.Unregister(playerID); unregisterErr == nil {
+			s.registered--
+			s.refund(playerID)
+		}
+		return fmt.Errorf("failed to start Sit&Go: %w", err)
+	}
+	return nil
+}
+
+// Leave withdraws a player before the game starts and refunds their entry
+func (s *SitAndGo) Leave(playerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.tournament.Unregister(playerID); err != nil {
+		return err
+	}
+	s.registered--
+	s.refund(playerID)
+	return nil
+}
+
+// debit charges a player's entry fee
+func (s *SitAndGo) debit(playerID string) error {
+	if s.config.EntryFee == 0 {
+		return nil
+	}
+	if err := s.ledger.Debit(playerID, s.config.EntryFee, "Sit&Go entry: "+s.config.Name); err != nil {
+		return &TableError{"ENTRY_FEE_FAILED", "Could not charge the entry fee: " + err.Error()}
+	}
+	return nil
+}
+
+// refund returns a player's entry fee
+func (s *SitAndGo) refund(playerID string) {
+	if s.config.EntryFee == 0 {
+		return
+	}
+	if err := s.ledger.Credit(playerID, s.config.EntryFee, "Sit&Go refund: "+s.config.Name); err != nil {
+		log.Printf("Sit&Go %s: failed to refund entry fee to %s: %v", s.config.ID, playerID, err)
+	}
+}
+
+// OnTournamentEvent passes the tournament's events on and pays the prizes
+// once it finishes
+func (s *SitAndGo) OnTournamentEvent(event *TournamentEvent) {
+	if event.Type == "tournament_finished" {
+		if standings, ok := event.Data["standings"].([]TournamentEntrant); ok {
+			s.payOut(standings)
+		}
+		s.tableManager.removeSitAndGo(s.config.ID)
+	}
+	if s.events != nil {
+		s.events.OnTournamentEvent(event)
+	}
+}
+
+// payOut credits each paid finisher's prize
+func (s *SitAndGo) payOut(standings []TournamentEntrant) {
+	if s.ledger == nil {
+		return
+	}
+	for _, entrant := range standings {
+		if entrant.Payout <= 0 {
+			continue
+		}
+		description := fmt.Sprintf("Sit&Go %s: finished #%d", s.config.Name, entrant.Position)
+		if err := s.ledger.Credit(entrant.PlayerID, entrant.Payout, description); err != nil {
+			log.Printf("Sit&Go %s: failed to pay %d diamonds to %s: %v", s.config.ID, entrant.Payout, entrant.PlayerID, err)
+		}
+	}
+}
+
+// Info returns the lobby view of the Sit&Go
+func (s *SitAndGo) Info() SitAndGoInfo {
+	s.mu.Lock()
+	registered := s.registered
+	s.mu.Unlock()
+
+	return SitAndGoInfo{
+		ID:            s.config.ID,
+		Name:          s.config.Name,
+		GameType:      s.tournament.config.GameType,
+		Status:        s.tournament.Status(),
+		Seats:         s.config.Seats,
+		Registered:    registered,
+		EntryFee:      s.config.EntryFee,
+		StartingStack: s.config.StartingStack,
+		PrizePool:     s.config.EntryFee * s.config.Seats,
+		Payouts:       calculatePayouts(s.config.EntryFee*s.config.Seats, s.config.Payouts),
+	}
+}
+
+// SetDiamondLedger sets the ledger charging Sit&Go entry fees and paying prizes
+func (tm *ActorTableManager) SetDiamondLedger(ledger DiamondLedger) {
+	tm.mu.Lock()
+	tm.ledger = ledger
+	tm.mu.Unlock()
+}
+
+// CreateSitAndGo opens a Sit&Go whose clock broadcasts through hub and whose
+// events go to events
+func (tm *ActorTableManager) CreateSitAndGo(config SitAndGoConfig, hub WebSocketHub, events TournamentEventSink) (*SitAndGo, error) {
+	if config.ID == "" {
+		config.ID = "sng_" + tm.generateTableID()
+	}
+
+	tm.mu.RLock()
+	ledger := tm.ledger
+	_, exists := tm.sitAndGos[config.ID]
+	tm.mu.RUnlock()
+	if exists {
+		return nil, &TableError{"SIT_AND_GO_EXISTS", "A Sit&Go with this ID already exists"}
+	}
+
+	sitAndGo, err := NewSitAndGo(config, tm, hub, ledger, events)
+	if err != nil {
+		return nil, err
+	}
+	// Catch a bad name or settings now rather than when the last seat fills
+	if err := tm.validator.ValidateTableCreateRequest(sitAndGo.tournament.tableRequest(1)); err != nil {
+		sitAndGo.tournament.detach()
+		return nil, err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if _, exists := tm.sitAndGos[config.ID]; exists {
+		sitAndGo.tournament.detach()
+		return nil, &TableError{"SIT_AND_GO_EXISTS", "A Sit&Go with this ID already exists"}
+	}
+	tm.sitAndGos[config.ID] = sitAndGo
+	return sitAndGo, nil
+}
+
+// removeSitAndGo forgets a finished Sit&Go
+func (tm *ActorTableManager) removeSitAndGo(id string) {
+	tm.mu.Lock()
+	delete(tm.sitAndGos, id)
+	tm.mu.Unlock()
+}
+
+// GetSitAndGo returns a Sit&Go by ID
+func (tm *ActorTableManager) GetSitAndGo(id string) (*SitAndGo, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	sitAndGo, ok := tm.sitAndGos[id]
+	return sitAndGo, ok
+}
+
+// ListSitAndGos returns the open and running Sit&Gos, fullest first
+func (tm *ActorTableManager) ListSitAndGos() []SitAndGoInfo {
+	tm.mu.RLock()
+	sitAndGos := make([]*SitAndGo, 0, len(tm.sitAndGos))
+	for _, sitAndGo := range tm.sitAndGos {
+		sitAndGos = append(sitAndGos, sitAndGo)
+	}
+	tm.mu.RUnlock()
+
+	infos := make([]SitAndGoInfo, 0, len(sitAndGos))
+	for _, sitAndGo := range sitAndGos {
+		infos = append(infos, sitAndGo.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Registered != infos[j].Registered {
+			return infos[i].Registered > infos[j].Registered
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLedger struct {
+	mu       sync.Mutex
+	balances map[string]int
+	entries  []string
+}
+
+func newFakeLedger(balances map[string]int) *fakeLedger {
+	return &fakeLedger{balances: balances}
+}
+
+func (l *fakeLedger) Debit(playerID string, amount int, description string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.balances[playerID] < amount {
+		return errors.New("insufficient balance")
+	}
+	l.balances[playerID] -= amount
+	l.entries = append(l.entries, fmt.Sprintf("%s -%d %s", playerID, amount, description))
+	return nil
+}
+
+func (l *fakeLedger) Credit(playerID string, amount int, description string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.balances[playerID] += amount
+	l.entries = append(l.entries, fmt.Sprintf("%s +%d %s", playerID, amount, description))
+	return nil
+}
+
+func (l *fakeLedger) balance(playerID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[playerID]
+}
+
+func testSitAndGoConfig() SitAndGoConfig {
+	return SitAndGoConfig{
+		ID:            "sng1",
+		Name:          "Turbo Three",
+		Seats:         3,
+		EntryFee:      100,
+		StartingStack: 1500,
+		Payouts:       []int{70, 30},
+		Clock:         testTournamentConfig(),
+	}
+}
+
+func TestSitAndGoStartsWhenFull(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ledger := newFakeLedger(map[string]int{"player1": 500, "player2": 500, "player3": 500, "broke": 50})
+	manager.SetDiamondLedger(ledger)
+	sink := &recordingTournamentSink{}
+	sitAndGo, err := manager.CreateSitAndGo(testSitAndGoConfig(), nil, sink)
+	require.NoError(t, err)
+	defer sitAndGo.Tournament().Clock().Stop()
+	ctx := context.Background()
+
+	require.NoError(t, sitAndGo.Join(ctx, "player1", "player1"))
+	require.NoError(t, sitAndGo.Join(ctx, "player2", "player2"))
+	assert.Equal(t, 400, ledger.balance("player1"))
+	assert.Empty(t, manager.GetTables(), "the table opens only when full")
+
+	assert.Error(t, sitAndGo.Join(ctx, "broke", "broke"))
+	assert.Equal(t, 50, ledger.balance("broke"))
+	assert.Error(t, sitAndGo.Join(ctx, "player1", "player1"), "one entry per player")
+	assert.Equal(t, 400, ledger.balance("player1"), "a duplicate entry is refunded")
+
+	require.NoError(t, sitAndGo.Join(ctx, "player3", "player3"))
+	assert.Equal(t, TournamentRunning, sitAndGo.Info().Status)
+	assert.Len(t, sink.ofType("tournament_started"), 1)
+
+	tables := manager.GetTables()
+	require.Len(t, tables, 1)
+	assert.Equal(t, 3, tables[0].GetPlayerCount())
+	assert.Equal(t, 3, tables[0].MaxPlayers)
+
+	assert.Error(t, sitAndGo.Join(ctx, "late", "late"))
+	assert.Error(t, sitAndGo.Leave("player1"), "no refunds once started")
+}
+
+func TestSitAndGoLeaveRefundsEntry(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ledger := newFakeLedger(map[string]int{"player1": 100})
+	manager.SetDiamondLedger(ledger)
+	sitAndGo, err := manager.CreateSitAndGo(testSitAndGoConfig(), nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, sitAndGo.Join(context.Background(), "player1", "player1"))
+	assert.Zero(t, ledger.balance("player1"))
+	require.NoError(t, sitAndGo.Leave("player1"))
+	assert.Equal(t, 100, ledger.balance("player1"))
+	assert.Zero(t, sitAndGo.Info().Registered)
+	assert.Error(t, sitAndGo.Leave("player1"))
+}
+
+func TestSitAndGoPaysFinishersInDiamonds(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	ledger := newFakeLedger(map[string]int{"player1": 100, "player2": 100, "player3": 100})
+	manager.SetDiamondLedger(ledger)
+	sink := &recordingTournamentSink{}
+	sitAndGo, err := manager.CreateSitAndGo(testSitAndGoConfig(), nil, sink)
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, playerID := range []string{"player1", "player2", "player3"} {
+		require.NoError(t, sitAndGo.Join(ctx, playerID, playerID))
+	}
+	assert.Len(t, manager.ListSitAndGos(), 1)
+
+	require.NoError(t, sitAndGo.Tournament().Eliminate(ctx, "player2"))
+	assert.Zero(t, ledger.balance("player2"), "third place is not paid")
+	require.NoError(t, sitAndGo.Tournament().Eliminate(ctx, "player3"))
+
+	assert.Equal(t, 210, ledger.balance("player1"), "winner takes 70% of 300")
+	assert.Equal(t, 90, ledger.balance("player3"))
+	assert.Len(t, sink.ofType("tournament_finished"), 1, "events still reach the sink")
+	assert.Empty(t, manager.ListSitAndGos())
+	_, found := manager.GetSitAndGo("sng1")
+	assert.False(t, found)
+}
+
+func TestCreateSitAndGoValidation(t *testing.T) {
+	manager := NewActorTableManager(nil)
+
+	_, err := manager.CreateSitAndGo(testSitAndGoConfig(), nil, nil)
+	assert.Error(t, err, "paid games need a ledger")
+
+	manager.SetDiamondLedger(newFakeLedger(map[string]int{}))
+	config := testSitAndGoConfig()
+	config.Name = "<script>"
+	_, err = manager.CreateSitAndGo(config, nil, nil)
+	assert.Error(t, err)
+
+	config = testSitAndGoConfig()
+	config.Payouts = []int{40, 30, 20, 10}
+	_, err = manager.CreateSitAndGo(config, nil, nil)
+	assert.Error(t, err, "more paid places than seats")
+
+	config = testSitAndGoConfig()
+	config.Payouts = nil
+	sitAndGo, err := manager.CreateSitAndGo(config, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{300}, sitAndGo.Info().Payouts, "three seats pay only the winner")
+
+	_, err = manager.CreateSitAndGo(config, nil, nil)
+	assert.Error(t, err, "IDs are unique")
+}
+
+func TestTableWebSocketHandlerSitAndGoFlow(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	manager.SetDiamondLedger(newFakeLedger(map[string]int{"player1": 100}))
+	handler := NewTableWebSocketHandler(manager, newRecordingHub())
+	conn := NewMockConnection("player1", "player1")
+	ctx := context.Background()
+
+	resp := handler.handleCreateSitAndGo(ctx, conn, &WebSocketMessage{Data: map[string]interface{}{
+		"name": "Heads Up", "seats": 2, "entry_fee": 100, "starting_stack": 1000,
+	}})
+	require.True(t, resp.Success, resp.Error)
+	info := resp.Data.(SitAndGoInfo)
+	assert.Equal(t, 200, info.PrizePool)
+	assert.Equal(t, GameTypeTexasHoldem, info.GameType)
+
+	resp = handler.handleJoinSitAndGo(ctx, conn, &WebSocketMessage{Data: map[string]interface{}{"sit_and_go_id": info.ID}})
+	require.True(t, resp.Success, resp.Error)
+	assert.Equal(t, 1, resp.Data.(SitAndGoInfo).Registered)
+
+	resp = handler.handleListSitAndGos(ctx, conn, &WebSocketMessage{})
+	require.True(t, resp.Success)
+	assert.Len(t, resp.Data.(map[string]interface{})["sit_and_gos"], 1)
+
+	resp = handler.handleLeaveSitAndGo(ctx, conn, &WebSocketMessage{Data: map[string]interface{}{"sit_and_go_id": info.ID}})
+	assert.True(t, resp.Success)
+
+	resp = handler.handleJoinSitAndGo(ctx, conn, &WebSocketMessage{Data: map[string]interface{}{"sit_and_go_id": "missing"}})
+	assert.False(t, resp.Success)
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"context"
+)
+
+// sitAndGoCreateRequest is what a player may choose when opening a Sit&Go;
+// the blind structure is always the default one
+type sitAndGoCreateRequest struct {
+	Name          string   `json:"name"`
+	GameType      GameType `json:"game_type"`
+	Seats         int      `json:"seats"`
+	EntryFee      int      `json:"entry_fee"`
+	StartingStack int      `json:"starting_stack"`
+	Payouts       []int    `json:"payouts"`
+}
+
+// sitAndGoRequest identifies the Sit&Go a message refers to
+type sitAndGoRequest struct {
+	SitAndGoID string `json:"sit_and_go_id"`
+}
+
+// CreateSitAndGo opens a Sit&Go whose clock and events are broadcast to its
+// table room by this handler
+func (h *TableWebSocketHandler) CreateSitAndGo(config SitAndGoConfig) (*SitAndGo, error) {
+	return h.tableManager.CreateSitAndGo(config, h.hub, h)
+}
+
+// lookupSitAndGo parses a Sit&Go request and finds the Sit&Go
+func (h *TableWebSocketHandler) lookupSitAndGo(msg *WebSocketMessage) (*SitAndGo, *WebSocketMessage) {
+	var req sitAndGoRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return nil, h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+	sitAndGo, ok := h.tableManager.GetSitAndGo(req.SitAndGoID)
+	if !ok {
+		return nil, h.errorResponse(msg.RequestID, "SIT_AND_GO_NOT_FOUND", "Sit&Go not found")
+	}
+	return sitAndGo, nil
+}
+
+// handleCreateSitAndGo opens a Sit&Go; the creator still has to join it
+func (h *TableWebSocketHandler) handleCreateSitAndGo(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req sitAndGoCreateRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	sitAndGo, err := h.CreateSitAndGo(SitAndGoConfig{
+		Name:          req.Name,
+		GameType:      req.GameType,
+		Seats:         req.Seats,
+		EntryFee:      req.EntryFee,
+		StartingStack: req.StartingStack,
+		Payouts:       req.Payouts,
+	})
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "CREATE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "sit_and_go_created", sitAndGo.Info())
+}
+
+// handleListSitAndGos lists the open and running Sit&Gos
+func (h *TableWebSocketHandler) handleListSitAndGos(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	return h.successResponse(msg.RequestID, "sit_and_go_list", map[string]interface{}{
+		"sit_and_gos": h.tableManager.ListSitAndGos(),
+	})
+}
+
+// handleJoinSitAndGo pays the caller's entry fee and takes a seat
+func (h *TableWebSocketHandler) handleJoinSitAndGo(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	sitAndGo, errResp := h.lookupSitAndGo(msg)
+	if errResp != nil {
+		return errResp
+	}
+	if err := sitAndGo.Join(ctx, conn.GetUserID(), conn.GetUsername()); err != nil {
+		return h.errorResponse(msg.RequestID, "JOIN_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "sit_and_go_joined", sitAndGo.Info())
+}
+
+// handleLeaveSitAndGo withdraws the caller before the game starts
+func (h *TableWebSocketHandler) handleLeaveSitAndGo(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	sitAndGo, errResp := h.lookupSitAndGo(msg)
+	if errResp != nil {
+		return errResp
+	}
+	if err := sitAndGo.Leave(conn.GetUserID()); err != nil {
+		return h.errorResponse(msg.RequestID, "LEAVE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "sit_and_go_left", sitAndGo.Info())
+}
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ActionSitOut keeps a player's seat and stack while they skip hands; it
+// takes effect from the next hand. ActionSitIn deals them back in.
+const (
+	ActionSitOut TexasHoldemAction = "sit_out"
+	ActionSitIn  TexasHoldemAction = "sit_in"
+)
+
+// SitOutEngine is an engine that deals around players sitting out
+type SitOutEngine interface {
+	SetSittingOut(playerID string, sittingOut bool) error
+}
+
+// SetSittingOutCommand takes a seated player out of the deal or back in
+type SetSittingOutCommand struct {
+	PlayerID   string
+	SittingOut bool
+	At         time.Time
+	Response   chan interface{}
+}
+
+func (cmd *SetSittingOutCommand) Execute(table *GameTable) interface{} {
+	if table.Settings.TournamentMode {
+		return &TableError{"SIT_OUT_NOT_ALLOWED", "Tournament players cannot sit out"}
+	}
+	for i := range table.PlayerSlots {
+		slot := &table.PlayerSlots[i]
+		if slot.PlayerID != cmd.PlayerID {
+			continue
+		}
+		if slot.SittingOut == cmd.SittingOut {
+			return &TableError{"SIT_OUT_UNCHANGED", "Player is already " + sitOutState(cmd.SittingOut)}
+		}
+		slot.SittingOut = cmd.SittingOut
+		slot.SatOutAt = time.Time{}
+		if cmd.SittingOut {
+			slot.SatOutAt = cmd.At
+		}
+		table.UpdatedAt = time.Now()
+		return nil
+	}
+	return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+}
+
+// sitOutState names a seat's sitting-out state
+func sitOutState(sittingOut bool) string {
+	if sittingOut {
+		return "sitting out"
+	}
+	return "sitting in"
+}
+
+// SetSittingOut sends a sit-out change to the table actor
+func (ta *TableActor) SetSittingOut(ctx context.Context, playerID string, sittingOut bool, at time.Time) error {
+	cmd := &SetSittingOutCommand{
+		PlayerID:   playerID,
+		SittingOut: sittingOut,
+		At:         at,
+		Response:   make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SitOut stops dealing a seated player in from the next hand on. They keep
+// their seat and stack, and blinds pass them by until they sit back in.
+func (tm *ActorTableManager) SitOut(ctx context.Context, tableID, playerID string) error {
+	return tm.setSittingOut(ctx, tableID, playerID, true)
+}
+
+// SitIn deals a player sitting out back in from the next hand. A player who
+// missed the big blind while away posts one to come back.
+func (tm *ActorTableManager) SitIn(ctx context.Context, tableID, playerID string) error {
+	return tm.setSittingOut(ctx, tableID, playerID, false)
+}
+
+func (tm *ActorTableManager) setSittingOut(ctx context.Context, tableID, playerID string, sittingOut bool) error {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return ErrTableNotFound
+	}
+
+	now := time.Now()
+	if err := actor.SetSittingOut(ctx, playerID, sittingOut, now); err != nil {
+		return err
+	}
+
+	eventType := "player_sat_in"
+	if sittingOut {
+		eventType = "player_sat_out"
+	}
+	tm.BroadcastGameEvent(actor.table, &GameEvent{
+		Type:     eventType,
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"table_id":  tableID,
+			"player_id": playerID,
+		},
+		Timestamp: now,
+	})
+	return nil
+}
+
+// SetSittingOut marks a seated player as sitting out or back in; hands in
+// progress are played out and the change applies from the next deal
+func (the *TexasHoldemEngine) SetSittingOut(playerID string, sittingOut bool) error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	player := the.getHoldemPlayer(playerID)
+	if player == nil {
+		return fmt.Errorf("player not found")
+	}
+	player.SittingOut = sittingOut
+	return nil
+}
+
+// playersToDeal counts the seated players with chips who are not sitting out
+func (the *TexasHoldemEngine) playersToDeal() int {
+	count := 0
+	for _, player := range the.players {
+		if holdemPlayer := the.getHoldemPlayer(player.ID); holdemPlayer != nil && holdemPlayer.Chips > 0 && !holdemPlayer.SittingOut {
+			count++
+		}
+	}
+	return count
+}
+
+// markMissedBlinds records that players sitting out in the seats the big
+// blind moved past, after previousBigBlind up to this hand's, owe a big blind
+func (the *TexasHoldemEngine) markMissedBlinds(previousBigBlind int) {
+	for _, player := range the.players {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		if holdemPlayer == nil || !holdemPlayer.SittingOut || holdemPlayer.Chips <= 0 {
+			continue
+		}
+		if seatPassed(previousBigBlind, the.bigBlindPos, player.Position) {
+			holdemPlayer.OwesBlind = true
+		}
+	}
+}
+
+// seatPassed reports whether seat lies after from and before to, going
+// around the table
+func seatPassed(from, to, seat int) bool {
+	if from < to {
+		return seat > from && seat < to
+	}
+	return seat > from || seat < to
+}
+
+// postReturningBlinds has players back from sitting out who owe a blind post
+// a live big blind, unless they are in the blinds already. It returns the
+// blinds posted.
+func (the *TexasHoldemEngine) postReturningBlinds() []map[string]interface{} {
+	posted := make([]map[string]interface{}, 0)
+	for _, player := range the.getActivePlayers() {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		if holdemPlayer == nil || !holdemPlayer.OwesBlind {
+			continue
+		}
+		holdemPlayer.OwesBlind = false
+		if player.Position == the.bigBlindPos || player.Position == the.smallBlindPos || player.Position == the.straddleSeat {
+			continue
+		}
+		amount := the.postBlind(holdemPlayer, the.bigBlind)
+		the.currentBet = max(the.currentBet, amount)
+		posted = append(posted, map[string]interface{}{
+			"playerID": player.ID,
+			"amount":   amount,
+		})
+	}
+	return posted
+}
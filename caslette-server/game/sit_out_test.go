@@ -0,0 +1,111 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSittingOutSkipsDealAndBlinds(t *testing.T) {
+	engine := newButtonTable(t, 4)
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 0, 1, 2)
+	foldHand(t, engine)
+
+	require.NoError(t, engine.SetSittingOut("d", true))
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 1, 2, 0)
+	assert.Empty(t, engine.getHoldemPlayer("d").Hand.Cards, "a player sitting out is not dealt in")
+	assert.Len(t, engine.getActivePlayers(), 3)
+	assert.True(t, engine.getHoldemPlayer("d").OwesBlind, "the big blind passed their seat")
+	assert.Equal(t, 1000, engine.getHoldemPlayer("d").Chips)
+	foldHand(t, engine)
+
+	require.NoError(t, engine.SetSittingOut("d", false))
+	require.NoError(t, engine.Start())
+	assertPositions(t, engine, 2, 0, 1)
+	returning := engine.getHoldemPlayer("d")
+	assert.Len(t, returning.Hand.Cards, 2)
+	assert.False(t, returning.OwesBlind)
+	assert.Equal(t, 10, returning.CurrentBet, "the returning player posts a big blind")
+	assert.Equal(t, 25, engine.pot)
+	blinds := lastEventOfType(engine, "blinds_posted")
+	require.NotNil(t, blinds)
+	assert.Equal(t, []map[string]interface{}{{"playerID": "d", "amount": 10}}, blinds.Data["returning"])
+}
+
+func TestStartNeedsTwoPlayersSittingIn(t *testing.T) {
+	engine := newButtonTable(t, 2)
+	require.NoError(t, engine.SetSittingOut("a", true))
+	assert.Error(t, engine.Start())
+	assert.False(t, engine.IsGameOver(), "a player sitting out still has chips")
+	assert.Error(t, engine.SetSittingOut("z", true))
+}
+
+func TestSeatPassed(t *testing.T) {
+	assert.True(t, seatPassed(1, 4, 3))
+	assert.False(t, seatPassed(1, 4, 4), "the new big blind posts it")
+	assert.False(t, seatPassed(1, 4, 5))
+	assert.True(t, seatPassed(4, 1, 5), "going around the table")
+	assert.True(t, seatPassed(4, 1, 0))
+	assert.False(t, seatPassed(4, 1, 2))
+}
+
+func TestManagerSitOutAndSitIn(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1", "p2")
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	require.NoError(t, manager.SitOut(context.Background(), table.ID, "p2"))
+	require.Len(t, broadcaster.ofType("player_sat_out"), 1)
+	var tableErr *TableError
+	require.True(t, errors.As(manager.SitOut(context.Background(), table.ID, "p2"), &tableErr))
+	assert.Equal(t, "SIT_OUT_UNCHANGED", tableErr.Code)
+	assert.ErrorIs(t, manager.SitOut(context.Background(), "missing", "p2"), ErrTableNotFound)
+
+	slots := table.GetDetailedInfo()["player_slots"].([]PlayerSlot)
+	sittingOut := map[string]bool{}
+	for _, slot := range slots {
+		if slot.PlayerID != "" {
+			sittingOut[slot.PlayerID] = slot.SittingOut
+		}
+	}
+	assert.Equal(t, map[string]bool{"p0": false, "p1": false, "p2": true}, sittingOut)
+	assert.Len(t, engine.getHoldemPlayer("p2").Hand.Cards, 2, "the hand under way is played out")
+
+	foldHand(t, engine)
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Empty(t, engine.getHoldemPlayer("p2").Hand.Cards)
+	assert.Len(t, engine.getActivePlayers(), 2)
+
+	require.NoError(t, manager.SitIn(context.Background(), table.ID, "p2"))
+	require.Len(t, broadcaster.ofType("player_sat_in"), 1)
+	foldHand(t, engine)
+	require.Eventually(t, func() bool { return handsStarted(broadcaster) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Len(t, engine.getHoldemPlayer("p2").Hand.Cards, 2, "dealt back in")
+}
+
+func TestHandLoopWaitsWhenTooFewPlayersSitIn(t *testing.T) {
+	manager, table, broadcaster := newHandLoopTable(t, 20*time.Millisecond, "p0", "p1")
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, manager.tryStartGame(table))
+
+	require.NoError(t, manager.SitOut(context.Background(), table.ID, "p1"))
+	foldHand(t, engine)
+	require.Eventually(t, func() bool { return len(broadcaster.ofType("waiting_for_players")) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, TableStatusWaiting, table.Status)
+}
+
+func TestTournamentPlayersCannotSitOut(t *testing.T) {
+	table := NewGameTable("t1", "Tournament", GameTypeTexasHoldem, "creator", DefaultTableSettings())
+	table.Settings.TournamentMode = true
+	table.PlayerSlots[0].PlayerID = "p0"
+
+	result := (&SetSittingOutCommand{PlayerID: "p0", SittingOut: true}).Execute(table)
+	require.IsType(t, &TableError{}, result)
+	assert.Equal(t, "SIT_OUT_NOT_ALLOWED", result.(*TableError).Code)
+}
@@ -0,0 +1,557 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// DefaultSnapshotInterval is how often open tables are snapshotted for
+// crash recovery
+const DefaultSnapshotInterval = 15 * time.Second
+
+// snapshotTimeout bounds how long one table may take to snapshot
+const snapshotTimeout = 5 * time.Second
+
+// baseSnapshot is the part of a base engine's state that survives a restart.
+// Past events are not kept apart from the open hand's log; sequence numbers
+// carry on from the last one.
+type baseSnapshot struct {
+	GameID      string                 `json:"game_id"`
+	State       GameState              `json:"state"`
+	Players     []*Player              `json:"players,omitempty"`
+	GameData    map[string]interface{} `json:"game_data"`
+	Config      map[string]interface{} `json:"config"`
+	EventSeq    uint64                 `json:"event_seq"`
+	CurrentTurn int                    `json:"current_turn"`
+	HandLog     []GameEvent            `json:"hand_log,omitempty"`
+	HandLogOpen bool                   `json:"hand_log_open,omitempty"`
+	HandID      string                 `json:"hand_id,omitempty"`
+}
+
+// snapshot captures the base engine's state, players in seat order
+func (b *BaseGameEngine) snapshot() baseSnapshot {
+	players := make([]*Player, 0, len(b.players))
+	for _, player := range b.players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Position < players[j].Position })
+	return baseSnapshot{
+		GameID:      b.gameID,
+		State:       b.state,
+		Players:     players,
+		GameData:    b.gameData,
+		Config:      b.config,
+		EventSeq:    b.eventSeq,
+		CurrentTurn: b.currentTurn,
+		HandLog:     b.handLog,
+		HandLogOpen: b.handLogOpen,
+		HandID:      b.handID,
+	}
+}
+
+// restore replaces the base engine's state with a snapshot's. Typed player
+// state is dropped; the game engine restores its own.
+func (b *BaseGameEngine) restore(snapshot baseSnapshot) {
+	b.gameID = snapshot.GameID
+	b.state = snapshot.State
+	b.players = make(map[string]*Player, len(snapshot.Players))
+	for _, player := range snapshot.Players {
+		b.players[player.ID] = player
+	}
+	b.states = make(map[string]PlayerState)
+	b.gameData = snapshot.GameData
+	if b.gameData == nil {
+		b.gameData = make(map[string]interface{})
+	}
+	b.config = snapshot.Config
+	if b.config == nil {
+		b.config = make(map[string]interface{})
+	}
+	b.eventSeq = snapshot.EventSeq
+	b.currentTurn = snapshot.CurrentTurn
+	b.handLog = snapshot.HandLog
+	b.handLogOpen = snapshot.HandLogOpen
+	b.logMu.Lock()
+	b.handID = snapshot.HandID
+	b.handLive = snapshot.HandLogOpen
+	b.logMu.Unlock()
+}
+
+// Serialize encodes the base engine's state as JSON
+func (b *BaseGameEngine) Serialize() ([]byte, error) {
+	return json.Marshal(b.snapshot())
+}
+
+// Deserialize loads state encoded by Serialize
+func (b *BaseGameEngine) Deserialize(data []byte) error {
+	var snapshot baseSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid engine snapshot: %w", err)
+	}
+	b.restore(snapshot)
+	return nil
+}
+
+// holdemSnapshot is a Texas Hold'em engine's state: the hand in progress
+// with the deck still to be dealt, bets, pot and board. Settings the table
+// configures the engine with, such as the turn limit and rake, are not kept.
+type holdemSnapshot struct {
+	Base           baseSnapshot             `json:"base"`
+	Players        []*TexasHoldemPlayer     `json:"players"`
+	Deck           []Card                   `json:"deck"`
+	DeckSeed       []byte                   `json:"deck_seed,omitempty"`
+	CommunityCards []Card                   `json:"community_cards"`
+	Pot            int                      `json:"pot"`
+	CurrentBet     int                      `json:"current_bet"`
+	DealerPos      int                      `json:"dealer_pos"`
+	SmallBlindPos  int                      `json:"small_blind_pos"`
+	BigBlindPos    int                      `json:"big_blind_pos"`
+	SmallBlindSeat int                      `json:"small_blind_seat"`
+	HandsDealt     int                      `json:"hands_dealt"`
+	ActionPos      int                      `json:"action_pos"`
+	LastRaise      int                      `json:"last_raise"`
+	FullRaises     int                      `json:"full_raises"`
+	ActedAtRaise   map[string]int           `json:"acted_at_raise,omitempty"`
+	RoundState     TexasHoldemState         `json:"round_state"`
+	SmallBlind     int                      `json:"small_blind"`
+	BigBlind       int                      `json:"big_blind"`
+	Ante           int                      `json:"ante"`
+	Winners        []string                 `json:"winners,omitempty"`
+	ShowChoices    map[string]bool          `json:"show_choices,omitempty"`
+	Revealed       map[string]bool          `json:"revealed,omitempty"`
+	LastAggressor  string                   `json:"last_aggressor,omitempty"`
+	Hand           *HandRecord              `json:"hand,omitempty"`
+	LastHand       *HandRecord              `json:"last_hand,omitempty"`
+	TimeBanks      map[string]time.Duration `json:"time_banks,omitempty"`
+}
+
+// Serialize encodes the hand in progress as JSON
+func (the *TexasHoldemEngine) Serialize() ([]byte, error) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+
+	base := the.snapshot()
+	players := make([]*TexasHoldemPlayer, 0, len(base.Players))
+	for _, player := range base.Players {
+		if holdemPlayer := the.getHoldemPlayer(player.ID); holdemPlayer != nil {
+			players = append(players, holdemPlayer)
+		}
+	}
+	base.Players = nil // Kept with their poker state
+
+	winners := make([]string, 0, len(the.winners))
+	for _, winner := range the.winners {
+		winners = append(winners, winner.ID)
+	}
+
+	return json.Marshal(holdemSnapshot{
+		Base:           base,
+		Players:        players,
+		Deck:           the.deck.cards,
+		DeckSeed:       the.deck.seed,
+		CommunityCards: the.communityCards.Cards,
+		Pot:            the.pot,
+		CurrentBet:     the.currentBet,
+		DealerPos:      the.dealerPos,
+		SmallBlindPos:  the.smallBlindPos,
+		BigBlindPos:    the.bigBlindPos,
+		SmallBlindSeat: the.smallBlindSeat,
+		HandsDealt:     the.handsDealt,
+		ActionPos:      the.actionPos,
+		LastRaise:      the.lastRaise,
+		FullRaises:     the.fullRaises,
+		ActedAtRaise:   the.actedAtRaise,
+		RoundState:     the.roundState,
+		SmallBlind:     the.smallBlind,
+		BigBlind:       the.bigBlind,
+		Ante:           the.ante,
+		Winners:        winners,
+		ShowChoices:    the.showChoices,
+		Revealed:       the.revealed,
+		LastAggressor:  the.lastAggressor,
+		Hand:           the.hand,
+		LastHand:       the.lastHand,
+		TimeBanks:      the.timeBanks,
+	})
+}
+
+// Deserialize loads a hand encoded by Serialize into the engine and restarts
+// the turn timer of the player to act, or the runout of a hand all in. Showdown hands are not kept, so
+// players who mucked before a restart cannot show their cards after it.
+func (the *TexasHoldemEngine) Deserialize(data []byte) error {
+	var snapshot holdemSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid engine snapshot: %w", err)
+	}
+	for _, player := range snapshot.Players {
+		if player == nil || player.Player == nil || player.ID == "" {
+			return fmt.Errorf("invalid engine snapshot: player without an ID")
+		}
+	}
+
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	the.stopTurnTimer()
+	the.stopRunout()
+
+	the.restore(snapshot.Base)
+	for _, player := range snapshot.Players {
+		if player.Hand == nil {
+			player.Hand = NewHand()
+		}
+		the.players[player.ID] = player.Player
+		the.states[player.ID] = player
+	}
+
+	the.deck.cards = snapshot.Deck
+	the.deck.seed = snapshot.DeckSeed
+	the.communityCards.Cards = snapshot.CommunityCards
+	the.pot = snapshot.Pot
+	the.currentBet = snapshot.CurrentBet
+	the.dealerPos = snapshot.DealerPos
+	the.smallBlindPos = snapshot.SmallBlindPos
+	the.bigBlindPos = snapshot.BigBlindPos
+	the.smallBlindSeat = snapshot.SmallBlindSeat
+	the.handsDealt = snapshot.HandsDealt
+	the.actionPos = snapshot.ActionPos
+	the.lastRaise = snapshot.LastRaise
+	the.fullRaises = snapshot.FullRaises
+	the.actedAtRaise = snapshot.ActedAtRaise
+	the.roundState = snapshot.RoundState
+	the.smallBlind = snapshot.SmallBlind
+	the.bigBlind = snapshot.BigBlind
+	the.ante = snapshot.Ante
+	the.showChoices = snapshot.ShowChoices
+	the.revealed = snapshot.Revealed
+	the.lastAggressor = snapshot.LastAggressor
+	the.hand = snapshot.Hand
+	the.lastHand = snapshot.LastHand
+	the.timeBanks = snapshot.TimeBanks
+	the.showdownHands = nil
+
+	the.winners = the.winners[:0]
+	for _, playerID := range snapshot.Winners {
+		if winner := the.getHoldemPlayer(playerID); winner != nil {
+			the.winners = append(the.winners, winner)
+		}
+	}
+
+	return the.continueHand()
+}
+
+// TableSnapshot is an open table saved for crash recovery: the table with
+// its seats and settings, its engine's state and the buy-ins in escrow
+type TableSnapshot struct {
+	TableID  string           `json:"table_id"`
+	GameType GameType         `json:"game_type"`
+	Table    []byte           `json:"table"`  // GameTable as JSON
+	Engine   []byte           `json:"engine"` // GameEngine.Serialize output
+	Escrow   map[string]int64 `json:"escrow"` // Player ID -> chips bought in
+
+	// The table's bcrypt password hash, which the table JSON leaves out
+	PasswordHash string    `json:"password_hash,omitempty"`
+	SavedAt      time.Time `json:"saved_at"`
+}
+
+// TableSnapshotStore persists table snapshots, one per table
+type TableSnapshotStore interface {
+	SaveSnapshot(snapshot TableSnapshot) error
+	LoadSnapshots() ([]TableSnapshot, error)
+	DeleteSnapshot(tableID string) error
+}
+
+// SnapshotTableCommand encodes the table as JSON
+type SnapshotTableCommand struct {
+	Response chan interface{}
+}
+
+func (cmd *SnapshotTableCommand) Execute(table *GameTable) interface{} {
+	encoded, err := json.Marshal(table)
+	if err != nil {
+		return err
+	}
+	return encoded
+}
+
+// Snapshot encodes the table through the actor, so it is not read mid-change
+func (ta *TableActor) Snapshot(ctx context.Context) ([]byte, error) {
+	cmd := &SnapshotTableCommand{
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+		encoded, _ := result.([]byte)
+		return encoded, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// snapshotTable captures one table. Tournament tables are left to their
+// tournament and not snapshotted.
+func (tm *ActorTableManager) snapshotTable(actor *TableActor) (*TableSnapshot, error) {
+	table := actor.table
+	if table.Settings.TournamentMode || table.GameEngine == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	encoded, err := actor.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := table.GameEngine.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &TableSnapshot{
+		TableID:  table.ID,
+		GameType: table.GameType,
+		Table:    encoded,
+		Engine:   engine,
+		Escrow:   tm.escrow.Balances(table.ID),
+		SavedAt:  time.Now(),
+
+		PasswordHash: table.Settings.PasswordHash,
+	}, nil
+}
+
+// SnapshotTables saves every open cash table to the snapshot store,
+// returning how many were saved
+func (tm *ActorTableManager) SnapshotTables() int {
+	tm.mu.RLock()
+	store := tm.snapshots
+	actors := make([]*TableActor, 0, len(tm.actors))
+	for _, actor := range tm.actors {
+		actors = append(actors, actor)
+	}
+	tm.mu.RUnlock()
+	if store == nil {
+		return 0
+	}
+
+	saved := 0
+	for _, actor := range actors {
+		if tm.saveSnapshot(store, actor) {
+			saved++
+		}
+	}
+	return saved
+}
+
+// saveSnapshot snapshots one table to store, reporting whether it was saved
+func (tm *ActorTableManager) saveSnapshot(store TableSnapshotStore, actor *TableActor) bool {
+	snapshot, err := tm.snapshotTable(actor)
+	if err != nil {
+		log.Printf("Table %s: snapshot failed: %v", actor.table.ID, err)
+		return false
+	}
+	if snapshot == nil {
+		return false
+	}
+	if err := store.SaveSnapshot(*snapshot); err != nil {
+		log.Printf("Table %s: failed to save snapshot: %v", actor.table.ID, err)
+		return false
+	}
+	return true
+}
+
+// persistTable snapshots a table as soon as it opens or its seats or
+// observers change, so a restart before the next periodic snapshot does not
+// lose them
+func (tm *ActorTableManager) persistTable(actor *TableActor) {
+	tm.mu.RLock()
+	store := tm.snapshots
+	tm.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	tm.saveSnapshot(store, actor)
+}
+
+// StartSnapshots snapshots open tables every interval until the manager stops
+func (tm *ActorTableManager) StartSnapshots(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+	tm.mu.Lock()
+	if tm.snapshotStop != nil {
+		tm.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	tm.snapshotStop = stop
+	tm.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				tm.SnapshotTables()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// deleteSnapshot drops a closed table's snapshot so it is not restored
+func (tm *ActorTableManager) deleteSnapshot(tableID string) {
+	tm.mu.RLock()
+	store := tm.snapshots
+	tm.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	if err := store.DeleteSnapshot(tableID); err != nil {
+		log.Printf("Table %s: failed to delete snapshot: %v", tableID, err)
+	}
+}
+
+// RestoreTables reopens the tables snapshotted before a restart and saves
+// snapshots to store from then on. Tables come back to the lobby with their
+// seats and observers; hands in progress resume where they were, and seated
+// players get the reconnect grace period to come back before their seats are
+// freed. It returns how many tables were restored.
+func (tm *ActorTableManager) RestoreTables(store TableSnapshotStore) (int, error) {
+	tm.mu.Lock()
+	tm.snapshots = store
+	tm.mu.Unlock()
+
+	snapshots, err := store.LoadSnapshots()
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	seated := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		table, err := tm.restoreTable(snapshot)
+		if err != nil {
+			log.Printf("Table %s: not restored: %v", snapshot.TableID, err)
+			tm.holdUnrestoredEscrow(snapshot)
+			continue
+		}
+		restored++
+		for _, slot := range table.PlayerSlots {
+			if slot.PlayerID != "" {
+				seated[slot.PlayerID] = true
+			}
+		}
+	}
+
+	for playerID := range seated {
+		tm.PlayerDisconnected(context.Background(), playerID)
+	}
+	return restored, nil
+}
+
+// holdUnrestoredEscrow escrows the buy-ins of a table that could not be
+// restored, for the escrow sweeper to refund, and drops its snapshot so they
+// are not held again after the next restart
+func (tm *ActorTableManager) holdUnrestoredEscrow(snapshot TableSnapshot) {
+	tm.mu.RLock()
+	_, open := tm.actors[snapshot.TableID]
+	tm.mu.RUnlock()
+	if open || len(snapshot.Escrow) == 0 {
+		return
+	}
+
+	// Without its settings nobody can tell what paid for the chips
+	table := &GameTable{}
+	if err := json.Unmarshal(snapshot.Table, table); err != nil || table.ID != snapshot.TableID {
+		log.Printf("Table %s: escrow of unreadable snapshot left for review", snapshot.TableID)
+		return
+	}
+	for playerID, amount := range snapshot.Escrow {
+		// A restore that failed part way may have escrowed some already
+		tm.escrow.Release(table.ID, playerID)
+		if err := tm.depositEscrow(table, playerID, amount); err != nil {
+			log.Printf("Table %s: failed to hold escrow of %s: %v", table.ID, playerID, err)
+		}
+	}
+	tm.deleteSnapshot(table.ID)
+}
+
+// restoreTable rebuilds one table and its engine from a snapshot
+func (tm *ActorTableManager) restoreTable(snapshot TableSnapshot) (*GameTable, error) {
+	if tm.gameEngineFactory == nil {
+		return nil, fmt.Errorf("no game engine factory")
+	}
+	tm.mu.RLock()
+	_, exists := tm.actors[snapshot.TableID]
+	tm.mu.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("table is already open")
+	}
+
+	table := &GameTable{}
+	if err := json.Unmarshal(snapshot.Table, table); err != nil {
+		return nil, fmt.Errorf("invalid table snapshot: %w", err)
+	}
+	if table.ID != snapshot.TableID {
+		return nil, fmt.Errorf("snapshot holds table %s", table.ID)
+	}
+
+	// Snapshots saved before passwords were hashed hold the plaintext
+	table.Settings.PasswordHash = snapshot.PasswordHash
+	if err := hashTablePassword(&table.Settings); err != nil {
+		return nil, err
+	}
+
+	// No connection survived the restart: players are marked disconnected
+	// once every table is back, and observers keep their place to watch from
+	// again when they reconnect
+	if table.Observers == nil {
+		table.Observers = make([]TableObserver, 0)
+	}
+	for i := range table.PlayerSlots {
+		table.PlayerSlots[i].Disconnected = false
+		table.PlayerSlots[i].DisconnectedAt = time.Time{}
+	}
+
+	engine, err := tm.gameEngineFactory.CreateEngine(table.GameType, table.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game engine: %w", err)
+	}
+	table.GameEngine = engine
+	tm.attachEngine(table, engine)
+	if err := engine.Deserialize(snapshot.Engine); err != nil {
+		return nil, err
+	}
+
+	for playerID, amount := range snapshot.Escrow {
+		if err := tm.depositEscrow(table, playerID, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	tm.mu.Lock()
+	tm.actors[table.ID] = NewTableActor(table)
+	tm.mu.Unlock()
+	tm.tags.Add(table)
+
+	// A table between hands deals the next one; a hand in progress carries on
+	if table.Status == TableStatusActive && engine.GetState() != GameStateInProgress {
+		tm.scheduleNextHand(table)
+	}
+	return table, nil
+}
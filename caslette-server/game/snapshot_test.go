@@ -0,0 +1,185 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySnapshots is a TableSnapshotStore keeping snapshots in memory
+type memorySnapshots struct {
+	mu    sync.Mutex
+	saved map[string]TableSnapshot
+}
+
+func newMemorySnapshots() *memorySnapshots {
+	return &memorySnapshots{saved: make(map[string]TableSnapshot)}
+}
+
+func (m *memorySnapshots) SaveSnapshot(snapshot TableSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved[snapshot.TableID] = snapshot
+	return nil
+}
+
+func (m *memorySnapshots) LoadSnapshots() ([]TableSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshots := make([]TableSnapshot, 0, len(m.saved))
+	for _, snapshot := range m.saved {
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (m *memorySnapshots) DeleteSnapshot(tableID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.saved, tableID)
+	return nil
+}
+
+// callOrCheck calls a bet or checks when there is nothing to call
+func callOrCheck(engine *TexasHoldemEngine) error {
+	if engine.getHoldemPlayer(engine.getCurrentActionPlayerID()).CurrentBet == engine.currentBet {
+		return act(engine, ActionCheck, 0)
+	}
+	return act(engine, ActionCall, 0)
+}
+
+func TestTexasHoldemSnapshotResumesTheHand(t *testing.T) {
+	engine := newButtonTable(t, 3)
+	require.NoError(t, engine.Start())
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	data, err := engine.Serialize()
+	require.NoError(t, err)
+	restored := NewTexasHoldemEngine("restored")
+	require.NoError(t, restored.Deserialize(data))
+
+	assert.Equal(t, GameStateInProgress, restored.GetState())
+	assert.Equal(t, engine.pot, restored.pot)
+	assert.Equal(t, engine.deck.cards, restored.deck.cards, "the deck carries on where it was")
+	assert.Equal(t, engine.getCurrentActionPlayerID(), restored.getCurrentActionPlayerID())
+	for _, playerID := range []string{"a", "b", "c"} {
+		original, resumed := engine.getHoldemPlayer(playerID), restored.getHoldemPlayer(playerID)
+		assert.Equal(t, original.Hand.Cards, resumed.Hand.Cards)
+		assert.Equal(t, original.Chips, resumed.Chips)
+		assert.Equal(t, original.CurrentBet, resumed.CurrentBet)
+		assert.Equal(t, original.Position, resumed.Position)
+		player, err := restored.GetPlayer(playerID)
+		require.NoError(t, err)
+		assert.Same(t, player, restored.getHoldemPlayer(playerID).Player)
+	}
+
+	// Both engines play the rest of the hand the same way
+	for engine.GetState() == GameStateInProgress {
+		require.NoError(t, callOrCheck(engine))
+		require.NoError(t, callOrCheck(restored))
+		assert.Equal(t, engine.communityCards.Cards, restored.communityCards.Cards)
+	}
+	assert.Equal(t, GameStateFinished, restored.GetState())
+	assert.Equal(t, len(engine.HandEvents()), len(restored.HandEvents()), "the hand's event log survives too")
+	stacks, pot := engine.ChipCounts()
+	restoredStacks, restoredPot := restored.ChipCounts()
+	assert.Equal(t, stacks, restoredStacks)
+	assert.Equal(t, pot, restoredPot)
+}
+
+func TestTexasHoldemDeserializeRejectsBadSnapshots(t *testing.T) {
+	engine := NewTexasHoldemEngine("restored")
+	assert.Error(t, engine.Deserialize([]byte("not json")))
+	assert.Error(t, engine.Deserialize([]byte(`{"players":[{"chips":100}]}`)))
+}
+
+func TestManagerRestoresSnapshottedTables(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1", "p2")
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+	require.NoError(t, manager.tryStartGame(table))
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	require.NoError(t, act(engine, ActionCall, 0))
+
+	assert.Equal(t, 1, manager.SnapshotTables())
+	require.Contains(t, store.saved, table.ID)
+
+	// A fresh manager after the restart
+	restarted := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(restarted.Stop)
+	restored, err := restarted.RestoreTables(store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	reopened, err := restarted.GetTable(table.ID)
+	require.NoError(t, err)
+	assert.Equal(t, TableStatusActive, reopened.Status)
+	assert.Equal(t, table.Settings, reopened.Settings)
+	assert.Equal(t, manager.escrow.Balances(table.ID), restarted.escrow.Balances(table.ID))
+	for _, playerID := range []string{"p0", "p1", "p2"} {
+		assert.True(t, isDisconnected(reopened, playerID), "players have the grace period to reconnect")
+	}
+
+	reopenedEngine := reopened.GameEngine.(*TexasHoldemEngine)
+	assert.Equal(t, GameStateInProgress, reopenedEngine.GetState())
+	assert.Equal(t, engine.pot, reopenedEngine.pot)
+	assert.Equal(t, engine.getCurrentActionPlayerID(), reopenedEngine.getCurrentActionPlayerID())
+	require.NoError(t, act(reopenedEngine, ActionCall, 0), "the hand carries on")
+
+	require.NoError(t, restarted.CloseTable(table.ID))
+	assert.NotContains(t, store.saved, table.ID, "closed tables are not restored")
+}
+
+func TestManagerDoesNotSnapshotTournamentTables(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+
+	settings := DefaultTableSettings()
+	settings.TournamentMode = true
+	newBalancingTable(t, manager, "tournament", settings, 0)
+	newBalancingTable(t, manager, "cash", DefaultTableSettings(), 0)
+
+	assert.Equal(t, 1, manager.SnapshotTables())
+}
+
+func TestManagerPersistsSeatChangesWithoutWaitingForSnapshots(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+
+	table := newBalancingTable(t, manager, "waiting", DefaultTableSettings(), 0)
+	require.Contains(t, store.saved, table.ID, "a new table is saved as it opens")
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p0", 1000))
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "p1", 1000))
+	require.NoError(t, manager.JoinTable(context.Background(), &TableJoinRequest{
+		TableID: table.ID, PlayerID: "rail", Username: "rail", Mode: JoinModeObserver,
+	}))
+	require.NoError(t, manager.LeaveTable(context.Background(), &TableLeaveRequest{TableID: table.ID, PlayerID: "p1"}))
+	seated := seatOf(table, "p0")
+
+	// A restart before any periodic snapshot ran
+	restarted := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(restarted.Stop)
+	restored, err := restarted.RestoreTables(store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	reopened, err := restarted.GetTable(table.ID)
+	require.NoError(t, err)
+	assert.Equal(t, TableStatusWaiting, reopened.Status)
+	assert.Equal(t, seated, seatOf(reopened, "p0"), "players keep their seats")
+	assert.Equal(t, 1000, seatChips(reopened, "p0"))
+	assert.False(t, reopened.IsPlayerAtTable("p1"), "seats given up stay free")
+	assert.True(t, reopened.IsObserver("rail"))
+	assert.Len(t, restarted.ListTables(nil), 1, "the table is back in the lobby")
+}
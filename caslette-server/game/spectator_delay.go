@@ -0,0 +1,44 @@
+package game
+
+import "time"
+
+// MaxSpectatorDelay is the longest a table may hold back game events from
+// its observers
+const MaxSpectatorDelay = 5 * time.Minute
+
+// SpectatorDelay returns how long observers' game events lag behind the
+// players'; zero for none
+func (t *GameTable) SpectatorDelay() time.Duration {
+	return time.Duration(t.Settings.SpectatorDelay) * time.Second
+}
+
+// ObserverRoomID returns the room observers watch from when the table has a
+// spectator delay, kept apart from the players' room so that only players
+// receive game events as they happen
+func (t *GameTable) ObserverRoomID() string {
+	return t.RoomID + "_observers"
+}
+
+// RoomFor returns the room a user follows the table from: the table room for
+// seated players and at tables without a delay, the observer room otherwise
+func (t *GameTable) RoomFor(userID string) string {
+	if t.SpectatorDelay() > 0 && !t.IsPlayerAtTable(userID) {
+		return t.ObserverRoomID()
+	}
+	return t.RoomID
+}
+
+// Rooms returns every room following the table, for updates that are not
+// delayed
+func (t *GameTable) Rooms() []string {
+	if t.SpectatorDelay() > 0 {
+		return []string{t.RoomID, t.ObserverRoomID()}
+	}
+	return []string{t.RoomID}
+}
+
+// handLive reports whether the table's engine is dealing a hand
+func (h *TableWebSocketHandler) handLive(table *GameTable) bool {
+	identifier, ok := table.GameEngine.(LiveHandIdentifier)
+	return ok && identifier.LiveHandID() != ""
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpectatorDelayRoutesObserversToTheirOwnRoom(t *testing.T) {
+	settings := DefaultTableSettings()
+	table := NewGameTable("t1", "Rooms", GameTypeTexasHoldem, "creator", settings)
+	table.PlayerSlots[0].PlayerID = "seated"
+
+	assert.Equal(t, table.RoomID, table.RoomFor("watcher"), "without a delay everyone shares the table room")
+	assert.Equal(t, []string{table.RoomID}, table.Rooms())
+
+	table.Settings.SpectatorDelay = 30
+	assert.Equal(t, table.RoomID, table.RoomFor("seated"))
+	assert.Equal(t, table.ObserverRoomID(), table.RoomFor("watcher"))
+	assert.Equal(t, []string{table.RoomID, table.ObserverRoomID()}, table.Rooms())
+}
+
+func TestSpectatorDelayValidation(t *testing.T) {
+	validator := NewTableValidator()
+	settings := DefaultTableSettings()
+
+	settings.SpectatorDelay = 30
+	assert.NoError(t, validator.ValidateTableSettings(settings))
+	settings.SpectatorDelay = int(MaxSpectatorDelay.Seconds()) + 1
+	assert.Error(t, validator.ValidateTableSettings(settings))
+	settings.SpectatorDelay = -1
+	assert.Error(t, validator.ValidateTableSettings(settings))
+}
+
+func TestDelayedTableHidesLiveStateFromObservers(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+	hub := &MockWebSocketHub{}
+	handler := NewTableWebSocketHandler(manager, hub)
+	t.Cleanup(handler.events.Stop)
+
+	ctx := context.Background()
+	settings := DefaultTableSettings()
+	settings.SpectatorDelay = 30
+	settings.TimeLimit = 0
+	table, err := manager.CreateTable(ctx, &TableCreateRequest{Name: "Spectated", GameType: GameTypeTexasHoldem, CreatedBy: "p1", Username: "player1", Settings: settings})
+	require.NoError(t, err)
+	for _, id := range []string{"p1", "p2"} {
+		require.NoError(t, manager.JoinTable(ctx, &TableJoinRequest{TableID: table.ID, PlayerID: id, Username: "player" + id, Mode: JoinModePlayer}))
+	}
+	require.NoError(t, manager.JoinTable(ctx, &TableJoinRequest{TableID: table.ID, PlayerID: "watcher", Username: "watcher", Mode: JoinModeObserver}))
+	require.NoError(t, manager.tryStartGame(table))
+
+	getState := handler.GetMessageHandlers()["table_get_game_state"]
+	request := &WebSocketMessage{Type: "table_get_game_state", Data: map[string]interface{}{"table_id": table.ID}}
+
+	observed := getState(ctx, NewMockConnection("watcher", "watcher"), request)
+	require.True(t, observed.Success, observed.Error)
+	data := observed.Data.(map[string]interface{})
+	assert.Nil(t, data["game_state"], "the live hand is withheld from observers")
+	assert.Equal(t, 30, data["spectator_delay"])
+
+	played := getState(ctx, NewMockConnection("p1", "p1"), request)
+	require.True(t, played.Success, played.Error)
+	assert.NotNil(t, played.Data.(map[string]interface{})["game_state"])
+}
+
+func TestBroadcastTableUpdateReachesObserverRoom(t *testing.T) {
+	hub := &MockWebSocketHub{}
+	handler := &TableWebSocketHandler{hub: hub}
+	settings := DefaultTableSettings()
+	settings.SpectatorDelay = 30
+	table := NewGameTable("t1", "Updates", GameTypeTexasHoldem, "creator", settings)
+
+	handler.broadcastTableUpdate(table, "player_joined", nil)
+	require.Len(t, hub.broadcastCalls, 2)
+	assert.Equal(t, table.RoomID, hub.broadcastCalls[0].RoomID)
+	assert.Equal(t, table.ObserverRoomID(), hub.broadcastCalls[1].RoomID)
+}
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ActionStraddle is posted before the deal by the player left of the big
+// blind: twice the big blind, which buys them the last action preflop
+const ActionStraddle TexasHoldemAction = "straddle"
+
+// StraddleEngine is an engine that takes straddles for the next hand
+type StraddleEngine interface {
+	Straddle(playerID string) error
+}
+
+// SetStraddleAllowed lets the player left of the big blind straddle
+func (the *TexasHoldemEngine) SetStraddleAllowed(allowed bool) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	the.straddleAllowed = allowed
+	if !allowed {
+		the.straddler = ""
+	}
+}
+
+// Straddle has a player post twice the big blind in the next hand. Only the
+// player who will sit left of the big blind may straddle, and only between
+// hands with at least three players to deal.
+func (the *TexasHoldemEngine) Straddle(playerID string) error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if the.variant != nil {
+		return fmt.Errorf("straddles are only posted in games with blinds")
+	}
+	if !the.straddleAllowed {
+		return fmt.Errorf("straddles are not allowed at this table")
+	}
+	if state := the.GetState(); state == GameStateInProgress || state == GameStatePaused {
+		return fmt.Errorf("a straddle can only be posted before the deal")
+	}
+	player := the.getHoldemPlayer(playerID)
+	if player == nil {
+		return fmt.Errorf("player %s not found", playerID)
+	}
+	if the.nextStraddleSeat() != player.Position {
+		return fmt.Errorf("only the player left of the next big blind may straddle")
+	}
+	if player.Chips < the.straddleAmount() {
+		return fmt.Errorf("a straddle of %d needs more chips", the.straddleAmount())
+	}
+	the.straddler = playerID
+	return nil
+}
+
+// straddleAmount is the size of a straddle: twice the big blind
+func (the *TexasHoldemEngine) straddleAmount() int {
+	return 2 * the.bigBlind
+}
+
+// nextStraddleSeat returns the seat left of the next hand's big blind, or
+// noSeat when fewer than three players will be dealt in
+func (the *TexasHoldemEngine) nextStraddleSeat() int {
+	seats := make([]int, 0, len(the.players))
+	for _, player := range the.players {
+		if holdemPlayer := the.getHoldemPlayer(player.ID); holdemPlayer != nil && holdemPlayer.Chips > 0 && !holdemPlayer.SittingOut {
+			seats = append(seats, player.Position)
+		}
+	}
+	if len(seats) < 3 {
+		return noSeat
+	}
+	sort.Ints(seats)
+
+	// Mirrors setPositions: the big blind moves one player on each hand
+	bigBlind := nextSeat(seats, nextSeat(seats, seats[0]))
+	if the.handsDealt > 0 {
+		bigBlind = nextSeat(seats, the.bigBlindPos)
+	}
+	return nextSeat(seats, bigBlind)
+}
+
+// postStraddle posts the straddle asked for, if its player still sits left
+// of the big blind with the chips for it, and returns what was posted. The
+// straddle becomes the bet to call and the size of the smallest raise.
+func (the *TexasHoldemEngine) postStraddle() map[string]interface{} {
+	playerID := the.straddler
+	the.straddler = ""
+	seats := the.dealtInSeats()
+	if playerID == "" || !the.straddleAllowed || len(seats) < 3 {
+		return nil
+	}
+	player := the.playerAtSeat(nextSeat(seats, the.bigBlindPos))
+	if player == nil || player.ID != playerID || player.Chips < the.straddleAmount() {
+		return nil
+	}
+
+	amount := the.postBlind(player, the.straddleAmount())
+	the.currentBet = max(the.currentBet, amount)
+	the.lastRaise = amount
+	the.straddleSeat = player.Position
+	return map[string]interface{}{
+		"playerID": player.ID,
+		"amount":   amount,
+	}
+}
+
+// lastBlindSeat returns the seat of the last blind posted preflop: the
+// straddle, or the big blind
+func (the *TexasHoldemEngine) lastBlindSeat() int {
+	if the.straddleSeat != noSeat {
+		return the.straddleSeat
+	}
+	return the.bigBlindPos
+}
+
+// Straddle has a seated player straddle the next hand at a table that allows
+// it
+func (tm *ActorTableManager) Straddle(ctx context.Context, tableID, playerID string) error {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return ErrTableNotFound
+	}
+
+	table := actor.table
+	if !table.Settings.Straddle {
+		return &TableError{"STRADDLE_NOT_ALLOWED", "Straddles are not allowed at this table"}
+	}
+	if table.GetPlayerPosition(playerID) == -1 {
+		return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+	}
+	engine, ok := table.GameEngine.(StraddleEngine)
+	if !ok {
+		return &TableError{"STRADDLE_NOT_ALLOWED", "This game does not take straddles"}
+	}
+	if err := engine.Straddle(playerID); err != nil {
+		return &TableError{"STRADDLE_REFUSED", err.Error()}
+	}
+
+	tm.BroadcastGameEvent(table, &GameEvent{
+		Type:     "player_straddling",
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"table_id":  tableID,
+			"player_id": playerID,
+			"amount":    2 * table.Settings.BigBlind,
+		},
+		Timestamp: time.Now(),
+	})
+	return nil
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStraddleTable seats players 1 to n at seats 1 to n with 10/20 blinds
+func newStraddleTable(t *testing.T, n int) *TexasHoldemEngine {
+	engine := NewTexasHoldemEngine("straddle-game")
+	engine.SetSmallBlind(10)
+	engine.SetBigBlind(20)
+	engine.SetStraddleAllowed(true)
+	for i := 1; i <= n; i++ {
+		playerID := string(rune('0' + i))
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: "Player " + playerID, Position: i}))
+	}
+	return engine
+}
+
+func TestStraddleActsAsTheBigBlind(t *testing.T) {
+	engine := newStraddleTable(t, 4)
+	assert.Error(t, engine.Straddle("1"), "only the player left of the big blind may straddle")
+	require.NoError(t, engine.Straddle("4"))
+	require.NoError(t, engine.Start())
+
+	straddler := engine.getHoldemPlayer("4")
+	assert.Equal(t, 40, straddler.CurrentBet)
+	assert.Equal(t, 40, engine.currentBet)
+	assert.Equal(t, "1", engine.getCurrentActionPlayerID(), "action starts left of the straddle")
+	assert.Equal(t, 40, engine.raiseRange(engine.getHoldemPlayer("1")).Min, "raises are at least the straddle")
+	assert.Error(t, engine.Straddle("4"), "straddles are posted before the deal")
+
+	for _, playerID := range []string{"1", "2", "3"} {
+		require.Equal(t, playerID, engine.getCurrentActionPlayerID())
+		require.NoError(t, act(engine, ActionCall, 0))
+	}
+	assert.Equal(t, PreFlop, engine.roundState, "the straddle has the last word")
+	require.Equal(t, "4", engine.getCurrentActionPlayerID())
+	require.NoError(t, act(engine, ActionCheck, 0))
+	assert.Equal(t, Flop, engine.roundState)
+	assert.Equal(t, 160, engine.pot)
+
+	foldHand(t, engine)
+	require.NoError(t, engine.StartNextHand())
+	assert.Equal(t, 20, engine.currentBet, "a straddle lasts one hand")
+}
+
+func TestStraddleNeedsThreePlayersAndTheTableSetting(t *testing.T) {
+	assert.Error(t, newStraddleTable(t, 2).Straddle("1"), "heads up the seat left of the big blind is the button")
+
+	engine := newStraddleTable(t, 3)
+	engine.SetStraddleAllowed(false)
+	assert.Error(t, engine.Straddle("1"))
+
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1", "p2")
+	var tableErr *TableError
+	err := manager.Straddle(context.Background(), table.ID, "p0")
+	require.True(t, errors.As(err, &tableErr))
+	assert.Equal(t, "STRADDLE_NOT_ALLOWED", tableErr.Code)
+}
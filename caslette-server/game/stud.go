@@ -0,0 +1,350 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Seven Card Stud limits
+const (
+	StudMaxPlayers = 7 // Seven cards each for seven players fit in one deck
+	StudBetCap     = 4 // Bets per street: a bet and three raises
+)
+
+// Seven Card Stud streets. Hands end at Showdown like hold'em.
+const (
+	ThirdStreet   TexasHoldemState = "third_street"
+	FourthStreet  TexasHoldemState = "fourth_street"
+	FifthStreet   TexasHoldemState = "fifth_street"
+	SixthStreet   TexasHoldemState = "sixth_street"
+	SeventhStreet TexasHoldemState = "seventh_street"
+)
+
+// studStreets is the street after each one and whether its card is dealt face up
+var studStreets = map[TexasHoldemState]struct {
+	next   TexasHoldemState
+	faceUp bool
+}{
+	ThirdStreet:  {FourthStreet, true},
+	FourthStreet: {FifthStreet, true},
+	FifthStreet:  {SixthStreet, true},
+	SixthStreet:  {SeventhStreet, false},
+}
+
+// StudEngine implements fixed-limit Seven Card Stud. Everyone antes and is
+// dealt two cards down and one up, and the lowest up card brings in. Fourth
+// to sixth street are dealt up and seventh down; the best showing hand acts
+// first from fourth street on, and players make their best five of seven
+// cards. Bets are the small bet on third and fourth street and the big bet,
+// twice the small bet, after that. Pots, rake, timers and the showdown follow
+// Texas Hold'em, with the table's small blind setting the bring-in and its
+// big blind the small bet.
+type StudEngine struct {
+	*TexasHoldemEngine
+}
+
+// NewStudEngine creates a new Seven Card Stud game engine
+func NewStudEngine(gameID string) *StudEngine {
+	engine := NewTexasHoldemEngine(gameID)
+	engine.maxPlayers = min(engine.maxPlayers, StudMaxPlayers)
+	stud := &StudEngine{TexasHoldemEngine: engine}
+	engine.variant = stud
+	return stud
+}
+
+// BringIn returns the forced bet made by the lowest up card on third street,
+// never more than the small bet
+func (se *StudEngine) BringIn() int {
+	return min(se.smallBlind, se.bigBlind)
+}
+
+// SmallBet returns the bet size on third and fourth street
+func (se *StudEngine) SmallBet() int {
+	return se.bigBlind
+}
+
+// BigBet returns the bet size from fifth street on
+func (se *StudEngine) BigBet() int {
+	return 2 * se.bigBlind
+}
+
+// streetBet returns the fixed bet size of the current street
+func (se *StudEngine) streetBet() int {
+	if se.roundState == ThirdStreet || se.roundState == FourthStreet {
+		return se.SmallBet()
+	}
+	return se.BigBet()
+}
+
+// dealHand takes the antes, deals third street and posts the bring-in
+func (se *StudEngine) dealHand() error {
+	se.handsDealt++
+	players := se.getActivePlayers()
+	se.postAntes(players)
+
+	for i := 0; i < 3; i++ {
+		for _, player := range players {
+			card, err := se.deck.Deal()
+			if err != nil {
+				return fmt.Errorf("error dealing third street: %v", err)
+			}
+			se.getHoldemPlayer(player.ID).Hand.AddCard(card)
+		}
+	}
+	se.roundState = ThirdStreet
+
+	bringIn := se.bringInPlayer()
+	if bringIn == nil {
+		return fmt.Errorf("no player to bring in")
+	}
+	amount := se.postBlind(bringIn, se.BringIn())
+	se.currentBet = amount
+	// Everyone calling the bring-in ends the street; completing it reopens
+	// the betting to the bring-in
+	bringIn.HasActed = true
+	if amount > 0 && amount < se.SmallBet() {
+		se.lastRaise = se.SmallBet() - amount
+	}
+	se.actionPos = se.firstToActAfter(bringIn.Position)
+
+	se.emitEvent(&GameEvent{
+		Type: "third_street_dealt",
+		Data: map[string]interface{}{
+			"street":  ThirdStreet,
+			"upCards": se.upCards(),
+			"bringIn": map[string]interface{}{
+				"playerID": bringIn.ID,
+				"amount":   amount,
+			},
+			"pot": se.pot,
+		},
+	})
+	return nil
+}
+
+// dealStreet deals everyone still in the hand their next card and gives the
+// action to the best showing hand. When fewer than two players can still
+// bet, the engine runs the remaining streets out to the showdown.
+func (se *StudEngine) dealStreet() error {
+	street, ok := studStreets[se.roundState]
+	if !ok {
+		return se.showdown()
+	}
+
+	players := se.getActivePlayers()
+	if se.deck.Remaining() > len(players) {
+		se.deck.Deal() // Burn a card when the deck can spare it
+	}
+	for _, player := range players {
+		card, err := se.deck.Deal()
+		if err != nil {
+			return fmt.Errorf("error dealing %s: %v", street.next, err)
+		}
+		se.getHoldemPlayer(player.ID).Hand.AddCard(card)
+	}
+	se.roundState = street.next
+	se.lastRaise = se.streetBet()
+	se.actionPos = se.bestShowingIndex()
+
+	se.emitEvent(&GameEvent{
+		Type: string(street.next) + "_dealt",
+		Data: map[string]interface{}{
+			"street":   street.next,
+			"faceDown": !street.faceUp,
+			"upCards":  se.upCards(),
+		},
+	})
+	return nil
+}
+
+// upCards returns the face-up cards of every player still in the hand: the
+// third to sixth cards dealt
+func (se *StudEngine) upCards() map[string][]Card {
+	cards := make(map[string][]Card)
+	for _, player := range se.getActivePlayers() {
+		cards[player.ID] = studUpCards(se.getHoldemPlayer(player.ID).Hand.Cards)
+	}
+	return cards
+}
+
+// studUpCards returns the face-up cards of a stud hand
+func studUpCards(hand []Card) []Card {
+	if len(hand) <= 2 {
+		return []Card{}
+	}
+	return append([]Card(nil), hand[2:min(len(hand), 6)]...)
+}
+
+// bringInPlayer returns the player showing the lowest up card. Ties go by
+// suit, clubs lowest, then diamonds, hearts and spades.
+func (se *StudEngine) bringInPlayer() *TexasHoldemPlayer {
+	var lowest *TexasHoldemPlayer
+	var lowestCard Card
+	for _, player := range se.getActivePlayers() {
+		holdemPlayer := se.getHoldemPlayer(player.ID)
+		if len(holdemPlayer.Hand.Cards) < 3 {
+			continue
+		}
+		card := holdemPlayer.Hand.Cards[2]
+		if lowest == nil || card.Rank < lowestCard.Rank ||
+			(card.Rank == lowestCard.Rank && studSuitOrder[card.Suit] < studSuitOrder[lowestCard.Suit]) {
+			lowest, lowestCard = holdemPlayer, card
+		}
+	}
+	return lowest
+}
+
+// studSuitOrder breaks bring-in ties
+var studSuitOrder = map[Suit]int{Clubs: 0, Diamonds: 1, Hearts: 2, Spades: 3}
+
+// bestShowingIndex returns the index in the active players of the player
+// who can still bet with the best up cards, ties going to the lowest seat
+func (se *StudEngine) bestShowingIndex() int {
+	best, bestKey := 0, []int(nil)
+	for i, player := range se.getActivePlayers() {
+		holdemPlayer := se.getHoldemPlayer(player.ID)
+		if holdemPlayer.IsAllIn {
+			continue
+		}
+		key := showingKey(studUpCards(holdemPlayer.Hand.Cards))
+		if bestKey == nil || compareKeys(key, bestKey) > 0 {
+			best, bestKey = i, key
+		}
+	}
+	return best
+}
+
+// showingKey ranks up cards for the order of action: four of a kind, three
+// of a kind, two pair, a pair, then high cards. Straights and flushes do not
+// count. Keys compare element by element.
+func showingKey(cards []Card) []int {
+	counts := make(map[Rank]int)
+	for _, card := range cards {
+		counts[card.Rank]++
+	}
+	ranks := make([]Rank, 0, len(counts))
+	for rank := range counts {
+		ranks = append(ranks, rank)
+	}
+	// Larger groups first, then higher ranks
+	sort.Slice(ranks, func(i, j int) bool {
+		if counts[ranks[i]] != counts[ranks[j]] {
+			return counts[ranks[i]] > counts[ranks[j]]
+		}
+		return ranks[i] > ranks[j]
+	})
+
+	category := 0
+	if len(ranks) > 0 {
+		switch counts[ranks[0]] {
+		case 4:
+			category = 4
+		case 3:
+			category = 3
+		case 2:
+			category = 1
+			if len(ranks) > 1 && counts[ranks[1]] == 2 {
+				category = 2
+			}
+		}
+	}
+	key := []int{category}
+	for _, rank := range ranks {
+		key = append(key, int(rank))
+	}
+	return key
+}
+
+// compareKeys compares two showing keys, a longer key winning a tie
+func compareKeys(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// betLimits returns the fixed-limit bet or raise: the street's bet size, or
+// what completes a bet short of it, such as the bring-in
+func (se *StudEngine) betLimits(player *TexasHoldemPlayer) RaiseRange {
+	size := se.streetBet()
+	remaining := max(player.Chips-(se.currentBet-player.CurrentBet), 0)
+	if se.currentBet == 0 {
+		return RaiseRange{Action: string(ActionBet), Min: min(size, remaining), Max: min(size, remaining)}
+	}
+	raise := size
+	if se.currentBet < size {
+		raise = size - se.currentBet
+	}
+	return RaiseRange{Action: string(ActionRaise), Min: min(raise, remaining), Max: min(raise, remaining)}
+}
+
+// checkBetSize holds bets and raises to the fixed size and the cap
+func (se *StudEngine) checkBetSize(player *TexasHoldemPlayer, amount float64) error {
+	if se.currentBet/max(se.streetBet(), 1) >= StudBetCap {
+		return fmt.Errorf("betting is capped at %d bets this street; you may only call or fold", StudBetCap)
+	}
+	legal := se.betLimits(player)
+	if legal.Max > 0 && amount > float64(legal.Max) {
+		return fmt.Errorf("fixed-limit %s is %d", legal.Action, legal.Max)
+	}
+	return nil
+}
+
+// checkAllIn allows going all in only for at most a call or a legal bet or raise
+func (se *StudEngine) checkAllIn(player *TexasHoldemPlayer) error {
+	callAmount := se.currentBet - player.CurrentBet
+	if player.Chips <= callAmount {
+		return nil
+	}
+	return se.validateRaise(player, float64(player.Chips-callAmount))
+}
+
+// pastFirstStreet reports whether the hand reached fourth street, from
+// which its pots are raked
+func (se *StudEngine) pastFirstStreet() bool {
+	return se.roundState != ThirdStreet
+}
+
+// GetValidActions returns the valid actions for a player within the fixed
+// limits and the cap
+func (se *StudEngine) GetValidActions(playerID string) []string {
+	actions := se.TexasHoldemEngine.GetValidActions(playerID)
+	player := se.getHoldemPlayer(playerID)
+	if player == nil {
+		return actions
+	}
+
+	valid := make([]string, 0, len(actions))
+	for _, action := range actions {
+		switch TexasHoldemAction(action) {
+		case ActionBet, ActionRaise:
+			if se.validateRaise(player, float64(se.betLimits(player).Min)) != nil {
+				continue
+			}
+		case ActionAllIn:
+			if se.checkAllIn(player) != nil {
+				continue
+			}
+		}
+		valid = append(valid, action)
+	}
+	return valid
+}
+
+// GetPublicGameState returns public game state with every player's up cards
+// and the stud stakes instead of blinds
+func (se *StudEngine) GetPublicGameState() map[string]interface{} {
+	state := se.TexasHoldemEngine.GetPublicGameState()
+	delete(state, "community_cards")
+	delete(state, "dealer_position")
+	delete(state, "small_blind")
+	delete(state, "big_blind")
+	state["variant"] = GameTypeSevenCardStud
+	state["up_cards"] = se.upCards()
+	state["bring_in"] = se.BringIn()
+	state["small_bet"] = se.SmallBet()
+	state["big_bet"] = se.BigBet()
+	return state
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStudTable deals a stud hand to players a, b, c... in seats 0, 1, 2...
+// with the given stacks, a 5 ante, a 5 bring-in and 10/20 bets
+func newStudTable(t *testing.T, stacks ...int) *StudEngine {
+	engine := NewStudEngine("stud-game")
+	engine.SetAnte(5)
+	engine.SetSmallBlind(5)
+	engine.SetBigBlind(10)
+	for i, chips := range stacks {
+		playerID := string(rune('a' + i))
+		require.NoError(t, engine.SeatPlayer(&Player{ID: playerID, Name: playerID, Data: map[string]interface{}{"chips": chips}}, i))
+	}
+	require.NoError(t, engine.Start())
+	return engine
+}
+
+// callAround calls or checks until the betting reaches the next street
+func callAround(t *testing.T, engine *StudEngine) {
+	street := engine.roundState
+	for engine.roundState == street && engine.GetState() == GameStateInProgress {
+		action := ActionCheck
+		if engine.currentBet > engine.getHoldemPlayer(engine.getCurrentActionPlayerID()).CurrentBet {
+			action = ActionCall
+		}
+		require.NoError(t, act(engine.TexasHoldemEngine, action, 0))
+	}
+}
+
+func TestStudDealsThirdStreetAndBringsIn(t *testing.T) {
+	engine := newStudTable(t, 1000, 1000, 1000)
+
+	assert.Equal(t, ThirdStreet, engine.roundState)
+	for _, playerID := range []string{"a", "b", "c"} {
+		assert.Len(t, engine.getHoldemPlayer(playerID).Hand.Cards, 3, "two down and one up")
+	}
+	bringIn := engine.bringInPlayer()
+	assert.Equal(t, 5, bringIn.CurrentBet)
+	assert.Equal(t, 3*5+5, engine.pot, "antes plus the bring-in")
+	assert.NotEqual(t, bringIn.ID, engine.getCurrentActionPlayerID(), "the player after the bring-in acts first")
+
+	state := engine.GetPublicGameState()
+	assert.Equal(t, GameTypeSevenCardStud, state["variant"])
+	assert.Equal(t, 20, state["big_bet"])
+	upCards := state["up_cards"].(map[string][]Card)
+	require.Len(t, upCards, 3)
+	assert.Equal(t, []Card{engine.getHoldemPlayer("a").Hand.Cards[2]}, upCards["a"])
+}
+
+func TestStudBettingIsFixedLimit(t *testing.T) {
+	engine := newStudTable(t, 1000, 1000, 1000)
+
+	// Completing the bring-in raises it to the small bet exactly
+	assert.Error(t, act(engine.TexasHoldemEngine, ActionRaise, 10))
+	assert.Error(t, act(engine.TexasHoldemEngine, ActionAllIn, 0), "no shoving in a limit game")
+	require.NoError(t, act(engine.TexasHoldemEngine, ActionRaise, 5))
+	assert.Equal(t, 10, engine.currentBet)
+	callAround(t, engine)
+
+	require.Equal(t, FourthStreet, engine.roundState)
+	assert.Len(t, engine.getHoldemPlayer("a").Hand.Cards, 4)
+	assert.Error(t, act(engine.TexasHoldemEngine, ActionBet, 20), "fourth street bets the small bet")
+	require.NoError(t, act(engine.TexasHoldemEngine, ActionBet, 10))
+	callAround(t, engine)
+
+	require.Equal(t, FifthStreet, engine.roundState)
+	assert.Error(t, act(engine.TexasHoldemEngine, ActionBet, 10), "fifth street bets the big bet")
+	require.NoError(t, act(engine.TexasHoldemEngine, ActionBet, 20))
+	for i := 0; i < StudBetCap-1; i++ {
+		require.NoError(t, act(engine.TexasHoldemEngine, ActionRaise, 20))
+	}
+	assert.Equal(t, 80, engine.currentBet)
+	assert.NotContains(t, engine.GetValidActions(engine.getCurrentActionPlayerID()), string(ActionRaise), "betting is capped")
+	assert.Error(t, act(engine.TexasHoldemEngine, ActionRaise, 20))
+	require.NoError(t, act(engine.TexasHoldemEngine, ActionCall, 0))
+}
+
+func TestStudBringInAndActionOrder(t *testing.T) {
+	engine := newStudTable(t, 1000, 1000, 1000)
+	for playerID, cards := range map[string][]Card{
+		"a": {NewCard(Spades, Ace), NewCard(Spades, King), NewCard(Hearts, Two), NewCard(Clubs, King)},
+		"b": {NewCard(Spades, Four), NewCard(Spades, Five), NewCard(Clubs, Two), NewCard(Diamonds, Nine)},
+		"c": {NewCard(Hearts, Six), NewCard(Hearts, Seven), NewCard(Diamonds, Eight), NewCard(Hearts, Eight)},
+	} {
+		engine.getHoldemPlayer(playerID).Hand.Cards = cards
+	}
+
+	assert.Equal(t, "b", engine.bringInPlayer().ID, "the deuce of clubs is lower than the deuce of hearts")
+	assert.Equal(t, "c", engine.getActivePlayers()[engine.bestShowingIndex()].ID, "a pair of eights shows best")
+
+	assert.Greater(t, compareKeys(showingKey([]Card{NewCard(Clubs, Three), NewCard(Hearts, Three)}),
+		showingKey([]Card{NewCard(Clubs, Ace), NewCard(Hearts, King)})), 0)
+	assert.Greater(t, compareKeys(showingKey([]Card{NewCard(Clubs, Ace), NewCard(Hearts, Queen)}),
+		showingKey([]Card{NewCard(Clubs, Ace), NewCard(Hearts, Jack)})), 0)
+}
+
+func TestStudAllInRunsOutToShowdown(t *testing.T) {
+	engine := newStudTable(t, 12, 1000)
+
+	for engine.GetState() == GameStateInProgress {
+		playerID := engine.getCurrentActionPlayerID()
+		valid := engine.GetValidActions(playerID)
+		switch {
+		case playerID == "a" && slices.Contains(valid, string(ActionAllIn)):
+			require.NoError(t, act(engine.TexasHoldemEngine, ActionAllIn, 0))
+		case slices.Contains(valid, string(ActionCall)):
+			require.NoError(t, act(engine.TexasHoldemEngine, ActionCall, 0))
+		default:
+			require.NoError(t, act(engine.TexasHoldemEngine, ActionCheck, 0))
+		}
+	}
+
+	assert.Equal(t, Showdown, engine.roundState)
+	for _, playerID := range []string{"a", "b"} {
+		assert.Len(t, engine.getHoldemPlayer(playerID).Hand.Cards, 7)
+	}
+	stacks, pot := engine.ChipCounts()
+	assert.Equal(t, 1012, stacks["a"]+stacks["b"]+pot, "no chips are created or lost")
+
+	record := engine.CompletedHand()
+	require.NotNil(t, record)
+	assert.Len(t, record.Players[0].HoleCards, 7, "the history keeps every street's card")
+}
+
+func TestEngineFactoryCreatesStud(t *testing.T) {
+	factory := &TexasHoldemEngineFactory{}
+	settings := DefaultTableSettings()
+	engine, err := factory.CreateEngine(GameTypeSevenCardStud, settings)
+	require.NoError(t, err)
+	stud, ok := engine.(*StudEngine)
+	require.True(t, ok)
+	assert.Equal(t, min(settings.SmallBlind, settings.BigBlind), stud.BringIn())
+	assert.Equal(t, settings.BigBlind, stud.SmallBet())
+
+	assert.NoError(t, NewTableValidator().ValidateGameType(GameTypeSevenCardStud))
+
+	manager := NewActorTableManager(factory)
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "Stud Night", GameType: GameTypeSevenCardStud, CreatedBy: "creator", Username: "creator", Settings: settings,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StudMaxPlayers, table.MaxPlayers)
+	_, ok = table.GameEngine.(*StudEngine)
+	assert.True(t, ok)
+}
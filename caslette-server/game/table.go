@@ -19,25 +19,87 @@ const (
 type GameType string
 
 const (
-	GameTypeTexasHoldem GameType = "texas_holdem"
+	GameTypeTexasHoldem   GameType = "texas_holdem"
+	GameTypeOmaha         GameType = "omaha"
+	GameTypeSevenCardStud GameType = "seven_card_stud"
 	// Add more game types as they're implemented
 )
 
+// TableCurrency identifies what a table's chips are backed by
+type TableCurrency string
+
+const (
+	CurrencyDiamonds  TableCurrency = "diamonds"   // Real-money tables backed by the diamond ledger
+	CurrencyPlayMoney TableCurrency = "play_money" // Practice tables that never touch the ledger
+)
+
 // TableSettings contains configurable settings for a table
 type TableSettings struct {
 	// Game-specific settings
 	SmallBlind     int  `json:"small_blind"`
 	BigBlind       int  `json:"big_blind"`
+	Ante           int  `json:"ante"` // Posted by every player before the deal; zero for none
 	BuyIn          int  `json:"buy_in"`
 	MaxBuyIn       int  `json:"max_buy_in"`
 	AutoStart      bool `json:"auto_start"`      // Auto start when enough players join
 	TimeLimit      int  `json:"time_limit"`      // Turn time limit in seconds
+	TimeBank       int  `json:"time_bank"`       // Extra seconds each player may draw on once the turn limit runs out
 	TournamentMode bool `json:"tournament_mode"` // Tournament vs cash game
 
+	// Rake taken from each pot that sees a flop at cash tables; zero
+	// percent takes the operator default. MaxRake caps it per hand, zero
+	// for no cap.
+	RakePercent float64 `json:"rake_percent"`
+	MaxRake     int     `json:"max_rake"`
+
+	// Currency defaults to diamonds when empty
+	Currency TableCurrency `json:"currency,omitempty"`
+
+	// ProvablyFair publishes a hash of each hand's shuffle seed when the
+	// hand starts and the seed when it ends, so players can verify the deal
+	ProvablyFair bool `json:"provably_fair"`
+
+	// BotsAllowed designates the table for sanctioned bot play. Bot tokens
+	// are refused at every other table.
+	BotsAllowed bool `json:"bots_allowed"`
+
+	// DecisionSupport gives the player to act their pot odds, and their
+	// equity once someone is all in
+	DecisionSupport bool `json:"decision_support"`
+
+	// AutoRebuy tops a player's stack back up to the maximum buy-in between
+	// hands once it drops below AutoRebuyBelow, taking diamonds at diamond
+	// tables. A zero threshold only rebuys players who bust.
+	AutoRebuy      bool `json:"auto_rebuy"`
+	AutoRebuyBelow int  `json:"auto_rebuy_below"`
+
+	// Straddle lets the player left of the big blind post twice the big
+	// blind before the deal and act last preflop
+	Straddle bool `json:"straddle"`
+
+	// Pauses, in milliseconds, before each street is dealt once everyone
+	// is all in, so the board comes out one street at a time. Zero deals
+	// the street at once.
+	RunoutFlopDelayMs  int `json:"runout_flop_delay_ms"`
+	RunoutTurnDelayMs  int `json:"runout_turn_delay_ms"`
+	RunoutRiverDelayMs int `json:"runout_river_delay_ms"`
+
+	// SpectatorDelay holds game events back from observers for this many
+	// seconds, so they cannot relay a hand to the players as it is played.
+	// Zero sends observers events as they happen.
+	SpectatorDelay int `json:"spectator_delay"`
+
+	// Seats at the table and players needed to deal, within the game
+	// type's capacity. Zero takes the game type's default.
+	MinPlayers int `json:"min_players,omitempty"`
+	MaxPlayers int `json:"max_players,omitempty"`
+
 	// Table behavior
 	ObserversAllowed bool   `json:"observers_allowed"`  // Allow spectators
+	MaxObservers     int    `json:"max_observers"`      // Most observers at once; zero for no limit
 	Private          bool   `json:"private"`            // Requires invitation
-	Password         string `json:"password,omitempty"` // Password protection
+	Password         string `json:"password,omitempty"` // Given on creation only; replaced by PasswordHash
+	PasswordHash     string `json:"-"`                  // bcrypt hash, never serialized
 }
 
 // PlayerSlot represents a player's position at the table
@@ -46,7 +108,25 @@ type PlayerSlot struct {
 	PlayerID string    `json:"player_id,omitempty"`
 	Username string    `json:"username,omitempty"`
 	IsReady  bool      `json:"is_ready"`
+	Chips    int       `json:"chips,omitempty"` // Stack brought to the seat
 	JoinedAt time.Time `json:"joined_at,omitempty"`
+
+	// Set while the player's connection is gone; the seat is freed if they
+	// do not come back within the reconnection grace period
+	Disconnected   bool      `json:"disconnected,omitempty"`
+	DisconnectedAt time.Time `json:"disconnected_at,omitempty"`
+
+	// Set while the player skips hands without giving up the seat
+	SittingOut bool      `json:"sitting_out,omitempty"`
+	SatOutAt   time.Time `json:"sat_out_at,omitempty"`
+
+	// Cosmetics the player has equipped, by kind, so other clients can render them
+	Cosmetics map[string]string `json:"cosmetics,omitempty"`
+
+	// Set on an empty seat held for a player finishing their buy-in; nobody
+	// else may take it until the reservation runs out
+	ReservedFor   string    `json:"reserved_for,omitempty"`
+	ReservedUntil time.Time `json:"reserved_until,omitempty"`
 }
 
 // TableObserver represents an observer watching the table
@@ -81,21 +161,20 @@ type GameTable struct {
 	// Metadata
 	Description string   `json:"description,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+
+	// Set on high-stakes tables, which stay out of the lobby until approved
+	Approval *TableApproval `json:"approval,omitempty"`
+
+	// Set on tables an admin pinned to the top of the lobby
+	Feature *TableFeature `json:"feature,omitempty"`
 }
 
 // NewGameTable creates a new game table
 func NewGameTable(id, name string, gameType GameType, createdBy string, settings TableSettings) *GameTable {
 	now := time.Now()
 
-	// Determine max players based on game type
-	maxPlayers := 8 // Default for Texas Hold'em
-	minPlayers := 2
-
-	switch gameType {
-	case GameTypeTexasHoldem:
-		maxPlayers = 8
-		minPlayers = 2
-	}
+	// Seats come from the settings, within the game type's capacity
+	minPlayers, maxPlayers := tableCapacity(gameType, settings)
 
 	// Initialize player slots
 	playerSlots := make([]PlayerSlot, maxPlayers)
@@ -138,6 +217,12 @@ func (t *GameTable) GetObserverCount() int {
 	return len(t.Observers)
 }
 
+// ObserversFull reports whether the table has as many observers as its
+// settings allow
+func (t *GameTable) ObserversFull() bool {
+	return t.Settings.MaxObservers > 0 && len(t.Observers) >= t.Settings.MaxObservers
+}
+
 // GetTotalCount returns total number of people at the table (players + observers)
 func (t *GameTable) GetTotalCount() int {
 	return t.GetPlayerCount() + t.GetObserverCount()
@@ -196,8 +281,8 @@ func (t *GameTable) CanJoinAsObserver(playerID string) bool {
 		return false
 	}
 
-	// Check if table is closed
-	if t.Status == TableStatusClosed {
+	// Check if table is closed or has all the observers it takes
+	if t.Status == TableStatusClosed || t.ObserversFull() {
 		return false
 	}
 
@@ -208,8 +293,9 @@ func (t *GameTable) CanJoinAsObserver(playerID string) bool {
 // GetAvailableSlots returns positions of available player slots
 func (t *GameTable) GetAvailableSlots() []int {
 	var available []int
+	now := time.Now()
 	for _, slot := range t.PlayerSlots {
-		if slot.PlayerID == "" {
+		if slot.openTo("", now) {
 			available = append(available, slot.Position)
 		}
 	}
@@ -230,10 +316,17 @@ func (t *GameTable) GetTableInfo() map[string]interface{} {
 		"min_players":    t.MinPlayers,
 		"player_count":   t.GetPlayerCount(),
 		"observer_count": len(t.Observers),
+		"max_observers":  t.Settings.MaxObservers,
 		"settings":       t.Settings,
 		"description":    t.Description,
 		"tags":           t.Tags,
 		"room_id":        t.RoomID,
+		"currency":       t.GetCurrency(),
+		"practice":       t.IsPractice(),
+		"bots_allowed":   t.Settings.BotsAllowed,
+		"approval":       t.Approval,
+		"featured":       t.IsFeatured(),
+		"feature":        t.Feature,
 	}
 }
 
@@ -248,6 +341,25 @@ func (t *GameTable) GetDetailedInfo() map[string]interface{} {
 	return info
 }
 
+// GetCurrency returns the table currency, defaulting to diamonds
+func (t *GameTable) GetCurrency() TableCurrency {
+	if t.Settings.Currency == "" {
+		return CurrencyDiamonds
+	}
+	return t.Settings.Currency
+}
+
+// IsPractice reports whether the table plays for play money
+func (t *GameTable) IsPractice() bool {
+	return t.GetCurrency() == CurrencyPlayMoney
+}
+
+// UsesDiamondLedger reports whether buy-ins, cash-outs, rake and stats for the
+// table belong to the real-money ledger. Practice tables must never touch it.
+func (t *GameTable) UsesDiamondLedger() bool {
+	return !t.IsPractice()
+}
+
 // Touch updates the UpdatedAt timestamp
 func (t *GameTable) Touch() {
 	t.UpdatedAt = time.Now()
@@ -17,6 +17,7 @@ type JoinPlayerCommand struct {
 	PlayerID string
 	Username string
 	Position int
+	Chips    int
 	Response chan interface{}
 }
 
@@ -33,16 +34,19 @@ func (cmd *JoinPlayerCommand) Execute(table *GameTable) interface{} {
 		}
 	}
 
-	// Find available position
+	// Find available position; seats reserved for others are skipped
+	now := time.Now()
 	position := cmd.Position
 	if position <= 0 { // Use <= 0 for auto-assign (position 0 or negative)
-		// Auto-assign to first available slot
-		position = -1 // Reset to indicate not found yet
+		// A seat reserved for the player is theirs, otherwise the first available slot
+		position = table.reservedSeat(cmd.PlayerID, now)
 		for _, slot := range table.PlayerSlots {
-			if slot.PlayerID == "" {
-				position = slot.Position
+			if position != -1 {
 				break
 			}
+			if slot.openTo(cmd.PlayerID, now) {
+				position = slot.Position
+			}
 		}
 		if position == -1 {
 			return &TableError{"TABLE_FULL", "No available positions"}
@@ -56,9 +60,15 @@ func (cmd *JoinPlayerCommand) Execute(table *GameTable) interface{} {
 		if table.PlayerSlots[adjustedPos].PlayerID != "" {
 			return &TableError{"POSITION_OCCUPIED", "Position is already occupied"}
 		}
+		if table.PlayerSlots[adjustedPos].heldFor(cmd.PlayerID, now) {
+			return &TableError{"SEAT_RESERVED", "Seat is reserved for another player"}
+		}
 		position = adjustedPos // Use 0-based position internally
 	}
 
+	// Sitting down uses up the player's reservation, wherever it was
+	table.releaseReservations(cmd.PlayerID, now)
+
 	// Add player
 	for i := range table.PlayerSlots {
 		if table.PlayerSlots[i].Position == position {
@@ -67,6 +77,7 @@ func (cmd *JoinPlayerCommand) Execute(table *GameTable) interface{} {
 				PlayerID: cmd.PlayerID,
 				Username: cmd.Username,
 				IsReady:  false,
+				Chips:    cmd.Chips,
 				JoinedAt: time.Now(),
 			}
 			break
@@ -84,22 +95,44 @@ type LeavePlayerCommand struct {
 }
 
 func (cmd *LeavePlayerCommand) Execute(table *GameTable) interface{} {
-	// Find and remove player
-	found := false
+	// Find and remove player, returning the stack they leave with
 	for i := range table.PlayerSlots {
 		if table.PlayerSlots[i].PlayerID == cmd.PlayerID {
+			stack := table.heldChips(cmd.PlayerID)
 			table.PlayerSlots[i] = PlayerSlot{Position: table.PlayerSlots[i].Position}
-			found = true
-			break
+			table.UpdatedAt = time.Now()
+			return stack
 		}
 	}
+	return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
+}
 
-	if !found {
-		return &TableError{"PLAYER_NOT_AT_TABLE", "Player is not at this table"}
-	}
+// SeatStackCommand reads the chips a seated player holds
+type SeatStackCommand struct {
+	PlayerID string
+	Response chan interface{}
+}
 
-	table.UpdatedAt = time.Now()
-	return nil
+func (cmd *SeatStackCommand) Execute(table *GameTable) interface{} {
+	return table.heldChips(cmd.PlayerID)
+}
+
+// heldChips returns the chips a seated player holds: the engine's count when
+// it deals to them, otherwise the stack they sat down with. Only the table's
+// actor may call it.
+func (table *GameTable) heldChips(playerID string) int {
+	if holder, ok := table.GameEngine.(ChipHolder); ok {
+		stacks, _ := holder.ChipCounts()
+		if chips, seated := stacks[playerID]; seated {
+			return chips
+		}
+	}
+	for _, slot := range table.PlayerSlots {
+		if slot.PlayerID == playerID {
+			return slot.Chips
+		}
+	}
+	return 0
 }
 
 // JoinObserverCommand represents an observer joining request
@@ -133,6 +166,10 @@ func (cmd *JoinObserverCommand) Execute(table *GameTable) interface{} {
 		}
 	}
 
+	if table.ObserversFull() {
+		return &TableError{"OBSERVERS_FULL", "Table has reached its observer limit"}
+	}
+
 	// Add to observers
 	observer := TableObserver{
 		PlayerID: cmd.PlayerID,
@@ -144,6 +181,23 @@ func (cmd *JoinObserverCommand) Execute(table *GameTable) interface{} {
 	return nil
 }
 
+// LeaveObserverCommand represents an observer leaving request
+type LeaveObserverCommand struct {
+	PlayerID string
+	Response chan interface{}
+}
+
+func (cmd *LeaveObserverCommand) Execute(table *GameTable) interface{} {
+	for i, observer := range table.Observers {
+		if observer.PlayerID == cmd.PlayerID {
+			table.Observers = append(table.Observers[:i], table.Observers[i+1:]...)
+			table.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return &TableError{"PLAYER_NOT_OBSERVING", "Player is not observing this table"}
+}
+
 // GetTableInfoCommand represents a request for table information
 type GetTableInfoCommand struct {
 	Response chan interface{}
@@ -216,8 +270,40 @@ func (ta *TableActor) run() {
 				typedCmd.Response <- result
 			case *LeavePlayerCommand:
 				typedCmd.Response <- result
+			case *SeatStackCommand:
+				typedCmd.Response <- result
 			case *GetTableInfoCommand:
 				typedCmd.Response <- result
+			case *SetPlayerCosmeticsCommand:
+				typedCmd.Response <- result
+			case *SetPlayerConnectedCommand:
+				typedCmd.Response <- result
+			case *SetSittingOutCommand:
+				typedCmd.Response <- result
+			case *AddSeatChipsCommand:
+				typedCmd.Response <- result
+			case *BustOutCommand:
+				typedCmd.Response <- result
+			case *SyncHandSeatsCommand:
+				typedCmd.Response <- result
+			case *SetTableStatusCommand:
+				typedCmd.Response <- result
+			case *ReviewApprovalCommand:
+				typedCmd.Response <- result
+			case *SnapshotTableCommand:
+				typedCmd.Response <- result
+			case *ReserveSeatCommand:
+				typedCmd.Response <- result
+			case *ChangeSeatCommand:
+				typedCmd.Response <- result
+			case *UpdateSettingsCommand:
+				typedCmd.Response <- result
+			case *FeatureTableCommand:
+				typedCmd.Response <- result
+			case *LeaveObserverCommand:
+				typedCmd.Response <- result
+			case *ReconcileChipsCommand:
+				typedCmd.Response <- result
 			}
 
 		case <-ta.quit:
@@ -228,10 +314,16 @@ func (ta *TableActor) run() {
 
 // JoinPlayer sends a join command to the table actor
 func (ta *TableActor) JoinPlayer(ctx context.Context, playerID, username string, position int) error {
+	return ta.JoinPlayerWithChips(ctx, playerID, username, position, 0)
+}
+
+// JoinPlayerWithChips seats a player with the stack they bought in for
+func (ta *TableActor) JoinPlayerWithChips(ctx context.Context, playerID, username string, position, chips int) error {
 	cmd := &JoinPlayerCommand{
 		PlayerID: playerID,
 		Username: username,
 		Position: position,
+		Chips:    chips,
 		Response: make(chan interface{}, 1),
 	}
 
@@ -254,11 +346,53 @@ func (ta *TableActor) JoinPlayer(ctx context.Context, playerID, username string,
 }
 
 // LeavePlayer sends a leave command to the table actor
-func (ta *TableActor) LeavePlayer(ctx context.Context, playerID string) error {
+func (ta *TableActor) LeavePlayer(ctx context.Context, playerID string) (int, error) {
 	cmd := &LeavePlayerCommand{
 		PlayerID: playerID,
 		Response: make(chan interface{}, 1),
 	}
+	return ta.sendStackCommand(ctx, cmd, cmd.Response)
+}
+
+// SeatStack reads the chips a seated player holds through the table actor
+func (ta *TableActor) SeatStack(ctx context.Context, playerID string) (int, error) {
+	cmd := &SeatStackCommand{
+		PlayerID: playerID,
+		Response: make(chan interface{}, 1),
+	}
+	return ta.sendStackCommand(ctx, cmd, cmd.Response)
+}
+
+// sendStackCommand sends a command answering with a stack and waits for it
+func (ta *TableActor) sendStackCommand(ctx context.Context, cmd TableCommand, response chan interface{}) (int, error) {
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	select {
+	case result := <-response:
+		switch result := result.(type) {
+		case int:
+			return result, nil
+		case *TableError:
+			return 0, result
+		}
+		return 0, fmt.Errorf("unexpected response type")
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// JoinObserver sends a join observer command to the table actor
+func (ta *TableActor) JoinObserver(ctx context.Context, playerID, username string) error {
+	cmd := &JoinObserverCommand{
+		PlayerID: playerID,
+		Username: username,
+		Response: make(chan interface{}, 1),
+	}
 
 	select {
 	case ta.commands <- cmd:
@@ -278,11 +412,10 @@ func (ta *TableActor) LeavePlayer(ctx context.Context, playerID string) error {
 	}
 }
 
-// JoinObserver sends a join observer command to the table actor
-func (ta *TableActor) JoinObserver(ctx context.Context, playerID, username string) error {
-	cmd := &JoinObserverCommand{
+// LeaveObserver sends a leave observer command to the table actor
+func (ta *TableActor) LeaveObserver(ctx context.Context, playerID string) error {
+	cmd := &LeaveObserverCommand{
 		PlayerID: playerID,
-		Username: username,
 		Response: make(chan interface{}, 1),
 	}
 
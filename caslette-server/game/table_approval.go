@@ -0,0 +1,167 @@
+package game
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+// TableApprovalStatus tracks an admin's decision on a high-stakes table
+type TableApprovalStatus string
+
+const (
+	ApprovalPending  TableApprovalStatus = "pending"
+	ApprovalApproved TableApprovalStatus = "approved"
+	ApprovalRejected TableApprovalStatus = "rejected"
+)
+
+// TableApproval records the review of a table whose stakes are above the
+// approval threshold
+type TableApproval struct {
+	Status      TableApprovalStatus `json:"status"`
+	RequestedAt time.Time           `json:"requested_at"`
+	ReviewedBy  string              `json:"reviewed_by,omitempty"`
+	ReviewedAt  time.Time           `json:"reviewed_at,omitempty"`
+	Reason      string              `json:"reason,omitempty"`
+}
+
+// ApprovalNotifier tells a table's creator what an admin decided
+type ApprovalNotifier func(table *GameTable, approval TableApproval)
+
+// AwaitingApproval reports whether the table is held back from the lobby
+// until an admin approves it
+func (t *GameTable) AwaitingApproval() bool {
+	return t.Approval != nil && t.Approval.Status == ApprovalPending
+}
+
+// SetApprovalThreshold makes real-money tables created with a big blind above
+// bigBlind wait for admin approval; zero turns approval off
+func (tm *ActorTableManager) SetApprovalThreshold(bigBlind int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.approvalBigBlind = max(bigBlind, 0)
+}
+
+// SetApprovalNotifier sets who hears about approval decisions
+func (tm *ActorTableManager) SetApprovalNotifier(notifier ApprovalNotifier) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.approvalNotifier = notifier
+}
+
+// requiresApproval reports whether a new table's stakes need an admin's review
+func (tm *ActorTableManager) requiresApproval(table *GameTable) bool {
+	tm.mu.RLock()
+	threshold := tm.approvalBigBlind
+	tm.mu.RUnlock()
+	return threshold > 0 && table.UsesDiamondLedger() && table.Settings.BigBlind > threshold
+}
+
+// PendingApprovals lists the tables waiting for review, oldest first
+func (tm *ActorTableManager) PendingApprovals() []*GameTable {
+	var pending []*GameTable
+	for _, table := range tm.GetTables() {
+		if table.AwaitingApproval() {
+			pending = append(pending, table)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Approval.RequestedAt.Before(pending[j].Approval.RequestedAt)
+	})
+	return pending
+}
+
+// ReviewApprovalCommand records an admin's decision on a pending table
+type ReviewApprovalCommand struct {
+	Approved   bool
+	ReviewedBy string
+	Reason     string
+	Response   chan interface{}
+}
+
+func (cmd *ReviewApprovalCommand) Execute(table *GameTable) interface{} {
+	if !table.AwaitingApproval() {
+		return &TableError{"NOT_PENDING_APPROVAL", "Table is not waiting for approval"}
+	}
+	approval := *table.Approval
+	approval.Status = ApprovalRejected
+	if cmd.Approved {
+		approval.Status = ApprovalApproved
+	}
+	approval.ReviewedBy = cmd.ReviewedBy
+	approval.ReviewedAt = time.Now()
+	approval.Reason = cmd.Reason
+	table.Approval = &approval
+	table.UpdatedAt = approval.ReviewedAt
+	return approval
+}
+
+// ReviewApproval sends an approval decision to the table actor
+func (ta *TableActor) ReviewApproval(ctx context.Context, approved bool, reviewedBy, reason string) (TableApproval, error) {
+	cmd := &ReviewApprovalCommand{
+		Approved:   approved,
+		ReviewedBy: reviewedBy,
+		Reason:     reason,
+		Response:   make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return TableApproval{}, ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return TableApproval{}, err
+		}
+		if approval, ok := result.(TableApproval); ok {
+			return approval, nil
+		}
+		return TableApproval{}, &TableError{"UNEXPECTED_RESPONSE", "Unexpected response type"}
+	case <-ctx.Done():
+		return TableApproval{}, ctx.Err()
+	}
+}
+
+// ReviewTable approves a pending table, listing it in the lobby, or rejects
+// it, seeing its players out and closing it. The creator is notified either way.
+func (tm *ActorTableManager) ReviewTable(ctx context.Context, tableID, reviewedBy string, approved bool, reason string) (TableApproval, error) {
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	notifier := tm.approvalNotifier
+	tm.mu.RUnlock()
+	if !exists {
+		return TableApproval{}, ErrTableNotFound
+	}
+
+	approval, err := actor.ReviewApproval(ctx, approved, reviewedBy, reason)
+	if err != nil {
+		return TableApproval{}, err
+	}
+
+	if approved {
+		// The table was kept out of the lobby until now
+		tm.notifyTableListeners(actor.table, TableCreated)
+	} else {
+		for _, slot := range actor.table.PlayerSlots {
+			if slot.PlayerID == "" {
+				continue
+			}
+			if err := tm.LeaveTable(ctx, &TableLeaveRequest{TableID: tableID, PlayerID: slot.PlayerID}); err != nil {
+				log.Printf("Table %s: failed to unseat %s from rejected table: %v", tableID, slot.PlayerID, err)
+			}
+		}
+		if err := tm.CloseTable(tableID); err != nil {
+			log.Printf("Table %s: failed to close rejected table: %v", tableID, err)
+		}
+	}
+
+	if notifier != nil {
+		notifier(actor.table, approval)
+	}
+	return approval, nil
+}
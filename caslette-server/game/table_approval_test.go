@@ -0,0 +1,82 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newApprovalManager requires approval for tables with a big blind above 10
+// and records the decisions sent to creators
+func newApprovalManager(t *testing.T) (*ActorTableManager, *[]TableApproval) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	manager.SetApprovalThreshold(10)
+	var decisions []TableApproval
+	manager.SetApprovalNotifier(func(table *GameTable, approval TableApproval) {
+		decisions = append(decisions, approval)
+	})
+	return manager, &decisions
+}
+
+func TestHighStakesTablesWaitForApproval(t *testing.T) {
+	manager, _ := newApprovalManager(t)
+
+	high := newBalancingTable(t, manager, "high", DefaultTableSettings(), 0)
+	low := newBalancingTable(t, manager, "low", QuickGameSettings(), 0)
+	practice := DefaultTableSettings()
+	practice.Currency = CurrencyPlayMoney
+	newBalancingTable(t, manager, "practice", practice, 0)
+
+	require.True(t, high.AwaitingApproval())
+	assert.Nil(t, low.Approval, "stakes at the threshold need no approval")
+	assert.Len(t, manager.ListTables(nil), 2, "the pending table is not in the lobby")
+	assert.Len(t, manager.ListTables(map[string]interface{}{"created_by": "creator_high"}), 1, "its creator still sees it")
+
+	pending := manager.PendingApprovals()
+	require.Len(t, pending, 1)
+	assert.Equal(t, high.ID, pending[0].ID)
+
+	err := joinWithBuyIn(manager, high.ID, "guest", 1000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "waiting for admin approval")
+	assert.NoError(t, joinWithBuyIn(manager, high.ID, "creator_high", 1000))
+}
+
+func TestApprovedTableJoinsTheLobby(t *testing.T) {
+	manager, decisions := newApprovalManager(t)
+	table := newBalancingTable(t, manager, "high", DefaultTableSettings(), 0)
+
+	approval, err := manager.ReviewTable(context.Background(), table.ID, "admin", true, "")
+	require.NoError(t, err)
+	assert.Equal(t, ApprovalApproved, approval.Status)
+	assert.Equal(t, "admin", approval.ReviewedBy)
+	require.Len(t, *decisions, 1)
+	assert.Equal(t, ApprovalApproved, (*decisions)[0].Status)
+
+	assert.Len(t, manager.ListTables(nil), 1)
+	assert.Empty(t, manager.PendingApprovals())
+	assert.NoError(t, joinWithBuyIn(manager, table.ID, "guest", 1000))
+
+	_, err = manager.ReviewTable(context.Background(), table.ID, "admin", false, "")
+	assert.Error(t, err, "a table is reviewed once")
+}
+
+func TestRejectedTableIsClosed(t *testing.T) {
+	manager, decisions := newApprovalManager(t)
+	table := newBalancingTable(t, manager, "high", DefaultTableSettings(), 0)
+	require.NoError(t, joinWithBuyIn(manager, table.ID, "creator_high", 1000))
+
+	approval, err := manager.ReviewTable(context.Background(), table.ID, "admin", false, "stakes too high")
+	require.NoError(t, err)
+	assert.Equal(t, ApprovalRejected, approval.Status)
+	assert.Equal(t, "stakes too high", approval.Reason)
+	require.Len(t, *decisions, 1)
+	assert.Equal(t, "stakes too high", (*decisions)[0].Reason)
+
+	_, err = manager.GetTable(table.ID)
+	assert.ErrorIs(t, err, ErrTableNotFound)
+	assert.Zero(t, manager.Escrow().Total(table.ID), "the creator's buy-in is released")
+}
@@ -0,0 +1,57 @@
+package game
+
+import "fmt"
+
+// DefaultTableSeats is how many seats a table gets when its settings do not
+// ask for a number
+const DefaultTableSeats = 8
+
+// TableCapacity is the range of seats a game type can be played with and
+// the number a table gets by default
+type TableCapacity struct {
+	MinPlayers     int `json:"min_players"`
+	MaxPlayers     int `json:"max_players"`
+	DefaultPlayers int `json:"default_players"`
+}
+
+// CapacityFor returns the seats a game type allows. Every game is capped by
+// the operator's engine limit; Seven Card Stud also by the cards in a deck.
+func CapacityFor(gameType GameType) TableCapacity {
+	maxPlayers := CurrentGameDefaults().MaxPlayers
+	if gameType == GameTypeSevenCardStud {
+		maxPlayers = min(maxPlayers, StudMaxPlayers)
+	}
+	return TableCapacity{
+		MinPlayers:     2,
+		MaxPlayers:     maxPlayers,
+		DefaultPlayers: min(DefaultTableSeats, maxPlayers),
+	}
+}
+
+// tableCapacity returns the seats and players needed to deal that a table
+// of the game type with these settings gets; zero settings take the game
+// type's defaults
+func tableCapacity(gameType GameType, settings TableSettings) (minPlayers, maxPlayers int) {
+	capacity := CapacityFor(gameType)
+	minPlayers, maxPlayers = settings.MinPlayers, settings.MaxPlayers
+	if maxPlayers == 0 {
+		maxPlayers = capacity.DefaultPlayers
+	}
+	if minPlayers == 0 {
+		minPlayers = capacity.MinPlayers
+	}
+	return minPlayers, maxPlayers
+}
+
+// ValidateCapacity checks a table's seat settings against its game type
+func (v *TableValidator) ValidateCapacity(gameType GameType, settings TableSettings) error {
+	capacity := CapacityFor(gameType)
+	minPlayers, maxPlayers := tableCapacity(gameType, settings)
+	if settings.MaxPlayers < 0 || maxPlayers < capacity.MinPlayers || maxPlayers > capacity.MaxPlayers {
+		return fmt.Errorf("max players out of range for %s (%d-%d)", gameType, capacity.MinPlayers, capacity.MaxPlayers)
+	}
+	if settings.MinPlayers < 0 || minPlayers < capacity.MinPlayers || minPlayers > maxPlayers {
+		return fmt.Errorf("min players must be between %d and max players", capacity.MinPlayers)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacityPerGameType(t *testing.T) {
+	assert.Equal(t, TableCapacity{MinPlayers: 2, MaxPlayers: MaxEnginePlayers, DefaultPlayers: DefaultTableSeats}, CapacityFor(GameTypeTexasHoldem))
+	assert.Equal(t, MaxEnginePlayers, CapacityFor(GameTypeOmaha).MaxPlayers)
+	stud := CapacityFor(GameTypeSevenCardStud)
+	assert.Equal(t, StudMaxPlayers, stud.MaxPlayers)
+	assert.Equal(t, StudMaxPlayers, stud.DefaultPlayers)
+
+	validator := NewTableValidator()
+	settings := DefaultTableSettings()
+	assert.NoError(t, validator.ValidateCapacity(GameTypeTexasHoldem, settings), "zero takes the defaults")
+	settings.MaxPlayers = 10
+	assert.NoError(t, validator.ValidateCapacity(GameTypeTexasHoldem, settings))
+	assert.Error(t, validator.ValidateCapacity(GameTypeSevenCardStud, settings), "stud seats at most seven")
+	settings.MaxPlayers = 1
+	assert.Error(t, validator.ValidateCapacity(GameTypeTexasHoldem, settings))
+	settings.MaxPlayers, settings.MinPlayers = 4, 5
+	assert.Error(t, validator.ValidateCapacity(GameTypeTexasHoldem, settings), "min players cannot exceed max players")
+	settings.MinPlayers = -1
+	assert.Error(t, validator.ValidateCapacity(GameTypeTexasHoldem, settings))
+}
+
+func TestTableSeatsFollowSettings(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+	create := func(gameType GameType, minPlayers, maxPlayers int) (*GameTable, error) {
+		settings := DefaultTableSettings()
+		settings.MinPlayers, settings.MaxPlayers = minPlayers, maxPlayers
+		return manager.CreateTable(context.Background(), &TableCreateRequest{
+			Name: "Seats", GameType: gameType, CreatedBy: "creator", Username: "creator", Settings: settings,
+		})
+	}
+
+	table, err := create(GameTypeTexasHoldem, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTableSeats, table.MaxPlayers)
+	assert.Len(t, table.PlayerSlots, DefaultTableSeats)
+
+	table, err = create(GameTypeTexasHoldem, 3, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, table.MaxPlayers)
+	assert.Equal(t, 3, table.MinPlayers)
+	assert.Len(t, table.PlayerSlots, 10)
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, engine.AddPlayer(&Player{ID: fmt.Sprintf("p%d", i), Name: "player"}))
+	}
+	assert.Error(t, engine.AddPlayer(&Player{ID: "p10", Name: "player"}), "the engine seats no more than the table")
+
+	_, err = create(GameTypeSevenCardStud, 0, 8)
+	assert.Error(t, err)
+}
+
+func TestNarrowTableEngineCapsPlayers(t *testing.T) {
+	settings := DefaultTableSettings()
+	settings.MaxPlayers = 6
+	engine, err := (&TexasHoldemEngineFactory{}).CreateEngine(GameTypeOmaha, settings)
+	require.NoError(t, err)
+	holdem := engine.(*OmahaEngine).TexasHoldemEngine
+	assert.Equal(t, 6, holdem.maxPlayers)
+}
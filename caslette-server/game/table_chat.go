@@ -0,0 +1,214 @@
+package game
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Table chat limits
+const (
+	MaxTableChatLength = 280
+)
+
+// ChatFilter screens a chat line before it reaches the table. It returns the
+// text to show, masked if need be, and whether anything was masked.
+type ChatFilter interface {
+	Filter(text string) (clean string, filtered bool)
+}
+
+// TableChatLine is one message said at a table, kept for moderation review
+type TableChatLine struct {
+	TableID  string    `json:"table_id"`
+	HandID   string    `json:"hand_id,omitempty"` // Hand being played when it was said
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"`
+	Text     string    `json:"text"`               // What the table saw
+	Original string    `json:"original,omitempty"` // What was typed, when the filter masked it
+	Filtered bool      `json:"filtered"`
+	Observer bool      `json:"observer"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// TableChatStore persists chat lines for moderators
+type TableChatStore interface {
+	SaveChatLine(line TableChatLine) error
+}
+
+// WordFilter masks whole words from a list, ignoring case, with asterisks
+type WordFilter struct {
+	pattern *regexp.Regexp
+}
+
+// defaultProfanity is the word list masked when no other filter is set
+var defaultProfanity = []string{
+	"fuck", "fucking", "fucker", "shit", "bitch", "cunt", "asshole", "bastard", "dick", "motherfucker",
+}
+
+// NewWordFilter creates a filter masking the given words; with none it
+// masks nothing
+func NewWordFilter(words []string) *WordFilter {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return &WordFilter{}
+	}
+	return &WordFilter{pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+// DefaultChatFilter masks common profanity
+func DefaultChatFilter() *WordFilter {
+	return NewWordFilter(defaultProfanity)
+}
+
+// Filter masks every listed word in text
+func (f *WordFilter) Filter(text string) (string, bool) {
+	if f.pattern == nil {
+		return text, false
+	}
+	filtered := false
+	clean := f.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		filtered = true
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+	return clean, filtered
+}
+
+// TableChat runs each table's chat: it filters what is said, keeps the
+// players each table's creator has muted and hands lines to the store
+type TableChat struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	filter ChatFilter
+	store  TableChatStore
+	muted  map[string]map[string]bool // Table ID -> muted player IDs
+}
+
+// NewTableChat creates table chat with the default profanity filter and no
+// store
+func NewTableChat() *TableChat {
+	return &TableChat{
+		now:    time.Now,
+		filter: DefaultChatFilter(),
+		muted:  make(map[string]map[string]bool),
+	}
+}
+
+// SetFilter replaces the chat filter; nil lets every line through unchanged
+func (tc *TableChat) SetFilter(filter ChatFilter) {
+	tc.mu.Lock()
+	tc.filter = filter
+	tc.mu.Unlock()
+}
+
+// SetStore sets where chat lines are persisted
+func (tc *TableChat) SetStore(store TableChatStore) {
+	tc.mu.Lock()
+	tc.store = store
+	tc.mu.Unlock()
+}
+
+// Post filters a line said by a player or observer at the table and records
+// it. The caller broadcasts the returned line to the table's room.
+func (tc *TableChat) Post(table *GameTable, playerID, username, text string) (TableChatLine, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return TableChatLine{}, &TableError{"EMPTY_MESSAGE", "Chat message is empty"}
+	}
+	if utf8.RuneCountInString(text) > MaxTableChatLength {
+		return TableChatLine{}, &TableError{"MESSAGE_TOO_LONG", "Chat messages are limited to 280 characters"}
+	}
+	observer := table.IsObserver(playerID)
+	if !observer && !table.IsPlayerAtTable(playerID) {
+		return TableChatLine{}, ErrPlayerNotAtTable
+	}
+
+	tc.mu.Lock()
+	if tc.muted[table.ID][playerID] {
+		tc.mu.Unlock()
+		return TableChatLine{}, &TableError{"CHAT_MUTED", "You have been muted at this table"}
+	}
+	filter, store := tc.filter, tc.store
+	tc.mu.Unlock()
+
+	line := TableChatLine{
+		TableID:  table.ID,
+		PlayerID: playerID,
+		Username: username,
+		Text:     text,
+		Observer: observer,
+		SentAt:   tc.now(),
+	}
+	if identifier, ok := table.GameEngine.(LiveHandIdentifier); ok {
+		line.HandID = identifier.LiveHandID()
+	}
+	if filter != nil {
+		if clean, filtered := filter.Filter(text); filtered {
+			line.Text, line.Original, line.Filtered = clean, text, true
+		}
+	}
+
+	if store != nil {
+		if err := store.SaveChatLine(line); err != nil {
+			log.Printf("Failed to save chat line at table %s: %v", table.ID, err)
+		}
+	}
+	return line, nil
+}
+
+// SetMuted mutes or unmutes a player's chat at a table. Only the table's
+// creator may, and not themselves.
+func (tc *TableChat) SetMuted(table *GameTable, byPlayerID, playerID string, muted bool) error {
+	if table.CreatedBy != byPlayerID {
+		return &TableError{"NOT_TABLE_CREATOR", "Only the table creator can mute players"}
+	}
+	if playerID == "" || playerID == byPlayerID {
+		return &TableError{"INVALID_PLAYER", "Cannot mute this player"}
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if !muted {
+		delete(tc.muted[table.ID], playerID)
+		return nil
+	}
+	if tc.muted[table.ID] == nil {
+		tc.muted[table.ID] = make(map[string]bool)
+	}
+	tc.muted[table.ID][playerID] = true
+	return nil
+}
+
+// IsMuted reports whether the player is muted at the table
+func (tc *TableChat) IsMuted(tableID, playerID string) bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.muted[tableID][playerID]
+}
+
+// Muted returns the players muted at the table
+func (tc *TableChat) Muted(tableID string) []string {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	players := make([]string, 0, len(tc.muted[tableID]))
+	for playerID := range tc.muted[tableID] {
+		players = append(players, playerID)
+	}
+	sort.Strings(players)
+	return players
+}
+
+// RemoveTable forgets a closed table's mutes
+func (tc *TableChat) RemoveTable(tableID string) {
+	tc.mu.Lock()
+	delete(tc.muted, tableID)
+	tc.mu.Unlock()
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingChatStore struct {
+	lines []TableChatLine
+}
+
+func (s *recordingChatStore) SaveChatLine(line TableChatLine) error {
+	s.lines = append(s.lines, line)
+	return nil
+}
+
+// newChatTable creates a table by "owner" with "player" seated and "watcher"
+// observing, behind a websocket handler broadcasting to hub
+func newChatTable(t *testing.T) (*TableWebSocketHandler, *MockWebSocketHub, *GameTable, *recordingChatStore) {
+	manager := NewActorTableManager(&MockGameEngineFactory{})
+	t.Cleanup(manager.Stop)
+	hub := &MockWebSocketHub{}
+	handler := NewTableWebSocketHandler(manager, hub)
+	t.Cleanup(handler.events.Stop)
+	store := &recordingChatStore{}
+	manager.TableChat().SetStore(store)
+
+	ctx := context.Background()
+	table, err := manager.CreateTable(ctx, &TableCreateRequest{Name: "Chat", GameType: GameTypeTexasHoldem, CreatedBy: "owner", Username: "Owner", Settings: DefaultTableSettings()})
+	require.NoError(t, err)
+	for _, join := range []*TableJoinRequest{
+		{TableID: table.ID, PlayerID: "owner", Username: "Owner", Mode: JoinModePlayer},
+		{TableID: table.ID, PlayerID: "player", Username: "Player", Mode: JoinModePlayer},
+		{TableID: table.ID, PlayerID: "watcher", Username: "Watcher", Mode: JoinModeObserver},
+	} {
+		require.NoError(t, manager.JoinTable(ctx, join))
+	}
+	hub.broadcastCalls = nil
+	return handler, hub, table, store
+}
+
+func chatMessage(msgType string, data map[string]interface{}) *WebSocketMessage {
+	return &WebSocketMessage{Type: msgType, RequestID: "r1", Data: data}
+}
+
+func TestWordFilterMasksWholeWords(t *testing.T) {
+	filter := DefaultChatFilter()
+
+	clean, filtered := filter.Filter("Well SHIT, nice hand")
+	assert.True(t, filtered)
+	assert.Equal(t, "Well ****, nice hand", clean)
+
+	clean, filtered = filter.Filter("Dickens would have folded that")
+	assert.False(t, filtered, "words inside other words are left alone")
+	assert.Equal(t, "Dickens would have folded that", clean)
+
+	clean, filtered = NewWordFilter(nil).Filter("shit")
+	assert.False(t, filtered)
+	assert.Equal(t, "shit", clean)
+}
+
+func TestTableChatBroadcastsFilteredLines(t *testing.T) {
+	handler, hub, table, store := newChatTable(t)
+	handlers := handler.GetMessageHandlers()
+
+	response := handlers["table_chat"](context.Background(), NewMockConnection("watcher", "Watcher"),
+		chatMessage("table_chat", map[string]interface{}{"table_id": table.ID, "text": "  what a fucking river  "}))
+	require.True(t, response.Success, response.Error)
+	assert.Equal(t, "table_chat_sent", response.Type)
+
+	require.Len(t, hub.broadcastCalls, 1)
+	call := hub.broadcastCalls[0]
+	assert.Equal(t, table.RoomID, call.RoomID)
+	broadcast := call.Message.(*WebSocketMessage)
+	assert.Equal(t, "table_chat_message", broadcast.Type)
+	line := broadcast.Data.(TableChatLine)
+	assert.Equal(t, "what a ******* river", line.Text)
+	assert.Empty(t, line.Original, "the table never sees what was masked")
+	assert.True(t, line.Observer)
+	assert.Equal(t, "Watcher", line.Username)
+
+	require.Len(t, store.lines, 1)
+	assert.Equal(t, "what a fucking river", store.lines[0].Original, "moderators see what was typed")
+	assert.True(t, store.lines[0].Filtered)
+	assert.Equal(t, table.ID, store.lines[0].TableID)
+}
+
+func TestTableChatRejectsOutsidersAndBadLines(t *testing.T) {
+	handler, hub, table, store := newChatTable(t)
+	chat := handler.GetMessageHandlers()["table_chat"]
+	say := func(userID, text string) *WebSocketMessage {
+		return chat(context.Background(), NewMockConnection(userID, userID),
+			chatMessage("table_chat", map[string]interface{}{"table_id": table.ID, "text": text}))
+	}
+
+	assert.False(t, say("stranger", "hello").Success, "only players and observers chat")
+	assert.False(t, say("player", "   ").Success)
+	assert.False(t, say("player", strings.Repeat("a", MaxTableChatLength+1)).Success)
+	assert.True(t, say("player", strings.Repeat("a", MaxTableChatLength)).Success)
+
+	assert.Len(t, hub.broadcastCalls, 1)
+	assert.Len(t, store.lines, 1)
+}
+
+func TestTableCreatorMutesChat(t *testing.T) {
+	handler, _, table, store := newChatTable(t)
+	handlers := handler.GetMessageHandlers()
+	mute := func(by, player string, muted bool) *WebSocketMessage {
+		return handlers["table_chat_mute"](context.Background(), NewMockConnection(by, by),
+			chatMessage("table_chat_mute", map[string]interface{}{"table_id": table.ID, "player_id": player, "muted": muted}))
+	}
+	say := func(userID string) *WebSocketMessage {
+		return handlers["table_chat"](context.Background(), NewMockConnection(userID, userID),
+			chatMessage("table_chat", map[string]interface{}{"table_id": table.ID, "text": "gl"}))
+	}
+
+	assert.False(t, mute("player", "watcher", true).Success, "only the creator mutes")
+	assert.False(t, mute("owner", "owner", true).Success)
+
+	response := mute("owner", "watcher", true)
+	require.True(t, response.Success, response.Error)
+	assert.Equal(t, []string{"watcher"}, response.Data.(map[string]interface{})["muted"])
+
+	muted := say("watcher")
+	assert.False(t, muted.Success)
+	assert.Contains(t, muted.Error, "muted")
+	assert.True(t, say("player").Success)
+
+	require.True(t, mute("owner", "watcher", false).Success)
+	assert.True(t, say("watcher").Success)
+	assert.Len(t, store.lines, 2)
+}
+
+func TestClosedTableForgetsChatMutes(t *testing.T) {
+	chat := NewTableChat()
+	table := &GameTable{ID: "t1", CreatedBy: "owner"}
+	require.NoError(t, chat.SetMuted(table, "owner", "p1", true))
+	assert.True(t, chat.IsMuted("t1", "p1"))
+
+	chat.RemoveTable("t1")
+	assert.False(t, chat.IsMuted("t1", "p1"))
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"context"
+)
+
+// tableChatRequest is a line said at a table
+type tableChatRequest struct {
+	TableID string `json:"table_id"`
+	Text    string `json:"text"`
+}
+
+// tableChatMuteRequest names who the table creator mutes or unmutes
+type tableChatMuteRequest struct {
+	TableID  string `json:"table_id"`
+	PlayerID string `json:"player_id"`
+	Muted    bool   `json:"muted"`
+}
+
+// handleTableChat filters a line from a player or observer and broadcasts it
+// to the table's room
+func (h *TableWebSocketHandler) handleTableChat(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req tableChatRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	table, err := h.tableManager.GetTable(req.TableID)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "TABLE_NOT_FOUND", err.Error())
+	}
+	line, err := h.tableManager.TableChat().Post(table, conn.GetUserID(), conn.GetUsername(), req.Text)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "CHAT_FAILED", err.Error())
+	}
+
+	// Everyone at the table sees the filtered text only
+	line.Original = ""
+	h.broadcastTableUpdate(table, "table_chat_message", line)
+	return h.successResponse(msg.RequestID, "table_chat_sent", line)
+}
+
+// handleTableChatMute lets a table's creator mute or unmute a player's chat
+func (h *TableWebSocketHandler) handleTableChatMute(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req tableChatMuteRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	table, err := h.tableManager.GetTable(req.TableID)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "TABLE_NOT_FOUND", err.Error())
+	}
+	chat := h.tableManager.TableChat()
+	if err := chat.SetMuted(table, conn.GetUserID(), req.PlayerID, req.Muted); err != nil {
+		return h.errorResponse(msg.RequestID, "MUTE_FAILED", err.Error())
+	}
+
+	h.broadcastTableUpdate(table, "table_chat_muted", map[string]interface{}{
+		"table_id":  table.ID,
+		"player_id": req.PlayerID,
+		"muted":     req.Muted,
+	})
+	return h.successResponse(msg.RequestID, "table_chat_mute_updated", map[string]interface{}{
+		"table_id": table.ID,
+		"muted":    chat.Muted(table.ID),
+	})
+}
@@ -3,12 +3,17 @@ package game
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
-// TexasHoldemEngineFactory implements GameEngineFactory for Texas Hold'em
+// TexasHoldemEngineFactory implements GameEngineFactory for the poker games
+// dealt on the hold'em engine: Texas Hold'em, Omaha and Seven Card Stud
 type TexasHoldemEngineFactory struct{}
 
 func (f *TexasHoldemEngineFactory) CreateEngine(gameType GameType, settings TableSettings) (GameEngine, error) {
+	// The engine deals to as many players as the table seats
+	_, maxPlayers := tableCapacity(gameType, settings)
+
 	switch gameType {
 	case GameTypeTexasHoldem:
 		engine := NewTexasHoldemEngine("table_game")
@@ -16,6 +21,45 @@ func (f *TexasHoldemEngineFactory) CreateEngine(gameType GameType, settings Tabl
 		// Configure engine with table settings
 		engine.SetSmallBlind(settings.SmallBlind)
 		engine.SetBigBlind(settings.BigBlind)
+		engine.SetMaxPlayers(maxPlayers)
+		engine.SetAnte(settings.Ante)
+		engine.SetTurnTimeLimit(time.Duration(settings.TimeLimit) * time.Second)
+		engine.SetTimeBank(time.Duration(settings.TimeBank) * time.Second)
+		engine.SetShuffleCommitments(settings.ProvablyFair)
+		engine.SetDecisionSupport(settings.DecisionSupport)
+		engine.SetRunoutPacing(tableRunoutPacing(settings))
+		engine.SetStraddleAllowed(settings.Straddle)
+		engine.SetRake(tableRake(settings))
+
+		return engine, nil
+	case GameTypeOmaha:
+		engine := NewOmahaEngine("table_game")
+		engine.SetSmallBlind(settings.SmallBlind)
+		engine.SetBigBlind(settings.BigBlind)
+		engine.SetMaxPlayers(maxPlayers)
+		engine.SetAnte(settings.Ante)
+		engine.SetTurnTimeLimit(time.Duration(settings.TimeLimit) * time.Second)
+		engine.SetTimeBank(time.Duration(settings.TimeBank) * time.Second)
+		engine.SetShuffleCommitments(settings.ProvablyFair)
+		engine.SetDecisionSupport(settings.DecisionSupport)
+		engine.SetRunoutPacing(tableRunoutPacing(settings))
+		engine.SetStraddleAllowed(settings.Straddle)
+		engine.SetRake(tableRake(settings))
+
+		return engine, nil
+	case GameTypeSevenCardStud:
+		// The small blind is the bring-in and the big blind the small bet
+		engine := NewStudEngine("table_game")
+		engine.SetSmallBlind(settings.SmallBlind)
+		engine.SetBigBlind(settings.BigBlind)
+		engine.SetMaxPlayers(maxPlayers)
+		engine.SetAnte(settings.Ante)
+		engine.SetTurnTimeLimit(time.Duration(settings.TimeLimit) * time.Second)
+		engine.SetTimeBank(time.Duration(settings.TimeBank) * time.Second)
+		engine.SetShuffleCommitments(settings.ProvablyFair)
+		engine.SetDecisionSupport(settings.DecisionSupport)
+		engine.SetRunoutPacing(tableRunoutPacing(settings))
+		engine.SetRake(tableRake(settings))
 
 		return engine, nil
 	default:
@@ -40,6 +84,15 @@ func NewTableGameIntegration(hub WebSocketHub) *TableGameIntegration {
 	// Create websocket handler
 	wsHandler := NewTableWebSocketHandler(tableManager, hub)
 
+	// Hubs that can reach individual players also get end-of-hand summaries,
+	// heads-up match notices and their tables' state when they reconnect
+	if messenger, ok := hub.(PlayerMessenger); ok {
+		tableManager.HandStats().SetMessenger(messenger)
+		tableManager.HeadsUp().SetMessenger(messenger)
+		tableManager.Invitations().SetMessenger(messenger)
+		tableManager.SetReconnectMessenger(messenger)
+	}
+
 	return &TableGameIntegration{
 		tableManager: tableManager,
 		wsHandler:    wsHandler,
@@ -108,6 +161,13 @@ func TournamentSettings() TableSettings {
 	}
 }
 
+// PracticeTableSettings returns settings for play-money practice tables
+func PracticeTableSettings() TableSettings {
+	settings := QuickGameSettings()
+	settings.Currency = CurrencyPlayMoney
+	return settings
+}
+
 // PrivateTableSettings returns settings for private tables
 func PrivateTableSettings(password string) TableSettings {
 	settings := DefaultTableSettings()
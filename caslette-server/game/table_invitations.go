@@ -0,0 +1,184 @@
+package game
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultInvitationTTL is how long an invitation lets its user skip a private
+// table's password
+const DefaultInvitationTTL = 15 * time.Minute
+
+// UsernameResolver looks up the player ID of a username
+type UsernameResolver func(username string) (playerID string, err error)
+
+// TableInvitation lets a user join a private table without its password
+// until it expires
+type TableInvitation struct {
+	TableID       string    `json:"table_id"`
+	TableName     string    `json:"table_name"`
+	PlayerID      string    `json:"player_id"`
+	Username      string    `json:"username"`
+	InvitedBy     string    `json:"invited_by"`
+	InvitedByName string    `json:"invited_by_name"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// TableInvitations keeps the invitations table creators have sent and
+// pushes each to the invited user's connections
+type TableInvitations struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	ttl       time.Duration
+	invites   map[string]map[string]TableInvitation // Table ID -> player ID -> invitation
+	resolver  UsernameResolver
+	messenger PlayerMessenger
+}
+
+// NewTableInvitations creates an invitation list that cannot resolve
+// usernames until a resolver is set
+func NewTableInvitations() *TableInvitations {
+	return &TableInvitations{
+		now:     time.Now,
+		ttl:     DefaultInvitationTTL,
+		invites: make(map[string]map[string]TableInvitation),
+	}
+}
+
+// SetResolver sets how invited usernames are looked up
+func (ti *TableInvitations) SetResolver(resolver UsernameResolver) {
+	ti.mu.Lock()
+	ti.resolver = resolver
+	ti.mu.Unlock()
+}
+
+// SetMessenger sets how invitations reach the invited user
+func (ti *TableInvitations) SetMessenger(messenger PlayerMessenger) {
+	ti.mu.Lock()
+	ti.messenger = messenger
+	ti.mu.Unlock()
+}
+
+// SetTTL sets how long new invitations last; non-positive values keep the
+// default
+func (ti *TableInvitations) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultInvitationTTL
+	}
+	ti.mu.Lock()
+	ti.ttl = ttl
+	ti.mu.Unlock()
+}
+
+// Invite records an invitation from the table's creator to a user named by
+// username and pushes it to them. Inviting a user again renews it.
+func (ti *TableInvitations) Invite(table *GameTable, byPlayerID, byUsername, username string) (TableInvitation, error) {
+	if table.CreatedBy != byPlayerID {
+		return TableInvitation{}, &TableError{"NOT_TABLE_CREATOR", "Only the table creator can invite players"}
+	}
+	if table.Status == TableStatusClosed {
+		return TableInvitation{}, &TableError{"TABLE_CLOSED", "Table is closed"}
+	}
+
+	ti.mu.Lock()
+	resolver := ti.resolver
+	ti.mu.Unlock()
+	if resolver == nil {
+		return TableInvitation{}, &TableError{"INVITATIONS_UNAVAILABLE", "Invitations are not available"}
+	}
+	playerID, err := resolver(username)
+	if err != nil || playerID == "" {
+		return TableInvitation{}, &TableError{"USER_NOT_FOUND", "No user named " + username}
+	}
+	if playerID == byPlayerID {
+		return TableInvitation{}, &TableError{"INVALID_PLAYER", "Cannot invite yourself"}
+	}
+	if table.IsPlayerAtTable(playerID) {
+		return TableInvitation{}, ErrPlayerAlreadyAtTable
+	}
+
+	ti.mu.Lock()
+	invitation := TableInvitation{
+		TableID:       table.ID,
+		TableName:     table.Name,
+		PlayerID:      playerID,
+		Username:      username,
+		InvitedBy:     byPlayerID,
+		InvitedByName: byUsername,
+		ExpiresAt:     ti.now().Add(ti.ttl),
+	}
+	if ti.invites[table.ID] == nil {
+		ti.invites[table.ID] = make(map[string]TableInvitation)
+	}
+	ti.invites[table.ID][playerID] = invitation
+	messenger := ti.messenger
+	ti.mu.Unlock()
+
+	if messenger != nil {
+		msg := &WebSocketMessage{Type: "table_invitation", Data: invitation, Success: true}
+		if err := messenger.SendToPlayer(playerID, msg); err != nil {
+			log.Printf("Failed to deliver table invitation to player %s: %v", playerID, err)
+		}
+	}
+	return invitation, nil
+}
+
+// Invited reports whether the player holds an unexpired invitation to the
+// table
+func (ti *TableInvitations) Invited(tableID, playerID string) bool {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	invitation, ok := ti.invites[tableID][playerID]
+	if ok && !ti.now().Before(invitation.ExpiresAt) {
+		delete(ti.invites[tableID], playerID)
+		return false
+	}
+	return ok
+}
+
+// Accept uses up the player's invitation once they have joined the table
+func (ti *TableInvitations) Accept(tableID, playerID string) {
+	ti.mu.Lock()
+	delete(ti.invites[tableID], playerID)
+	ti.mu.Unlock()
+}
+
+// Pending returns the table's unexpired invitations, soonest to expire first
+func (ti *TableInvitations) Pending(tableID string) []TableInvitation {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	now := ti.now()
+	pending := make([]TableInvitation, 0, len(ti.invites[tableID]))
+	for playerID, invitation := range ti.invites[tableID] {
+		if !now.Before(invitation.ExpiresAt) {
+			delete(ti.invites[tableID], playerID)
+			continue
+		}
+		pending = append(pending, invitation)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ExpiresAt.Before(pending[j].ExpiresAt)
+	})
+	return pending
+}
+
+// RemoveTable drops a closed table's invitations
+func (ti *TableInvitations) RemoveTable(tableID string) {
+	ti.mu.Lock()
+	delete(ti.invites, tableID)
+	ti.mu.Unlock()
+}
+
+// Invitations returns the invitations to the manager's private tables
+func (tm *ActorTableManager) Invitations() *TableInvitations {
+	return tm.invitations
+}
+
+// passwordRequired reports whether the player must give the table's
+// password to join: private tables with a password ask everyone but the
+// users their creator has invited
+func (tm *ActorTableManager) passwordRequired(table *GameTable, playerID string) bool {
+	return table.Settings.Private && table.HasPassword() && !tm.invitations.Invited(table.ID, playerID)
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newInvitationTable creates a password-protected table owned by "owner",
+// resolving usernames "alice" and "bob" to "2" and "3"
+func newInvitationTable(t *testing.T) (*ActorTableManager, *GameTable, *recordingMessenger) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	messenger := newRecordingMessenger()
+	invitations := manager.Invitations()
+	invitations.SetMessenger(messenger)
+	invitations.SetResolver(func(username string) (string, error) {
+		switch username {
+		case "alice":
+			return "2", nil
+		case "bob":
+			return "3", nil
+		}
+		return "", errors.New("record not found")
+	})
+
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "Private", GameType: GameTypeTexasHoldem, CreatedBy: "owner", Username: "owner",
+		Settings: PrivateTableSettings("secret"),
+	})
+	require.NoError(t, err)
+	return manager, table, messenger
+}
+
+func joinPrivate(manager *ActorTableManager, tableID, playerID, password string) error {
+	return manager.JoinTable(context.Background(), &TableJoinRequest{
+		TableID: tableID, PlayerID: playerID, Username: "player" + playerID, Mode: JoinModePlayer, Password: password,
+	})
+}
+
+func TestInvitedPlayerSkipsThePassword(t *testing.T) {
+	manager, table, messenger := newInvitationTable(t)
+
+	require.Error(t, joinPrivate(manager, table.ID, "2", ""), "uninvited players need the password")
+
+	invitation, err := manager.Invitations().Invite(table, "owner", "Owner", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "2", invitation.PlayerID)
+	assert.Equal(t, table.Name, invitation.TableName)
+
+	select {
+	case playerID := <-messenger.to:
+		msg := <-messenger.sent
+		assert.Equal(t, "2", playerID)
+		assert.Equal(t, "table_invitation", msg.Type)
+		assert.Equal(t, invitation, msg.Data)
+	case <-time.After(time.Second):
+		t.Fatal("invitation not pushed")
+	}
+
+	preview, err := manager.PreviewBuyIn("2", table.ID, 0, "")
+	require.NoError(t, err)
+	assert.True(t, preview.CanJoin, "the buy-in preview knows about the invitation")
+
+	require.Error(t, joinPrivate(manager, table.ID, "3", ""), "invitations are personal")
+	require.NoError(t, joinPrivate(manager, table.ID, "2", ""))
+	assert.False(t, manager.Invitations().Invited(table.ID, "2"), "joining uses the invitation up")
+}
+
+func TestInvitationsExpire(t *testing.T) {
+	manager, table, _ := newInvitationTable(t)
+	invitations := manager.Invitations()
+	now := time.Now()
+	invitations.now = func() time.Time { return now }
+
+	_, err := invitations.Invite(table, "owner", "Owner", "alice")
+	require.NoError(t, err)
+	assert.Len(t, invitations.Pending(table.ID), 1)
+
+	now = now.Add(DefaultInvitationTTL)
+	assert.False(t, invitations.Invited(table.ID, "2"))
+	assert.Empty(t, invitations.Pending(table.ID))
+	assert.Error(t, joinPrivate(manager, table.ID, "2", ""))
+}
+
+func TestOnlyTheCreatorInvites(t *testing.T) {
+	manager, table, _ := newInvitationTable(t)
+	invitations := manager.Invitations()
+
+	_, err := invitations.Invite(table, "2", "alice", "bob")
+	assert.Error(t, err)
+	_, err = invitations.Invite(table, "owner", "Owner", "nobody")
+	assert.ErrorContains(t, err, "No user named nobody")
+
+	invitations.SetResolver(func(string) (string, error) { return "owner", nil })
+	_, err = invitations.Invite(table, "owner", "Owner", "owner")
+	assert.Error(t, err, "creators cannot invite themselves")
+}
+
+func TestTableInviteMessage(t *testing.T) {
+	manager, table, _ := newInvitationTable(t)
+	handler := NewTableWebSocketHandler(manager, &MockWebSocketHub{})
+	t.Cleanup(handler.events.Stop)
+	invite := handler.GetMessageHandlers()["table_invite"]
+
+	response := invite(context.Background(), NewMockConnection("owner", "Owner"),
+		&WebSocketMessage{Type: "table_invite", RequestID: "r1", Data: map[string]interface{}{"table_id": table.ID, "username": "bob"}})
+	require.True(t, response.Success, response.Error)
+	assert.Equal(t, "table_invite_sent", response.Type)
+	assert.Equal(t, "3", response.Data.(TableInvitation).PlayerID)
+
+	response = invite(context.Background(), NewMockConnection("owner", "Owner"),
+		&WebSocketMessage{Type: "table_invite", RequestID: "r2", Data: map[string]interface{}{"table_id": table.ID}})
+	assert.False(t, response.Success)
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"context"
+)
+
+// tableInviteRequest names the table and the user its creator invites
+type tableInviteRequest struct {
+	TableID  string `json:"table_id"`
+	Username string `json:"username"`
+}
+
+// handleTableInvite lets a table's creator invite a user by username; the
+// invited user is pushed a table_invitation on every connection
+func (h *TableWebSocketHandler) handleTableInvite(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req tableInviteRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+	if req.Username == "" {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "username is required")
+	}
+
+	table, err := h.tableManager.GetTable(req.TableID)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "TABLE_NOT_FOUND", err.Error())
+	}
+	invitation, err := h.tableManager.Invitations().Invite(table, conn.GetUserID(), conn.GetUsername(), req.Username)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "INVITE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "table_invite_sent", invitation)
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Table listing page sizes
+const (
+	DefaultTableListLimit = 20
+	MaxTableListLimit     = 100
+)
+
+// TableListSort is the order a table listing is returned in
+type TableListSort string
+
+const (
+	TableSortCreated TableListSort = "created" // When the table opened
+	TableSortPlayers TableListSort = "players" // How many seats are taken
+	TableSortStakes  TableListSort = "stakes"  // Big blind
+)
+
+// TableListQuery asks for one page of the lobby. Filters are those of
+// ListTables; Search matches table names and tags, ignoring case.
+type TableListQuery struct {
+	Filters    map[string]interface{}
+	Search     string
+	Sort       TableListSort
+	Descending bool
+	Page       int
+	Limit      int
+}
+
+// TableListPage is one page of a table listing, with the total the page is
+// drawn from
+type TableListPage struct {
+	Tables     []*GameTable `json:"tables"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	Total      int          `json:"total"`
+	TotalPages int          `json:"total_pages"`
+}
+
+// ListTablesPage returns one page of the tables matching the query, sorted
+// as asked after the featured tables, which are pinned first. It defaults to
+// the first page of DefaultTableListLimit tables in the order they opened.
+func (tm *ActorTableManager) ListTablesPage(query TableListQuery) (*TableListPage, error) {
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultTableListLimit
+	}
+	if query.Page < 0 || query.Limit < 0 || query.Limit > MaxTableListLimit {
+		return nil, &TableError{"INVALID_PAGE", fmt.Sprintf("page must be positive and limit between 1 and %d", MaxTableListLimit)}
+	}
+	if query.Sort == "" {
+		query.Sort = TableSortCreated
+	}
+	less, ok := tableSorts[query.Sort]
+	if !ok {
+		return nil, &TableError{"INVALID_SORT", "sort must be created, players or stakes"}
+	}
+
+	search := strings.ToLower(strings.TrimSpace(query.Search))
+	tables := make([]*GameTable, 0)
+	for _, table := range tm.ListTables(query.Filters) {
+		if search == "" || tableMatchesSearch(table, search) {
+			tables = append(tables, table)
+		}
+	}
+
+	// Ties fall back to the oldest table first so pages do not shuffle
+	sort.SliceStable(tables, func(i, j int) bool {
+		a, b := tables[i], tables[j]
+		if query.Descending {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		if !tables[i].CreatedAt.Equal(tables[j].CreatedAt) {
+			return tables[i].CreatedAt.Before(tables[j].CreatedAt)
+		}
+		return tables[i].ID < tables[j].ID
+	})
+	sortFeaturedFirst(tables)
+
+	page := &TableListPage{
+		Tables:     make([]*GameTable, 0, query.Limit),
+		Page:       query.Page,
+		Limit:      query.Limit,
+		Total:      len(tables),
+		TotalPages: (len(tables) + query.Limit - 1) / query.Limit,
+	}
+	if start := (query.Page - 1) * query.Limit; start < len(tables) {
+		end := start + query.Limit
+		if end > len(tables) {
+			end = len(tables)
+		}
+		page.Tables = append(page.Tables, tables[start:end]...)
+	}
+	return page, nil
+}
+
+// tableSorts orders tables ascending by each sort key
+var tableSorts = map[TableListSort]func(a, b *GameTable) bool{
+	TableSortCreated: func(a, b *GameTable) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	TableSortPlayers: func(a, b *GameTable) bool { return a.GetPlayerCount() < b.GetPlayerCount() },
+	TableSortStakes:  func(a, b *GameTable) bool { return a.Settings.BigBlind < b.Settings.BigBlind },
+}
+
+// tableMatchesSearch reports whether the lowercased search term appears in
+// the table's name or one of its tags
+func tableMatchesSearch(table *GameTable, search string) bool {
+	if strings.Contains(strings.ToLower(table.Name), search) {
+		return true
+	}
+	for _, tag := range table.Tags {
+		if strings.Contains(strings.ToLower(tag), search) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newListingTables opens three tables an hour apart: "low" with one player
+// at QuickGameSettings, "mid" with three and "high" with two at doubled
+// default stakes, tagged "deep"
+func newListingTables(t *testing.T) (*ActorTableManager, map[string]*GameTable) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+
+	high := DefaultTableSettings()
+	high.SmallBlind, high.BigBlind = 20, 40
+	tables := map[string]*GameTable{
+		"low":  newBalancingTable(t, manager, "low", QuickGameSettings(), 1),
+		"mid":  newBalancingTable(t, manager, "mid", DefaultTableSettings(), 3),
+		"high": newBalancingTable(t, manager, "high", high, 2),
+	}
+	tables["high"].Tags = []string{"Deep"}
+	opened := time.Now().Add(-3 * time.Hour)
+	for i, name := range []string{"low", "mid", "high"} {
+		tables[name].CreatedAt = opened.Add(time.Duration(i) * time.Hour)
+	}
+	return manager, tables
+}
+
+func listedNames(page *TableListPage) []string {
+	names := make([]string, 0, len(page.Tables))
+	for _, table := range page.Tables {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+func TestListTablesPageSorts(t *testing.T) {
+	manager, _ := newListingTables(t)
+	list := func(sort TableListSort, descending bool) []string {
+		page, err := manager.ListTablesPage(TableListQuery{Sort: sort, Descending: descending})
+		require.NoError(t, err)
+		return listedNames(page)
+	}
+
+	assert.Equal(t, []string{"low", "mid", "high"}, list("", false), "in the order they opened by default")
+	assert.Equal(t, []string{"high", "mid", "low"}, list(TableSortCreated, true))
+	assert.Equal(t, []string{"mid", "high", "low"}, list(TableSortPlayers, true))
+	assert.Equal(t, []string{"low", "mid", "high"}, list(TableSortStakes, false))
+
+	_, err := manager.ListTablesPage(TableListQuery{Sort: "name"})
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_SORT", err.(*TableError).Code)
+}
+
+func TestListTablesPagePaginates(t *testing.T) {
+	manager, _ := newListingTables(t)
+
+	page, err := manager.ListTablesPage(TableListQuery{Page: 2, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"high"}, listedNames(page))
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 2, page.TotalPages)
+
+	page, err = manager.ListTablesPage(TableListQuery{Page: 3, Limit: 2})
+	require.NoError(t, err)
+	assert.Empty(t, page.Tables, "pages past the end are empty")
+
+	_, err = manager.ListTablesPage(TableListQuery{Limit: MaxTableListLimit + 1})
+	assert.Error(t, err)
+}
+
+func TestListTablesPageSearchesNamesAndTags(t *testing.T) {
+	manager, _ := newListingTables(t)
+	search := func(term string) []string {
+		page, err := manager.ListTablesPage(TableListQuery{Search: term})
+		require.NoError(t, err)
+		return listedNames(page)
+	}
+
+	assert.Equal(t, []string{"mid"}, search("MI"))
+	assert.Equal(t, []string{"high"}, search("deep"), "tags match too")
+	assert.Empty(t, search("turbo"))
+
+	page, err := manager.ListTablesPage(TableListQuery{Filters: map[string]interface{}{"created_by": "creator_low"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"low"}, listedNames(page), "ListTables filters still apply")
+}
+
+func TestTableListHandlerReturnsAPage(t *testing.T) {
+	manager, _ := newListingTables(t)
+	handler := NewTableWebSocketHandler(manager, &MockWebSocketHub{})
+	t.Cleanup(handler.events.Stop)
+	list := handler.GetMessageHandlers()["table_list"]
+
+	response := list(context.Background(), NewMockConnection("viewer", "Viewer"), &WebSocketMessage{
+		Type: "table_list", RequestID: "r1",
+		Data: map[string]interface{}{"sort": "stakes", "limit": float64(2), "game_type": string(GameTypeTexasHoldem)},
+	})
+	require.True(t, response.Success, response.Error)
+	data := response.Data.(map[string]interface{})
+	tables := data["tables"].([]map[string]interface{})
+	require.Len(t, tables, 2)
+	assert.Equal(t, "high", tables[0]["name"], "highest stakes first by default")
+	assert.Equal(t, 3, data["total"])
+	assert.Equal(t, 2, data["total_pages"])
+
+	response = list(context.Background(), NewMockConnection("viewer", "Viewer"), &WebSocketMessage{
+		Type: "table_list", RequestID: "r2", Data: map[string]interface{}{"order": "sideways"},
+	})
+	assert.False(t, response.Success)
+}
@@ -0,0 +1,39 @@
+package game
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tablePasswordCost is the bcrypt cost private table passwords are hashed at
+var tablePasswordCost = bcrypt.DefaultCost
+
+// hashTablePassword replaces the password a table was created with by its
+// bcrypt hash, so the plaintext is neither kept nor serialized
+func hashTablePassword(settings *TableSettings) error {
+	if settings.Password == "" {
+		return nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(settings.Password), tablePasswordCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash table password: %w", err)
+	}
+	settings.PasswordHash = string(hash)
+	settings.Password = ""
+	return nil
+}
+
+// HasPassword reports whether joining the table takes a password
+func (t *GameTable) HasPassword() bool {
+	return t.Settings.PasswordHash != ""
+}
+
+// CheckPassword reports whether the password is the table's. bcrypt compares
+// in constant time, so a wrong guess says nothing about how close it was.
+func (t *GameTable) CheckPassword(password string) bool {
+	if !t.HasPassword() {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(t.Settings.PasswordHash), []byte(password)) == nil
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateTableKeepsOnlyThePasswordHash(t *testing.T) {
+	manager, table, _ := newInvitationTable(t)
+
+	assert.Empty(t, table.Settings.Password, "the plaintext is not kept")
+	assert.True(t, table.HasPassword())
+	assert.NotEqual(t, "secret", table.Settings.PasswordHash)
+
+	require.Error(t, joinPrivate(manager, table.ID, "2", "Secret"))
+	require.Error(t, joinPrivate(manager, table.ID, "2", ""))
+	require.NoError(t, joinPrivate(manager, table.ID, "2", "secret"))
+}
+
+func TestTableInfoNeverShowsThePassword(t *testing.T) {
+	_, table, _ := newInvitationTable(t)
+	hash := table.Settings.PasswordHash
+
+	for name, value := range map[string]interface{}{
+		"table":         table,
+		"table info":    table.GetTableInfo(),
+		"detailed":      table.GetDetailedInfo(),
+		"filtered":      NewDataFilter().FilterTableInfo(table, "owner", "admin"),
+		"lobby listing": NewDataFilter().FilterTableList([]*GameTable{table}, "2"),
+	} {
+		data, err := json.Marshal(value)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret", name)
+		assert.NotContains(t, string(data), hash, name)
+	}
+}
+
+func TestRestoredPrivateTableKeepsItsPassword(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: "Private", GameType: GameTypeTexasHoldem, CreatedBy: "owner", Username: "owner",
+		Settings: PrivateTableSettings("secret"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, manager.SnapshotTables())
+	assert.Equal(t, table.Settings.PasswordHash, store.saved[table.ID].PasswordHash)
+
+	restarted := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(restarted.Stop)
+	_, err = restarted.RestoreTables(store)
+	require.NoError(t, err)
+
+	require.Error(t, joinPrivate(restarted, table.ID, "2", "wrong"))
+	require.NoError(t, joinPrivate(restarted, table.ID, "2", "secret"))
+}
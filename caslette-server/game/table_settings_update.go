@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TableSettingsUpdate is a change a table's creator makes to a waiting
+// table. Only the fields that are set change; stakes, buy-ins and seats are
+// fixed once the table opens.
+type TableSettingsUpdate struct {
+	Name             *string `json:"name,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	ObserversAllowed *bool   `json:"observers_allowed,omitempty"`
+	AutoStart        *bool   `json:"auto_start,omitempty"`
+	TimeLimit        *int    `json:"time_limit,omitempty"` // Turn time limit in seconds
+}
+
+// empty reports whether the update changes nothing
+func (u TableSettingsUpdate) empty() bool {
+	return u.Name == nil && u.Description == nil && u.ObserversAllowed == nil && u.AutoStart == nil && u.TimeLimit == nil
+}
+
+// ValidateSettingsUpdate checks a creator's settings update as the same
+// fields are checked when a table is created
+func (v *TableValidator) ValidateSettingsUpdate(update TableSettingsUpdate) error {
+	if update.empty() {
+		return fmt.Errorf("no settings to update")
+	}
+	if update.Name != nil {
+		if err := v.ValidateTableName(*update.Name); err != nil {
+			return fmt.Errorf("invalid table name: %w", err)
+		}
+	}
+	if update.Description != nil {
+		if err := v.ValidateDescription(*update.Description); err != nil {
+			return fmt.Errorf("invalid description: %w", err)
+		}
+	}
+	if update.TimeLimit != nil && (*update.TimeLimit < 0 || *update.TimeLimit > MaxTimeLimit) {
+		return fmt.Errorf("time limit out of range (0-%d seconds)", MaxTimeLimit)
+	}
+	return nil
+}
+
+// turnTimeLimiter is implemented by engines with a turn timer
+type turnTimeLimiter interface {
+	SetTurnTimeLimit(limit time.Duration)
+}
+
+// UpdateSettingsCommand applies a creator's settings update to a table that
+// is waiting for players
+type UpdateSettingsCommand struct {
+	PlayerID string
+	Update   TableSettingsUpdate
+	Response chan interface{}
+}
+
+func (cmd *UpdateSettingsCommand) Execute(table *GameTable) interface{} {
+	if table.CreatedBy != cmd.PlayerID {
+		return &TableError{"NOT_TABLE_CREATOR", "Only the table creator can change its settings"}
+	}
+	if table.Status != TableStatusWaiting {
+		return &TableError{"TABLE_NOT_WAITING", "Settings can only be changed while the table is waiting for players"}
+	}
+
+	update := cmd.Update
+	if update.Name != nil {
+		table.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Description != nil {
+		table.Description = *update.Description
+	}
+	if update.ObserversAllowed != nil {
+		table.Settings.ObserversAllowed = *update.ObserversAllowed
+	}
+	if update.AutoStart != nil {
+		table.Settings.AutoStart = *update.AutoStart
+	}
+	if update.TimeLimit != nil {
+		table.Settings.TimeLimit = *update.TimeLimit
+		if engine, ok := table.GameEngine.(turnTimeLimiter); ok {
+			engine.SetTurnTimeLimit(time.Duration(*update.TimeLimit) * time.Second)
+		}
+	}
+	table.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateSettings sends a settings update to the table actor
+func (ta *TableActor) UpdateSettings(ctx context.Context, playerID string, update TableSettingsUpdate) error {
+	cmd := &UpdateSettingsCommand{
+		PlayerID: playerID,
+		Update:   update,
+		Response: make(chan interface{}, 1),
+	}
+
+	select {
+	case ta.commands <- cmd:
+		// Command sent successfully
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case result := <-cmd.Response:
+		if err, ok := result.(*TableError); ok {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// UpdateTableSettings lets a table's creator rename it, describe it, open or
+// close it to new observers, switch auto-start and change the turn time
+// limit while it waits for players. Observers already watching stay when
+// observers are turned off.
+func (tm *ActorTableManager) UpdateTableSettings(ctx context.Context, tableID, playerID string, update TableSettingsUpdate) (*GameTable, error) {
+	if err := tm.validator.ValidateSettingsUpdate(update); err != nil {
+		return nil, &TableError{"INVALID_SETTINGS", err.Error()}
+	}
+
+	tm.mu.RLock()
+	actor, exists := tm.actors[tableID]
+	tm.mu.RUnlock()
+	if !exists {
+		return nil, ErrTableNotFound
+	}
+
+	if err := actor.UpdateSettings(ctx, playerID, update); err != nil {
+		return nil, err
+	}
+	tm.tableChanged(actor)
+	return actor.table, nil
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatorUpdatesWaitingTableSettings(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1")
+	name, timeLimit, observers := "Renamed game", 45, false
+
+	_, err := manager.UpdateTableSettings(context.Background(), table.ID, "p0", TableSettingsUpdate{Name: &name})
+	require.Error(t, err)
+	assert.Equal(t, "NOT_TABLE_CREATOR", err.(*TableError).Code)
+
+	updated, err := manager.UpdateTableSettings(context.Background(), table.ID, table.CreatedBy, TableSettingsUpdate{
+		Name: &name, TimeLimit: &timeLimit, ObserversAllowed: &observers,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed game", updated.Name)
+	assert.Equal(t, 45, updated.Settings.TimeLimit)
+	assert.False(t, updated.Settings.ObserversAllowed)
+	assert.Equal(t, DefaultTableSettings().AutoStart, updated.Settings.AutoStart, "fields left out stay")
+	assert.Equal(t, 45*time.Second, table.GameEngine.(*TexasHoldemEngine).turnLimit, "the engine's turn timer follows")
+
+	require.Error(t, manager.JoinTable(context.Background(), &TableJoinRequest{
+		TableID: table.ID, PlayerID: "watcher", Username: "watcher", Mode: JoinModeObserver,
+	}), "observers are turned away")
+
+	require.NoError(t, manager.tryStartGame(table))
+	_, err = manager.UpdateTableSettings(context.Background(), table.ID, table.CreatedBy, TableSettingsUpdate{Name: &name})
+	require.Error(t, err)
+	assert.Equal(t, "TABLE_NOT_WAITING", err.(*TableError).Code)
+}
+
+func TestUpdateTableSettingsValidates(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour)
+	update := func(update TableSettingsUpdate) error {
+		_, err := manager.UpdateTableSettings(context.Background(), table.ID, table.CreatedBy, update)
+		return err
+	}
+	blank, tooLong := "", MaxTimeLimit+1
+
+	assert.Error(t, update(TableSettingsUpdate{}), "nothing to change")
+	assert.Error(t, update(TableSettingsUpdate{Name: &blank}))
+	assert.Error(t, update(TableSettingsUpdate{TimeLimit: &tooLong}))
+	assert.Equal(t, "loop", table.Name)
+}
+
+func TestUpdateTableSettingsHandlerBroadcasts(t *testing.T) {
+	manager, table, _ := newHandLoopTable(t, time.Hour, "p0", "p1")
+	hub := &MockWebSocketHub{}
+	handler := NewTableWebSocketHandler(manager, hub)
+	t.Cleanup(handler.events.Stop)
+
+	response := handler.GetMessageHandlers()["table_update_settings"](context.Background(), NewMockConnection(table.CreatedBy, table.CreatedBy), &WebSocketMessage{
+		Type: "table_update_settings", RequestID: "r1",
+		Data: map[string]interface{}{"table_id": table.ID, "description": "Friendly game", "auto_start": false},
+	})
+	require.True(t, response.Success, response.Error)
+	assert.Equal(t, "Friendly game", table.Description)
+	assert.False(t, table.Settings.AutoStart)
+
+	require.Len(t, hub.broadcastCalls, 1)
+	assert.Equal(t, table.RoomID, hub.broadcastCalls[0].RoomID)
+	broadcast := hub.broadcastCalls[0].Message.(*WebSocketMessage)
+	assert.Equal(t, "table_settings_updated", broadcast.Type)
+	settings := broadcast.Data.(map[string]interface{})["settings"].(map[string]interface{})
+	assert.Equal(t, "Friendly game", settings["description"])
+	assert.Equal(t, false, settings["auto_start"])
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"context"
+)
+
+// tableSettingsRequest is a creator's settings update for a table
+type tableSettingsRequest struct {
+	TableID string `json:"table_id"`
+	TableSettingsUpdate
+}
+
+// handleUpdateTableSettings applies the creator's changes to a waiting table
+// and tells everyone at it the new configuration
+func (h *TableWebSocketHandler) handleUpdateTableSettings(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req tableSettingsRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	table, err := h.tableManager.UpdateTableSettings(ctx, req.TableID, conn.GetUserID(), req.TableSettingsUpdate)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "UPDATE_SETTINGS_FAILED", err.Error())
+	}
+
+	settings := map[string]interface{}{
+		"name":              table.Name,
+		"description":       table.Description,
+		"observers_allowed": table.Settings.ObserversAllowed,
+		"auto_start":        table.Settings.AutoStart,
+		"time_limit":        table.Settings.TimeLimit,
+	}
+	h.broadcastTableUpdate(table, "table_settings_updated", map[string]interface{}{
+		"table_id": table.ID,
+		"settings": settings,
+		"table":    table.GetDetailedInfo(),
+	})
+	return h.successResponse(msg.RequestID, "table_settings_updated", map[string]interface{}{
+		"table_id": table.ID,
+		"settings": settings,
+	})
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"strings"
+	"sync"
+)
+
+// TableTagIndex maps tags to the open tables carrying them, so a lobby
+// filtered by tag reads only those tables. Tags are matched ignoring case.
+type TableTagIndex struct {
+	mu     sync.RWMutex
+	tables map[string]map[string]bool // Lowercased tag -> table IDs
+}
+
+// NewTableTagIndex creates an empty tag index
+func NewTableTagIndex() *TableTagIndex {
+	return &TableTagIndex{tables: make(map[string]map[string]bool)}
+}
+
+// Add indexes an open table under each of its tags
+func (ti *TableTagIndex) Add(table *GameTable) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	for _, tag := range table.Tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if ti.tables[tag] == nil {
+			ti.tables[tag] = make(map[string]bool)
+		}
+		ti.tables[tag][table.ID] = true
+	}
+}
+
+// Remove drops a closed table from the index
+func (ti *TableTagIndex) Remove(table *GameTable) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	for _, tag := range table.Tags {
+		tag = normalizeTag(tag)
+		delete(ti.tables[tag], table.ID)
+		if len(ti.tables[tag]) == 0 {
+			delete(ti.tables, tag)
+		}
+	}
+}
+
+// Lookup returns the IDs of the tables carrying every one of the tags
+func (ti *TableTagIndex) Lookup(tags []string) []string {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	if len(tags) == 0 {
+		return nil
+	}
+
+	// Start from the rarest tag so the intersection stays small
+	var smallest map[string]bool
+	for _, tag := range tags {
+		tables := ti.tables[normalizeTag(tag)]
+		if len(tables) == 0 {
+			return nil
+		}
+		if smallest == nil || len(tables) < len(smallest) {
+			smallest = tables
+		}
+	}
+
+	matches := make([]string, 0, len(smallest))
+	for tableID := range smallest {
+		tagged := true
+		for _, tag := range tags {
+			if !ti.tables[normalizeTag(tag)][tableID] {
+				tagged = false
+				break
+			}
+		}
+		if tagged {
+			matches = append(matches, tableID)
+		}
+	}
+	return matches
+}
+
+// normalizeTag is the form tags are indexed and compared in
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// tagsFilter reads the "tags" lobby filter: a list of tags or a
+// comma-separated string. ok is false when the filter is absent or empty.
+func tagsFilter(filters map[string]interface{}) (tags []string, ok bool) {
+	switch value := filters["tags"].(type) {
+	case []string:
+		tags = value
+	case []interface{}:
+		for _, item := range value {
+			if tag, isString := item.(string); isString {
+				tags = append(tags, tag)
+			}
+		}
+	case string:
+		tags = strings.Split(value, ",")
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = normalizeTag(tag); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, len(normalized) > 0
+}
+
+// tablesTagged returns the open tables carrying every one of the tags
+func (tm *ActorTableManager) tablesTagged(tags []string) []*GameTable {
+	ids := tm.tags.Lookup(tags)
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	tables := make([]*GameTable, 0, len(ids))
+	for _, tableID := range ids {
+		if actor, open := tm.actors[tableID]; open {
+			tables = append(tables, actor.table)
+		}
+	}
+	return tables
+}
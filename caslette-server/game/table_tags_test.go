@@ -0,0 +1,84 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTaggedTable opens a table carrying tags, named after them
+func newTaggedTable(t *testing.T, manager *ActorTableManager, name string, tags ...string) *GameTable {
+	table, err := manager.CreateTable(context.Background(), &TableCreateRequest{
+		Name: name, GameType: GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator",
+		Settings: DefaultTableSettings(), Tags: tags,
+	})
+	require.NoError(t, err)
+	return table
+}
+
+func tableNames(tables []*GameTable) []string {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+func TestListTablesFiltersByEveryTag(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	newTaggedTable(t, manager, "Turbo Deep", "turbo", "deep")
+	newTaggedTable(t, manager, "Turbo Only", "Turbo")
+	newTaggedTable(t, manager, "Untagged")
+
+	list := func(tags interface{}) []string {
+		return tableNames(manager.ListTables(map[string]interface{}{"tags": tags}))
+	}
+	assert.ElementsMatch(t, []string{"Turbo Deep", "Turbo Only"}, list([]interface{}{"TURBO"}), "tags match ignoring case")
+	assert.Equal(t, []string{"Turbo Deep"}, list("turbo, deep"), "a table must carry every tag")
+	assert.Equal(t, []string{"Turbo Deep"}, list([]string{"deep"}))
+	assert.Empty(t, list("cash"))
+	assert.Len(t, list(""), 3, "an empty tag filter lists every table")
+}
+
+func TestListTablesFiltersByNameSubstring(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	newTaggedTable(t, manager, "Friday Night", "weekly")
+	newTaggedTable(t, manager, "Late Night")
+	newTaggedTable(t, manager, "Morning Coffee")
+
+	names := tableNames(manager.ListTables(map[string]interface{}{"name": "NIGHT"}))
+	assert.ElementsMatch(t, []string{"Friday Night", "Late Night"}, names)
+
+	names = tableNames(manager.ListTables(map[string]interface{}{"name": "night", "tags": "weekly"}))
+	assert.Equal(t, []string{"Friday Night"}, names)
+}
+
+func TestTagIndexForgetsClosedTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	table := newTaggedTable(t, manager, "Closing Soon", "deep")
+	assert.Equal(t, []string{table.ID}, manager.tags.Lookup([]string{"deep"}))
+
+	require.NoError(t, manager.CloseTable(table.ID))
+	assert.Empty(t, manager.tags.Lookup([]string{"deep"}))
+	assert.Empty(t, manager.ListTables(map[string]interface{}{"tags": "deep"}))
+}
+
+func TestRestoredTablesAreTagIndexed(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(manager.Stop)
+	store := newMemorySnapshots()
+	_, err := manager.RestoreTables(store)
+	require.NoError(t, err)
+	table := newTaggedTable(t, manager, "Deep Stacks", "deep")
+
+	restarted := NewActorTableManager(&TexasHoldemEngineFactory{})
+	t.Cleanup(restarted.Stop)
+	_, err = restarted.RestoreTables(store)
+	require.NoError(t, err)
+	assert.Equal(t, []string{table.ID}, restarted.tags.Lookup([]string{"Deep"}))
+}
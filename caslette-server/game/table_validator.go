@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -28,6 +29,8 @@ const (
 	MinBlind           = 1
 	MaxBlind           = 100000
 	MaxTimeLimit       = 300 // 5 minutes max per turn
+	MaxTimeBank        = 600 // 10 minutes of extra time per player
+	MaxObserverLimit   = 1000
 )
 
 var (
@@ -84,6 +87,9 @@ func (v *TableValidator) ValidateTableCreateRequest(req *TableCreateRequest) err
 	if err := v.ValidateTableSettings(req.Settings); err != nil {
 		return fmt.Errorf("invalid settings: %w", err)
 	}
+	if err := v.ValidateCapacity(req.GameType, req.Settings); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
 
 	return nil
 }
@@ -239,7 +245,7 @@ func (v *TableValidator) ValidateTableID(tableID string) error {
 // ValidateGameType validates game types
 func (v *TableValidator) ValidateGameType(gameType GameType) error {
 	switch gameType {
-	case GameTypeTexasHoldem:
+	case GameTypeTexasHoldem, GameTypeOmaha, GameTypeSevenCardStud:
 		return nil
 	default:
 		return fmt.Errorf("unsupported game type: %s", gameType)
@@ -317,6 +323,9 @@ func (v *TableValidator) ValidateTableSettings(settings TableSettings) error {
 	if settings.BigBlind <= settings.SmallBlind {
 		return fmt.Errorf("big blind must be greater than small blind")
 	}
+	if settings.Ante < 0 || settings.Ante > settings.BigBlind {
+		return fmt.Errorf("ante must be between 0 and the big blind")
+	}
 
 	// Validate buy-in
 	if settings.BuyIn < MinBuyIn || settings.BuyIn > MaxBuyIn {
@@ -327,10 +336,49 @@ func (v *TableValidator) ValidateTableSettings(settings TableSettings) error {
 		return fmt.Errorf("max buy-in must be greater than or equal to buy-in")
 	}
 
+	if settings.AutoRebuyBelow < 0 || settings.AutoRebuyBelow > max(settings.MaxBuyIn, settings.BuyIn) {
+		return fmt.Errorf("auto-rebuy threshold must be between 0 and the maximum buy-in")
+	}
+
+	for _, delay := range []int{settings.RunoutFlopDelayMs, settings.RunoutTurnDelayMs, settings.RunoutRiverDelayMs} {
+		if delay < 0 || time.Duration(delay)*time.Millisecond > MaxRunoutDelay {
+			return fmt.Errorf("runout delays must be between 0 and %d milliseconds", MaxRunoutDelay.Milliseconds())
+		}
+	}
+
+	if settings.MaxObservers < 0 || settings.MaxObservers > MaxObserverLimit {
+		return fmt.Errorf("max observers out of range (0-%d)", MaxObserverLimit)
+	}
+
+	if settings.SpectatorDelay < 0 || time.Duration(settings.SpectatorDelay)*time.Second > MaxSpectatorDelay {
+		return fmt.Errorf("spectator delay must be between 0 and %d seconds", int(MaxSpectatorDelay.Seconds()))
+	}
+
+	if settings.RakePercent < 0 || settings.RakePercent > MaxRakePercent {
+		return fmt.Errorf("rake must be between 0 and %g percent", MaxRakePercent)
+	}
+	if settings.MaxRake < 0 {
+		return fmt.Errorf("rake cap cannot be negative")
+	}
+
+	// Validate currency
+	switch settings.Currency {
+	case "", CurrencyDiamonds:
+	case CurrencyPlayMoney:
+		if settings.TournamentMode {
+			return fmt.Errorf("tournaments cannot be played for play money")
+		}
+	default:
+		return fmt.Errorf("unsupported currency: %s", v.SanitizeInput(string(settings.Currency)))
+	}
+
 	// Validate time limit
 	if settings.TimeLimit < 0 || settings.TimeLimit > MaxTimeLimit {
 		return fmt.Errorf("time limit out of range (0-%d seconds)", MaxTimeLimit)
 	}
+	if settings.TimeBank < 0 || settings.TimeBank > MaxTimeBank {
+		return fmt.Errorf("time bank out of range (0-%d seconds)", MaxTimeBank)
+	}
 
 	// Validate password
 	if settings.Password != "" {
@@ -358,8 +406,9 @@ func (v *TableValidator) ValidateJoinMode(mode TableJoinMode) error {
 
 // ValidatePosition validates player positions
 func (v *TableValidator) ValidatePosition(position int) error {
-	if position < 0 || position >= 10 { // Assuming max 10 players
-		return fmt.Errorf("position out of range (0-9)")
+	maxPlayers := CurrentGameDefaults().MaxPlayers
+	if position < 0 || position >= maxPlayers {
+		return fmt.Errorf("position out of range (0-%d)", maxPlayers-1)
 	}
 	return nil
 }
@@ -525,6 +574,7 @@ func (v *TableValidator) ValidateFilterRequest(filters map[string]interface{}) e
 		"min_buy_in":  true,
 		"max_buy_in":  true,
 		"tags":        true,
+		"name":        true,
 	}
 
 	for key := range filters {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 )
 
@@ -27,6 +28,12 @@ type WebSocketHub interface {
 type TableWebSocketHandler struct {
 	tableManager *ActorTableManager
 	hub          WebSocketHub
+	events       *EventCoalescer
+	lobby        *LobbyFeed
+	balancer     *SeatBalancer
+
+	tournamentsMu sync.RWMutex
+	tournaments   map[string]*Tournament
 }
 
 // NewTableWebSocketHandler creates a new table websocket handler
@@ -34,11 +41,22 @@ func NewTableWebSocketHandler(tableManager *ActorTableManager, hub WebSocketHub)
 	handler := &TableWebSocketHandler{
 		tableManager: tableManager,
 		hub:          hub,
+		events:       NewEventCoalescer(hub, DefaultCoalesceInterval),
+		lobby:        NewLobbyFeed(hub, DefaultLobbyFeedInterval),
+		balancer:     NewSeatBalancer(tableManager, hub),
+		tournaments:  make(map[string]*Tournament),
 	}
 
 	// Register as webhook handler for table events
 	tableManager.AddWebhookHandler(handler)
 
+	// Deliver game events to table rooms in coalesced frames
+	handler.events.Start()
+	tableManager.SetEventBroadcaster(handler.events)
+
+	// Push table changes to lobby subscribers
+	tableManager.AddTableListener(handler.lobby.Record)
+
 	return handler
 }
 
@@ -55,16 +73,39 @@ type WebSocketMessage struct {
 // GetMessageHandlers returns all table-related message handlers
 func (h *TableWebSocketHandler) GetMessageHandlers() map[string]func(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
 	return map[string]func(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage{
-		"table_create":         h.handleCreateTable,
-		"table_join":           h.handleJoinTable,
-		"table_leave":          h.handleLeaveTable,
-		"table_list":           h.handleListTables,
-		"table_get":            h.handleGetTable,
-		"table_close":          h.handleCloseTable,
-		"table_set_ready":      h.handleSetReady,
-		"table_start_game":     h.handleStartGame,
-		"table_get_stats":      h.handleGetStats,
-		"table_get_game_state": h.handleGetGameState,
+		"table_create":               h.handleCreateTable,
+		"table_join":                 h.handleJoinTable,
+		"table_leave":                h.handleLeaveTable,
+		"add_chips":                  h.handleAddChips,
+		"table_list":                 h.handleListTables,
+		"table_get_observers":        h.handleGetObservers,
+		"lobby_subscribe":            h.handleLobbySubscribe,
+		"lobby_unsubscribe":          h.handleLobbyUnsubscribe,
+		"table_get":                  h.handleGetTable,
+		"table_close":                h.handleCloseTable,
+		"table_set_ready":            h.handleSetReady,
+		"table_start_game":           h.handleStartGame,
+		"table_get_stats":            h.handleGetStats,
+		"table_get_game_state":       h.handleGetGameState,
+		"table_balance_accept":       h.handleBalanceAccept,
+		"table_chat":                 h.handleTableChat,
+		"table_chat_mute":            h.handleTableChatMute,
+		"table_invite":               h.handleTableInvite,
+		"table_change_seat":          h.handleChangeSeat,
+		"table_reserve_seat":         h.handleReserveSeat,
+		"table_update_settings":      h.handleUpdateTableSettings,
+		"tournament_register":        h.handleTournamentRegister,
+		"tournament_unregister":      h.handleTournamentUnregister,
+		"tournament_get":             h.handleGetTournament,
+		"tournament_spectate":        h.handleSpectateTournament,
+		"tournament_stop_spectating": h.handleStopSpectatingTournament,
+		"sit_and_go_create":          h.handleCreateSitAndGo,
+		"sit_and_go_list":            h.handleListSitAndGos,
+		"sit_and_go_join":            h.handleJoinSitAndGo,
+		"sit_and_go_leave":           h.handleLeaveSitAndGo,
+		"heads_up_list":              h.handleListHeadsUp,
+		"heads_up_join":              h.handleJoinHeadsUp,
+		"heads_up_leave":             h.handleLeaveHeadsUp,
 	}
 }
 
@@ -124,6 +165,11 @@ func (h *TableWebSocketHandler) handleJoinTable(ctx context.Context, conn WebSoc
 	// Get updated table info
 	table, _ := h.tableManager.GetTable(req.TableID)
 
+	// A seated player follows the hand live, not from the delayed observer room
+	if req.Mode == JoinModePlayer && table.SpectatorDelay() > 0 {
+		conn.LeaveRoom(table.ObserverRoomID())
+	}
+
 	return h.successResponse(msg.RequestID, "table_joined", map[string]interface{}{
 		"table": table.GetDetailedInfo(),
 		"mode":  req.Mode,
@@ -152,31 +198,121 @@ func (h *TableWebSocketHandler) handleLeaveTable(ctx context.Context, conn WebSo
 	}
 
 	log.Printf("Successfully left table %s", req.TableID)
+
+	// Someone who gave up a seat no longer follows the hand live
+	if table, err := h.tableManager.GetTable(req.TableID); err == nil && table.SpectatorDelay() > 0 {
+		conn.LeaveRoom(table.RoomID)
+	}
+
+	// A departure may leave this table short-handed
+	h.balancer.Evaluate()
+
 	return h.successResponse(msg.RequestID, "table_left", map[string]interface{}{
 		"table_id": req.TableID,
 	})
 }
 
-// handleListTables handles table listing requests
+// addChipsRequest names the table and how many chips to buy
+type addChipsRequest struct {
+	TableID string `json:"table_id"`
+	Amount  int    `json:"amount"`
+}
+
+// handleAddChips buys the caller more chips between hands
+func (h *TableWebSocketHandler) handleAddChips(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req addChipsRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	chips, err := h.tableManager.AddChips(ctx, req.TableID, conn.GetUserID(), req.Amount)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "ADD_CHIPS_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "chips_added", map[string]interface{}{
+		"table_id": req.TableID,
+		"amount":   req.Amount,
+		"chips":    chips,
+	})
+}
+
+// handleBalanceAccept moves a volunteer to the table named in a seat balance offer
+func (h *TableWebSocketHandler) handleBalanceAccept(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req struct {
+		OfferID string `json:"offer_id"`
+	}
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	table, err := h.balancer.Accept(ctx, req.OfferID, conn.GetUserID(), conn.GetUsername())
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "BALANCE_FAILED", err.Error())
+	}
+
+	return h.successResponse(msg.RequestID, "table_joined", map[string]interface{}{
+		"table": table.GetDetailedInfo(),
+		"mode":  JoinModePlayer,
+	})
+}
+
+// handleListTables handles table listing requests: one page of the tables
+// matching the optional filters and search, newest, busiest or highest
+// stakes first unless the order is asc
 func (h *TableWebSocketHandler) handleListTables(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
-	// Parse optional filters
-	filters := make(map[string]interface{})
+	var req struct {
+		Page   int    `json:"page"`
+		Limit  int    `json:"limit"`
+		Sort   string `json:"sort"`
+		Order  string `json:"order"`
+		Search string `json:"search"`
+	}
 	if msg.Data != nil {
-		if filterMap, ok := msg.Data.(map[string]interface{}); ok {
-			filters = filterMap
+		if err := h.parseMessageData(msg.Data, &req); err != nil {
+			return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
 		}
 	}
+	if req.Order != "" && req.Order != "asc" && req.Order != "desc" {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "order must be asc or desc")
+	}
 
-	// Get tables
-	tables := h.tableManager.ListTables(filters)
+	// Everything else in the request filters the tables
+	filters := make(map[string]interface{})
+	if filterMap, ok := msg.Data.(map[string]interface{}); ok {
+		for key, value := range filterMap {
+			switch key {
+			case "page", "limit", "sort", "order", "search":
+			default:
+				filters[key] = value
+			}
+		}
+	}
+
+	page, err := h.tableManager.ListTablesPage(TableListQuery{
+		Filters:    filters,
+		Search:     req.Search,
+		Sort:       TableListSort(req.Sort),
+		Descending: req.Order != "asc",
+		Page:       req.Page,
+		Limit:      req.Limit,
+	})
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", err.Error())
+	}
 
 	// Convert to public info
-	var tableList []map[string]interface{}
-	for _, table := range tables {
+	tableList := make([]map[string]interface{}, 0, len(page.Tables))
+	for _, table := range page.Tables {
 		tableList = append(tableList, table.GetTableInfo())
 	}
 
-	return h.successResponse(msg.RequestID, "table_list", tableList)
+	return h.successResponse(msg.RequestID, "table_list", map[string]interface{}{
+		"tables":      tableList,
+		"page":        page.Page,
+		"limit":       page.Limit,
+		"total":       page.Total,
+		"total_pages": page.TotalPages,
+	})
 }
 
 // handleGetTable handles get table info requests
@@ -206,6 +342,38 @@ func (h *TableWebSocketHandler) handleGetTable(ctx context.Context, conn WebSock
 	return h.successResponse(msg.RequestID, "table_info", tableInfo)
 }
 
+// handleGetObservers lists who is watching a table; private tables show
+// their observers only to the people at them
+func (h *TableWebSocketHandler) handleGetObservers(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	var req struct {
+		TableID string `json:"table_id"`
+	}
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+
+	table, err := h.tableManager.GetTable(req.TableID)
+	if err != nil {
+		return h.errorResponse(msg.RequestID, "TABLE_NOT_FOUND", err.Error())
+	}
+	filter := NewDataFilter()
+	playerID := conn.GetUserID()
+	if err := filter.ValidateTableAccess(table, playerID, "view"); err != nil {
+		return h.errorResponse(msg.RequestID, "ACCESS_DENIED", err.Error())
+	}
+
+	observers := filter.filterObservers(table.Observers, playerID, table.IsObserver(playerID))
+	if observers == nil {
+		observers = make([]map[string]interface{}, 0)
+	}
+	return h.successResponse(msg.RequestID, "table_observers", map[string]interface{}{
+		"table_id":      table.ID,
+		"observers":     observers,
+		"count":         len(observers),
+		"max_observers": table.Settings.MaxObservers,
+	})
+}
+
 // handleCloseTable handles table close requests
 func (h *TableWebSocketHandler) handleCloseTable(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
 	var req struct {
@@ -225,6 +393,9 @@ func (h *TableWebSocketHandler) handleCloseTable(ctx context.Context, conn WebSo
 	if table.CreatedBy != conn.GetUserID() {
 		return h.errorResponse(msg.RequestID, "NOT_AUTHORIZED", "Only table creator can close the table")
 	}
+	if table.IsFeatured() {
+		return h.errorResponse(msg.RequestID, "TABLE_FEATURED", "Featured tables can only be closed by an admin")
+	}
 
 	// Close table
 	if err := h.tableManager.CloseTable(req.TableID); err != nil {
@@ -360,6 +531,15 @@ func (h *TableWebSocketHandler) handleGetGameState(ctx context.Context, conn Web
 	}
 	log.Printf("Access granted for player %s to table %s", playerID, req.TableID)
 
+	// Observers of a delayed table would otherwise see the hand live; they
+	// catch up from the delayed frames instead
+	if table.SpectatorDelay() > 0 && !table.IsPlayerAtTable(playerID) && h.handLive(table) {
+		return h.successResponse(msg.RequestID, "game_state_response", map[string]interface{}{
+			"game_state":      nil,
+			"spectator_delay": table.Settings.SpectatorDelay,
+		})
+	}
+
 	// Get game state from engine
 	var gameState map[string]interface{}
 	if table.GameEngine != nil {
@@ -409,6 +589,7 @@ func (h *TableWebSocketHandler) OnTableCreated(table *GameTable) {
 
 // OnTableClosed broadcasts table closure event
 func (h *TableWebSocketHandler) OnTableClosed(table *GameTable) {
+	h.events.Forget(table.ID)
 	h.broadcastTableUpdate(table, "table_closed", map[string]interface{}{
 		"table_id": table.ID,
 		"reason":   "closed",
@@ -488,17 +669,20 @@ func (h *TableWebSocketHandler) errorResponse(requestID, code, message string) *
 	}
 }
 
-// broadcastTableUpdate broadcasts an update to all users in the table room
+// broadcastTableUpdate broadcasts an update to all users in the table's
+// rooms, observers included
 func (h *TableWebSocketHandler) broadcastTableUpdate(table *GameTable, eventType string, data interface{}) {
 	if h.hub != nil {
-		msg := &WebSocketMessage{
-			Type: eventType,
-			Data: data,
-			Room: table.RoomID,
-		}
+		for _, roomID := range table.Rooms() {
+			msg := &WebSocketMessage{
+				Type: eventType,
+				Data: data,
+				Room: roomID,
+			}
 
-		if err := h.hub.BroadcastToRoom(table.RoomID, msg); err != nil {
-			log.Printf("Failed to broadcast to room %s: %v", table.RoomID, err)
+			if err := h.hub.BroadcastToRoom(roomID, msg); err != nil {
+				log.Printf("Failed to broadcast to room %s: %v", roomID, err)
+			}
 		}
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // TexasHoldemState represents the current state of a Texas Hold'em game
@@ -41,6 +43,13 @@ type TexasHoldemPlayer struct {
 	HasFolded  bool  `json:"hasFolded"`
 	IsAllIn    bool  `json:"isAllIn"`
 	HasActed   bool  `json:"hasActed"`
+	SittingOut bool  `json:"sittingOut"` // Seated but not dealt in
+	OwesBlind  bool  `json:"owesBlind"`  // Missed the big blind sitting out; posts one on return
+}
+
+// BasePlayer returns the seated player this state belongs to
+func (p *TexasHoldemPlayer) BasePlayer() *Player {
+	return p.Player
 }
 
 // TexasHoldemEngine implements the Texas Hold'em poker game
@@ -50,29 +59,97 @@ type TexasHoldemEngine struct {
 	communityCards *Hand
 	pot            int
 	currentBet     int
-	dealerPos      int
-	smallBlindPos  int
-	bigBlindPos    int
+	dealerPos      int // Seat of the button, which may be empty when the button is dead
+	smallBlindPos  int // Seat posting the small blind, or noSeat when it is dead
+	bigBlindPos    int // Seat posting the big blind
+	smallBlindSeat int // Seat the small blind fell on, even when dead; the next button
+	handsDealt     int // Hands started, so the button moves from the second hand on
 	actionPos      int
+	lastRaise      int            // Size of the last full bet or raise this round, the minimum raise
+	fullRaises     int            // Full bets and raises this round; short all-ins do not count
+	actedAtRaise   map[string]int // Full raises each player had seen when they last acted this round
 	roundState     TexasHoldemState
 	smallBlind     int
 	bigBlind       int
+	ante           int
+	rakePercent    float64 // Share of each pot that sees a flop taken as rake
+	maxRake        int     // Most rake taken from one hand; zero for no cap
+	startingChips  int     // Stack for players added without chips
+	maxPlayers     int
 	evaluator      *PokerEvaluator
 	winners        []*TexasHoldemPlayer
+	showdownHands  map[string]*PokerHand // Best hand of each player reaching showdown
+	showChoices    map[string]bool       // Show (true) or muck (false) chosen for this hand's showdown
+	revealed       map[string]bool       // Players whose hole cards were shown this hand
+	lastAggressor  string                // Last player to bet or raise this round
+	hand           *HandRecord           // History of the hand being played
+	lastHand       *HandRecord           // History of the last hand completed
+	holeCardCount  int                   // Cards dealt to each player per hand
+	commitShuffle  bool                  // Publish each hand's shuffle commitment and reveal its seed
+	hud            bool                  // Give the player to act pot odds and all-in equity
+	equity         equityCache           // Last equity estimate, reused until the situation changes
+	bestHand       func(holeCards, board []Card) *PokerHand
+	variant        handVariant              // Deals and limits games other than hold'em; nil for hold'em and Omaha
+	actionMu       sync.Mutex               // Serializes player actions with turn timeouts
+	turnLimit      time.Duration            // Time a player has to act; zero disables the timer
+	turnTick       time.Duration            // Interval between countdown events
+	turn           *turnTimer               // Countdown for the player to act, guarded by actionMu
+	timeBank       time.Duration            // Extra time each player starts with once the turn limit runs out
+	timeBanks      map[string]time.Duration // Time bank left per player, guarded by actionMu
+	disconnected   map[string]bool          // Players whose connection dropped, guarded by actionMu
+	offlineLimit   time.Duration            // Most time a disconnected player has to act; zero means DisconnectedTurnLimit
+	runoutPacing   RunoutPacing             // Pauses between the streets of an all-in runout
+	runout         *runoutTimer             // Next street of a runout waiting to be dealt, guarded by actionMu
+
+	// Straddles, guarded by actionMu
+	straddleAllowed bool   // The player left of the big blind may straddle
+	straddler       string // Player straddling the next hand
+	straddleSeat    int    // Seat that straddled this hand, or noSeat
+}
+
+// handVariant deals a game other than hold'em on the hold'em betting, pots
+// and showdown: it posts the forced bets, deals each street, picks who acts
+// first on it and sets the betting limits
+type handVariant interface {
+	dealHand() error                                              // Forced bets and the first street
+	dealStreet() error                                            // The next street, or the showdown after the last
+	betLimits(player *TexasHoldemPlayer) RaiseRange               // Legal bet or raise for the player to act
+	checkBetSize(player *TexasHoldemPlayer, amount float64) error // Limits beyond the minimum bet or raise
+	checkAllIn(player *TexasHoldemPlayer) error
+	pastFirstStreet() bool // Whether the pots are raked
+}
+
+// ShowdownHand describes a player's evaluated hand in the showdown event so
+// clients can explain the result without their own evaluator
+type ShowdownHand struct {
+	PlayerID    string          `json:"playerId"`
+	HoleCards   []Card          `json:"holeCards"`
+	Rank        HandRank        `json:"rank"`
+	RankName    string          `json:"rankName"`
+	Description string          `json:"description"`
+	BestCards   []Card          `json:"bestCards"` // Five cards played, most significant first
+	Kickers     []Rank          `json:"kickers"`
+	IsWinner    bool            `json:"isWinner"`
+	Comparison  *HandComparison `json:"comparison,omitempty"` // Against the winning hand, for losing hands
 }
 
 // NewTexasHoldemEngine creates a new Texas Hold'em game engine
 func NewTexasHoldemEngine(gameID string) *TexasHoldemEngine {
 	base := NewBaseGameEngine(gameID)
+	defaults := CurrentGameDefaults()
 	return &TexasHoldemEngine{
 		BaseGameEngine: base,
 		deck:           NewDeck(),
 		communityCards: NewHand(),
 		roundState:     PreFlop,
-		smallBlind:     5,
-		bigBlind:       10,
+		smallBlind:     defaults.SmallBlind,
+		bigBlind:       defaults.BigBlind,
+		startingChips:  defaults.StartingChips,
+		maxPlayers:     defaults.MaxPlayers,
 		evaluator:      NewPokerEvaluator(),
 		winners:        make([]*TexasHoldemPlayer, 0),
+		holeCardCount:  2,
+		straddleSeat:   noSeat,
 	}
 }
 
@@ -95,27 +172,22 @@ func (the *TexasHoldemEngine) Initialize(config map[string]interface{}) error {
 
 // AddPlayer adds a player to the Texas Hold'em game
 func (the *TexasHoldemEngine) AddPlayer(player *Player) error {
-	if len(the.players) >= 10 {
-		return fmt.Errorf("maximum 10 players allowed")
+	if len(the.players) >= the.maxPlayers {
+		return fmt.Errorf("maximum %d players allowed", the.maxPlayers)
 	}
 
-	// Set default chips if not provided
-	if player.Data == nil {
-		player.Data = make(map[string]interface{})
+	// A chips entry in the player data seeds the stack. It is taken out so
+	// the typed state is the only copy.
+	chips := the.startingChips
+	if seeded, ok := player.Data["chips"].(int); ok {
+		chips = seeded
+		delete(player.Data, "chips")
 	}
-	if _, hasChips := player.Data["chips"]; !hasChips {
-		player.Data["chips"] = 1000
-	}
-
-	// Initialize poker-specific data
-	player.Data["hand"] = []Card{}
-	player.Data["currentBet"] = 0
-	player.Data["totalBet"] = 0
-	player.Data["hasFolded"] = false
-	player.Data["isAllIn"] = false
-	player.Data["hasActed"] = false
 
-	return the.BaseGameEngine.AddPlayer(player)
+	if err := the.BaseGameEngine.AddPlayer(player); err != nil {
+		return err
+	}
+	return the.SetPlayerState(&TexasHoldemPlayer{Player: player, Hand: NewHand(), Chips: chips})
 }
 
 // Start begins the Texas Hold'em game
@@ -123,6 +195,12 @@ func (the *TexasHoldemEngine) Start() error {
 	if len(the.players) < 2 {
 		return fmt.Errorf("need at least 2 players to start Texas Hold'em")
 	}
+	if the.playersToDeal() < 2 {
+		return fmt.Errorf("need at least 2 players with chips to deal a hand")
+	}
+
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
 
 	if err := the.BaseGameEngine.Start(); err != nil {
 		return err
@@ -134,6 +212,8 @@ func (the *TexasHoldemEngine) Start() error {
 
 // startNewHand begins a new hand of poker
 func (the *TexasHoldemEngine) startNewHand() error {
+	the.beginHandLog()
+
 	// Reset deck and shuffle
 	the.deck.Reset()
 	the.communityCards.Clear()
@@ -141,132 +221,134 @@ func (the *TexasHoldemEngine) startNewHand() error {
 	the.currentBet = 0
 	the.roundState = PreFlop
 	the.winners = the.winners[:0]
-
-	// Reset all players
+	the.showdownHands = nil
+	the.showChoices = nil
+	the.revealed = nil
+	the.lastAggressor = ""
+	the.straddleSeat = noSeat
+
+	// Reset all players; those without chips or sitting out skip the hand
+	stacks := make(map[string]int, len(the.players))
 	for _, player := range the.players {
 		holdemPlayer := the.getHoldemPlayer(player.ID)
 		if holdemPlayer != nil {
+			stacks[player.ID] = holdemPlayer.Chips
 			holdemPlayer.Hand.Clear()
 			holdemPlayer.CurrentBet = 0
 			holdemPlayer.TotalBet = 0
-			holdemPlayer.HasFolded = false
+			holdemPlayer.HasFolded = holdemPlayer.Chips <= 0 || holdemPlayer.SittingOut
+			holdemPlayer.IsActive = !holdemPlayer.HasFolded
 			holdemPlayer.IsAllIn = false
 			holdemPlayer.HasActed = false
 		}
 	}
 
-	// Set positions
-	the.setPositions()
-
-	// Post blinds
-	if err := the.postBlinds(); err != nil {
+	the.resetRaiseRules()
+	if the.variant != nil {
+		if err := the.variant.dealHand(); err != nil {
+			return err
+		}
+	} else if err := the.dealHoldemHand(); err != nil {
 		return err
 	}
+	the.beginHandRecord(stacks)
 
-	// Deal hole cards
-	if err := the.dealHoleCards(); err != nil {
-		return err
+	handStarted := map[string]interface{}{
+		"roundState":    the.roundState,
+		"dealerPos":     the.dealerPos,
+		"smallBlindPos": the.smallBlindPos,
+		"bigBlindPos":   the.bigBlindPos,
+		"pot":           the.pot,
+		"currentBet":    the.currentBet,
+	}
+	if the.commitShuffle {
+		handStarted["shuffleCommitment"] = the.deck.Commitment()
 	}
-
-	// Set action to left of big blind for preflop
-	the.actionPos = (the.bigBlindPos + 1) % len(the.getActivePlayers())
-
 	the.emitEvent(&GameEvent{
 		Type: "hand_started",
-		Data: map[string]interface{}{
-			"roundState":    the.roundState,
-			"dealerPos":     the.dealerPos,
-			"smallBlindPos": the.smallBlindPos,
-			"bigBlindPos":   the.bigBlindPos,
-			"pot":           the.pot,
-			"currentBet":    the.currentBet,
-		},
+		Data: handStarted,
 	})
 
+	// Blinds and antes can put everyone all in before anyone acts
+	if the.needsRunout() {
+		return the.beginRunout()
+	}
+	the.startTurnTimer()
 	return nil
 }
 
-// setPositions sets dealer, small blind, and big blind positions
-func (the *TexasHoldemEngine) setPositions() {
-	activePlayers := the.getActivePlayers()
-	numPlayers := len(activePlayers)
-
-	if numPlayers == 2 {
-		// Heads up: dealer is small blind
-		the.smallBlindPos = the.dealerPos
-		the.bigBlindPos = (the.dealerPos + 1) % numPlayers
-	} else {
-		// Multi-way: small blind is left of dealer
-		the.smallBlindPos = (the.dealerPos + 1) % numPlayers
-		the.bigBlindPos = (the.dealerPos + 2) % numPlayers
+// dealHoldemHand places the button, posts the blinds, deals the hole cards
+// and gives the action to the player left of the big blind, or of the
+// straddle
+func (the *TexasHoldemEngine) dealHoldemHand() error {
+	previousBigBlind, dealtBefore := the.bigBlindPos, the.handsDealt > 0
+	the.setPositions()
+	if dealtBefore {
+		the.markMissedBlinds(previousBigBlind)
+	}
+	if err := the.postBlinds(); err != nil {
+		return err
+	}
+	if err := the.dealHoleCards(); err != nil {
+		return err
 	}
+	the.actionPos = the.firstToActAfter(the.lastBlindSeat())
+	return nil
 }
 
-// postBlinds posts the small and big blinds
+// postBlinds collects the antes and posts the small and big blinds and any
+// straddle. No small blind is posted when it is dead.
 func (the *TexasHoldemEngine) postBlinds() error {
-	activePlayers := the.getActivePlayers()
-
-	// Post small blind
-	sbPlayer := the.getHoldemPlayer(activePlayers[the.smallBlindPos].ID)
-	if sbPlayer == nil {
-		return fmt.Errorf("small blind player not found")
-	}
-
-	sbAmount := min(the.smallBlind, sbPlayer.Chips)
-	sbPlayer.Chips -= sbAmount
-	sbPlayer.CurrentBet = sbAmount
-	sbPlayer.TotalBet = sbAmount
-	the.pot += sbAmount
-
-	if sbPlayer.Chips == 0 {
-		sbPlayer.IsAllIn = true
+	the.postAntes(the.getActivePlayers())
+
+	var smallBlind interface{}
+	sbAmount := 0
+	if the.smallBlindPos != noSeat {
+		sbPlayer := the.playerAtSeat(the.smallBlindPos)
+		if sbPlayer == nil {
+			return fmt.Errorf("small blind player not found")
+		}
+		sbAmount = the.postBlind(sbPlayer, the.smallBlind)
+		smallBlind = map[string]interface{}{
+			"playerID": sbPlayer.ID,
+			"amount":   sbAmount,
+		}
 	}
 
-	the.saveHoldemPlayer(sbPlayer)
-
-	// Post big blind
-	bbPlayer := the.getHoldemPlayer(activePlayers[the.bigBlindPos].ID)
+	bbPlayer := the.playerAtSeat(the.bigBlindPos)
 	if bbPlayer == nil {
 		return fmt.Errorf("big blind player not found")
 	}
-
-	bbAmount := min(the.bigBlind, bbPlayer.Chips)
-	bbPlayer.Chips -= bbAmount
-	bbPlayer.CurrentBet = bbAmount
-	bbPlayer.TotalBet = bbAmount
-	the.pot += bbAmount
-	the.currentBet = bbAmount
-
-	if bbPlayer.Chips == 0 {
-		bbPlayer.IsAllIn = true
-	}
-
-	the.saveHoldemPlayer(bbPlayer)
+	bbAmount := the.postBlind(bbPlayer, the.bigBlind)
+	// A big blind left short by the ante still leaves the small blind to call
+	the.currentBet = max(bbAmount, sbAmount)
+	straddle := the.postStraddle()
+	returning := the.postReturningBlinds()
 
 	the.emitEvent(&GameEvent{
 		Type: "blinds_posted",
 		Data: map[string]interface{}{
-			"smallBlind": map[string]interface{}{
-				"playerID": sbPlayer.ID,
-				"amount":   sbAmount,
-			},
+			"smallBlind": smallBlind,
 			"bigBlind": map[string]interface{}{
 				"playerID": bbPlayer.ID,
 				"amount":   bbAmount,
 			},
-			"pot": the.pot,
+			"straddle":       straddle,
+			"deadSmallBlind": the.smallBlindPos == noSeat,
+			"returning":      returning,
+			"pot":            the.pot,
 		},
 	})
 
 	return nil
 }
 
-// dealHoleCards deals 2 cards to each player
+// dealHoleCards deals each player their hole cards, one at a time around
+// the table
 func (the *TexasHoldemEngine) dealHoleCards() error {
 	activePlayers := the.getActivePlayers()
 
-	// Deal 2 cards to each player
-	for i := 0; i < 2; i++ {
+	for i := 0; i < the.holeCardCount; i++ {
 		for _, player := range activePlayers {
 			holdemPlayer := the.getHoldemPlayer(player.ID)
 			if holdemPlayer == nil {
@@ -279,14 +361,14 @@ func (the *TexasHoldemEngine) dealHoleCards() error {
 			}
 
 			holdemPlayer.Hand.AddCard(card)
-			the.saveHoldemPlayer(holdemPlayer)
 		}
 	}
 
 	the.emitEvent(&GameEvent{
 		Type: "hole_cards_dealt",
 		Data: map[string]interface{}{
-			"playersCount": len(activePlayers),
+			"playersCount":   len(activePlayers),
+			"cardsPerPlayer": the.holeCardCount,
 		},
 	})
 
@@ -295,6 +377,17 @@ func (the *TexasHoldemEngine) dealHoleCards() error {
 
 // ProcessAction processes a player action
 func (the *TexasHoldemEngine) ProcessAction(ctx context.Context, action *GameAction) (*GameEvent, error) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if isShowCards(action) {
+		return the.processShowCards(action)
+	}
+	return the.processAction(ctx, action)
+}
+
+// processAction applies a validated action and starts the next player's
+// turn timer; the caller must hold actionMu
+func (the *TexasHoldemEngine) processAction(ctx context.Context, action *GameAction) (*GameEvent, error) {
 	if err := the.IsValidAction(action); err != nil {
 		return nil, err
 	}
@@ -304,16 +397,22 @@ func (the *TexasHoldemEngine) ProcessAction(ctx context.Context, action *GameAct
 		return nil, fmt.Errorf("player not found")
 	}
 
+	// Amounts beyond the stack are taken as all in
 	actionType := action.Data["action"].(string)
 	amount := 0
 	if val, ok := action.Data["amount"].(float64); ok {
-		amount = int(val)
+		amount = player.Chips
+		if val < float64(player.Chips) {
+			amount = int(val)
+		}
 	} else if val, ok := action.Data["amount"].(int); ok {
-		amount = val
+		amount = min(val, player.Chips)
 	}
 
 	var event *GameEvent
 	var err error
+	previousBet := the.currentBet
+	invested := player.TotalBet
 
 	switch TexasHoldemAction(actionType) {
 	case ActionFold:
@@ -335,9 +434,28 @@ func (the *TexasHoldemEngine) ProcessAction(ctx context.Context, action *GameAct
 	if err != nil {
 		return nil, err
 	}
+	if TexasHoldemAction(actionType) != ActionFold {
+		the.recordHandAction(player.ID, actionType, player.TotalBet-invested)
+	}
 
 	player.HasActed = true
-	the.saveHoldemPlayer(player)
+	the.markActed(player.ID)
+	if the.currentBet > previousBet {
+		the.lastAggressor = player.ID
+	}
+
+	// Emit before advancing so the action precedes any cards it triggers
+	if event.Sequence == 0 {
+		the.emitEvent(event)
+	}
+
+	// Once nobody is left to bet, the rest of the board is run out
+	if the.needsRunout() {
+		if err := the.beginRunout(); err != nil {
+			return nil, err
+		}
+		return event, nil
+	}
 
 	// Check if betting round is complete
 	if the.isBettingRoundComplete() {
@@ -349,15 +467,17 @@ func (the *TexasHoldemEngine) ProcessAction(ctx context.Context, action *GameAct
 		the.nextPlayer()
 	}
 
+	the.startTurnTimer()
 	return event, nil
 }
 
 // Helper methods for processing specific actions
 
 func (the *TexasHoldemEngine) processFold(player *TexasHoldemPlayer) (*GameEvent, error) {
+	// Recorded first, since the fold may end the hand
+	the.recordHandAction(player.ID, string(ActionFold), 0)
 	player.HasFolded = true
 	player.IsActive = false
-	the.saveHoldemPlayer(player)
 
 	event := &GameEvent{
 		Type:     "player_folded",
@@ -366,6 +486,8 @@ func (the *TexasHoldemEngine) processFold(player *TexasHoldemPlayer) (*GameEvent
 			"playerID": player.ID,
 		},
 	}
+	// Emitted here so the fold precedes the pot distribution it may trigger
+	the.emitEvent(event)
 
 	// Check if only one player remains
 	activePlayers := the.getActivePlayers()
@@ -391,8 +513,6 @@ func (the *TexasHoldemEngine) processCall(player *TexasHoldemPlayer) (*GameEvent
 		player.IsAllIn = true
 	}
 
-	the.saveHoldemPlayer(player)
-
 	return &GameEvent{
 		Type:     "player_called",
 		PlayerID: player.ID,
@@ -405,6 +525,7 @@ func (the *TexasHoldemEngine) processCall(player *TexasHoldemPlayer) (*GameEvent
 }
 
 func (the *TexasHoldemEngine) processRaise(player *TexasHoldemPlayer, amount int) (*GameEvent, error) {
+	previousBet := the.currentBet
 	totalBet := the.currentBet + amount
 	actualAmount := min(totalBet-player.CurrentBet, player.Chips)
 
@@ -413,6 +534,7 @@ func (the *TexasHoldemEngine) processRaise(player *TexasHoldemPlayer, amount int
 	player.TotalBet += actualAmount
 	the.pot += actualAmount
 	the.currentBet = player.CurrentBet
+	the.recordRaise(previousBet)
 
 	if player.Chips == 0 {
 		player.IsAllIn = true
@@ -423,12 +545,9 @@ func (the *TexasHoldemEngine) processRaise(player *TexasHoldemPlayer, amount int
 		holdemPlayer := the.getHoldemPlayer(p.ID)
 		if holdemPlayer != nil && holdemPlayer.ID != player.ID && !holdemPlayer.HasFolded && !holdemPlayer.IsAllIn {
 			holdemPlayer.HasActed = false
-			the.saveHoldemPlayer(holdemPlayer)
 		}
 	}
 
-	the.saveHoldemPlayer(player)
-
 	return &GameEvent{
 		Type:     "player_raised",
 		PlayerID: player.ID,
@@ -449,13 +568,12 @@ func (the *TexasHoldemEngine) processBet(player *TexasHoldemPlayer, amount int)
 	player.TotalBet += actualAmount
 	the.pot += actualAmount
 	the.currentBet = actualAmount
+	the.recordRaise(0)
 
 	if player.Chips == 0 {
 		player.IsAllIn = true
 	}
 
-	the.saveHoldemPlayer(player)
-
 	return &GameEvent{
 		Type:     "player_bet",
 		PlayerID: player.ID,
@@ -468,7 +586,6 @@ func (the *TexasHoldemEngine) processBet(player *TexasHoldemPlayer, amount int)
 }
 
 func (the *TexasHoldemEngine) processCheck(player *TexasHoldemPlayer) (*GameEvent, error) {
-	the.saveHoldemPlayer(player)
 
 	return &GameEvent{
 		Type:     "player_checked",
@@ -488,19 +605,18 @@ func (the *TexasHoldemEngine) processAllIn(player *TexasHoldemPlayer) (*GameEven
 	the.pot += amount
 
 	if player.CurrentBet > the.currentBet {
+		previousBet := the.currentBet
 		the.currentBet = player.CurrentBet
+		the.recordRaise(previousBet)
 		// Reset HasActed for all other players
 		for _, p := range the.players {
 			holdemPlayer := the.getHoldemPlayer(p.ID)
 			if holdemPlayer != nil && holdemPlayer.ID != player.ID && !holdemPlayer.HasFolded && !holdemPlayer.IsAllIn {
 				holdemPlayer.HasActed = false
-				the.saveHoldemPlayer(holdemPlayer)
 			}
 		}
 	}
 
-	the.saveHoldemPlayer(player)
-
 	return &GameEvent{
 		Type:     "player_all_in",
 		PlayerID: player.ID,
@@ -514,6 +630,9 @@ func (the *TexasHoldemEngine) processAllIn(player *TexasHoldemPlayer) (*GameEven
 
 // IsValidAction checks if an action is valid
 func (the *TexasHoldemEngine) IsValidAction(action *GameAction) error {
+	if action.Data != nil && isShowCards(action) {
+		return the.validateShowCards(action)
+	}
 	if the.GetState() != GameStateInProgress {
 		return fmt.Errorf("game is not in progress")
 	}
@@ -627,18 +746,24 @@ func (the *TexasHoldemEngine) IsValidAction(action *GameAction) error {
 		if !ok {
 			return fmt.Errorf("raise amount is required")
 		}
+		if the.currentBet == 0 {
+			return fmt.Errorf("cannot raise when there is no bet, use bet")
+		}
 		// Validate amount is a valid number type
-		if raiseAmount, ok := amount.(float64); ok {
-			if raiseAmount <= 0 {
-				return fmt.Errorf("raise amount must be positive")
-			}
-		} else if raiseAmount, ok := amount.(int); ok {
-			if raiseAmount <= 0 {
-				return fmt.Errorf("raise amount must be positive")
-			}
+		var raiseAmount float64
+		if value, ok := amount.(float64); ok {
+			raiseAmount = value
+		} else if value, ok := amount.(int); ok {
+			raiseAmount = float64(value)
 		} else {
 			return fmt.Errorf("raise amount must be a number")
 		}
+		if raiseAmount <= 0 {
+			return fmt.Errorf("raise amount must be positive")
+		}
+		if err := the.validateRaise(player, raiseAmount); err != nil {
+			return err
+		}
 		// Validate no conflicting action type data
 		if bet, exists := action.Data["bet"]; exists {
 			return fmt.Errorf("raise action should not contain bet data: %v", bet)
@@ -652,17 +777,20 @@ func (the *TexasHoldemEngine) IsValidAction(action *GameAction) error {
 			return fmt.Errorf("bet amount is required")
 		}
 		// Validate amount is a valid number type
-		if betAmount, ok := amount.(float64); ok {
-			if betAmount <= 0 {
-				return fmt.Errorf("bet amount must be positive")
-			}
-		} else if betAmount, ok := amount.(int); ok {
-			if betAmount <= 0 {
-				return fmt.Errorf("bet amount must be positive")
-			}
+		var betAmount float64
+		if value, ok := amount.(float64); ok {
+			betAmount = value
+		} else if value, ok := amount.(int); ok {
+			betAmount = float64(value)
 		} else {
 			return fmt.Errorf("bet amount must be a number")
 		}
+		if betAmount <= 0 {
+			return fmt.Errorf("bet amount must be positive")
+		}
+		if err := the.validateRaise(player, betAmount); err != nil {
+			return err
+		}
 		// Validate no conflicting action type data
 		if raise, exists := action.Data["raise"]; exists {
 			return fmt.Errorf("bet action should not contain raise data: %v", raise)
@@ -683,6 +811,9 @@ func (the *TexasHoldemEngine) IsValidAction(action *GameAction) error {
 		if amount, exists := action.Data["amount"]; exists {
 			return fmt.Errorf("all-in action should not contain amount data: %v", amount)
 		}
+		if the.variant != nil {
+			return the.variant.checkAllIn(player)
+		}
 	default:
 		return fmt.Errorf("invalid action type: %s", actionType)
 	}
@@ -712,8 +843,8 @@ func (the *TexasHoldemEngine) GetValidActions(playerID string) []string {
 		if player.Chips >= (the.currentBet - player.CurrentBet) {
 			actions = append(actions, string(ActionCall))
 		}
-		// Player can raise
-		if player.Chips > (the.currentBet - player.CurrentBet) {
+		// Player can raise unless a short all-in left the betting closed to them
+		if player.Chips > (the.currentBet-player.CurrentBet) && the.raiseReopened(playerID) {
 			actions = append(actions, string(ActionRaise))
 		}
 	} else {
@@ -727,71 +858,50 @@ func (the *TexasHoldemEngine) GetValidActions(playerID string) []string {
 	return actions
 }
 
-// Helper methods
-
-func (the *TexasHoldemEngine) getHoldemPlayer(playerID string) *TexasHoldemPlayer {
-	player, err := the.GetPlayer(playerID)
-	if err != nil {
-		return nil
-	}
-
-	// Convert to TexasHoldemPlayer
-	holdemPlayer := &TexasHoldemPlayer{
-		Player: player,
-		Hand:   NewHand(),
-	}
+// holdemPlayerView is the public part of a player's state: everything but
+// the hole cards
+type holdemPlayerView struct {
+	*Player
+	Chips      int  `json:"chips"`
+	CurrentBet int  `json:"currentBet"`
+	TotalBet   int  `json:"totalBet"`
+	HasFolded  bool `json:"hasFolded"`
+	IsAllIn    bool `json:"isAllIn"`
+	HasActed   bool `json:"hasActed"`
+	SittingOut bool `json:"sittingOut"`
+}
 
-	// Load poker-specific data from player.Data
-	if player.Data != nil {
-		if chips, ok := player.Data["chips"].(int); ok {
-			holdemPlayer.Chips = chips
-		} else {
-			holdemPlayer.Chips = 1000
-		}
-		if currentBet, ok := player.Data["currentBet"].(int); ok {
-			holdemPlayer.CurrentBet = currentBet
-		}
-		if totalBet, ok := player.Data["totalBet"].(int); ok {
-			holdemPlayer.TotalBet = totalBet
-		}
-		if hasFolded, ok := player.Data["hasFolded"].(bool); ok {
-			holdemPlayer.HasFolded = hasFolded
-		}
-		if isAllIn, ok := player.Data["isAllIn"].(bool); ok {
-			holdemPlayer.IsAllIn = isAllIn
-		}
-		if hasActed, ok := player.Data["hasActed"].(bool); ok {
-			holdemPlayer.HasActed = hasActed
-		}
-		if handData, ok := player.Data["hand"].([]Card); ok {
-			holdemPlayer.Hand.Cards = handData
+// GetGameState returns the game state with each player's public poker state,
+// in seat order
+func (the *TexasHoldemEngine) GetGameState() map[string]interface{} {
+	state := the.BaseGameEngine.GetGameState()
+	players := make([]holdemPlayerView, 0, len(the.players))
+	for _, player := range the.players {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		if holdemPlayer == nil {
+			continue
 		}
-	} else {
-		holdemPlayer.Chips = 1000
-	}
-
-	return holdemPlayer
+		players = append(players, holdemPlayerView{
+			Player:     holdemPlayer.Player,
+			Chips:      holdemPlayer.Chips,
+			CurrentBet: holdemPlayer.CurrentBet,
+			TotalBet:   holdemPlayer.TotalBet,
+			HasFolded:  holdemPlayer.HasFolded,
+			IsAllIn:    holdemPlayer.IsAllIn,
+			HasActed:   holdemPlayer.HasActed,
+			SittingOut: holdemPlayer.SittingOut,
+		})
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Position < players[j].Position })
+	state["players"] = players
+	return state
 }
 
-// saveHoldemPlayer saves the holdem player data back to the base player
-func (the *TexasHoldemEngine) saveHoldemPlayer(holdemPlayer *TexasHoldemPlayer) {
-	player, err := the.GetPlayer(holdemPlayer.ID)
-	if err != nil {
-		return
-	}
-
-	if player.Data == nil {
-		player.Data = make(map[string]interface{})
-	}
+// Helper methods
 
-	player.Data["chips"] = holdemPlayer.Chips
-	player.Data["currentBet"] = holdemPlayer.CurrentBet
-	player.Data["totalBet"] = holdemPlayer.TotalBet
-	player.Data["hasFolded"] = holdemPlayer.HasFolded
-	player.Data["isAllIn"] = holdemPlayer.IsAllIn
-	player.Data["hasActed"] = holdemPlayer.HasActed
-	player.Data["hand"] = holdemPlayer.Hand.Cards
-	player.IsActive = !holdemPlayer.HasFolded
+func (the *TexasHoldemEngine) getHoldemPlayer(playerID string) *TexasHoldemPlayer {
+	holdemPlayer, _ := the.PlayerState(playerID).(*TexasHoldemPlayer)
+	return holdemPlayer
 }
 
 func (the *TexasHoldemEngine) getActivePlayers() []*Player {
@@ -857,10 +967,16 @@ func (the *TexasHoldemEngine) nextBettingRound() error {
 		if holdemPlayer != nil {
 			holdemPlayer.CurrentBet = 0
 			holdemPlayer.HasActed = false
-			the.saveHoldemPlayer(holdemPlayer)
 		}
 	}
 	the.currentBet = 0
+	the.resetRaiseRules()
+	if the.roundState != River && the.roundState != SeventhStreet {
+		the.lastAggressor = "" // The last street's aggressor shows first at showdown
+	}
+	if the.variant != nil {
+		return the.variant.dealStreet()
+	}
 
 	switch the.roundState {
 	case PreFlop:
@@ -890,7 +1006,7 @@ func (the *TexasHoldemEngine) dealFlop() error {
 	}
 
 	the.roundState = Flop
-	the.actionPos = the.smallBlindPos
+	the.actionPos = the.firstToActAfter(the.dealerPos)
 
 	the.emitEvent(&GameEvent{
 		Type: "flop_dealt",
@@ -914,7 +1030,7 @@ func (the *TexasHoldemEngine) dealTurn() error {
 	the.communityCards.AddCard(card)
 
 	the.roundState = Turn
-	the.actionPos = the.smallBlindPos
+	the.actionPos = the.firstToActAfter(the.dealerPos)
 
 	the.emitEvent(&GameEvent{
 		Type: "turn_dealt",
@@ -938,7 +1054,7 @@ func (the *TexasHoldemEngine) dealRiver() error {
 	the.communityCards.AddCard(card)
 
 	the.roundState = River
-	the.actionPos = the.smallBlindPos
+	the.actionPos = the.firstToActAfter(the.dealerPos)
 
 	the.emitEvent(&GameEvent{
 		Type: "river_dealt",
@@ -956,12 +1072,23 @@ func (the *TexasHoldemEngine) showdown() error {
 	the.distributePot()
 	the.SetState(GameStateFinished)
 
+	hands, explanation := the.describeShowdown()
+	data := map[string]interface{}{
+		"winners":        the.winnerIDs(),
+		"communityCards": the.communityCards.Cards,
+		"hands":          hands,
+		"mucked":         the.muckedHands(),
+		"explanation":    explanation,
+	}
+	if len(the.winners) > 0 && the.revealed[the.winners[0].ID] {
+		if winningHand := the.showdownHands[the.winners[0].ID]; winningHand != nil {
+			data["winningHand"] = winningHand.Description()
+		}
+	}
+
 	the.emitEvent(&GameEvent{
 		Type: "showdown",
-		Data: map[string]interface{}{
-			"winners":        the.winners,
-			"communityCards": the.communityCards.Cards,
-		},
+		Data: data,
 	})
 
 	return nil
@@ -978,14 +1105,7 @@ func (the *TexasHoldemEngine) determineWinners() {
 			continue
 		}
 
-		// Combine hole cards with community cards
-		allCards := make([]Card, 0, 7)
-		allCards = append(allCards, holdemPlayer.Hand.Cards...)
-		allCards = append(allCards, the.communityCards.Cards...)
-
-		// Find best 5-card hand
-		bestHand := the.evaluator.FindBestHand(allCards)
-		playerHands[player.ID] = bestHand
+		playerHands[player.ID] = the.evaluateBestHand(holdemPlayer.Hand.Cards, the.communityCards.Cards)
 	}
 
 	// Find winners
@@ -1002,26 +1122,324 @@ func (the *TexasHoldemEngine) determineWinners() {
 	}
 
 	the.winners = winners
+	the.showdownHands = playerHands
+}
+
+// evaluateBestHand finds a player's best five-card hand. Hold'em plays any
+// five of the hole and community cards; variants override it with their own rule.
+func (the *TexasHoldemEngine) evaluateBestHand(holeCards, board []Card) *PokerHand {
+	if the.bestHand != nil {
+		return the.bestHand(holeCards, board)
+	}
+
+	allCards := make([]Card, 0, len(holeCards)+len(board))
+	allCards = append(allCards, holeCards...)
+	allCards = append(allCards, board...)
+	return the.evaluator.FindBestHand(allCards)
+}
+
+// describeShowdown lists the shown hands in seat order and explains why the
+// winning hand beat the best losing hand shown. Mucked hands are left out.
+func (the *TexasHoldemEngine) describeShowdown() ([]ShowdownHand, string) {
+	if len(the.winners) == 0 {
+		return []ShowdownHand{}, ""
+	}
+	winningHand := the.showdownHands[the.winners[0].ID]
+	isWinner := make(map[string]bool, len(the.winners))
+	for _, winner := range the.winners {
+		isWinner[winner.ID] = true
+	}
+
+	hands := make([]ShowdownHand, 0, len(the.showdownHands))
+	var runnerUp *PokerHand
+	for _, player := range the.getActivePlayers() {
+		hand, ok := the.showdownHands[player.ID]
+		if !ok || !the.revealed[player.ID] {
+			continue
+		}
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		entry := ShowdownHand{
+			PlayerID:    player.ID,
+			HoleCards:   holdemPlayer.Hand.Cards,
+			Rank:        hand.Rank,
+			RankName:    hand.Rank.String(),
+			Description: hand.Description(),
+			BestCards:   hand.OrderedCards(),
+			Kickers:     hand.Kickers,
+			IsWinner:    isWinner[player.ID],
+		}
+		if !entry.IsWinner && winningHand != nil {
+			entry.Comparison = hand.Explain(winningHand)
+			if runnerUp == nil || hand.Compare(runnerUp) > 0 {
+				runnerUp = hand
+			}
+		}
+		hands = append(hands, entry)
+	}
+
+	if winningHand == nil {
+		return hands, ""
+	}
+	if runnerUp == nil {
+		if len(the.winners) > 1 {
+			return hands, fmt.Sprintf("Split pot: %s", lowerFirst(winningHand.Description()))
+		}
+		return hands, winningHand.Description()
+	}
+	explanation := winningHand.Explain(runnerUp).Explanation
+	if len(the.winners) > 1 {
+		explanation = "Split pot: " + lowerFirst(explanation)
+	}
+	return hands, explanation
 }
 
+// distributePot splits the pot into a main pot and side pots and awards each
+// to the best eligible hand, so an all-in player only wins what they could
+// match. Rake comes out of the pots first. Each pot is announced with a
+// pot_awarded event.
 func (the *TexasHoldemEngine) distributePot() {
 	if len(the.winners) == 0 {
 		return
 	}
 
-	potPerWinner := the.pot / len(the.winners)
-	for _, winner := range the.winners {
-		winner.Chips += potPerWinner
+	contributions := make([]potContribution, 0, len(the.players))
+	for _, player := range the.players {
+		if holdemPlayer := the.getHoldemPlayer(player.ID); holdemPlayer != nil {
+			contributions = append(contributions, potContribution{
+				PlayerID: player.ID,
+				Position: player.Position,
+				Amount:   holdemPlayer.TotalBet,
+				Folded:   holdemPlayer.HasFolded,
+			})
+		}
+	}
+	pots := buildPots(contributions, the.pot)
+	rake := the.takeRake(pots, contributions)
+
+	collected := make(map[string]int, len(the.winners))
+	split := make(map[string]bool)
+	contested := make(map[string]bool) // Winners of a pot someone else could have won
+
+	// Live players decide pots none of their eligible players can take
+	live := make([]string, 0, len(contributions))
+	for _, contribution := range contributions {
+		if !contribution.Folded {
+			live = append(live, contribution.PlayerID)
+		}
 	}
 
+	for i := range pots {
+		pot := &pots[i]
+		winners := the.potWinners(pot.Eligible)
+		if len(winners) == 0 {
+			// Chips never vanish: an orphaned pot goes to the best remaining hand
+			winners = the.potWinners(live)
+		}
+		if len(winners) == 0 {
+			winners = the.winners
+		}
+
+		pot.Share = pot.Amount / len(winners)
+		pot.OddChips = pot.Amount % len(winners)
+
+		// Odd chips from a split go to the winner in the earliest seat
+		first := winners[0]
+		for _, winner := range winners[1:] {
+			if winner.Position < first.Position {
+				first = winner
+			}
+		}
+
+		for _, winner := range winners {
+			winner.Chips += pot.Share
+			collected[winner.ID] += pot.Share
+			if winner.ID == first.ID {
+				winner.Chips += pot.OddChips
+				collected[winner.ID] += pot.OddChips
+			}
+			if len(winners) > 1 {
+				split[winner.ID] = true
+			}
+			if len(pot.Eligible) > 1 {
+				contested[winner.ID] = true
+			}
+			pot.Winners = append(pot.Winners, winner.ID)
+		}
+
+		awarded := map[string]interface{}{
+			"pot":      pot.Name,
+			"index":    i,
+			"amount":   pot.Amount,
+			"eligible": pot.Eligible,
+			"winners":  pot.Winners,
+			"share":    pot.Share,
+			"oddChips": pot.OddChips,
+			"rake":     pot.Rake,
+		}
+		// Winners of a contested pot always show their hands
+		if len(pot.Eligible) > 1 {
+			if hands := the.winningHands(winners); len(hands) > 0 {
+				awarded["winningHands"] = hands
+			}
+		}
+		the.emitEvent(&GameEvent{
+			Type: "pot_awarded",
+			Data: awarded,
+		})
+	}
+
+	totalPot := the.pot
+	the.pot = 0
+	the.revealShowdownHands(contested)
+	results := the.handResults(collected, split)
+	the.finishHandRecord(pots, totalPot, rake, results)
+
+	the.revealShuffle()
 	the.emitEvent(&GameEvent{
 		Type: "pot_distributed",
 		Data: map[string]interface{}{
-			"winners":      the.winners,
-			"potPerWinner": potPerWinner,
-			"totalPot":     the.pot,
+			"winners":      the.winnerIDs(),
+			"potPerWinner": pots[0].Share,
+			"totalPot":     totalPot,
+			"pots":         pots,
+			"results":      results,
+			"rake":         rake,
 		},
 	})
+	the.emitHandSummary(contributions, pots, totalPot, rake, results)
+}
+
+// winnerIDs lists who won the main pot. Events name winners rather than
+// carry their players, whose hole cards are only shown when revealed.
+func (the *TexasHoldemEngine) winnerIDs() []string {
+	ids := make([]string, 0, len(the.winners))
+	for _, winner := range the.winners {
+		ids = append(ids, winner.ID)
+	}
+	return ids
+}
+
+// handResults reports what each player put in and took out of the hand, in
+// seat order. Players who reached showdown also get its result.
+func (the *TexasHoldemEngine) handResults(collected map[string]int, split map[string]bool) []HandResult {
+	results := make([]HandResult, 0, len(the.players))
+	positions := make(map[string]int, len(the.players))
+	for _, player := range the.players {
+		holdemPlayer := the.getHoldemPlayer(player.ID)
+		if holdemPlayer == nil {
+			continue
+		}
+		result := HandResult{
+			PlayerID:  player.ID,
+			Invested:  holdemPlayer.TotalBet,
+			Collected: collected[player.ID],
+			Net:       collected[player.ID] - holdemPlayer.TotalBet,
+		}
+		if hand, ok := the.showdownHands[player.ID]; ok {
+			if the.revealed[player.ID] {
+				result.Hand = hand.Description()
+				result.BestCards = hand.OrderedCards()
+			}
+			switch {
+			case split[player.ID]:
+				result.Showdown = ShowdownSplit
+			case result.Collected > 0:
+				result.Showdown = ShowdownWon
+			default:
+				result.Showdown = ShowdownLost
+			}
+		}
+		results = append(results, result)
+		positions[player.ID] = player.Position
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return positions[results[i].PlayerID] < positions[results[j].PlayerID]
+	})
+	return results
+}
+
+// potWinners returns the eligible players holding the best showdown hand.
+// When the hand ended without a showdown, the remaining player wins.
+func (the *TexasHoldemEngine) potWinners(eligible []string) []*TexasHoldemPlayer {
+	var best *PokerHand
+	winners := make([]*TexasHoldemPlayer, 0, 1)
+	for _, playerID := range eligible {
+		hand := the.showdownHands[playerID]
+		if hand == nil {
+			continue
+		}
+		switch {
+		case best == nil || hand.Compare(best) > 0:
+			best = hand
+			winners = []*TexasHoldemPlayer{the.getHoldemPlayer(playerID)}
+		case hand.Compare(best) == 0:
+			winners = append(winners, the.getHoldemPlayer(playerID))
+		}
+	}
+	if len(winners) > 0 {
+		return winners
+	}
+
+	for _, playerID := range eligible {
+		for _, winner := range the.winners {
+			if winner.ID == playerID {
+				winners = append(winners, winner)
+			}
+		}
+	}
+	return winners
+}
+
+// ChipCounts returns every player's stack and the chips in the pot once any
+// action being processed has finished
+func (the *TexasHoldemEngine) ChipCounts() (map[string]int, int) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	stacks := make(map[string]int, len(the.players))
+	for _, player := range the.players {
+		if holdemPlayer := the.getHoldemPlayer(player.ID); holdemPlayer != nil {
+			stacks[player.ID] = holdemPlayer.Chips
+		}
+	}
+	return stacks, the.pot
+}
+
+// AdjustChips adds delta chips to a player's stack, refusing to go negative
+func (the *TexasHoldemEngine) AdjustChips(playerID string, delta int) error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	holdemPlayer := the.getHoldemPlayer(playerID)
+	if holdemPlayer == nil {
+		return fmt.Errorf("player %s not found", playerID)
+	}
+	if holdemPlayer.Chips+delta < 0 {
+		return fmt.Errorf("adjustment would leave player %s with a negative stack", playerID)
+	}
+	holdemPlayer.Chips += delta
+	return nil
+}
+
+// RepairChips applies reconciliation adjustments to several stacks at once,
+// all or none. Stacks are only repaired between hands, with the pot empty.
+func (the *TexasHoldemEngine) RepairChips(deltas map[string]int) error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if state := the.GetState(); state == GameStateInProgress || state == GameStatePaused || the.pot > 0 {
+		return fmt.Errorf("stacks can only be repaired between hands")
+	}
+	for playerID, delta := range deltas {
+		holdemPlayer := the.getHoldemPlayer(playerID)
+		if holdemPlayer == nil {
+			return fmt.Errorf("player %s not found", playerID)
+		}
+		if holdemPlayer.Chips+delta < 0 {
+			return fmt.Errorf("adjustment would leave player %s with a negative stack", playerID)
+		}
+	}
+	for playerID, delta := range deltas {
+		the.getHoldemPlayer(playerID).Chips += delta
+	}
+	return nil
 }
 
 // GetWinners returns the winners of the current hand
@@ -1040,15 +1458,7 @@ func (the *TexasHoldemEngine) IsGameOver() bool {
 	}
 
 	// Game is over if only one player has chips
-	playersWithChips := 0
-	for _, player := range the.players {
-		holdemPlayer := the.getHoldemPlayer(player.ID)
-		if holdemPlayer != nil && holdemPlayer.Chips > 0 {
-			playersWithChips++
-		}
-	}
-
-	return playersWithChips <= 1
+	return the.playersWithChips() <= 1
 }
 
 // Helper function
@@ -1147,6 +1557,12 @@ func (the *TexasHoldemEngine) SetBigBlind(amount int) {
 	the.bigBlind = amount
 }
 
+// SetMaxPlayers sets how many players the engine deals to, no more than
+// MaxEnginePlayers
+func (the *TexasHoldemEngine) SetMaxPlayers(count int) {
+	the.maxPlayers = min(max(count, 2), MaxEnginePlayers)
+}
+
 // GetPublicGameState returns public game state (community cards, pot, etc.)
 func (the *TexasHoldemEngine) GetPublicGameState() map[string]interface{} {
 	currentPlayerID := ""
@@ -1163,6 +1579,7 @@ func (the *TexasHoldemEngine) GetPublicGameState() map[string]interface{} {
 		"dealer_position": the.dealerPos,
 		"small_blind":     the.smallBlind,
 		"big_blind":       the.bigBlind,
+		"ante":            the.ante,
 	}
 }
 
@@ -1178,7 +1595,7 @@ func (the *TexasHoldemEngine) GetPlayerState(playerID string) map[string]interfa
 		return nil
 	}
 
-	return map[string]interface{}{
+	state := map[string]interface{}{
 		"hand":        holdemPlayer.Hand,
 		"chips":       holdemPlayer.Chips,
 		"current_bet": holdemPlayer.CurrentBet,
@@ -1186,4 +1603,11 @@ func (the *TexasHoldemEngine) GetPlayerState(playerID string) map[string]interfa
 		"is_all_in":   holdemPlayer.IsAllIn,
 		"position":    player.Position,
 	}
+	if raiseRange, err := the.GetRaiseRange(playerID); err == nil {
+		state["raise_range"] = raiseRange
+	}
+	if support := the.DecisionSupport(playerID); support != nil {
+		state["decision_support"] = support
+	}
+	return state
 }
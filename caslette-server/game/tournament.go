@@ -0,0 +1,650 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tournament defaults
+const (
+	DefaultTournamentSeats = 8 // Players per tournament table
+	tournamentOpTimeout    = 5 * time.Second
+)
+
+// TournamentStatus is the lifecycle state of a tournament
+type TournamentStatus string
+
+const (
+	TournamentRegistering TournamentStatus = "registering"
+	TournamentRunning     TournamentStatus = "running"
+	TournamentFinished    TournamentStatus = "finished"
+)
+
+// TournamentConfig describes a multi-table freezeout
+type TournamentConfig struct {
+	ID            string                `json:"id"`
+	Name          string                `json:"name"`
+	GameType      GameType              `json:"game_type"`
+	Settings      TableSettings         `json:"settings"`       // Template for the tournament's tables
+	StartingStack int                   `json:"starting_stack"` // Chips every entrant starts with
+	SeatsPerTable int                   `json:"seats_per_table"`
+	EntryFee      int                   `json:"entry_fee"` // Added to the prize pool per entrant
+	Guarantee     int                   `json:"guarantee"` // Minimum prize pool
+	Payouts       []int                 `json:"payouts"`   // Percent of the prize pool by finishing position, summing to 100
+	MinEntrants   int                   `json:"min_entrants"`
+	Clock         TournamentClockConfig `json:"clock"`
+}
+
+// TournamentEntrant is a registered player and, once out, their result
+type TournamentEntrant struct {
+	PlayerID     string    `json:"player_id"`
+	Username     string    `json:"username"`
+	TableID      string    `json:"table_id,omitempty"` // Current table while still playing
+	Position     int       `json:"position,omitempty"` // Finishing position, 1 for the winner
+	Payout       int       `json:"payout,omitempty"`
+	EliminatedAt time.Time `json:"eliminated_at,omitempty"`
+}
+
+// TournamentEvent is a tournament-wide event for the rooms of its tables
+type TournamentEvent struct {
+	Type         string                 `json:"type"`
+	TournamentID string                 `json:"tournament_id"`
+	Data         map[string]interface{} `json:"data"`
+	Timestamp    time.Time              `json:"timestamp"`
+	Rooms        []string               `json:"-"` // Table rooms to deliver to
+}
+
+// TournamentEventSink receives tournament events for delivery to clients.
+// Events arrive in order and without the tournament lock held, so sinks may
+// call back into the tournament.
+type TournamentEventSink interface {
+	OnTournamentEvent(event *TournamentEvent)
+}
+
+// Tournament seats entrants across tournament tables, escalates blinds with a
+// TournamentClock, eliminates busted players, re-seats the remaining field to
+// keep tables balanced and pays out by finishing position
+type Tournament struct {
+	config       TournamentConfig
+	tableManager *ActorTableManager
+	clock        *TournamentClock
+	events       TournamentEventSink
+	stopHands    func()
+	stopEvents   func()
+
+	mu        sync.Mutex
+	status    TournamentStatus
+	entrants  map[string]*TournamentEntrant
+	order     []string        // Player IDs in registration order
+	tables    map[string]bool // Tournament tables still in play
+	feature   featureTable
+	leaders   []ChipLeader
+	remaining int
+	prizePool int
+	payouts   []int // Amount paid to each finishing position
+
+	// Events queued under mu and delivered after it is released, since
+	// sinks may write to the ledger or take other locks
+	pending    []*TournamentEvent
+	delivering bool
+}
+
+// NewTournament validates the configuration and creates a tournament open for
+// registration. The clock broadcasts through hub; other events go to events.
+func NewTournament(config TournamentConfig, tableManager *ActorTableManager, hub WebSocketHub, events TournamentEventSink) (*Tournament, error) {
+	if config.ID == "" {
+		return nil, fmt.Errorf("tournament requires an ID")
+	}
+	if config.GameType == "" {
+		config.GameType = GameTypeTexasHoldem
+	}
+	if config.SeatsPerTable == 0 {
+		config.SeatsPerTable = DefaultTournamentSeats
+	}
+	if config.SeatsPerTable < 2 || config.SeatsPerTable > DefaultTournamentSeats {
+		return nil, fmt.Errorf("tournament tables need between 2 and %d seats", DefaultTournamentSeats)
+	}
+	if config.StartingStack <= 0 {
+		return nil, fmt.Errorf("starting stack must be positive")
+	}
+	if config.MinEntrants < 2 {
+		config.MinEntrants = 2
+	}
+	if err := validatePayouts(config.Payouts); err != nil {
+		return nil, err
+	}
+
+	// Every seat starts with exactly the starting stack
+	config.Settings.TournamentMode = true
+	config.Settings.BuyIn = config.StartingStack
+	config.Settings.MaxBuyIn = config.StartingStack
+	config.Settings.AutoStart = false
+
+	config.Clock.TournamentID = config.ID
+	clock, err := NewTournamentClock(config.Clock, hub)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tournament{
+		config:       config,
+		tableManager: tableManager,
+		clock:        clock,
+		events:       events,
+		status:       TournamentRegistering,
+		entrants:     make(map[string]*TournamentEntrant),
+		tables:       make(map[string]bool),
+	}
+	clock.AddRoom(TournamentRoomID(config.ID))
+	t.stopHands = tableManager.AddHandListener(t.onHandComplete)
+	t.stopEvents = tableManager.AddGameEventListener(t.onGameEvent)
+	return t, nil
+}
+
+// validatePayouts checks the payout percentages
+func validatePayouts(payouts []int) error {
+	if len(payouts) == 0 {
+		return fmt.Errorf("tournament requires at least one paid position")
+	}
+	total := 0
+	for i, percent := range payouts {
+		if percent <= 0 {
+			return fmt.Errorf("payout for position %d must be positive", i+1)
+		}
+		total += percent
+	}
+	if total != 100 {
+		return fmt.Errorf("payouts must sum to 100 percent, got %d", total)
+	}
+	return nil
+}
+
+// calculatePayouts splits the prize pool by percentage. Rounding leftovers go
+// to the winner.
+func calculatePayouts(prizePool int, percents []int) []int {
+	amounts := make([]int, len(percents))
+	paid := 0
+	for i, percent := range percents {
+		amounts[i] = prizePool * percent / 100
+		paid += amounts[i]
+	}
+	if len(amounts) > 0 {
+		amounts[0] += prizePool - paid
+	}
+	return amounts
+}
+
+// ID returns the tournament ID
+func (t *Tournament) ID() string {
+	return t.config.ID
+}
+
+// Clock returns the blind clock driving the tournament's tables
+func (t *Tournament) Clock() *TournamentClock {
+	return t.clock
+}
+
+// Register enters a player while the tournament is open for registration
+func (t *Tournament) Register(playerID, username string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRegistering {
+		return &TableError{"REGISTRATION_CLOSED", "Tournament registration is closed"}
+	}
+	if _, exists := t.entrants[playerID]; exists {
+		return &TableError{"ALREADY_REGISTERED", "Player is already registered"}
+	}
+	t.entrants[playerID] = &TournamentEntrant{PlayerID: playerID, Username: username}
+	t.order = append(t.order, playerID)
+	return nil
+}
+
+// Unregister withdraws a player before the tournament starts
+func (t *Tournament) Unregister(playerID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRegistering {
+		return &TableError{"REGISTRATION_CLOSED", "Tournament registration is closed"}
+	}
+	if _, exists := t.entrants[playerID]; !exists {
+		return &TableError{"NOT_REGISTERED", "Player is not registered"}
+	}
+	delete(t.entrants, playerID)
+	for i, id := range t.order {
+		if id == playerID {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Start closes registration, creates the tables, seats the field round-robin
+// and starts the blind clock
+func (t *Tournament) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.deliverEvents()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRegistering {
+		return &TableError{"TOURNAMENT_STARTED", "Tournament has already started"}
+	}
+	if len(t.order) < t.config.MinEntrants {
+		return &TableError{"NOT_ENOUGH_ENTRANTS", fmt.Sprintf("Tournament needs at least %d entrants", t.config.MinEntrants)}
+	}
+
+	tableCount := (len(t.order) + t.config.SeatsPerTable - 1) / t.config.SeatsPerTable
+	tables := make([]*GameTable, 0, tableCount)
+	for i := 0; i < tableCount; i++ {
+		table, err := t.tableManager.CreateTable(ctx, t.tableRequest(i+1))
+		if err != nil {
+			t.closeTables(tables)
+			return fmt.Errorf("failed to create tournament table: %w", err)
+		}
+		tables = append(tables, table)
+	}
+
+	for i, playerID := range t.order {
+		entrant := t.entrants[playerID]
+		table := tables[i%tableCount]
+		if err := t.tableManager.JoinTable(ctx, &TableJoinRequest{
+			TableID:  table.ID,
+			PlayerID: playerID,
+			Username: entrant.Username,
+			Mode:     JoinModePlayer,
+			BuyIn:    t.config.StartingStack,
+		}); err != nil {
+			t.closeTables(tables)
+			return fmt.Errorf("failed to seat %s: %w", playerID, err)
+		}
+		entrant.TableID = table.ID
+	}
+
+	for _, table := range tables {
+		t.tables[table.ID] = true
+		t.clock.AddTable(table)
+	}
+	t.remaining = len(t.order)
+	t.prizePool = max(t.config.EntryFee*len(t.order), t.config.Guarantee)
+	paid := min(len(t.config.Payouts), len(t.order))
+	t.payouts = calculatePayouts(t.prizePool, t.config.Payouts[:paid])
+	t.status = TournamentRunning
+	t.clock.Start()
+
+	t.emitLocked("tournament_started", map[string]interface{}{
+		"entrants":   len(t.order),
+		"tables":     len(tables),
+		"prize_pool": t.prizePool,
+		"payouts":    t.payouts,
+	})
+	t.updateLeadersLocked()
+	return nil
+}
+
+// tableRequest describes the numbered tournament table to create
+func (t *Tournament) tableRequest(number int) *TableCreateRequest {
+	// Each table seats SeatsPerTable, as far as the game allows, and deals
+	// down to the last two players
+	settings := t.config.Settings
+	settings.MaxPlayers = min(t.config.SeatsPerTable, CapacityFor(t.config.GameType).MaxPlayers)
+	settings.MinPlayers = 0
+	return &TableCreateRequest{
+		Name:       fmt.Sprintf("%s Table %d", t.config.Name, number),
+		GameType:   t.config.GameType,
+		CreatedBy:  "tournament_" + t.config.ID,
+		Username:   "tournament",
+		Settings:   settings,
+		Tags:       []string{"tournament"},
+		Sanctioned: true,
+	}
+}
+
+// closeTables closes tables created by a start that failed
+func (t *Tournament) closeTables(tables []*GameTable) {
+	for _, table := range tables {
+		t.tableManager.CloseTable(table.ID)
+	}
+}
+
+// onHandComplete eliminates players at a tournament table who busted in the
+// hand, then re-seats the field
+func (t *Tournament) onHandComplete(table *GameTable, results []HandResult) {
+	t.mu.Lock()
+	defer t.deliverEvents()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRunning || !t.tables[table.ID] {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tournamentOpTimeout)
+	defer cancel()
+
+	// Players who started the hand with less finish below those with more
+	busted := make([]HandResult, 0)
+	for _, result := range results {
+		entrant, ok := t.entrants[result.PlayerID]
+		if !ok || entrant.Position != 0 {
+			continue
+		}
+		if t.tableManager.stackAt(ctx, table.ID, result.PlayerID) <= 0 {
+			busted = append(busted, result)
+		}
+	}
+	if len(busted) == 0 {
+		t.updateLeadersLocked()
+		return
+	}
+	sort.SliceStable(busted, func(i, j int) bool {
+		return busted[i].Invested < busted[j].Invested
+	})
+
+	for _, result := range busted {
+		t.eliminateLocked(ctx, result.PlayerID)
+	}
+	t.afterEliminationLocked(ctx)
+}
+
+// Eliminate knocks a player out of the tournament, e.g. when they bust at a
+// table whose hand results are not reported or an admin removes them
+func (t *Tournament) Eliminate(ctx context.Context, playerID string) error {
+	t.mu.Lock()
+	defer t.deliverEvents()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRunning {
+		return &TableError{"TOURNAMENT_NOT_RUNNING", "Tournament is not running"}
+	}
+	entrant, ok := t.entrants[playerID]
+	if !ok || entrant.Position != 0 {
+		return &TableError{"NOT_IN_TOURNAMENT", "Player is not in the tournament"}
+	}
+	t.eliminateLocked(ctx, playerID)
+	t.afterEliminationLocked(ctx)
+	return nil
+}
+
+// eliminateLocked records a player's finishing position and unseats them
+func (t *Tournament) eliminateLocked(ctx context.Context, playerID string) {
+	entrant := t.entrants[playerID]
+	entrant.Position = t.remaining
+	entrant.Payout = t.payoutFor(entrant.Position)
+	entrant.EliminatedAt = time.Now()
+	tableID := entrant.TableID
+	entrant.TableID = ""
+	t.remaining--
+
+	if err := t.tableManager.LeaveTable(ctx, &TableLeaveRequest{TableID: tableID, PlayerID: playerID}); err != nil {
+		log.Printf("Tournament %s: failed to unseat eliminated player %s: %v", t.config.ID, playerID, err)
+	}
+
+	t.emitLocked("tournament_player_eliminated", map[string]interface{}{
+		"player_id":    playerID,
+		"username":     entrant.Username,
+		"table_id":     tableID,
+		"position":     entrant.Position,
+		"payout":       entrant.Payout,
+		"players_left": t.remaining,
+	})
+}
+
+// payoutFor returns the prize for a finishing position
+func (t *Tournament) payoutFor(position int) int {
+	if position < 1 || position > len(t.payouts) {
+		return 0
+	}
+	return t.payouts[position-1]
+}
+
+// afterEliminationLocked finishes the tournament once one player is left and
+// otherwise re-seats the field
+func (t *Tournament) afterEliminationLocked(ctx context.Context) {
+	if t.remaining <= 1 {
+		t.finishLocked(ctx)
+		return
+	}
+	t.rebalanceLocked(ctx)
+	t.updateLeadersLocked()
+}
+
+// finishLocked crowns the last player standing and stops the clock
+func (t *Tournament) finishLocked(ctx context.Context) {
+	for _, entrant := range t.entrants {
+		if entrant.Position == 0 {
+			entrant.Position = 1
+			entrant.Payout = t.payoutFor(1)
+		}
+	}
+	t.remaining = 0
+	t.status = TournamentFinished
+	t.clock.Stop()
+	t.detach()
+
+	t.emitLocked("tournament_finished", map[string]interface{}{
+		"prize_pool": t.prizePool,
+		"standings":  t.standingsLocked(),
+	})
+}
+
+// tableCountsLocked returns the tournament's tables with their players,
+// most crowded first
+func (t *Tournament) tableCountsLocked() []*GameTable {
+	tables := make([]*GameTable, 0, len(t.tables))
+	for tableID := range t.tables {
+		table, err := t.tableManager.GetTable(tableID)
+		if err != nil {
+			delete(t.tables, tableID)
+			continue
+		}
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].GetPlayerCount() != tables[j].GetPlayerCount() {
+			return tables[i].GetPlayerCount() > tables[j].GetPlayerCount()
+		}
+		return tables[i].ID < tables[j].ID
+	})
+	return tables
+}
+
+// rebalanceLocked breaks tables the field no longer needs, then moves players
+// from the fullest table to the shortest until no two tables differ by more
+// than one player
+func (t *Tournament) rebalanceLocked(ctx context.Context) {
+	needed := (t.remaining + t.config.SeatsPerTable - 1) / t.config.SeatsPerTable
+
+	for len(t.tables) > needed {
+		tables := t.tableCountsLocked()
+		if len(tables) <= needed {
+			break
+		}
+		broken := tables[len(tables)-1]
+		if !t.breakTableLocked(ctx, broken, tables[:len(tables)-1]) {
+			return
+		}
+	}
+
+	for {
+		tables := t.tableCountsLocked()
+		if len(tables) < 2 {
+			return
+		}
+		fullest, shortest := tables[0], tables[len(tables)-1]
+		if fullest.GetPlayerCount()-shortest.GetPlayerCount() <= 1 {
+			return
+		}
+		playerID := lastSeatedPlayer(fullest)
+		if playerID == "" || !t.moveLocked(ctx, playerID, fullest, shortest, "balance") {
+			return
+		}
+	}
+}
+
+// breakTableLocked moves every player at a table to the shortest remaining
+// tables and closes it
+func (t *Tournament) breakTableLocked(ctx context.Context, broken *GameTable, others []*GameTable) bool {
+	for _, slot := range broken.PlayerSlots {
+		if slot.PlayerID == "" {
+			continue
+		}
+		target := shortestTable(others)
+		if target == nil || !t.moveLocked(ctx, slot.PlayerID, broken, target, "table_broken") {
+			return false
+		}
+	}
+
+	delete(t.tables, broken.ID)
+	t.clock.RemoveTable(broken.ID)
+	t.emitLocked("tournament_table_broken", map[string]interface{}{
+		"table_id":     broken.ID,
+		"tables_left":  len(t.tables),
+		"players_left": t.remaining,
+	})
+	if err := t.tableManager.CloseTable(broken.ID); err != nil {
+		log.Printf("Tournament %s: failed to close broken table %s: %v", t.config.ID, broken.ID, err)
+	}
+	return true
+}
+
+// moveLocked re-seats a player with their stack and announces it to both tables
+func (t *Tournament) moveLocked(ctx context.Context, playerID string, from, to *GameTable, reason string) bool {
+	entrant := t.entrants[playerID]
+	username := playerID
+	if entrant != nil {
+		username = entrant.Username
+	}
+	if err := t.tableManager.MovePlayer(ctx, playerID, username, from.ID, to.ID); err != nil {
+		log.Printf("Tournament %s: failed to move %s from %s to %s: %v", t.config.ID, playerID, from.ID, to.ID, err)
+		return false
+	}
+	if entrant != nil {
+		entrant.TableID = to.ID
+	}
+
+	t.emit("tournament_player_moved", []string{from.RoomID, to.RoomID}, map[string]interface{}{
+		"player_id":     playerID,
+		"username":      username,
+		"from_table_id": from.ID,
+		"to_table_id":   to.ID,
+		"reason":        reason,
+	})
+	return true
+}
+
+// shortestTable returns the table with the fewest players and an open seat
+func shortestTable(tables []*GameTable) *GameTable {
+	var shortest *GameTable
+	for _, table := range tables {
+		if table.GetPlayerCount() >= table.MaxPlayers {
+			continue
+		}
+		if shortest == nil || table.GetPlayerCount() < shortest.GetPlayerCount() {
+			shortest = table
+		}
+	}
+	return shortest
+}
+
+// lastSeatedPlayer returns the player in the highest occupied seat
+func lastSeatedPlayer(table *GameTable) string {
+	for i := len(table.PlayerSlots) - 1; i >= 0; i-- {
+		if table.PlayerSlots[i].PlayerID != "" {
+			return table.PlayerSlots[i].PlayerID
+		}
+	}
+	return ""
+}
+
+// Status returns the tournament's lifecycle state
+func (t *Tournament) Status() TournamentStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// Standings lists entrants still playing first, then finishers by position
+func (t *Tournament) Standings() []TournamentEntrant {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.standingsLocked()
+}
+
+// standingsLocked builds the standings; the caller must hold t.mu
+func (t *Tournament) standingsLocked() []TournamentEntrant {
+	standings := make([]TournamentEntrant, 0, len(t.order))
+	for _, playerID := range t.order {
+		standings = append(standings, *t.entrants[playerID])
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i].Position, standings[j].Position
+		if a == 0 || b == 0 {
+			return a == 0 && b != 0
+		}
+		return a < b
+	})
+	return standings
+}
+
+// PrizePool returns the prize pool, known once the tournament starts
+func (t *Tournament) PrizePool() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.prizePool
+}
+
+// emitLocked sends an event to every tournament table room
+func (t *Tournament) emitLocked(eventType string, data map[string]interface{}) {
+	rooms := make([]string, 0, len(t.tables))
+	for tableID := range t.tables {
+		if table, err := t.tableManager.GetTable(tableID); err == nil {
+			rooms = append(rooms, table.Rooms()...)
+		}
+	}
+	sort.Strings(rooms)
+	t.emit(eventType, rooms, data)
+}
+
+// emit queues an event for the given rooms and the tournament's spectator
+// room; the caller must hold t.mu and call deliverEvents after releasing it
+func (t *Tournament) emit(eventType string, rooms []string, data map[string]interface{}) {
+	if t.events == nil {
+		return
+	}
+	rooms = append(rooms, TournamentRoomID(t.config.ID))
+	t.pending = append(t.pending, &TournamentEvent{
+		Type:         eventType,
+		TournamentID: t.config.ID,
+		Data:         data,
+		Timestamp:    time.Now(),
+		Rooms:        rooms,
+	})
+}
+
+// deliverEvents hands queued events to the sink in order without holding
+// t.mu. Only one caller delivers at a time; events queued meanwhile, even by
+// the sink itself, are picked up by that caller's loop.
+func (t *Tournament) deliverEvents() {
+	t.mu.Lock()
+	if t.delivering {
+		t.mu.Unlock()
+		return
+	}
+	t.delivering = true
+	for len(t.pending) > 0 {
+		batch := t.pending
+		t.pending = nil
+		t.mu.Unlock()
+		for _, event := range batch {
+			t.events.OnTournamentEvent(event)
+		}
+		t.mu.Lock()
+	}
+	t.delivering = false
+	t.mu.Unlock()
+}
@@ -0,0 +1,369 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// TournamentLevel describes one blind level of a tournament structure
+type TournamentLevel struct {
+	Level      int           `json:"level"`
+	SmallBlind int           `json:"small_blind"`
+	BigBlind   int           `json:"big_blind"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// TournamentClockConfig configures level progression and break scheduling
+type TournamentClockConfig struct {
+	TournamentID     string            `json:"tournament_id"`
+	Levels           []TournamentLevel `json:"levels"`
+	BreakEveryLevels int               `json:"break_every_levels"` // 0 disables breaks
+	BreakDuration    time.Duration     `json:"break_duration"`
+	TickInterval     time.Duration     `json:"tick_interval"`
+}
+
+// TournamentClockStatus is the clock snapshot broadcast to tournament tables
+type TournamentClockStatus struct {
+	TournamentID   string    `json:"tournament_id"`
+	Level          int       `json:"level"`
+	SmallBlind     int       `json:"small_blind"`
+	BigBlind       int       `json:"big_blind"`
+	TimeRemaining  int       `json:"time_remaining"` // seconds left in the current level or break
+	OnBreak        bool      `json:"on_break"`
+	NextBreakLevel int       `json:"next_break_level,omitempty"`
+	NextBreakIn    int       `json:"next_break_in,omitempty"` // seconds until the next break
+	AverageStack   int       `json:"average_stack"`
+	PlayersLeft    int       `json:"players_left"`
+	Finished       bool      `json:"finished"`
+	TableCount     int       `json:"table_count"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TournamentClock drives blind levels and synchronized breaks across tournament tables
+type TournamentClock struct {
+	config TournamentClockConfig
+	hub    WebSocketHub
+
+	mu             sync.Mutex
+	tables         map[string]*GameTable
+	rooms          map[string]bool // Extra rooms following the clock, e.g. spectators
+	levelIndex     int
+	phaseStartedAt time.Time
+	onBreak        bool
+	finished       bool
+	pausedEngines  map[string]bool // table IDs paused by the clock for a break
+
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// NewTournamentClock creates a clock for the given structure
+func NewTournamentClock(config TournamentClockConfig, hub WebSocketHub) (*TournamentClock, error) {
+	if len(config.Levels) == 0 {
+		return nil, fmt.Errorf("tournament requires at least one level")
+	}
+	for i, level := range config.Levels {
+		if level.Duration <= 0 {
+			return nil, fmt.Errorf("level %d must have a positive duration", i+1)
+		}
+		if level.SmallBlind <= 0 || level.BigBlind < level.SmallBlind {
+			return nil, fmt.Errorf("level %d has invalid blinds", i+1)
+		}
+	}
+	if config.BreakEveryLevels > 0 && config.BreakDuration <= 0 {
+		return nil, fmt.Errorf("break duration must be positive when breaks are scheduled")
+	}
+	if config.TickInterval <= 0 {
+		config.TickInterval = time.Second
+	}
+
+	return &TournamentClock{
+		config:        config,
+		hub:           hub,
+		tables:        make(map[string]*GameTable),
+		rooms:         make(map[string]bool),
+		pausedEngines: make(map[string]bool),
+		stop:          make(chan struct{}),
+	}, nil
+}
+
+// AddTable associates a table with the clock and applies the current blinds
+func (tc *TournamentClock) AddTable(table *GameTable) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.tables[table.ID] = table
+	tc.applyBlinds(table)
+}
+
+// RemoveTable detaches a table, e.g. after it has been broken up
+func (tc *TournamentClock) RemoveTable(tableID string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	delete(tc.tables, tableID)
+	delete(tc.pausedEngines, tableID)
+}
+
+// AddRoom also broadcasts the clock to a room that is not a table room
+func (tc *TournamentClock) AddRoom(roomID string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.rooms[roomID] = true
+}
+
+// Start begins the clock at the first level and broadcasts on every tick
+func (tc *TournamentClock) Start() {
+	tc.mu.Lock()
+	tc.levelIndex = 0
+	tc.phaseStartedAt = time.Now()
+	for _, table := range tc.tables {
+		tc.applyBlinds(table)
+	}
+	tc.mu.Unlock()
+
+	go tc.run()
+}
+
+// Stop halts the clock goroutine
+func (tc *TournamentClock) Stop() {
+	tc.stopped.Do(func() { close(tc.stop) })
+}
+
+// run ticks the clock until stopped or the structure is exhausted
+func (tc *TournamentClock) run() {
+	ticker := time.NewTicker(tc.config.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tc.stop:
+			return
+		case now := <-ticker.C:
+			status := tc.Tick(now)
+			tc.broadcast(status)
+			if status.Finished {
+				return
+			}
+		}
+	}
+}
+
+// Tick advances level and break state to now and returns the resulting status
+func (tc *TournamentClock) Tick(now time.Time) TournamentClockStatus {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.phaseStartedAt.IsZero() {
+		tc.phaseStartedAt = now
+	}
+
+	// Catch up on every transition that elapsed since the last tick
+	for !tc.finished {
+		phaseEnd := tc.phaseStartedAt.Add(tc.currentPhaseDuration())
+		if now.Before(phaseEnd) {
+			break
+		}
+		tc.advancePhase(phaseEnd)
+	}
+
+	return tc.statusLocked(now)
+}
+
+// Status returns the current clock status without advancing it
+func (tc *TournamentClock) Status() TournamentClockStatus {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.statusLocked(time.Now())
+}
+
+// currentPhaseDuration returns the length of the running level or break
+func (tc *TournamentClock) currentPhaseDuration() time.Duration {
+	if tc.onBreak {
+		return tc.config.BreakDuration
+	}
+	return tc.config.Levels[tc.levelIndex].Duration
+}
+
+// advancePhase moves from the current level to a break or the next level
+func (tc *TournamentClock) advancePhase(at time.Time) {
+	tc.phaseStartedAt = at
+
+	if tc.onBreak {
+		tc.onBreak = false
+		tc.resumeTables()
+		tc.nextLevel()
+		return
+	}
+
+	levelNumber := tc.levelIndex + 1
+	isLast := tc.levelIndex == len(tc.config.Levels)-1
+	if !isLast && tc.config.BreakEveryLevels > 0 && levelNumber%tc.config.BreakEveryLevels == 0 {
+		tc.onBreak = true
+		tc.pauseTables()
+		return
+	}
+
+	tc.nextLevel()
+}
+
+// nextLevel moves to the next level, finishing the clock after the final one
+func (tc *TournamentClock) nextLevel() {
+	if tc.levelIndex >= len(tc.config.Levels)-1 {
+		tc.finished = true
+		return
+	}
+	tc.levelIndex++
+	for _, table := range tc.tables {
+		tc.applyBlinds(table)
+	}
+}
+
+// blindSetter is implemented by engines with configurable blinds
+type blindSetter interface {
+	SetSmallBlind(amount int)
+	SetBigBlind(amount int)
+}
+
+// applyBlinds pushes the current level's blinds to a table and its engine
+func (tc *TournamentClock) applyBlinds(table *GameTable) {
+	level := tc.config.Levels[tc.levelIndex]
+	table.Settings.SmallBlind = level.SmallBlind
+	table.Settings.BigBlind = level.BigBlind
+	if engine, ok := table.GameEngine.(blindSetter); ok {
+		engine.SetSmallBlind(level.SmallBlind)
+		engine.SetBigBlind(level.BigBlind)
+	}
+}
+
+// pauseTables pauses every running engine for a synchronized break
+func (tc *TournamentClock) pauseTables() {
+	for id, table := range tc.tables {
+		if table.GameEngine == nil || table.GameEngine.GetState() != GameStateInProgress {
+			continue
+		}
+		if err := table.GameEngine.Pause(); err != nil {
+			log.Printf("TournamentClock: failed to pause table %s: %v", id, err)
+			continue
+		}
+		tc.pausedEngines[id] = true
+	}
+}
+
+// resumeTables resumes the engines the clock paused
+func (tc *TournamentClock) resumeTables() {
+	for id := range tc.pausedEngines {
+		if table, ok := tc.tables[id]; ok && table.GameEngine != nil {
+			if err := table.GameEngine.Resume(); err != nil {
+				log.Printf("TournamentClock: failed to resume table %s: %v", id, err)
+			}
+		}
+		delete(tc.pausedEngines, id)
+	}
+}
+
+// statusLocked builds a status snapshot; the caller must hold tc.mu
+func (tc *TournamentClock) statusLocked(now time.Time) TournamentClockStatus {
+	level := tc.config.Levels[tc.levelIndex]
+	remaining := tc.phaseStartedAt.Add(tc.currentPhaseDuration()).Sub(now)
+	if remaining < 0 || tc.finished {
+		remaining = 0
+	}
+
+	status := TournamentClockStatus{
+		TournamentID:  tc.config.TournamentID,
+		Level:         tc.levelIndex + 1,
+		SmallBlind:    level.SmallBlind,
+		BigBlind:      level.BigBlind,
+		TimeRemaining: int(remaining.Seconds()),
+		OnBreak:       tc.onBreak,
+		Finished:      tc.finished,
+		UpdatedAt:     now,
+		TableCount:    len(tc.tables),
+	}
+
+	if !tc.onBreak && !tc.finished && tc.config.BreakEveryLevels > 0 {
+		untilBreak := remaining
+		for i := tc.levelIndex; i < len(tc.config.Levels)-1; i++ {
+			if (i+1)%tc.config.BreakEveryLevels == 0 {
+				status.NextBreakLevel = i + 1
+				status.NextBreakIn = int(untilBreak.Seconds())
+				break
+			}
+			untilBreak += tc.config.Levels[i+1].Duration
+		}
+	}
+
+	status.AverageStack, status.PlayersLeft = tc.averageStack()
+	return status
+}
+
+// averageStack computes the mean chip count of players still holding chips
+func (tc *TournamentClock) averageStack() (int, int) {
+	total, players := 0, 0
+	for _, table := range tc.tables {
+		if table.GameEngine == nil {
+			continue
+		}
+		for _, player := range table.GameEngine.GetPlayers() {
+			chips := playerChips(table.GameEngine, player)
+			if chips <= 0 {
+				continue
+			}
+			total += chips
+			players++
+		}
+	}
+	if players == 0 {
+		return 0, 0
+	}
+	return total / players, players
+}
+
+// playerStates is implemented by engines keeping typed per-player state
+type playerStates interface {
+	PlayerState(playerID string) PlayerState
+}
+
+// playerChips reads a player's stack from the engine's typed player state,
+// counting chips already committed to the current hand so blinds do not skew
+// the average
+func playerChips(engine GameEngine, player *Player) int {
+	states, ok := engine.(playerStates)
+	if !ok || player == nil {
+		return 0
+	}
+	holdemPlayer, ok := states.PlayerState(player.ID).(*TexasHoldemPlayer)
+	if !ok {
+		return 0
+	}
+	return holdemPlayer.Chips + holdemPlayer.TotalBet
+}
+
+// broadcast sends the status to every associated table room and extra room
+func (tc *TournamentClock) broadcast(status TournamentClockStatus) {
+	if tc.hub == nil {
+		return
+	}
+
+	tc.mu.Lock()
+	rooms := make([]string, 0, len(tc.tables)+len(tc.rooms))
+	for _, table := range tc.tables {
+		rooms = append(rooms, table.Rooms()...)
+	}
+	for roomID := range tc.rooms {
+		rooms = append(rooms, roomID)
+	}
+	tc.mu.Unlock()
+
+	for _, roomID := range rooms {
+		msg := &WebSocketMessage{
+			Type: "tournament_clock",
+			Data: status,
+			Room: roomID,
+		}
+		if err := tc.hub.BroadcastToRoom(roomID, msg); err != nil {
+			log.Printf("TournamentClock: failed to broadcast to room %s: %v", roomID, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHub struct {
+	mu       sync.Mutex
+	messages map[string][]interface{}
+}
+
+func newRecordingHub() *recordingHub {
+	return &recordingHub{messages: make(map[string][]interface{})}
+}
+
+func (h *recordingHub) BroadcastToRoom(roomID string, msg interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages[roomID] = append(h.messages[roomID], msg)
+	return nil
+}
+
+func (h *recordingHub) GetRoomUsers(roomID string) []map[string]interface{} {
+	return nil
+}
+
+func testTournamentConfig() TournamentClockConfig {
+	return TournamentClockConfig{
+		TournamentID: "t1",
+		Levels: []TournamentLevel{
+			{Level: 1, SmallBlind: 25, BigBlind: 50, Duration: 10 * time.Minute},
+			{Level: 2, SmallBlind: 50, BigBlind: 100, Duration: 10 * time.Minute},
+			{Level: 3, SmallBlind: 100, BigBlind: 200, Duration: 10 * time.Minute},
+		},
+		BreakEveryLevels: 2,
+		BreakDuration:    5 * time.Minute,
+	}
+}
+
+func newRunningTournamentTable(t *testing.T, id string) *GameTable {
+	table := NewGameTable(id, "Tournament "+id, GameTypeTexasHoldem, "creator", TournamentSettings())
+	engine := NewTexasHoldemEngine(id)
+	require.NoError(t, engine.AddPlayer(&Player{ID: id + "_p1", Name: "p1", Data: map[string]interface{}{"chips": 1500}}))
+	require.NoError(t, engine.AddPlayer(&Player{ID: id + "_p2", Name: "p2", Data: map[string]interface{}{"chips": 2500}}))
+	require.NoError(t, engine.Start())
+	table.GameEngine = engine
+	return table
+}
+
+func TestNewTournamentClockValidation(t *testing.T) {
+	_, err := NewTournamentClock(TournamentClockConfig{}, nil)
+	assert.Error(t, err)
+
+	config := testTournamentConfig()
+	config.BreakDuration = 0
+	_, err = NewTournamentClock(config, nil)
+	assert.Error(t, err)
+
+	config = testTournamentConfig()
+	config.Levels[1].BigBlind = 10
+	_, err = NewTournamentClock(config, nil)
+	assert.Error(t, err)
+}
+
+func TestTournamentClockLevelsAndBreaks(t *testing.T) {
+	clock, err := NewTournamentClock(testTournamentConfig(), nil)
+	require.NoError(t, err)
+
+	tableA := newRunningTournamentTable(t, "a")
+	tableB := newRunningTournamentTable(t, "b")
+	clock.AddTable(tableA)
+	clock.AddTable(tableB)
+
+	start := time.Now()
+	status := clock.Tick(start)
+	assert.Equal(t, 1, status.Level)
+	assert.Equal(t, 25, tableA.Settings.SmallBlind)
+	assert.Equal(t, 600, status.TimeRemaining)
+	assert.Equal(t, 2, status.NextBreakLevel)
+	assert.Equal(t, 1200, status.NextBreakIn)
+	assert.Equal(t, 2000, status.AverageStack)
+	assert.Equal(t, 4, status.PlayersLeft)
+
+	// Level 2 applies new blinds to every engine
+	status = clock.Tick(start.Add(11 * time.Minute))
+	assert.Equal(t, 2, status.Level)
+	assert.Equal(t, 100, tableB.Settings.BigBlind)
+	assert.Equal(t, 100, tableB.GameEngine.(*TexasHoldemEngine).bigBlind)
+
+	// Break after level 2 pauses all running engines together
+	status = clock.Tick(start.Add(21 * time.Minute))
+	assert.True(t, status.OnBreak)
+	assert.Equal(t, 240, status.TimeRemaining)
+	assert.Equal(t, GameStatePaused, tableA.GameEngine.GetState())
+	assert.Equal(t, GameStatePaused, tableB.GameEngine.GetState())
+
+	// Break ends, engines resume and level 3 starts
+	status = clock.Tick(start.Add(26 * time.Minute))
+	assert.False(t, status.OnBreak)
+	assert.Equal(t, 3, status.Level)
+	assert.Equal(t, GameStateInProgress, tableA.GameEngine.GetState())
+	assert.Equal(t, GameStateInProgress, tableB.GameEngine.GetState())
+	assert.Zero(t, status.NextBreakLevel, "no break after the final level")
+
+	status = clock.Tick(start.Add(40 * time.Minute))
+	assert.True(t, status.Finished)
+	assert.Equal(t, 0, status.TimeRemaining)
+}
+
+func TestTournamentClockBroadcastsToAllTables(t *testing.T) {
+	hub := newRecordingHub()
+	clock, err := NewTournamentClock(testTournamentConfig(), hub)
+	require.NoError(t, err)
+
+	tableA := newRunningTournamentTable(t, "a")
+	tableB := newRunningTournamentTable(t, "b")
+	clock.AddTable(tableA)
+	clock.AddTable(tableB)
+
+	clock.broadcast(clock.Tick(time.Now()))
+
+	for _, table := range []*GameTable{tableA, tableB} {
+		msgs := hub.messages[table.RoomID]
+		require.Len(t, msgs, 1)
+		msg := msgs[0].(*WebSocketMessage)
+		assert.Equal(t, "tournament_clock", msg.Type)
+		assert.Equal(t, "t1", msg.Data.(TournamentClockStatus).TournamentID)
+	}
+}
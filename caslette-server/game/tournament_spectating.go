@@ -0,0 +1,167 @@
+package game
+
+import (
+	"context"
+	"sort"
+)
+
+// DefaultChipLeaderCount is how many chip leaders spectators are shown
+const DefaultChipLeaderCount = 5
+
+// TournamentRoomID returns the spectator room that aggregates a tournament's
+// tables: every tournament event, the clock, the chip leaders and the game
+// events of the feature table
+func TournamentRoomID(tournamentID string) string {
+	return "tournament_" + tournamentID
+}
+
+// ChipLeader is one entry of a tournament's chip leader board
+type ChipLeader struct {
+	PlayerID string `json:"player_id"`
+	Username string `json:"username"`
+	TableID  string `json:"table_id"`
+	Chips    int    `json:"chips"`
+}
+
+// featureTable is the table whose game events are streamed to spectators
+type featureTable struct {
+	tableID string
+	pinned  bool // Chosen by hand rather than following the chip leader
+}
+
+// ChipLeaders returns the current chip leader board
+func (t *Tournament) ChipLeaders() []ChipLeader {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ChipLeader(nil), t.leaders...)
+}
+
+// FeatureTable returns the ID of the table streamed to spectators
+func (t *Tournament) FeatureTable() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.feature.tableID
+}
+
+// SetFeatureTable pins the table streamed to spectators. An empty ID goes
+// back to following the chip leader.
+func (t *Tournament) SetFeatureTable(tableID string) error {
+	t.mu.Lock()
+	defer t.deliverEvents()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRunning {
+		return &TableError{"TOURNAMENT_NOT_RUNNING", "Tournament is not running"}
+	}
+	if tableID == "" {
+		t.feature.pinned = false
+		t.updateLeadersLocked()
+		return nil
+	}
+	if !t.tables[tableID] {
+		return &TableError{"TABLE_NOT_IN_TOURNAMENT", "Table is not part of this tournament"}
+	}
+	t.feature.pinned = true
+	t.setFeatureLocked(tableID)
+	return nil
+}
+
+// chipLeadersLocked ranks every player still in by stack
+func (t *Tournament) chipLeadersLocked() []ChipLeader {
+	leaders := make([]ChipLeader, 0, t.remaining)
+	for _, playerID := range t.order {
+		entrant := t.entrants[playerID]
+		if entrant.Position != 0 || entrant.TableID == "" {
+			continue
+		}
+		if _, err := t.tableManager.GetTable(entrant.TableID); err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), tournamentOpTimeout)
+		chips := t.tableManager.stackAt(ctx, entrant.TableID, playerID)
+		cancel()
+		leaders = append(leaders, ChipLeader{
+			PlayerID: playerID,
+			Username: entrant.Username,
+			TableID:  entrant.TableID,
+			Chips:    chips,
+		})
+	}
+	sort.SliceStable(leaders, func(i, j int) bool {
+		return leaders[i].Chips > leaders[j].Chips
+	})
+	return leaders
+}
+
+// updateLeadersLocked announces changes to the chip leader board and moves
+// an unpinned feature table to the chip leader's table
+func (t *Tournament) updateLeadersLocked() {
+	leaders := t.chipLeadersLocked()
+	top := leaders[:min(len(leaders), DefaultChipLeaderCount)]
+	if !sameChipLeaders(top, t.leaders) {
+		t.leaders = top
+		t.emit("tournament_chip_leaders", nil, map[string]interface{}{
+			"leaders":      top,
+			"players_left": t.remaining,
+		})
+	}
+
+	// A pinned table that has been broken up no longer holds the feature
+	if t.feature.pinned && !t.tables[t.feature.tableID] {
+		t.feature.pinned = false
+	}
+	if !t.feature.pinned && len(leaders) > 0 {
+		t.setFeatureLocked(leaders[0].TableID)
+	}
+}
+
+// setFeatureLocked switches the feature table and tells spectators
+func (t *Tournament) setFeatureLocked(tableID string) {
+	if t.feature.tableID == tableID {
+		return
+	}
+	t.feature.tableID = tableID
+	data := map[string]interface{}{
+		"table_id": tableID,
+		"pinned":   t.feature.pinned,
+	}
+	if table, err := t.tableManager.GetTable(tableID); err == nil {
+		data["room_id"] = table.RoomID
+		data["table"] = table.GetTableInfo()
+	}
+	t.emit("tournament_feature_table", nil, data)
+}
+
+// sameChipLeaders reports whether two leader boards are identical
+func sameChipLeaders(a, b []ChipLeader) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// onGameEvent relays the feature table's game events to spectators
+func (t *Tournament) onGameEvent(table *GameTable, event *GameEvent) {
+	t.mu.Lock()
+	defer t.deliverEvents()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRunning || table.ID != t.feature.tableID {
+		return
+	}
+	t.emit("tournament_feature_event", nil, map[string]interface{}{
+		"table_id": table.ID,
+		"event":    event,
+	})
+}
+
+// detach stops listening to the manager's tables
+func (t *Tournament) detach() {
+	t.stopHands()
+	t.stopEvents()
+}
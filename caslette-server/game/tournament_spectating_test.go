@@ -0,0 +1,143 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seatEngineStacks gives each table's engine the players seated there with
+// the given stacks
+func seatEngineStacks(t *testing.T, manager *ActorTableManager, stacks map[string]int) {
+	for _, table := range manager.GetTables() {
+		engine := table.GameEngine.(*TexasHoldemEngine)
+		for _, slot := range table.PlayerSlots {
+			if slot.PlayerID == "" {
+				continue
+			}
+			require.NoError(t, engine.AddPlayer(&Player{ID: slot.PlayerID, Name: slot.PlayerID, Data: map[string]interface{}{"chips": stacks[slot.PlayerID]}}))
+		}
+	}
+}
+
+func tableOf(t *testing.T, manager *ActorTableManager, playerID string) *GameTable {
+	for _, table := range manager.GetTables() {
+		if table.IsPlayerAtTable(playerID) {
+			return table
+		}
+	}
+	t.Fatalf("%s is not seated", playerID)
+	return nil
+}
+
+func TestTournamentChipLeadersFollowHands(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	tournament, sink := startTestTournament(t, manager, testTournamentSetup("t1", 2), 4)
+	seatEngineStacks(t, manager, map[string]int{"e0": 1000, "e1": 2600, "e2": 1900, "e3": 500})
+
+	manager.notifyHandListeners(tableOf(t, manager, "e1"), []HandResult{{PlayerID: "e1", Net: 1100}})
+
+	leaders := tournament.ChipLeaders()
+	require.Len(t, leaders, 4)
+	assert.Equal(t, ChipLeader{PlayerID: "e1", Username: "entrante1", TableID: tableOf(t, manager, "e1").ID, Chips: 2600}, leaders[0])
+	assert.Equal(t, "e3", leaders[3].PlayerID)
+
+	announced := sink.ofType("tournament_chip_leaders")
+	require.NotEmpty(t, announced)
+	last := announced[len(announced)-1]
+	assert.Equal(t, []string{TournamentRoomID("t1")}, last.Rooms, "leader boards go to spectators only")
+
+	assert.Equal(t, tableOf(t, manager, "e1").ID, tournament.FeatureTable(), "the feature table follows the chip leader")
+	features := sink.ofType("tournament_feature_table")
+	require.NotEmpty(t, features)
+	assert.Equal(t, tournament.FeatureTable(), features[len(features)-1].Data["table_id"])
+
+	// An unchanged board is not announced again
+	count := len(sink.ofType("tournament_chip_leaders"))
+	manager.notifyHandListeners(tableOf(t, manager, "e1"), []HandResult{{PlayerID: "e1"}})
+	assert.Len(t, sink.ofType("tournament_chip_leaders"), count)
+}
+
+func TestTournamentRelaysFeatureTableEvents(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	tournament, sink := startTestTournament(t, manager, testTournamentSetup("t1", 2), 4)
+	seatEngineStacks(t, manager, map[string]int{"e0": 3000, "e1": 1000, "e2": 1000, "e3": 1000})
+	manager.notifyHandListeners(tableOf(t, manager, "e0"), nil)
+
+	feature := tableOf(t, manager, "e0")
+	other := tableOf(t, manager, "e1")
+	require.Equal(t, feature.ID, tournament.FeatureTable())
+
+	manager.notifyGameEventListeners(other, &GameEvent{Type: "player_action"})
+	assert.Empty(t, sink.ofType("tournament_feature_event"))
+
+	manager.notifyGameEventListeners(feature, &GameEvent{Type: "player_action", PlayerID: "e0"})
+	relayed := sink.ofType("tournament_feature_event")
+	require.Len(t, relayed, 1)
+	assert.Equal(t, feature.ID, relayed[0].Data["table_id"])
+	assert.Equal(t, "player_action", relayed[0].Data["event"].(*GameEvent).Type)
+	assert.Equal(t, []string{TournamentRoomID("t1")}, relayed[0].Rooms)
+
+	// A pinned feature table stays put when the chip lead is elsewhere
+	require.NoError(t, tournament.SetFeatureTable(other.ID))
+	manager.notifyHandListeners(feature, nil)
+	assert.Equal(t, other.ID, tournament.FeatureTable())
+	assert.Error(t, tournament.SetFeatureTable("unknown"))
+
+	require.NoError(t, tournament.SetFeatureTable(""))
+	assert.Equal(t, feature.ID, tournament.FeatureTable())
+}
+
+func TestTournamentFeatureMovesWhenTableBreaks(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	tournament, _ := startTestTournament(t, manager, testTournamentSetup("t1", 4), 8)
+	ctx := context.Background()
+
+	broken := tableOf(t, manager, "e0")
+	require.NoError(t, tournament.SetFeatureTable(broken.ID))
+	for _, playerID := range []string{"e0", "e2", "e4", "e6"} {
+		require.NoError(t, tournament.Eliminate(ctx, playerID))
+	}
+
+	require.Len(t, manager.GetTables(), 1)
+	assert.Equal(t, manager.GetTables()[0].ID, tournament.FeatureTable())
+}
+
+func TestTableWebSocketHandlerTournamentSpectating(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	hub := newRecordingHub()
+	handler := NewTableWebSocketHandler(manager, hub)
+	tournament, err := handler.CreateTournament(testTournamentSetup("t1", 4))
+	require.NoError(t, err)
+	require.NoError(t, tournament.Register("p1", "player1"))
+	require.NoError(t, tournament.Register("p2", "player2"))
+	require.NoError(t, tournament.Start(context.Background()))
+	defer tournament.Clock().Stop()
+
+	spectator := NewMockConnection("watcher", "watcher")
+	resp := handler.handleSpectateTournament(context.Background(), spectator, &WebSocketMessage{Data: map[string]interface{}{"tournament_id": "t1"}})
+	require.True(t, resp.Success, resp.Error)
+	assert.Equal(t, []string{TournamentRoomID("t1")}, spectator.rooms)
+	data := resp.Data.(map[string]interface{})
+	assert.Len(t, data["chip_leaders"], 2)
+	assert.Equal(t, manager.GetTables()[0].ID, data["feature_table_id"])
+	assert.Contains(t, data, "clock")
+
+	hub.mu.Lock()
+	messages := hub.messages[TournamentRoomID("t1")]
+	hub.mu.Unlock()
+	require.NotEmpty(t, messages, "tournament events reach the spectator room")
+	assert.Equal(t, "tournament_started", messages[0].(*WebSocketMessage).Type)
+
+	tournament.Clock().broadcast(tournament.Clock().Status())
+	hub.mu.Lock()
+	last := hub.messages[TournamentRoomID("t1")][len(hub.messages[TournamentRoomID("t1")])-1]
+	hub.mu.Unlock()
+	assert.Equal(t, "tournament_clock", last.(*WebSocketMessage).Type, "the clock reaches spectators")
+
+	resp = handler.handleStopSpectatingTournament(context.Background(), spectator, &WebSocketMessage{Data: map[string]interface{}{"tournament_id": "t1"}})
+	assert.True(t, resp.Success)
+	assert.Empty(t, spectator.rooms)
+}
@@ -0,0 +1,287 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTournamentSink struct {
+	mu     sync.Mutex
+	events []*TournamentEvent
+}
+
+func (s *recordingTournamentSink) OnTournamentEvent(event *TournamentEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *recordingTournamentSink) ofType(eventType string) []*TournamentEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matching := make([]*TournamentEvent, 0)
+	for _, event := range s.events {
+		if event.Type == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func testTournamentSetup(id string, seats int) TournamentConfig {
+	return TournamentConfig{
+		ID:            id,
+		Name:          "Sunday Major",
+		Settings:      TournamentSettings(),
+		StartingStack: 1500,
+		SeatsPerTable: seats,
+		EntryFee:      100,
+		Payouts:       []int{50, 30, 20},
+		Clock:         testTournamentConfig(),
+	}
+}
+
+func startTestTournament(t *testing.T, manager *ActorTableManager, config TournamentConfig, entrants int) (*Tournament, *recordingTournamentSink) {
+	sink := &recordingTournamentSink{}
+	tournament, err := NewTournament(config, manager, nil, sink)
+	require.NoError(t, err)
+	for i := 0; i < entrants; i++ {
+		playerID := fmt.Sprintf("e%d", i)
+		require.NoError(t, tournament.Register(playerID, "entrant"+playerID))
+	}
+	require.NoError(t, tournament.Start(context.Background()))
+	t.Cleanup(tournament.Clock().Stop)
+	return tournament, sink
+}
+
+func tournamentTableCounts(tournament *Tournament) []int {
+	tournament.mu.Lock()
+	defer tournament.mu.Unlock()
+	counts := make([]int, 0)
+	for _, table := range tournament.tableCountsLocked() {
+		counts = append(counts, table.GetPlayerCount())
+	}
+	return counts
+}
+
+func TestNewTournamentValidation(t *testing.T) {
+	manager := NewActorTableManager(nil)
+
+	config := testTournamentSetup("t1", 6)
+	config.Payouts = []int{60, 30}
+	_, err := NewTournament(config, manager, nil, nil)
+	assert.Error(t, err, "payouts must sum to 100")
+
+	config = testTournamentSetup("t1", 6)
+	config.StartingStack = 0
+	_, err = NewTournament(config, manager, nil, nil)
+	assert.Error(t, err)
+
+	config = testTournamentSetup("t1", 12)
+	_, err = NewTournament(config, manager, nil, nil)
+	assert.Error(t, err, "tables seat at most eight")
+}
+
+func TestCalculatePayoutsGivesRoundingToWinner(t *testing.T) {
+	assert.Equal(t, []int{501, 300, 200}, calculatePayouts(1001, []int{50, 30, 20}))
+	assert.Equal(t, []int{700}, calculatePayouts(700, []int{100}))
+}
+
+func TestTournamentStartSeatsFieldAcrossTables(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	tournament, sink := startTestTournament(t, manager, testTournamentSetup("t1", 5), 9)
+
+	assert.Equal(t, TournamentRunning, tournament.Status())
+	assert.Equal(t, []int{5, 4}, tournamentTableCounts(tournament))
+	assert.Equal(t, 900, tournament.PrizePool())
+
+	for _, table := range manager.GetTables() {
+		assert.True(t, table.Settings.TournamentMode)
+		assert.Equal(t, 25, table.Settings.SmallBlind, "clock applies the first level")
+		for _, slot := range table.PlayerSlots {
+			if slot.PlayerID != "" {
+				assert.Equal(t, 1500, slot.Chips)
+			}
+		}
+	}
+
+	started := sink.ofType("tournament_started")
+	require.Len(t, started, 1)
+	assert.Len(t, started[0].Rooms, 3)
+	assert.Contains(t, started[0].Rooms, TournamentRoomID("t1"), "spectators follow every tournament event")
+	assert.Equal(t, []int{450, 270, 180}, started[0].Data["payouts"])
+
+	assert.Error(t, tournament.Register("late", "late"), "registration closes at the start")
+}
+
+func TestTournamentStartNeedsEntrants(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	tournament, err := NewTournament(testTournamentSetup("t1", 6), manager, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, tournament.Register("solo", "solo"))
+
+	assert.Error(t, tournament.Start(context.Background()))
+	assert.Empty(t, manager.GetTables())
+}
+
+func TestTournamentEliminationRebalancesAndPays(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	tournament, sink := startTestTournament(t, manager, testTournamentSetup("t1", 4), 8)
+	ctx := context.Background()
+	assert.Equal(t, []int{4, 4}, tournamentTableCounts(tournament))
+
+	// e0, e2, e4, e6 sit at the first table
+	require.NoError(t, tournament.Eliminate(ctx, "e0"))
+	require.NoError(t, tournament.Eliminate(ctx, "e2"))
+	assert.Equal(t, []int{3, 3}, tournamentTableCounts(tournament), "a player moves to the short table")
+	assert.NotEmpty(t, sink.ofType("tournament_player_moved"))
+
+	require.NoError(t, tournament.Eliminate(ctx, "e4"))
+	require.NoError(t, tournament.Eliminate(ctx, "e6"))
+	assert.Equal(t, []int{4}, tournamentTableCounts(tournament), "four players fit on one table")
+	assert.Len(t, sink.ofType("tournament_table_broken"), 1)
+	assert.Len(t, manager.GetTables(), 1)
+
+	require.NoError(t, tournament.Eliminate(ctx, "e1"))
+	require.NoError(t, tournament.Eliminate(ctx, "e3"))
+	require.NoError(t, tournament.Eliminate(ctx, "e5"))
+	assert.Equal(t, TournamentFinished, tournament.Status())
+	assert.Error(t, tournament.Eliminate(ctx, "e7"))
+
+	standings := tournament.Standings()
+	require.Len(t, standings, 8)
+	assert.Equal(t, TournamentEntrant{PlayerID: "e7", Username: "entrante7", TableID: standings[0].TableID, Position: 1, Payout: 400}, standings[0])
+	assert.Equal(t, "e5", standings[1].PlayerID)
+	assert.Equal(t, 240, standings[1].Payout)
+	assert.Equal(t, "e3", standings[2].PlayerID)
+	assert.Equal(t, 160, standings[2].Payout)
+	assert.Equal(t, "e0", standings[7].PlayerID)
+	assert.Zero(t, standings[7].Payout)
+
+	finished := sink.ofType("tournament_finished")
+	require.Len(t, finished, 1)
+	assert.Equal(t, 800, finished[0].Data["prize_pool"])
+}
+
+// reentrantTournamentSink reads the tournament back while handling its events,
+// as ledger-backed sinks and their managers do
+type reentrantTournamentSink struct {
+	recordingTournamentSink
+	tournament *Tournament
+	standings  []int
+}
+
+func (s *reentrantTournamentSink) OnTournamentEvent(event *TournamentEvent) {
+	s.recordingTournamentSink.OnTournamentEvent(event)
+	if s.tournament != nil {
+		s.standings = append(s.standings, len(s.tournament.Standings()))
+	}
+}
+
+func TestTournamentDeliversEventsOutsideItsLock(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	sink := &reentrantTournamentSink{}
+	tournament, err := NewTournament(testTournamentSetup("t1", 4), manager, nil, sink)
+	require.NoError(t, err)
+	sink.tournament = tournament
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tournament.Register(fmt.Sprintf("e%d", i), "entrant"))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx := context.Background()
+		require.NoError(t, tournament.Start(ctx))
+		require.NoError(t, tournament.Eliminate(ctx, "e0"))
+		require.NoError(t, tournament.Eliminate(ctx, "e1"))
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a sink calling back into the tournament deadlocked")
+	}
+	t.Cleanup(tournament.Clock().Stop)
+
+	types := make([]string, 0)
+	for _, event := range sink.events {
+		if event.Type != "tournament_chip_leaders" && event.Type != "tournament_feature_table" {
+			types = append(types, event.Type)
+		}
+	}
+	assert.Equal(t, []string{"tournament_started", "tournament_player_eliminated", "tournament_player_eliminated", "tournament_finished"}, types, "events keep their order")
+	assert.Len(t, sink.standings, len(sink.events))
+}
+
+func TestTournamentEliminatesBustedPlayersAfterHand(t *testing.T) {
+	manager := NewActorTableManager(&TexasHoldemEngineFactory{})
+	tournament, sink := startTestTournament(t, manager, testTournamentSetup("t1", 4), 4)
+
+	tables := manager.GetTables()
+	require.Len(t, tables, 1)
+	table := tables[0]
+	engine := table.GameEngine.(*TexasHoldemEngine)
+	stacks := map[string]int{"e0": 0, "e1": 0, "e2": 3000, "e3": 3000}
+	for playerID, chips := range stacks {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Data: map[string]interface{}{"chips": chips}}))
+	}
+
+	manager.notifyHandListeners(table, []HandResult{
+		{PlayerID: "e0", Invested: 1500, Net: -1500},
+		{PlayerID: "e1", Invested: 900, Net: -900},
+		{PlayerID: "e2", Invested: 1500, Collected: 3000, Net: 1500},
+		{PlayerID: "e3", Invested: 600, Collected: 2100, Net: 1500},
+	})
+
+	eliminated := sink.ofType("tournament_player_eliminated")
+	require.Len(t, eliminated, 2)
+	assert.Equal(t, "e1", eliminated[0].Data["player_id"], "the shorter stack finishes lower")
+	assert.Equal(t, 4, eliminated[0].Data["position"])
+	assert.Equal(t, "e0", eliminated[1].Data["player_id"])
+	assert.Equal(t, 3, eliminated[1].Data["position"])
+	assert.Equal(t, 80, eliminated[1].Data["payout"], "third of four takes 20% of 400")
+	assert.False(t, table.IsPlayerAtTable("e0"))
+	assert.Equal(t, TournamentRunning, tournament.Status())
+}
+
+func TestTableWebSocketHandlerBroadcastsTournamentEvents(t *testing.T) {
+	manager := NewActorTableManager(nil)
+	hub := newRecordingHub()
+	handler := NewTableWebSocketHandler(manager, hub)
+
+	tournament, err := handler.CreateTournament(testTournamentSetup("t1", 4))
+	require.NoError(t, err)
+	_, err = handler.CreateTournament(testTournamentSetup("t1", 4))
+	assert.Error(t, err)
+
+	conn := NewMockConnection("p1", "player1")
+	resp := handler.handleTournamentRegister(context.Background(), conn, &WebSocketMessage{Data: map[string]interface{}{"tournament_id": "t1"}})
+	assert.True(t, resp.Success)
+	require.NoError(t, tournament.Register("p2", "player2"))
+	require.NoError(t, tournament.Start(context.Background()))
+	defer tournament.Clock().Stop()
+
+	table := manager.GetTables()[0]
+	hub.mu.Lock()
+	messages := hub.messages[table.RoomID]
+	hub.mu.Unlock()
+	require.NotEmpty(t, messages)
+	msg, ok := messages[len(messages)-1].(*WebSocketMessage)
+	require.True(t, ok)
+	assert.Equal(t, "tournament_started", msg.Type)
+
+	resp = handler.handleGetTournament(context.Background(), conn, &WebSocketMessage{Data: map[string]interface{}{"tournament_id": "t1"}})
+	require.True(t, resp.Success)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, TournamentRunning, data["status"])
+	assert.Contains(t, data, "clock")
+
+	resp = handler.handleGetTournament(context.Background(), conn, &WebSocketMessage{Data: map[string]interface{}{"tournament_id": "missing"}})
+	assert.False(t, resp.Success)
+}
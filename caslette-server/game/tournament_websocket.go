@@ -0,0 +1,161 @@
+package game
+
+import (
+	"context"
+	"log"
+)
+
+// CreateTournament sets up a tournament whose clock and events are broadcast
+// to its table rooms by this handler
+func (h *TableWebSocketHandler) CreateTournament(config TournamentConfig) (*Tournament, error) {
+	h.tournamentsMu.Lock()
+	defer h.tournamentsMu.Unlock()
+
+	if _, exists := h.tournaments[config.ID]; exists {
+		return nil, &TableError{"TOURNAMENT_EXISTS", "A tournament with this ID already exists"}
+	}
+	tournament, err := NewTournament(config, h.tableManager, h.hub, h)
+	if err != nil {
+		return nil, err
+	}
+	h.tournaments[config.ID] = tournament
+	return tournament, nil
+}
+
+// GetTournament returns a tournament created by this handler or the
+// tournament running a Sit&Go
+func (h *TableWebSocketHandler) GetTournament(tournamentID string) (*Tournament, bool) {
+	h.tournamentsMu.RLock()
+	tournament, ok := h.tournaments[tournamentID]
+	h.tournamentsMu.RUnlock()
+	if ok {
+		return tournament, true
+	}
+	if sitAndGo, ok := h.tableManager.GetSitAndGo(tournamentID); ok {
+		return sitAndGo.Tournament(), true
+	}
+	return nil, false
+}
+
+// OnTournamentEvent broadcasts a tournament event to its table rooms
+func (h *TableWebSocketHandler) OnTournamentEvent(event *TournamentEvent) {
+	if h.hub == nil {
+		return
+	}
+	for _, roomID := range event.Rooms {
+		msg := &WebSocketMessage{
+			Type:    event.Type,
+			Data:    event,
+			Room:    roomID,
+			Success: true,
+		}
+		if err := h.hub.BroadcastToRoom(roomID, msg); err != nil {
+			log.Printf("Failed to broadcast %s to room %s: %v", event.Type, roomID, err)
+		}
+	}
+}
+
+// tournamentRequest identifies the tournament a message refers to
+type tournamentRequest struct {
+	TournamentID string `json:"tournament_id"`
+}
+
+// lookupTournament parses a tournament request and finds the tournament
+func (h *TableWebSocketHandler) lookupTournament(msg *WebSocketMessage) (*Tournament, *WebSocketMessage) {
+	var req tournamentRequest
+	if err := h.parseMessageData(msg.Data, &req); err != nil {
+		return nil, h.errorResponse(msg.RequestID, "INVALID_DATA", "Invalid request data: "+err.Error())
+	}
+	tournament, ok := h.GetTournament(req.TournamentID)
+	if !ok {
+		return nil, h.errorResponse(msg.RequestID, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+	}
+	return tournament, nil
+}
+
+// handleTournamentRegister enters the caller into a tournament
+func (h *TableWebSocketHandler) handleTournamentRegister(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	tournament, errResp := h.lookupTournament(msg)
+	if errResp != nil {
+		return errResp
+	}
+	if err := tournament.Register(conn.GetUserID(), conn.GetUsername()); err != nil {
+		return h.errorResponse(msg.RequestID, "REGISTER_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "tournament_registered", map[string]interface{}{
+		"tournament_id": tournament.ID(),
+	})
+}
+
+// handleTournamentUnregister withdraws the caller before the tournament starts
+func (h *TableWebSocketHandler) handleTournamentUnregister(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	tournament, errResp := h.lookupTournament(msg)
+	if errResp != nil {
+		return errResp
+	}
+	if err := tournament.Unregister(conn.GetUserID()); err != nil {
+		return h.errorResponse(msg.RequestID, "UNREGISTER_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "tournament_unregistered", map[string]interface{}{
+		"tournament_id": tournament.ID(),
+	})
+}
+
+// handleGetTournament returns a tournament's standings, prize pool and clock
+func (h *TableWebSocketHandler) handleGetTournament(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	tournament, errResp := h.lookupTournament(msg)
+	if errResp != nil {
+		return errResp
+	}
+	data := map[string]interface{}{
+		"tournament_id": tournament.ID(),
+		"status":        tournament.Status(),
+		"prize_pool":    tournament.PrizePool(),
+		"standings":     tournament.Standings(),
+	}
+	if tournament.Status() != TournamentRegistering {
+		data["clock"] = tournament.Clock().Status()
+	}
+	return h.successResponse(msg.RequestID, "tournament_info", data)
+}
+
+// handleSpectateTournament joins the caller to the tournament's spectator
+// room and returns what has happened so far
+func (h *TableWebSocketHandler) handleSpectateTournament(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	tournament, errResp := h.lookupTournament(msg)
+	if errResp != nil {
+		return errResp
+	}
+	roomID := TournamentRoomID(tournament.ID())
+	if err := conn.JoinRoom(roomID); err != nil {
+		return h.errorResponse(msg.RequestID, "SPECTATE_FAILED", err.Error())
+	}
+
+	data := map[string]interface{}{
+		"tournament_id":    tournament.ID(),
+		"room_id":          roomID,
+		"status":           tournament.Status(),
+		"prize_pool":       tournament.PrizePool(),
+		"standings":        tournament.Standings(),
+		"chip_leaders":     tournament.ChipLeaders(),
+		"feature_table_id": tournament.FeatureTable(),
+	}
+	if tournament.Status() != TournamentRegistering {
+		data["clock"] = tournament.Clock().Status()
+	}
+	return h.successResponse(msg.RequestID, "tournament_spectating", data)
+}
+
+// handleStopSpectatingTournament leaves the tournament's spectator room
+func (h *TableWebSocketHandler) handleStopSpectatingTournament(ctx context.Context, conn WebSocketConnection, msg *WebSocketMessage) *WebSocketMessage {
+	tournament, errResp := h.lookupTournament(msg)
+	if errResp != nil {
+		return errResp
+	}
+	if err := conn.LeaveRoom(TournamentRoomID(tournament.ID())); err != nil {
+		return h.errorResponse(msg.RequestID, "LEAVE_FAILED", err.Error())
+	}
+	return h.successResponse(msg.RequestID, "tournament_spectating_stopped", map[string]interface{}{
+		"tournament_id": tournament.ID(),
+	})
+}
@@ -0,0 +1,291 @@
+package game
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultTurnTick is how often a running turn timer broadcasts its countdown
+const DefaultTurnTick = time.Second
+
+// DisconnectedTurnLimit is the most time a disconnected player is given to
+// act, timer or not, so the hand does not wait out their whole turn
+const DisconnectedTurnLimit = 10 * time.Second
+
+// turnTimer counts down the turn of the player to act
+type turnTimer struct {
+	playerID  string
+	deadline  time.Time
+	bankStart time.Time // When the player started drawing on their time bank
+	stop      chan struct{}
+}
+
+// SetTurnTimeLimit sets how long a player has to act before they are checked
+// or folded automatically. Zero disables the timer.
+func (the *TexasHoldemEngine) SetTurnTimeLimit(limit time.Duration) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	the.turnLimit = limit
+	if the.turnTick <= 0 {
+		the.turnTick = DefaultTurnTick
+	}
+}
+
+// SetTimeBank gives every player a reserve of extra time that is drawn on
+// once the turn limit runs out. The bank carries over between hands until it
+// is spent. Zero disables it.
+func (the *TexasHoldemEngine) SetTimeBank(bank time.Duration) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if bank < 0 {
+		bank = 0
+	}
+	the.timeBank = bank
+	the.timeBanks = make(map[string]time.Duration)
+}
+
+// TimeBank returns how much of the player's time bank is left
+func (the *TexasHoldemEngine) TimeBank(playerID string) time.Duration {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	return the.bankFor(playerID)
+}
+
+// bankFor returns the player's remaining time bank; the caller must hold actionMu
+func (the *TexasHoldemEngine) bankFor(playerID string) time.Duration {
+	if remaining, ok := the.timeBanks[playerID]; ok {
+		return remaining
+	}
+	return the.timeBank
+}
+
+// Pause stops the turn timer and any runout along with the game
+func (the *TexasHoldemEngine) Pause() error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if err := the.BaseGameEngine.Pause(); err != nil {
+		return err
+	}
+	the.stopTurnTimer()
+	the.stopRunout()
+	return nil
+}
+
+// Resume continues the game and gives the player to act a fresh timer, or
+// carries on running the board out
+func (the *TexasHoldemEngine) Resume() error {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if err := the.BaseGameEngine.Resume(); err != nil {
+		return err
+	}
+	return the.continueHand()
+}
+
+// SetPlayerDisconnected marks a player's connection as dropped or back. A
+// disconnected player gets at most DisconnectedTurnLimit to act, even at
+// tables without a turn timer, and is then checked or folded without
+// drawing on their time bank.
+func (the *TexasHoldemEngine) SetPlayerDisconnected(playerID string, disconnected bool) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+	if the.disconnected == nil {
+		the.disconnected = make(map[string]bool)
+	}
+	if !disconnected {
+		delete(the.disconnected, playerID)
+		return
+	}
+	the.disconnected[playerID] = true
+
+	// Cut short the turn of a player who drops while it is theirs
+	if the.GetState() != GameStateInProgress || the.getCurrentActionPlayerID() != playerID {
+		return
+	}
+	if the.turn == nil || time.Until(the.turn.deadline) > the.disconnectedLimit() {
+		the.startTurnTimer()
+	}
+}
+
+// disconnectedLimit returns the most time a disconnected player has to act
+func (the *TexasHoldemEngine) disconnectedLimit() time.Duration {
+	if the.offlineLimit > 0 {
+		return the.offlineLimit
+	}
+	return DisconnectedTurnLimit
+}
+
+// turnLimitFor returns how long the player has to act; the caller must hold
+// actionMu
+func (the *TexasHoldemEngine) turnLimitFor(playerID string) time.Duration {
+	offline := the.disconnectedLimit()
+	if the.disconnected[playerID] && (the.turnLimit <= 0 || the.turnLimit > offline) {
+		return offline
+	}
+	return the.turnLimit
+}
+
+// startTurnTimer replaces any running timer with one for the player now to
+// act; the caller must hold actionMu
+func (the *TexasHoldemEngine) startTurnTimer() {
+	the.stopTurnTimer()
+	if the.GetState() != GameStateInProgress {
+		return
+	}
+	playerID := the.getCurrentActionPlayerID()
+	player := the.getHoldemPlayer(playerID)
+	if player == nil || player.HasFolded || player.IsAllIn {
+		return
+	}
+	limit := the.turnLimitFor(playerID)
+	if limit <= 0 {
+		return
+	}
+
+	timer := &turnTimer{
+		playerID: playerID,
+		deadline: time.Now().Add(limit),
+		stop:     make(chan struct{}),
+	}
+	the.turn = timer
+	the.emitEvent(&GameEvent{
+		Type:     "turn_timer_started",
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"playerID":  playerID,
+			"timeLimit": int(limit / time.Second),
+			"timeBank":  wholeSeconds(the.bankFor(playerID)),
+			"expiresAt": timer.deadline,
+		},
+	})
+	tick := the.turnTick
+	if tick <= 0 {
+		// Tables without a turn timer still time out disconnected players
+		tick = DefaultTurnTick
+	}
+	go the.runTurnTimer(timer, tick)
+}
+
+// stopTurnTimer cancels the running timer, charging any time bank the player
+// used; the caller must hold actionMu
+func (the *TexasHoldemEngine) stopTurnTimer() {
+	if the.turn == nil {
+		return
+	}
+	timer := the.turn
+	close(timer.stop)
+	the.turn = nil
+	if timer.bankStart.IsZero() {
+		return
+	}
+
+	remaining := max(the.bankFor(timer.playerID)-time.Since(timer.bankStart), 0)
+	the.timeBanks[timer.playerID] = remaining
+	the.emitEvent(&GameEvent{
+		Type:     "time_bank_updated",
+		PlayerID: timer.playerID,
+		Data: map[string]interface{}{
+			"playerID":  timer.playerID,
+			"remaining": wholeSeconds(remaining),
+		},
+	})
+}
+
+// wholeSeconds rounds a duration up to whole seconds for countdown displays
+func wholeSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// runTurnTimer broadcasts the countdown and acts for the player once their
+// time is up
+func (the *TexasHoldemEngine) runTurnTimer(timer *turnTimer, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	expiry := time.NewTimer(time.Until(timer.deadline))
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-timer.stop:
+			return
+		case now := <-ticker.C:
+			the.actionMu.Lock()
+			if the.turn == timer {
+				// Time bank countdowns are told apart so clients can render them
+				eventType := "turn_timer_tick"
+				if !timer.bankStart.IsZero() {
+					eventType = "time_bank_tick"
+				}
+				the.emitEvent(&GameEvent{
+					Type:     eventType,
+					PlayerID: timer.playerID,
+					Data: map[string]interface{}{
+						"playerID":  timer.playerID,
+						"remaining": wholeSeconds(timer.deadline.Sub(now)),
+					},
+				})
+			}
+			the.actionMu.Unlock()
+		case <-expiry.C:
+			deadline, extended := the.expireTurn(timer)
+			if !extended {
+				return
+			}
+			expiry.Reset(time.Until(deadline))
+		}
+	}
+}
+
+// expireTurn draws on the player's time bank when the turn limit runs out,
+// returning the extended deadline. Once the bank is spent too, or at once for
+// a disconnected player, it checks for the player when they owe nothing and
+// folds them otherwise.
+func (the *TexasHoldemEngine) expireTurn(timer *turnTimer) (time.Time, bool) {
+	the.actionMu.Lock()
+	defer the.actionMu.Unlock()
+
+	if the.turn != timer {
+		return time.Time{}, false
+	}
+	if bank := the.bankFor(timer.playerID); timer.bankStart.IsZero() && bank > 0 && !the.disconnected[timer.playerID] {
+		timer.bankStart = time.Now()
+		timer.deadline = timer.bankStart.Add(bank)
+		the.emitEvent(&GameEvent{
+			Type:     "time_bank_activated",
+			PlayerID: timer.playerID,
+			Data: map[string]interface{}{
+				"playerID":  timer.playerID,
+				"remaining": wholeSeconds(bank),
+				"expiresAt": timer.deadline,
+			},
+		})
+		return timer.deadline, true
+	}
+	if !timer.bankStart.IsZero() {
+		the.timeBanks[timer.playerID] = 0
+	}
+	the.turn = nil
+
+	action := &GameAction{
+		Type:     "texas_holdem_action",
+		PlayerID: timer.playerID,
+		Data:     map[string]interface{}{"action": string(ActionCheck)},
+	}
+	if the.IsValidAction(action) != nil {
+		action.Data["action"] = string(ActionFold)
+	}
+
+	the.emitEvent(&GameEvent{
+		Type:     "turn_timed_out",
+		PlayerID: timer.playerID,
+		Data: map[string]interface{}{
+			"playerID": timer.playerID,
+			"action":   action.Data["action"],
+		},
+	})
+	if _, err := the.processAction(context.Background(), action); err != nil {
+		log.Printf("TexasHoldemEngine: failed to act for timed-out player %s: %v", timer.playerID, err)
+	}
+	return time.Time{}, false
+}
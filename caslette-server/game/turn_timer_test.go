@@ -0,0 +1,203 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timerEvents collects engine events, which subscribers receive on their
+// own goroutines and so possibly out of order
+type timerEvents struct {
+	mu     sync.Mutex
+	events []*GameEvent
+}
+
+func (e *timerEvents) record(event *GameEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *timerEvents) find(eventType string, match func(*GameEvent) bool) []*GameEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	found := make([]*GameEvent, 0)
+	for _, event := range e.events {
+		if event.Type == eventType && (match == nil || match(event)) {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+// newTimedHeadsUp starts a heads-up hand with a short turn timer and returns
+// the engine with a log of its events
+func newTimedHeadsUp(t *testing.T, limit time.Duration) (*TexasHoldemEngine, *timerEvents) {
+	engine := NewTexasHoldemEngine("timer_game")
+	engine.SetTurnTimeLimit(limit)
+	engine.turnTick = 10 * time.Millisecond
+	events := &timerEvents{}
+	engine.SubscribeToEvents(events.record)
+	for i, playerID := range []string{"p1", "p2"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Position: i}))
+	}
+	require.NoError(t, engine.Start())
+	return engine, events
+}
+
+// waitForEvent waits until an event of the given type matching match arrives
+func waitForEvent(t *testing.T, events *timerEvents, eventType string, match func(*GameEvent) bool) *GameEvent {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if found := events.find(eventType, match); len(found) > 0 {
+			return found[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no %s event", eventType)
+	return nil
+}
+
+func forPlayer(playerID string) func(*GameEvent) bool {
+	return func(event *GameEvent) bool { return event.PlayerID == playerID }
+}
+
+func actFor(t *testing.T, engine *TexasHoldemEngine, action string) string {
+	engine.actionMu.Lock()
+	playerID := engine.getCurrentActionPlayerID()
+	engine.actionMu.Unlock()
+	_, err := engine.ProcessAction(context.Background(), &GameAction{
+		Type: "texas_holdem_action", PlayerID: playerID, Data: map[string]interface{}{"action": action},
+	})
+	require.NoError(t, err)
+	return playerID
+}
+
+func TestTurnTimerFoldsPlayerFacingABet(t *testing.T) {
+	_, events := newTimedHeadsUp(t, 50*time.Millisecond)
+
+	started := waitForEvent(t, events, "turn_timer_started", nil)
+	assert.Equal(t, 0, started.Data["timeLimit"], "limits under a second round down")
+	waitForEvent(t, events, "turn_timer_tick", forPlayer(started.PlayerID))
+
+	timedOut := waitForEvent(t, events, "turn_timed_out", nil)
+	assert.Equal(t, started.PlayerID, timedOut.PlayerID)
+	assert.Equal(t, "fold", timedOut.Data["action"], "the blind must be called, so the player folds")
+	waitForEvent(t, events, "player_folded", forPlayer(started.PlayerID))
+	waitForEvent(t, events, "pot_distributed", nil)
+}
+
+func TestTurnTimerChecksWhenNothingIsOwed(t *testing.T) {
+	engine, events := newTimedHeadsUp(t, 80*time.Millisecond)
+
+	caller := actFor(t, engine, "call")
+	next := waitForEvent(t, events, "turn_timer_started", func(event *GameEvent) bool { return event.PlayerID != caller })
+
+	timedOut := waitForEvent(t, events, "turn_timed_out", nil)
+	assert.Equal(t, next.PlayerID, timedOut.PlayerID)
+	assert.Equal(t, "check", timedOut.Data["action"], "the big blind has nothing to call")
+	waitForEvent(t, events, "flop_dealt", nil)
+}
+
+func TestTurnTimerStopsWhenPlayerActs(t *testing.T) {
+	engine, events := newTimedHeadsUp(t, 150*time.Millisecond)
+
+	caller := actFor(t, engine, "call")
+	waitForEvent(t, events, "turn_timer_started", func(event *GameEvent) bool { return event.PlayerID != caller })
+
+	timedOut := waitForEvent(t, events, "turn_timed_out", nil)
+	assert.NotEqual(t, caller, timedOut.PlayerID, "the player who acted is never timed out")
+}
+
+func TestTurnTimerPausesWithTheGame(t *testing.T) {
+	engine, events := newTimedHeadsUp(t, 40*time.Millisecond)
+	waitForEvent(t, events, "turn_timer_started", nil)
+
+	require.NoError(t, engine.Pause())
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, events.find("turn_timed_out", nil))
+
+	require.NoError(t, engine.Resume())
+	waitForEvent(t, events, "turn_timed_out", nil)
+	assert.Len(t, events.find("turn_timer_started", nil), 2, "resuming restarts the countdown")
+}
+
+func TestTurnTimerDisabledWithoutLimit(t *testing.T) {
+	_, events := newTimedHeadsUp(t, 0)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, events.find("turn_timer_started", nil))
+}
+
+func TestFactoryAppliesTableTimeLimit(t *testing.T) {
+	settings := DefaultTableSettings()
+	settings.TimeLimit = 12
+	settings.TimeBank = 60
+
+	engine, err := (&TexasHoldemEngineFactory{}).CreateEngine(GameTypeTexasHoldem, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Second, engine.(*TexasHoldemEngine).turnLimit)
+	assert.Equal(t, time.Minute, engine.(*TexasHoldemEngine).TimeBank("anyone"))
+
+	engine, err = (&TexasHoldemEngineFactory{}).CreateEngine(GameTypeOmaha, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Second, engine.(*OmahaEngine).turnLimit)
+}
+
+// newBankedHeadsUp starts a heads-up hand whose players have a time bank
+func newBankedHeadsUp(t *testing.T, limit, bank time.Duration) (*TexasHoldemEngine, *timerEvents) {
+	engine := NewTexasHoldemEngine("time_bank_game")
+	engine.SetTurnTimeLimit(limit)
+	engine.SetTimeBank(bank)
+	engine.turnTick = 10 * time.Millisecond
+	events := &timerEvents{}
+	engine.SubscribeToEvents(events.record)
+	for i, playerID := range []string{"p1", "p2"} {
+		require.NoError(t, engine.AddPlayer(&Player{ID: playerID, Name: playerID, Position: i}))
+	}
+	require.NoError(t, engine.Start())
+	return engine, events
+}
+
+func TestTimeBankExtendsTurnBeforeTimingOut(t *testing.T) {
+	engine, events := newBankedHeadsUp(t, 30*time.Millisecond, 60*time.Millisecond)
+
+	started := waitForEvent(t, events, "turn_timer_started", nil)
+	assert.Equal(t, 1, started.Data["timeBank"], "the bank is announced with the turn")
+	activated := waitForEvent(t, events, "time_bank_activated", forPlayer(started.PlayerID))
+	assert.Equal(t, 1, activated.Data["remaining"])
+	assert.Empty(t, events.find("turn_timed_out", nil), "the bank runs before the player is acted for")
+
+	waitForEvent(t, events, "time_bank_tick", forPlayer(started.PlayerID))
+	timedOut := waitForEvent(t, events, "turn_timed_out", nil)
+	assert.Equal(t, started.PlayerID, timedOut.PlayerID)
+	assert.Zero(t, engine.TimeBank(started.PlayerID), "an exhausted bank stays spent")
+	assert.Equal(t, 60*time.Millisecond, engine.TimeBank(otherPlayer(started.PlayerID)))
+}
+
+func TestTimeBankChargesOnlyTheTimeUsed(t *testing.T) {
+	engine, events := newBankedHeadsUp(t, 20*time.Millisecond, time.Second)
+
+	started := waitForEvent(t, events, "turn_timer_started", nil)
+	waitForEvent(t, events, "time_bank_activated", forPlayer(started.PlayerID))
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, started.PlayerID, actFor(t, engine, "call"))
+
+	updated := waitForEvent(t, events, "time_bank_updated", forPlayer(started.PlayerID))
+	assert.Equal(t, 1, updated.Data["remaining"])
+	left := engine.TimeBank(started.PlayerID)
+	assert.Less(t, left, time.Second-20*time.Millisecond)
+	assert.Greater(t, left, 500*time.Millisecond)
+	assert.Empty(t, events.find("turn_timed_out", forPlayer(started.PlayerID)))
+}
+
+func otherPlayer(playerID string) string {
+	if playerID == "p1" {
+		return "p2"
+	}
+	return "p1"
+}
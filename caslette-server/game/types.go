@@ -50,6 +50,8 @@ type TableJoinRequest struct {
 	Mode     TableJoinMode `json:"mode"`               // player or observer
 	Position int           `json:"position,omitempty"` // specific position (optional)
 	Password string        `json:"password,omitempty"` // for private tables
+	BuyIn    int           `json:"buy_in,omitempty"`   // chips to sit with; defaults to the minimum allowed
+	Prepaid  bool          `json:"-"`                  // buy-in taken, and paid back, by the caller, such as the heads-up queue
 }
 
 // TableLeaveRequest represents a request to leave a table
@@ -67,6 +69,7 @@ type TableCreateRequest struct {
 	Settings    TableSettings `json:"settings"`
 	Description string        `json:"description,omitempty"`
 	Tags        []string      `json:"tags,omitempty"`
+	Sanctioned  bool          `json:"-"` // Created by the system (tournaments, matchmaking); skips admin approval
 }
 
 // UserLimitState tracks rate limiting state for a user
@@ -19,12 +19,63 @@ func ConvertMapToStruct(data map[string]interface{}, target interface{}) error {
 	return nil
 }
 
-// BroadcastGameEvent is a method that should be added to ActorTableManager
-// For now, this is a placeholder implementation since the webhook system is not fully implemented
+// BroadcastGameEvent forwards a game event to the configured broadcaster.
+// Without one (e.g. in tests) events are not delivered to clients.
 func (tm *ActorTableManager) BroadcastGameEvent(table *GameTable, event *GameEvent) {
-	// This would broadcast game events to WebSocket clients
-	// Currently a no-op since the webhook handler system is not fully implemented
-	// TODO: Implement proper event broadcasting when needed
+	tm.mu.RLock()
+	broadcaster := tm.eventBroadcaster
+	tm.mu.RUnlock()
+
+	if broadcaster == nil || table == nil || event == nil {
+		return
+	}
+	// Events raised around the engine during a hand belong to it too; the
+	// engine's own, which carry sequence numbers, are stamped as emitted
+	if identifier, ok := table.GameEngine.(LiveHandIdentifier); ok && event.Sequence == 0 && event.HandID == "" {
+		event.HandID = identifier.LiveHandID()
+	}
+	broadcaster.OnGameEvent(table, event)
+}
+
+// SetEventBroadcaster sets where game events of managed tables are delivered
+func (tm *ActorTableManager) SetEventBroadcaster(broadcaster GameEventBroadcaster) {
+	tm.mu.Lock()
+	tm.eventBroadcaster = broadcaster
+	tm.mu.Unlock()
+}
+
+// GameEventListener is told every game event of a managed table, after it
+// has been handed to the broadcaster
+type GameEventListener func(table *GameTable, event *GameEvent)
+
+// AddGameEventListener registers a listener for game events and returns a
+// function that removes it
+func (tm *ActorTableManager) AddGameEventListener(listener GameEventListener) func() {
+	tm.mu.Lock()
+	id := tm.nextListenerID
+	tm.nextListenerID++
+	tm.gameListeners[id] = listener
+	tm.mu.Unlock()
+
+	return func() {
+		tm.mu.Lock()
+		delete(tm.gameListeners, id)
+		tm.mu.Unlock()
+	}
+}
+
+// notifyGameEventListeners passes a game event to every listener
+func (tm *ActorTableManager) notifyGameEventListeners(table *GameTable, event *GameEvent) {
+	tm.mu.RLock()
+	listeners := make([]GameEventListener, 0, len(tm.gameListeners))
+	for _, listener := range tm.gameListeners {
+		listeners = append(listeners, listener)
+	}
+	tm.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(table, event)
+	}
 }
 
 // GameEventBroadcaster interface for broadcasting game events
@@ -0,0 +1,214 @@
+// Package geo maps client IP addresses to regions and decides which
+// activities are allowed from each jurisdiction.
+//
+// Regions are ISO 3166 codes: a country ("FR") or a country subdivision
+// ("US-NV"). Blocking a country also blocks all of its subdivisions.
+package geo
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Action is an activity subject to jurisdiction rules
+type Action string
+
+const (
+	ActionRegister  Action = "register"   // Creating an account
+	ActionRealMoney Action = "real_money" // Sitting at diamond-backed tables
+)
+
+// UnknownRegion is reported for addresses no range covers
+const UnknownRegion = ""
+
+// Resolver maps a client IP to a region code
+type Resolver interface {
+	Resolve(ip string) string
+}
+
+// regionRange is one CIDR block assigned to a region
+type regionRange struct {
+	network *net.IPNet
+	region  string
+}
+
+// RangeResolver resolves regions from a table of CIDR blocks. When blocks
+// overlap, the most specific one wins.
+type RangeResolver struct {
+	ranges []regionRange
+}
+
+// NewRangeResolver builds a resolver from CIDR block to region code
+func NewRangeResolver(blocks map[string]string) (*RangeResolver, error) {
+	resolver := &RangeResolver{}
+	for cidr, region := range blocks {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		code := NormalizeRegion(region)
+		if code == UnknownRegion {
+			return nil, fmt.Errorf("missing region for %s", cidr)
+		}
+		resolver.ranges = append(resolver.ranges, regionRange{network: network, region: code})
+	}
+
+	sort.Slice(resolver.ranges, func(i, j int) bool {
+		si, _ := resolver.ranges[i].network.Mask.Size()
+		sj, _ := resolver.ranges[j].network.Mask.Size()
+		if si != sj {
+			return si > sj
+		}
+		return resolver.ranges[i].network.String() < resolver.ranges[j].network.String()
+	})
+	return resolver, nil
+}
+
+// LoadRangeFile reads "cidr,region" lines from a file. Blank lines and lines
+// starting with # are ignored.
+func LoadRangeFile(path string) (*RangeResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocks := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.Split(text, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected cidr,region", path, line)
+		}
+		blocks[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewRangeResolver(blocks)
+}
+
+// Resolve returns the region for an IP, or UnknownRegion
+func (r *RangeResolver) Resolve(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return UnknownRegion
+	}
+	for _, block := range r.ranges {
+		if block.network.Contains(parsed) {
+			return block.region
+		}
+	}
+	return UnknownRegion
+}
+
+// RestrictedError reports an action refused in the client's region
+type RestrictedError struct {
+	Action Action
+	Region string
+}
+
+func (e *RestrictedError) Error() string {
+	switch e.Action {
+	case ActionRegister:
+		return fmt.Sprintf("registration is not available in your region (%s)", e.Region)
+	case ActionRealMoney:
+		return fmt.Sprintf("real-money play is not available in your region (%s)", e.Region)
+	}
+	return fmt.Sprintf("%s is not available in your region (%s)", e.Action, e.Region)
+}
+
+// Policy decides which actions are allowed from which regions. A nil
+// policy, or one without a resolver, allows everything.
+type Policy struct {
+	resolver Resolver
+	blocked  map[Action]map[string]bool
+}
+
+// NewPolicy creates a policy blocking the given regions per action
+func NewPolicy(resolver Resolver, blocked map[Action][]string) *Policy {
+	policy := &Policy{
+		resolver: resolver,
+		blocked:  make(map[Action]map[string]bool),
+	}
+	for action, regions := range blocked {
+		set := make(map[string]bool)
+		for _, region := range regions {
+			if code := NormalizeRegion(region); code != UnknownRegion {
+				set[code] = true
+			}
+		}
+		policy.blocked[action] = set
+	}
+	return policy
+}
+
+// Region resolves the region for a client IP
+func (p *Policy) Region(ip string) string {
+	if p == nil || p.resolver == nil {
+		return UnknownRegion
+	}
+	return p.resolver.Resolve(ip)
+}
+
+// Allows reports whether an action is permitted from a region. Addresses
+// that resolve to no region are allowed; block them at the network edge
+// if that is required.
+func (p *Policy) Allows(action Action, region string) bool {
+	if p == nil || region == UnknownRegion {
+		return true
+	}
+	blocked := p.blocked[action]
+	if blocked[region] {
+		return false
+	}
+	country, _, _ := strings.Cut(region, "-")
+	return !blocked[country]
+}
+
+// Check resolves a client IP and returns its region, with a
+// *RestrictedError when the action is not allowed there
+func (p *Policy) Check(action Action, ip string) (string, error) {
+	region := p.Region(ip)
+	if !p.Allows(action, region) {
+		return region, &RestrictedError{Action: action, Region: region}
+	}
+	return region, nil
+}
+
+// Blocked returns the sorted regions an action is blocked in
+func (p *Policy) Blocked(action Action) []string {
+	if p == nil {
+		return []string{}
+	}
+	regions := make([]string, 0, len(p.blocked[action]))
+	for region := range p.blocked[action] {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// NormalizeRegion upper-cases and trims a region code
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+// ParseRegionList splits a comma-separated list of region codes
+func ParseRegionList(list string) []string {
+	regions := make([]string, 0)
+	for _, region := range strings.Split(list, ",") {
+		if code := NormalizeRegion(region); code != UnknownRegion {
+			regions = append(regions, code)
+		}
+	}
+	return regions
+}
@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeResolverPrefersMostSpecificBlock(t *testing.T) {
+	resolver, err := NewRangeResolver(map[string]string{
+		"203.0.113.0/24": "us",
+		"203.0.113.0/28": "US-NV",
+		"2001:db8::/32":  "FR",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "US-NV", resolver.Resolve("203.0.113.5"))
+	assert.Equal(t, "US", resolver.Resolve("203.0.113.200"))
+	assert.Equal(t, "FR", resolver.Resolve("2001:db8::1"))
+	assert.Equal(t, UnknownRegion, resolver.Resolve("198.51.100.1"))
+	assert.Equal(t, UnknownRegion, resolver.Resolve("not-an-ip"))
+}
+
+func TestNewRangeResolverRejectsBadEntries(t *testing.T) {
+	_, err := NewRangeResolver(map[string]string{"203.0.113.0/33": "US"})
+	assert.Error(t, err)
+
+	_, err = NewRangeResolver(map[string]string{"203.0.113.0/24": " "})
+	assert.Error(t, err)
+}
+
+func TestLoadRangeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.csv")
+	content := "# test ranges\n\n203.0.113.0/24,US-NV\n198.51.100.0/24, fr\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	resolver, err := LoadRangeFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "US-NV", resolver.Resolve("203.0.113.1"))
+	assert.Equal(t, "FR", resolver.Resolve("198.51.100.1"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("203.0.113.0/24\n"), 0o600))
+	_, err = LoadRangeFile(path)
+	assert.Error(t, err)
+}
+
+func TestPolicyBlocksCountryAndSubdivisions(t *testing.T) {
+	resolver, err := NewRangeResolver(map[string]string{
+		"203.0.113.0/24":  "US-NV",
+		"198.51.100.0/24": "FR",
+	})
+	assert.NoError(t, err)
+	policy := NewPolicy(resolver, map[Action][]string{
+		ActionRealMoney: {"us"},
+		ActionRegister:  {"FR-75"},
+	})
+
+	region, err := policy.Check(ActionRealMoney, "203.0.113.1")
+	assert.Equal(t, "US-NV", region)
+	var restricted *RestrictedError
+	assert.ErrorAs(t, err, &restricted)
+	assert.Equal(t, ActionRealMoney, restricted.Action)
+
+	_, err = policy.Check(ActionRegister, "203.0.113.1")
+	assert.NoError(t, err)
+
+	assert.True(t, policy.Allows(ActionRegister, "FR"), "blocking a subdivision leaves the country open")
+	assert.False(t, policy.Allows(ActionRegister, "FR-75"))
+	assert.True(t, policy.Allows(ActionRealMoney, UnknownRegion))
+	assert.Equal(t, []string{"US"}, policy.Blocked(ActionRealMoney))
+}
+
+func TestNilPolicyAllowsEverything(t *testing.T) {
+	var policy *Policy
+
+	assert.Equal(t, UnknownRegion, policy.Region("203.0.113.1"))
+	assert.True(t, policy.Allows(ActionRealMoney, "US"))
+	assert.Empty(t, policy.Blocked(ActionRegister))
+}
+
+func TestParseRegionList(t *testing.T) {
+	assert.Equal(t, []string{"US", "FR-75"}, ParseRegionList(" us, ,fr-75 "))
+	assert.Empty(t, ParseRegionList(""))
+}
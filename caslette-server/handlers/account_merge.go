@@ -0,0 +1,567 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountMergeAuditAction is the audit action of every step of a merge; the
+// entry's result names the step
+const AccountMergeAuditAction = "account_merge"
+
+// AccountMergeRequest names the duplicate account folded into the target
+type AccountMergeRequest struct {
+	SourceID uint `json:"source_id" binding:"required"`
+}
+
+// AccountMergeReport describes what a merge moved from the source account
+// to the target and how conflicts were resolved
+type AccountMergeReport struct {
+	SourceID             uint      `json:"source_id"`
+	TargetID             uint      `json:"target_id"`
+	MergedBy             uint      `json:"merged_by"`
+	MergedAt             time.Time `json:"merged_at"`
+	DiamondsMoved        int64     `json:"diamonds_moved"`
+	DiamondTransactionID string    `json:"diamond_transaction_id,omitempty"` // Credit to the target's wallet
+	PlayMoneyMoved       int64     `json:"play_money_moved"`
+	HandsReassigned      int64     `json:"hands_reassigned"`
+	SharedHands          int64     `json:"shared_hands"` // Hands both accounts were dealt into, netted into the target's seat
+	StatsMerged          []string  `json:"stats_merged"` // Currencies whose lifetime stats were combined
+	RolesAdded           int64     `json:"roles_added"`
+	PermissionsAdded     int64     `json:"permissions_added"`
+	TagsAdded            int64     `json:"tags_added"`
+	CosmeticsMoved       int64     `json:"cosmetics_moved"`
+	CosmeticsDuplicate   int64     `json:"cosmetics_duplicate"` // Items both accounts owned; the target's copy is kept
+	PreferencesFrom      string    `json:"preferences_from"`    // "target", "source" or "none"
+	BotTokensMoved       int64     `json:"bot_tokens_moved"`
+	LoginEventsMoved     int64     `json:"login_events_moved"`
+	DisputesMoved        int64     `json:"disputes_moved"`
+}
+
+// AccountMerger folds a duplicate account, such as a second sign-up through
+// another OAuth provider, into the account kept. In one transaction:
+//
+//   - The source's diamonds and play money are added to the target's
+//   - Hand history and lifetime stats are reassigned; hands both accounts
+//     were dealt into keep the target's seat with the nets summed, and stats
+//     of the same currency are summed with the biggest pot the larger one
+//   - Roles, permissions, tags and cosmetics become the union of both; the
+//     target keeps its equipped items and moved items come unequipped
+//   - The target's preferences, profile, username and email are kept
+//   - Bot tokens, sign-in history and disputes move to the target
+//   - The source is deactivated and soft-deleted
+//
+// Every step is written to the audit trail. Players seated at a table cannot
+// be merged, since their seat and escrow belong to the source account, and
+// the source cannot sit down while its merge runs.
+type AccountMerger struct {
+	db           *gorm.DB
+	tableManager *game.ActorTableManager
+	now          func() time.Time
+}
+
+// NewAccountMerger creates a merger over the user tables; the table manager
+// may be nil when no tables are served
+func NewAccountMerger(db *gorm.DB, tableManager *game.ActorTableManager) *AccountMerger {
+	return &AccountMerger{db: db, tableManager: tableManager, now: time.Now}
+}
+
+// Merge folds the source account into the target on behalf of an admin.
+// Failures are txFailure errors carrying the HTTP status to respond with.
+func (m *AccountMerger) Merge(ctx context.Context, sourceID, targetID, adminID uint) (*AccountMergeReport, error) {
+	if sourceID == targetID {
+		return nil, txAbort(http.StatusBadRequest, "An account cannot be merged into itself")
+	}
+	// The source may not sit down while it is merged, so a seat it holds
+	// cannot appear between the check and the commit
+	if m.tableManager != nil {
+		release := m.tableManager.JoinGate().Block(strconv.FormatUint(uint64(sourceID), 10))
+		defer release()
+	}
+
+	report := &AccountMergeReport{
+		SourceID:    sourceID,
+		TargetID:    targetID,
+		MergedBy:    adminID,
+		MergedAt:    m.now(),
+		StatsMerged: make([]string, 0),
+	}
+	err := WithTransaction(ctx, m.db, func(tx *gorm.DB) error {
+		var source, target models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&source, sourceID).Error; err != nil {
+			return txAbort(http.StatusNotFound, "Source account not found")
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&target, targetID).Error; err != nil {
+			return txAbort(http.StatusNotFound, "Target account not found")
+		}
+		if tableID := m.seatedAt(sourceID); tableID != "" {
+			return txAbort(http.StatusConflict, "The source account is seated at table "+tableID)
+		}
+
+		steps := []func(tx *gorm.DB, report *AccountMergeReport) error{
+			m.mergeDiamonds,
+			m.mergePlayMoney,
+			m.mergeHandHistory,
+			m.mergePlayerStats,
+			m.mergeAccess,
+			m.mergeCosmetics,
+			m.mergePreferences,
+			m.mergeRecords,
+		}
+		for _, step := range steps {
+			if err := step(tx, report); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&source).Update("is_active", false).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to deactivate source account")
+		}
+		if err := tx.Delete(&source).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to delete source account")
+		}
+
+		summary, _ := json.Marshal(report)
+		return m.audit(tx, report, "completed", string(summary))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	middleware.InvalidateUserPermissions(sourceID)
+	middleware.InvalidateUserPermissions(targetID)
+	return report, nil
+}
+
+// seatedAt returns a table the user holds a seat at, or ""
+func (m *AccountMerger) seatedAt(userID uint) string {
+	if m.tableManager == nil {
+		return ""
+	}
+	playerID := strconv.FormatUint(uint64(userID), 10)
+	for _, table := range m.tableManager.GetTables() {
+		if table.IsPlayerAtTable(playerID) {
+			return table.ID
+		}
+	}
+	return ""
+}
+
+// mergeDiamonds moves the source's whole diamond balance with a debit
+// taking it to zero and a matching credit to the target
+func (m *AccountMerger) mergeDiamonds(tx *gorm.DB, report *AccountMergeReport) error {
+	balance, err := userDiamondBalance(tx, report.SourceID)
+	if err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to calculate source balance")
+	}
+	if balance <= 0 {
+		return nil
+	}
+	targetBalance, err := userDiamondBalance(tx, report.TargetID)
+	if err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to calculate target balance")
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"merge_source": report.SourceID,
+		"merge_target": report.TargetID,
+		"merged_by":    report.MergedBy,
+	})
+	debit := models.Diamond{
+		UserID:      report.SourceID,
+		Amount:      -balance,
+		Balance:     0,
+		Type:        "account_merge",
+		Description: fmt.Sprintf("Merged into account #%d", report.TargetID),
+		Metadata:    string(metadata),
+	}
+	credit := models.Diamond{
+		UserID:      report.TargetID,
+		Amount:      balance,
+		Balance:     targetBalance + balance,
+		Type:        "account_merge",
+		Description: fmt.Sprintf("Merged from account #%d", report.SourceID),
+		Metadata:    string(metadata),
+	}
+	if err := tx.Create(&debit).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to move diamonds")
+	}
+	if err := tx.Create(&credit).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to move diamonds")
+	}
+
+	report.DiamondsMoved = balance
+	report.DiamondTransactionID = credit.TransactionID
+	return m.audit(tx, report, "diamonds", fmt.Sprintf("moved %d diamonds (debit %s, credit %s)",
+		balance, debit.TransactionID, credit.TransactionID))
+}
+
+// mergePlayMoney adds the source's practice balance to the target's,
+// keeping the later refill so the merge does not earn an early one
+func (m *AccountMerger) mergePlayMoney(tx *gorm.DB, report *AccountMergeReport) error {
+	var source models.PlayMoneyAccount
+	if err := tx.Where("user_id = ?", report.SourceID).First(&source).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return txAbort(http.StatusInternalServerError, "Failed to fetch play money account")
+	}
+
+	var target models.PlayMoneyAccount
+	err := tx.Where("user_id = ?", report.TargetID).First(&target).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := tx.Model(&source).Update("user_id", report.TargetID).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to move play money account")
+		}
+	case err != nil:
+		return txAbort(http.StatusInternalServerError, "Failed to fetch play money account")
+	default:
+		target.Balance += source.Balance
+		if source.LastRefillAt != nil && (target.LastRefillAt == nil || source.LastRefillAt.After(*target.LastRefillAt)) {
+			target.LastRefillAt = source.LastRefillAt
+		}
+		if err := tx.Save(&target).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge play money")
+		}
+		if err := tx.Delete(&source).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge play money")
+		}
+	}
+
+	report.PlayMoneyMoved = source.Balance
+	return m.audit(tx, report, "play_money", fmt.Sprintf("moved %d play money chips", source.Balance))
+}
+
+// mergeHandHistory reassigns the source's persisted hands to the target.
+// Hands both accounts were dealt into keep both seats in their record, with
+// the target's index entry carrying the combined net.
+func (m *AccountMerger) mergeHandHistory(tx *gorm.DB, report *AccountMergeReport) error {
+	sourcePlayer := strconv.FormatUint(uint64(report.SourceID), 10)
+	targetPlayer := strconv.FormatUint(uint64(report.TargetID), 10)
+
+	var shared []models.HandHistoryPlayer
+	if err := tx.Where("player_id = ? AND hand_id IN (?)", sourcePlayer,
+		tx.Model(&models.HandHistoryPlayer{}).Select("hand_id").Where("player_id = ?", targetPlayer)).
+		Find(&shared).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to fetch hand history")
+	}
+	for _, seat := range shared {
+		if err := tx.Model(&models.HandHistoryPlayer{}).
+			Where("hand_id = ? AND player_id = ?", seat.HandID, targetPlayer).
+			Update("net", gorm.Expr("net + ?", seat.Net)).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge hand history")
+		}
+		if err := tx.Delete(&seat).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge hand history")
+		}
+	}
+
+	var hands []models.HandHistory
+	err := tx.Where("hand_id IN (?)", tx.Model(&models.HandHistoryPlayer{}).Select("hand_id").
+		Where("player_id = ?", sourcePlayer)).
+		FindInBatches(&hands, 100, func(batch *gorm.DB, _ int) error {
+			for _, hand := range hands {
+				record, err := decodeHandRecord(hand)
+				if err != nil {
+					return err
+				}
+				record.ReassignPlayer(sourcePlayer, targetPlayer)
+				encoded, err := json.Marshal(record)
+				if err != nil {
+					return err
+				}
+				if err := tx.Model(&models.HandHistory{}).Where("id = ?", hand.ID).
+					Update("record", string(encoded)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to reassign hand history")
+	}
+
+	reassigned := tx.Model(&models.HandHistoryPlayer{}).Where("player_id = ?", sourcePlayer).
+		Update("player_id", targetPlayer)
+	if reassigned.Error != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to reassign hand history")
+	}
+
+	report.HandsReassigned = reassigned.RowsAffected
+	report.SharedHands = int64(len(shared))
+	if report.HandsReassigned == 0 && report.SharedHands == 0 {
+		return nil
+	}
+	return m.audit(tx, report, "hand_history", fmt.Sprintf("reassigned %d hands, netted %d hands both accounts played",
+		report.HandsReassigned, report.SharedHands))
+}
+
+// mergePlayerStats combines lifetime stats per currency: counts and
+// winnings are summed and the biggest pot is the larger of the two
+func (m *AccountMerger) mergePlayerStats(tx *gorm.DB, report *AccountMergeReport) error {
+	sourcePlayer := strconv.FormatUint(uint64(report.SourceID), 10)
+	targetPlayer := strconv.FormatUint(uint64(report.TargetID), 10)
+
+	var sourceStats []models.PlayerStats
+	if err := tx.Where("player_id = ?", sourcePlayer).Order("currency").Find(&sourceStats).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to fetch player stats")
+	}
+	for _, stats := range sourceStats {
+		var target models.PlayerStats
+		err := tx.Where("player_id = ? AND currency = ?", targetPlayer, stats.Currency).First(&target).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Model(&stats).Update("player_id", targetPlayer).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to merge player stats")
+			}
+		case err != nil:
+			return txAbort(http.StatusInternalServerError, "Failed to fetch player stats")
+		default:
+			target.HandsPlayed += stats.HandsPlayed
+			target.VPIPHands += stats.VPIPHands
+			target.PFRHands += stats.PFRHands
+			target.Showdowns += stats.Showdowns
+			target.ShowdownsWon += stats.ShowdownsWon
+			target.TotalWinnings += stats.TotalWinnings
+			target.BiggestPot = max(target.BiggestPot, stats.BiggestPot)
+			if err := tx.Save(&target).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to merge player stats")
+			}
+			if err := tx.Delete(&stats).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to merge player stats")
+			}
+		}
+		report.StatsMerged = append(report.StatsMerged, stats.Currency)
+	}
+
+	if len(report.StatsMerged) == 0 {
+		return nil
+	}
+	return m.audit(tx, report, "player_stats", fmt.Sprintf("merged stats for %v", report.StatsMerged))
+}
+
+// mergeAccess gives the target the union of both accounts' roles, direct
+// permissions and tags, removing them from the source
+func (m *AccountMerger) mergeAccess(tx *gorm.DB, report *AccountMergeReport) error {
+	var roleIDs, permissionIDs []uint
+	if err := tx.Model(&models.UserRole{}).Where("user_id = ?", report.SourceID).Pluck("role_id", &roleIDs).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to fetch roles")
+	}
+	if err := tx.Model(&models.UserPermission{}).Where("user_id = ?", report.SourceID).Pluck("permission_id", &permissionIDs).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to fetch permissions")
+	}
+
+	for _, roleID := range roleIDs {
+		added := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{UserID: report.TargetID, RoleID: roleID})
+		if added.Error != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge roles")
+		}
+		report.RolesAdded += added.RowsAffected
+	}
+	for _, permissionID := range permissionIDs {
+		added := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserPermission{UserID: report.TargetID, PermissionID: permissionID})
+		if added.Error != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge permissions")
+		}
+		report.PermissionsAdded += added.RowsAffected
+	}
+
+	tags := tx.Model(&models.UserTag{}).
+		Where("user_id = ? AND tag NOT IN (?)", report.SourceID,
+			tx.Model(&models.UserTag{}).Select("tag").Where("user_id = ?", report.TargetID)).
+		Update("user_id", report.TargetID)
+	if tags.Error != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to merge tags")
+	}
+	report.TagsAdded = tags.RowsAffected
+
+	if err := tx.Where("user_id = ?", report.SourceID).Delete(&models.UserRole{}).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to merge roles")
+	}
+	if err := tx.Where("user_id = ?", report.SourceID).Delete(&models.UserPermission{}).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to merge permissions")
+	}
+	if err := tx.Where("user_id = ?", report.SourceID).Delete(&models.UserTag{}).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to merge tags")
+	}
+
+	if len(roleIDs) == 0 && len(permissionIDs) == 0 && report.TagsAdded == 0 {
+		return nil
+	}
+	return m.audit(tx, report, "access", fmt.Sprintf("added %d roles, %d permissions and %d tags (source held %d roles and %d permissions)",
+		report.RolesAdded, report.PermissionsAdded, report.TagsAdded, len(roleIDs), len(permissionIDs)))
+}
+
+// mergeCosmetics moves the items only the source owns, unequipped, and
+// drops the source's copies of items the target already owns
+func (m *AccountMerger) mergeCosmetics(tx *gorm.DB, report *AccountMergeReport) error {
+	moved := tx.Model(&models.UserCosmetic{}).
+		Where("user_id = ? AND item_id NOT IN (?)", report.SourceID,
+			tx.Model(&models.UserCosmetic{}).Select("item_id").Where("user_id = ?", report.TargetID)).
+		Updates(map[string]interface{}{"user_id": report.TargetID, "equipped": false})
+	if moved.Error != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to merge cosmetics")
+	}
+	duplicates := tx.Where("user_id = ?", report.SourceID).Delete(&models.UserCosmetic{})
+	if duplicates.Error != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to merge cosmetics")
+	}
+
+	report.CosmeticsMoved = moved.RowsAffected
+	report.CosmeticsDuplicate = duplicates.RowsAffected
+	if report.CosmeticsMoved == 0 && report.CosmeticsDuplicate == 0 {
+		return nil
+	}
+	return m.audit(tx, report, "cosmetics", fmt.Sprintf("moved %d items, dropped %d owned by both accounts",
+		report.CosmeticsMoved, report.CosmeticsDuplicate))
+}
+
+// mergePreferences keeps the target's preferences, taking the source's only
+// when the target has none
+func (m *AccountMerger) mergePreferences(tx *gorm.DB, report *AccountMergeReport) error {
+	var source, target models.UserPreference
+	sourceErr := tx.Where("user_id = ?", report.SourceID).First(&source).Error
+	targetErr := tx.Where("user_id = ?", report.TargetID).First(&target).Error
+	for _, err := range []error{sourceErr, targetErr} {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return txAbort(http.StatusInternalServerError, "Failed to fetch preferences")
+		}
+	}
+
+	switch {
+	case targetErr == nil:
+		report.PreferencesFrom = "target"
+	case sourceErr == nil:
+		report.PreferencesFrom = "source"
+		moved := source
+		moved.UserID = report.TargetID
+		if err := tx.Create(&moved).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge preferences")
+		}
+	default:
+		report.PreferencesFrom = "none"
+		return nil
+	}
+	if sourceErr == nil {
+		if err := tx.Delete(&source).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to merge preferences")
+		}
+	}
+	return m.audit(tx, report, "preferences", "kept the "+report.PreferencesFrom+" account's preferences")
+}
+
+// mergeRecords moves the source's bot tokens, sign-in history and disputes
+func (m *AccountMerger) mergeRecords(tx *gorm.DB, report *AccountMergeReport) error {
+	for _, record := range []struct {
+		model interface{}
+		count *int64
+		name  string
+	}{
+		{&models.BotToken{}, &report.BotTokensMoved, "bot tokens"},
+		{&models.LoginEvent{}, &report.LoginEventsMoved, "sign-in events"},
+		{&models.HandDispute{}, &report.DisputesMoved, "disputes"},
+	} {
+		moved := tx.Model(record.model).Where("user_id = ?", report.SourceID).Update("user_id", report.TargetID)
+		if moved.Error != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to move "+record.name)
+		}
+		*record.count = moved.RowsAffected
+	}
+
+	if report.BotTokensMoved == 0 && report.LoginEventsMoved == 0 && report.DisputesMoved == 0 {
+		return nil
+	}
+	return m.audit(tx, report, "records", fmt.Sprintf("moved %d bot tokens, %d sign-in events and %d disputes",
+		report.BotTokensMoved, report.LoginEventsMoved, report.DisputesMoved))
+}
+
+// audit writes one step of the merge to the audit trail in the merge's
+// transaction, under the target account
+func (m *AccountMerger) audit(tx *gorm.DB, report *AccountMergeReport, step, details string) error {
+	entry := models.AuditLog{
+		UserID:    strconv.FormatUint(uint64(report.TargetID), 10),
+		Action:    AccountMergeAuditAction,
+		Result:    step,
+		Details:   fmt.Sprintf("account #%d merged into #%d by admin #%d: %s", report.SourceID, report.TargetID, report.MergedBy, details),
+		CreatedAt: report.MergedAt,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return txAbort(http.StatusInternalServerError, "Failed to write audit trail")
+	}
+	return nil
+}
+
+// AccountMergeHandler serves the admin account merge tool
+type AccountMergeHandler struct {
+	merger    *AccountMerger
+	validator *SecurityValidator
+}
+
+// NewAccountMergeHandler creates a handler for account merges
+func NewAccountMergeHandler(db *gorm.DB, tableManager *game.ActorTableManager) *AccountMergeHandler {
+	return &AccountMergeHandler{
+		merger:    NewAccountMerger(db, tableManager),
+		validator: NewSecurityValidator(),
+	}
+}
+
+// MergeAccount handles POST /api/v1/admin/users/:id/merge, folding the
+// account named in the body into the account in the path
+func (h *AccountMergeHandler) MergeAccount(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	targetID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "invalid user ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req AccountMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	report, err := h.merger.Merge(c.Request.Context(), req.SourceID, targetID, adminID.(uint))
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to merge accounts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"merge":      report,
+		"request_id": requestID,
+	})
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newMergeTestDB creates users 1 (kept) and 2 (the duplicate) with the
+// tables a merge touches
+func newMergeTestDB(t *testing.T) *gorm.DB {
+	db := newSQLiteDB(t, &models.User{}, &models.Role{}, &models.Permission{}, &models.UserRole{}, &models.UserPermission{},
+		&models.Diamond{}, &models.PlayMoneyAccount{}, &models.HandHistory{}, &models.HandHistoryPlayer{}, &models.PlayerStats{},
+		&models.UserTag{}, &models.CosmeticItem{}, &models.UserCosmetic{}, &models.UserPreference{}, &models.BotToken{},
+		&models.LoginEvent{}, &models.HandDispute{}, &models.AuditLog{})
+	for _, userID := range []uint{1, 2} {
+		require.NoError(t, db.Create(&models.User{ID: userID, Username: fmt.Sprintf("player%d", userID), Email: fmt.Sprintf("p%d@example.com", userID), Password: "x", IsActive: true}).Error)
+	}
+	return db
+}
+
+// mergeTestHand is a hand with the given players, the first winning 20
+func mergeTestHand(handID string, playerIDs ...string) game.HandRecord {
+	record := game.HandRecord{HandID: handID, TableID: "merge-table", GameType: game.GameTypeTexasHoldem, TotalPot: 40, EndedAt: time.Now()}
+	for i, playerID := range playerIDs {
+		net := -20
+		if i == 0 {
+			net = 20
+		}
+		record.Players = append(record.Players, game.HandPlayer{PlayerID: playerID, Seat: i})
+		record.Actions = append(record.Actions, game.HandAction{PlayerID: playerID, Action: "call", Amount: 20})
+		record.Results = append(record.Results, game.HandResult{PlayerID: playerID, Net: net})
+	}
+	record.Winners = []string{playerIDs[0]}
+	record.Pots = []game.Pot{{Name: "main", Amount: 40, Eligible: playerIDs, Winners: []string{playerIDs[0]}}}
+	return record
+}
+
+func TestAccountMerger_CombinesAccounts(t *testing.T) {
+	db := newMergeTestDB(t)
+	refilled := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&[]models.Diamond{
+		{UserID: 1, Amount: 100, Balance: 100, Type: "credit", Metadata: "{}"},
+		{UserID: 2, Amount: 250, Balance: 250, Type: "credit", Metadata: "{}"},
+	}).Error)
+	require.NoError(t, db.Create(&[]models.PlayMoneyAccount{{UserID: 1, Balance: 1000}, {UserID: 2, Balance: 400, LastRefillAt: &refilled}}).Error)
+
+	store := NewHandHistoryStore(db)
+	require.NoError(t, store.SaveHand(mergeTestHand("solo", "2", "9")))
+	require.NoError(t, store.SaveHand(mergeTestHand("shared", "1", "2")))
+	require.NoError(t, db.Create(&[]models.PlayerStats{
+		{PlayerID: "1", Currency: "diamonds", HandsPlayed: 10, TotalWinnings: 50, BiggestPot: 300},
+		{PlayerID: "2", Currency: "diamonds", HandsPlayed: 5, TotalWinnings: -20, BiggestPot: 800},
+		{PlayerID: "2", Currency: "play_money", HandsPlayed: 7},
+	}).Error)
+
+	require.NoError(t, db.Create(&[]models.Role{{ID: 1, Name: "user"}, {ID: 2, Name: "moderator"}}).Error)
+	require.NoError(t, db.Create(&[]models.UserRole{{UserID: 1, RoleID: 1}, {UserID: 2, RoleID: 1}, {UserID: 2, RoleID: 2}}).Error)
+	require.NoError(t, db.Create(&[]models.UserTag{{UserID: 1, Tag: "vip"}, {UserID: 2, Tag: "vip"}, {UserID: 2, Tag: "beta"}}).Error)
+	require.NoError(t, db.Create(&[]models.CosmeticItem{{ID: 1, SKU: "deck_neon", Name: "Neon", Kind: CosmeticKindDeck}, {ID: 2, SKU: "table_wood", Name: "Wood", Kind: CosmeticKindTable}}).Error)
+	require.NoError(t, db.Create(&[]models.UserCosmetic{{UserID: 1, ItemID: 1, Equipped: true}, {UserID: 2, ItemID: 1, Equipped: true}, {UserID: 2, ItemID: 2, Equipped: true}}).Error)
+	require.NoError(t, db.Create(&models.UserPreference{UserID: 2, HandStats: true}).Error)
+	require.NoError(t, db.Create(&models.LoginEvent{UserID: 2, Success: true}).Error)
+
+	report, err := NewAccountMerger(db, nil).Merge(context.Background(), 2, 1, 7)
+	require.NoError(t, err)
+
+	// Wallets
+	assert.Equal(t, int64(250), report.DiamondsMoved)
+	assert.Equal(t, int64(350), diamondBalance(t, db, 1))
+	assert.Equal(t, int64(0), diamondBalance(t, db, 2))
+	var credit models.Diamond
+	require.NoError(t, db.Where("transaction_id = ?", report.DiamondTransactionID).First(&credit).Error)
+	assert.Equal(t, int64(350), credit.Balance, "the credit carries the running balance")
+	var playMoney models.PlayMoneyAccount
+	require.NoError(t, db.Where("user_id = ?", 1).First(&playMoney).Error)
+	assert.Equal(t, int64(1400), playMoney.Balance)
+	require.NotNil(t, playMoney.LastRefillAt, "the later refill is kept")
+
+	// Hand history
+	assert.Equal(t, int64(1), report.HandsReassigned)
+	assert.Equal(t, int64(1), report.SharedHands)
+	solo, err := store.GetHand("solo")
+	require.NoError(t, err)
+	assert.True(t, solo.Dealt("1"))
+	assert.False(t, solo.Dealt("2"))
+	assert.Equal(t, []string{"1"}, solo.Winners)
+	assert.Equal(t, []string{"1", "9"}, solo.Pots[0].Eligible)
+	var shared models.HandHistoryPlayer
+	require.NoError(t, db.Where("hand_id = ? AND player_id = ?", "shared", "1").First(&shared).Error)
+	assert.Equal(t, int64(0), shared.Net, "both seats are netted into the target's")
+	var sourceHands int64
+	db.Model(&models.HandHistoryPlayer{}).Where("player_id = ?", "2").Count(&sourceHands)
+	assert.Zero(t, sourceHands)
+
+	// Stats
+	assert.Equal(t, []string{"diamonds", "play_money"}, report.StatsMerged)
+	var stats models.PlayerStats
+	require.NoError(t, db.Where("player_id = ? AND currency = ?", "1", "diamonds").First(&stats).Error)
+	assert.Equal(t, int64(15), stats.HandsPlayed)
+	assert.Equal(t, int64(30), stats.TotalWinnings)
+	assert.Equal(t, int64(800), stats.BiggestPot)
+	var practice models.PlayerStats
+	require.NoError(t, db.Where("player_id = ? AND currency = ?", "1", "play_money").First(&practice).Error)
+	assert.Equal(t, int64(7), practice.HandsPlayed)
+
+	// Roles, tags, cosmetics and preferences
+	var roleIDs []uint
+	db.Model(&models.UserRole{}).Where("user_id = ?", 1).Order("role_id").Pluck("role_id", &roleIDs)
+	assert.Equal(t, []uint{1, 2}, roleIDs)
+	assert.Equal(t, int64(1), report.TagsAdded)
+	hasBeta, err := UserHasTag(db, 1, "beta")
+	require.NoError(t, err)
+	assert.True(t, hasBeta)
+	assert.Equal(t, int64(1), report.CosmeticsMoved)
+	assert.Equal(t, int64(1), report.CosmeticsDuplicate)
+	equipped, err := EquippedCosmetics(db, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{CosmeticKindDeck: "deck_neon"}, equipped, "moved items come unequipped")
+	assert.Equal(t, "source", report.PreferencesFrom)
+	var preference models.UserPreference
+	require.NoError(t, db.First(&preference, "user_id = ?", 1).Error)
+	assert.True(t, preference.HandStats)
+	assert.Equal(t, int64(1), report.LoginEventsMoved)
+
+	// The duplicate is gone and every step is audited
+	var source models.User
+	assert.True(t, errors.Is(db.First(&source, 2).Error, gorm.ErrRecordNotFound))
+	require.NoError(t, db.Unscoped().First(&source, 2).Error)
+	assert.False(t, source.IsActive)
+	var steps []string
+	db.Model(&models.AuditLog{}).Where("action = ?", AccountMergeAuditAction).Order("id").Pluck("result", &steps)
+	assert.Equal(t, []string{"diamonds", "play_money", "hand_history", "player_stats", "access", "cosmetics", "preferences", "records", "completed"}, steps)
+}
+
+func TestAccountMerger_RefusesInvalidMerges(t *testing.T) {
+	db := newMergeTestDB(t)
+	manager := game.NewActorTableManager(nil)
+	table, err := manager.CreateTable(context.Background(), &game.TableCreateRequest{
+		Name: "Merge", GameType: game.GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: game.DefaultTableSettings(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, manager.JoinTable(context.Background(), &game.TableJoinRequest{TableID: table.ID, PlayerID: "2", Username: "player2", Mode: game.JoinModePlayer}))
+	merger := NewAccountMerger(db, manager)
+
+	for name, merge := range map[string]struct {
+		source, target uint
+		status         int
+	}{
+		"itself":         {1, 1, http.StatusBadRequest},
+		"seated source":  {2, 1, http.StatusConflict},
+		"missing source": {3, 1, http.StatusNotFound},
+		"missing target": {1, 3, http.StatusNotFound},
+	} {
+		_, err := merger.Merge(context.Background(), merge.source, merge.target, 7)
+		var txErr *TxError
+		require.True(t, errors.As(err, &txErr), name)
+		assert.Equal(t, merge.status, txErr.Status, name)
+	}
+
+	var entries int64
+	db.Model(&models.AuditLog{}).Count(&entries)
+	assert.Zero(t, entries, "refused merges change nothing")
+}
+
+func TestAccountMergeHandler_MergeAccount(t *testing.T) {
+	db := newMergeTestDB(t)
+	handler := NewAccountMergeHandler(db, nil)
+
+	c, w := newDisputeContext("POST", "/admin/users/1/merge", map[string]interface{}{}, uint(7))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	handler.MergeAccount(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the source is required")
+
+	c, w = newDisputeContext("POST", "/admin/users/1/merge", map[string]interface{}{"source_id": 2}, uint(7))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	handler.MergeAccount(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"merged_by":7`)
+}
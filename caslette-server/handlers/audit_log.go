@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Audit trail query limits and retention defaults
+const (
+	DefaultAuditLimit      = 100
+	MaxAuditLimit          = 500
+	DefaultAuditRetention  = 90 * 24 * time.Hour
+	DefaultAuditPruneEvery = time.Hour
+)
+
+// auditFilterPattern restricts user, table, action and result filters
+var auditFilterPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,64}$`)
+
+// AuditLogStore persists security audit entries and deletes them once they
+// are older than the retention period
+type AuditLogStore struct {
+	db        *gorm.DB
+	retention time.Duration // Zero keeps entries forever
+
+	mu   sync.Mutex
+	stop chan struct{}
+	now  func() time.Time
+}
+
+// NewAuditLogStore creates a store over the audit_logs table
+func NewAuditLogStore(db *gorm.DB, retention time.Duration) *AuditLogStore {
+	if retention < 0 {
+		retention = 0
+	}
+	return &AuditLogStore{db: db, retention: retention, now: time.Now}
+}
+
+// SaveAuditEntry writes one audit entry
+func (s *AuditLogStore) SaveAuditEntry(entry game.AuditLogEntry) error {
+	return s.db.Create(&models.AuditLog{
+		UserID:    entry.UserID,
+		TableID:   entry.TableID,
+		Action:    entry.Action,
+		Result:    entry.Result,
+		IPAddress: entry.IPAddress,
+		UserAgent: entry.UserAgent,
+		Details:   entry.Details,
+		CreatedAt: entry.Timestamp,
+	}).Error
+}
+
+// Retention returns how long entries are kept; zero keeps them forever
+func (s *AuditLogStore) Retention() time.Duration {
+	return s.retention
+}
+
+// Prune deletes entries older than the retention period and returns how
+// many were removed
+func (s *AuditLogStore) Prune() (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	return s.PruneBefore(s.now().Add(-s.retention))
+}
+
+// PruneBefore deletes entries written before cutoff; it is the store's
+// retention purger
+func (s *AuditLogStore) PruneBefore(cutoff time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", cutoff).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// Start prunes expired entries every interval until Stop is called
+func (s *AuditLogStore) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultAuditPruneEvery
+	}
+
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	stop := s.stop
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if removed, err := s.Prune(); err != nil {
+					log.Printf("AuditLogStore: failed to prune expired entries: %v", err)
+				} else if removed > 0 {
+					log.Printf("AuditLogStore: pruned %d expired entries", removed)
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends periodic pruning
+func (s *AuditLogStore) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// AuditLogHandler serves the persisted audit trail to admins
+type AuditLogHandler struct {
+	db    *gorm.DB
+	store *AuditLogStore
+}
+
+// NewAuditLogHandler creates a handler reading from the store's table
+func NewAuditLogHandler(store *AuditLogStore) *AuditLogHandler {
+	return &AuditLogHandler{db: store.db, store: store}
+}
+
+// auditQuery is the parsed filter for an audit trail request
+type auditQuery struct {
+	filters map[string]string // Column -> required value
+	since   *time.Time
+	until   *time.Time
+	limit   int
+}
+
+// parseAuditQuery reads the user_id, table_id, action, result, since, until
+// and limit query parameters
+func parseAuditQuery(c *gin.Context) (*auditQuery, error) {
+	query := &auditQuery{filters: make(map[string]string), limit: DefaultAuditLimit}
+
+	for _, column := range []string{"user_id", "table_id", "action", "result"} {
+		if raw := c.Query(column); raw != "" {
+			if !auditFilterPattern.MatchString(raw) {
+				return nil, fmt.Errorf("invalid %s filter", column)
+			}
+			query.filters[column] = raw
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"since": &query.since, "until": &query.until} {
+		if raw := c.Query(param); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = &parsed
+		}
+	}
+	if query.since != nil && query.until != nil && !query.since.Before(*query.until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxAuditLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxAuditLimit)
+		}
+		query.limit = limit
+	}
+
+	return query, nil
+}
+
+// ListAuditLogs handles GET /api/v1/admin/audit. Entries come newest first;
+// older pages are fetched by passing next_until back as until.
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	db := h.db.Model(&models.AuditLog{})
+	for column, value := range query.filters {
+		db = db.Where(column+" = ?", value)
+	}
+	if query.since != nil {
+		db = db.Where("created_at >= ?", *query.since)
+	}
+	if query.until != nil {
+		db = db.Where("created_at < ?", *query.until)
+	}
+
+	entries := make([]models.AuditLog, 0)
+	if err := db.Order("created_at desc").Order("id desc").Limit(query.limit + 1).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load audit log",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	truncated := len(entries) > query.limit
+	if truncated {
+		entries = entries[:query.limit]
+	}
+	response := gin.H{
+		"entries":           entries,
+		"truncated":         truncated,
+		"retention_seconds": int64(h.store.Retention() / time.Second),
+	}
+	if truncated {
+		response["next_until"] = entries[len(entries)-1].CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       response,
+		"request_id": requestID,
+	})
+}
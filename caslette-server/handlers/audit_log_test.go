@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listAuditLogs(handler *AuditLogHandler, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/admin/audit?"+query, nil)
+	handler.ListAuditLogs(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data, _ := response["data"].(map[string]interface{})
+	return w, data
+}
+
+func TestAuditLogStore_PersistsAuditorEntries(t *testing.T) {
+	store := NewAuditLogStore(newSQLiteDB(t, &models.AuditLog{}), DefaultAuditRetention)
+	auditor := game.NewSecurityAuditor()
+	auditor.SetStore(store)
+
+	auditor.LogAction("1", "table-1", "join_table", "success", "")
+	auditor.LogAction("2", "table-1", "join_table", "failed", "table full")
+	auditor.LogAction("2", "table-2", game.ChipReconcileAuditAction, "discrepancy", "short 20 chips")
+
+	handler := NewAuditLogHandler(store)
+	w, data := listAuditLogs(handler, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	entries := data["entries"].([]interface{})
+	require.Len(t, entries, 3)
+	assert.Equal(t, game.ChipReconcileAuditAction, entries[0].(map[string]interface{})["action"], "newest first")
+	assert.Equal(t, float64(DefaultAuditRetention/time.Second), data["retention_seconds"])
+
+	_, data = listAuditLogs(handler, "user_id=2&table_id=table-1")
+	require.Len(t, data["entries"], 1)
+	assert.Equal(t, "table full", data["entries"].([]interface{})[0].(map[string]interface{})["details"])
+
+	_, data = listAuditLogs(handler, "action=join_table&result=success")
+	require.Len(t, data["entries"], 1)
+	assert.Equal(t, "1", data["entries"].([]interface{})[0].(map[string]interface{})["user_id"])
+}
+
+func TestAuditLogHandler_PagesAndValidates(t *testing.T) {
+	store := NewAuditLogStore(newSQLiteDB(t, &models.AuditLog{}), 0)
+	start := time.Now().Add(-time.Hour).UTC()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.SaveAuditEntry(game.AuditLogEntry{
+			Timestamp: start.Add(time.Duration(i) * time.Minute), UserID: "7", Action: "create_table", Result: "success",
+		}))
+	}
+	handler := NewAuditLogHandler(store)
+
+	_, data := listAuditLogs(handler, "limit=2")
+	assert.Len(t, data["entries"], 2)
+	assert.Equal(t, true, data["truncated"])
+	nextUntil := data["next_until"].(string)
+
+	_, data = listAuditLogs(handler, "limit=2&until="+nextUntil)
+	assert.Len(t, data["entries"], 1)
+	assert.Equal(t, false, data["truncated"])
+
+	for _, query := range []string{"limit=0", "limit=501", "since=yesterday", "user_id=1%3BDROP", "since=2024-02-01T00:00:00Z&until=2024-01-01T00:00:00Z"} {
+		w, _ := listAuditLogs(handler, query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestAuditLogStore_PrunesExpiredEntries(t *testing.T) {
+	db := newSQLiteDB(t, &models.AuditLog{})
+	store := NewAuditLogStore(db, 24*time.Hour)
+	now := time.Now()
+	require.NoError(t, store.SaveAuditEntry(game.AuditLogEntry{Timestamp: now.Add(-48 * time.Hour), Action: "old", Result: "success"}))
+	require.NoError(t, store.SaveAuditEntry(game.AuditLogEntry{Timestamp: now.Add(-time.Hour), Action: "recent", Result: "success"}))
+
+	removed, err := store.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	var remaining []models.AuditLog
+	require.NoError(t, db.Find(&remaining).Error)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "recent", remaining[0].Action)
+
+	removed, err = NewAuditLogStore(db, 0).Prune()
+	require.NoError(t, err)
+	assert.Zero(t, removed, "zero retention keeps everything")
+}
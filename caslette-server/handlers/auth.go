@@ -3,6 +3,7 @@ package handlers
 import (
 	"caslette-server/auth"
 	"caslette-server/models"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -150,35 +151,31 @@ func (h *SecureAuthHandler) Register(c *gin.Context) {
 		FirstName: firstName,
 		LastName:  lastName,
 		IsActive:  true,
+		Region:    c.GetString("region"),
 	}
 
-	// Use transaction for data consistency
-	tx := h.db.Begin()
-	if err := tx.Create(&user).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      "Registration failed",
-			"request_id": requestID,
-		})
-		return
-	}
+	// Create the user, default role and welcome bonus together
+	if err := WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
 
-	// Assign default user role
-	var defaultRole models.Role
-	if err := tx.Where("name = ?", "user").First(&defaultRole).Error; err == nil {
-		tx.Model(&user).Association("Roles").Append(&defaultRole)
-	}
+		// Assign default user role
+		var defaultRole models.Role
+		if err := tx.Where("name = ?", "user").First(&defaultRole).Error; err == nil {
+			tx.Model(&user).Association("Roles").Append(&defaultRole)
+		}
 
-	// Create initial diamond balance (1000 starting diamonds)
-	diamond := models.Diamond{
-		UserID:      user.ID,
-		Amount:      1000,
-		Balance:     1000,
-		Type:        "bonus",
-		Description: "Welcome bonus",
-	}
-	if err := tx.Create(&diamond).Error; err != nil {
-		tx.Rollback()
+		// Create initial diamond balance (1000 starting diamonds)
+		diamond := models.Diamond{
+			UserID:      user.ID,
+			Amount:      1000,
+			Balance:     1000,
+			Type:        "bonus",
+			Description: "Welcome bonus",
+		}
+		return tx.Create(&diamond).Error
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":      "Registration failed",
 			"request_id": requestID,
@@ -186,8 +183,6 @@ func (h *SecureAuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	tx.Commit()
-
 	// Generate token
 	token, err := h.authService.GenerateToken(&user)
 	if err != nil {
@@ -258,6 +253,7 @@ func (h *SecureAuthHandler) Login(c *gin.Context) {
 
 	// Check if user is active
 	if !user.IsActive {
+		h.recordLogin(c, user.ID, false, "account_disabled")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":      "Account disabled",
 			"request_id": requestID,
@@ -267,6 +263,7 @@ func (h *SecureAuthHandler) Login(c *gin.Context) {
 
 	// Verify password
 	if err := h.authService.CheckPassword(user.Password, req.Password); err != nil {
+		h.recordLogin(c, user.ID, false, "invalid_password")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":      "Invalid credentials",
 			"request_id": requestID,
@@ -283,6 +280,14 @@ func (h *SecureAuthHandler) Login(c *gin.Context) {
 		})
 		return
 	}
+	h.recordLogin(c, user.ID, true, "")
+
+	// Keep the user's detected region current for compliance reporting
+	if region := c.GetString("region"); region != "" && region != user.Region {
+		if err := h.db.Model(&user).Update("region", region).Error; err != nil {
+			log.Printf("Failed to update region for user %d: %v", user.ID, err)
+		}
+	}
 
 	// Return secure response
 	// Convert roles to secure format
@@ -312,6 +317,26 @@ func (h *SecureAuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// recordLogin stores a sign-in attempt for the user's activity timeline.
+// Failures to record are logged and never block the login itself.
+func (h *SecureAuthHandler) recordLogin(c *gin.Context, userID uint, success bool, reason string) {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	event := models.LoginEvent{
+		UserID:    userID,
+		Success:   success,
+		Reason:    reason,
+		IPAddress: c.ClientIP(),
+		Region:    c.GetString("region"),
+		UserAgent: userAgent,
+	}
+	if err := h.db.Create(&event).Error; err != nil {
+		log.Printf("Failed to record login for user %d: %v", userID, err)
+	}
+}
+
 func (h *SecureAuthHandler) GetProfile(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
 	userID, exists := c.Get("user_id")
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"caslette-server/websocket_v2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Bot token limits
+const (
+	BotTokenPrefix          = websocket_v2.BotTokenPrefix
+	MaxActiveBotTokens      = 5
+	MaxBotTokenTables       = 20
+	MaxBotTokenGameTypes    = 5
+	DefaultBotTokenLifetime = 90 * 24 * time.Hour
+)
+
+var (
+	validBotTableID  = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+	validBotGameType = regexp.MustCompile(`^[a-z_]{1,30}$`)
+)
+
+// ErrInvalidBotToken is returned for unknown, revoked or expired bot tokens
+var ErrInvalidBotToken = errors.New("invalid or expired bot token")
+
+// CreateBotTokenRequest mints a bot token scoped to tables and/or game types
+type CreateBotTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=50"`
+	TableIDs      []string `json:"table_ids"`
+	GameTypes     []string `json:"game_types"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=365"`
+}
+
+// BotTokenResponse describes a bot token without its secret
+type BotTokenResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	TableIDs    []string   `json:"table_ids"`
+	GameTypes   []string   `json:"game_types"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// SecureBotTokenHandler lets users mint and revoke bot API tokens
+type SecureBotTokenHandler struct {
+	db        *gorm.DB
+	validator *SecurityValidator
+}
+
+// NewSecureBotTokenHandler creates a new bot token handler
+func NewSecureBotTokenHandler(db *gorm.DB) *SecureBotTokenHandler {
+	return &SecureBotTokenHandler{
+		db:        db,
+		validator: NewSecurityValidator(),
+	}
+}
+
+// Backward compatibility alias
+func NewBotTokenHandler(db *gorm.DB) *SecureBotTokenHandler {
+	return NewSecureBotTokenHandler(db)
+}
+
+// CreateBotToken handles POST /api/v1/bot-tokens. The plaintext token is only
+// returned in this response.
+func (h *SecureBotTokenHandler) CreateBotToken(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req CreateBotTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	name, err := h.validator.SanitizeFreeText(req.Name, "name", 50)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid name: " + err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if err := validateBotScope(req.TableIDs, req.GameTypes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var active int64
+	if err := h.db.Model(&models.BotToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Count(&active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Database error",
+			"request_id": requestID,
+		})
+		return
+	}
+	if active >= MaxActiveBotTokens {
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error":      "Too many active bot tokens; revoke one first",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	plaintext, err := generateBotToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to generate token",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	lifetime := DefaultBotTokenLifetime
+	if req.ExpiresInDays > 0 {
+		lifetime = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	expiresAt := time.Now().Add(lifetime)
+
+	tableIDs, _ := json.Marshal(nonNilStrings(req.TableIDs))
+	gameTypes, _ := json.Marshal(nonNilStrings(req.GameTypes))
+	token := models.BotToken{
+		UserID:      userID.(uint),
+		Name:        name,
+		TokenHash:   hashBotToken(plaintext),
+		TokenPrefix: plaintext[:len(BotTokenPrefix)+8],
+		TableIDs:    string(tableIDs),
+		GameTypes:   string(gameTypes),
+		ExpiresAt:   &expiresAt,
+	}
+	if err := h.db.Create(&token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to create bot token",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"token":     plaintext,
+			"bot_token": botTokenResponse(&token),
+		},
+		"message":    "Store this token now; it will not be shown again",
+		"request_id": requestID,
+	})
+}
+
+// GetBotTokens handles GET /api/v1/bot-tokens, listing the caller's tokens
+func (h *SecureBotTokenHandler) GetBotTokens(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var tokens []models.BotToken
+	if err := h.db.Where("user_id = ?", userID).Order("created_at desc").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Database error",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	responses := make([]BotTokenResponse, len(tokens))
+	for i := range tokens {
+		responses[i] = botTokenResponse(&tokens[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"bot_tokens": responses},
+		"request_id": requestID,
+	})
+}
+
+// RevokeBotToken handles DELETE /api/v1/bot-tokens/:id for the token owner
+func (h *SecureBotTokenHandler) RevokeBotToken(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	tokenID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid token ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	result := h.db.Model(&models.BotToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to revoke bot token",
+			"request_id": requestID,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Bot token not found",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Bot token revoked",
+		"request_id": requestID,
+	})
+}
+
+// AuthenticateBotToken resolves a plaintext bot token to its record and active
+// owner, recording when it was last used
+func AuthenticateBotToken(db *gorm.DB, plaintext string) (*models.BotToken, *models.User, error) {
+	var token models.BotToken
+	err := db.Preload("User").
+		Where("token_hash = ? AND revoked_at IS NULL", hashBotToken(plaintext)).
+		First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, ErrInvalidBotToken
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		return nil, nil, ErrInvalidBotToken
+	}
+	if !token.User.IsActive {
+		return nil, nil, ErrInvalidBotToken
+	}
+
+	db.Model(&token).Update("last_used_at", now)
+	return &token, &token.User, nil
+}
+
+// BotTokenScope decodes the table and game type whitelists of a token
+func BotTokenScope(token *models.BotToken) (tableIDs []string, gameTypes []string) {
+	if token.TableIDs != "" {
+		json.Unmarshal([]byte(token.TableIDs), &tableIDs)
+	}
+	if token.GameTypes != "" {
+		json.Unmarshal([]byte(token.GameTypes), &gameTypes)
+	}
+	return tableIDs, gameTypes
+}
+
+// validateBotScope requires at least one whitelist entry and checks formats
+func validateBotScope(tableIDs, gameTypes []string) error {
+	if len(tableIDs) == 0 && len(gameTypes) == 0 {
+		return errors.New("bot tokens must be scoped to at least one table or game type")
+	}
+	if len(tableIDs) > MaxBotTokenTables {
+		return errors.New("too many tables in bot token scope")
+	}
+	if len(gameTypes) > MaxBotTokenGameTypes {
+		return errors.New("too many game types in bot token scope")
+	}
+	for _, tableID := range tableIDs {
+		if !validBotTableID.MatchString(tableID) {
+			return errors.New("invalid table ID in bot token scope")
+		}
+	}
+	for _, gameType := range gameTypes {
+		if !validBotGameType.MatchString(gameType) {
+			return errors.New("invalid game type in bot token scope")
+		}
+	}
+	return nil
+}
+
+// generateBotToken creates a random prefixed token
+func generateBotToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return BotTokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+// hashBotToken hashes a token for storage and lookup
+func hashBotToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// botTokenResponse converts a token record for API responses
+func botTokenResponse(token *models.BotToken) BotTokenResponse {
+	tableIDs, gameTypes := BotTokenScope(token)
+	return BotTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		TableIDs:    nonNilStrings(tableIDs),
+		GameTypes:   nonNilStrings(gameTypes),
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		RevokedAt:   token.RevokedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+// nonNilStrings keeps empty lists encoding as [] rather than null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
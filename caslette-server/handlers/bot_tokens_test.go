@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureBotTokenHandler_RequiresAuth(t *testing.T) {
+	handler := NewSecureBotTokenHandler(nil)
+
+	c, w := newDisputeContext("POST", "/bot-tokens", map[string]interface{}{"name": "bot", "game_types": []string{"texas_holdem"}}, nil)
+	handler.CreateBotToken(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newDisputeContext("GET", "/bot-tokens", nil, nil)
+	handler.GetBotTokens(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureBotTokenHandler_CreateBotToken_RequiresScope(t *testing.T) {
+	handler := NewSecureBotTokenHandler(nil)
+
+	c, w := newDisputeContext("POST", "/bot-tokens", map[string]interface{}{"name": "my bot"}, uint(1))
+	handler.CreateBotToken(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Contains(t, response["error"], "scoped")
+}
+
+func TestSecureBotTokenHandler_CreateBotToken_InvalidScope(t *testing.T) {
+	handler := NewSecureBotTokenHandler(nil)
+
+	c, w := newDisputeContext("POST", "/bot-tokens", map[string]interface{}{
+		"name":      "my bot",
+		"table_ids": []string{"table' OR 1=1"},
+	}, uint(1))
+	handler.CreateBotToken(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	c, w = newDisputeContext("POST", "/bot-tokens", map[string]interface{}{
+		"name":            "my bot",
+		"game_types":      []string{"texas_holdem"},
+		"expires_in_days": 1000,
+	}, uint(1))
+	handler.CreateBotToken(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureBotTokenHandler_RevokeBotToken_InvalidID(t *testing.T) {
+	handler := NewSecureBotTokenHandler(nil)
+
+	c, w := newDisputeContext("DELETE", "/bot-tokens/abc", nil, uint(1))
+	handler.RevokeBotToken(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGenerateBotToken(t *testing.T) {
+	token, err := generateBotToken()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, BotTokenPrefix))
+
+	other, _ := generateBotToken()
+	assert.NotEqual(t, token, other)
+
+	assert.Equal(t, hashBotToken(token), hashBotToken(token))
+	assert.NotEqual(t, hashBotToken(token), hashBotToken(other))
+	assert.Len(t, hashBotToken(token), 64)
+}
+
+func TestBotTokenScope(t *testing.T) {
+	tableIDs, gameTypes := BotTokenScope(&models.BotToken{TableIDs: `["t1","t2"]`, GameTypes: `["texas_holdem"]`})
+	assert.Equal(t, []string{"t1", "t2"}, tableIDs)
+	assert.Equal(t, []string{"texas_holdem"}, gameTypes)
+
+	tableIDs, gameTypes = BotTokenScope(&models.BotToken{})
+	assert.Empty(t, tableIDs)
+	assert.Empty(t, gameTypes)
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GenesisHash is the previous hash of the first certification entry
+var GenesisHash = strings.Repeat("0", 64)
+
+// certificationVerifyBatch is how many entries verification reads at a time
+const certificationVerifyBatch = 500
+
+// CertificationHash is the SHA-256 of an entry's sequence, kind, subject,
+// table, time, outcome and the previous entry's hash, one per line. Anyone
+// holding the entries can recompute it.
+func CertificationHash(entry models.CertificationEntry) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		strconv.FormatInt(entry.Sequence, 10),
+		entry.Kind,
+		entry.Subject,
+		entry.TableID,
+		entry.OccurredAt.UTC().Format(time.RFC3339),
+		entry.Outcome,
+		entry.PrevHash,
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// CertificationLogStore appends game outcomes to the certification_entries
+// table, each chained to the one before by its hash. Entries are only ever
+// appended; nothing updates or deletes them.
+type CertificationLogStore struct {
+	db *gorm.DB
+	mu sync.Mutex // Serializes appends so each links to the latest entry
+}
+
+// NewCertificationLogStore creates a store over the certification log
+func NewCertificationLogStore(db *gorm.DB) *CertificationLogStore {
+	return &CertificationLogStore{db: db}
+}
+
+// Certify appends an outcome to the chain
+func (s *CertificationLogStore) Certify(outcome game.CertifiedOutcome) error {
+	encoded, err := json.Marshal(outcome.Outcome)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return WithTransaction(context.Background(), s.db, func(tx *gorm.DB) error {
+		entry := models.CertificationEntry{
+			Sequence:   1,
+			Kind:       outcome.Kind,
+			Subject:    outcome.Subject,
+			TableID:    outcome.TableID,
+			Outcome:    string(encoded),
+			OccurredAt: outcome.OccurredAt.UTC().Truncate(time.Second),
+			PrevHash:   GenesisHash,
+		}
+		var head models.CertificationEntry
+		err := tx.Order("sequence desc").First(&head).Error
+		switch {
+		case err == nil:
+			entry.Sequence, entry.PrevHash = head.Sequence+1, head.Hash
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		entry.Hash = CertificationHash(entry)
+		return tx.Create(&entry).Error
+	})
+}
+
+// Entries returns up to limit entries from a sequence number on, in order
+func (s *CertificationLogStore) Entries(fromSequence int64, limit int) ([]models.CertificationEntry, error) {
+	var entries []models.CertificationEntry
+	err := s.db.Where("sequence >= ?", fromSequence).Order("sequence").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// EntryFor returns the entry certifying a subject, or gorm.ErrRecordNotFound
+func (s *CertificationLogStore) EntryFor(kind, subject string) (*models.CertificationEntry, error) {
+	var entry models.CertificationEntry
+	if err := s.db.Where("kind = ? AND subject = ?", kind, subject).Order("sequence").First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CertificationReport is the result of verifying the chain
+type CertificationReport struct {
+	Valid        bool   `json:"valid"`
+	Entries      int64  `json:"entries"`
+	HeadSequence int64  `json:"head_sequence"`
+	HeadHash     string `json:"head_hash"`
+	BrokenAt     int64  `json:"broken_at,omitempty"` // First sequence that fails verification
+	Reason       string `json:"reason,omitempty"`
+}
+
+// Verify recomputes every entry's hash and checks it links to the entry
+// before it with no sequence numbers missing
+func (s *CertificationLogStore) Verify() (CertificationReport, error) {
+	report := CertificationReport{Valid: true, HeadHash: GenesisHash}
+	for {
+		batch, err := s.Entries(report.HeadSequence+1, certificationVerifyBatch)
+		if err != nil {
+			return report, err
+		}
+		for _, entry := range batch {
+			if reason := verifyLink(entry, report.HeadSequence, report.HeadHash); reason != "" {
+				report.Valid, report.BrokenAt, report.Reason = false, report.HeadSequence+1, reason
+				return report, nil
+			}
+			report.Entries++
+			report.HeadSequence, report.HeadHash = entry.Sequence, entry.Hash
+		}
+		if len(batch) < certificationVerifyBatch {
+			break
+		}
+	}
+
+	// Entries removed from the end leave the chain intact; the count shows it
+	var total int64
+	if err := s.db.Model(&models.CertificationEntry{}).Count(&total).Error; err != nil {
+		return report, err
+	}
+	if total != report.Entries {
+		report.Valid, report.BrokenAt = false, report.HeadSequence+1
+		report.Reason = fmt.Sprintf("%d entries are not part of the chain", total-report.Entries)
+	}
+	return report, nil
+}
+
+// verifyLink checks an entry follows the one with the given sequence and
+// hash, returning why it does not
+func verifyLink(entry models.CertificationEntry, prevSequence int64, prevHash string) string {
+	switch {
+	case entry.Sequence != prevSequence+1:
+		return fmt.Sprintf("entry %d is missing", prevSequence+1)
+	case entry.PrevHash != prevHash:
+		return fmt.Sprintf("entry %d does not link to the entry before it", entry.Sequence)
+	case CertificationHash(entry) != entry.Hash:
+		return fmt.Sprintf("entry %d does not match its hash", entry.Sequence)
+	}
+	return ""
+}
+
+// VerifyEntry checks one entry matches its hash and links to the entry
+// before it
+func (s *CertificationLogStore) VerifyEntry(entry models.CertificationEntry) (bool, error) {
+	prevHash := GenesisHash
+	if entry.Sequence > 1 {
+		var prev models.CertificationEntry
+		err := s.db.Where("sequence = ?", entry.Sequence-1).First(&prev).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		prevHash = prev.Hash
+	}
+	return verifyLink(entry, entry.Sequence-1, prevHash) == "", nil
+}
+
+// CertificationHandler serves the certification log over REST
+type CertificationHandler struct {
+	store     *CertificationLogStore
+	validator *SecurityValidator
+}
+
+// NewCertificationHandler creates a handler over the certification log
+func NewCertificationHandler(store *CertificationLogStore) *CertificationHandler {
+	return &CertificationHandler{store: store, validator: NewSecurityValidator()}
+}
+
+// Verify handles GET /api/v1/certification/verify: whether the whole chain
+// is intact, and its head hash to compare against a published one
+func (h *CertificationHandler) Verify(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	report, err := h.store.Verify()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to verify certification log",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       report,
+		"request_id": requestID,
+	})
+}
+
+// GetHandEntry handles GET /api/v1/certification/hands/:hand_id: the entry
+// certifying a hand's result and whether it checks out, for players dealt
+// into the hand and admins
+func (h *CertificationHandler) GetHandEntry(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	userID := c.GetUint("user_id")
+
+	entry, err := h.store.EntryFor(game.CertifiedHandResult, c.Param("hand_id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Hand not certified",
+			"request_id": requestID,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch certification entry",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var outcome game.HandOutcome
+	if err := json.Unmarshal([]byte(entry.Outcome), &outcome); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to read certification entry",
+			"request_id": requestID,
+		})
+		return
+	}
+	if !slices.Contains(outcome.Players, strconv.FormatUint(uint64(userID), 10)) && !h.hasAdminPermission(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Access denied",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	verified, err := h.store.VerifyEntry(*entry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to verify certification entry",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"entry":    entry,
+			"verified": verified,
+		},
+		"request_id": requestID,
+	})
+}
+
+// ListEntries handles GET /api/v1/admin/certification/entries: a run of the
+// chain from ?from_sequence (default 1), up to ?limit entries, so it can be
+// exported and checked independently
+func (h *CertificationHandler) ListEntries(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	from, err := h.validator.ValidatePositiveInt(c.DefaultQuery("from_sequence", "1"), "from_sequence")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "from_sequence must be a positive number",
+			"request_id": requestID,
+		})
+		return
+	}
+	limit, err := h.validator.ValidatePositiveInt(c.DefaultQuery("limit", strconv.Itoa(MaxHandHistoryLimit)), "limit")
+	if err != nil || limit > certificationVerifyBatch {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "limit must be between 1 and " + strconv.Itoa(certificationVerifyBatch),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	entries, err := h.store.Entries(int64(from), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch certification entries",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"entries": entries},
+		"request_id": requestID,
+	})
+}
+
+// hasAdminPermission reports whether a user holds the admin role
+func (h *CertificationHandler) hasAdminPermission(userID uint) bool {
+	isAdmin, err := middleware.HasRole(h.store.db, userID, "admin")
+	return err == nil && isAdmin
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newCertificationStore opens a certification log holding the given hands
+func newCertificationStore(t *testing.T, hands ...game.HandRecord) (*gorm.DB, *CertificationLogStore) {
+	db := newHandHistoryDB(t, 2640)
+	require.NoError(t, db.AutoMigrate(&models.CertificationEntry{}))
+	store := NewCertificationLogStore(db)
+	for _, hand := range hands {
+		require.NoError(t, store.Certify(game.CertifiedOutcome{
+			Kind:       game.CertifiedHandResult,
+			Subject:    hand.HandID,
+			TableID:    hand.TableID,
+			Outcome:    game.NewHandOutcome(hand),
+			OccurredAt: hand.EndedAt,
+		}))
+	}
+	return db, store
+}
+
+func TestCertificationLogStore_ChainsEntries(t *testing.T) {
+	now := time.Now()
+	_, store := newCertificationStore(t, testHand("t1", 1, now), testHand("t1", 2, now), testHand("t2", 1, now))
+
+	entries, err := store.Entries(1, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, GenesisHash, entries[0].PrevHash)
+	for i, entry := range entries {
+		assert.Equal(t, int64(i+1), entry.Sequence)
+		assert.Equal(t, CertificationHash(entry), entry.Hash)
+		if i > 0 {
+			assert.Equal(t, entries[i-1].Hash, entry.PrevHash)
+		}
+	}
+
+	report, err := store.Verify()
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, int64(3), report.Entries)
+	assert.Equal(t, entries[2].Hash, report.HeadHash)
+}
+
+func TestCertificationLogStore_DetectsTampering(t *testing.T) {
+	now := time.Now()
+	db, store := newCertificationStore(t, testHand("t1", 1, now), testHand("t1", 2, now), testHand("t1", 3, now))
+
+	assert.Error(t, db.Where("sequence = ?", 2).Delete(&models.CertificationEntry{}).Error, "entries cannot be deleted")
+	assert.Error(t, db.Model(&models.CertificationEntry{}).Where("sequence = ?", 2).Update("kind", "edited").Error, "or changed")
+
+	// Edits made behind the store's back break the chain
+	require.NoError(t, db.Exec("UPDATE certification_entries SET outcome = ? WHERE sequence = 2", `{"winners":["2642"]}`).Error)
+	report, err := store.Verify()
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, int64(2), report.BrokenAt)
+	assert.Equal(t, "entry 2 does not match its hash", report.Reason)
+
+	entry, err := store.EntryFor(game.CertifiedHandResult, "t1-3")
+	require.NoError(t, err)
+	verified, err := store.VerifyEntry(*entry)
+	require.NoError(t, err)
+	assert.True(t, verified, "later entries still link to the stored hash")
+
+	require.NoError(t, db.Exec("DELETE FROM certification_entries WHERE sequence = 3").Error)
+	require.NoError(t, db.Exec("DELETE FROM certification_entries WHERE sequence = 2").Error)
+	report, err = store.Verify()
+	require.NoError(t, err)
+	assert.True(t, report.Valid, "an entry lost from the end is only seen against the published head")
+	assert.Equal(t, int64(1), report.HeadSequence)
+
+	require.NoError(t, store.Certify(game.CertifiedOutcome{Kind: game.CertifiedHandResult, Subject: "t1-4", OccurredAt: now}))
+	require.NoError(t, db.Exec("DELETE FROM certification_entries WHERE sequence = 1").Error)
+	report, err = store.Verify()
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, "entry 1 is missing", report.Reason)
+}
+
+func TestCertificationHandler_GetHandEntry(t *testing.T) {
+	_, store := newCertificationStore(t, testHand("t1", 1, time.Now()))
+	handler := NewCertificationHandler(store)
+
+	w, response := performHandHistoryRequest(handler.GetHandEntry, 2642, "/certification/hands/t1-1", "t1-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, true, data["verified"])
+	assert.Equal(t, float64(1), data["entry"].(map[string]interface{})["sequence"])
+
+	w, _ = performHandHistoryRequest(handler.GetHandEntry, 2643, "/certification/hands/t1-1", "t1-1")
+	assert.Equal(t, http.StatusForbidden, w.Code, "only players dealt in and admins")
+	w, _ = performHandHistoryRequest(handler.GetHandEntry, 2640, "/certification/hands/t1-1", "t1-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = performHandHistoryRequest(handler.GetHandEntry, 2642, "/certification/hands/t9-1", "t9-1")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, response = performHandHistoryRequest(handler.Verify, 2643, "/certification/verify", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, response["data"].(map[string]interface{})["valid"])
+}
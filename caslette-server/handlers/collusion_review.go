@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Collusion review states
+const (
+	CollusionStatusOpen      = "open"
+	CollusionStatusConfirmed = "confirmed"
+	CollusionStatusDismissed = "dismissed"
+)
+
+// Collusion review queue listing limits
+const (
+	DefaultCollusionLimit = 50
+	MaxCollusionLimit     = 200
+)
+
+// CollusionReviewStore persists collusion flags for admins to review
+type CollusionReviewStore struct {
+	db *gorm.DB
+}
+
+// NewCollusionReviewStore creates a store over the collusion_flags table
+func NewCollusionReviewStore(db *gorm.DB) *CollusionReviewStore {
+	return &CollusionReviewStore{db: db}
+}
+
+// QueueCollusionFlag adds a flag to the review queue
+func (s *CollusionReviewStore) QueueCollusionFlag(flag game.CollusionFlag) error {
+	handIDs, err := json.Marshal(flag.HandIDs)
+	if err != nil {
+		return err
+	}
+	record := models.CollusionFlag{
+		Kind:      flag.Kind,
+		TableID:   flag.TableID,
+		HandIDs:   string(handIDs),
+		Hands:     flag.Hands,
+		Score:     flag.Score,
+		Details:   flag.Details,
+		Status:    CollusionStatusOpen,
+		CreatedAt: flag.RaisedAt,
+	}
+	if len(flag.Players) == 2 {
+		record.PlayerID, record.OpponentID = flag.Players[0], flag.Players[1]
+	}
+	return s.db.Create(&record).Error
+}
+
+// CollusionReviewHandler serves the collusion review queue to admins
+type CollusionReviewHandler struct {
+	db        *gorm.DB
+	validator *SecurityValidator
+}
+
+// ReviewCollusionRequest is the payload for an admin deciding a flag
+type ReviewCollusionRequest struct {
+	Status string `json:"status" binding:"required"`
+	Notes  string `json:"notes" binding:"max=500"`
+}
+
+// NewCollusionReviewHandler creates a handler over the store's table
+func NewCollusionReviewHandler(store *CollusionReviewStore) *CollusionReviewHandler {
+	return &CollusionReviewHandler{db: store.db, validator: NewSecurityValidator()}
+}
+
+// ListFlags handles GET /api/v1/admin/collusion/flags: flags oldest first,
+// filtered by ?status (default open), ?kind and ?player_id, which matches
+// either player
+func (h *CollusionReviewHandler) ListFlags(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	status := c.DefaultQuery("status", CollusionStatusOpen)
+	if !isCollusionStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid status filter",
+			"request_id": requestID,
+		})
+		return
+	}
+	limit, err := h.validator.ValidatePositiveInt(c.DefaultQuery("limit", strconv.Itoa(DefaultCollusionLimit)), "limit")
+	if err != nil || limit > MaxCollusionLimit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "limit must be between 1 and " + strconv.Itoa(MaxCollusionLimit),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	query := h.db.Model(&models.CollusionFlag{}).Where("status = ?", status)
+	if kind := c.Query("kind"); kind != "" {
+		if !auditFilterPattern.MatchString(kind) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid kind filter",
+				"request_id": requestID,
+			})
+			return
+		}
+		query = query.Where("kind = ?", kind)
+	}
+	if playerID := c.Query("player_id"); playerID != "" {
+		if !auditFilterPattern.MatchString(playerID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid player_id filter",
+				"request_id": requestID,
+			})
+			return
+		}
+		query = query.Where("player_id = ? OR opponent_id = ?", playerID, playerID)
+	}
+
+	flags := make([]models.CollusionFlag, 0)
+	if err := query.Order("created_at asc").Order("id asc").Limit(limit).Find(&flags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch collusion flags",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"flags": flags},
+		"request_id": requestID,
+	})
+}
+
+// ReviewFlag handles POST /api/v1/admin/collusion/flags/:id/review, closing
+// an open flag as confirmed or dismissed
+func (h *CollusionReviewHandler) ReviewFlag(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	flagID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid flag ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req ReviewCollusionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+	if req.Status != CollusionStatusConfirmed && req.Status != CollusionStatusDismissed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Status must be confirmed or dismissed",
+			"request_id": requestID,
+		})
+		return
+	}
+	notes := ""
+	if req.Notes != "" {
+		notes, err = h.validator.SanitizeFreeText(req.Notes, "notes", 500)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid notes: " + err.Error(),
+				"request_id": requestID,
+			})
+			return
+		}
+	}
+
+	var flag models.CollusionFlag
+	err = WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		if err := tx.First(&flag, flagID).Error; err != nil {
+			return txAbort(http.StatusNotFound, "Flag not found")
+		}
+		if flag.Status != CollusionStatusOpen {
+			return txAbort(http.StatusConflict, "Flag has already been "+flag.Status)
+		}
+
+		reviewerID := c.GetUint("user_id")
+		now := time.Now()
+		flag.Status = req.Status
+		flag.ReviewNotes = notes
+		flag.ReviewedBy = &reviewerID
+		flag.ReviewedAt = &now
+		if err := tx.Save(&flag).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to update flag")
+		}
+		return nil
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to commit transaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       flag,
+		"request_id": requestID,
+	})
+}
+
+// isCollusionStatus reports whether status is a known review state
+func isCollusionStatus(status string) bool {
+	switch status {
+	case CollusionStatusOpen, CollusionStatusConfirmed, CollusionStatusDismissed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCollusionReviewHandler(t *testing.T) (*CollusionReviewStore, *CollusionReviewHandler) {
+	db := newSQLiteDB(t, &models.CollusionFlag{})
+	store := NewCollusionReviewStore(db)
+	return store, NewCollusionReviewHandler(store)
+}
+
+func TestCollusionReviewStore_QueuesAndListsFlags(t *testing.T) {
+	store, handler := newCollusionReviewHandler(t)
+	raised := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.QueueCollusionFlag(game.CollusionFlag{
+		Kind: game.CollusionChipDumping, Players: []string{"7", "9"}, TableID: "t1",
+		HandIDs: []string{"t1-3", "t1-5"}, Hands: 2, Score: 140, Details: "7 lost 140 big blinds to 9", RaisedAt: raised,
+	}))
+	require.NoError(t, store.QueueCollusionFlag(game.CollusionFlag{
+		Kind: game.CollusionSoftPlay, Players: []string{"4", "7"}, TableID: "t2", RaisedAt: raised.Add(time.Minute),
+	}))
+
+	c, w := newDisputeContext("GET", "/admin/collusion/flags?player_id=7", nil, uint(1))
+	handler.ListFlags(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Flags []models.CollusionFlag `json:"flags"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	flags := response.Data.Flags
+	require.Len(t, flags, 2, "either player matches")
+	assert.Equal(t, game.CollusionChipDumping, flags[0].Kind, "oldest first")
+	assert.Equal(t, "7", flags[0].PlayerID)
+	assert.Equal(t, "9", flags[0].OpponentID)
+	assert.JSONEq(t, `["t1-3","t1-5"]`, flags[0].HandIDs)
+	assert.Equal(t, CollusionStatusOpen, flags[0].Status)
+
+	c, w = newDisputeContext("GET", "/admin/collusion/flags?kind=soft_play", nil, uint(1))
+	handler.ListFlags(c)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Flags, 1)
+
+	c, w = newDisputeContext("GET", "/admin/collusion/flags?status=closed", nil, uint(1))
+	handler.ListFlags(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCollusionReviewHandler_ReviewFlag(t *testing.T) {
+	store, handler := newCollusionReviewHandler(t)
+	require.NoError(t, store.QueueCollusionFlag(game.CollusionFlag{
+		Kind: game.CollusionFoldPattern, Players: []string{"7", "9"}, RaisedAt: time.Now(),
+	}))
+	review := func(id string, body map[string]interface{}) int {
+		c, w := newDisputeContext("POST", "/admin/collusion/flags/"+id+"/review", body, uint(1))
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		handler.ReviewFlag(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, review("1", map[string]interface{}{"status": "open"}), "flags are closed by review")
+	assert.Equal(t, http.StatusNotFound, review("2", map[string]interface{}{"status": "dismissed"}))
+	assert.Equal(t, http.StatusOK, review("1", map[string]interface{}{"status": "confirmed", "notes": "Same household"}))
+	assert.Equal(t, http.StatusConflict, review("1", map[string]interface{}{"status": "dismissed"}), "a decided flag stays decided")
+
+	var flag models.CollusionFlag
+	require.NoError(t, store.db.First(&flag, 1).Error)
+	assert.Equal(t, CollusionStatusConfirmed, flag.Status)
+	assert.Equal(t, "Same household", flag.ReviewNotes)
+	require.NotNil(t, flag.ReviewedBy)
+	assert.Equal(t, uint(1), *flag.ReviewedBy)
+	assert.NotNil(t, flag.ReviewedAt)
+
+	c, w := newDisputeContext("GET", "/admin/collusion/flags", nil, uint(1))
+	handler.ListFlags(c)
+	assert.NotContains(t, w.Body.String(), `"id":1`, "reviewed flags leave the open queue")
+}
@@ -0,0 +1,571 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Cosmetic kinds; a user equips at most one item of each kind
+const (
+	CosmeticKindDeck  = "deck"  // Card faces and backs
+	CosmeticKindTable = "table" // Felt and table frame
+)
+
+// validCosmeticKinds lists the kinds clients know how to render
+var validCosmeticKinds = map[string]bool{
+	CosmeticKindDeck:  true,
+	CosmeticKindTable: true,
+}
+
+// validCosmeticSKU restricts SKUs to lowercase slugs such as "deck_neon"
+var validCosmeticSKU = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// CosmeticItemRequest creates a shop item
+type CosmeticItemRequest struct {
+	SKU      string                 `json:"sku" binding:"required"`
+	Name     string                 `json:"name" binding:"required,max=100"`
+	Kind     string                 `json:"kind" binding:"required"`
+	Price    int64                  `json:"price" binding:"min=0"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// CosmeticItemUpdate changes a shop item's price or availability
+type CosmeticItemUpdate struct {
+	Price  *int64 `json:"price" binding:"omitempty,min=0"`
+	Active *bool  `json:"active"`
+}
+
+// SecureCosmeticHandler runs the cosmetics shop and user inventories.
+// Purchases are debited from the diamond ledger.
+type SecureCosmeticHandler struct {
+	db           *gorm.DB
+	validator    *SecurityValidator
+	tableManager *game.ActorTableManager // Seats refreshed when equipment changes
+}
+
+// NewSecureCosmeticHandler creates a new cosmetics handler
+func NewSecureCosmeticHandler(db *gorm.DB, tableManager *game.ActorTableManager) *SecureCosmeticHandler {
+	return &SecureCosmeticHandler{
+		db:           db,
+		validator:    NewSecurityValidator(),
+		tableManager: tableManager,
+	}
+}
+
+// Backward compatibility alias
+func NewCosmeticHandler(db *gorm.DB, tableManager *game.ActorTableManager) *SecureCosmeticHandler {
+	return NewSecureCosmeticHandler(db, tableManager)
+}
+
+// EquippedCosmetics returns a user's equipped items as kind -> SKU, the form
+// shown on table seats
+func EquippedCosmetics(db *gorm.DB, userID uint) (map[string]string, error) {
+	var owned []models.UserCosmetic
+	if err := db.Preload("Item").Where("user_id = ? AND equipped = ?", userID, true).Find(&owned).Error; err != nil {
+		return nil, err
+	}
+	return equippedByKind(owned), nil
+}
+
+// equippedByKind maps equipped inventory entries to kind -> SKU
+func equippedByKind(owned []models.UserCosmetic) map[string]string {
+	equipped := make(map[string]string, len(owned))
+	for _, entry := range owned {
+		if entry.Equipped {
+			equipped[entry.Item.Kind] = entry.Item.SKU
+		}
+	}
+	return equipped
+}
+
+// validateCosmeticItem normalizes and checks a new shop item
+func (h *SecureCosmeticHandler) validateCosmeticItem(req *CosmeticItemRequest) error {
+	req.SKU = strings.ToLower(strings.TrimSpace(req.SKU))
+	if !validCosmeticSKU.MatchString(req.SKU) {
+		return errors.New("sku must be 1-64 lowercase letters, digits or underscores")
+	}
+	req.Kind = strings.ToLower(strings.TrimSpace(req.Kind))
+	if !validCosmeticKinds[req.Kind] {
+		return fmt.Errorf("kind must be %q or %q", CosmeticKindDeck, CosmeticKindTable)
+	}
+	name, err := h.validator.SanitizeFreeText(req.Name, "name", 100)
+	if err != nil {
+		return fmt.Errorf("invalid name: %v", err)
+	}
+	req.Name = name
+	return nil
+}
+
+// GetCatalog handles GET /api/v1/cosmetics, listing items for sale.
+// Query parameters: kind (deck or table).
+func (h *SecureCosmeticHandler) GetCatalog(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	kind := strings.ToLower(c.Query("kind"))
+	if kind != "" && !validCosmeticKinds[kind] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid kind",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	query := h.db.Where("active = ?", true)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var items []models.CosmeticItem
+	if err := query.Order("kind, price, id").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load cosmetics",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       items,
+		"request_id": requestID,
+	})
+}
+
+// CreateItem handles POST /api/v1/cosmetics with admin authorization
+func (h *SecureCosmeticHandler) CreateItem(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req CosmeticItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+	if err := h.validateCosmeticItem(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	metadata := "{}"
+	if req.Metadata != nil {
+		encoded, err := json.Marshal(req.Metadata)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid metadata",
+				"request_id": requestID,
+			})
+			return
+		}
+		metadata = string(encoded)
+	}
+
+	item := models.CosmeticItem{
+		SKU:      req.SKU,
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Price:    req.Price,
+		Metadata: metadata,
+		Active:   true,
+	}
+	var count int64
+	if err := h.db.Model(&models.CosmeticItem{}).Where("sku = ?", item.SKU).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Database error",
+			"request_id": requestID,
+		})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error":      "An item with this SKU already exists",
+			"request_id": requestID,
+		})
+		return
+	}
+	if err := h.db.Create(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to create cosmetic",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":    true,
+		"data":       item,
+		"request_id": requestID,
+	})
+}
+
+// UpdateItem handles PUT /api/v1/cosmetics/:id with admin authorization.
+// Retired items stay in inventories but can no longer be bought.
+func (h *SecureCosmeticHandler) UpdateItem(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	itemID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid cosmetic ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req CosmeticItemUpdate
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Price == nil && req.Active == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var item models.CosmeticItem
+	if err := h.db.First(&item, itemID).Error; err != nil {
+		respondCosmeticLookupError(c, err, requestID)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if err := h.db.Model(&item).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to update cosmetic",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       item,
+		"request_id": requestID,
+	})
+}
+
+// GetInventory handles GET /api/v1/cosmetics/inventory for the caller
+func (h *SecureCosmeticHandler) GetInventory(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var owned []models.UserCosmetic
+	if err := h.db.Preload("Item").Where("user_id = ?", userID).Order("created_at").Find(&owned).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load inventory",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"items":    owned,
+			"equipped": equippedByKind(owned),
+		},
+		"request_id": requestID,
+	})
+}
+
+// Purchase handles POST /api/v1/cosmetics/:id/purchase, paying the item's
+// price from the caller's diamond balance
+func (h *SecureCosmeticHandler) Purchase(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	itemID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid cosmetic ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+	buyerID := userID.(uint)
+
+	var owned models.UserCosmetic
+	var newBalance int64
+	err = WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		var item models.CosmeticItem
+		if err := tx.Where("id = ? AND active = ?", itemID, true).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return txAbort(http.StatusNotFound, "Cosmetic not found")
+			}
+			return txAbort(http.StatusInternalServerError, "Database error")
+		}
+
+		// Lock the buyer so concurrent purchases cannot overspend the balance
+		var buyer models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&buyer, buyerID).Error; err != nil {
+			return txAbort(http.StatusNotFound, "User not found")
+		}
+
+		var count int64
+		if err := tx.Model(&models.UserCosmetic{}).Where("user_id = ? AND item_id = ?", buyerID, item.ID).Count(&count).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Database error")
+		}
+		if count > 0 {
+			return txAbort(http.StatusConflict, "You already own this cosmetic")
+		}
+
+		if err := tx.Model(&models.Diamond{}).
+			Where("user_id = ?", buyerID).
+			Select("COALESCE(SUM(amount), 0)").
+			Row().Scan(&newBalance); err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to calculate current balance")
+		}
+
+		owned = models.UserCosmetic{UserID: buyerID, ItemID: item.ID, Item: item}
+		if item.Price > 0 {
+			if newBalance < item.Price {
+				return &TxError{
+					Status:  http.StatusBadRequest,
+					Message: "Insufficient balance",
+					Details: gin.H{
+						"current_balance": newBalance,
+						"required":        item.Price,
+					},
+				}
+			}
+
+			newBalance -= item.Price
+			metadata, _ := json.Marshal(map[string]interface{}{"cosmetic_id": item.ID, "sku": item.SKU})
+			payment := models.Diamond{
+				UserID:      buyerID,
+				Amount:      -item.Price,
+				Balance:     newBalance,
+				Type:        "purchase",
+				Description: "Cosmetic: " + item.Name,
+				Metadata:    string(metadata),
+			}
+			if err := tx.Create(&payment).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to charge diamonds")
+			}
+			owned.TransactionID = payment.TransactionID
+		}
+
+		if err := tx.Omit("Item").Create(&owned).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to add cosmetic to inventory")
+		}
+		return nil
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to purchase cosmetic")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cosmetic":    owned,
+			"new_balance": newBalance,
+		},
+		"request_id": requestID,
+	})
+}
+
+// Equip handles PUT /api/v1/cosmetics/:id/equip, replacing any equipped item
+// of the same kind and refreshing the caller's seats
+func (h *SecureCosmeticHandler) Equip(c *gin.Context) {
+	h.setEquipped(c, true)
+}
+
+// Unequip handles DELETE /api/v1/cosmetics/:id/equip
+func (h *SecureCosmeticHandler) Unequip(c *gin.Context) {
+	h.setEquipped(c, false)
+}
+
+// setEquipped equips or unequips an owned item
+func (h *SecureCosmeticHandler) setEquipped(c *gin.Context, equip bool) {
+	requestID, _ := c.Get("request_id")
+
+	itemID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid cosmetic ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+	ownerID := userID.(uint)
+
+	err = WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		var owned models.UserCosmetic
+		if err := tx.Preload("Item").Where("user_id = ? AND item_id = ?", ownerID, itemID).First(&owned).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return txAbort(http.StatusNotFound, "You do not own this cosmetic")
+			}
+			return txAbort(http.StatusInternalServerError, "Database error")
+		}
+
+		if equip {
+			// One item per kind: unequip the others first
+			var sameKind []uint
+			if err := tx.Model(&models.CosmeticItem{}).Where("kind = ?", owned.Item.Kind).Pluck("id", &sameKind).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Database error")
+			}
+			if err := tx.Model(&models.UserCosmetic{}).
+				Where("user_id = ? AND item_id IN ? AND equipped = ?", ownerID, sameKind, true).
+				Update("equipped", false).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to update inventory")
+			}
+		}
+
+		if err := tx.Model(&owned).Update("equipped", equip).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to update inventory")
+		}
+		return nil
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to update inventory")
+		return
+	}
+
+	equipped, err := EquippedCosmetics(h.db, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load inventory",
+			"request_id": requestID,
+		})
+		return
+	}
+	tables := 0
+	if h.tableManager != nil {
+		tables = h.tableManager.UpdatePlayerCosmetics(context.Background(), strconv.FormatUint(uint64(ownerID), 10), equipped)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"equipped":       equipped,
+			"tables_updated": tables,
+		},
+		"request_id": requestID,
+	})
+}
+
+// respondCosmeticLookupError reports a failed item lookup
+func respondCosmeticLookupError(c *gin.Context, err error, requestID interface{}) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Cosmetic not found",
+			"request_id": requestID,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"success":    false,
+		"error":      "Database error",
+		"request_id": requestID,
+	})
+}
+
+// hasAdminPermission checks if user has admin role
+func (h *SecureCosmeticHandler) hasAdminPermission(userID uint) bool {
+	isAdmin, err := middleware.HasRole(h.db, userID, "admin")
+	return err == nil && isAdmin
+}
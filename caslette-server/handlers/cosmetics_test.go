@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockCosmeticHandler() *SecureCosmeticHandler {
+	return &SecureCosmeticHandler{
+		db:        nil, // No actual DB for unit tests
+		validator: NewSecurityValidator(),
+	}
+}
+
+func TestSecureCosmeticHandler_GetCatalog_InvalidKind(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	c, w := newDisputeContext("GET", "/cosmetics?kind=avatar", nil, uint(1))
+
+	handler.GetCatalog(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureCosmeticHandler_CreateItem_RequiresAuth(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	c, w := newDisputeContext("POST", "/cosmetics", map[string]interface{}{
+		"sku": "deck_neon", "name": "Neon", "kind": "deck", "price": 500,
+	}, nil)
+
+	handler.CreateItem(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureCosmeticHandler_CreateItem_InvalidFields(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	cases := []map[string]interface{}{
+		{"sku": "Deck Neon!", "name": "Neon", "kind": "deck"},
+		{"sku": "deck_neon", "name": "Neon", "kind": "avatar"},
+		{"sku": "deck_neon", "name": "Neon", "kind": "deck", "price": -1},
+		{"sku": "deck_neon", "kind": "deck"},
+	}
+	for _, body := range cases {
+		c, w := newDisputeContext("POST", "/cosmetics", body, uint(1))
+
+		handler.CreateItem(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%v", body)
+	}
+}
+
+func TestSecureCosmeticHandler_UpdateItem_InvalidID(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	c, w := newDisputeContext("PUT", "/cosmetics/abc", map[string]interface{}{"price": 100}, uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	handler.UpdateItem(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureCosmeticHandler_UpdateItem_EmptyUpdate(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	c, w := newDisputeContext("PUT", "/cosmetics/1", map[string]interface{}{}, uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.UpdateItem(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureCosmeticHandler_Purchase_RequiresAuth(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	c, w := newDisputeContext("POST", "/cosmetics/1/purchase", nil, nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.Purchase(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureCosmeticHandler_Equip_InvalidID(t *testing.T) {
+	handler := createMockCosmeticHandler()
+	c, w := newDisputeContext("PUT", "/cosmetics/0/equip", nil, uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "0"}}
+
+	handler.Equip(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestEquippedByKind(t *testing.T) {
+	equipped := equippedByKind([]models.UserCosmetic{
+		{Equipped: true, Item: models.CosmeticItem{SKU: "deck_neon", Kind: CosmeticKindDeck}},
+		{Equipped: false, Item: models.CosmeticItem{SKU: "felt_plain", Kind: CosmeticKindTable}},
+		{Equipped: true, Item: models.CosmeticItem{SKU: "felt_royal", Kind: CosmeticKindTable}},
+	})
+
+	assert.Equal(t, map[string]string{"deck": "deck_neon", "table": "felt_royal"}, equipped)
+}
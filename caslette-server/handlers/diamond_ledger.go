@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientDiamonds is returned when a debit exceeds the balance
+var ErrInsufficientDiamonds = errors.New("insufficient balance")
+
+// DiamondLedger records diamond debits and credits for games that need them,
+// such as Sit&Go entry fees and prizes
+type DiamondLedger struct {
+	db *gorm.DB
+}
+
+// NewDiamondLedger creates a ledger over the diamonds table
+func NewDiamondLedger(db *gorm.DB) *DiamondLedger {
+	return &DiamondLedger{db: db}
+}
+
+// Debit takes diamonds from a player, failing if they cannot afford it
+func (l *DiamondLedger) Debit(playerID string, amount int, description string) error {
+	return l.record(playerID, -int64(amount), "debit", description)
+}
+
+// Credit gives diamonds to a player
+func (l *DiamondLedger) Credit(playerID string, amount int, description string) error {
+	return l.record(playerID, int64(amount), "credit", description)
+}
+
+// record writes one transaction with the running balance, holding the user's
+// row lock so concurrent entries cannot overspend
+func (l *DiamondLedger) record(playerID string, amount int64, txType, description string) error {
+	userID, err := strconv.ParseUint(playerID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid player ID %q", playerID)
+	}
+	if amount == 0 {
+		return nil
+	}
+
+	return WithTransaction(context.Background(), l.db, func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return fmt.Errorf("user not found: %w", err)
+		}
+
+		var balance int64
+		if err := tx.Model(&models.Diamond{}).
+			Where("user_id = ?", userID).
+			Select("COALESCE(SUM(amount), 0)").
+			Row().Scan(&balance); err != nil {
+			return fmt.Errorf("failed to calculate current balance: %w", err)
+		}
+		if balance+amount < 0 {
+			return ErrInsufficientDiamonds
+		}
+
+		return tx.Create(&models.Diamond{
+			UserID:      uint(userID),
+			Amount:      amount,
+			Balance:     balance + amount,
+			Type:        txType,
+			Description: description,
+			Metadata:    "{}",
+		}).Error
+	})
+}
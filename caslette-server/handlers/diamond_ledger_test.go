@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiamondLedgerRejectsInvalidPlayerID(t *testing.T) {
+	ledger := NewDiamondLedger(nil)
+
+	assert.Error(t, ledger.Debit("not-a-user", 100, "Sit&Go entry"))
+	assert.Error(t, ledger.Credit("", 100, "Sit&Go prize"))
+}
+
+func TestDiamondLedgerSkipsZeroAmounts(t *testing.T) {
+	ledger := NewDiamondLedger(nil)
+
+	assert.NoError(t, ledger.Credit("42", 0, "Sit&Go prize"))
+	assert.Error(t, ledger.Debit("42", 100, "Sit&Go entry"), "no database configured")
+}
@@ -89,46 +89,38 @@ func (h *SecureDiamondHandler) AddDiamonds(c *gin.Context) {
 		return
 	}
 
-	// Start transaction
-	tx := h.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// Read the balance and append the ledger entry atomically
+	var diamond models.Diamond
+	var newBalance int64
+	err := WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		// Get current balance (sum of all diamond transactions for this user)
+		var currentBalance int64
+		if err := tx.Model(&models.Diamond{}).
+			Where("user_id = ?", request.UserID).
+			Select("COALESCE(SUM(amount), 0)").
+			Row().Scan(&currentBalance); err != nil {
+			return txAbort(http.StatusInternalServerError, "failed to calculate current balance")
 		}
-	}()
 
-	// Get current balance (sum of all diamond transactions for this user)
-	var currentBalance int64
-	err := tx.Model(&models.Diamond{}).
-		Where("user_id = ?", request.UserID).
-		Select("COALESCE(SUM(amount), 0)").
-		Row().Scan(&currentBalance)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate current balance"})
-		return
-	}
-
-	// Create new diamond transaction
-	newBalance := currentBalance + int64(request.Amount)
-	diamond := models.Diamond{
-		UserID:      request.UserID,
-		Amount:      int64(request.Amount),
-		Balance:     newBalance,
-		Type:        request.Type,
-		Description: request.Description,
-		Metadata:    "{}",
-	}
-
-	if err := tx.Create(&diamond).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add diamonds"})
-		return
-	}
+		// Create new diamond transaction
+		newBalance = currentBalance + int64(request.Amount)
+		diamond = models.Diamond{
+			UserID:      request.UserID,
+			Amount:      int64(request.Amount),
+			Balance:     newBalance,
+			Type:        request.Type,
+			Description: request.Description,
+			Metadata:    "{}",
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+		if err := tx.Create(&diamond).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "failed to add diamonds")
+		}
+		return nil
+	})
+	if err != nil {
+		failure := txFailure(err, "failed to commit transaction")
+		c.JSON(failure.Status, gin.H{"error": failure.Message})
 		return
 	}
 
@@ -177,57 +169,54 @@ func (h *SecureDiamondHandler) DeductDiamonds(c *gin.Context) {
 		return
 	}
 
-	// Start transaction
-	tx := h.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// Check the balance and append the ledger entry atomically
+	var diamond models.Diamond
+	var newBalance int64
+	err := WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		// Get current balance (sum of all diamond transactions for this user)
+		var currentBalance int64
+		if err := tx.Model(&models.Diamond{}).
+			Where("user_id = ?", request.UserID).
+			Select("COALESCE(SUM(amount), 0)").
+			Row().Scan(&currentBalance); err != nil {
+			return txAbort(http.StatusInternalServerError, "failed to calculate current balance")
 		}
-	}()
 
-	// Get current balance (sum of all diamond transactions for this user)
-	var currentBalance int64
-	err := tx.Model(&models.Diamond{}).
-		Where("user_id = ?", request.UserID).
-		Select("COALESCE(SUM(amount), 0)").
-		Row().Scan(&currentBalance)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to calculate current balance"})
-		return
-	}
-
-	// Check if user has sufficient balance
-	if currentBalance < int64(request.Amount) {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":           "insufficient balance",
-			"current_balance": currentBalance,
-			"required":        request.Amount,
-		})
-		return
-	}
-
-	// Create new diamond transaction (negative amount for deduction)
-	newBalance := currentBalance - int64(request.Amount)
-	diamond := models.Diamond{
-		UserID:      request.UserID,
-		Amount:      -int64(request.Amount), // Negative for deduction
-		Balance:     newBalance,
-		Type:        request.Type,
-		Description: request.Description,
-		Metadata:    "{}",
-	}
+		// Check if user has sufficient balance
+		if currentBalance < int64(request.Amount) {
+			return &TxError{
+				Status:  http.StatusBadRequest,
+				Message: "insufficient balance",
+				Details: gin.H{
+					"current_balance": currentBalance,
+					"required":        request.Amount,
+				},
+			}
+		}
 
-	if err := tx.Create(&diamond).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deduct diamonds"})
-		return
-	}
+		// Create new diamond transaction (negative amount for deduction)
+		newBalance = currentBalance - int64(request.Amount)
+		diamond = models.Diamond{
+			UserID:      request.UserID,
+			Amount:      -int64(request.Amount), // Negative for deduction
+			Balance:     newBalance,
+			Type:        request.Type,
+			Description: request.Description,
+			Metadata:    "{}",
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to commit transaction"})
+		if err := tx.Create(&diamond).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "failed to deduct diamonds")
+		}
+		return nil
+	})
+	if err != nil {
+		failure := txFailure(err, "failed to commit transaction")
+		response := gin.H{"error": failure.Message}
+		for key, value := range failure.Details {
+			response[key] = value
+		}
+		c.JSON(failure.Status, response)
 		return
 	}
 
@@ -0,0 +1,537 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Dispute resolution states
+const (
+	DisputeStatusOpen        = "open"
+	DisputeStatusUnderReview = "under_review"
+	DisputeStatusResolved    = "resolved"
+	DisputeStatusRejected    = "rejected"
+)
+
+// disputeTransitions lists the states each dispute state may move to
+var disputeTransitions = map[string][]string{
+	DisputeStatusOpen:        {DisputeStatusUnderReview, DisputeStatusResolved, DisputeStatusRejected},
+	DisputeStatusUnderReview: {DisputeStatusResolved, DisputeStatusRejected},
+}
+
+// SecureDisputeHandler handles hand review and dispute requests
+type SecureDisputeHandler struct {
+	db          *gorm.DB
+	handHistory *HandHistoryStore
+	validator   *SecurityValidator
+}
+
+// FileDisputeRequest is the payload for flagging a hand
+type FileDisputeRequest struct {
+	TableID string `json:"table_id" binding:"required,max=64"`
+	HandID  string `json:"hand_id" binding:"required,max=64"`
+	Reason  string `json:"reason" binding:"required,max=500"`
+}
+
+// ReviewDisputeRequest is the payload for an admin moving a dispute forward
+type ReviewDisputeRequest struct {
+	Status           string `json:"status" binding:"required"`
+	Resolution       string `json:"resolution" binding:"max=500"`
+	AdjustmentAmount int64  `json:"adjustment_amount"` // Optional diamond credit (positive) or debit (negative)
+}
+
+// NewSecureDisputeHandler creates a new dispute handler over the persisted
+// hand history
+func NewSecureDisputeHandler(db *gorm.DB, handHistory *HandHistoryStore) *SecureDisputeHandler {
+	return &SecureDisputeHandler{
+		db:          db,
+		handHistory: handHistory,
+		validator:   NewSecurityValidator(),
+	}
+}
+
+// Backward compatibility alias
+func NewDisputeHandler(db *gorm.DB, handHistory *HandHistoryStore) *SecureDisputeHandler {
+	return NewSecureDisputeHandler(db, handHistory)
+}
+
+// FileDispute handles POST /api/v1/disputes
+func (h *SecureDisputeHandler) FileDispute(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req FileDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	tableID, err := h.validator.ValidateAndSanitizeString(req.TableID, "table_id", 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid table ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	handID, err := h.validator.ValidateAndSanitizeString(req.HandID, "hand_id", 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid hand ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	reason, err := h.validator.SanitizeFreeText(req.Reason, "reason", 500)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid reason: " + err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if h.handHistory == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success":    false,
+			"error":      "Hand history unavailable",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// The hand must have been completed and persisted at the named table
+	hand, err := h.handHistory.GetHand(handID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && hand.TableID != tableID) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Hand not found",
+			"request_id": requestID,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// Only players dealt into the hand may dispute it
+	playerID := strconv.FormatUint(uint64(userID.(uint)), 10)
+	if !hand.Dealt(playerID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Access denied",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// Reject duplicate disputes for the same hand while one is pending
+	var existing int64
+	if err := h.db.Model(&models.HandDispute{}).
+		Where("user_id = ? AND table_id = ? AND hand_id = ? AND status IN ?", userID, tableID, handID,
+			[]string{DisputeStatusOpen, DisputeStatusUnderReview}).
+		Count(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to check pending disputes",
+			"request_id": requestID,
+		})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error":      "A dispute for this hand is already pending",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	dispute := models.HandDispute{
+		UserID:   userID.(uint),
+		TableID:  tableID,
+		HandID:   handID,
+		Reason:   reason,
+		Status:   DisputeStatusOpen,
+		Evidence: captureHandEvidence(hand),
+	}
+
+	if err := h.db.Create(&dispute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to file dispute",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":    true,
+		"dispute":    dispute,
+		"request_id": requestID,
+	})
+}
+
+// GetMyDisputes handles GET /api/v1/disputes/mine
+func (h *SecureDisputeHandler) GetMyDisputes(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var disputes []models.HandDispute
+	if err := h.db.Omit("Evidence").Where("user_id = ?", userID).
+		Order("created_at desc").Limit(100).Find(&disputes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch disputes",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"disputes":   disputes,
+		"request_id": requestID,
+	})
+}
+
+// GetDisputes handles GET /api/v1/disputes with admin authorization
+func (h *SecureDisputeHandler) GetDisputes(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	query := h.db.Model(&models.HandDispute{}).Omit("Evidence").Preload("User")
+	if status := c.Query("status"); status != "" {
+		if !isDisputeStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid status filter",
+				"request_id": requestID,
+			})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var disputes []models.HandDispute
+	if err := query.Order("created_at asc").Limit(100).Find(&disputes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch disputes",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"disputes":   disputes,
+		"request_id": requestID,
+	})
+}
+
+// GetDispute handles GET /api/v1/disputes/:id, returning the captured hand
+// record as its replay
+func (h *SecureDisputeHandler) GetDispute(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	disputeID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid dispute ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var dispute models.HandDispute
+	if err := h.db.First(&dispute, disputeID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Dispute not found",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// Filers can view their own dispute, admins can view any
+	isAdmin := h.hasAdminPermission(userID.(uint))
+	if dispute.UserID != userID.(uint) && !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Access denied",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// Filers see the hand as they could at the table; admins see every card
+	var replay *game.HandRecord
+	if dispute.Evidence != "" {
+		var hand game.HandRecord
+		if err := json.Unmarshal([]byte(dispute.Evidence), &hand); err == nil {
+			if !isAdmin {
+				hand = hand.VisibleTo(strconv.FormatUint(uint64(dispute.UserID), 10))
+			}
+			replay = &hand
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"dispute":    dispute,
+		"replay":     replay,
+		"request_id": requestID,
+	})
+}
+
+// ReviewDispute handles PUT /api/v1/disputes/:id/review with admin authorization
+func (h *SecureDisputeHandler) ReviewDispute(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	disputeID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid dispute ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req ReviewDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !isDisputeStatus(req.Status) || req.Status == DisputeStatusOpen {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid status",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if req.AdjustmentAmount != 0 && req.Status != DisputeStatusResolved {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Adjustments can only be applied when resolving a dispute",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	resolution := ""
+	if req.Resolution != "" {
+		resolution, err = h.validator.SanitizeFreeText(req.Resolution, "resolution", 500)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid resolution: " + err.Error(),
+				"request_id": requestID,
+			})
+			return
+		}
+	}
+
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var dispute models.HandDispute
+	err = WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		if err := tx.First(&dispute, disputeID).Error; err != nil {
+			return txAbort(http.StatusNotFound, "Dispute not found")
+		}
+
+		if !canTransitionDispute(dispute.Status, req.Status) {
+			return txAbort(http.StatusConflict, "Cannot move dispute from "+dispute.Status+" to "+req.Status)
+		}
+
+		reviewerID := userID.(uint)
+		dispute.Status = req.Status
+		dispute.Resolution = resolution
+		dispute.ReviewedBy = &reviewerID
+		if req.Status == DisputeStatusResolved || req.Status == DisputeStatusRejected {
+			now := time.Now()
+			dispute.ResolvedAt = &now
+		}
+
+		// Apply the optional ledger adjustment in the same transaction
+		if req.AdjustmentAmount != 0 {
+			var currentBalance int64
+			if err := tx.Model(&models.Diamond{}).
+				Where("user_id = ?", dispute.UserID).
+				Select("COALESCE(SUM(amount), 0)").
+				Row().Scan(&currentBalance); err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to calculate current balance")
+			}
+
+			newBalance := currentBalance + req.AdjustmentAmount
+			if newBalance < 0 {
+				return txAbort(http.StatusBadRequest, "Adjustment would result in a negative balance")
+			}
+
+			metadata, _ := json.Marshal(map[string]interface{}{
+				"dispute_id": dispute.ID,
+				"table_id":   dispute.TableID,
+				"hand_id":    dispute.HandID,
+			})
+			adjustment := models.Diamond{
+				UserID:      dispute.UserID,
+				Amount:      req.AdjustmentAmount,
+				Balance:     newBalance,
+				Type:        "dispute_adjustment",
+				Description: "Dispute #" + strconv.FormatUint(uint64(dispute.ID), 10) + " adjustment",
+				Metadata:    string(metadata),
+			}
+			if err := tx.Create(&adjustment).Error; err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to apply adjustment")
+			}
+
+			dispute.AdjustmentAmount = req.AdjustmentAmount
+			dispute.AdjustmentTransactionID = adjustment.TransactionID
+		}
+
+		if err := tx.Save(&dispute).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to update dispute")
+		}
+		return nil
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to commit transaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"dispute":    dispute,
+		"request_id": requestID,
+	})
+}
+
+// hasAdminPermission checks if user has admin role
+func (h *SecureDisputeHandler) hasAdminPermission(userID uint) bool {
+	isAdmin, err := middleware.HasRole(h.db, userID, "admin")
+	return err == nil && isAdmin
+}
+
+// captureHandEvidence snapshots the disputed hand's persisted record as JSON,
+// so the evidence outlives the hand history's retention period
+func captureHandEvidence(hand *game.HandRecord) string {
+	evidence, err := json.Marshal(hand)
+	if err != nil {
+		return "{}"
+	}
+	return string(evidence)
+}
+
+// isDisputeStatus reports whether status is a known dispute state
+func isDisputeStatus(status string) bool {
+	switch status {
+	case DisputeStatusOpen, DisputeStatusUnderReview, DisputeStatusResolved, DisputeStatusRejected:
+		return true
+	}
+	return false
+}
+
+// canTransitionDispute reports whether a dispute may move from one state to another
+func canTransitionDispute(from, to string) bool {
+	for _, allowed := range disputeTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createMockDisputeHandler() *SecureDisputeHandler {
+	return &SecureDisputeHandler{
+		db:          nil, // No actual DB for unit tests
+		handHistory: nil,
+		validator:   NewSecurityValidator(),
+	}
+}
+
+func newDisputeContext(method, path string, body interface{}, userID interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+	c.Set("request_id", "test-request")
+	if userID != nil {
+		c.Set("user_id", userID)
+	}
+	return c, w
+}
+
+func TestSecureDisputeHandler_FileDispute_RequiresAuth(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("POST", "/disputes", map[string]interface{}{
+		"table_id": "abc123",
+		"hand_id":  "h1",
+		"reason":   "Wrong winner",
+	}, nil)
+
+	handler.FileDispute(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureDisputeHandler_FileDispute_MissingFields(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("POST", "/disputes", map[string]interface{}{
+		"table_id": "abc123",
+	}, uint(1))
+
+	handler.FileDispute(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureDisputeHandler_FileDispute_XSSInReason(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("POST", "/disputes", map[string]interface{}{
+		"table_id": "abc123",
+		"hand_id":  "h1",
+		"reason":   "<script>alert('xss')</script>",
+	}, uint(1))
+
+	handler.FileDispute(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureDisputeHandler_FileDispute_SQLInjectionInTableID(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("POST", "/disputes", map[string]interface{}{
+		"table_id": "'; DROP TABLE hand_disputes; --",
+		"hand_id":  "h1",
+		"reason":   "Wrong winner",
+	}, uint(1))
+
+	handler.FileDispute(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureDisputeHandler_FileDispute_NoHandHistory(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("POST", "/disputes", map[string]interface{}{
+		"table_id": "abc123",
+		"hand_id":  "h1",
+		"reason":   "Pot was split incorrectly",
+	}, uint(1))
+
+	handler.FileDispute(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestSecureDisputeHandler_FileDispute_ChecksPersistedHand(t *testing.T) {
+	db := newHandHistoryDB(t, 2320)
+	require.NoError(t, db.AutoMigrate(&models.HandDispute{}))
+	store := NewHandHistoryStore(db)
+	hand := testHand("t1", 1, time.Now().UTC())
+	require.NoError(t, store.SaveHand(hand))
+	handler := NewSecureDisputeHandler(db, store)
+
+	file := func(tableID, handID string, userID uint) *httptest.ResponseRecorder {
+		c, w := newDisputeContext("POST", "/disputes", map[string]interface{}{
+			"table_id": tableID,
+			"hand_id":  handID,
+			"reason":   "Pot was split incorrectly",
+		}, userID)
+		handler.FileDispute(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, file("t1", "t1-2", 2642).Code, "the hand must exist")
+	assert.Equal(t, http.StatusNotFound, file("t2", hand.HandID, 2642).Code, "the hand must be at the table")
+	assert.Equal(t, http.StatusForbidden, file("t1", hand.HandID, 2643).Code, "only players dealt in may dispute")
+	require.Equal(t, http.StatusCreated, file("t1", hand.HandID, 2642).Code)
+	assert.Equal(t, http.StatusConflict, file("t1", hand.HandID, 2642).Code)
+
+	var dispute models.HandDispute
+	require.NoError(t, db.First(&dispute).Error)
+	var evidence game.HandRecord
+	require.NoError(t, json.Unmarshal([]byte(dispute.Evidence), &evidence))
+	assert.Equal(t, hand.HandID, evidence.HandID)
+
+	// The filer sees the replay without the opponent's unshown cards
+	c, w := newDisputeContext("GET", "/disputes/1", nil, uint(2642))
+	c.Params = []gin.Param{{Key: "id", Value: strconv.FormatUint(uint64(dispute.ID), 10)}}
+	handler.GetDispute(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Replay game.HandRecord `json:"replay"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Replay.Players[0].HoleCards)
+	assert.Len(t, response.Replay.Players[1].HoleCards, 2)
+}
+
+func TestSecureDisputeHandler_ReviewDispute_InvalidStatus(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("PUT", "/disputes/1/review", map[string]interface{}{
+		"status": "open",
+	}, uint(1))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	handler.ReviewDispute(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureDisputeHandler_ReviewDispute_AdjustmentRequiresResolution(t *testing.T) {
+	handler := createMockDisputeHandler()
+	c, w := newDisputeContext("PUT", "/disputes/1/review", map[string]interface{}{
+		"status":            DisputeStatusRejected,
+		"adjustment_amount": 100,
+	}, uint(1))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	handler.ReviewDispute(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDisputeTransitions(t *testing.T) {
+	assert.True(t, canTransitionDispute(DisputeStatusOpen, DisputeStatusUnderReview))
+	assert.True(t, canTransitionDispute(DisputeStatusOpen, DisputeStatusResolved))
+	assert.True(t, canTransitionDispute(DisputeStatusUnderReview, DisputeStatusRejected))
+	assert.False(t, canTransitionDispute(DisputeStatusUnderReview, DisputeStatusOpen))
+	assert.False(t, canTransitionDispute(DisputeStatusResolved, DisputeStatusUnderReview))
+	assert.False(t, canTransitionDispute(DisputeStatusRejected, DisputeStatusResolved))
+}
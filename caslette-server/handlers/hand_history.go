@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetentionHandHistories is the retention category of persisted hands
+const RetentionHandHistories = "hand_histories"
+
+// Hand history page sizes
+const (
+	DefaultHandHistoryLimit = 10
+	MaxHandHistoryLimit     = 100
+)
+
+// HandHistoryQuery selects a page of persisted hands, most recent first.
+// Empty filters match every hand.
+type HandHistoryQuery struct {
+	TableID  string
+	PlayerID string // Only hands the player was dealt into
+	Page     int
+	Limit    int
+}
+
+// HandHistoryStore persists completed hands in the hand_histories table,
+// with the players dealt into each in hand_history_players
+type HandHistoryStore struct {
+	db *gorm.DB
+}
+
+// NewHandHistoryStore creates a store over the hand history tables
+func NewHandHistoryStore(db *gorm.DB) *HandHistoryStore {
+	return &HandHistoryStore{db: db}
+}
+
+// SaveHand writes a completed hand; a hand already saved is left as it is
+func (s *HandHistoryStore) SaveHand(record game.HandRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	net := make(map[string]int, len(record.Results))
+	for _, result := range record.Results {
+		net[result.PlayerID] = result.Net
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.HandHistory{
+			HandID:     record.HandID,
+			TableID:    record.TableID,
+			GameType:   string(record.GameType),
+			HandNumber: record.HandNumber,
+			TotalPot:   int64(record.TotalPot),
+			Rake:       int64(record.Rake),
+			Record:     string(encoded),
+			StartedAt:  record.StartedAt,
+			EndedAt:    record.EndedAt,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		for _, player := range record.Players {
+			if err := tx.Create(&models.HandHistoryPlayer{
+				HandID:   record.HandID,
+				PlayerID: player.PlayerID,
+				Seat:     player.Seat,
+				Net:      int64(net[player.PlayerID]),
+				EndedAt:  record.EndedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetHand returns a persisted hand, or gorm.ErrRecordNotFound
+func (s *HandHistoryStore) GetHand(handID string) (*game.HandRecord, error) {
+	var row models.HandHistory
+	if err := s.db.Where("hand_id = ?", handID).First(&row).Error; err != nil {
+		return nil, err
+	}
+	return decodeHandRecord(row)
+}
+
+// ListHands returns a page of hands and how many match in total
+func (s *HandHistoryStore) ListHands(query HandHistoryQuery) ([]game.HandRecord, int64, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = DefaultHandHistoryLimit
+	}
+
+	rows := s.db.Model(&models.HandHistory{})
+	if query.TableID != "" {
+		rows = rows.Where("table_id = ?", query.TableID)
+	}
+	if query.PlayerID != "" {
+		rows = rows.Where("hand_id IN (?)", s.db.Model(&models.HandHistoryPlayer{}).
+			Select("hand_id").Where("player_id = ?", query.PlayerID))
+	}
+
+	var total int64
+	if err := rows.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var page []models.HandHistory
+	if err := rows.Order("ended_at desc").Order("id desc").
+		Limit(query.Limit).Offset((query.Page - 1) * query.Limit).
+		Find(&page).Error; err != nil {
+		return nil, 0, err
+	}
+
+	records := make([]game.HandRecord, 0, len(page))
+	for _, row := range page {
+		record, err := decodeHandRecord(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, *record)
+	}
+	return records, total, nil
+}
+
+// AppendHandEvents writes a completed hand's event log; entries already
+// logged for the hand are left as they are
+func (s *HandHistoryStore) AppendHandEvents(tableID, handID string, events []game.GameEvent) error {
+	rows := make([]models.HandEvent, 0, len(events))
+	for i, event := range events {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, models.HandEvent{
+			HandID:    handID,
+			Position:  i,
+			TableID:   tableID,
+			Type:      event.Type,
+			Event:     string(encoded),
+			Timestamp: event.Timestamp,
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// HandEvents returns a hand's event log in the order it was played; a hand
+// without one has no events
+func (s *HandHistoryStore) HandEvents(handID string) ([]game.GameEvent, error) {
+	var rows []models.HandEvent
+	if err := s.db.Where("hand_id = ?", handID).Order("position").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	events := make([]game.GameEvent, 0, len(rows))
+	for _, row := range rows {
+		var event game.GameEvent
+		if err := json.Unmarshal([]byte(row.Event), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// PruneBefore deletes hands completed before cutoff, with their event logs;
+// it is the store's retention purger
+func (s *HandHistoryStore) PruneBefore(cutoff time.Time) (int64, error) {
+	var purged int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ended_at < ?", cutoff).Delete(&models.HandHistoryPlayer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("timestamp < ?", cutoff).Delete(&models.HandEvent{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("ended_at < ?", cutoff).Delete(&models.HandHistory{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+// decodeHandRecord reads the record stored with a hand
+func decodeHandRecord(row models.HandHistory) (*game.HandRecord, error) {
+	var record game.HandRecord
+	if err := json.Unmarshal([]byte(row.Record), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// HandHistoryHandler serves persisted hands over REST
+type HandHistoryHandler struct {
+	db        *gorm.DB
+	store     *HandHistoryStore
+	validator *SecurityValidator
+}
+
+// NewHandHistoryHandler creates a handler over the store's hands
+func NewHandHistoryHandler(store *HandHistoryStore) *HandHistoryHandler {
+	return &HandHistoryHandler{db: store.db, store: store, validator: NewSecurityValidator()}
+}
+
+// ListHands handles GET /api/v1/hands: the caller's hands, most recent
+// first, optionally at one table. Admins may ask for any player's hands
+// with ?player_id, or every hand at a table.
+func (h *HandHistoryHandler) ListHands(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	userID := c.GetUint("user_id")
+	isAdmin := h.hasAdminPermission(userID)
+
+	page, limit, ok := parseHandHistoryPage(c, h.validator)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid pagination: page must be positive and limit between 1 and " + strconv.Itoa(MaxHandHistoryLimit),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	query := HandHistoryQuery{
+		TableID:  c.Query("table_id"),
+		PlayerID: strconv.FormatUint(uint64(userID), 10),
+		Page:     page,
+		Limit:    limit,
+	}
+	if isAdmin {
+		query.PlayerID = c.Query("player_id")
+	}
+
+	records, total, err := h.store.ListHands(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand history",
+			"request_id": requestID,
+		})
+		return
+	}
+	viewer := strconv.FormatUint(uint64(userID), 10)
+	hands := make([]game.HandRecord, 0, len(records))
+	for i := range records {
+		if isAdmin {
+			hands = append(hands, records[i])
+		} else {
+			hands = append(hands, records[i].VisibleTo(viewer))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"hands": hands,
+			"pagination": PaginationInfo{
+				Page:       page,
+				Limit:      limit,
+				Total:      total,
+				TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+			},
+		},
+		"request_id": requestID,
+	})
+}
+
+// GetHand handles GET /api/v1/hands/:hand_id for players dealt into the
+// hand and admins. Players see only their own and shown hole cards.
+func (h *HandHistoryHandler) GetHand(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	userID := c.GetUint("user_id")
+
+	record, err := h.store.GetHand(c.Param("hand_id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Hand not found",
+			"request_id": requestID,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	hand := *record
+	if !h.hasAdminPermission(userID) {
+		viewer := strconv.FormatUint(uint64(userID), 10)
+		if !record.Dealt(viewer) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success":    false,
+				"error":      "Access denied",
+				"request_id": requestID,
+			})
+			return
+		}
+		hand = record.VisibleTo(viewer)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       hand,
+		"request_id": requestID,
+	})
+}
+
+// ReplayHand handles GET /api/v1/hands/:hand_id/replay: the hand's events
+// with their offsets from the deal, so clients can animate it. The events
+// are those broadcast to the table, so no one sees hole cards that were not
+// shown. Like the hand itself, replays are for players dealt in and admins.
+func (h *HandHistoryHandler) ReplayHand(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	userID := c.GetUint("user_id")
+
+	record, err := h.store.GetHand(c.Param("hand_id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Hand not found",
+			"request_id": requestID,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand",
+			"request_id": requestID,
+		})
+		return
+	}
+	if !record.Dealt(strconv.FormatUint(uint64(userID), 10)) && !h.hasAdminPermission(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Access denied",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	events, err := h.store.HandEvents(record.HandID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand events",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       game.NewHandReplay(record.TableID, record.HandID, events),
+		"request_id": requestID,
+	})
+}
+
+// hasAdminPermission checks if user has admin role
+func (h *HandHistoryHandler) hasAdminPermission(userID uint) bool {
+	isAdmin, err := middleware.HasRole(h.db, userID, "admin")
+	return err == nil && isAdmin
+}
+
+// parseHandHistoryPage reads ?page and ?limit, defaulting to the first page
+// of DefaultHandHistoryLimit hands
+func parseHandHistoryPage(c *gin.Context, validator *SecurityValidator) (int, int, bool) {
+	page, err := validator.ValidatePositiveInt(c.DefaultQuery("page", "1"), "page")
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, err := validator.ValidatePositiveInt(c.DefaultQuery("limit", strconv.Itoa(DefaultHandHistoryLimit)), "limit")
+	if err != nil || limit > MaxHandHistoryLimit {
+		return 0, 0, false
+	}
+	return page, limit, true
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newHandHistoryDB opens a database with hand history and role tables;
+// adminID holds the admin role
+func newHandHistoryDB(t *testing.T, adminID uint) *gorm.DB {
+	db := newSQLiteDB(t, &models.HandHistory{}, &models.HandHistoryPlayer{}, &models.HandEvent{}, &models.User{}, &models.Role{},
+		&models.UserRole{}, &models.Permission{}, &models.RolePermission{}, &models.UserPermission{})
+	role := models.Role{Name: "admin"}
+	require.NoError(t, db.Create(&role).Error)
+	require.NoError(t, db.Create(&models.UserRole{UserID: adminID, RoleID: role.ID}).Error)
+	return db
+}
+
+// testHand is a hand at tableID between players 2641 and 2642, which 2641
+// won without showing
+func testHand(tableID string, number int, endedAt time.Time) game.HandRecord {
+	return game.HandRecord{
+		HandID:     fmt.Sprintf("%s-%d", tableID, number),
+		TableID:    tableID,
+		GameType:   game.GameTypeTexasHoldem,
+		HandNumber: number,
+		Players: []game.HandPlayer{
+			{PlayerID: "2641", Seat: 1, StartingStack: 1000, HoleCards: []game.Card{game.NewCard(game.Hearts, game.Ace), game.NewCard(game.Spades, game.Ace)}},
+			{PlayerID: "2642", Seat: 2, StartingStack: 1000, HoleCards: []game.Card{game.NewCard(game.Hearts, game.King), game.NewCard(game.Spades, game.King)}},
+		},
+		Winners:   []string{"2641"},
+		Results:   []game.HandResult{{PlayerID: "2641", Net: 15}, {PlayerID: "2642", Net: -15}},
+		TotalPot:  30,
+		StartedAt: endedAt.Add(-time.Minute),
+		EndedAt:   endedAt,
+	}
+}
+
+func performHandHistoryRequest(handle gin.HandlerFunc, userID uint, target, handID string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", target, nil)
+	c.Set("user_id", userID)
+	if handID != "" {
+		c.Params = gin.Params{{Key: "hand_id", Value: handID}}
+	}
+	handle(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestHandHistoryStore_SavesListsAndPrunes(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	store := NewHandHistoryStore(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, store.SaveHand(testHand("t1", 1, now.Add(-3*time.Hour))))
+	require.NoError(t, store.SaveHand(testHand("t1", 2, now.Add(-2*time.Hour))))
+	require.NoError(t, store.SaveHand(testHand("t2", 1, now.Add(-time.Hour))))
+	require.NoError(t, store.SaveHand(testHand("t2", 1, now)), "saving a hand twice is harmless")
+
+	var players []models.HandHistoryPlayer
+	require.NoError(t, db.Where("player_id = ?", "2642").Find(&players).Error)
+	require.Len(t, players, 3)
+	assert.Equal(t, int64(-15), players[0].Net)
+
+	hands, total, err := store.ListHands(HandHistoryQuery{TableID: "t1", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, hands, 1)
+	assert.Equal(t, 2, hands[0].HandNumber, "the most recent hand comes first")
+
+	hands, total, err = store.ListHands(HandHistoryQuery{PlayerID: "9999"})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, hands)
+
+	hand, err := store.GetHand(testHand("t2", 1, now).HandID)
+	require.NoError(t, err)
+	assert.True(t, now.Add(-time.Hour).Equal(hand.EndedAt), "the first save is kept")
+	assert.Len(t, hand.Players[1].HoleCards, 2)
+
+	purged, err := store.PruneBefore(now.Add(-90 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	var remaining int64
+	db.Model(&models.HandHistoryPlayer{}).Count(&remaining)
+	assert.Equal(t, int64(2), remaining, "players of purged hands go with them")
+}
+
+func TestHandHistoryHandler_ListHands(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	store := NewHandHistoryStore(db)
+	handler := NewHandHistoryHandler(store)
+	now := time.Now().UTC()
+	for number := 1; number <= 3; number++ {
+		require.NoError(t, store.SaveHand(testHand("t1", number, now.Add(time.Duration(number)*time.Minute))))
+	}
+
+	w, response := performHandHistoryRequest(handler.ListHands, 2642, "/hands?limit=2&page=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	data := response["data"].(map[string]interface{})
+	hands := data["hands"].([]interface{})
+	require.Len(t, hands, 1)
+	pagination := data["pagination"].(map[string]interface{})
+	assert.Equal(t, float64(3), pagination["total"])
+	assert.Equal(t, float64(2), pagination["total_pages"])
+	players := hands[0].(map[string]interface{})["players"].([]interface{})
+	assert.Nil(t, players[0].(map[string]interface{})["hole_cards"], "the winner did not show")
+	assert.NotNil(t, players[1].(map[string]interface{})["hole_cards"], "players see their own cards")
+
+	// Other players' hands are only for admins
+	_, response = performHandHistoryRequest(handler.ListHands, 2643, "/hands?player_id=2642", "")
+	assert.Empty(t, response["data"].(map[string]interface{})["hands"])
+	_, response = performHandHistoryRequest(handler.ListHands, 2640, "/hands?player_id=2642", "")
+	assert.Len(t, response["data"].(map[string]interface{})["hands"], 3)
+
+	w, _ = performHandHistoryRequest(handler.ListHands, 2642, "/hands?limit=101", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandHistoryHandler_GetHand(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	store := NewHandHistoryStore(db)
+	handler := NewHandHistoryHandler(store)
+	hand := testHand("t1", 1, time.Now().UTC())
+	require.NoError(t, store.SaveHand(hand))
+
+	w, _ := performHandHistoryRequest(handler.GetHand, 2642, "/hands/"+hand.HandID, hand.HandID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, _ = performHandHistoryRequest(handler.GetHand, 2643, "/hands/"+hand.HandID, hand.HandID)
+	assert.Equal(t, http.StatusForbidden, w.Code, "players not dealt in are refused")
+
+	w, response := performHandHistoryRequest(handler.GetHand, 2640, "/hands/"+hand.HandID, hand.HandID)
+	require.Equal(t, http.StatusOK, w.Code)
+	players := response["data"].(map[string]interface{})["players"].([]interface{})
+	assert.NotNil(t, players[0].(map[string]interface{})["hole_cards"], "admins see every hand")
+
+	w, _ = performHandHistoryRequest(handler.GetHand, 2640, "/hands/missing", "missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// testHandEvents is the event log of a hand dealt at dealt
+func testHandEvents(dealt time.Time) []game.GameEvent {
+	return []game.GameEvent{
+		{Type: "hand_started", Sequence: 4, Timestamp: dealt},
+		{Type: "player_called", PlayerID: "2642", Sequence: 5, Data: map[string]interface{}{"amount": float64(10)}, Timestamp: dealt.Add(2 * time.Second)},
+		{Type: game.HandSummaryEvent, Sequence: 6, Timestamp: dealt.Add(3 * time.Second)},
+	}
+}
+
+func TestHandHistoryStore_AppendsAndPrunesHandEvents(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	store := NewHandHistoryStore(db)
+	dealt := time.Now().UTC().Truncate(time.Second)
+	hand := testHand("t1", 1, dealt.Add(3*time.Second))
+	require.NoError(t, store.SaveHand(hand))
+
+	require.NoError(t, store.AppendHandEvents("t1", hand.HandID, testHandEvents(dealt)))
+	require.NoError(t, store.AppendHandEvents("t1", hand.HandID, testHandEvents(dealt)), "logging a hand twice is harmless")
+
+	events, err := store.HandEvents(hand.HandID)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "player_called", events[1].Type)
+	assert.Equal(t, "2642", events[1].PlayerID)
+	assert.Equal(t, float64(10), events[1].Data["amount"])
+	assert.True(t, dealt.Add(2*time.Second).Equal(events[1].Timestamp))
+
+	events, err = store.HandEvents("missing")
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	_, err = store.PruneBefore(dealt.Add(time.Hour))
+	require.NoError(t, err)
+	var remaining int64
+	db.Model(&models.HandEvent{}).Count(&remaining)
+	assert.Zero(t, remaining, "event logs go with their hands")
+}
+
+func TestHandHistoryHandler_ReplayHand(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	store := NewHandHistoryStore(db)
+	handler := NewHandHistoryHandler(store)
+	dealt := time.Now().UTC().Truncate(time.Second)
+	hand := testHand("t1", 1, dealt.Add(3*time.Second))
+	require.NoError(t, store.SaveHand(hand))
+	require.NoError(t, store.AppendHandEvents("t1", hand.HandID, testHandEvents(dealt)))
+
+	w, response := performHandHistoryRequest(handler.ReplayHand, 2642, "/hands/"+hand.HandID+"/replay", hand.HandID)
+	require.Equal(t, http.StatusOK, w.Code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, hand.HandID, data["hand_id"])
+	assert.Equal(t, float64(3000), data["duration_ms"])
+	events := data["events"].([]interface{})
+	require.Len(t, events, 3)
+	assert.Equal(t, float64(2000), events[1].(map[string]interface{})["offset_ms"])
+	assert.Equal(t, "player_called", events[1].(map[string]interface{})["type"])
+
+	w, _ = performHandHistoryRequest(handler.ReplayHand, 2643, "/hands/"+hand.HandID+"/replay", hand.HandID)
+	assert.Equal(t, http.StatusForbidden, w.Code, "players not dealt in are refused")
+	w, _ = performHandHistoryRequest(handler.ReplayHand, 2640, "/hands/"+hand.HandID+"/replay", hand.HandID)
+	assert.Equal(t, http.StatusOK, w.Code, "admins may replay any hand")
+	w, _ = performHandHistoryRequest(handler.ReplayHand, 2640, "/hands/missing/replay", "missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// handIDPattern restricts looked up hand IDs to those the server assigns
+var handIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// HandCertification names the certification log entry of a hand
+type HandCertification struct {
+	Sequence int64  `json:"sequence"`
+	Hash     string `json:"hash"`
+}
+
+// HandDisputeRef is a dispute filed about a hand, without its evidence
+type HandDisputeRef struct {
+	ID     uint   `json:"id"`
+	UserID uint   `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// HandCollusionRef is a collusion flag citing a hand
+type HandCollusionRef struct {
+	ID     uint   `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+}
+
+// HandReferences is everything else the server holds about a hand ID
+type HandReferences struct {
+	Events        int64              `json:"events"` // Events in the hand's replay log
+	Certification *HandCertification `json:"certification,omitempty"`
+	Rake          int64              `json:"rake"`
+	Disputes      []HandDisputeRef   `json:"disputes"`
+	Collusion     []HandCollusionRef `json:"collusion_flags"`
+}
+
+// empty reports whether nothing refers to the hand
+func (r HandReferences) empty() bool {
+	return r.Events == 0 && r.Certification == nil && r.Rake == 0 && len(r.Disputes) == 0 && len(r.Collusion) == 0
+}
+
+// References gathers the records kept under a hand ID across the event log,
+// certification log, rake ledger, disputes and collusion flags
+func (s *HandHistoryStore) References(handID string) (HandReferences, error) {
+	refs := HandReferences{Disputes: make([]HandDisputeRef, 0), Collusion: make([]HandCollusionRef, 0)}
+	if err := s.db.Model(&models.HandEvent{}).Where("hand_id = ?", handID).Count(&refs.Events).Error; err != nil {
+		return refs, err
+	}
+
+	var entry models.CertificationEntry
+	err := s.db.Where("kind = ? AND subject = ?", game.CertifiedHandResult, handID).Order("sequence").First(&entry).Error
+	switch {
+	case err == nil:
+		refs.Certification = &HandCertification{Sequence: entry.Sequence, Hash: entry.Hash}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return refs, err
+	}
+
+	if err := s.db.Model(&models.RakeRecord{}).Where("hand_id = ?", handID).
+		Select("COALESCE(SUM(amount), 0)").Row().Scan(&refs.Rake); err != nil {
+		return refs, err
+	}
+	if err := s.db.Model(&models.HandDispute{}).Where("hand_id = ?", handID).
+		Order("created_at").Find(&refs.Disputes).Error; err != nil {
+		return refs, err
+	}
+	// Flags keep their evidence as a JSON array of hand IDs
+	if err := s.db.Model(&models.CollusionFlag{}).Where("hand_ids LIKE ?", `%"`+handID+`"%`).
+		Order("created_at").Find(&refs.Collusion).Error; err != nil {
+		return refs, err
+	}
+	return refs, nil
+}
+
+// LookupHand handles GET /api/v1/admin/hands/:hand_id for support staff:
+// the full record of any hand, every hole card included, with what else
+// refers to it. A hand purged from the history is still found through its
+// references.
+func (h *HandHistoryHandler) LookupHand(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	handID := c.Param("hand_id")
+	if !handIDPattern.MatchString(handID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid hand ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	record, err := h.store.GetHand(handID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand",
+			"request_id": requestID,
+		})
+		return
+	}
+	refs, err := h.store.References(handID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch hand references",
+			"request_id": requestID,
+		})
+		return
+	}
+	if record == nil && refs.empty() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Hand not found",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"hand_id":    handID,
+			"hand":       record,
+			"references": refs,
+		},
+		"request_id": requestID,
+	})
+}
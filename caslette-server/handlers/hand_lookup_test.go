@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupHand(handler *HandHistoryHandler, handID string) (int, map[string]interface{}) {
+	w, response := performHandHistoryRequest(handler.LookupHand, 2640, "/admin/hands/"+handID, handID)
+	data, _ := response["data"].(map[string]interface{})
+	return w.Code, data
+}
+
+func TestHandHistoryHandler_LookupHandGathersReferences(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	require.NoError(t, db.AutoMigrate(&models.CertificationEntry{}, &models.RakeRecord{}, &models.HandDispute{}, &models.CollusionFlag{}))
+	store := NewHandHistoryStore(db)
+	handler := NewHandHistoryHandler(store)
+
+	now := time.Now()
+	hand := testHand("t1", 1, now)
+	require.NoError(t, store.SaveHand(hand))
+	require.NoError(t, store.AppendHandEvents("t1", hand.HandID, []game.GameEvent{{Type: "hand_started"}, {Type: "hand_summary"}}))
+	require.NoError(t, NewCertificationLogStore(db).Certify(game.CertifiedOutcome{Kind: game.CertifiedHandResult, Subject: hand.HandID, OccurredAt: now}))
+	require.NoError(t, db.Create(&models.RakeRecord{TableID: "t1", HandID: hand.HandID, StakeLevel: "5/10", Amount: 3}).Error)
+	require.NoError(t, db.Create(&models.HandDispute{UserID: 2642, TableID: "t1", HandID: hand.HandID, Reason: "Wrong winner", Status: DisputeStatusOpen}).Error)
+	require.NoError(t, NewCollusionReviewStore(db).QueueCollusionFlag(game.CollusionFlag{
+		Kind: game.CollusionChipDumping, Players: []string{"2642", "2641"}, HandIDs: []string{"t0-9", hand.HandID}, RaisedAt: now,
+	}))
+
+	code, data := lookupHand(handler, hand.HandID)
+	require.Equal(t, http.StatusOK, code)
+	record := data["hand"].(map[string]interface{})
+	players := record["players"].([]interface{})
+	assert.Len(t, players[1].(map[string]interface{})["hole_cards"], 2, "support sees every hole card")
+
+	refs := data["references"].(map[string]interface{})
+	assert.Equal(t, float64(2), refs["events"])
+	assert.Equal(t, float64(1), refs["certification"].(map[string]interface{})["sequence"])
+	assert.Equal(t, float64(3), refs["rake"])
+	require.Len(t, refs["disputes"], 1)
+	assert.Equal(t, DisputeStatusOpen, refs["disputes"].([]interface{})[0].(map[string]interface{})["status"])
+	require.Len(t, refs["collusion_flags"], 1)
+
+	// A purged hand is still found through what refers to it
+	require.NoError(t, db.Where("hand_id = ?", hand.HandID).Delete(&models.HandHistory{}).Error)
+	code, data = lookupHand(handler, hand.HandID)
+	require.Equal(t, http.StatusOK, code)
+	assert.Nil(t, data["hand"])
+
+	code, _ = lookupHand(handler, "t9-1")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = lookupHand(handler, "t1-1' OR 1=1")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestHandHistoryHandler_LookupHandNeedsNoReferences(t *testing.T) {
+	db := newHandHistoryDB(t, 2640)
+	require.NoError(t, db.AutoMigrate(&models.CertificationEntry{}, &models.RakeRecord{}, &models.HandDispute{}, &models.CollusionFlag{}))
+	store := NewHandHistoryStore(db)
+	require.NoError(t, store.SaveHand(testHand("t1", 1, time.Now())))
+
+	gin.SetMode(gin.TestMode)
+	code, data := lookupHand(NewHandHistoryHandler(store), "t1-1")
+	require.Equal(t, http.StatusOK, code)
+	refs := data["references"].(map[string]interface{})
+	assert.Empty(t, refs["disputes"])
+	assert.Nil(t, refs["certification"])
+}
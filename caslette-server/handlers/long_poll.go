@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"caslette-server/websocket_v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultLongPollTimeout is how long a poll waits when no timeout is given
+const DefaultLongPollTimeout = 25 * time.Second
+
+// validPollRoom matches the room names accepted by the websocket hub
+var validPollRoom = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,50}$`)
+
+// SubscribeRoomRequest follows a room from a long-poll client
+type SubscribeRoomRequest struct {
+	Room string `json:"room" binding:"required"`
+}
+
+// SecureLongPollHandler serves the websocket outbox over HTTP long polling
+// for clients on networks that block websockets
+type SecureLongPollHandler struct {
+	outbox *websocket_v2.OutboxStore
+}
+
+// NewSecureLongPollHandler creates a new long-poll handler
+func NewSecureLongPollHandler(outbox *websocket_v2.OutboxStore) *SecureLongPollHandler {
+	return &SecureLongPollHandler{outbox: outbox}
+}
+
+// Backward compatibility alias
+func NewLongPollHandler(outbox *websocket_v2.OutboxStore) *SecureLongPollHandler {
+	return NewSecureLongPollHandler(outbox)
+}
+
+// Poll handles GET /api/v1/poll. Query parameters: cursor (last seen seq) and
+// timeout (seconds, at most 30). Returns as soon as newer messages exist.
+func (h *SecureLongPollHandler) Poll(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userIDStr, ok := h.userID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var cursor uint64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		parsed, err := strconv.ParseUint(cursorStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid cursor",
+				"request_id": requestID,
+			})
+			return
+		}
+		cursor = parsed
+	}
+
+	timeout := DefaultLongPollTimeout
+	if timeoutStr := c.Query("timeout"); timeoutStr != "" {
+		seconds, err := strconv.Atoi(timeoutStr)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > websocket_v2.MaxLongPollTimeout {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid timeout (0-30 seconds)",
+				"request_id": requestID,
+			})
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	result := h.outbox.Poll(c.Request.Context(), userIDStr, cursor, timeout)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       result,
+		"request_id": requestID,
+	})
+}
+
+// GetSubscriptions handles GET /api/v1/poll/subscriptions
+func (h *SecureLongPollHandler) GetSubscriptions(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userIDStr, ok := h.userID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"rooms": h.outbox.Subscriptions(userIDStr)},
+		"request_id": requestID,
+	})
+}
+
+// Subscribe handles POST /api/v1/poll/subscriptions, delivering a room's
+// broadcasts (e.g. a table room or the lobby) to the caller's outbox
+func (h *SecureLongPollHandler) Subscribe(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userIDStr, ok := h.userID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req SubscribeRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+	if !validPollRoom.MatchString(req.Room) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid room name",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if err := h.outbox.Subscribe(userIDStr, req.Room); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Subscribed to room",
+		"data":       gin.H{"room": req.Room},
+		"request_id": requestID,
+	})
+}
+
+// Unsubscribe handles DELETE /api/v1/poll/subscriptions/:room
+func (h *SecureLongPollHandler) Unsubscribe(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userIDStr, ok := h.userID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	room := c.Param("room")
+	if !validPollRoom.MatchString(room) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid room name",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	h.outbox.Unsubscribe(userIDStr, room)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Unsubscribed from room",
+		"request_id": requestID,
+	})
+}
+
+// userID returns the caller's ID in the string form the websocket hub uses
+func (h *SecureLongPollHandler) userID(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return "", false
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatUint(uint64(id), 10), true
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"caslette-server/websocket_v2"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureLongPollHandler_RequiresAuth(t *testing.T) {
+	handler := NewSecureLongPollHandler(websocket_v2.NewOutboxStore(10))
+
+	c, w := newDisputeContext("GET", "/poll", nil, nil)
+	handler.Poll(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newDisputeContext("POST", "/poll/subscriptions", map[string]interface{}{"room": "lobby"}, nil)
+	handler.Subscribe(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureLongPollHandler_Poll_InvalidParams(t *testing.T) {
+	handler := NewSecureLongPollHandler(websocket_v2.NewOutboxStore(10))
+
+	c, w := newDisputeContext("GET", "/poll?cursor=abc", nil, uint(7))
+	handler.Poll(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	c, w = newDisputeContext("GET", "/poll?timeout=120", nil, uint(7))
+	handler.Poll(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureLongPollHandler_Poll_DrainsOutbox(t *testing.T) {
+	outbox := websocket_v2.NewOutboxStore(10)
+	outbox.Append("7", &websocket_v2.Message{Type: "your_turn"})
+	outbox.Append("8", &websocket_v2.Message{Type: "someone_else"})
+	handler := NewSecureLongPollHandler(outbox)
+
+	c, w := newDisputeContext("GET", "/poll?cursor=0&timeout=0", nil, uint(7))
+	handler.Poll(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data websocket_v2.PollResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Messages, 1)
+	assert.Equal(t, "your_turn", response.Data.Messages[0].Message.Type)
+	assert.Equal(t, uint64(1), response.Data.Cursor)
+}
+
+func TestSecureLongPollHandler_Subscribe_ValidatesRoom(t *testing.T) {
+	outbox := websocket_v2.NewOutboxStore(10)
+	handler := NewSecureLongPollHandler(outbox)
+
+	c, w := newDisputeContext("POST", "/poll/subscriptions", map[string]interface{}{"room": "table' OR 1=1"}, uint(7))
+	handler.Subscribe(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	c, w = newDisputeContext("POST", "/poll/subscriptions", map[string]interface{}{"room": "table_abc"}, uint(7))
+	handler.Subscribe(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"7"}, outbox.RoomSubscribers("table_abc"))
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"caslette-server/middleware"
 	"caslette-server/models"
 	"net/http"
 	"strconv"
@@ -138,6 +139,7 @@ func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update permission"})
 		return
 	}
+	middleware.InvalidateAllPermissions()
 
 	c.JSON(http.StatusOK, permission)
 }
@@ -154,6 +156,7 @@ func (h *PermissionHandler) DeletePermission(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete permission"})
 		return
 	}
+	middleware.InvalidateAllPermissions()
 
 	c.JSON(http.StatusOK, gin.H{"message": "Permission deleted successfully"})
 }
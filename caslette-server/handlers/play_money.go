@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Play-money wallet limits
+const (
+	PlayMoneyStartingBalance = 10000
+	PlayMoneyRefillCooldown  = time.Hour
+)
+
+// SecurePlayMoneyHandler manages practice-table balances. It never reads or
+// writes the diamond ledger, so play money cannot be converted into diamonds.
+type SecurePlayMoneyHandler struct {
+	db        *gorm.DB
+	validator *SecurityValidator
+}
+
+// NewSecurePlayMoneyHandler creates a new play-money handler
+func NewSecurePlayMoneyHandler(db *gorm.DB) *SecurePlayMoneyHandler {
+	return &SecurePlayMoneyHandler{
+		db:        db,
+		validator: NewSecurityValidator(),
+	}
+}
+
+// Backward compatibility alias
+func NewPlayMoneyHandler(db *gorm.DB) *SecurePlayMoneyHandler {
+	return NewSecurePlayMoneyHandler(db)
+}
+
+// GetBalance handles GET /api/v1/play-money, opening the wallet on first use
+func (h *SecurePlayMoneyHandler) GetBalance(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	account, err := h.getOrCreateAccount(h.db, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load play-money balance",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       h.accountResponse(account),
+		"request_id": requestID,
+	})
+}
+
+// Refill handles POST /api/v1/play-money/refill, topping the balance back up
+// to the starting amount at most once per cooldown
+func (h *SecurePlayMoneyHandler) Refill(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var account models.PlayMoneyAccount
+	err := WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		if _, err := h.getOrCreateAccount(tx, userID.(uint)); err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to load play-money balance")
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&account).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to load play-money balance")
+		}
+
+		now := time.Now()
+		if account.Balance >= PlayMoneyStartingBalance {
+			return txAbort(http.StatusConflict, "Balance is already at or above the refill amount")
+		}
+		if account.LastRefillAt != nil && now.Sub(*account.LastRefillAt) < PlayMoneyRefillCooldown {
+			return &TxError{
+				Status:  http.StatusTooManyRequests,
+				Message: "Refill is on cooldown",
+				Details: gin.H{"next_refill_at": account.LastRefillAt.Add(PlayMoneyRefillCooldown)},
+			}
+		}
+
+		account.Balance = PlayMoneyStartingBalance
+		account.LastRefillAt = &now
+		return tx.Save(&account).Error
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to refill play-money balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Play-money balance refilled",
+		"data":       h.accountResponse(&account),
+		"request_id": requestID,
+	})
+}
+
+// getOrCreateAccount loads a user's wallet, opening it with the starting balance
+func (h *SecurePlayMoneyHandler) getOrCreateAccount(db *gorm.DB, userID uint) (*models.PlayMoneyAccount, error) {
+	account := models.PlayMoneyAccount{UserID: userID, Balance: PlayMoneyStartingBalance}
+	if err := db.Where(models.PlayMoneyAccount{UserID: userID}).FirstOrCreate(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// accountResponse formats a wallet for API responses
+func (h *SecurePlayMoneyHandler) accountResponse(account *models.PlayMoneyAccount) gin.H {
+	response := gin.H{
+		"balance":  account.Balance,
+		"currency": "play_money",
+	}
+	if account.LastRefillAt != nil {
+		response["next_refill_at"] = account.LastRefillAt.Add(PlayMoneyRefillCooldown)
+	}
+	return response
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurePlayMoneyHandler_RequiresAuth(t *testing.T) {
+	handler := &SecurePlayMoneyHandler{db: nil, validator: NewSecurityValidator()}
+
+	c, w := newDisputeContext("GET", "/play-money", nil, nil)
+	handler.GetBalance(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newDisputeContext("POST", "/play-money/refill", nil, nil)
+	handler.Refill(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlayerStatsStore keeps players' lifetime poker stats in the player_stats
+// table, one row per player and currency
+type PlayerStatsStore struct {
+	db *gorm.DB
+}
+
+// NewPlayerStatsStore creates a store over the player_stats table
+func NewPlayerStatsStore(db *gorm.DB) *PlayerStatsStore {
+	return &PlayerStatsStore{db: db}
+}
+
+// AddPlayerStats adds one hand's stats to each player's totals
+func (s *PlayerStatsStore) AddPlayerStats(stats []game.PlayerStats) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, hand := range stats {
+			row := models.PlayerStats{
+				PlayerID:      hand.PlayerID,
+				Currency:      string(hand.Currency),
+				HandsPlayed:   hand.HandsPlayed,
+				VPIPHands:     hand.VPIPHands,
+				PFRHands:      hand.PFRHands,
+				Showdowns:     hand.Showdowns,
+				ShowdownsWon:  hand.ShowdownsWon,
+				TotalWinnings: hand.TotalWinnings,
+				BiggestPot:    hand.BiggestPot,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "player_id"}, {Name: "currency"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"hands_played":   gorm.Expr("hands_played + ?", hand.HandsPlayed),
+					"vpip_hands":     gorm.Expr("vpip_hands + ?", hand.VPIPHands),
+					"pfr_hands":      gorm.Expr("pfr_hands + ?", hand.PFRHands),
+					"showdowns":      gorm.Expr("showdowns + ?", hand.Showdowns),
+					"showdowns_won":  gorm.Expr("showdowns_won + ?", hand.ShowdownsWon),
+					"total_winnings": gorm.Expr("total_winnings + ?", hand.TotalWinnings),
+					"biggest_pot":    gorm.Expr("CASE WHEN biggest_pot < ? THEN ? ELSE biggest_pot END", hand.BiggestPot, hand.BiggestPot),
+					"updated_at":     gorm.Expr("CURRENT_TIMESTAMP"),
+				}),
+			}).Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetPlayerStats returns a player's lifetime stats at tables of one
+// currency; a player with no hands has zero stats
+func (s *PlayerStatsStore) GetPlayerStats(playerID string, currency game.TableCurrency) (game.PlayerStats, error) {
+	stats := game.PlayerStats{PlayerID: playerID, Currency: currency}
+
+	var row models.PlayerStats
+	err := s.db.Where("player_id = ? AND currency = ?", playerID, string(currency)).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+
+	stats.HandsPlayed = row.HandsPlayed
+	stats.VPIPHands = row.VPIPHands
+	stats.PFRHands = row.PFRHands
+	stats.Showdowns = row.Showdowns
+	stats.ShowdownsWon = row.ShowdownsWon
+	stats.TotalWinnings = row.TotalWinnings
+	stats.BiggestPot = row.BiggestPot
+	return stats.WithRates(), nil
+}
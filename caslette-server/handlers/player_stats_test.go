@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayerStatsStore_AccumulatesPerCurrency(t *testing.T) {
+	store := NewPlayerStatsStore(newSQLiteDB(t, &models.PlayerStats{}))
+
+	require.NoError(t, store.AddPlayerStats([]game.PlayerStats{
+		{PlayerID: "7", Currency: game.CurrencyDiamonds, HandsPlayed: 1, VPIPHands: 1, PFRHands: 1, Showdowns: 1, ShowdownsWon: 1, TotalWinnings: 40, BiggestPot: 80},
+		{PlayerID: "8", Currency: game.CurrencyDiamonds, HandsPlayed: 1, VPIPHands: 1, Showdowns: 1, TotalWinnings: -40},
+	}))
+	require.NoError(t, store.AddPlayerStats([]game.PlayerStats{
+		{PlayerID: "7", Currency: game.CurrencyDiamonds, HandsPlayed: 1, TotalWinnings: -10},
+		{PlayerID: "7", Currency: game.CurrencyPlayMoney, HandsPlayed: 1, TotalWinnings: 500, BiggestPot: 1000},
+	}))
+	require.NoError(t, store.AddPlayerStats([]game.PlayerStats{
+		{PlayerID: "7", Currency: game.CurrencyDiamonds, HandsPlayed: 1, VPIPHands: 1, TotalWinnings: 30, BiggestPot: 60},
+	}))
+
+	stats, err := store.GetPlayerStats("7", game.CurrencyDiamonds)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.HandsPlayed)
+	assert.Equal(t, int64(2), stats.VPIPHands)
+	assert.Equal(t, int64(1), stats.PFRHands)
+	assert.Equal(t, int64(1), stats.ShowdownsWon)
+	assert.Equal(t, int64(60), stats.TotalWinnings)
+	assert.Equal(t, int64(80), stats.BiggestPot, "a smaller pot does not replace the biggest")
+	assert.InDelta(t, 66.67, stats.VPIP, 0.01)
+
+	practice, err := store.GetPlayerStats("7", game.CurrencyPlayMoney)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), practice.HandsPlayed, "practice hands are kept apart")
+	assert.Equal(t, int64(1000), practice.BiggestPot)
+
+	unknown, err := store.GetPlayerStats("9", game.CurrencyDiamonds)
+	require.NoError(t, err)
+	assert.Equal(t, "9", unknown.PlayerID)
+	assert.Zero(t, unknown.HandsPlayed)
+}
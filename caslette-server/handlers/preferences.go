@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferencesRequest changes a user's preferences; omitted fields are kept
+type PreferencesRequest struct {
+	HandStats *bool `json:"hand_stats"`
+}
+
+// SecurePreferenceHandler manages a user's gameplay preferences
+type SecurePreferenceHandler struct {
+	db *gorm.DB
+}
+
+// NewSecurePreferenceHandler creates a new preferences handler
+func NewSecurePreferenceHandler(db *gorm.DB) *SecurePreferenceHandler {
+	return &SecurePreferenceHandler{db: db}
+}
+
+// Backward compatibility alias
+func NewPreferenceHandler(db *gorm.DB) *SecurePreferenceHandler {
+	return NewSecurePreferenceHandler(db)
+}
+
+// defaultPreferences are the preferences of users who never changed them
+func defaultPreferences(userID uint) models.UserPreference {
+	return models.UserPreference{UserID: userID, HandStats: true}
+}
+
+// LoadPreferences returns a user's preferences, or the defaults when they
+// have none stored
+func LoadPreferences(db *gorm.DB, userID uint) (models.UserPreference, error) {
+	var prefs models.UserPreference
+	err := db.Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultPreferences(userID), nil
+	}
+	if err != nil {
+		return defaultPreferences(userID), err
+	}
+	return prefs, nil
+}
+
+// GetPreferences handles GET /api/v1/preferences for the caller
+func (h *SecurePreferenceHandler) GetPreferences(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	prefs, err := LoadPreferences(h.db, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load preferences",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       prefs,
+		"request_id": requestID,
+	})
+}
+
+// UpdatePreferences handles PUT /api/v1/preferences for the caller
+func (h *SecurePreferenceHandler) UpdatePreferences(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.HandStats == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	prefs, err := LoadPreferences(h.db, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load preferences",
+			"request_id": requestID,
+		})
+		return
+	}
+	prefs.HandStats = *req.HandStats
+
+	if err := h.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&prefs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to save preferences",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       prefs,
+		"request_id": requestID,
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurePreferenceHandler_GetPreferences_RequiresAuth(t *testing.T) {
+	handler := &SecurePreferenceHandler{db: nil}
+	c, w := newDisputeContext("GET", "/preferences", nil, nil)
+
+	handler.GetPreferences(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecurePreferenceHandler_UpdatePreferences_RequiresField(t *testing.T) {
+	handler := &SecurePreferenceHandler{db: nil}
+	c, w := newDisputeContext("PUT", "/preferences", map[string]interface{}{}, uint(1))
+
+	handler.UpdatePreferences(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDefaultPreferencesEnableHandStats(t *testing.T) {
+	prefs := defaultPreferences(7)
+
+	assert.Equal(t, uint(7), prefs.UserID)
+	assert.True(t, prefs.HandStats)
+}
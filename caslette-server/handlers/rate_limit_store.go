@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// RateLimitStore persists the game rate limiter's per-user table counts in
+// the rate_limit_states table
+type RateLimitStore struct {
+	db *gorm.DB
+}
+
+// NewRateLimitStore creates a store over the rate_limit_states table
+func NewRateLimitStore(db *gorm.DB) *RateLimitStore {
+	return &RateLimitStore{db: db}
+}
+
+// LoadRateLimits returns every persisted record
+func (s *RateLimitStore) LoadRateLimits() ([]game.RateLimitRecord, error) {
+	var rows []models.RateLimitState
+	if err := s.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]game.RateLimitRecord, 0, len(rows))
+	for _, row := range rows {
+		record := game.RateLimitRecord{UserID: row.UserID, LastActivity: row.LastActivity}
+		if err := unmarshalTableIDs(row.CreatedTables, &record.CreatedTables); err != nil {
+			return nil, fmt.Errorf("created tables for %s: %w", row.UserID, err)
+		}
+		if err := unmarshalTableIDs(row.ActiveTables, &record.ActiveTables); err != nil {
+			return nil, fmt.Errorf("active tables for %s: %w", row.UserID, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// SaveRateLimits writes a user's record, deleting it once they have no tables
+func (s *RateLimitStore) SaveRateLimits(record game.RateLimitRecord) error {
+	if len(record.CreatedTables) == 0 && len(record.ActiveTables) == 0 {
+		return s.db.Where("user_id = ?", record.UserID).Delete(&models.RateLimitState{}).Error
+	}
+
+	created, err := json.Marshal(nonNilTableIDs(record.CreatedTables))
+	if err != nil {
+		return err
+	}
+	active, err := json.Marshal(nonNilTableIDs(record.ActiveTables))
+	if err != nil {
+		return err
+	}
+	return s.db.Save(&models.RateLimitState{
+		UserID:        record.UserID,
+		CreatedTables: string(created),
+		ActiveTables:  string(active),
+		LastActivity:  record.LastActivity,
+	}).Error
+}
+
+// unmarshalTableIDs reads a JSON array of table IDs; empty means none
+func unmarshalTableIDs(raw string, target *[]string) error {
+	if raw == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(raw), target)
+}
+
+// nonNilTableIDs stores an empty list as [] rather than null
+func nonNilTableIDs(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStore_RoundTripsAndDeletesEmptyRecords(t *testing.T) {
+	db := newSQLiteDB(t, &models.RateLimitState{})
+	store := NewRateLimitStore(db)
+	active := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, store.SaveRateLimits(game.RateLimitRecord{UserID: "7", CreatedTables: []string{"t1", "t2"}, LastActivity: active}))
+	require.NoError(t, store.SaveRateLimits(game.RateLimitRecord{UserID: "8", ActiveTables: []string{"t1"}}))
+	require.NoError(t, store.SaveRateLimits(game.RateLimitRecord{UserID: "7", CreatedTables: []string{"t2"}, ActiveTables: []string{"t2"}, LastActivity: active}))
+
+	records, err := store.LoadRateLimits()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	byUser := map[string]game.RateLimitRecord{}
+	for _, record := range records {
+		byUser[record.UserID] = record
+	}
+	assert.Equal(t, []string{"t2"}, byUser["7"].CreatedTables)
+	assert.Equal(t, []string{"t2"}, byUser["7"].ActiveTables)
+	assert.True(t, active.Equal(byUser["7"].LastActivity))
+	assert.Empty(t, byUser["8"].CreatedTables)
+	assert.Equal(t, []string{"t1"}, byUser["8"].ActiveTables)
+
+	require.NoError(t, store.SaveRateLimits(game.RateLimitRecord{UserID: "8"}))
+	var count int64
+	db.Model(&models.RateLimitState{}).Count(&count)
+	assert.Equal(t, int64(1), count, "a user with no tables is removed")
+}
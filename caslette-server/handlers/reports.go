@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"caslette-server/geo"
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Rake report groupings
+const (
+	RakeGroupByTable = "table"
+	RakeGroupByStake = "stake"
+	RakeGroupByDay   = "day"
+)
+
+// reportDateLayout is the accepted format for report date filters
+const reportDateLayout = "2006-01-02"
+
+// MaxReportRangeDays bounds the window a single report may cover
+const MaxReportRangeDays = 366
+
+// rakeGroupColumns maps groupings to the SQL expression they aggregate on
+var rakeGroupColumns = map[string]string{
+	RakeGroupByTable: "table_id",
+	RakeGroupByStake: "stake_level",
+	RakeGroupByDay:   "DATE(created_at)",
+}
+
+// RakeStore writes the rake taken at each pot settlement to the rake ledger
+// the finance reports are built from
+type RakeStore struct {
+	db *gorm.DB
+}
+
+// NewRakeStore creates a store over the rake_records table
+func NewRakeStore(db *gorm.DB) *RakeStore {
+	return &RakeStore{db: db}
+}
+
+// RecordRake writes one hand's rake as a ledger entry
+func (s *RakeStore) RecordRake(tableID, handID, stakeLevel string, amount int) error {
+	return s.db.Create(&models.RakeRecord{
+		TableID:    tableID,
+		HandID:     handID,
+		StakeLevel: stakeLevel,
+		Amount:     int64(amount),
+	}).Error
+}
+
+// SecureReportHandler serves finance reports built from the rake ledger
+type SecureReportHandler struct {
+	db        *gorm.DB
+	validator *SecurityValidator
+	geo       *geo.Policy // Jurisdiction rules flagged in the region report
+}
+
+// RakeReportRow is one aggregated line of a rake report
+type RakeReportRow struct {
+	Group     string `json:"group" gorm:"column:group_key"`
+	Hands     int64  `json:"hands"`
+	TotalRake int64  `json:"total_rake"`
+}
+
+// NewSecureReportHandler creates a new report handler
+func NewSecureReportHandler(db *gorm.DB) *SecureReportHandler {
+	return &SecureReportHandler{
+		db:        db,
+		validator: NewSecurityValidator(),
+	}
+}
+
+// Backward compatibility alias
+func NewReportHandler(db *gorm.DB) *SecureReportHandler {
+	return NewSecureReportHandler(db)
+}
+
+// SetGeoPolicy sets the jurisdiction rules the region report is checked against
+func (h *SecureReportHandler) SetGeoPolicy(policy *geo.Policy) {
+	h.geo = policy
+}
+
+// RegionReportRow is one region's line of the compliance report
+type RegionReportRow struct {
+	Region              string `json:"region"`
+	Users               int64  `json:"users"`
+	TransactingUsers    int64  `json:"transacting_users"` // Users with diamond movements in the range
+	RegistrationBlocked bool   `json:"registration_blocked"`
+	RealMoneyBlocked    bool   `json:"real_money_blocked"`
+	NeedsReview         bool   `json:"needs_review"` // Diamond activity in a region where real-money play is blocked
+}
+
+// regionCount is a per-region count scanned from the database
+type regionCount struct {
+	Region string
+	Count  int64
+}
+
+// GetRakeReport handles GET /api/v1/reports/rake with admin authorization.
+// Query parameters: group_by (table, stake, day), from and to (YYYY-MM-DD),
+// and format (json or csv).
+func (h *SecureReportHandler) GetRakeReport(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", RakeGroupByTable)
+	column, ok := rakeGroupColumns[groupBy]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid group_by parameter (table, stake or day)",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid format parameter (json or csv)",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	from, to, err := parseReportRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var rows []RakeReportRow
+	err = h.db.Model(&models.RakeRecord{}).
+		Select(column+" AS group_key, COUNT(*) AS hands, COALESCE(SUM(amount), 0) AS total_rake").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group(column).
+		Order("total_rake desc").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to build rake report",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var total int64
+	for _, row := range rows {
+		total += row.TotalRake
+	}
+
+	if format == "csv" {
+		filename := fmt.Sprintf("rake_by_%s_%s_%s.csv", groupBy, from.Format(reportDateLayout), to.AddDate(0, 0, -1).Format(reportDateLayout))
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.Status(http.StatusOK)
+		writeRakeCSV(c.Writer, groupBy, rows, total)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"group_by":   groupBy,
+			"from":       from.Format(reportDateLayout),
+			"to":         to.AddDate(0, 0, -1).Format(reportDateLayout),
+			"rows":       rows,
+			"total_rake": total,
+		},
+		"request_id": requestID,
+	})
+}
+
+// GetRegionReport handles GET /api/v1/reports/regions with admin
+// authorization, listing users and diamond activity by detected region for
+// compliance review. Query parameters: from and to (YYYY-MM-DD).
+func (h *SecureReportHandler) GetRegionReport(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	from, to, err := parseReportRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var users []regionCount
+	if err := h.db.Model(&models.User{}).
+		Select("region, COUNT(*) AS count").
+		Group("region").
+		Scan(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to build region report",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var transacting []regionCount
+	if err := h.db.Table("diamonds").
+		Select("users.region AS region, COUNT(DISTINCT diamonds.user_id) AS count").
+		Joins("JOIN users ON users.id = diamonds.user_id").
+		Where("diamonds.created_at >= ? AND diamonds.created_at < ? AND diamonds.deleted_at IS NULL", from, to).
+		Group("users.region").
+		Scan(&transacting).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to build region report",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"from":    from.Format(reportDateLayout),
+			"to":      to.AddDate(0, 0, -1).Format(reportDateLayout),
+			"rows":    buildRegionReport(users, transacting, h.geo),
+			"blocked": gin.H{"registration": h.geo.Blocked(geo.ActionRegister), "real_money": h.geo.Blocked(geo.ActionRealMoney)},
+		},
+		"request_id": requestID,
+	})
+}
+
+// buildRegionReport merges per-region counts and flags them against the
+// policy, listing regions needing review first and unknown regions last
+func buildRegionReport(users, transacting []regionCount, policy *geo.Policy) []RegionReportRow {
+	rows := make(map[string]*RegionReportRow)
+	row := func(region string) *RegionReportRow {
+		if rows[region] == nil {
+			rows[region] = &RegionReportRow{
+				Region:              region,
+				RegistrationBlocked: !policy.Allows(geo.ActionRegister, region),
+				RealMoneyBlocked:    !policy.Allows(geo.ActionRealMoney, region),
+			}
+		}
+		return rows[region]
+	}
+	for _, count := range users {
+		row(count.Region).Users += count.Count
+	}
+	for _, count := range transacting {
+		r := row(count.Region)
+		r.TransactingUsers += count.Count
+		r.NeedsReview = r.RealMoneyBlocked && r.TransactingUsers > 0
+	}
+
+	report := make([]RegionReportRow, 0, len(rows))
+	for _, r := range rows {
+		report = append(report, *r)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].NeedsReview != report[j].NeedsReview {
+			return report[i].NeedsReview
+		}
+		if (report[i].Region == geo.UnknownRegion) != (report[j].Region == geo.UnknownRegion) {
+			return report[j].Region == geo.UnknownRegion
+		}
+		if report[i].Users != report[j].Users {
+			return report[i].Users > report[j].Users
+		}
+		return report[i].Region < report[j].Region
+	})
+	return report
+}
+
+// hasAdminPermission checks if user has admin role
+func (h *SecureReportHandler) hasAdminPermission(userID uint) bool {
+	isAdmin, err := middleware.HasRole(h.db, userID, "admin")
+	return err == nil && isAdmin
+}
+
+// parseReportRange parses inclusive from/to dates into a half-open time range.
+// Without filters the report covers the last 30 days.
+func parseReportRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+
+	if fromStr != "" {
+		parsed, err := time.Parse(reportDateLayout, fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date (expected YYYY-MM-DD)")
+		}
+		from = parsed
+	}
+	if toStr != "" {
+		parsed, err := time.Parse(reportDateLayout, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date (expected YYYY-MM-DD)")
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from date must not be after to date")
+	}
+	if to.Sub(from) > MaxReportRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("report range cannot exceed %d days", MaxReportRangeDays)
+	}
+
+	return from, to, nil
+}
+
+// writeRakeCSV writes report rows followed by a total line
+func writeRakeCSV(w http.ResponseWriter, groupBy string, rows []RakeReportRow, total int64) {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{groupBy, "hands", "total_rake"})
+	for _, row := range rows {
+		writer.Write([]string{row.Group, strconv.FormatInt(row.Hands, 10), strconv.FormatInt(row.TotalRake, 10)})
+	}
+	writer.Write([]string{"total", "", strconv.FormatInt(total, 10)})
+	writer.Flush()
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"caslette-server/geo"
+	"caslette-server/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createMockReportHandler() *SecureReportHandler {
+	return &SecureReportHandler{
+		db:        nil, // No actual DB for unit tests
+		validator: NewSecurityValidator(),
+	}
+}
+
+func TestSecureReportHandler_GetRakeReport_RequiresAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createMockReportHandler()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/reports/rake", nil)
+
+	handler.GetRakeReport(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureReportHandler_GetRakeReport_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createMockReportHandler()
+
+	queries := []string{
+		"group_by=player",
+		"group_by=table_id%3BDROP",
+		"format=xml",
+		"from=2024-13-01",
+		"from=2024-02-01&to=2024-01-01",
+		"from=2020-01-01&to=2024-01-01",
+	}
+
+	for _, query := range queries {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/reports/rake?"+query, nil)
+		c.Set("user_id", uint(1))
+
+		handler.GetRakeReport(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "query %s should be rejected", query)
+	}
+}
+
+func TestParseReportRange(t *testing.T) {
+	from, to, err := parseReportRange("2024-03-01", "2024-03-31")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), to, "to date is inclusive")
+
+	from, to, err = parseReportRange("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, to.Sub(from))
+}
+
+func TestWriteRakeCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+
+	writeRakeCSV(w, RakeGroupByStake, []RakeReportRow{
+		{Group: "10/20", Hands: 12, TotalRake: 240},
+		{Group: "5/10", Hands: 3, TotalRake: 15},
+	}, 255)
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, []string{"stake,hands,total_rake", "10/20,12,240", "5/10,3,15", "total,,255"}, lines)
+	assert.False(t, bytes.Contains(w.Body.Bytes(), []byte("\r")))
+}
+
+func TestSecureReportHandler_GetRegionReport_RequiresAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createMockReportHandler()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/reports/regions", nil)
+
+	handler.GetRegionReport(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureReportHandler_GetRegionReport_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createMockReportHandler()
+
+	for _, query := range []string{"from=2024-13-01", "from=2024-02-01&to=2024-01-01"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/reports/regions?"+query, nil)
+		c.Set("user_id", uint(1))
+
+		handler.GetRegionReport(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "query %s should be rejected", query)
+	}
+}
+
+func TestBuildRegionReport(t *testing.T) {
+	policy := geo.NewPolicy(nil, map[geo.Action][]string{
+		geo.ActionRegister:  {"KP"},
+		geo.ActionRealMoney: {"US"},
+	})
+	users := []regionCount{{Region: "FR", Count: 10}, {Region: "US-NV", Count: 3}, {Region: "", Count: 20}, {Region: "KP", Count: 1}}
+	transacting := []regionCount{{Region: "FR", Count: 4}, {Region: "US-NV", Count: 2}}
+
+	rows := buildRegionReport(users, transacting, policy)
+
+	assert.Len(t, rows, 4)
+	assert.Equal(t, "US-NV", rows[0].Region, "blocked regions with real-money activity come first")
+	assert.True(t, rows[0].RealMoneyBlocked)
+	assert.True(t, rows[0].NeedsReview)
+	assert.Equal(t, int64(2), rows[0].TransactingUsers)
+
+	assert.Equal(t, "FR", rows[1].Region)
+	assert.False(t, rows[1].NeedsReview)
+	assert.Equal(t, "KP", rows[2].Region)
+	assert.True(t, rows[2].RegistrationBlocked)
+	assert.Equal(t, geo.UnknownRegion, rows[3].Region, "unknown region is listed last")
+}
+
+func TestRakeStore_RecordRake(t *testing.T) {
+	db := newSQLiteDB(t, &models.RakeRecord{})
+	store := NewRakeStore(db)
+
+	require.NoError(t, store.RecordRake("table1", "table1-7", "10/20", 15))
+
+	var records []models.RakeRecord
+	require.NoError(t, db.Find(&records).Error)
+	require.Len(t, records, 1)
+	assert.Equal(t, "table1", records[0].TableID)
+	assert.Equal(t, "table1-7", records[0].HandID)
+	assert.Equal(t, "10/20", records[0].StakeLevel)
+	assert.Equal(t, int64(15), records[0].Amount)
+	assert.False(t, records[0].CreatedAt.IsZero())
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Retention categories
+const (
+	RetentionAuditLogs      = "audit_logs"
+	RetentionLoginEvents    = "login_events"
+	RetentionClosedAccounts = "closed_accounts"
+)
+
+// Retention scheduling and reporting defaults
+const (
+	DefaultRetentionPurgeEvery    = time.Hour
+	DefaultClosedAccountRetention = 30 * 24 * time.Hour
+	DefaultPurgeReportLimit       = 50
+	MaxPurgeReportLimit           = 500
+)
+
+// RetentionPurger deletes a category's records older than cutoff and returns
+// how many went
+type RetentionPurger func(cutoff time.Time) (int64, error)
+
+// RetentionPolicy is how long one category of data is kept
+type RetentionPolicy struct {
+	Category  string
+	Retention time.Duration // Zero keeps the data forever
+	Purge     RetentionPurger
+}
+
+// RetentionService purges each registered category of data once it is older
+// than the category's retention period and records every run for admins
+type RetentionService struct {
+	db *gorm.DB
+
+	mu       sync.Mutex
+	policies []RetentionPolicy
+	stop     chan struct{}
+	now      func() time.Time
+}
+
+// NewRetentionService creates a service recording its runs in purge_records
+func NewRetentionService(db *gorm.DB) *RetentionService {
+	return &RetentionService{db: db, now: time.Now}
+}
+
+// Register adds or replaces the policy for a category
+func (s *RetentionService) Register(category string, retention time.Duration, purge RetentionPurger) {
+	if retention < 0 {
+		retention = 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	policy := RetentionPolicy{Category: category, Retention: retention, Purge: purge}
+	for i := range s.policies {
+		if s.policies[i].Category == category {
+			s.policies[i] = policy
+			return
+		}
+	}
+	s.policies = append(s.policies, policy)
+}
+
+// Policies returns the registered policies in registration order
+func (s *RetentionService) Policies() []RetentionPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RetentionPolicy(nil), s.policies...)
+}
+
+// RunOnce purges every category with a retention period and returns the
+// records of the run. triggeredBy is the admin asking for it, zero for the
+// schedule.
+func (s *RetentionService) RunOnce(triggeredBy uint) []models.PurgeRecord {
+	now := s.now()
+	records := make([]models.PurgeRecord, 0)
+	for _, policy := range s.Policies() {
+		if policy.Retention <= 0 {
+			continue
+		}
+		record := models.PurgeRecord{
+			Category:    policy.Category,
+			Cutoff:      now.Add(-policy.Retention),
+			TriggeredBy: triggeredBy,
+			CreatedAt:   now,
+		}
+		purged, err := policy.Purge(record.Cutoff)
+		record.Purged = purged
+		if err != nil {
+			record.Error = err.Error()
+			if len(record.Error) > 255 {
+				record.Error = record.Error[:255]
+			}
+			log.Printf("RetentionService: failed to purge %s: %v", policy.Category, err)
+		}
+		if err := s.db.Create(&record).Error; err != nil {
+			log.Printf("RetentionService: failed to record %s purge: %v", policy.Category, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Start purges every interval until Stop is called
+func (s *RetentionService) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRetentionPurgeEvery
+	}
+
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	stop := s.stop
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				for _, record := range s.RunOnce(0) {
+					if record.Purged > 0 {
+						log.Printf("RetentionService: purged %d %s older than %s", record.Purged, record.Category, record.Cutoff.Format(time.RFC3339))
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends scheduled purging
+func (s *RetentionService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// PurgeLoginEvents returns a purger deleting sign-in history
+func PurgeLoginEvents(db *gorm.DB) RetentionPurger {
+	return func(cutoff time.Time) (int64, error) {
+		result := db.Where("created_at < ?", cutoff).Delete(&models.LoginEvent{})
+		return result.RowsAffected, result.Error
+	}
+}
+
+// purgedEmailDomain marks the address of an account whose personal data has
+// been purged
+const purgedEmailDomain = "@purged.invalid"
+
+// PurgeClosedAccounts returns a purger removing the personal data of accounts
+// deleted before cutoff. The account row stays, renamed and with its email
+// and password cleared, so the diamond ledger and disputes it appears in
+// remain intact; its sign-in history, preferences, tags, cosmetics, bot
+// tokens, roles and permissions are deleted.
+func PurgeClosedAccounts(db *gorm.DB) RetentionPurger {
+	return func(cutoff time.Time) (int64, error) {
+		var users []models.User
+		if err := db.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Where("email NOT LIKE ?", "%"+purgedEmailDomain).
+			Find(&users).Error; err != nil {
+			return 0, err
+		}
+
+		var purged int64
+		for _, user := range users {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				for _, personal := range []interface{}{
+					&models.LoginEvent{}, &models.UserPreference{}, &models.UserTag{}, &models.UserCosmetic{},
+					&models.BotToken{}, &models.UserRole{}, &models.UserPermission{},
+				} {
+					if err := tx.Where("user_id = ?", user.ID).Delete(personal).Error; err != nil {
+						return err
+					}
+				}
+				alias := fmt.Sprintf("purged_%d", user.ID)
+				return tx.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+					"username":   alias,
+					"email":      alias + purgedEmailDomain,
+					"first_name": "",
+					"last_name":  "",
+					"password":   "",
+					"region":     "",
+				}).Error
+			})
+			if err != nil {
+				return purged, fmt.Errorf("account %d: %w", user.ID, err)
+			}
+			purged++
+		}
+		return purged, nil
+	}
+}
+
+// RetentionHandler reports retention policies and purges to admins
+type RetentionHandler struct {
+	db      *gorm.DB
+	service *RetentionService
+}
+
+// NewRetentionHandler creates a handler over the service's records
+func NewRetentionHandler(service *RetentionService) *RetentionHandler {
+	return &RetentionHandler{db: service.db, service: service}
+}
+
+// GetRetention handles GET /api/v1/admin/retention: every policy with its
+// last run, followed by the most recent purges, optionally for one category
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	limit := DefaultPurgeReportLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > MaxPurgeReportLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      fmt.Sprintf("limit must be between 1 and %d", MaxPurgeReportLimit),
+				"request_id": requestID,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	policies := make([]gin.H, 0)
+	for _, policy := range h.service.Policies() {
+		entry := gin.H{
+			"category":          policy.Category,
+			"retention_seconds": int64(policy.Retention / time.Second),
+		}
+		var last models.PurgeRecord
+		if err := h.db.Where("category = ?", policy.Category).Order("created_at desc").Order("id desc").First(&last).Error; err == nil {
+			entry["last_purge"] = last
+		}
+		policies = append(policies, entry)
+	}
+
+	query := h.db.Model(&models.PurgeRecord{})
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	purges := make([]models.PurgeRecord, 0)
+	if err := query.Order("created_at desc").Order("id desc").Limit(limit).Find(&purges).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load purge history",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"policies": policies,
+			"purges":   purges,
+		},
+		"request_id": requestID,
+	})
+}
+
+// RunPurge handles POST /api/v1/admin/retention/purge, purging every
+// category now
+func (h *RetentionHandler) RunPurge(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	records := h.service.RunOnce(c.GetUint("user_id"))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"purges": records,
+		},
+		"request_id": requestID,
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getRetention(handler *RetentionHandler, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/admin/retention?"+query, nil)
+	handler.GetRetention(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data, _ := response["data"].(map[string]interface{})
+	return w, data
+}
+
+func TestRetentionService_PurgesExpiredRecordsAndReports(t *testing.T) {
+	db := newSQLiteDB(t, &models.AuditLog{}, &models.LoginEvent{}, &models.PurgeRecord{})
+	now := time.Now().UTC()
+	store := NewAuditLogStore(db, 24*time.Hour)
+	for _, age := range []time.Duration{48 * time.Hour, 30 * time.Hour, time.Hour} {
+		require.NoError(t, store.SaveAuditEntry(game.AuditLogEntry{Timestamp: now.Add(-age), UserID: "1", Action: "join_table", Result: "success"}))
+	}
+	require.NoError(t, db.Create(&models.LoginEvent{UserID: 1, Success: true, CreatedAt: now.Add(-400 * 24 * time.Hour)}).Error)
+
+	service := NewRetentionService(db)
+	service.Register(RetentionAuditLogs, store.Retention(), store.PruneBefore)
+	service.Register(RetentionLoginEvents, 0, PurgeLoginEvents(db))
+	service.Register("broken", time.Hour, func(time.Time) (int64, error) { return 0, errors.New("store offline") })
+
+	records := service.RunOnce(7)
+	require.Len(t, records, 2, "categories kept forever are not run")
+	assert.Equal(t, RetentionAuditLogs, records[0].Category)
+	assert.Equal(t, int64(2), records[0].Purged)
+	assert.Equal(t, uint(7), records[0].TriggeredBy)
+	assert.Equal(t, "store offline", records[1].Error)
+
+	var remaining int64
+	db.Model(&models.AuditLog{}).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+	db.Model(&models.LoginEvent{}).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+
+	w, data := getRetention(NewRetentionHandler(service), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	policies := data["policies"].([]interface{})
+	require.Len(t, policies, 3)
+	audit := policies[0].(map[string]interface{})
+	assert.Equal(t, float64(24*60*60), audit["retention_seconds"])
+	assert.Equal(t, float64(2), audit["last_purge"].(map[string]interface{})["purged"])
+	assert.NotContains(t, policies[1], "last_purge", "login events have never been purged")
+	assert.Len(t, data["purges"], 2)
+
+	_, data = getRetention(NewRetentionHandler(service), "category=broken")
+	assert.Len(t, data["purges"], 1)
+	w, _ = getRetention(NewRetentionHandler(service), "limit=0")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPurgeClosedAccounts_RemovesPersonalData(t *testing.T) {
+	db := newSQLiteDB(t, &models.User{}, &models.LoginEvent{}, &models.UserPreference{}, &models.UserTag{},
+		&models.UserCosmetic{}, &models.BotToken{}, &models.UserRole{}, &models.UserPermission{}, &models.Diamond{})
+	closed := models.User{Username: "closed", Email: "closed@example.com", Password: "hash", FirstName: "Cl", Region: "US"}
+	recent := models.User{Username: "recent", Email: "recent@example.com", Password: "hash"}
+	active := models.User{Username: "active", Email: "active@example.com", Password: "hash"}
+	for _, user := range []*models.User{&closed, &recent, &active} {
+		require.NoError(t, db.Create(user).Error)
+		require.NoError(t, db.Create(&models.LoginEvent{UserID: user.ID, Success: true}).Error)
+	}
+	require.NoError(t, db.Create(&models.Diamond{UserID: closed.ID, Amount: 100, Balance: 100, TransactionID: "tx1", Type: "credit"}).Error)
+	require.NoError(t, db.Delete(&closed).Error)
+	require.NoError(t, db.Model(&closed).Unscoped().Update("deleted_at", time.Now().Add(-60*24*time.Hour)).Error)
+	require.NoError(t, db.Delete(&recent).Error)
+
+	purge := PurgeClosedAccounts(db)
+	purged, err := purge(time.Now().Add(-DefaultClosedAccountRetention))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var user models.User
+	require.NoError(t, db.Unscoped().First(&user, closed.ID).Error)
+	assert.Equal(t, "purged_1", user.Username)
+	assert.Equal(t, "purged_1@purged.invalid", user.Email)
+	assert.Empty(t, user.Password)
+	assert.Empty(t, user.FirstName)
+	assert.Empty(t, user.Region)
+
+	var count int64
+	db.Model(&models.LoginEvent{}).Where("user_id = ?", closed.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.LoginEvent{}).Count(&count)
+	assert.Equal(t, int64(2), count, "accounts closed recently or still open keep their history")
+	db.Model(&models.Diamond{}).Where("user_id = ?", closed.ID).Count(&count)
+	assert.Equal(t, int64(1), count, "the diamond ledger is kept")
+
+	purged, err = purge(time.Now().Add(-DefaultClosedAccountRetention))
+	require.NoError(t, err)
+	assert.Zero(t, purged, "an account is purged once")
+	var kept models.User
+	require.NoError(t, db.Unscoped().First(&kept, recent.ID).Error)
+	assert.Equal(t, "recent@example.com", kept.Email)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"caslette-server/middleware"
 	"caslette-server/models"
 	"net/http"
 	"strconv"
@@ -126,6 +127,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
 		return
 	}
+	middleware.InvalidateAllPermissions()
 
 	c.JSON(http.StatusOK, role)
 }
@@ -142,6 +144,7 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
 		return
 	}
+	middleware.InvalidateAllPermissions()
 
 	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
 }
@@ -185,6 +188,7 @@ func (h *RoleHandler) AssignPermissions(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign permissions"})
 		return
 	}
+	middleware.InvalidateAllPermissions()
 
 	// Reload role with permissions
 	if err := h.db.Preload("Permissions").First(&role, uint(id)).Error; err != nil {
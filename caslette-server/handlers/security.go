@@ -127,6 +127,28 @@ func (s *SecurityValidator) ValidateAndSanitizeString(input, inputType string, m
 	return sanitized, nil
 }
 
+// SanitizeFreeText validates user-written prose such as reasons and notes.
+// Keyword-based SQL and command patterns reject ordinary English, so only
+// markup is blocked here; storage relies on parameterized queries.
+func (s *SecurityValidator) SanitizeFreeText(input, inputType string, maxLength int) (string, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return "", fmt.Errorf("%s cannot be empty", inputType)
+	}
+
+	if len(trimmed) > maxLength {
+		return "", fmt.Errorf("%s exceeds maximum length of %d characters", inputType, maxLength)
+	}
+
+	for _, pattern := range xssPatterns {
+		if pattern.MatchString(trimmed) {
+			return "", fmt.Errorf("%s contains dangerous content: XSS attempt detected", inputType)
+		}
+	}
+
+	return html.EscapeString(trimmed), nil
+}
+
 // ValidateID validates and converts string ID to uint
 func (s *SecurityValidator) ValidateID(idStr string) (uint, error) {
 	if idStr == "" {
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"caslette-server/middleware"
+	"caslette-server/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Overall system states reported by the status endpoint
+const (
+	SystemStatusOperational = "operational"
+	SystemStatusDegraded    = "degraded"
+	SystemStatusMaintenance = "maintenance"
+)
+
+// MaxDegradedFeatures caps the degraded feature list an admin may publish
+const MaxDegradedFeatures = 20
+
+// validFeaturePattern restricts feature identifiers such as "chat" or "cashier"
+var validFeaturePattern = regexp.MustCompile(`^[a-z0-9_.-]{1,50}$`)
+
+// MaintenanceWindow is a scheduled period of planned downtime
+type MaintenanceWindow struct {
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	Description string    `json:"description" binding:"max=200"`
+}
+
+// UpdateStatusRequest replaces the published operational status
+type UpdateStatusRequest struct {
+	MaintenanceWindows []MaintenanceWindow `json:"maintenance_windows" binding:"max=10,dive"`
+	DegradedFeatures   []string            `json:"degraded_features"`
+	IncidentMessage    string              `json:"incident_message" binding:"max=500"`
+}
+
+// StatusHandler serves non-sensitive operational status for status pages and clients
+type StatusHandler struct {
+	db        *gorm.DB
+	validator *SecurityValidator
+	startedAt time.Time
+
+	mu                 sync.RWMutex
+	maintenanceWindows []MaintenanceWindow
+	degradedFeatures   []string
+	incidentMessage    string
+}
+
+// systemStatusID is the row holding the published status
+const systemStatusID = 1
+
+// NewStatusHandler creates a status handler with the last published status;
+// uptime is measured from creation
+func NewStatusHandler(db *gorm.DB) *StatusHandler {
+	h := &StatusHandler{
+		db:        db,
+		validator: NewSecurityValidator(),
+		startedAt: time.Now(),
+	}
+	if db != nil {
+		if err := h.load(); err != nil {
+			log.Printf("Status: failed to load published status: %v", err)
+		}
+	}
+	return h
+}
+
+// load restores the published status saved by a previous update
+func (h *StatusHandler) load() error {
+	var saved models.SystemStatus
+	err := h.db.Where("id = ?", systemStatusID).Limit(1).Find(&saved).Error
+	if err != nil || saved.ID == 0 {
+		return err
+	}
+
+	var windows []MaintenanceWindow
+	if saved.MaintenanceWindows != "" {
+		if err := json.Unmarshal([]byte(saved.MaintenanceWindows), &windows); err != nil {
+			return err
+		}
+	}
+	var features []string
+	if saved.DegradedFeatures != "" {
+		if err := json.Unmarshal([]byte(saved.DegradedFeatures), &features); err != nil {
+			return err
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maintenanceWindows = windows
+	h.degradedFeatures = features
+	h.incidentMessage = saved.IncidentMessage
+	return nil
+}
+
+// save persists the published status so it survives restarts
+func (h *StatusHandler) save(windows []MaintenanceWindow, features []string, incident string, updatedBy uint) error {
+	windowsJSON, err := json.Marshal(windows)
+	if err != nil {
+		return err
+	}
+	featuresJSON, err := json.Marshal(features)
+	if err != nil {
+		return err
+	}
+	return h.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.SystemStatus{
+		ID:                 systemStatusID,
+		MaintenanceWindows: string(windowsJSON),
+		DegradedFeatures:   string(featuresJSON),
+		IncidentMessage:    incident,
+		UpdatedBy:          updatedBy,
+	}).Error
+}
+
+// GetStatus handles GET /api/v1/status (public)
+func (h *StatusHandler) GetStatus(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	now := time.Now()
+
+	h.mu.RLock()
+	windows := make([]MaintenanceWindow, 0, len(h.maintenanceWindows))
+	inMaintenance := false
+	for _, window := range h.maintenanceWindows {
+		if window.EndsAt.Before(now) {
+			continue
+		}
+		if !now.Before(window.StartsAt) {
+			inMaintenance = true
+		}
+		windows = append(windows, window)
+	}
+	degraded := append([]string{}, h.degradedFeatures...)
+	incident := h.incidentMessage
+	h.mu.RUnlock()
+
+	status := SystemStatusOperational
+	if inMaintenance {
+		status = SystemStatusMaintenance
+	} else if len(degraded) > 0 || incident != "" {
+		status = SystemStatusDegraded
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"status":              status,
+			"uptime_seconds":      int64(now.Sub(h.startedAt).Seconds()),
+			"started_at":          h.startedAt.UTC(),
+			"maintenance_windows": windows,
+			"degraded_features":   degraded,
+			"incident_message":    incident,
+			"server_time":         now.UTC(),
+		},
+		"request_id": requestID,
+	})
+}
+
+// UpdateStatus handles PUT /api/v1/status with admin authorization
+func (h *StatusHandler) UpdateStatus(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// Only admins learn how a status update would be validated
+	if !h.hasAdminPermission(userID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "Insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	for i, window := range req.MaintenanceWindows {
+		if !window.EndsAt.After(window.StartsAt) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Maintenance window must end after it starts",
+				"request_id": requestID,
+			})
+			return
+		}
+		if window.Description != "" {
+			description, err := h.validator.SanitizeFreeText(window.Description, "description", 200)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success":    false,
+					"error":      "Invalid description: " + err.Error(),
+					"request_id": requestID,
+				})
+				return
+			}
+			req.MaintenanceWindows[i].Description = description
+		}
+	}
+
+	if len(req.DegradedFeatures) > MaxDegradedFeatures {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Too many degraded features",
+			"request_id": requestID,
+		})
+		return
+	}
+	features := make([]string, 0, len(req.DegradedFeatures))
+	for _, feature := range req.DegradedFeatures {
+		if !validFeaturePattern.MatchString(feature) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid feature name",
+				"request_id": requestID,
+			})
+			return
+		}
+		features = append(features, feature)
+	}
+
+	incident := ""
+	if req.IncidentMessage != "" {
+		sanitized, err := h.validator.SanitizeFreeText(req.IncidentMessage, "incident_message", 500)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid incident message: " + err.Error(),
+				"request_id": requestID,
+			})
+			return
+		}
+		incident = sanitized
+	}
+
+	sort.Slice(req.MaintenanceWindows, func(i, j int) bool {
+		return req.MaintenanceWindows[i].StartsAt.Before(req.MaintenanceWindows[j].StartsAt)
+	})
+
+	if err := h.save(req.MaintenanceWindows, features, incident, userID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to save status",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	h.mu.Lock()
+	h.maintenanceWindows = req.MaintenanceWindows
+	h.degradedFeatures = features
+	h.incidentMessage = incident
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Status updated successfully",
+		"request_id": requestID,
+	})
+}
+
+// hasAdminPermission checks if user has admin role
+func (h *StatusHandler) hasAdminPermission(userID uint) bool {
+	isAdmin, err := middleware.HasRole(h.db, userID, "admin")
+	return err == nil && isAdmin
+}
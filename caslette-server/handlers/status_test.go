@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func performStatusGet(handler *StatusHandler) map[string]interface{} {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/status", nil)
+	handler.GetStatus(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response["data"].(map[string]interface{})
+}
+
+func TestStatusHandler_GetStatus_Operational(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewStatusHandler(nil)
+
+	data := performStatusGet(handler)
+
+	assert.Equal(t, SystemStatusOperational, data["status"])
+	assert.Contains(t, data, "uptime_seconds")
+	assert.Empty(t, data["degraded_features"])
+}
+
+func TestStatusHandler_GetStatus_MaintenanceAndDegraded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewStatusHandler(nil)
+	now := time.Now()
+
+	handler.degradedFeatures = []string{"chat"}
+	data := performStatusGet(handler)
+	assert.Equal(t, SystemStatusDegraded, data["status"])
+
+	handler.maintenanceWindows = []MaintenanceWindow{
+		{StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour), Description: "past"},
+		{StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), Description: "current"},
+	}
+	data = performStatusGet(handler)
+	assert.Equal(t, SystemStatusMaintenance, data["status"])
+	assert.Len(t, data["maintenance_windows"], 1, "past windows are not published")
+}
+
+// newStatusDB opens a database with status and role tables; adminID holds the admin role
+func newStatusDB(t *testing.T, adminID uint) *gorm.DB {
+	db := newSQLiteDB(t, &models.User{}, &models.Role{}, &models.UserRole{}, &models.Permission{},
+		&models.RolePermission{}, &models.UserPermission{}, &models.SystemStatus{})
+	role := models.Role{Name: "admin"}
+	require.NoError(t, db.Create(&role).Error)
+	require.NoError(t, db.Create(&models.UserRole{UserID: adminID, RoleID: role.ID}).Error)
+	return db
+}
+
+func performStatusUpdate(handler *StatusHandler, userID uint, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/status", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", userID)
+	handler.UpdateStatus(c)
+	return w
+}
+
+func TestStatusHandler_UpdateStatus_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewStatusHandler(newStatusDB(t, 2341))
+	now := time.Now()
+
+	bodies := []map[string]interface{}{
+		{"maintenance_windows": []map[string]interface{}{{"starts_at": now, "ends_at": now.Add(-time.Hour)}}},
+		{"degraded_features": []string{"chat; rm -rf"}},
+		{"incident_message": "<script>alert(1)</script>"},
+	}
+
+	for _, body := range bodies {
+		w := performStatusUpdate(handler, 2341, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestStatusHandler_UpdateStatus_ChecksAdminBeforeValidating(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewStatusHandler(newStatusDB(t, 2342))
+
+	w := performStatusUpdate(handler, 2343, map[string]interface{}{"degraded_features": []string{"chat; rm -rf"}})
+	assert.Equal(t, http.StatusForbidden, w.Code, "non-admins are refused before their payload is inspected")
+}
+
+func TestStatusHandler_UpdateStatus_PersistsAcrossRestarts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newStatusDB(t, 2344)
+	handler := NewStatusHandler(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	w := performStatusUpdate(handler, 2344, map[string]interface{}{
+		"maintenance_windows": []map[string]interface{}{{"starts_at": now.Add(time.Hour), "ends_at": now.Add(2 * time.Hour), "description": "upgrade"}},
+		"degraded_features":   []string{"chat"},
+		"incident_message":    "Chat is delayed",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var saved models.SystemStatus
+	require.NoError(t, db.First(&saved).Error)
+	assert.Equal(t, uint(2344), saved.UpdatedBy)
+
+	data := performStatusGet(NewStatusHandler(db))
+	assert.Equal(t, SystemStatusDegraded, data["status"])
+	assert.Equal(t, []interface{}{"chat"}, data["degraded_features"])
+	assert.Equal(t, "Chat is delayed", data["incident_message"])
+	assert.Len(t, data["maintenance_windows"], 1)
+
+	// Clearing the status is persisted too
+	require.Equal(t, http.StatusOK, performStatusUpdate(handler, 2344, map[string]interface{}{}).Code)
+	data = performStatusGet(NewStatusHandler(db))
+	assert.Equal(t, SystemStatusOperational, data["status"])
+}
+
+func TestStatusHandler_UpdateStatus_RequiresAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewStatusHandler(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/status", bytes.NewBufferString(`{}`))
+
+	handler.UpdateStatus(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
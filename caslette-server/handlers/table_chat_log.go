@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Table chat log listing limits
+const (
+	DefaultTableChatLimit = 100
+	MaxTableChatLimit     = 500
+)
+
+// TableChatLogStore persists what is said in table chat for moderators
+type TableChatLogStore struct {
+	db *gorm.DB
+}
+
+// NewTableChatLogStore creates a store over the table_chat_messages table
+func NewTableChatLogStore(db *gorm.DB) *TableChatLogStore {
+	return &TableChatLogStore{db: db}
+}
+
+// SaveChatLine records one chat line
+func (s *TableChatLogStore) SaveChatLine(line game.TableChatLine) error {
+	return s.db.Create(&models.TableChatMessage{
+		TableID:   line.TableID,
+		HandID:    line.HandID,
+		PlayerID:  line.PlayerID,
+		Username:  line.Username,
+		Text:      line.Text,
+		Original:  line.Original,
+		Filtered:  line.Filtered,
+		Observer:  line.Observer,
+		CreatedAt: line.SentAt,
+	}).Error
+}
+
+// TableChatLogHandler serves persisted table chat to admins
+type TableChatLogHandler struct {
+	db        *gorm.DB
+	validator *SecurityValidator
+}
+
+// NewTableChatLogHandler creates a handler over the store's table
+func NewTableChatLogHandler(store *TableChatLogStore) *TableChatLogHandler {
+	return &TableChatLogHandler{db: store.db, validator: NewSecurityValidator()}
+}
+
+// ListMessages handles GET /api/v1/admin/chat/messages: chat lines newest
+// first, filtered by ?table_id, ?player_id, ?hand_id, ?filtered=true for
+// lines the profanity filter masked, and ?since as an RFC 3339 timestamp
+func (h *TableChatLogHandler) ListMessages(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	limit, err := h.validator.ValidatePositiveInt(c.DefaultQuery("limit", strconv.Itoa(DefaultTableChatLimit)), "limit")
+	if err != nil || limit > MaxTableChatLimit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "limit must be between 1 and " + strconv.Itoa(MaxTableChatLimit),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	query := h.db.Model(&models.TableChatMessage{})
+	for _, column := range []string{"table_id", "player_id", "hand_id"} {
+		if raw := c.Query(column); raw != "" {
+			if !auditFilterPattern.MatchString(raw) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success":    false,
+					"error":      "Invalid " + column + " filter",
+					"request_id": requestID,
+				})
+				return
+			}
+			query = query.Where(column+" = ?", raw)
+		}
+	}
+	if c.Query("filtered") == "true" {
+		query = query.Where("filtered = ?", true)
+	}
+	if raw := c.Query("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "since must be an RFC 3339 timestamp",
+				"request_id": requestID,
+			})
+			return
+		}
+		query = query.Where("created_at >= ?", since)
+	}
+
+	messages := make([]models.TableChatMessage, 0)
+	if err := query.Order("created_at desc").Order("id desc").Limit(limit).Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to fetch chat messages",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"messages": messages},
+		"request_id": requestID,
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableChatLogStore_SavesAndListsLines(t *testing.T) {
+	db := newSQLiteDB(t, &models.TableChatMessage{})
+	store := NewTableChatLogStore(db)
+	handler := NewTableChatLogHandler(store)
+
+	sent := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.SaveChatLine(game.TableChatLine{
+		TableID: "t1", HandID: "h1", PlayerID: "7", Username: "seven", Text: "nice hand", SentAt: sent,
+	}))
+	require.NoError(t, store.SaveChatLine(game.TableChatLine{
+		TableID: "t1", PlayerID: "9", Username: "nine", Text: "**** off", Original: "shit off",
+		Filtered: true, Observer: true, SentAt: sent.Add(time.Second),
+	}))
+	require.NoError(t, store.SaveChatLine(game.TableChatLine{
+		TableID: "t2", PlayerID: "7", Username: "seven", Text: "gl", SentAt: sent.Add(2 * time.Second),
+	}))
+
+	list := func(target string) (int, []models.TableChatMessage) {
+		c, w := newDisputeContext("GET", target, nil, uint(1))
+		handler.ListMessages(c)
+		var response struct {
+			Data struct {
+				Messages []models.TableChatMessage `json:"messages"`
+			} `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response.Data.Messages
+	}
+
+	code, messages := list("/admin/chat/messages?table_id=t1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "9", messages[0].PlayerID, "newest first")
+	assert.Equal(t, "shit off", messages[0].Original)
+	assert.True(t, messages[0].Observer)
+	assert.Equal(t, "h1", messages[1].HandID)
+
+	_, messages = list("/admin/chat/messages?filtered=true")
+	require.Len(t, messages, 1)
+	assert.Equal(t, "9", messages[0].PlayerID)
+
+	_, messages = list("/admin/chat/messages?player_id=7&limit=1")
+	require.Len(t, messages, 1)
+	assert.Equal(t, "t2", messages[0].TableID)
+
+	code, _ = list("/admin/chat/messages?since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = list("/admin/chat/messages?table_id=t1%27--")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
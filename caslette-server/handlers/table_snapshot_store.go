@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TableSnapshotStore persists crash recovery snapshots of open tables in the
+// table_snapshots table, one row per table
+type TableSnapshotStore struct {
+	db *gorm.DB
+}
+
+// NewTableSnapshotStore creates a store over the table_snapshots table
+func NewTableSnapshotStore(db *gorm.DB) *TableSnapshotStore {
+	return &TableSnapshotStore{db: db}
+}
+
+// SaveSnapshot writes a table's snapshot, replacing the one saved before
+func (s *TableSnapshotStore) SaveSnapshot(snapshot game.TableSnapshot) error {
+	escrow, err := json.Marshal(snapshot.Escrow)
+	if err != nil {
+		return err
+	}
+	return s.db.Save(&models.TableSnapshot{
+		TableID:  snapshot.TableID,
+		GameType: string(snapshot.GameType),
+		Table:    string(snapshot.Table),
+		Engine:   string(snapshot.Engine),
+		Escrow:   string(escrow),
+		SavedAt:  snapshot.SavedAt,
+
+		PasswordHash: snapshot.PasswordHash,
+	}).Error
+}
+
+// LoadSnapshots returns every saved snapshot
+func (s *TableSnapshotStore) LoadSnapshots() ([]game.TableSnapshot, error) {
+	var rows []models.TableSnapshot
+	if err := s.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]game.TableSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshot := game.TableSnapshot{
+			TableID:  row.TableID,
+			GameType: game.GameType(row.GameType),
+			Table:    []byte(row.Table),
+			Engine:   []byte(row.Engine),
+			SavedAt:  row.SavedAt,
+
+			PasswordHash: row.PasswordHash,
+		}
+		if row.Escrow != "" {
+			if err := json.Unmarshal([]byte(row.Escrow), &snapshot.Escrow); err != nil {
+				return nil, fmt.Errorf("escrow for table %s: %w", row.TableID, err)
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// DeleteSnapshot removes a closed table's snapshot
+func (s *TableSnapshotStore) DeleteSnapshot(tableID string) error {
+	return s.db.Where("table_id = ?", tableID).Delete(&models.TableSnapshot{}).Error
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"caslette-server/game"
+	"caslette-server/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableSnapshotStore_ReplacesAndDeletesSnapshots(t *testing.T) {
+	db := newSQLiteDB(t, &models.TableSnapshot{})
+	store := NewTableSnapshotStore(db)
+	saved := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, store.SaveSnapshot(game.TableSnapshot{
+		TableID: "t1", GameType: game.GameTypeTexasHoldem, Table: []byte(`{"id":"t1"}`), Engine: []byte(`{"pot":0}`),
+	}))
+	require.NoError(t, store.SaveSnapshot(game.TableSnapshot{
+		TableID: "t1", GameType: game.GameTypeTexasHoldem, Table: []byte(`{"id":"t1"}`), Engine: []byte(`{"pot":30}`),
+		Escrow: map[string]int64{"7": 500}, SavedAt: saved,
+	}))
+	require.NoError(t, store.SaveSnapshot(game.TableSnapshot{TableID: "t2", GameType: game.GameTypeOmaha, Table: []byte(`{"id":"t2"}`), Engine: []byte(`{}`)}))
+
+	snapshots, err := store.LoadSnapshots()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	byTable := map[string]game.TableSnapshot{}
+	for _, snapshot := range snapshots {
+		byTable[snapshot.TableID] = snapshot
+	}
+	assert.JSONEq(t, `{"pot":30}`, string(byTable["t1"].Engine), "the latest snapshot replaces the last")
+	assert.Equal(t, map[string]int64{"7": 500}, byTable["t1"].Escrow)
+	assert.True(t, saved.Equal(byTable["t1"].SavedAt))
+	assert.Equal(t, game.GameTypeOmaha, byTable["t2"].GameType)
+
+	require.NoError(t, store.DeleteSnapshot("t1"))
+	snapshots, err = store.LoadSnapshots()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "t2", snapshots[0].TableID)
+}
@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"caslette-server/game"
+	"caslette-server/geo"
 	"caslette-server/models"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -18,6 +21,7 @@ type SecureTableHandler struct {
 	db           *gorm.DB
 	tableManager *game.ActorTableManager
 	validator    *SecurityValidator
+	geo          *geo.Policy
 }
 
 // SecureTableCreateRequest with additional validation
@@ -29,6 +33,9 @@ type SecureTableCreateRequest struct {
 	BuyIn      int64  `json:"buy_in" binding:"required,min=1"`
 	IsPrivate  bool   `json:"is_private"`
 	Password   string `json:"password"`
+
+	// Most observers at once; zero for no limit
+	MaxObservers int `json:"max_observers" binding:"min=0,max=1000"`
 }
 
 // SecureTableResponse with sanitized data
@@ -56,6 +63,11 @@ func NewSecureTableHandler(db *gorm.DB, tableManager *game.ActorTableManager) *S
 	}
 }
 
+// SetGeoPolicy sets the jurisdiction rules real-money joins are checked against
+func (h *SecureTableHandler) SetGeoPolicy(policy *geo.Policy) {
+	h.geo = policy
+}
+
 // CreateTable handles POST /api/tables with security validation
 func (h *SecureTableHandler) CreateTable(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
@@ -202,9 +214,12 @@ func (h *SecureTableHandler) CreateTable(c *gin.Context) {
 		}(),
 		Settings: game.TableSettings{
 			BuyIn:            int(req.BuyIn),
+			MinPlayers:       req.MinPlayers,
+			MaxPlayers:       req.MaxPlayers,
 			Private:          req.IsPrivate,
 			Password:         tablePassword,
 			ObserversAllowed: true, // Default setting
+			MaxObservers:     req.MaxObservers,
 		},
 		Description: fmt.Sprintf("Table created by user %d", userID.(uint)),
 	}
@@ -272,8 +287,8 @@ func (h *SecureTableHandler) GetTable(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
 
 	// Validate table ID
-	tableID, err := h.validator.ValidateIDParam(c, "id")
-	if err != nil {
+	tableIDStr := c.Param("id")
+	if err := game.NewTableValidator().ValidateTableID(tableIDStr); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success":    false,
 			"error":      "Invalid table ID",
@@ -282,8 +297,6 @@ func (h *SecureTableHandler) GetTable(c *gin.Context) {
 		return
 	}
 
-	tableIDStr := strconv.Itoa(int(tableID))
-
 	// Get table from actor manager
 	table, err := h.tableManager.GetTable(tableIDStr)
 	if err != nil {
@@ -330,6 +343,128 @@ func (h *SecureTableHandler) GetTable(c *gin.Context) {
 	})
 }
 
+// GetLobbyStats handles GET /api/v1/lobby/stats: the action at each stake
+// level, optionally for one currency
+func (h *SecureTableHandler) GetLobbyStats(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	currency := game.TableCurrency(c.Query("currency"))
+	if currency != "" && currency != game.CurrencyDiamonds && currency != game.CurrencyPlayMoney {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid currency",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	stakes := make([]game.StakeLevelStats, 0)
+	for _, level := range h.tableManager.LobbyStats() {
+		if currency == "" || level.Currency == currency {
+			stakes = append(stakes, level)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"stakes":     stakes,
+		"request_id": requestID,
+	})
+}
+
+// maxTableSearchLength caps the lobby search term
+const maxTableSearchLength = 64
+
+// ListTables handles GET /api/v1/tables: one page of the lobby, filtered by
+// ?game_type, ?currency, ?practice, ?bots_allowed, ?name (a substring) and
+// ?tags (comma-separated, all required), searched by ?search over names and
+// tags, and sorted by ?sort (created, players or stakes) in
+// ?order (desc unless asc)
+func (h *SecureTableHandler) ListTables(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	badRequest := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      message,
+			"request_id": requestID,
+		})
+	}
+
+	page, err := h.validator.ValidatePositiveInt(c.DefaultQuery("page", "1"), "page")
+	if err != nil {
+		badRequest("page must be positive")
+		return
+	}
+	limit, err := h.validator.ValidatePositiveInt(c.DefaultQuery("limit", strconv.Itoa(game.DefaultTableListLimit)), "limit")
+	if err != nil || limit > game.MaxTableListLimit {
+		badRequest("limit must be between 1 and " + strconv.Itoa(game.MaxTableListLimit))
+		return
+	}
+	order := c.DefaultQuery("order", "desc")
+	if order != "asc" && order != "desc" {
+		badRequest("order must be asc or desc")
+		return
+	}
+	search := strings.TrimSpace(c.Query("search"))
+	if len(search) > maxTableSearchLength {
+		badRequest("search exceeds maximum length of " + strconv.Itoa(maxTableSearchLength) + " characters")
+		return
+	}
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"game_type", "currency", "name", "tags"} {
+		if raw := c.Query(key); raw != "" {
+			filters[key] = raw
+		}
+	}
+	for _, key := range []string{"practice", "bots_allowed"} {
+		if raw := c.Query(key); raw != "" {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				badRequest(key + " must be true or false")
+				return
+			}
+			filters[key] = value
+		}
+	}
+
+	listing, err := h.tableManager.ListTablesPage(game.TableListQuery{
+		Filters:    filters,
+		Search:     search,
+		Sort:       game.TableListSort(c.Query("sort")),
+		Descending: order == "desc",
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+
+	viewer := ""
+	if userID, ok := c.Get("user_id"); ok {
+		viewer = fmt.Sprintf("%d", userID)
+	}
+	tables := game.NewDataFilter().FilterTableList(listing.Tables, viewer)
+	if tables == nil {
+		tables = make([]map[string]interface{}, 0)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"tables": tables,
+			"pagination": PaginationInfo{
+				Page:       listing.Page,
+				Limit:      listing.Limit,
+				Total:      int64(listing.Total),
+				TotalPages: listing.TotalPages,
+			},
+		},
+		"request_id": requestID,
+	})
+}
+
 // JoinTable handles POST /api/tables/:id/join with authorization and validation
 func (h *SecureTableHandler) JoinTable(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
@@ -344,8 +479,8 @@ func (h *SecureTableHandler) JoinTable(c *gin.Context) {
 	}
 
 	// Validate table ID
-	tableID, err := h.validator.ValidateIDParam(c, "id")
-	if err != nil {
+	tableIDStr := c.Param("id")
+	if err := game.NewTableValidator().ValidateTableID(tableIDStr); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success":    false,
 			"error":      "Invalid table ID",
@@ -354,16 +489,16 @@ func (h *SecureTableHandler) JoinTable(c *gin.Context) {
 		return
 	}
 
-	tableIDStr := strconv.Itoa(int(tableID))
-
 	// Parse password if provided
 	var req struct {
 		Password string `json:"password"`
+		BuyIn    int    `json:"buy_in"`
 	}
 	c.ShouldBindJSON(&req)
 
 	var password string
 	if req.Password != "" {
+		var err error
 		password, err = h.validator.ValidateAndSanitizeString(req.Password, "name", 100)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -375,22 +510,6 @@ func (h *SecureTableHandler) JoinTable(c *gin.Context) {
 		}
 	}
 
-	// Check user's diamond balance
-	var user models.User
-	if err := h.db.Preload("Diamonds").First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success":    false,
-			"error":      "User not found",
-			"request_id": requestID,
-		})
-		return
-	}
-
-	// Get current diamond balance
-	var currentBalance int64
-	h.db.Model(&models.Diamond{}).Where("user_id = ?", userID).Order("created_at desc").Limit(1).Pluck("balance", &currentBalance)
-
-	// Get table to check buy-in requirement
 	table, err := h.tableManager.GetTable(tableIDStr)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -401,26 +520,69 @@ func (h *SecureTableHandler) JoinTable(c *gin.Context) {
 		return
 	}
 
-	if currentBalance < int64(table.Settings.BuyIn) {
+	// Diamond tables are closed to regions where real-money play is blocked
+	if h.geo != nil && table.UsesDiamondLedger() {
+		region := c.GetString("region")
+		if !h.geo.Allows(geo.ActionRealMoney, region) {
+			err := &geo.RestrictedError{Action: geo.ActionRealMoney, Region: region}
+			c.JSON(http.StatusUnavailableForLegalReasons, gin.H{
+				"success":    false,
+				"error":      err.Error(),
+				"region":     region,
+				"request_id": requestID,
+			})
+			return
+		}
+	}
+
+	// A player returning to the same stakes must bring back the stack they
+	// left with, so charge what the seat will actually hold
+	playerID := fmt.Sprintf("%d", userID.(uint))
+	chips, err := h.tableManager.ResolveBuyIn(playerID, tableIDStr, req.BuyIn)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success":    false,
-			"error":      "Insufficient diamond balance",
+			"error":      err.Error(),
 			"request_id": requestID,
 		})
 		return
 	}
 
-	// Create join request
 	username, _ := c.Get("username")
 	joinReq := game.TableJoinRequest{
 		TableID:  tableIDStr,
-		PlayerID: fmt.Sprintf("%d", userID.(uint)),
+		PlayerID: playerID,
 		Username: username.(string),
 		Mode:     game.JoinModePlayer, // Default to player mode
 		Password: password,
+		BuyIn:    chips,
+	}
+
+	// The table manager charges the buy-in as it seats the player and pays
+	// the stack back when they leave; checking the balance first explains a
+	// refusal
+	if table.UsesDiamondLedger() && chips > 0 {
+		balance, err := userDiamondBalance(h.db, userID.(uint))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error":      "Failed to join table",
+				"request_id": requestID,
+			})
+			return
+		}
+		if balance < int64(chips) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":         false,
+				"error":           "Insufficient diamond balance",
+				"current_balance": balance,
+				"required":        chips,
+				"request_id":      requestID,
+			})
+			return
+		}
 	}
 
-	// Join table through actor manager (thread-safe)
 	if err := h.tableManager.JoinTable(c.Request.Context(), &joinReq); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success":    false,
@@ -437,10 +599,104 @@ func (h *SecureTableHandler) JoinTable(c *gin.Context) {
 	})
 }
 
+// userDiamondBalance sums a user's diamond ledger entries
+func userDiamondBalance(tx *gorm.DB, userID uint) (int64, error) {
+	var balance int64
+	err := tx.Model(&models.Diamond{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&balance)
+	return balance, err
+}
+
+// PreviewBuyIn handles POST /api/tables/:id/join/preview: what joining with
+// the requested buy-in would cost and what would refuse the seat, without
+// charging anything
+func (h *SecureTableHandler) PreviewBuyIn(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	tableIDStr := c.Param("id")
+	if err := game.NewTableValidator().ValidateTableID(tableIDStr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid table ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+		BuyIn    int    `json:"buy_in"`
+	}
+	c.ShouldBindJSON(&req)
+	if req.BuyIn < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid buy-in",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	preview, err := h.BuyInPreview(userID.(uint), tableIDStr, req.BuyIn, req.Password, c.GetString("region"))
+	if err != nil {
+		if errors.Is(err, game.ErrTableNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error":      "Table not found",
+				"request_id": requestID,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to preview buy-in",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"preview":    preview,
+		"request_id": requestID,
+	})
+}
+
+// BuyInPreview works out a user's buy-in at a table: the table's own rules,
+// the user's diamond balance and, for diamond tables, the region they play from
+func (h *SecureTableHandler) BuyInPreview(userID uint, tableID string, buyIn int, password, region string) (*game.BuyInPreview, error) {
+	playerID := fmt.Sprintf("%d", userID)
+	preview, err := h.tableManager.PreviewBuyIn(playerID, tableID, buyIn, password)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := userDiamondBalance(h.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	preview.SetBalance(balance)
+
+	if h.geo != nil && preview.DiamondCost > 0 && !h.geo.Allows(geo.ActionRealMoney, region) {
+		restricted := &geo.RestrictedError{Action: geo.ActionRealMoney, Region: region}
+		preview.Block("REGION_RESTRICTED", restricted.Error())
+	}
+	return preview, nil
+}
+
 // SaveTableToDB saves table to database with transaction safety
 func (h *SecureTableHandler) SaveTableToDB(table *game.GameTable) error {
-	tx := h.db.Begin()
-
 	// Convert game table settings to JSON string
 	settingsJSON, _ := json.Marshal(table.Settings)
 
@@ -460,10 +716,7 @@ func (h *SecureTableHandler) SaveTableToDB(table *game.GameTable) error {
 		RoomID:      table.RoomID,
 	}
 
-	if err := tx.Create(gameTable).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	return tx.Commit().Error
+	return WithTransaction(context.Background(), h.db, func(tx *gorm.DB) error {
+		return tx.Create(gameTable).Error
+	})
 }
@@ -2,13 +2,19 @@ package handlers
 
 import (
 	"bytes"
+	"caslette-server/game"
+	"caslette-server/models"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func createMockTableHandler() *SecureTableHandler {
@@ -379,3 +385,212 @@ func TestSecureTableHandler_CreateTable_NegativeBuyIn(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func newJoinTestHandler(t *testing.T, balances map[uint]int64) (*SecureTableHandler, *game.GameTable) {
+	db := newSQLiteDB(t, &models.User{}, &models.Diamond{})
+	for userID, balance := range balances {
+		require.NoError(t, db.Create(&models.User{ID: userID, Username: fmt.Sprintf("player%d", userID), Email: fmt.Sprintf("p%d@example.com", userID), Password: "x"}).Error)
+		require.NoError(t, db.Create(&models.Diamond{UserID: userID, Amount: balance, Balance: balance, Type: "credit", Metadata: "{}"}).Error)
+	}
+
+	manager := game.NewActorTableManager(nil)
+	manager.SetDiamondLedger(NewDiamondLedger(db))
+	table, err := manager.CreateTable(context.Background(), &game.TableCreateRequest{
+		Name: "Diamonds", GameType: game.GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: game.DefaultTableSettings(),
+	})
+	require.NoError(t, err)
+	return NewSecureTableHandler(db, manager), table
+}
+
+func joinTableRequest(handler *SecureTableHandler, tableID string, userID uint, body map[string]interface{}) *httptest.ResponseRecorder {
+	c, w := newDisputeContext("POST", "/tables/"+tableID+"/join", body, userID)
+	c.Set("username", fmt.Sprintf("player%d", userID))
+	c.Params = []gin.Param{{Key: "id", Value: tableID}}
+	handler.JoinTable(c)
+	return w
+}
+
+func diamondBalance(t *testing.T, db *gorm.DB, userID uint) int64 {
+	var balance int64
+	require.NoError(t, db.Model(&models.Diamond{}).Where("user_id = ?", userID).Select("COALESCE(SUM(amount), 0)").Row().Scan(&balance))
+	return balance
+}
+
+func TestSecureTableHandler_JoinTable_DebitsBuyIn(t *testing.T) {
+	handler, table := newJoinTestHandler(t, map[uint]int64{1: 1500})
+
+	w := joinTableRequest(handler, table.ID, 1, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, table.IsPlayerAtTable("1"))
+	assert.Equal(t, int64(500), diamondBalance(t, handler.db, 1), "the buy-in is debited")
+}
+
+func TestSecureTableHandler_JoinTable_DoesNotChargeRefusedSeat(t *testing.T) {
+	handler, table := newJoinTestHandler(t, map[uint]int64{1: 2500})
+	require.Equal(t, http.StatusOK, joinTableRequest(handler, table.ID, 1, nil).Code)
+
+	// Already seated: the seat is refused before anything is charged
+	w := joinTableRequest(handler, table.ID, 1, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, int64(1500), diamondBalance(t, handler.db, 1), "only the first buy-in is debited")
+
+	var debits int64
+	require.NoError(t, handler.db.Model(&models.Diamond{}).Where("user_id = ? AND type = ?", 1, "debit").Count(&debits).Error)
+	assert.Equal(t, int64(1), debits)
+}
+
+func TestSecureTableHandler_JoinTable_InsufficientBalance(t *testing.T) {
+	handler, table := newJoinTestHandler(t, map[uint]int64{2: 300})
+
+	w := joinTableRequest(handler, table.ID, 2, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Insufficient diamond balance", body["error"])
+	assert.Equal(t, float64(1000), body["required"])
+	assert.False(t, table.IsPlayerAtTable("2"))
+	assert.Equal(t, int64(300), diamondBalance(t, handler.db, 2))
+}
+
+func TestSecureTableHandler_JoinTable_DebitsRatholeMinimum(t *testing.T) {
+	handler, table := newJoinTestHandler(t, map[uint]int64{1: 5000})
+	require.Equal(t, http.StatusOK, joinTableRequest(handler, table.ID, 1, map[string]interface{}{"buy_in": 1800}).Code)
+	assert.Equal(t, int64(3200), diamondBalance(t, handler.db, 1))
+	require.NoError(t, handler.tableManager.LeaveTable(context.Background(), &game.TableLeaveRequest{TableID: table.ID, PlayerID: "1"}))
+	assert.Equal(t, int64(5000), diamondBalance(t, handler.db, 1), "leaving pays the stack back")
+
+	// Coming back with the table minimum is refused before anything is charged
+	w := joinTableRequest(handler, table.ID, 1, map[string]interface{}{"buy_in": 1000})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, int64(5000), diamondBalance(t, handler.db, 1))
+
+	// Without an amount the player is charged the stack they left with
+	w = joinTableRequest(handler, table.ID, 1, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int64(3200), diamondBalance(t, handler.db, 1))
+	available, err := handler.tableManager.GetTable(table.ID)
+	require.NoError(t, err)
+	for _, slot := range available.PlayerSlots {
+		if slot.PlayerID == "1" {
+			assert.Equal(t, 1800, slot.Chips)
+		}
+	}
+}
+
+func TestSecureTableHandler_GetLobbyStats_FiltersByCurrency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := game.NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	handler := &SecureTableHandler{validator: NewSecurityValidator(), tableManager: manager}
+
+	get := func(target string) (*httptest.ResponseRecorder, []game.StakeLevelStats) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", target, nil)
+		handler.GetLobbyStats(c)
+		var response struct {
+			Stakes []game.StakeLevelStats `json:"stakes"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response.Stakes
+	}
+
+	w, stakes := get("/lobby/stats")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, stakes, "the heads-up stake levels are always listed")
+
+	_, stakes = get("/lobby/stats?currency=play_money")
+	assert.Empty(t, stakes)
+
+	w, _ = get("/lobby/stats?currency=gold")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func previewBuyInRequest(handler *SecureTableHandler, tableID string, userID uint, body map[string]interface{}) (*httptest.ResponseRecorder, game.BuyInPreview) {
+	c, w := newDisputeContext("POST", "/tables/"+tableID+"/join/preview", body, userID)
+	c.Params = []gin.Param{{Key: "id", Value: tableID}}
+	handler.PreviewBuyIn(c)
+	var response struct {
+		Preview game.BuyInPreview `json:"preview"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response.Preview
+}
+
+func TestSecureTableHandler_PreviewBuyIn_ShowsBalanceWithoutCharging(t *testing.T) {
+	handler, table := newJoinTestHandler(t, map[uint]int64{1: 1500, 2: 300})
+
+	w, preview := previewBuyInRequest(handler, table.ID, 1, map[string]interface{}{"buy_in": 1200})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, preview.CanJoin)
+	assert.Equal(t, int64(1200), preview.Total)
+	assert.Equal(t, int64(1500), preview.Balance)
+	assert.Equal(t, int64(300), preview.BalanceAfter)
+	assert.Equal(t, int64(1500), diamondBalance(t, handler.db, 1), "nothing is charged")
+	assert.False(t, table.IsPlayerAtTable("1"))
+
+	_, preview = previewBuyInRequest(handler, table.ID, 2, nil)
+	assert.False(t, preview.CanJoin)
+	require.Len(t, preview.Blockers, 1)
+	assert.Equal(t, "INSUFFICIENT_BALANCE", preview.Blockers[0].Code)
+
+	w, _ = previewBuyInRequest(handler, "missing-table", 1, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSecureTableHandler_ListTables_PagesAndHidesPrivateStakes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := game.NewActorTableManager(nil)
+	t.Cleanup(manager.Stop)
+	handler := &SecureTableHandler{validator: NewSecurityValidator(), tableManager: manager}
+	for _, create := range []struct {
+		name     string
+		settings game.TableSettings
+	}{
+		{"Casual", game.QuickGameSettings()},
+		{"Regulars", game.DefaultTableSettings()},
+		{"Backroom", game.PrivateTableSettings("secret")},
+	} {
+		_, err := manager.CreateTable(context.Background(), &game.TableCreateRequest{
+			Name: create.name, GameType: game.GameTypeTexasHoldem, CreatedBy: "creator", Username: "creator", Settings: create.settings,
+		})
+		require.NoError(t, err)
+	}
+
+	list := func(target string) (int, []map[string]interface{}, PaginationInfo) {
+		c, w := newDisputeContext("GET", target, nil, uint(7))
+		handler.ListTables(c)
+		var response struct {
+			Data struct {
+				Tables     []map[string]interface{} `json:"tables"`
+				Pagination PaginationInfo           `json:"pagination"`
+			} `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data.Tables, response.Data.Pagination
+	}
+
+	code, tables, pagination := list("/tables?sort=stakes&order=asc&limit=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, tables, 2)
+	assert.Equal(t, "Casual", tables[0]["name"])
+	assert.Equal(t, int64(3), pagination.Total)
+	assert.Equal(t, 2, pagination.TotalPages)
+
+	_, tables, _ = list("/tables?search=back")
+	require.Len(t, tables, 1)
+	assert.Equal(t, true, tables[0]["requires_password"])
+	assert.NotContains(t, tables[0], "big_blind", "private stakes stay hidden from the lobby")
+	assert.NotContains(t, tables[0], "settings")
+
+	_, tables, _ = list("/tables?name=REGUL")
+	require.Len(t, tables, 1)
+	assert.Equal(t, "Regulars", tables[0]["name"])
+	_, tables, _ = list("/tables?tags=turbo,deep")
+	assert.Empty(t, tables)
+
+	for _, target := range []string{"/tables?limit=500", "/tables?sort=name", "/tables?order=up", "/tables?practice=maybe"} {
+		code, _, _ = list(target)
+		assert.Equal(t, http.StatusBadRequest, code, target)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TxError aborts a transaction with the response the handler should send.
+// Returning one from a transaction function rolls the transaction back.
+type TxError struct {
+	Status  int
+	Message string
+	Details gin.H // Extra response fields, e.g. the current balance
+}
+
+func (e *TxError) Error() string {
+	return e.Message
+}
+
+// txAbort creates a TxError with the given status and client-facing message
+func txAbort(status int, message string) *TxError {
+	return &TxError{Status: status, Message: message}
+}
+
+// WithTransaction runs fn in a single database transaction bound to ctx.
+// The transaction commits when fn returns nil and rolls back when fn returns
+// an error or panics, so multi-step flows never leave partial writes behind.
+func WithTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) (err error) {
+	if db == nil {
+		return errors.New("database not configured")
+	}
+
+	tx := db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			log.Printf("Transaction rolled back after panic: %v", r)
+			err = fmt.Errorf("transaction panicked: %v", r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// txFailure maps a transaction error to the response to send. TxErrors keep
+// their status and message; anything else is reported as a server error with
+// the fallback message so database details never reach the client.
+func txFailure(err error, fallback string) *TxError {
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		return txErr
+	}
+	return txAbort(http.StatusInternalServerError, fallback)
+}
+
+// respondTxError writes a failed transaction using the standard error envelope
+func respondTxError(c *gin.Context, err error, requestID interface{}, fallback string) {
+	failure := txFailure(err, fallback)
+	response := gin.H{
+		"success":    false,
+		"error":      failure.Message,
+		"request_id": requestID,
+	}
+	for key, value := range failure.Details {
+		response[key] = value
+	}
+	c.JSON(failure.Status, response)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteDB opens a private in-memory database with the given models migrated
+func newSQLiteDB(t *testing.T, models ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(models...))
+	return db
+}
+
+func TestWithTransaction_RequiresDatabase(t *testing.T) {
+	called := false
+	err := WithTransaction(context.Background(), nil, func(tx *gorm.DB) error {
+		called = true
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestTxFailure_KeepsTxErrors(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", txAbort(http.StatusConflict, "Dispute already resolved"))
+
+	failure := txFailure(err, "Failed to update dispute")
+	assert.Equal(t, http.StatusConflict, failure.Status)
+	assert.Equal(t, "Dispute already resolved", failure.Message)
+}
+
+func TestTxFailure_HidesDatabaseErrors(t *testing.T) {
+	failure := txFailure(errors.New("Error 1213: Deadlock found"), "Failed to update dispute")
+	assert.Equal(t, http.StatusInternalServerError, failure.Status)
+	assert.Equal(t, "Failed to update dispute", failure.Message)
+}
+
+func TestRespondTxError_IncludesDetails(t *testing.T) {
+	c, w := newDisputeContext("POST", "/play-money/refill", nil, uint(7))
+	c.Set("request_id", "req-1")
+
+	err := &TxError{
+		Status:  http.StatusTooManyRequests,
+		Message: "Refill is on cooldown",
+		Details: gin.H{"next_refill_at": "2026-01-01T00:00:00Z"},
+	}
+	respondTxError(c, err, "req-1", "Failed to refill play-money balance")
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, false, body["success"])
+	assert.Equal(t, "Refill is on cooldown", body["error"])
+	assert.Equal(t, "req-1", body["request_id"])
+	assert.Equal(t, "2026-01-01T00:00:00Z", body["next_refill_at"])
+}
+
+func TestWithTransaction_RollsBackOnError(t *testing.T) {
+	db := newSQLiteDB(t, &models.User{}, &models.Diamond{})
+	require.NoError(t, db.Create(&models.User{ID: 1, Username: "player1", Email: "p1@example.com", Password: "x"}).Error)
+
+	err := WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Diamond{UserID: 1, Amount: 100, Balance: 100, Type: "credit", Metadata: "{}"}).Error; err != nil {
+			return err
+		}
+		return txAbort(http.StatusConflict, "second step failed")
+	})
+	assert.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Diamond{}).Count(&count).Error)
+	assert.Zero(t, count, "the first write is rolled back with the failed step")
+}
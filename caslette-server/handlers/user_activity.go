@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Activity timeline limits
+const (
+	DefaultActivityLimit = 100
+	MaxActivityLimit     = 500
+)
+
+// Activity entry types
+const (
+	ActivityLogin        = "login"
+	ActivityTableSession = "table_session"
+	ActivityTransaction  = "transaction"
+	ActivityModeration   = "moderation"
+)
+
+// activityTypes lists every entry type the timeline can return
+var activityTypes = []string{ActivityLogin, ActivityTableSession, ActivityTransaction, ActivityModeration}
+
+// ActivityEntry is one event on a user's activity timeline
+type ActivityEntry struct {
+	Type       string    `json:"type"`
+	At         time.Time `json:"at"`
+	Summary    string    `json:"summary"`
+	Details    gin.H     `json:"details,omitempty"`
+	sourceID   uint      // Row ID, used to order entries sharing a timestamp
+	sourceName string
+}
+
+// activityQuery is the parsed filter for a timeline request
+type activityQuery struct {
+	types map[string]bool
+	since *time.Time
+	until *time.Time
+	limit int
+}
+
+// parseActivityQuery reads the types, since, until and limit query parameters
+func parseActivityQuery(c *gin.Context) (*activityQuery, error) {
+	query := &activityQuery{types: make(map[string]bool), limit: DefaultActivityLimit}
+
+	if raw := c.Query("types"); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			if !isActivityType(name) {
+				return nil, fmt.Errorf("unknown activity type: %s", name)
+			}
+			query.types[name] = true
+		}
+	} else {
+		for _, name := range activityTypes {
+			query.types[name] = true
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"since": &query.since, "until": &query.until} {
+		if raw := c.Query(param); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = &parsed
+		}
+	}
+	if query.since != nil && query.until != nil && !query.since.Before(*query.until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxActivityLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxActivityLimit)
+		}
+		query.limit = limit
+	}
+
+	return query, nil
+}
+
+// isActivityType reports whether name is a known entry type
+func isActivityType(name string) bool {
+	for _, known := range activityTypes {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// scope restricts a source query to the requested window, newest first,
+// fetching one extra row so truncation can be detected
+func (q *activityQuery) scope(db *gorm.DB, column string) *gorm.DB {
+	if q.since != nil {
+		db = db.Where(column+" >= ?", *q.since)
+	}
+	if q.until != nil {
+		db = db.Where(column+" < ?", *q.until)
+	}
+	return db.Order(column + " desc").Limit(q.limit + 1)
+}
+
+// GetUserActivity handles GET /api/v1/users/:id/activity with admin
+// authorization. It merges logins, table sessions, diamond transactions and
+// moderation actions into one chronological timeline so support can answer
+// "what happened to my diamonds" without querying each table by hand.
+func (h *SecureUserHandler) GetUserActivity(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	userID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "invalid user ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	currentUserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	query, err := parseActivityQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(currentUserID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error":      "User not found",
+				"request_id": requestID,
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error":      "Database error",
+				"request_id": requestID,
+			})
+		}
+		return
+	}
+
+	entries, err := h.collectActivity(userID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to load activity",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	timeline, truncated := buildActivityTimeline(entries, query.limit)
+	response := gin.H{
+		"user_id":   userID,
+		"username":  user.Username,
+		"entries":   timeline,
+		"truncated": truncated,
+	}
+	// Older entries are fetched by passing the oldest timestamp back as until
+	if truncated && len(timeline) > 0 {
+		response["next_until"] = timeline[0].At.Format(time.RFC3339Nano)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       response,
+		"request_id": requestID,
+	})
+}
+
+// collectActivity loads the requested entry types for a user
+func (h *SecureUserHandler) collectActivity(userID uint, query *activityQuery) ([]ActivityEntry, error) {
+	entries := make([]ActivityEntry, 0)
+
+	if query.types[ActivityLogin] {
+		var logins []models.LoginEvent
+		if err := query.scope(h.db.Where("user_id = ?", userID), "created_at").Find(&logins).Error; err != nil {
+			return nil, err
+		}
+		for _, login := range logins {
+			entries = append(entries, loginActivity(login))
+		}
+	}
+
+	if query.types[ActivityTableSession] {
+		var seats []models.TablePlayer
+		if err := query.scope(h.db.Preload("Table").Where("user_id = ?", userID), "joined_at").Find(&seats).Error; err != nil {
+			return nil, err
+		}
+		for _, seat := range seats {
+			entries = append(entries, seatActivity(seat))
+		}
+
+		var watches []models.TableObserver
+		if err := query.scope(h.db.Preload("Table").Where("user_id = ?", userID), "joined_at").Find(&watches).Error; err != nil {
+			return nil, err
+		}
+		for _, watch := range watches {
+			entries = append(entries, observerActivity(watch))
+		}
+	}
+
+	if query.types[ActivityTransaction] {
+		var transactions []models.Diamond
+		if err := query.scope(h.db.Where("user_id = ?", userID), "created_at").Find(&transactions).Error; err != nil {
+			return nil, err
+		}
+		for _, transaction := range transactions {
+			entries = append(entries, transactionActivity(transaction))
+		}
+	}
+
+	if query.types[ActivityModeration] {
+		var tags []models.UserTag
+		if err := query.scope(h.db.Where("user_id = ?", userID), "created_at").Find(&tags).Error; err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			entries = append(entries, tagActivity(tag))
+		}
+
+		var disputes []models.HandDispute
+		if err := query.scope(h.db.Where("user_id = ?", userID), "created_at").Find(&disputes).Error; err != nil {
+			return nil, err
+		}
+		for _, dispute := range disputes {
+			entries = append(entries, disputeActivity(dispute)...)
+		}
+	}
+
+	return entries, nil
+}
+
+// buildActivityTimeline keeps the most recent limit entries and returns them
+// oldest first, reporting whether older entries were left out
+func buildActivityTimeline(entries []ActivityEntry, limit int) ([]ActivityEntry, bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].At.Equal(entries[j].At) {
+			return entries[i].At.After(entries[j].At)
+		}
+		if entries[i].sourceName != entries[j].sourceName {
+			return entries[i].sourceName < entries[j].sourceName
+		}
+		return entries[i].sourceID > entries[j].sourceID
+	})
+
+	truncated := len(entries) > limit
+	if truncated {
+		entries = entries[:limit]
+	}
+
+	timeline := make([]ActivityEntry, len(entries))
+	for i, entry := range entries {
+		timeline[len(entries)-1-i] = entry
+	}
+	return timeline, truncated
+}
+
+func loginActivity(login models.LoginEvent) ActivityEntry {
+	summary := "Logged in"
+	if !login.Success {
+		summary = "Failed login: " + strings.ReplaceAll(login.Reason, "_", " ")
+	}
+	return ActivityEntry{
+		Type:    ActivityLogin,
+		At:      login.CreatedAt,
+		Summary: summary,
+		Details: gin.H{
+			"success":    login.Success,
+			"ip_address": login.IPAddress,
+			"region":     login.Region,
+			"user_agent": login.UserAgent,
+		},
+		sourceID:   login.ID,
+		sourceName: "login_events",
+	}
+}
+
+func seatActivity(seat models.TablePlayer) ActivityEntry {
+	details := gin.H{
+		"table_id": seat.TableID,
+		"position": seat.Position,
+		"role":     "player",
+	}
+	if seat.LeftAt != nil {
+		details["left_at"] = seat.LeftAt
+		details["duration_seconds"] = int64(seat.LeftAt.Sub(seat.JoinedAt).Seconds())
+	}
+	return ActivityEntry{
+		Type:       ActivityTableSession,
+		At:         seat.JoinedAt,
+		Summary:    fmt.Sprintf("Sat at %s in seat %d", tableLabel(seat.Table, seat.TableID), seat.Position),
+		Details:    details,
+		sourceID:   seat.ID,
+		sourceName: "table_players",
+	}
+}
+
+func observerActivity(watch models.TableObserver) ActivityEntry {
+	details := gin.H{
+		"table_id": watch.TableID,
+		"role":     "observer",
+	}
+	if watch.LeftAt != nil {
+		details["left_at"] = watch.LeftAt
+		details["duration_seconds"] = int64(watch.LeftAt.Sub(watch.JoinedAt).Seconds())
+	}
+	return ActivityEntry{
+		Type:       ActivityTableSession,
+		At:         watch.JoinedAt,
+		Summary:    "Watched " + tableLabel(watch.Table, watch.TableID),
+		Details:    details,
+		sourceID:   watch.ID,
+		sourceName: "table_observers",
+	}
+}
+
+func transactionActivity(transaction models.Diamond) ActivityEntry {
+	summary := fmt.Sprintf("%+d diamonds (%s)", transaction.Amount, transaction.Type)
+	if transaction.Description != "" {
+		summary += ": " + transaction.Description
+	}
+	return ActivityEntry{
+		Type:    ActivityTransaction,
+		At:      transaction.CreatedAt,
+		Summary: summary,
+		Details: gin.H{
+			"transaction_id": transaction.TransactionID,
+			"amount":         transaction.Amount,
+			"balance":        transaction.Balance,
+			"type":           transaction.Type,
+		},
+		sourceID:   transaction.ID,
+		sourceName: "diamonds",
+	}
+}
+
+func tagActivity(tag models.UserTag) ActivityEntry {
+	return ActivityEntry{
+		Type:    ActivityModeration,
+		At:      tag.CreatedAt,
+		Summary: fmt.Sprintf("Tagged %q by user %d", tag.Tag, tag.CreatedBy),
+		Details: gin.H{
+			"action":     "tag_applied",
+			"tag":        tag.Tag,
+			"created_by": tag.CreatedBy,
+		},
+		sourceID:   tag.ID,
+		sourceName: "user_tags",
+	}
+}
+
+// disputeActivity reports a dispute being filed and, once closed, its outcome
+func disputeActivity(dispute models.HandDispute) []ActivityEntry {
+	entries := []ActivityEntry{{
+		Type:    ActivityModeration,
+		At:      dispute.CreatedAt,
+		Summary: fmt.Sprintf("Filed dispute #%d for hand %s", dispute.ID, dispute.HandID),
+		Details: gin.H{
+			"action":     "dispute_filed",
+			"dispute_id": dispute.ID,
+			"table_id":   dispute.TableID,
+			"hand_id":    dispute.HandID,
+		},
+		sourceID:   dispute.ID,
+		sourceName: "hand_disputes",
+	}}
+
+	if dispute.ResolvedAt != nil {
+		details := gin.H{
+			"action":     "dispute_" + dispute.Status,
+			"dispute_id": dispute.ID,
+			"resolution": dispute.Resolution,
+		}
+		if dispute.ReviewedBy != nil {
+			details["reviewed_by"] = *dispute.ReviewedBy
+		}
+		summary := fmt.Sprintf("Dispute #%d %s", dispute.ID, dispute.Status)
+		if dispute.AdjustmentAmount != 0 {
+			details["adjustment_amount"] = dispute.AdjustmentAmount
+			details["adjustment_transaction_id"] = dispute.AdjustmentTransactionID
+			summary += fmt.Sprintf(" with a %+d diamond adjustment", dispute.AdjustmentAmount)
+		}
+		entries = append(entries, ActivityEntry{
+			Type:       ActivityModeration,
+			At:         *dispute.ResolvedAt,
+			Summary:    summary,
+			Details:    details,
+			sourceID:   dispute.ID,
+			sourceName: "hand_disputes",
+		})
+	}
+	return entries
+}
+
+// tableLabel names a table by its stored name, falling back to its ID
+func tableLabel(table models.GameTable, tableID string) string {
+	if table.Name != "" {
+		return fmt.Sprintf("%q", table.Name)
+	}
+	return "table " + tableID
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"caslette-server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newActivityContext(path string, userID interface{}) (*gin.Context, func() map[string]interface{}, func() int) {
+	c, w := newDisputeContext(http.MethodGet, path, nil, userID)
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+	body := func() map[string]interface{} {
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+	return c, body, func() int { return w.Code }
+}
+
+func TestGetUserActivity_RequiresAuth(t *testing.T) {
+	handler := &SecureUserHandler{validator: NewSecurityValidator()}
+	c, body, code := newActivityContext("/api/v1/users/42/activity", nil)
+
+	handler.GetUserActivity(c)
+
+	assert.Equal(t, http.StatusUnauthorized, code())
+	assert.Equal(t, false, body()["success"])
+}
+
+func TestGetUserActivity_RejectsInvalidQuery(t *testing.T) {
+	handler := &SecureUserHandler{validator: NewSecurityValidator()}
+	cases := map[string]string{
+		"unknown type":    "?types=login,purchases",
+		"bad since":       "?since=yesterday",
+		"inverted window": "?since=2026-02-01T00:00:00Z&until=2026-01-01T00:00:00Z",
+		"limit too high":  "?limit=501",
+		"limit zero":      "?limit=0",
+	}
+
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, body, code := newActivityContext("/api/v1/users/42/activity"+query, uint(1))
+
+			handler.GetUserActivity(c)
+
+			assert.Equal(t, http.StatusBadRequest, code())
+			assert.Equal(t, "test-request", body()["request_id"])
+		})
+	}
+}
+
+func TestParseActivityQuery_Defaults(t *testing.T) {
+	c, _, _ := newActivityContext("/api/v1/users/42/activity", uint(1))
+
+	query, err := parseActivityQuery(c)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultActivityLimit, query.limit)
+	assert.Len(t, query.types, len(activityTypes))
+	assert.Nil(t, query.since)
+	assert.Nil(t, query.until)
+}
+
+func TestBuildActivityTimeline_ChronologicalAndTruncated(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []ActivityEntry{
+		{Type: ActivityTransaction, At: base.Add(3 * time.Minute), Summary: "third"},
+		{Type: ActivityLogin, At: base, Summary: "first"},
+		{Type: ActivityModeration, At: base.Add(4 * time.Minute), Summary: "fourth"},
+		{Type: ActivityTableSession, At: base.Add(time.Minute), Summary: "second"},
+	}
+
+	timeline, truncated := buildActivityTimeline(entries, 10)
+	assert.False(t, truncated)
+	require.Len(t, timeline, 4)
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, summaries(timeline))
+
+	// Truncation drops the oldest entries, keeping the most recent ones
+	timeline, truncated = buildActivityTimeline(entries, 2)
+	assert.True(t, truncated)
+	assert.Equal(t, []string{"third", "fourth"}, summaries(timeline))
+}
+
+func TestDisputeActivity_IncludesResolution(t *testing.T) {
+	filed := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	resolved := filed.Add(time.Hour)
+	reviewer := uint(9)
+	dispute := models.HandDispute{
+		ID: 3, UserID: 42, HandID: "hand_1", Status: "resolved",
+		ReviewedBy: &reviewer, ResolvedAt: &resolved, AdjustmentAmount: 250,
+	}
+	dispute.CreatedAt = filed
+
+	entries := disputeActivity(dispute)
+	require.Len(t, entries, 2)
+	assert.Equal(t, filed, entries[0].At)
+	assert.Equal(t, "dispute_filed", entries[0].Details["action"])
+	assert.Equal(t, resolved, entries[1].At)
+	assert.Equal(t, "dispute_resolved", entries[1].Details["action"])
+	assert.Equal(t, reviewer, entries[1].Details["reviewed_by"])
+	assert.Equal(t, int64(250), entries[1].Details["adjustment_amount"])
+}
+
+func summaries(entries []ActivityEntry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Summary
+	}
+	return result
+}
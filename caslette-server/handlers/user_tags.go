@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"caslette-server/models"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxTagsPerRequest caps the number of tags applied in a single request
+const MaxTagsPerRequest = 20
+
+// validTagPattern restricts tags to short lowercase slugs such as "vip" or "suspected_bot"
+var validTagPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// UserTagsRequest applies one or more tags to a user
+type UserTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+// normalizeUserTag lowercases and validates a tag
+func normalizeUserTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tag))
+	if !validTagPattern.MatchString(normalized) {
+		return "", errors.New("tags must be 1-32 characters of letters, digits or underscores")
+	}
+	return normalized, nil
+}
+
+// UserIDsWithTag returns the IDs of every user carrying the given tag so that
+// announcements, feature flags and leaderboards can target a segment
+func UserIDsWithTag(db *gorm.DB, tag string) ([]uint, error) {
+	normalized, err := normalizeUserTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []uint
+	err = db.Model(&models.UserTag{}).Where("tag = ?", normalized).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// UserHasTag reports whether a user belongs to a tagged segment
+func UserHasTag(db *gorm.DB, userID uint, tag string) (bool, error) {
+	normalized, err := normalizeUserTag(tag)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = db.Model(&models.UserTag{}).Where("user_id = ? AND tag = ?", userID, normalized).Count(&count).Error
+	return count > 0, err
+}
+
+// AddUserTags handles POST /api/v1/users/:id/tags with admin authorization
+func (h *SecureUserHandler) AddUserTags(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	userID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "invalid user ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	currentUserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var req UserTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Invalid request data",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if len(req.Tags) > MaxTagsPerRequest {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "Too many tags in one request",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	seen := make(map[string]bool, len(req.Tags))
+	tags := make([]models.UserTag, 0, len(req.Tags))
+	for _, raw := range req.Tags {
+		tag, err := normalizeUserTag(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      err.Error(),
+				"request_id": requestID,
+			})
+			return
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, models.UserTag{UserID: userID, Tag: tag, CreatedBy: currentUserID.(uint)})
+	}
+
+	if !h.hasAdminPermission(currentUserID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error":      "User not found",
+				"request_id": requestID,
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error":      "Database error",
+				"request_id": requestID,
+			})
+		}
+		return
+	}
+
+	// Re-applying an existing tag is a no-op
+	if err := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to apply tags",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "tags applied successfully",
+		"data":       gin.H{"tags": h.userTagNames(userID)},
+		"request_id": requestID,
+	})
+}
+
+// GetUserTags handles GET /api/v1/users/:id/tags with admin authorization
+func (h *SecureUserHandler) GetUserTags(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	userID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "invalid user ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	currentUserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	// Tags such as "suspected_bot" are internal, so only admins may read them
+	if !h.hasAdminPermission(currentUserID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	var tags []models.UserTag
+	if err := h.db.Where("user_id = ?", userID).Order("tag").Find(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Database error",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"tags": tags},
+		"request_id": requestID,
+	})
+}
+
+// RemoveUserTag handles DELETE /api/v1/users/:id/tags/:tag with admin authorization
+func (h *SecureUserHandler) RemoveUserTag(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	userID, err := h.validator.ValidateIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "invalid user ID",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	tag, err := normalizeUserTag(c.Param("tag"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error":      "invalid tag",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	currentUserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(currentUserID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	result := h.db.Where("user_id = ? AND tag = ?", userID, tag).Delete(&models.UserTag{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Failed to remove tag",
+			"request_id": requestID,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error":      "Tag not found on user",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "tag removed successfully",
+		"tag":        tag,
+		"request_id": requestID,
+	})
+}
+
+// GetTags handles GET /api/v1/users/tags, listing every tag in use with its member count
+func (h *SecureUserHandler) GetTags(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	currentUserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error":      "Authentication required",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	if !h.hasAdminPermission(currentUserID.(uint)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error":      "insufficient permissions",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	type tagCount struct {
+		Tag   string `json:"tag"`
+		Users int64  `json:"users"`
+	}
+	var counts []tagCount
+	if err := h.db.Model(&models.UserTag{}).
+		Select("tag, COUNT(*) AS users").
+		Group("tag").
+		Order("tag").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error":      "Database error",
+			"request_id": requestID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       gin.H{"tags": counts},
+		"request_id": requestID,
+	})
+}
+
+// userTagNames lists a user's tags after a change
+func (h *SecureUserHandler) userTagNames(userID uint) []string {
+	var names []string
+	h.db.Model(&models.UserTag{}).Where("user_id = ?", userID).Order("tag").Pluck("tag", &names)
+	return names
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockTagUserHandler() *SecureUserHandler {
+	return &SecureUserHandler{
+		db:        nil, // No actual DB for unit tests
+		validator: NewSecurityValidator(),
+	}
+}
+
+func TestNormalizeUserTag(t *testing.T) {
+	tag, err := normalizeUserTag(" VIP ")
+	assert.NoError(t, err)
+	assert.Equal(t, "vip", tag)
+
+	tag, err = normalizeUserTag("suspected_bot")
+	assert.NoError(t, err)
+	assert.Equal(t, "suspected_bot", tag)
+
+	for _, invalid := range []string{"", "beta tester", "vip'; --", "<b>", "a_really_long_tag_name_exceeding_limits"} {
+		_, err := normalizeUserTag(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSecureUserHandler_AddUserTags_RequiresAuth(t *testing.T) {
+	handler := createMockTagUserHandler()
+	c, w := newDisputeContext("POST", "/users/1/tags", map[string]interface{}{"tags": []string{"vip"}}, nil)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	handler.AddUserTags(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSecureUserHandler_AddUserTags_InvalidTag(t *testing.T) {
+	handler := createMockTagUserHandler()
+	c, w := newDisputeContext("POST", "/users/1/tags", map[string]interface{}{"tags": []string{"vip", "'; DROP TABLE user_tags; --"}}, uint(1))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	handler.AddUserTags(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureUserHandler_AddUserTags_EmptyTags(t *testing.T) {
+	handler := createMockTagUserHandler()
+	c, w := newDisputeContext("POST", "/users/1/tags", map[string]interface{}{"tags": []string{}}, uint(1))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	handler.AddUserTags(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecureUserHandler_RemoveUserTag_InvalidTag(t *testing.T) {
+	handler := createMockTagUserHandler()
+	c, w := newDisputeContext("DELETE", "/users/1/tags/bad%20tag", nil, uint(1))
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "tag", Value: "bad tag"}}
+
+	handler.RemoveUserTag(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
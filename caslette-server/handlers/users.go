@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"caslette-server/middleware"
 	"caslette-server/models"
 	"net/http"
 
@@ -39,6 +40,7 @@ type SecureUserResponse struct {
 	CreatedAt   string                     `json:"created_at"`
 	Roles       []SecureRoleResponse       `json:"roles"`
 	Permissions []SecurePermissionResponse `json:"permissions"`
+	Tags        []string                   `json:"tags,omitempty"`
 	RequestID   string                     `json:"request_id"`
 }
 
@@ -137,11 +139,26 @@ func (h *SecureUserHandler) GetUsers(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
+	// Optional segment filter
+	query := h.db.Model(&models.User{})
+	if tagParam := c.Query("tag"); tagParam != "" {
+		tag, err := normalizeUserTag(tagParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error":      "Invalid tag parameter",
+				"request_id": requestID,
+			})
+			return
+		}
+		query = query.Where("id IN (?)", h.db.Model(&models.UserTag{}).Select("user_id").Where("tag = ?", tag))
+	}
+
 	var users []models.User
 	var total int64
 
 	// Get total count
-	if err := h.db.Model(&models.User{}).Count(&total).Error; err != nil {
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success":    false,
 			"error":      "Failed to get user count",
@@ -150,8 +167,8 @@ func (h *SecureUserHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	// Get users with roles and permissions
-	if err := h.db.Preload("Roles").Preload("Permissions").
+	// Get users with roles, permissions and tags
+	if err := query.Preload("Roles").Preload("Permissions").Preload("Tags").
 		Limit(limit).Offset(offset).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success":    false,
@@ -191,6 +208,11 @@ func (h *SecureUserHandler) GetUsers(c *gin.Context) {
 			}
 		}
 
+		tags := make([]string, len(user.Tags))
+		for j, tag := range user.Tags {
+			tags[j] = tag.Tag
+		}
+
 		secureUsers[i] = SecureUserResponse{
 			ID:          user.ID,
 			Username:    user.Username,
@@ -202,6 +224,7 @@ func (h *SecureUserHandler) GetUsers(c *gin.Context) {
 			CreatedAt:   user.CreatedAt.Format("2006-01-02T15:04:05Z"),
 			Roles:       secureRoles,
 			Permissions: securePermissions,
+			Tags:        tags,
 			RequestID:   requestID.(string),
 		}
 	}
@@ -460,17 +483,12 @@ func (h *SecureUserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	// Update user with transaction safety
-	tx := h.db.Begin()
-	if err := tx.Save(&user).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error":      "Failed to update user",
-			"request_id": requestID,
-		})
+	if err := WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		return tx.Save(&user).Error
+	}); err != nil {
+		respondTxError(c, err, requestID, "Failed to update user")
 		return
 	}
-	tx.Commit()
 
 	// Return secure response
 	response := SecureUserResponse{
@@ -555,17 +573,13 @@ func (h *SecureUserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	// Soft delete with transaction safety
-	tx := h.db.Begin()
-	if err := tx.Delete(&user).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error":      "Failed to delete user",
-			"request_id": requestID,
-		})
+	if err := WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		return tx.Delete(&user).Error
+	}); err != nil {
+		respondTxError(c, err, requestID, "Failed to delete user")
 		return
 	}
-	tx.Commit()
+	middleware.InvalidateUserPermissions(user.ID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
@@ -576,12 +590,8 @@ func (h *SecureUserHandler) DeleteUser(c *gin.Context) {
 
 // hasAdminPermission checks if user has admin permissions
 func (h *SecureUserHandler) hasAdminPermission(userID uint) bool {
-	var count int64
-	h.db.Table("user_roles").
-		Joins("JOIN roles ON user_roles.role_id = roles.id").
-		Where("user_roles.user_id = ? AND roles.name = ?", userID, "admin").
-		Count(&count)
-	return count > 0
+	isAdmin, err := middleware.HasRole(h.db, userID, "admin")
+	return err == nil && isAdmin
 }
 
 // AssignRoles handles POST /api/users/:id/roles with admin authorization
@@ -651,38 +661,30 @@ func (h *SecureUserHandler) AssignRoles(c *gin.Context) {
 		return
 	}
 
-	// Find the roles
-	var roles []models.Role
-	if err := h.db.Where("id IN ?", req.RoleIDs).Find(&roles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error":      "Failed to find roles",
-			"request_id": requestID,
-		})
-		return
-	}
+	// Replace the user's roles in one transaction so a failed assignment
+	// never leaves the user with none
+	err = WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		var roles []models.Role
+		if err := tx.Where("id IN ?", req.RoleIDs).Find(&roles).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to find roles")
+		}
 
-	// Clear existing roles and assign new ones
-	if err := h.db.Model(&user).Association("Roles").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error":      "Failed to clear existing roles",
-			"request_id": requestID,
-		})
-		return
-	}
+		if err := tx.Model(&user).Association("Roles").Clear(); err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to clear existing roles")
+		}
 
-	// Assign new roles
-	if len(roles) > 0 {
-		if err := h.db.Model(&user).Association("Roles").Append(roles); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success":    false,
-				"error":      "Failed to assign roles",
-				"request_id": requestID,
-			})
-			return
+		if len(roles) > 0 {
+			if err := tx.Model(&user).Association("Roles").Append(roles); err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to assign roles")
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to assign roles")
+		return
 	}
+	middleware.InvalidateUserPermissions(userID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
@@ -757,38 +759,30 @@ func (h *SecureUserHandler) AssignPermissions(c *gin.Context) {
 		return
 	}
 
-	// Find the permissions
-	var permissions []models.Permission
-	if err := h.db.Where("id IN ?", req.PermissionIDs).Find(&permissions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error":      "Failed to find permissions",
-			"request_id": requestID,
-		})
-		return
-	}
+	// Replace the user's permissions in one transaction so a failed assignment
+	// never leaves the user with none
+	err = WithTransaction(c.Request.Context(), h.db, func(tx *gorm.DB) error {
+		var permissions []models.Permission
+		if err := tx.Where("id IN ?", req.PermissionIDs).Find(&permissions).Error; err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to find permissions")
+		}
 
-	// Clear existing permissions and assign new ones
-	if err := h.db.Model(&user).Association("Permissions").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error":      "Failed to clear existing permissions",
-			"request_id": requestID,
-		})
-		return
-	}
+		if err := tx.Model(&user).Association("Permissions").Clear(); err != nil {
+			return txAbort(http.StatusInternalServerError, "Failed to clear existing permissions")
+		}
 
-	// Assign new permissions
-	if len(permissions) > 0 {
-		if err := h.db.Model(&user).Association("Permissions").Append(permissions); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success":    false,
-				"error":      "Failed to assign permissions",
-				"request_id": requestID,
-			})
-			return
+		if len(permissions) > 0 {
+			if err := tx.Model(&user).Association("Permissions").Append(permissions); err != nil {
+				return txAbort(http.StatusInternalServerError, "Failed to assign permissions")
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		respondTxError(c, err, requestID, "Failed to assign permissions")
+		return
 	}
+	middleware.InvalidateUserPermissions(userID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
@@ -958,6 +952,7 @@ func (h *SecureUserHandler) RemoveUserPermission(c *gin.Context) {
 		})
 		return
 	}
+	middleware.InvalidateUserPermissions(userID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":       true,
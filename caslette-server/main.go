@@ -5,174 +5,276 @@ import (
 	"caslette-server/config"
 	"caslette-server/database"
 	"caslette-server/game"
+	"caslette-server/geo"
 	"caslette-server/handlers"
 	"caslette-server/middleware"
 	"caslette-server/models"
 	"caslette-server/websocket_v2"
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Engines created from here on use the configured gameplay defaults
+	if err := game.SetGameDefaults(cfg.Game); err != nil {
+		log.Fatal("Invalid game defaults: ", err)
+	}
+
 	// Run database migrations
 	database.Migrate(cfg.DB)
 
 	// Initialize auth service
 	authService := auth.NewAuthService(cfg.JWTSecret)
 
+	// Detect client regions and refuse restricted activity from blocked jurisdictions
+	geoPolicy := loadGeoPolicy(cfg)
+
 	// Initialize WebSocket server
 	wsServer := websocket_v2.NewServer(authService)
+	if err := wsServer.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+	if err := wsServer.SetCompressionPolicy(cfg.WSCompression); err != nil {
+		log.Fatal("Invalid WebSocket compression settings:", err)
+	}
+	if err := wsServer.SetMessageSizePolicy(cfg.WSMessageSize); err != nil {
+		log.Fatal("Invalid WebSocket message size settings:", err)
+	}
+	if err := wsServer.SetSendQueuePolicy(cfg.WSSendQueue); err != nil {
+		log.Fatal("Invalid WebSocket send queue settings:", err)
+	}
+	if err := wsServer.SetHeartbeatPolicy(cfg.WSHeartbeat); err != nil {
+		log.Fatal("Invalid WebSocket heartbeat settings:", err)
+	}
+	if err := wsServer.SetShedPolicy(cfg.WSLoadShedding); err != nil {
+		log.Fatal("Invalid WebSocket load shedding settings:", err)
+	}
+	wsServer.SetResumeWindow(cfg.WSResumeWindow)
+	if err := wsServer.SetSessionPolicy(cfg.WSSessionPolicy); err != nil {
+		log.Fatal("Invalid WebSocket session policy:", err)
+	}
+	if cfg.WSBackplane == "redis" {
+		backplane, err := websocket_v2.NewRedisBackplane(cfg.WSRedis)
+		if err == nil {
+			err = wsServer.SetBackplane(backplane)
+		}
+		if err != nil {
+			log.Fatal("Failed to connect WebSocket backplane: ", err)
+		}
+	}
 
-	// Initialize poker table system
-	setupPokerSystem(wsServer)
+	// Initialize poker table system, persisting every completed hand and
+	// players' lifetime stats
+	handHistory := handlers.NewHandHistoryStore(cfg.DB)
+	playerStats := handlers.NewPlayerStatsStore(cfg.DB)
+	tableManager := setupPokerSystem(wsServer, handHistory, playerStats)
+	tableManager.SetHandHistoryStore(handHistory)
+	tableManager.SetHandEventStore(handHistory)
+	tableManager.SetPlayerStatsStore(playerStats)
+	tableManager.RatholeGuard().SetWindow(cfg.RatholeWindow)
+	tableManager.SetInterHandDelay(cfg.InterHandDelay)
+
+	// High-stakes tables wait for an admin; their creators hear the decision
+	tableManager.SetApprovalThreshold(cfg.TableApprovalBigBlind)
+	tableManager.SetApprovalNotifier(func(table *game.GameTable, approval game.TableApproval) {
+		wsServer.BroadcastToUser(table.CreatedBy, "table_approval_decided", map[string]interface{}{
+			"table_id":   table.ID,
+			"table_name": table.Name,
+			"approval":   approval,
+		})
+	})
 
-	// Register custom WebSocket message handlers
+	// Only push end-of-hand summaries to players who want them
+	tableManager.HandStats().SetPreference(func(playerID string) bool {
+		id, err := strconv.ParseUint(playerID, 10, 32)
+		if err != nil {
+			return false
+		}
+		prefs, err := handlers.LoadPreferences(cfg.DB, uint(id))
+		if err != nil {
+			log.Printf("Failed to load preferences for player %s: %v", playerID, err)
+		}
+		return prefs.HandStats
+	})
 
-	// Handler for getting user balance
-	wsServer.RegisterHandler("get_user_balance", func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		log.Printf("WebSocket: get_user_balance request from connection %s", conn.ID)
+	// Table creators own their table's chat room; observers can be muted there
+	wsServer.SetRoomDirectory(&TableRoomDirectory{tableManager: tableManager})
 
-		// Check if user is authenticated
-		if conn.UserID == "" {
-			return &websocket_v2.Message{
-				Type:      "get_user_balance_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Authentication required",
-			}
+	// Table creators invite users by username; invited users skip a private
+	// table's password until the invitation expires
+	tableManager.Invitations().SetTTL(cfg.TableInvitationTTL)
+	tableManager.SetSeatReservation(cfg.SeatReservationTTL)
+	tableManager.Invitations().SetResolver(func(username string) (string, error) {
+		var user models.User
+		if err := cfg.DB.Select("id").Where("username = ? AND is_active = ?", username, true).First(&user).Error; err != nil {
+			return "", err
 		}
+		return strconv.FormatUint(uint64(user.ID), 10), nil
+	})
+
+	// Keep table chat, profanity masked, for moderators to review
+	tableChatLog := handlers.NewTableChatLogStore(cfg.DB)
+	tableManager.TableChat().SetStore(tableChatLog)
 
-		// Parse request data
-		var requestData map[string]interface{}
-		if data, ok := msg.Data.(map[string]interface{}); ok {
-			requestData = data
+	// Players whose last connection drops keep their seats for the grace
+	// period; a reconnect before it runs out cancels the cleanup
+	tableManager.SetReconnectGrace(cfg.ReconnectGrace)
+	wsServer.SetPresenceHandler(func(userID string, online bool) {
+		if online {
+			tableManager.PlayerReconnected(context.Background(), userID)
 		} else {
-			return &websocket_v2.Message{
-				Type:      "get_user_balance_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Invalid request data",
-			}
+			tableManager.PlayerDisconnected(context.Background(), userID)
 		}
+	})
 
-		// Get userId from request or use authenticated user's ID
-		userID := conn.UserID
-		if reqUserID, exists := requestData["userId"]; exists {
-			if reqUserIDStr, ok := reqUserID.(string); ok {
-				// For now, users can only get their own balance
-				if reqUserIDStr != conn.UserID {
-					return &websocket_v2.Message{
-						Type:      "get_user_balance_response",
-						RequestID: msg.RequestID,
-						Success:   false,
-						Error:     "Access denied: can only access own balance",
-					}
-				}
-				userID = reqUserIDStr
-			}
-		}
+	// Charge Sit&Go entry fees and pay prizes in diamonds; rake goes to the
+	// house account
+	tableManager.SetDiamondLedger(handlers.NewDiamondLedger(cfg.DB))
+	tableManager.SetHouseAccount(cfg.HouseAccountID)
+	tableManager.SetRakeStore(handlers.NewRakeStore(cfg.DB))
+
+	// Certify every hand's result in a hash-chained, append-only log
+	certificationLog := handlers.NewCertificationLogStore(cfg.DB)
+	tableManager.SetCertificationLog(certificationLog)
 
-		// Query user's current balance
-		var currentBalance int
-		err := cfg.DB.Model(&models.Diamond{}).Where("user_id = ?", userID).Order("created_at desc").Limit(1).Pluck("balance", &currentBalance).Error
+	// Show equipped deck and table themes on the seats players take
+	tableManager.SetCosmeticsProvider(func(playerID string) map[string]string {
+		id, err := strconv.ParseUint(playerID, 10, 32)
 		if err != nil {
-			log.Printf("Error getting user balance: %v", err)
-			return &websocket_v2.Message{
-				Type:      "get_user_balance_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Failed to retrieve balance",
-			}
+			return nil
 		}
-
-		// Return success response
-		return &websocket_v2.Message{
-			Type:      "get_user_balance_response",
-			RequestID: msg.RequestID,
-			Success:   true,
-			Data: map[string]interface{}{
-				"userId":          userID,
-				"current_balance": currentBalance,
-			},
+		cosmetics, err := handlers.EquippedCosmetics(cfg.DB, uint(id))
+		if err != nil {
+			log.Printf("Failed to load cosmetics for player %s: %v", playerID, err)
+			return nil
 		}
+		return cosmetics
 	})
 
-	// Handler for getting user profile
-	wsServer.RegisterHandler("get_user_profile", func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		log.Printf("WebSocket: get_user_profile request from connection %s", conn.ID)
+	// Periodically check that chips in play match escrowed buy-ins
+	auditStore := handlers.NewAuditLogStore(cfg.DB, cfg.AuditRetention)
+	auditor := game.NewSecurityAuditor()
+	auditor.SetStore(auditStore)
+	chipReconciler := game.NewChipReconciler(tableManager, auditor)
+	chipReconciler.Start(game.DefaultReconcileInterval)
+
+	// Watch completed hands for soft play, one-sided folding and chip
+	// dumping, queueing what looks like collusion for an admin to review
+	collusionReview := handlers.NewCollusionReviewStore(cfg.DB)
+	collusionDetector := game.NewCollusionDetector(auditor)
+	collusionDetector.SetReviewQueue(collusionReview)
+	tableManager.SetCollusionDetector(collusionDetector)
+
+	// Reopen the tables open before a restart, resuming hands in progress,
+	// and keep snapshotting them
+	if restored, err := tableManager.RestoreTables(handlers.NewTableSnapshotStore(cfg.DB)); err != nil {
+		log.Printf("Failed to restore tables: %v", err)
+	} else if restored > 0 {
+		log.Printf("Restored %d tables from snapshots", restored)
+	}
+	tableManager.StartSnapshots(cfg.TableSnapshotInterval)
 
-		// Check if user is authenticated
-		if conn.UserID == "" {
-			return &websocket_v2.Message{
-				Type:      "get_user_profile_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Authentication required",
-			}
-		}
+	// On shutdown, hands in progress are played out before clients are
+	// disconnected; those still going when time runs out are snapshotted
+	wsServer.SetShutdownDrainer(func(ctx context.Context) {
+		result := tableManager.Drain(ctx)
+		log.Printf("Tables drained: %d hands finished, %d cut short, %d tables snapshotted", result.Finished, result.MidHand, result.Snapshotted)
+	})
 
-		// Parse request data
-		var requestData map[string]interface{}
-		if data, ok := msg.Data.(map[string]interface{}); ok {
-			requestData = data
-		} else {
-			return &websocket_v2.Message{
-				Type:      "get_user_profile_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Invalid request data",
-			}
-		}
+	// Refund escrow left behind by tables that closed without settling or
+	// did not survive a restart, once restored tables hold theirs again
+	escrowSweeper := game.NewEscrowSweeper(tableManager, auditor)
+	escrowSweeper.Sweep()
+	escrowSweeper.Start(game.DefaultEscrowSweepInterval)
+
+	// Keep per-user table caps across restarts, dropping tables that did not
+	// survive one
+	if report, err := tableManager.RestoreRateLimits(handlers.NewRateLimitStore(cfg.DB)); err != nil {
+		log.Printf("Failed to restore rate limit state: %v", err)
+	} else if report.Users > 0 {
+		log.Printf("Reconciled rate limit state for %d users against open tables", report.Users)
+	}
 
-		// Get userId from request or use authenticated user's ID
-		userID := conn.UserID
-		if reqUserID, exists := requestData["userId"]; exists {
-			if reqUserIDStr, ok := reqUserID.(string); ok {
-				// For now, users can only get their own profile
-				if reqUserIDStr != conn.UserID {
-					return &websocket_v2.Message{
-						Type:      "get_user_profile_response",
-						RequestID: msg.RequestID,
-						Success:   false,
-						Error:     "Access denied: can only access own profile",
-					}
-				}
-				userID = reqUserIDStr
-			}
-		}
+	// Purge audit entries, sign-in history, closed accounts' personal data
+	// and hand histories once they outlive their retention periods
+	retention := handlers.NewRetentionService(cfg.DB)
+	retention.Register(handlers.RetentionAuditLogs, auditStore.Retention(), auditStore.PruneBefore)
+	retention.Register(handlers.RetentionLoginEvents, cfg.LoginEventRetention, handlers.PurgeLoginEvents(cfg.DB))
+	retention.Register(handlers.RetentionClosedAccounts, cfg.ClosedAccountRetention, handlers.PurgeClosedAccounts(cfg.DB))
+	retention.Register(handlers.RetentionHandHistories, cfg.HandHistoryRetention, handHistory.PruneBefore)
+	retention.Start(cfg.RetentionPurgeEvery)
+
+	// Push hub, table and wallet metrics to admins subscribed to the stats topic
+	wsServer.Stats().AddSource("tables", func() interface{} { return tableManager.GetStats() })
+	wsServer.Stats().AddSource("wallet", func() interface{} { return walletStats(cfg.DB, tableManager, chipReconciler) })
+	wsServer.Stats().Start(websocket_v2.DefaultStatsInterval)
+
+	// Push per-stake-level action to players watching the lobby
+	wsServer.Lobby().SetSource(func() interface{} { return tableManager.LobbyStats() })
+	wsServer.Lobby().Start(websocket_v2.DefaultLobbyInterval)
+	wsServer.PublicLobby().Start(websocket_v2.DefaultPublicLobbyInterval)
+
+	// Shed low-priority WebSocket load while table mailboxes back up or the
+	// database slows down; actions in hands under way always go through
+	wsServer.LoadShedder().AddMailbox("tables", tableManager.MailboxDepth)
+	wsServer.LoadShedder().SetDBProbe(func() (time.Duration, error) {
+		return pingDatabase(cfg.DB)
+	})
+	wsServer.LoadShedder().Start()
 
-		// Query user profile
-		var user models.User
-		err := cfg.DB.Where("id = ?", userID).First(&user).Error
+	// Resolve declared WebSocket handler permissions against the database
+	wsServer.SetPermissionChecker(func(userID, permission string) (bool, error) {
+		id, err := strconv.ParseUint(userID, 10, 32)
 		if err != nil {
-			log.Printf("Error getting user profile: %v", err)
-			return &websocket_v2.Message{
-				Type:      "get_user_profile_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Failed to retrieve user profile",
-			}
+			return false, nil
 		}
+		return middleware.HasPermission(cfg.DB, uint(id), permission)
+	})
 
-		// Return success response
-		return &websocket_v2.Message{
-			Type:      "get_user_profile_response",
-			RequestID: msg.RequestID,
-			Success:   true,
-			Data: map[string]interface{}{
-				"id":       user.ID,
-				"username": user.Username,
-				"email":    user.Email,
-			},
+	// Bot tokens authenticate sanctioned bots, confined to bot-allowed tables in their scope
+	wsServer.SetBotTokenValidator(func(token string) (*websocket_v2.AuthResult, error) {
+		return authenticateBotToken(cfg.DB, token)
+	})
+	wsServer.SetBotAccessChecker(func(conn *websocket_v2.Connection, msg *websocket_v2.Message) error {
+		return checkBotTableAccess(conn, msg, tableManager)
+	})
+	wsServer.SetAccessChecker(func(conn *websocket_v2.Connection, msg *websocket_v2.Message) error {
+		return checkRegionTableAccess(conn, msg, tableManager, geoPolicy)
+	})
+
+	// Every request is logged once with its outcome instead of by each handler
+	wsServer.Use(websocket_v2.LogRequests())
+
+	// System actors are not held back by the message rate limits
+	for userID, actor := range cfg.RateLimitExempt {
+		if err := wsServer.RateLimitExemptions().Exempt(userID, actor); err != nil {
+			log.Fatal("Invalid rate limit exemption: ", err)
 		}
+	}
+
+	// Accounts that keep flooding the hub after being banned are tagged for review
+	wsServer.Penalties().SetAccountFlagger(func(userID string, penalty websocket_v2.Penalty) {
+		flagRateLimitAbuser(cfg.DB, userID, penalty)
 	})
 
+	// Register user WebSocket message handlers
+	registerUserHandlers(wsServer, cfg.DB)
+
 	// Start WebSocket server in background
 	go wsServer.Run()
 
@@ -182,27 +284,62 @@ func main() {
 	diamondHandler := handlers.NewDiamondHandler(cfg.DB)
 	roleHandler := handlers.NewRoleHandler(cfg.DB)
 	permissionHandler := handlers.NewPermissionHandler(cfg.DB)
+	disputeHandler := handlers.NewDisputeHandler(cfg.DB, handHistory)
+	handHistoryHandler := handlers.NewHandHistoryHandler(handHistory)
+	certificationHandler := handlers.NewCertificationHandler(certificationLog)
+	cosmeticHandler := handlers.NewCosmeticHandler(cfg.DB, tableManager)
+	preferenceHandler := handlers.NewPreferenceHandler(cfg.DB)
+	reportHandler := handlers.NewReportHandler(cfg.DB)
+	reportHandler.SetGeoPolicy(geoPolicy)
+	statusHandler := handlers.NewStatusHandler(cfg.DB)
+	auditLogHandler := handlers.NewAuditLogHandler(auditStore)
+	collusionHandler := handlers.NewCollusionReviewHandler(collusionReview)
+	tableChatLogHandler := handlers.NewTableChatLogHandler(tableChatLog)
+	retentionHandler := handlers.NewRetentionHandler(retention)
+	playMoneyHandler := handlers.NewPlayMoneyHandler(cfg.DB)
+	botTokenHandler := handlers.NewBotTokenHandler(cfg.DB)
+	tableHandler := handlers.NewSecureTableHandler(cfg.DB, tableManager)
+	accountMergeHandler := handlers.NewAccountMergeHandler(cfg.DB, tableManager)
+	tableHandler.SetGeoPolicy(geoPolicy)
+	registerBuyInPreviewHandler(wsServer, tableHandler, geoPolicy)
+	longPollHandler := handlers.NewLongPollHandler(wsServer.Outbox())
 
 	// Setup Gin router
 	router := gin.Default()
 
+	// Only believe forwarding headers set by our own proxies, so clients
+	// cannot choose the address their region is detected from
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// Add CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// Add Request ID middleware
 	router.Use(middleware.RequestIDMiddleware())
 
+	// Annotate requests with the client's detected region
+	router.Use(middleware.GeoMiddleware(geoPolicy))
+
 	// API routes
 	api := router.Group("/api/v1")
 	{
 		// Auth routes (public)
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
+			auth.POST("/register", middleware.RegionRestriction(geoPolicy, geo.ActionRegister), authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.GET("/profile", middleware.AuthMiddleware(authService), authHandler.GetProfile)
 		}
 
+		// System status (public read, admin update)
+		api.GET("/status", statusHandler.GetStatus)
+		api.PUT("/status", middleware.AuthMiddleware(authService), statusHandler.UpdateStatus)
+
+		// Diamond movements are refused where real-money play is blocked
+		realMoney := middleware.RegionRestriction(geoPolicy, geo.ActionRealMoney)
+
 		// Protected routes
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware(authService))
@@ -218,6 +355,11 @@ func main() {
 				users.POST("/:id/permissions", userHandler.AssignPermissions)
 				users.GET("/:id/permissions", userHandler.GetUserPermissions)
 				users.DELETE("/:id/permissions/:permission_id", userHandler.RemoveUserPermission)
+				users.GET("/tags", userHandler.GetTags)
+				users.GET("/:id/tags", userHandler.GetUserTags)
+				users.GET("/:id/activity", userHandler.GetUserActivity)
+				users.POST("/:id/tags", userHandler.AddUserTags)
+				users.DELETE("/:id/tags/:tag", userHandler.RemoveUserTag)
 			}
 
 			// Role routes
@@ -245,10 +387,322 @@ func main() {
 			diamonds := protected.Group("/diamonds")
 			{
 				diamonds.GET("/user/:userId", diamondHandler.GetUserDiamonds)
-				diamonds.POST("/credit", diamondHandler.AddDiamonds)
-				diamonds.POST("/debit", diamondHandler.DeductDiamonds)
+				diamonds.POST("/credit", realMoney, diamondHandler.AddDiamonds)
+				diamonds.POST("/debit", realMoney, diamondHandler.DeductDiamonds)
 				diamonds.GET("/transactions", diamondHandler.GetAllTransactions)
 			}
+
+			// Table routes; creating always opens a diamond table, joins are
+			// checked against the table's currency
+			tables := protected.Group("/tables")
+			{
+				tables.GET("", tableHandler.ListTables)
+				tables.POST("", realMoney, tableHandler.CreateTable)
+				tables.GET("/:id", tableHandler.GetTable)
+				tables.POST("/:id/join", tableHandler.JoinTable)
+				tables.POST("/:id/join/preview", tableHandler.PreviewBuyIn)
+			}
+
+			// Per-stake-level action shown in the lobby
+			protected.GET("/lobby/stats", tableHandler.GetLobbyStats)
+
+			// Play-money routes for practice tables (separate from diamonds)
+			playMoney := protected.Group("/play-money")
+			{
+				playMoney.GET("", playMoneyHandler.GetBalance)
+				playMoney.POST("/refill", playMoneyHandler.Refill)
+			}
+
+			// Bot token routes
+			botTokens := protected.Group("/bot-tokens")
+			{
+				botTokens.POST("", botTokenHandler.CreateBotToken)
+				botTokens.GET("", botTokenHandler.GetBotTokens)
+				botTokens.DELETE("/:id", botTokenHandler.RevokeBotToken)
+			}
+
+			// Long-poll fallback for clients that cannot open a websocket
+			poll := protected.Group("/poll")
+			{
+				poll.GET("", longPollHandler.Poll)
+				poll.GET("/subscriptions", longPollHandler.GetSubscriptions)
+				poll.POST("/subscriptions", longPollHandler.Subscribe)
+				poll.DELETE("/subscriptions/:room", longPollHandler.Unsubscribe)
+			}
+
+			// Hand dispute routes
+			disputes := protected.Group("/disputes")
+			{
+				disputes.POST("", disputeHandler.FileDispute)
+				disputes.GET("", disputeHandler.GetDisputes)
+				disputes.GET("/mine", disputeHandler.GetMyDisputes)
+				disputes.GET("/:id", disputeHandler.GetDispute)
+				disputes.PUT("/:id/review", disputeHandler.ReviewDispute)
+			}
+
+			// Hand history routes
+			hands := protected.Group("/hands")
+			{
+				hands.GET("", handHistoryHandler.ListHands)
+				hands.GET("/:hand_id", handHistoryHandler.GetHand)
+				hands.GET("/:hand_id/replay", handHistoryHandler.ReplayHand)
+			}
+
+			// Tamper-evidence for game results
+			certification := protected.Group("/certification")
+			{
+				certification.GET("/verify", certificationHandler.Verify)
+				certification.GET("/hands/:hand_id", certificationHandler.GetHandEntry)
+			}
+
+			// Gameplay preference routes
+			preferences := protected.Group("/preferences")
+			{
+				preferences.GET("", preferenceHandler.GetPreferences)
+				preferences.PUT("", preferenceHandler.UpdatePreferences)
+			}
+
+			// Cosmetic shop and inventory routes
+			cosmetics := protected.Group("/cosmetics")
+			{
+				cosmetics.GET("", cosmeticHandler.GetCatalog)
+				cosmetics.POST("", cosmeticHandler.CreateItem)
+				cosmetics.GET("/inventory", cosmeticHandler.GetInventory)
+				cosmetics.PUT("/:id", cosmeticHandler.UpdateItem)
+				cosmetics.POST("/:id/purchase", realMoney, cosmeticHandler.Purchase)
+				cosmetics.PUT("/:id/equip", cosmeticHandler.Equip)
+				cosmetics.DELETE("/:id/equip", cosmeticHandler.Unequip)
+			}
+
+			// Finance report routes
+			reports := protected.Group("/reports")
+			{
+				reports.GET("/rake", reportHandler.GetRakeReport)
+				reports.GET("/regions", reportHandler.GetRegionReport)
+			}
+
+			// Admin diagnostics routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.PermissionMiddleware(cfg.DB, "admin.access"))
+			{
+				admin.GET("/websocket/bandwidth", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       wsServer.Bandwidth().Diagnostics(),
+						"request_id": requestID,
+					})
+				})
+				admin.PUT("/websocket/bandwidth/users/:userId", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					var req struct {
+						Tier string `json:"tier" binding:"required"`
+					}
+					if err := c.ShouldBindJSON(&req); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data", "request_id": requestID})
+						return
+					}
+					if _, err := strconv.ParseUint(c.Param("userId"), 10, 32); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid user ID", "request_id": requestID})
+						return
+					}
+					if err := wsServer.Bandwidth().SetUserTier(c.Param("userId"), websocket_v2.BandwidthTier(req.Tier)); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "message": "Bandwidth tier updated", "request_id": requestID})
+				})
+				admin.GET("/websocket/send-queues", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       wsServer.SendQueues().Diagnostics(),
+						"request_id": requestID,
+					})
+				})
+				admin.GET("/websocket/load", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       wsServer.LoadShedder().Status(),
+						"request_id": requestID,
+					})
+				})
+				admin.GET("/websocket/penalties", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success": true,
+						"data": gin.H{
+							"penalties": wsServer.Penalties().List(),
+							"steps":     wsServer.Penalties().Steps(),
+						},
+						"request_id": requestID,
+					})
+				})
+				admin.DELETE("/websocket/penalties/:subject", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					if !wsServer.Penalties().Clear(c.Param("subject")) {
+						c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "No penalty for subject", "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "message": "Penalty cleared", "request_id": requestID})
+				})
+				admin.GET("/websocket/rate-limit-exemptions", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success": true,
+						"data": gin.H{
+							"exemptions": wsServer.RateLimitExemptions().List(),
+							"stats":      wsServer.RateLimitExemptions().Stats(),
+						},
+						"request_id": requestID,
+					})
+				})
+				admin.PUT("/websocket/rate-limit-exemptions/:userId", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					var req struct {
+						Actor string `json:"actor" binding:"required"`
+					}
+					if err := c.ShouldBindJSON(&req); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data", "request_id": requestID})
+						return
+					}
+					if err := wsServer.RateLimitExemptions().Exempt(c.Param("userId"), req.Actor); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "message": "Rate limit exemption granted", "request_id": requestID})
+				})
+				admin.DELETE("/websocket/rate-limit-exemptions/:userId", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					if !wsServer.RateLimitExemptions().Revoke(c.Param("userId")) {
+						c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "No exemption for user", "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "message": "Rate limit exemption revoked", "request_id": requestID})
+				})
+				admin.GET("/tables/reconciliation", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       chipReconciler.Reconcile(false),
+						"request_id": requestID,
+					})
+				})
+				admin.POST("/tables/reconciliation/repair", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       chipReconciler.Reconcile(true),
+						"request_id": requestID,
+					})
+				})
+				admin.GET("/escrow/sweeps", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       escrowSweeper.History(),
+						"request_id": requestID,
+					})
+				})
+				admin.POST("/escrow/sweep", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					c.JSON(http.StatusOK, gin.H{
+						"success":    true,
+						"data":       escrowSweeper.Sweep(),
+						"request_id": requestID,
+					})
+				})
+				admin.GET("/audit", auditLogHandler.ListAuditLogs)
+				admin.GET("/hands/:hand_id", handHistoryHandler.LookupHand)
+				admin.GET("/collusion/flags", collusionHandler.ListFlags)
+				admin.POST("/collusion/flags/:id/review", collusionHandler.ReviewFlag)
+				admin.GET("/chat/messages", tableChatLogHandler.ListMessages)
+				admin.GET("/certification/entries", certificationHandler.ListEntries)
+				admin.POST("/users/:id/merge", accountMergeHandler.MergeAccount)
+				admin.GET("/retention", retentionHandler.GetRetention)
+				admin.POST("/retention/purge", retentionHandler.RunPurge)
+				admin.GET("/tables/approvals", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					pending := make([]map[string]interface{}, 0)
+					for _, table := range tableManager.PendingApprovals() {
+						pending = append(pending, table.GetTableInfo())
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "data": pending, "request_id": requestID})
+				})
+				admin.POST("/tables/:tableId/approval", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					var req struct {
+						Approved *bool  `json:"approved" binding:"required"`
+						Reason   string `json:"reason"`
+					}
+					if err := c.ShouldBindJSON(&req); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data", "request_id": requestID})
+						return
+					}
+					reviewer := strconv.FormatUint(uint64(c.GetUint("user_id")), 10)
+					approval, err := tableManager.ReviewTable(c.Request.Context(), c.Param("tableId"), reviewer, *req.Approved, req.Reason)
+					if err == game.ErrTableNotFound {
+						c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					if err != nil {
+						c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "data": approval, "request_id": requestID})
+				})
+				admin.GET("/tables/featured", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					featured := make([]map[string]interface{}, 0)
+					for _, table := range tableManager.FeaturedTables() {
+						featured = append(featured, table.GetTableInfo())
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "data": featured, "request_id": requestID})
+				})
+				admin.PUT("/tables/:tableId/feature", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					var req struct {
+						Branding map[string]string `json:"branding"`
+					}
+					if err := c.ShouldBindJSON(&req); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data", "request_id": requestID})
+						return
+					}
+					featuredBy := strconv.FormatUint(uint64(c.GetUint("user_id")), 10)
+					feature, err := tableManager.FeatureTable(c.Request.Context(), c.Param("tableId"), featuredBy, req.Branding)
+					if err == game.ErrTableNotFound {
+						c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					if err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "data": feature, "request_id": requestID})
+				})
+				admin.DELETE("/tables/:tableId/feature", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					err := tableManager.UnfeatureTable(c.Request.Context(), c.Param("tableId"))
+					if err == game.ErrTableNotFound {
+						c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					if err != nil {
+						c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "request_id": requestID})
+				})
+				admin.DELETE("/tables/:tableId", func(c *gin.Context) {
+					requestID, _ := c.Get("request_id")
+					if err := tableManager.CloseTable(c.Param("tableId")); err != nil {
+						c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error(), "request_id": requestID})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"success": true, "request_id": requestID})
+				})
+			}
 		}
 	}
 
@@ -260,6 +714,14 @@ func main() {
 	// WebSocket endpoint
 	router.GET("/ws", gin.WrapH(wsServer))
 
+	// WebSocket handler documentation generated from the handler registry
+	router.GET("/api/websocket/handlers", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"success":  true,
+			"handlers": wsServer.HandlerSpecs(),
+		})
+	})
+
 	// WebSocket health check endpoint
 	router.GET("/api/websocket/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -272,11 +734,417 @@ func main() {
 
 	log.Printf("Server starting on port 8081")
 	log.Printf("WebSocket endpoint available at ws://localhost:8081/ws")
-	log.Fatal(http.ListenAndServe(":8081", router))
+	httpServer := &http.Server{Addr: ":8081", Handler: router}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// SIGINT or SIGTERM shuts down gracefully; a second signal exits at once
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-signals.Done()
+	stopSignals()
+	log.Printf("Shutting down, waiting up to %v for hands and connections to finish", cfg.ShutdownTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := wsServer.Shutdown(ctx); err != nil {
+		log.Printf("WebSocket shutdown did not finish cleanly: %v", err)
+	}
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTP shutdown did not finish cleanly: %v", err)
+		httpServer.Close()
+	}
+	tableManager.Stop()
+	log.Printf("Server stopped")
+}
+
+// registerUserHandlers registers account-related WebSocket handlers
+func registerUserHandlers(wsServer *websocket_v2.Server, db *gorm.DB) {
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "get_user_balance",
+		Description: "Returns the authenticated user's diamond balance",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "userId", Type: "string", Description: "Must match the authenticated user"},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleGetUserBalance(ctx, conn, msg, db)
+		},
+	})
+
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "get_user_profile",
+		Description: "Returns the authenticated user's profile",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "userId", Type: "string", Description: "Must match the authenticated user"},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleGetUserProfile(ctx, conn, msg, db)
+		},
+	})
+}
+
+// registerBuyInPreviewHandler registers the query clients use to confirm a
+// buy-in before joining a table
+func registerBuyInPreviewHandler(wsServer *websocket_v2.Server, tableHandler *handlers.SecureTableHandler, policy *geo.Policy) {
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "table_buy_in_preview",
+		Description: "Returns the diamond cost, fees, resulting balance and anything that would refuse a buy-in at a table, without joining",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "buy_in", Type: "number", Description: "Chips to sit with; defaults to the minimum"},
+			{Name: "password", Type: "string"},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		ResponseType:   "table_buy_in_preview_response",
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleBuyInPreview(conn, msg, tableHandler, policy)
+		},
+	})
+}
+
+// handleBuyInPreview previews the caller's buy-in at a table
+func handleBuyInPreview(conn *websocket_v2.Connection, msg *websocket_v2.Message, tableHandler *handlers.SecureTableHandler, policy *geo.Policy) *websocket_v2.Message {
+	var requestData struct {
+		TableID  string `json:"table_id"`
+		BuyIn    int    `json:"buy_in"`
+		Password string `json:"password"`
+	}
+	respond := func(success bool, errMsg string, data interface{}) *websocket_v2.Message {
+		return &websocket_v2.Message{
+			Type:      "table_buy_in_preview_response",
+			RequestID: msg.RequestID,
+			Success:   success,
+			Error:     errMsg,
+			Data:      data,
+		}
+	}
+	if err := parseMessageData(msg.Data, &requestData); err != nil || requestData.BuyIn < 0 {
+		return respond(false, "Invalid request data", nil)
+	}
+
+	userID, err := strconv.ParseUint(conn.UserID, 10, 32)
+	if err != nil {
+		return respond(false, "Buy-in previews are for signed-in players", nil)
+	}
+	preview, err := tableHandler.BuyInPreview(uint(userID), requestData.TableID, requestData.BuyIn, requestData.Password, policy.Region(conn.RemoteIP))
+	if err != nil {
+		if errors.Is(err, game.ErrTableNotFound) {
+			return respond(false, "Table not found", nil)
+		}
+		log.Printf("Failed to preview buy-in at table %s: %v", requestData.TableID, err)
+		return respond(false, "Failed to preview buy-in", nil)
+	}
+	return respond(true, "", preview)
+}
+
+// pingDatabase times a round trip to the database for the load shedder
+func pingDatabase(db *gorm.DB) (time.Duration, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	started := time.Now()
+	err = sqlDB.PingContext(ctx)
+	return time.Since(started), err
+}
+
+// walletStats summarizes the last hour of diamond movements and the chips
+// currently escrowed at tables for the stats topic
+func walletStats(db *gorm.DB, tableManager *game.ActorTableManager, reconciler *game.ChipReconciler) map[string]interface{} {
+	var totals struct {
+		Transactions int64
+		Credited     int64
+		Debited      int64
+	}
+	since := time.Now().Add(-time.Hour)
+	if err := db.Model(&models.Diamond{}).
+		Select("COUNT(*) AS transactions, "+
+			"COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS credited, "+
+			"COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS debited").
+		Where("created_at >= ?", since).
+		Scan(&totals).Error; err != nil {
+		log.Printf("Stats: failed to load wallet totals: %v", err)
+	}
+
+	var escrowed int64
+	escrow := tableManager.Escrow()
+	for _, tableID := range escrow.Tables() {
+		escrowed += escrow.Total(tableID)
+	}
+
+	unbalanced := 0
+	for _, result := range reconciler.LastResults() {
+		if !result.Balanced {
+			unbalanced++
+		}
+	}
+
+	return map[string]interface{}{
+		"transactions_last_hour": totals.Transactions,
+		"credited_last_hour":     totals.Credited,
+		"debited_last_hour":      totals.Debited,
+		"escrowed_chips":         escrowed,
+		"unbalanced_tables":      unbalanced,
+	}
+}
+
+// mustRegisterHandler registers a handler spec and aborts startup if it is invalid
+func mustRegisterHandler(wsServer *websocket_v2.Server, spec websocket_v2.HandlerSpec) {
+	if err := wsServer.Register(spec); err != nil {
+		log.Fatalf("Failed to register WebSocket handler %s: %v", spec.Name, err)
+	}
+}
+
+// handleGetUserBalance returns the current diamond balance for the connected user
+func handleGetUserBalance(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, db *gorm.DB) *websocket_v2.Message {
+	// Parse request data
+	var requestData map[string]interface{}
+	if data, ok := msg.Data.(map[string]interface{}); ok {
+		requestData = data
+	} else {
+		return &websocket_v2.Message{
+			Type:      "get_user_balance_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Invalid request data",
+		}
+	}
+
+	// Get userId from request or use authenticated user's ID
+	userID := conn.UserID
+	if reqUserID, exists := requestData["userId"]; exists {
+		if reqUserIDStr, ok := reqUserID.(string); ok {
+			// For now, users can only get their own balance
+			if reqUserIDStr != conn.UserID {
+				return &websocket_v2.Message{
+					Type:      "get_user_balance_response",
+					RequestID: msg.RequestID,
+					Success:   false,
+					Error:     "Access denied: can only access own balance",
+				}
+			}
+			userID = reqUserIDStr
+		}
+	}
+
+	// Query user's current balance
+	var currentBalance int
+	err := db.Model(&models.Diamond{}).Where("user_id = ?", userID).Order("created_at desc").Limit(1).Pluck("balance", &currentBalance).Error
+	if err != nil {
+		log.Printf("Error getting user balance: %v", err)
+		return &websocket_v2.Message{
+			Type:      "get_user_balance_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Failed to retrieve balance",
+		}
+	}
+
+	// Return success response
+	return &websocket_v2.Message{
+		Type:      "get_user_balance_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data: map[string]interface{}{
+			"userId":          userID,
+			"current_balance": currentBalance,
+		},
+	}
+}
+
+// handleGetUserProfile returns the profile of the connected user
+func handleGetUserProfile(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, db *gorm.DB) *websocket_v2.Message {
+	// Parse request data
+	var requestData map[string]interface{}
+	if data, ok := msg.Data.(map[string]interface{}); ok {
+		requestData = data
+	} else {
+		return &websocket_v2.Message{
+			Type:      "get_user_profile_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Invalid request data",
+		}
+	}
+
+	// Get userId from request or use authenticated user's ID
+	userID := conn.UserID
+	if reqUserID, exists := requestData["userId"]; exists {
+		if reqUserIDStr, ok := reqUserID.(string); ok {
+			// For now, users can only get their own profile
+			if reqUserIDStr != conn.UserID {
+				return &websocket_v2.Message{
+					Type:      "get_user_profile_response",
+					RequestID: msg.RequestID,
+					Success:   false,
+					Error:     "Access denied: can only access own profile",
+				}
+			}
+			userID = reqUserIDStr
+		}
+	}
+
+	// Query user profile
+	var user models.User
+	err := db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		log.Printf("Error getting user profile: %v", err)
+		return &websocket_v2.Message{
+			Type:      "get_user_profile_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Failed to retrieve user profile",
+		}
+	}
+
+	// Return success response
+	return &websocket_v2.Message{
+		Type:      "get_user_profile_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data: map[string]interface{}{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+		},
+	}
+}
+
+// authenticateBotToken validates a bot token and returns a scoped identity
+func authenticateBotToken(db *gorm.DB, token string) (*websocket_v2.AuthResult, error) {
+	botToken, user, err := handlers.AuthenticateBotToken(db, token)
+	if err != nil {
+		return &websocket_v2.AuthResult{Success: false, Error: "Invalid bot token"}, err
+	}
+
+	tableIDs, gameTypes := handlers.BotTokenScope(botToken)
+	return &websocket_v2.AuthResult{
+		UserID:   strconv.FormatUint(uint64(user.ID), 10),
+		Username: user.Username,
+		Success:  true,
+		Bot: &websocket_v2.BotScope{
+			TokenID:   botToken.ID,
+			TableIDs:  tableIDs,
+			GameTypes: gameTypes,
+		},
+	}, nil
+}
+
+// checkBotTableAccess keeps bot connections to bot-allowed tables within
+// their token scope. Messages that address no table are left to the handler.
+func checkBotTableAccess(conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	tableID, ok := data["table_id"].(string)
+	if !ok || tableID == "" {
+		return nil
+	}
+
+	table, err := tableManager.GetTable(tableID)
+	if err != nil {
+		return errors.New("table not found")
+	}
+	if !table.Settings.BotsAllowed {
+		return errors.New("bots are not allowed at this table")
+	}
+	if !conn.Bot.AllowsTable(table.ID, string(table.GameType)) {
+		return errors.New("bot token is not scoped to this table")
+	}
+	return nil
+}
+
+// rateLimitAbuseTag marks accounts flagged by the websocket penalty system
+const rateLimitAbuseTag = "rate_limit_abuse"
+
+// flagRateLimitAbuser tags a user who reached the highest rate-limit penalty
+func flagRateLimitAbuser(db *gorm.DB, userID string, penalty websocket_v2.Penalty) {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return
+	}
+	tag := models.UserTag{UserID: uint(id), Tag: rateLimitAbuseTag}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+		log.Printf("Failed to flag user %s for rate limit abuse: %v", userID, err)
+		return
+	}
+	log.Printf("Flagged user %s for rate limit abuse after %d violations", userID, penalty.Violations)
+}
+
+// loadGeoPolicy builds the jurisdiction policy from configuration. Without a
+// ranges file no region is detected, so nothing is blocked.
+func loadGeoPolicy(cfg *config.Config) *geo.Policy {
+	if cfg.GeoRangesFile == "" {
+		return geo.NewPolicy(nil, cfg.GeoBlocked)
+	}
+	resolver, err := geo.LoadRangeFile(cfg.GeoRangesFile)
+	if err != nil {
+		log.Fatal("Failed to load geo ranges:", err)
+	}
+	return geo.NewPolicy(resolver, cfg.GeoBlocked)
+}
+
+// checkRegionTableAccess refuses real-money play from regions where it is
+// blocked: sitting at or creating diamond tables, acting in their hands,
+// buying into or opening paid Sit&Gos and queueing for heads-up matches.
+// Observing and practice tables stay open everywhere.
+func checkRegionTableAccess(conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager, policy *geo.Policy) error {
+	data, _ := msg.Data.(map[string]interface{})
+
+	realMoney := false
+	switch msg.Type {
+	case "table_create":
+		settings, _ := data["settings"].(map[string]interface{})
+		currency, _ := settings["currency"].(string)
+		realMoney = game.TableCurrency(currency) != game.CurrencyPlayMoney
+	case "table_join":
+		if mode, _ := data["mode"].(string); strings.EqualFold(mode, string(game.JoinModeObserver)) {
+			return nil
+		}
+		fallthrough
+	case "poker_action":
+		tableID, _ := data["table_id"].(string)
+		table, err := tableManager.GetTable(tableID)
+		if err != nil {
+			return nil // Let the handler report the missing table
+		}
+		realMoney = table.UsesDiamondLedger()
+	case "sit_and_go_create":
+		fee, _ := data["entry_fee"].(float64)
+		realMoney = fee > 0
+	case "sit_and_go_join":
+		sitAndGoID, _ := data["sit_and_go_id"].(string)
+		sitAndGo, found := tableManager.GetSitAndGo(sitAndGoID)
+		if !found {
+			return nil // Let the handler report the missing Sit&Go
+		}
+		realMoney = sitAndGo.Info().EntryFee > 0
+	case "heads_up_join":
+		realMoney = true // Every heads-up match buys in with diamonds
+	}
+	if !realMoney {
+		return nil
+	}
+
+	if _, err := policy.Check(geo.ActionRealMoney, conn.RemoteIP); err != nil {
+		return err
+	}
+	return nil
 }
 
 // setupPokerSystem initializes the poker table system with WebSocket integration
-func setupPokerSystem(wsServer *websocket_v2.Server) {
+func setupPokerSystem(wsServer *websocket_v2.Server, handHistory *handlers.HandHistoryStore, playerStats *handlers.PlayerStatsStore) *game.ActorTableManager {
 	// Create WebSocket hub adapter
 	hubAdapter := &WebSocketHubAdapter{server: wsServer}
 
@@ -286,13 +1154,71 @@ func setupPokerSystem(wsServer *websocket_v2.Server) {
 	// Register all table message handlers
 	tableHandlers := tableIntegration.GetMessageHandlers()
 	for messageType, handler := range tableHandlers {
-		registerTableHandler(wsServer, messageType, handler)
+		spec, ok := tableHandlerSpecs[messageType]
+		if !ok {
+			spec = websocket_v2.HandlerSpec{RequireAuth: true, RateLimitClass: websocket_v2.RateLimitWrite}
+		}
+		spec.Name = messageType
+		registerTableHandler(wsServer, spec, handler)
 	}
 
 	// Register poker action handlers
-	registerPokerActionHandlers(wsServer, tableIntegration.GetTableManager())
+	registerPokerActionHandlers(wsServer, tableIntegration.GetTableManager(), handHistory, playerStats)
+
+	// Show visitors who have not signed in the open tables and tournaments
+	wsServer.PublicLobby().AddSource("tables", func() interface{} {
+		return tableIntegration.GetTableManager().PublicTables()
+	})
+	wsServer.PublicLobby().AddSource("tournaments", func() interface{} {
+		return tableIntegration.GetWebSocketHandler().PublicTournaments()
+	})
 
 	log.Printf("Poker system initialized with %d message handlers", len(tableHandlers)+5)
+
+	return tableIntegration.GetTableManager()
+}
+
+// TableRoomDirectory adapts the table manager to websocket_v2.RoomDirectory
+// so room chat settings know who created and who watches a table
+type TableRoomDirectory struct {
+	tableManager *game.ActorTableManager
+}
+
+// table returns the table whose room this is
+func (d *TableRoomDirectory) table(room string) (*game.GameTable, bool) {
+	if !strings.HasPrefix(room, "table_") {
+		return nil, false
+	}
+	table, err := d.tableManager.GetTable(strings.TrimPrefix(room, "table_"))
+	if err != nil || table.RoomID != room {
+		return nil, false
+	}
+	return table, true
+}
+
+// RoomOwner returns the creator of the table
+func (d *TableRoomDirectory) RoomOwner(room string) (string, bool) {
+	table, ok := d.table(room)
+	if !ok {
+		return "", false
+	}
+	return table.CreatedBy, true
+}
+
+// IsObserver reports whether the user watches the table without a seat
+func (d *TableRoomDirectory) IsObserver(room, userID string) bool {
+	table, ok := d.table(room)
+	return ok && !table.IsPlayerAtTable(userID)
+}
+
+// CanJoinRoom keeps observers out of the players' room at tables with a
+// spectator delay; they watch from the observer room instead
+func (d *TableRoomDirectory) CanJoinRoom(room, userID string) error {
+	table, ok := d.table(room)
+	if !ok || table.RoomFor(userID) == room {
+		return nil
+	}
+	return errors.New("observers watch this table from room " + table.RoomFor(userID))
 }
 
 // WebSocketHubAdapter adapts websocket_v2.Server to game.WebSocketHub
@@ -317,6 +1243,17 @@ func (w *WebSocketHubAdapter) BroadcastToRoom(roomID string, msg interface{}) er
 	return nil
 }
 
+// SendToPlayer delivers a message to every connection of one user
+func (w *WebSocketHubAdapter) SendToPlayer(playerID string, msg interface{}) error {
+	switch m := msg.(type) {
+	case *game.WebSocketMessage:
+		w.server.BroadcastToUser(playerID, m.Type, m.Data)
+	default:
+		w.server.BroadcastToUser(playerID, "unknown", msg)
+	}
+	return nil
+}
+
 func (w *WebSocketHubAdapter) GetRoomUsers(roomID string) []map[string]interface{} {
 	users := w.server.GetRoomUsers(roomID)
 	result := make([]map[string]interface{}, len(users))
@@ -377,11 +1314,311 @@ func (w *WebSocketConnectionAdapter) LeaveRoom(roomID string) error {
 	return nil
 }
 
-// registerTableHandler registers a table handler with WebSocket message conversion
-func registerTableHandler(wsServer *websocket_v2.Server, messageType string, handler func(ctx context.Context, conn game.WebSocketConnection, msg *game.WebSocketMessage) *game.WebSocketMessage) {
-	wsServer.RegisterHandler(messageType, func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		log.Printf("registerTableHandler: Handling message type '%s' for user %s", messageType, conn.UserID)
+// tableIDSchema is the payload schema shared by handlers addressing one table
+var tableIDSchema = []websocket_v2.FieldSpec{
+	{Name: "table_id", Type: "string", Required: true},
+}
 
+// tableHandlerSpecs declares metadata for the handlers exposed by the game package
+var tableHandlerSpecs = map[string]websocket_v2.HandlerSpec{
+	"table_create": {
+		Description: "Creates a table owned by the caller",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "name", Type: "string", Required: true},
+			{Name: "game_type", Type: "string", Required: true},
+			{Name: "settings", Type: "object"},
+			{Name: "description", Type: "string"},
+			{Name: "tags", Type: "array"},
+		},
+		RateLimitClass: websocket_v2.RateLimitStrict,
+	},
+	"table_join": {
+		Description: "Joins a table as a player or observer",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "mode", Type: "string"},
+			{Name: "position", Type: "number"},
+			{Name: "password", Type: "string"},
+			{Name: "buy_in", Type: "number", Description: "Chips to sit with; defaults to the minimum, which may be a previous stack at these stakes"},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		AllowBots:      true,
+		MovesMoney:     true,
+	},
+	"table_leave": {
+		Description:    "Leaves a table",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		Priority:       websocket_v2.PriorityCritical,
+		AllowBots:      true,
+	},
+	"add_chips": {
+		Description: "Buys the caller more chips between hands, up to the table's maximum buy-in",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "amount", Type: "number", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		AllowBots:      true,
+		MovesMoney:     true,
+	},
+	"table_list": {
+		Description: "Lists tables matching optional filters",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "game_type", Type: "string"},
+			{Name: "currency", Type: "string", Description: "diamonds or play_money"},
+			{Name: "practice", Type: "bool", Description: "Only practice (play-money) tables when true"},
+			{Name: "bots_allowed", Type: "bool", Description: "Only tables that admit bot tokens when true"},
+			{Name: "name", Type: "string", Description: "Only tables whose name contains this, ignoring case"},
+			{Name: "tags", Type: "any", Description: "Only tables carrying every tag: a list or a comma-separated string"},
+			{Name: "search", Type: "string", Description: "Matches table names and tags"},
+			{Name: "sort", Type: "string", Description: "created, players or stakes"},
+			{Name: "order", Type: "string", Description: "desc (default) or asc"},
+			{Name: "page", Type: "number"},
+			{Name: "limit", Type: "number", Description: "Tables per page, at most 100"},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		AllowBots:      true,
+	},
+	"lobby_subscribe": {
+		Description:    "Joins the lobby room for pushed table changes and returns the open tables",
+		RequireAuth:    true,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		AllowBots:      true,
+	},
+	"lobby_unsubscribe": {
+		Description:    "Leaves the lobby room",
+		RequireAuth:    true,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		AllowBots:      true,
+	},
+	"table_get": {
+		Description:    "Returns detailed information about a table",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		AllowBots:      true,
+	},
+	"table_get_observers": {
+		Description:    "Lists the observers watching a table",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		AllowBots:      true,
+	},
+	"table_close": {
+		Description:    "Closes a table owned by the caller",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"table_set_ready": {
+		Description:    "Marks the caller ready at a table",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		AllowBots:      true,
+	},
+	"table_start_game": {
+		Description:    "Starts the game at a table",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"table_get_stats": {
+		Description:    "Returns table manager statistics",
+		RequireAuth:    true,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+	},
+	"table_get_game_state": {
+		Description:    "Returns the game state visible to the caller",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityCritical,
+		AllowBots:      true,
+	},
+	"table_balance_accept": {
+		Description: "Accepts a seat balance offer, moving the caller to a busier table at the same stakes",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "offer_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"table_chat": {
+		Description: "Says a line in a table's chat, with profanity masked, to its players and observers",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "text", Type: "string", Required: true, Description: "Up to 280 characters"},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		Priority:       websocket_v2.PriorityLow,
+		Chat:           true,
+	},
+	"table_invite": {
+		Description: "Invites a user by username to a table the caller created, letting them skip its password for a while",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "username", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitStrict,
+	},
+	"table_change_seat": {
+		Description: "Moves the caller to an empty seat at their table between hands",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "position", Type: "number", Required: true, Description: "1-based seat"},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		AllowBots:      true,
+	},
+	"table_reserve_seat": {
+		Description: "Holds a seat for the caller while they buy in",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "position", Type: "number", Description: "1-based seat; the first open one when left out"},
+			{Name: "password", Type: "string", Description: "Private tables only"},
+		},
+		RateLimitClass: websocket_v2.RateLimitStrict,
+	},
+	"table_update_settings": {
+		Description: "Changes the name, description, observers, auto-start or turn time limit of a waiting table the caller created",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "name", Type: "string"},
+			{Name: "description", Type: "string"},
+			{Name: "observers_allowed", Type: "bool"},
+			{Name: "auto_start", Type: "bool"},
+			{Name: "time_limit", Type: "number", Description: "Turn time limit in seconds"},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"table_chat_mute": {
+		Description: "Mutes or unmutes a player in the chat of a table the caller created",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "player_id", Type: "string", Required: true},
+			{Name: "muted", Type: "bool", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"tournament_register": {
+		Description: "Registers the caller for a tournament that has not started",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "tournament_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"tournament_unregister": {
+		Description: "Withdraws the caller from a tournament that has not started",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "tournament_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"tournament_get": {
+		Description: "Returns a tournament's status, standings, prize pool and blind clock",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "tournament_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+	},
+	"tournament_spectate": {
+		Description: "Follows a tournament from its spectator room: eliminations, chip leaders, the clock and the feature table",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "tournament_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+	},
+	"tournament_stop_spectating": {
+		Description: "Leaves a tournament's spectator room",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "tournament_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+	},
+	"sit_and_go_create": {
+		Description: "Opens a single-table tournament with a diamond entry fee that starts when every seat is taken",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "name", Type: "string", Required: true},
+			{Name: "game_type", Type: "string"},
+			{Name: "seats", Type: "number"},
+			{Name: "entry_fee", Type: "number", Required: true},
+			{Name: "starting_stack", Type: "number", Required: true},
+			{Name: "payouts", Type: "array", Description: "Percent of the prize pool by finishing position; defaults by seat count"},
+		},
+		RateLimitClass: websocket_v2.RateLimitStrict,
+	},
+	"sit_and_go_list": {
+		Description:    "Lists the Sit&Gos that are filling up or in play",
+		RequireAuth:    true,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+	},
+	"sit_and_go_join": {
+		Description: "Pays the entry fee and takes a seat in a Sit&Go",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "sit_and_go_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		MovesMoney:     true,
+	},
+	"sit_and_go_leave": {
+		Description: "Leaves a Sit&Go that has not started and refunds the entry fee",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "sit_and_go_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+	"heads_up_list": {
+		Description:    "Lists the heads-up stakes and how many players are waiting at each",
+		RequireAuth:    true,
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+	},
+	"heads_up_join": {
+		Description: "Pays the buy-in and queues for a heads-up match, pairing with the next player at the same stakes",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "stakes", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		MovesMoney:     true,
+	},
+	"heads_up_leave": {
+		Description:    "Leaves the heads-up queue and refunds the buy-in",
+		RequireAuth:    true,
+		RateLimitClass: websocket_v2.RateLimitWrite,
+	},
+}
+
+// registerTableHandler registers a table handler with WebSocket message conversion
+func registerTableHandler(wsServer *websocket_v2.Server, spec websocket_v2.HandlerSpec, handler func(ctx context.Context, conn game.WebSocketConnection, msg *game.WebSocketMessage) *game.WebSocketMessage) {
+	spec.Handler = func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
 		// Convert websocket types to game types
 		tableConn := &WebSocketConnectionAdapter{conn: conn}
 		tableMsg := &game.WebSocketMessage{
@@ -390,17 +1627,12 @@ func registerTableHandler(wsServer *websocket_v2.Server, messageType string, han
 			Data:      msg.Data,
 		}
 
-		log.Printf("registerTableHandler: Calling handler for '%s'", messageType)
-
 		// Call the table handler
 		response := handler(ctx, tableConn, tableMsg)
 		if response == nil {
-			log.Printf("registerTableHandler: Handler returned nil for '%s'", messageType)
 			return nil
 		}
 
-		log.Printf("registerTableHandler: Handler returned success=%t, error='%s' for '%s'", response.Success, response.Error, messageType)
-
 		// Convert response back to websocket types
 		return &websocket_v2.Message{
 			Type:      response.Type,
@@ -409,48 +1641,105 @@ func registerTableHandler(wsServer *websocket_v2.Server, messageType string, han
 			Error:     response.Error,
 			Data:      response.Data,
 		}
-	})
+	}
+	mustRegisterHandler(wsServer, spec)
 }
 
 // registerPokerActionHandlers registers poker-specific action handlers
-func registerPokerActionHandlers(wsServer *websocket_v2.Server, tableManager *game.ActorTableManager) {
+func registerPokerActionHandlers(wsServer *websocket_v2.Server, tableManager *game.ActorTableManager, handHistory *handlers.HandHistoryStore, playerStats *handlers.PlayerStatsStore) {
 	// Register poker action handler
-	wsServer.RegisterHandler("poker_action", func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		return handlePokerAction(ctx, conn, msg, tableManager)
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "poker_action",
+		Description: "Submits a betting action for the caller at a table",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "action", Type: "string", Required: true, Description: "fold, call, raise, check, bet, all_in, show_cards, sit_out, sit_in or straddle"},
+			{Name: "amount", Type: "number", Description: "Required for raise and bet"},
+			{Name: "show", Type: "bool", Description: "For show_cards during a hand: false mucks at showdown"},
+		},
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		Priority:       websocket_v2.PriorityCritical,
+		AllowBots:      true,
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handlePokerAction(ctx, conn, msg, tableManager)
+		},
 	})
 
 	// Register hand history request handler
-	wsServer.RegisterHandler("get_hand_history", func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		return handleGetHandHistory(ctx, conn, msg, tableManager)
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "get_hand_history",
+		Description: "Returns completed hands played at a table, most recent first",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "limit", Type: "number", Description: "Hands per page, at most 100"},
+			{Name: "page", Type: "number", Description: "Defaults to the first page"},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		ResponseType:   "hand_history_response",
+		AllowBots:      true,
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleGetHandHistory(ctx, conn, msg, tableManager, handHistory)
+		},
+	})
+
+	// Register hand replay handler
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "replay_hand",
+		Description: "Returns a completed hand's events with their offsets from the deal, for players dealt into it",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "hand_id", Type: "string", Required: true},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		AllowBots:      true,
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleReplayHand(conn, msg, handHistory)
+		},
 	})
 
 	// Register player stats handler
-	wsServer.RegisterHandler("get_player_stats", func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		return handleGetPlayerStats(ctx, conn, msg, tableManager)
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:        "get_player_stats",
+		Description: "Returns a player's lifetime statistics across tables",
+		RequireAuth: true,
+		Schema: []websocket_v2.FieldSpec{
+			{Name: "table_id", Type: "string", Description: "Takes the currency from the table"},
+			{Name: "player_id", Type: "string", Description: "Defaults to the caller"},
+			{Name: "currency", Type: "string", Description: "diamonds (default) or play_money"},
+		},
+		RateLimitClass: websocket_v2.RateLimitRead,
+		Priority:       websocket_v2.PriorityLow,
+		ResponseType:   "player_stats_response",
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleGetPlayerStats(ctx, conn, msg, tableManager, playerStats)
+		},
 	})
 
 	// Register table join room handler (for spectating)
-	wsServer.RegisterHandler("join_table_room", func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
-		return handleJoinTableRoom(ctx, conn, msg, tableManager)
+	mustRegisterHandler(wsServer, websocket_v2.HandlerSpec{
+		Name:           "join_table_room",
+		Description:    "Subscribes the caller to a table's broadcast room",
+		RequireAuth:    true,
+		Schema:         tableIDSchema,
+		RateLimitClass: websocket_v2.RateLimitWrite,
+		Handler: func(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message) *websocket_v2.Message {
+			return handleJoinTableRoom(ctx, conn, msg, tableManager)
+		},
 	})
 }
 
 // handlePokerAction handles poker actions (fold, call, raise, etc.)
 func handlePokerAction(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager) *websocket_v2.Message {
-	if conn.UserID == "" {
-		return &websocket_v2.Message{
-			Type:      "poker_action_response",
-			RequestID: msg.RequestID,
-			Success:   false,
-			Error:     "Authentication required",
-		}
-	}
-
 	// Parse poker action data
 	var actionData struct {
 		TableID string `json:"table_id"`
-		Action  string `json:"action"` // fold, call, raise, check, bet, all_in
+		Action  string `json:"action"` // fold, call, raise, check, bet, all_in, show_cards, sit_out, sit_in, straddle
 		Amount  int    `json:"amount"` // for raise/bet actions
+		Show    *bool  `json:"show"`   // for show_cards; false mucks
 	}
 
 	if err := parseMessageData(msg.Data, &actionData); err != nil {
@@ -484,6 +1773,17 @@ func handlePokerAction(ctx context.Context, conn *websocket_v2.Connection, msg *
 		}
 	}
 
+	// Sitting out or back in changes the seat rather than the hand, so it
+	// works between hands too
+	if sittingOut := actionData.Action == string(game.ActionSitOut); sittingOut || actionData.Action == string(game.ActionSitIn) {
+		return handleSitOut(ctx, msg, tableManager, table.ID, playerID, sittingOut)
+	}
+
+	// A straddle is posted before the deal of the next hand
+	if actionData.Action == string(game.ActionStraddle) {
+		return handleStraddle(ctx, msg, tableManager, table.ID, playerID)
+	}
+
 	if table.Status != game.TableStatusActive {
 		return &websocket_v2.Message{
 			Type:      "poker_action_response",
@@ -502,6 +1802,9 @@ func handlePokerAction(ctx context.Context, conn *websocket_v2.Connection, msg *
 			"amount": actionData.Amount,
 		},
 	}
+	if actionData.Show != nil {
+		gameAction.Data["show"] = *actionData.Show
+	}
 
 	// Validate action
 	if err := table.GameEngine.IsValidAction(gameAction); err != nil {
@@ -524,8 +1827,11 @@ func handlePokerAction(ctx context.Context, conn *websocket_v2.Connection, msg *
 		}
 	}
 
-	// Broadcast game event to all players at table
-	tableManager.BroadcastGameEvent(table, event)
+	// Broadcast game event to all players at table; a show or muck choice is
+	// only confirmed to the player making it
+	if event.Type != "show_choice" {
+		tableManager.BroadcastGameEvent(table, event)
+	}
 
 	return &websocket_v2.Message{
 		Type:      "poker_action_response",
@@ -539,79 +1845,66 @@ func handlePokerAction(ctx context.Context, conn *websocket_v2.Connection, msg *
 	}
 }
 
-// handleGetGameState returns current game state for a table
-func handleGetGameState(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager) *websocket_v2.Message {
-	if conn.UserID == "" {
-		return &websocket_v2.Message{
-			Type:      "game_state_response",
-			RequestID: msg.RequestID,
-			Success:   false,
-			Error:     "Authentication required",
-		}
-	}
-
-	var requestData struct {
-		TableID string `json:"table_id"`
+// handleSitOut takes the caller out of the deal or back in from the next hand
+func handleSitOut(ctx context.Context, msg *websocket_v2.Message, tableManager *game.ActorTableManager, tableID, playerID string, sittingOut bool) *websocket_v2.Message {
+	change := tableManager.SitIn
+	action := game.ActionSitIn
+	if sittingOut {
+		change, action = tableManager.SitOut, game.ActionSitOut
 	}
-
-	if err := parseMessageData(msg.Data, &requestData); err != nil {
+	if err := change(ctx, tableID, playerID); err != nil {
 		return &websocket_v2.Message{
-			Type:      "game_state_response",
+			Type:      "poker_action_response",
 			RequestID: msg.RequestID,
 			Success:   false,
-			Error:     "Invalid request data",
+			Error:     "Failed to process action: " + err.Error(),
 		}
 	}
-
-	table, err := tableManager.GetTable(requestData.TableID)
-	if err != nil {
-		return &websocket_v2.Message{
-			Type:      "game_state_response",
-			RequestID: msg.RequestID,
-			Success:   false,
-			Error:     "Table not found",
-		}
+	return &websocket_v2.Message{
+		Type:      "poker_action_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data: map[string]interface{}{
+			"action":      action,
+			"processed":   true,
+			"sitting_out": sittingOut,
+		},
 	}
+}
 
-	// Check if user can view game state (player or observer)
-	playerID := conn.UserID
-	if !table.IsPlayerAtTable(playerID) && !table.IsObserver(playerID) {
+// handleStraddle has the caller straddle the next hand
+func handleStraddle(ctx context.Context, msg *websocket_v2.Message, tableManager *game.ActorTableManager, tableID, playerID string) *websocket_v2.Message {
+	if err := tableManager.Straddle(ctx, tableID, playerID); err != nil {
 		return &websocket_v2.Message{
-			Type:      "game_state_response",
+			Type:      "poker_action_response",
 			RequestID: msg.RequestID,
 			Success:   false,
-			Error:     "Access denied",
+			Error:     "Failed to process action: " + err.Error(),
 		}
 	}
-
-	// Get game state
-	gameState := table.GameEngine.GetGameState()
-
 	return &websocket_v2.Message{
-		Type:      "game_state_response",
+		Type:      "poker_action_response",
 		RequestID: msg.RequestID,
 		Success:   true,
-		Data:      gameState,
+		Data: map[string]interface{}{
+			"action":    game.ActionStraddle,
+			"processed": true,
+		},
 	}
 }
 
-// handleGetHandHistory returns hand history for a table
-func handleGetHandHistory(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager) *websocket_v2.Message {
-	if conn.UserID == "" {
-		return &websocket_v2.Message{
-			Type:      "hand_history_response",
-			RequestID: msg.RequestID,
-			Success:   false,
-			Error:     "Authentication required",
-		}
-	}
-
+// handleGetHandHistory returns a page of the hands persisted for a table.
+// Seated players and observers see every hand; once the table has closed,
+// players see the hands they were dealt into. Hole cards not shown at
+// showdown are hidden except the caller's own.
+func handleGetHandHistory(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager, handHistory *handlers.HandHistoryStore) *websocket_v2.Message {
 	var requestData struct {
 		TableID string `json:"table_id"`
 		Limit   int    `json:"limit"`
+		Page    int    `json:"page"`
 	}
 
-	if err := parseMessageData(msg.Data, &requestData); err != nil {
+	if err := parseMessageData(msg.Data, &requestData); err != nil || requestData.Limit < 0 || requestData.Page < 0 {
 		return &websocket_v2.Message{
 			Type:      "hand_history_response",
 			RequestID: msg.RequestID,
@@ -621,32 +1914,50 @@ func handleGetHandHistory(ctx context.Context, conn *websocket_v2.Connection, ms
 	}
 
 	if requestData.Limit == 0 {
-		requestData.Limit = 10 // Default limit
+		requestData.Limit = handlers.DefaultHandHistoryLimit
+	}
+	if requestData.Limit > handlers.MaxHandHistoryLimit {
+		requestData.Limit = handlers.MaxHandHistoryLimit
+	}
+	if requestData.Page == 0 {
+		requestData.Page = 1
 	}
 
-	table, err := tableManager.GetTable(requestData.TableID)
-	if err != nil {
-		return &websocket_v2.Message{
-			Type:      "hand_history_response",
-			RequestID: msg.RequestID,
-			Success:   false,
-			Error:     "Table not found",
-		}
+	query := handlers.HandHistoryQuery{
+		TableID: requestData.TableID,
+		Page:    requestData.Page,
+		Limit:   requestData.Limit,
 	}
 
 	// Check access permissions
 	playerID := conn.UserID
-	if !table.IsPlayerAtTable(playerID) && !table.IsObserver(playerID) {
+	if table, err := tableManager.GetTable(requestData.TableID); err == nil {
+		if !table.IsPlayerAtTable(playerID) && !table.IsObserver(playerID) {
+			return &websocket_v2.Message{
+				Type:      "hand_history_response",
+				RequestID: msg.RequestID,
+				Success:   false,
+				Error:     "Access denied",
+			}
+		}
+	} else {
+		query.PlayerID = playerID
+	}
+
+	records, total, err := handHistory.ListHands(query)
+	if err != nil {
+		conn.Logf("Failed to load hand history for table %s: %v", requestData.TableID, err)
 		return &websocket_v2.Message{
 			Type:      "hand_history_response",
 			RequestID: msg.RequestID,
 			Success:   false,
-			Error:     "Access denied",
+			Error:     "Failed to load hand history",
 		}
 	}
-
-	// Get hand history
-	history := table.GameEngine.GetHandHistory(requestData.Limit)
+	history := make([]game.HandRecord, 0, len(records))
+	for i := range records {
+		history = append(history, records[i].VisibleTo(playerID))
+	}
 
 	return &websocket_v2.Message{
 		Type:      "hand_history_response",
@@ -655,24 +1966,71 @@ func handleGetHandHistory(ctx context.Context, conn *websocket_v2.Connection, ms
 		Data: map[string]interface{}{
 			"table_id": requestData.TableID,
 			"history":  history,
+			"pagination": handlers.PaginationInfo{
+				Page:       query.Page,
+				Limit:      query.Limit,
+				Total:      total,
+				TotalPages: int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+			},
 		},
 	}
 }
 
-// handleGetPlayerStats returns player statistics
-func handleGetPlayerStats(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager) *websocket_v2.Message {
-	if conn.UserID == "" {
+// handleReplayHand returns the event log of a hand the caller was dealt
+// into, timed from the deal so the client can animate it
+func handleReplayHand(conn *websocket_v2.Connection, msg *websocket_v2.Message, handHistory *handlers.HandHistoryStore) *websocket_v2.Message {
+	var requestData struct {
+		HandID string `json:"hand_id"`
+	}
+	if err := parseMessageData(msg.Data, &requestData); err != nil || requestData.HandID == "" {
 		return &websocket_v2.Message{
-			Type:      "player_stats_response",
+			Type:      "replay_hand_response",
 			RequestID: msg.RequestID,
 			Success:   false,
-			Error:     "Authentication required",
+			Error:     "Invalid request data",
+		}
+	}
+
+	record, err := handHistory.GetHand(requestData.HandID)
+	if err != nil || !record.Dealt(conn.UserID) {
+		// Hands the caller was not dealt into look the same as missing ones
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			conn.Logf("Failed to load hand %s: %v", requestData.HandID, err)
 		}
+		return &websocket_v2.Message{
+			Type:      "replay_hand_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Hand not found",
+		}
+	}
+
+	events, err := handHistory.HandEvents(record.HandID)
+	if err != nil {
+		conn.Logf("Failed to load the event log of hand %s: %v", record.HandID, err)
+		return &websocket_v2.Message{
+			Type:      "replay_hand_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Failed to load hand events",
+		}
+	}
+
+	return &websocket_v2.Message{
+		Type:      "replay_hand_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data:      game.NewHandReplay(record.TableID, record.HandID, events),
 	}
+}
 
+// handleGetPlayerStats returns a player's lifetime statistics at tables of
+// one currency: the named table's, or the requested one
+func handleGetPlayerStats(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager, playerStats *handlers.PlayerStatsStore) *websocket_v2.Message {
 	var requestData struct {
-		TableID  string `json:"table_id"`
+		TableID  string `json:"table_id,omitempty"`
 		PlayerID string `json:"player_id,omitempty"`
+		Currency string `json:"currency,omitempty"`
 	}
 
 	if err := parseMessageData(msg.Data, &requestData); err != nil {
@@ -689,18 +2047,41 @@ func handleGetPlayerStats(ctx context.Context, conn *websocket_v2.Connection, ms
 		requestData.PlayerID = conn.UserID
 	}
 
-	table, err := tableManager.GetTable(requestData.TableID)
-	if err != nil {
+	currency := game.TableCurrency(requestData.Currency)
+	if requestData.TableID != "" {
+		table, err := tableManager.GetTable(requestData.TableID)
+		if err != nil {
+			return &websocket_v2.Message{
+				Type:      "player_stats_response",
+				RequestID: msg.RequestID,
+				Success:   false,
+				Error:     "Table not found",
+			}
+		}
+		currency = table.GetCurrency()
+	}
+	if currency == "" {
+		currency = game.CurrencyDiamonds
+	}
+	if currency != game.CurrencyDiamonds && currency != game.CurrencyPlayMoney {
 		return &websocket_v2.Message{
 			Type:      "player_stats_response",
 			RequestID: msg.RequestID,
 			Success:   false,
-			Error:     "Table not found",
+			Error:     "Invalid currency",
 		}
 	}
 
-	// Get player stats
-	stats := table.GameEngine.GetPlayerStats(requestData.PlayerID)
+	stats, err := playerStats.GetPlayerStats(requestData.PlayerID, currency)
+	if err != nil {
+		conn.Logf("Failed to load stats for player %s: %v", requestData.PlayerID, err)
+		return &websocket_v2.Message{
+			Type:      "player_stats_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Failed to load player stats",
+		}
+	}
 
 	return &websocket_v2.Message{
 		Type:      "player_stats_response",
@@ -710,6 +2091,9 @@ func handleGetPlayerStats(ctx context.Context, conn *websocket_v2.Connection, ms
 			"table_id":  requestData.TableID,
 			"player_id": requestData.PlayerID,
 			"stats":     stats,
+			// Practice stats are kept apart so they are never merged with real-money stats
+			"currency": currency,
+			"practice": currency == game.CurrencyPlayMoney,
 		},
 	}
 }
@@ -718,16 +2102,6 @@ func handleGetPlayerStats(ctx context.Context, conn *websocket_v2.Connection, ms
 func handleJoinTableRoom(ctx context.Context, conn *websocket_v2.Connection, msg *websocket_v2.Message, tableManager *game.ActorTableManager) *websocket_v2.Message {
 	log.Printf("handleJoinTableRoom: Starting for user %s", conn.UserID)
 
-	if conn.UserID == "" {
-		log.Printf("handleJoinTableRoom: Authentication required")
-		return &websocket_v2.Message{
-			Type:      "join_table_room_response",
-			RequestID: msg.RequestID,
-			Success:   false,
-			Error:     "Authentication required",
-		}
-	}
-
 	log.Printf("handleJoinTableRoom: Parsing request data")
 	var requestData struct {
 		TableID string `json:"table_id"`
@@ -767,18 +2141,25 @@ func handleJoinTableRoom(ctx context.Context, conn *websocket_v2.Connection, msg
 		}
 	}
 
-	log.Printf("handleJoinTableRoom: Joining room %s", table.RoomID)
-	// Join the table room
-	conn.JoinRoom(table.RoomID)
+	// Observers at tables with a spectator delay follow the observer room
+	roomID := table.RoomFor(conn.UserID)
+	log.Printf("handleJoinTableRoom: Joining room %s", roomID)
+	for _, room := range table.Rooms() {
+		if room != roomID {
+			conn.LeaveRoom(room)
+		}
+	}
+	conn.JoinRoom(roomID)
 
-	log.Printf("handleJoinTableRoom: Successfully joined room %s", table.RoomID)
+	log.Printf("handleJoinTableRoom: Successfully joined room %s", roomID)
 	return &websocket_v2.Message{
 		Type:      "join_table_room_response",
 		RequestID: msg.RequestID,
 		Success:   true,
 		Data: map[string]interface{}{
-			"table_id": requestData.TableID,
-			"room_id":  table.RoomID,
+			"table_id":        requestData.TableID,
+			"room_id":         roomID,
+			"spectator_delay": table.Settings.SpectatorDelay,
 		},
 	}
 }
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"caslette-server/geo"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GeoMiddleware resolves the client's region and stores it in the context
+// under "region" for handlers and compliance records
+func GeoMiddleware(policy *geo.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("region", policy.Region(c.ClientIP()))
+		c.Next()
+	}
+}
+
+// RegionRestriction refuses requests from regions where the action is blocked
+func RegionRestriction(policy *geo.Policy, action geo.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		region, exists := c.Get("region")
+		if !exists {
+			region = policy.Region(c.ClientIP())
+		}
+
+		if !policy.Allows(action, region.(string)) {
+			err := &geo.RestrictedError{Action: action, Region: region.(string)}
+			c.JSON(http.StatusUnavailableForLegalReasons, gin.H{"error": err.Error(), "region": region})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -19,7 +19,7 @@ func PermissionMiddleware(db *gorm.DB, requiredPermission string) gin.HandlerFun
 		}
 
 		// Check if user has the required permission
-		hasPermission, err := checkUserPermission(db, userID.(uint), requiredPermission)
+		hasPermission, err := permissionCache.HasPermission(db, userID.(uint), requiredPermission)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
 			c.Abort()
@@ -36,34 +36,8 @@ func PermissionMiddleware(db *gorm.DB, requiredPermission string) gin.HandlerFun
 	}
 }
 
-// checkUserPermission checks if a user has a specific permission (through roles or direct assignment)
-func checkUserPermission(db *gorm.DB, userID uint, permissionName string) (bool, error) {
-	var count int64
-
-	// Check direct user permissions
-	err := db.Table("user_permissions").
-		Joins("JOIN permissions ON permissions.id = user_permissions.permission_id").
-		Where("user_permissions.user_id = ? AND permissions.name = ?", userID, permissionName).
-		Count(&count).Error
-
-	if err != nil {
-		return false, err
-	}
-
-	if count > 0 {
-		return true, nil
-	}
-
-	// Check permissions through roles
-	err = db.Table("role_permissions").
-		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
-		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
-		Where("user_roles.user_id = ? AND permissions.name = ?", userID, permissionName).
-		Count(&count).Error
-
-	if err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
+// HasPermission reports whether a user holds a permission directly or through
+// a role, using the shared cache
+func HasPermission(db *gorm.DB, userID uint, permissionName string) (bool, error) {
+	return permissionCache.HasPermission(db, userID, permissionName)
 }
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultPermissionCacheTTL bounds how long a user's roles and permissions
+// are served from memory before being reloaded
+const DefaultPermissionCacheTTL = 5 * time.Minute
+
+// userGrants is everything a user holds, directly or through roles
+type userGrants struct {
+	roles       map[string]bool
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+// pendingLoad tracks the loads of one user's grants under way and the
+// invalidations seen while they run
+type pendingLoad struct {
+	loads   int
+	version uint64 // Bumped by Invalidate
+}
+
+// PermissionCache keeps each user's roles and permissions in memory so
+// authorization checks don't join four tables on every request. Entries
+// expire after the TTL and are dropped explicitly when grants change.
+type PermissionCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	entries    map[uint]*userGrants
+	generation uint64                // Bumped by InvalidateAll so in-flight loads aren't stored
+	pending    map[uint]*pendingLoad // Users whose grants are being loaded
+	now        func() time.Time
+	load       func(db *gorm.DB, userID uint) (*userGrants, error)
+}
+
+// NewPermissionCache creates an empty cache with the given TTL
+func NewPermissionCache(ttl time.Duration) *PermissionCache {
+	if ttl <= 0 {
+		ttl = DefaultPermissionCacheTTL
+	}
+	return &PermissionCache{
+		ttl:     ttl,
+		entries: make(map[uint]*userGrants),
+		pending: make(map[uint]*pendingLoad),
+		now:     time.Now,
+		load:    loadUserGrants,
+	}
+}
+
+// permissionCache is shared by REST middleware, handlers and WebSocket authorization
+var permissionCache = NewPermissionCache(DefaultPermissionCacheTTL)
+
+// HasPermission reports whether a user holds a permission
+func (pc *PermissionCache) HasPermission(db *gorm.DB, userID uint, permissionName string) (bool, error) {
+	grants, err := pc.grants(db, userID)
+	if err != nil {
+		return false, err
+	}
+	return grants.permissions[permissionName], nil
+}
+
+// HasRole reports whether a user has been assigned a role
+func (pc *PermissionCache) HasRole(db *gorm.DB, userID uint, roleName string) (bool, error) {
+	grants, err := pc.grants(db, userID)
+	if err != nil {
+		return false, err
+	}
+	return grants.roles[roleName], nil
+}
+
+// Invalidate drops a user's cached grants after their roles or permissions change
+func (pc *PermissionCache) Invalidate(userID uint) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	delete(pc.entries, userID)
+	if load, ok := pc.pending[userID]; ok {
+		load.version++
+	}
+}
+
+// InvalidateAll drops every cached entry, for changes to a role or
+// permission that may affect many users
+func (pc *PermissionCache) InvalidateAll() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.entries = make(map[uint]*userGrants)
+	pc.generation++
+}
+
+// grants returns a user's cached grants, loading them when missing or expired
+func (pc *PermissionCache) grants(db *gorm.DB, userID uint) (*userGrants, error) {
+	pc.mu.Lock()
+	if entry, ok := pc.entries[userID]; ok && pc.now().Sub(entry.loadedAt) < pc.ttl {
+		pc.mu.Unlock()
+		return entry, nil
+	}
+	load, ok := pc.pending[userID]
+	if !ok {
+		load = &pendingLoad{}
+		pc.pending[userID] = load
+	}
+	load.loads++
+	generation, version := pc.generation, load.version
+	pc.mu.Unlock()
+
+	loaded, err := pc.load(db, userID)
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	// The last load under way stops tracking the user
+	if load.loads--; load.loads == 0 {
+		delete(pc.pending, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	loaded.loadedAt = pc.now()
+	// An invalidation during the load means the result may already be stale
+	if pc.generation == generation && load.version == version {
+		pc.entries[userID] = loaded
+	}
+	return loaded, nil
+}
+
+// loadUserGrants reads a user's roles and their direct and role permissions
+func loadUserGrants(db *gorm.DB, userID uint) (*userGrants, error) {
+	grants := &userGrants{
+		roles:       make(map[string]bool),
+		permissions: make(map[string]bool),
+	}
+
+	var roles []string
+	if err := db.Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("roles.name", &roles).Error; err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		grants.roles[role] = true
+	}
+
+	var direct []string
+	if err := db.Table("user_permissions").
+		Joins("JOIN permissions ON permissions.id = user_permissions.permission_id").
+		Where("user_permissions.user_id = ?", userID).
+		Pluck("permissions.name", &direct).Error; err != nil {
+		return nil, err
+	}
+
+	var inherited []string
+	if err := db.Table("role_permissions").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.name", &inherited).Error; err != nil {
+		return nil, err
+	}
+
+	for _, permission := range append(direct, inherited...) {
+		grants.permissions[permission] = true
+	}
+	return grants, nil
+}
+
+// HasRole reports whether a user has a role, using the shared cache
+func HasRole(db *gorm.DB, userID uint, roleName string) (bool, error) {
+	return permissionCache.HasRole(db, userID, roleName)
+}
+
+// InvalidateUserPermissions drops a user's cached grants
+func InvalidateUserPermissions(userID uint) {
+	permissionCache.Invalidate(userID)
+}
+
+// InvalidateAllPermissions drops every user's cached grants
+func InvalidateAllPermissions() {
+	permissionCache.InvalidateAll()
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newTestPermissionCache returns a cache whose loader serves grants from a
+// map and counts how often it is called
+func newTestPermissionCache(grants map[uint][]string, roles map[uint][]string) (*PermissionCache, *int, *time.Time) {
+	cache := NewPermissionCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	loads := 0
+	cache.load = func(db *gorm.DB, userID uint) (*userGrants, error) {
+		loads++
+		loaded := &userGrants{roles: make(map[string]bool), permissions: make(map[string]bool)}
+		for _, role := range roles[userID] {
+			loaded.roles[role] = true
+		}
+		for _, permission := range grants[userID] {
+			loaded.permissions[permission] = true
+		}
+		return loaded, nil
+	}
+	return cache, &loads, &now
+}
+
+func TestPermissionCacheServesFromMemoryUntilExpiry(t *testing.T) {
+	cache, loads, now := newTestPermissionCache(
+		map[uint][]string{1: {"admin.access"}},
+		map[uint][]string{1: {"admin"}},
+	)
+
+	allowed, err := cache.HasPermission(nil, 1, "admin.access")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	isAdmin, err := cache.HasRole(nil, 1, "admin")
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+
+	allowed, _ = cache.HasPermission(nil, 1, "users.delete")
+	assert.False(t, allowed)
+	assert.Equal(t, 1, *loads)
+
+	*now = now.Add(time.Minute)
+	cache.HasPermission(nil, 1, "admin.access")
+	assert.Equal(t, 2, *loads)
+}
+
+func TestPermissionCacheInvalidate(t *testing.T) {
+	grants := map[uint][]string{1: {"admin.access"}, 2: {"users.read"}}
+	cache, loads, _ := newTestPermissionCache(grants, nil)
+
+	cache.HasPermission(nil, 1, "admin.access")
+	cache.HasPermission(nil, 2, "users.read")
+	assert.Equal(t, 2, *loads)
+
+	// Revoking a grant takes effect on the next check
+	grants[1] = nil
+	cache.Invalidate(1)
+	allowed, _ := cache.HasPermission(nil, 1, "admin.access")
+	assert.False(t, allowed)
+	assert.Equal(t, 3, *loads)
+
+	// Other users stay cached
+	cache.HasPermission(nil, 2, "users.read")
+	assert.Equal(t, 3, *loads)
+
+	cache.InvalidateAll()
+	cache.HasPermission(nil, 2, "users.read")
+	assert.Equal(t, 4, *loads)
+}
+
+func TestPermissionCacheDiscardsLoadRacingInvalidation(t *testing.T) {
+	cache, _, _ := newTestPermissionCache(nil, nil)
+	loads := 0
+	cache.load = func(db *gorm.DB, userID uint) (*userGrants, error) {
+		loads++
+		if loads == 1 {
+			// The user's roles change while their old grants are being read
+			cache.Invalidate(userID)
+		}
+		return &userGrants{roles: map[string]bool{}, permissions: map[string]bool{}}, nil
+	}
+
+	cache.HasRole(nil, 1, "admin")
+	cache.HasRole(nil, 1, "admin")
+	assert.Equal(t, 2, loads)
+}
+
+func TestPermissionCacheDoesNotCacheErrors(t *testing.T) {
+	cache, _, _ := newTestPermissionCache(nil, nil)
+	loads := 0
+	cache.load = func(db *gorm.DB, userID uint) (*userGrants, error) {
+		loads++
+		return nil, errors.New("connection refused")
+	}
+
+	_, err := cache.HasPermission(nil, 1, "admin.access")
+	assert.Error(t, err)
+	_, err = cache.HasPermission(nil, 1, "admin.access")
+	assert.Error(t, err)
+	assert.Equal(t, 2, loads)
+}
+
+func TestPermissionCacheForgetsFinishedLoads(t *testing.T) {
+	cache, _, _ := newTestPermissionCache(nil, nil)
+	for userID := uint(1); userID <= 3; userID++ {
+		cache.HasRole(nil, userID, "admin")
+		cache.Invalidate(userID)
+	}
+	cache.Invalidate(4)
+	assert.Empty(t, cache.pending, "only loads under way are tracked")
+}
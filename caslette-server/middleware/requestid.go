@@ -16,8 +16,10 @@ func RequestIDMiddleware() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 
-		// Set the request ID in the context
+		// Set the request ID in the context, and on the request itself so plain
+		// http.Handlers such as the WebSocket upgrade see the same ID
 		c.Set("request_id", requestID)
+		c.Request.Header.Set("X-Request-ID", requestID)
 
 		// Add the request ID to the response headers for debugging
 		c.Header("X-Request-ID", requestID)
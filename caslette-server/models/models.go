@@ -17,6 +17,7 @@ type User struct {
 	FirstName string         `json:"first_name"`
 	LastName  string         `json:"last_name"`
 	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	Region    string         `json:"region,omitempty" gorm:"size:16;index"` // Region detected at registration or last sign-in
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -25,6 +26,7 @@ type User struct {
 	Roles       []Role       `json:"roles" gorm:"many2many:user_roles;"`
 	Permissions []Permission `json:"permissions" gorm:"many2many:user_permissions;"`
 	Diamonds    []Diamond    `json:"diamonds" gorm:"foreignKey:UserID"`
+	Tags        []UserTag    `json:"tags,omitempty" gorm:"foreignKey:UserID"`
 }
 
 type Role struct {
@@ -175,3 +177,290 @@ type UserPermission struct {
 	UserID       uint `gorm:"primaryKey"`
 	PermissionID uint `gorm:"primaryKey"`
 }
+
+// HandDispute is a player-filed case about the outcome of a specific hand
+type HandDispute struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	UserID     uint           `json:"user_id" gorm:"not null;index"`
+	TableID    string         `json:"table_id" gorm:"not null;index"`
+	HandID     string         `json:"hand_id" gorm:"not null"`
+	Reason     string         `json:"reason" gorm:"not null"`
+	Status     string         `json:"status" gorm:"not null;default:'open';index"` // "open", "under_review", "resolved", "rejected"
+	Evidence   string         `json:"evidence" gorm:"type:json"`                   // Hand record captured when the dispute was filed
+	Resolution string         `json:"resolution"`
+	ReviewedBy *uint          `json:"reviewed_by,omitempty"`
+	ResolvedAt *time.Time     `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Optional ledger adjustment applied on resolution
+	AdjustmentAmount        int64  `json:"adjustment_amount"`
+	AdjustmentTransactionID string `json:"adjustment_transaction_id,omitempty"`
+
+	// Relationships
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// RakeRecord is a ledger entry for rake collected from a single pot
+type RakeRecord struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TableID    string    `json:"table_id" gorm:"not null;index"`
+	HandID     string    `json:"hand_id" gorm:"index"`
+	StakeLevel string    `json:"stake_level" gorm:"not null;index"` // e.g. "10/20"
+	Amount     int64     `json:"amount" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// UserTag is an admin-applied label used to segment users (e.g. "vip", "beta_tester")
+type UserTag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_tag"`
+	Tag       string    `json:"tag" gorm:"not null;size:32;uniqueIndex:idx_user_tag;index"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PlayMoneyAccount holds a user's practice-table balance, kept apart from the diamond ledger
+type PlayMoneyAccount struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	Balance      int64      `json:"balance" gorm:"not null;default:0"`
+	LastRefillAt *time.Time `json:"last_refill_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// BotToken is a user-minted API token for sanctioned bot play. Only a hash of
+// the token is stored; the plaintext is shown once when it is created.
+type BotToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Name        string     `json:"name" gorm:"not null;size:50"`
+	TokenHash   string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	TokenPrefix string     `json:"token_prefix" gorm:"not null;size:16"` // Leading characters shown to identify the token
+	TableIDs    string     `json:"table_ids" gorm:"type:json"`           // JSON array of whitelisted table IDs
+	GameTypes   string     `json:"game_types" gorm:"type:json"`          // JSON array of whitelisted game types
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	User        User       `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// LoginEvent records a sign-in attempt against a known account
+type LoginEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty" gorm:"size:64"` // Why a failed attempt was rejected
+	IPAddress string    `json:"ip_address" gorm:"size:45"`
+	Region    string    `json:"region,omitempty" gorm:"size:16"`
+	UserAgent string    `json:"user_agent" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// CosmeticItem is a purchasable deck or table theme in the diamond shop
+type CosmeticItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SKU       string    `json:"sku" gorm:"not null;size:64;uniqueIndex"`
+	Name      string    `json:"name" gorm:"not null;size:100"`
+	Kind      string    `json:"kind" gorm:"not null;size:32;index"`  // "deck" or "table"
+	Price     int64     `json:"price" gorm:"not null;default:0"`     // In diamonds
+	Metadata  string    `json:"metadata" gorm:"type:json"`           // Asset references for clients
+	Active    bool      `json:"active" gorm:"not null;default:true"` // Inactive items can no longer be bought
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserCosmetic is a cosmetic in a user's inventory
+type UserCosmetic struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	UserID        uint         `json:"user_id" gorm:"not null;uniqueIndex:idx_user_cosmetic"`
+	ItemID        uint         `json:"item_id" gorm:"not null;uniqueIndex:idx_user_cosmetic"`
+	Equipped      bool         `json:"equipped" gorm:"not null;default:false"`
+	TransactionID string       `json:"transaction_id,omitempty" gorm:"size:64"` // Diamond ledger entry that paid for it
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Item          CosmeticItem `json:"item" gorm:"foreignKey:ItemID"`
+}
+
+// UserPreference holds a user's gameplay preferences
+type UserPreference struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	HandStats bool      `json:"hand_stats" gorm:"not null"` // Push a private summary after each hand
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SystemStatus is the operational status admins publish on the status page.
+// A single row (ID 1) holds the current status so it survives restarts.
+type SystemStatus struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	MaintenanceWindows string    `json:"maintenance_windows" gorm:"type:json"` // Scheduled windows as JSON
+	DegradedFeatures   string    `json:"degraded_features" gorm:"type:json"`   // Feature names as JSON
+	IncidentMessage    string    `json:"incident_message" gorm:"size:500"`
+	UpdatedBy          uint      `json:"updated_by"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// AuditLog is a persisted security audit entry
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"size:64;index"`
+	TableID   string    `json:"table_id" gorm:"size:64;index"`
+	Action    string    `json:"action" gorm:"size:64;not null;index"`
+	Result    string    `json:"result" gorm:"size:64;not null;index"`
+	IPAddress string    `json:"ip_address,omitempty" gorm:"size:45"`
+	UserAgent string    `json:"user_agent,omitempty" gorm:"size:255"`
+	Details   string    `json:"details,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// PurgeRecord reports one run of a retention policy: which data was
+// purged, up to when, and how many records went
+type PurgeRecord struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Category    string    `json:"category" gorm:"size:32;not null;index"`
+	Cutoff      time.Time `json:"cutoff"` // Records older than this were purged
+	Purged      int64     `json:"purged"`
+	Error       string    `json:"error,omitempty" gorm:"size:255"`
+	TriggeredBy uint      `json:"triggered_by,omitempty"` // Admin who ran the purge; zero for the schedule
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// RateLimitState persists the tables a user has created and sits at, so
+// per-user table caps survive restarts
+type RateLimitState struct {
+	UserID        string    `json:"user_id" gorm:"primaryKey;size:64"`
+	CreatedTables string    `json:"created_tables" gorm:"type:json"` // JSON array of table IDs
+	ActiveTables  string    `json:"active_tables" gorm:"type:json"`  // JSON array of table IDs
+	LastActivity  time.Time `json:"last_activity"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// HandHistory is the persisted record of one completed hand
+type HandHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	HandID     string    `json:"hand_id" gorm:"size:64;not null;uniqueIndex"`
+	TableID    string    `json:"table_id" gorm:"size:64;not null;index"`
+	GameType   string    `json:"game_type" gorm:"size:32"`
+	HandNumber int       `json:"hand_number"`
+	TotalPot   int64     `json:"total_pot"`
+	Rake       int64     `json:"rake"`
+	Record     string    `json:"record" gorm:"type:json"` // Actions, board, pots and winners as JSON
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HandHistoryPlayer records a player dealt into a persisted hand, so a
+// player's hands can be found without reading every record
+type HandHistoryPlayer struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	HandID   string    `json:"hand_id" gorm:"size:64;not null;uniqueIndex:idx_hand_player"`
+	PlayerID string    `json:"player_id" gorm:"size:64;not null;uniqueIndex:idx_hand_player;index"`
+	Seat     int       `json:"seat"`
+	Net      int64     `json:"net"`
+	EndedAt  time.Time `json:"ended_at" gorm:"index"`
+}
+
+// HandEvent is one entry of a completed hand's append-only event log, kept
+// so the hand can be replayed
+type HandEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	HandID    string    `json:"hand_id" gorm:"size:64;not null;uniqueIndex:idx_hand_event"`
+	Position  int       `json:"position" gorm:"not null;uniqueIndex:idx_hand_event"` // Order within the hand
+	TableID   string    `json:"table_id" gorm:"size:64;not null;index"`
+	Type      string    `json:"type" gorm:"size:64;not null"`
+	Event     string    `json:"event" gorm:"type:json"` // The game event as JSON
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+}
+
+// PlayerStats are a player's lifetime poker results at tables of one currency
+type PlayerStats struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	PlayerID      string    `json:"player_id" gorm:"size:64;not null;uniqueIndex:idx_player_stats_currency"`
+	Currency      string    `json:"currency" gorm:"size:32;not null;uniqueIndex:idx_player_stats_currency"`
+	HandsPlayed   int64     `json:"hands_played"`
+	VPIPHands     int64     `json:"vpip_hands" gorm:"column:vpip_hands"`
+	PFRHands      int64     `json:"pfr_hands" gorm:"column:pfr_hands"`
+	Showdowns     int64     `json:"showdowns"`
+	ShowdownsWon  int64     `json:"showdowns_won"`
+	TotalWinnings int64     `json:"total_winnings"`
+	BiggestPot    int64     `json:"biggest_pot"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableSnapshot is the latest crash recovery snapshot of an open table
+type TableSnapshot struct {
+	TableID  string `json:"table_id" gorm:"primaryKey;size:64"`
+	GameType string `json:"game_type" gorm:"size:32"`
+	Table    string `json:"table" gorm:"type:json"`  // Table, seats and settings as JSON
+	Engine   string `json:"engine" gorm:"type:json"` // Engine state as JSON
+	Escrow   string `json:"escrow" gorm:"type:json"` // Buy-ins by player as JSON
+	// bcrypt hash of a private table's password
+	PasswordHash string    `json:"-" gorm:"size:100"`
+	SavedAt      time.Time `json:"saved_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CertificationEntry is one entry of the hash-chained log of game outcomes.
+// Each entry's hash covers its contents and the previous entry's hash, so
+// changing or removing an entry breaks every hash after it.
+type CertificationEntry struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	Sequence   int64     `json:"sequence" gorm:"not null;uniqueIndex"` // Position in the chain, from 1
+	Kind       string    `json:"kind" gorm:"size:32;not null;index"`
+	Subject    string    `json:"subject" gorm:"size:64;not null;index"` // Such as the hand ID
+	TableID    string    `json:"table_id" gorm:"size:64;index"`
+	Outcome    string    `json:"outcome" gorm:"type:json"` // The certified outcome as JSON
+	OccurredAt time.Time `json:"occurred_at"`
+	PrevHash   string    `json:"prev_hash" gorm:"size:64;not null"`
+	Hash       string    `json:"hash" gorm:"size:64;not null;uniqueIndex"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeUpdate refuses changes to a certification entry once written
+func (e *CertificationEntry) BeforeUpdate(tx *gorm.DB) error {
+	return fmt.Errorf("certification entries cannot be changed")
+}
+
+// BeforeDelete refuses to delete a certification entry
+func (e *CertificationEntry) BeforeDelete(tx *gorm.DB) error {
+	return fmt.Errorf("certification entries cannot be deleted")
+}
+
+// CollusionFlag is a suspicious pattern between two players awaiting or
+// after an admin's review
+type CollusionFlag struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Kind        string     `json:"kind" gorm:"size:32;not null;index"`
+	PlayerID    string     `json:"player_id" gorm:"size:64;not null;index"`   // The folder or loser for directed patterns
+	OpponentID  string     `json:"opponent_id" gorm:"size:64;not null;index"` // The bettor or winner for directed patterns
+	TableID     string     `json:"table_id" gorm:"size:64"`
+	HandIDs     string     `json:"hand_ids" gorm:"type:json"` // Evidence hand IDs as JSON
+	Hands       int        `json:"hands"`
+	Score       float64    `json:"score"`
+	Details     string     `json:"details"`
+	Status      string     `json:"status" gorm:"size:16;not null;default:'open';index"` // "open", "confirmed", "dismissed"
+	ReviewNotes string     `json:"review_notes,omitempty"`
+	ReviewedBy  *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableChatMessage is a line said in a table's chat, kept for moderators
+type TableChatMessage struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TableID   string    `json:"table_id" gorm:"size:64;not null;index"`
+	HandID    string    `json:"hand_id,omitempty" gorm:"size:64;index"` // Hand being played when it was said
+	PlayerID  string    `json:"player_id" gorm:"size:64;not null;index"`
+	Username  string    `json:"username" gorm:"size:100"`
+	Text      string    `json:"text" gorm:"size:1200;not null"`      // What the table saw
+	Original  string    `json:"original,omitempty" gorm:"size:1200"` // What was typed, when the filter masked it
+	Filtered  bool      `json:"filtered" gorm:"index"`
+	Observer  bool      `json:"observer"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
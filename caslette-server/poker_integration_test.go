@@ -301,8 +301,10 @@ func joinTable(t *testing.T, conn *websocket.Conn, tableID string, mode game.Tab
 		"type":       "table_join",
 		"request_id": generateRequestID(),
 		"data": map[string]interface{}{
-			"table_id": tableID,
-			"mode":     string(mode),
+			"table_id":   tableID,
+			"mode":       string(mode),
+			"nonce":      "join-" + generateRequestID(),
+			"expires_at": time.Now().Add(time.Minute).Unix(),
 		},
 	}
 
@@ -485,7 +487,7 @@ func listTables(t *testing.T, conn *websocket.Conn) []interface{} {
 	require.NoError(t, err)
 	require.True(t, response["success"].(bool))
 
-	return response["data"].([]interface{})
+	return response["data"].(map[string]interface{})["tables"].([]interface{})
 }
 
 func closeTable(t *testing.T, conn *websocket.Conn, tableID string) {
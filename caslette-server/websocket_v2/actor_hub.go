@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"html"
 	"log"
@@ -32,23 +33,73 @@ type ActorHub struct {
 	// Internal state (only accessed by the actor goroutine)
 	connections map[string]*Connection
 	rooms       map[string]map[string]*Connection
-	users       map[string]*Connection
+	users       map[string]map[string]*Connection // User ID -> connection ID -> connection
+	topics      map[string]map[string]*Connection // Server-published topics; unlike rooms, clients cannot join them directly
 
 	// Message handlers
 	messageHandlers map[string]MessageHandler
 	authHandler     AuthHandler
 
-	// Rate limiting
+	// Presence changes are delivered in order off the actor goroutine, so
+	// handlers may call back into the hub
+	presenceHandler atomic.Value // PresenceHandler
+	presenceEvents  chan presenceChange
+
+	// Rate limiting, with escalating penalties for repeat violators
 	rateLimiter *RateLimiter
+	penalties   *PenaltyTracker
+	exemptions  *RateLimitExemptions
+
+	// Per-room chat settings enforced before room messages are broadcast
+	chat *ChatControls
+
+	// Per-user outbox shared with long-poll clients
+	outbox *OutboxStore
+
+	// Resumable sessions of authenticated connections
+	sessions *SessionStore
+
+	// Workers fanning large broadcasts out off the actor; pooledAudiences
+	// are the rooms and topics currently going through them (actor only)
+	broadcasts      *BroadcastPool
+	pooledFanout    int
+	pooledAudiences map[string]bool
+
+	// Cluster backplane carrying broadcasts and presence between instances;
+	// remoteUsers is who is online elsewhere (actor only)
+	instanceID   string
+	backplane    atomic.Value // Backplane
+	backplaneOut chan backplaneEnvelope
+	remoteUsers  map[string]map[string]bool // User ID -> instance IDs
+
+	// Custom handler deadline (nanoseconds, read atomically) and circuit breaker
+	handlerTimeout int64
+	breaker        *CircuitBreaker
 
 	// Connection counter for unique IDs
 	connectionCounter int64
 
+	// Set once Shutdown begins; drainer is the ShutdownDrainer it runs
+	shuttingDown atomic.Bool
+	drainer      atomic.Value // ShutdownDrainer
+
+	// Connections dropped for missing their heartbeats
+	reaped atomic.Int64
+
+	// Whether a login replaces the user's other connections
+	sessionPolicy atomic.Value // SessionPolicy
+
 	// Context for graceful shutdown
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// presenceChange is a user coming online or going offline
+type presenceChange struct {
+	userID string
+	online bool
+}
+
 // RateLimiter tracks message rates per connection
 type RateLimiter struct {
 	connectionLimits map[string]*ConnectionLimit
@@ -60,16 +111,11 @@ type RateLimiter struct {
 type ConnectionLimit struct {
 	messageCount    int64
 	lastMessageTime time.Time
-	violations      int
-	blocked         bool
-	blockUntil      time.Time
 }
 
-// Rate limiting constants
+// Rate limiting constants; violations are penalised by the PenaltyTracker
 const (
 	MaxMessagesPerSecond = 10
-	MaxViolations        = 3
-	BlockDuration        = time.Minute * 5
 	CleanupInterval      = time.Minute * 10
 )
 
@@ -93,16 +139,33 @@ func NewActorHub() *ActorHub {
 		hubChannel:        make(chan HubMessage, 1000), // Buffered channel for performance
 		connections:       make(map[string]*Connection),
 		rooms:             make(map[string]map[string]*Connection),
-		users:             make(map[string]*Connection),
+		users:             make(map[string]map[string]*Connection),
+		topics:            make(map[string]map[string]*Connection),
 		messageHandlers:   make(map[string]MessageHandler),
+		presenceEvents:    make(chan presenceChange, 1000),
 		connectionCounter: 0,
 		ctx:               ctx,
 		cancel:            cancel,
 		rateLimiter:       newRateLimiter(),
+		penalties:         NewPenaltyTracker(),
+		exemptions:        NewRateLimitExemptions(),
+		chat:              NewChatControls(),
+		outbox:            NewOutboxStore(DefaultOutboxSize),
+		sessions:          NewSessionStore(DefaultResumeWindow, DefaultReplayBufferSize),
+		broadcasts:        NewBroadcastPool(DefaultBroadcastWorkers),
+		pooledFanout:      DefaultPooledFanout,
+		pooledAudiences:   make(map[string]bool),
+		instanceID:        newInstanceID(),
+		backplaneOut:      make(chan backplaneEnvelope, backplaneQueueSize),
+		remoteUsers:       make(map[string]map[string]bool),
+		handlerTimeout:    int64(DefaultHandlerTimeout),
+		breaker:           NewCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
 	}
 
 	// Start the actor goroutine
 	go hub.actorLoop()
+	go hub.presenceLoop()
+	go hub.reapLoop()
 
 	return hub
 }
@@ -137,6 +200,32 @@ func (h *ActorHub) actorLoop() {
 	}
 }
 
+// presenceLoop delivers presence changes to the presence handler
+func (h *ActorHub) presenceLoop() {
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case change := <-h.presenceEvents:
+			if handler, _ := h.presenceHandler.Load().(PresenceHandler); handler != nil {
+				handler(change.userID, change.online)
+			}
+		}
+	}
+}
+
+// notifyPresence queues a presence change (actor method)
+func (h *ActorHub) notifyPresence(userID string, online bool) {
+	if handler, _ := h.presenceHandler.Load().(PresenceHandler); handler == nil {
+		return
+	}
+	select {
+	case h.presenceEvents <- presenceChange{userID: userID, online: online}:
+	default:
+		log.Printf("ActorHub: Presence queue full, dropping change for user %s", userID)
+	}
+}
+
 // handleActorMessage processes a message sent to the actor
 func (h *ActorHub) handleActorMessage(msg HubMessage) {
 	log.Printf("ActorHub: handleActorMessage called with type: %s", msg.Type)
@@ -146,7 +235,7 @@ func (h *ActorHub) handleActorMessage(msg HubMessage) {
 	case "unregister":
 		h.actorUnregisterConnection(msg.Connection, msg.Response)
 	case "process_message":
-		log.Printf("ActorHub: About to call actorProcessMessage for connection %s", msg.Connection.ID)
+		msg.Connection.Logf("ActorHub: About to call actorProcessMessage for connection %s", msg.Connection.ID)
 		h.actorProcessMessage(msg.Connection, msg.Message, msg.Response)
 	case "join_room":
 		h.actorJoinRoom(msg.Connection.ID, msg.Room, msg.Response)
@@ -160,10 +249,26 @@ func (h *ActorHub) handleActorMessage(msg HubMessage) {
 		h.actorBroadcastToAll(msg.Message, msg.Response)
 	case "get_connection_count":
 		h.actorGetConnectionCount(msg.Response)
+	case "reap_dead_connections":
+		h.actorReapDeadConnections(msg.Response)
+	case "send_to_device":
+		h.actorSendToDevice(msg.UserID, msg.Room, msg.Message, msg.Response)
+	case "list_connections":
+		h.actorListConnections(msg.Response)
 	case "list_rooms":
 		h.actorListRooms(msg.Response)
+	case "subscribe_topic":
+		h.actorSubscribeTopic(msg.Connection.ID, msg.Room, msg.Response)
+	case "unsubscribe_topic":
+		h.actorUnsubscribeTopic(msg.Connection.ID, msg.Room, msg.Response)
+	case "publish_to_topic":
+		h.actorPublishToTopic(msg.Room, msg.Message, msg.Response)
+	case "get_stats":
+		h.actorGetStats(msg.Response)
 	case "check_rate_limit":
 		h.actorCheckRateLimit(msg.UserID, msg.Response)
+	case "backplane":
+		h.actorHandleBackplane(msg.Data.(backplaneEnvelope))
 	default:
 		log.Printf("ActorHub: Unknown message type: %s", msg.Type)
 		if msg.Response != nil {
@@ -281,7 +386,7 @@ func (h *ActorHub) Unregister(conn *Connection) {
 
 // ProcessMessage processes an incoming message
 func (h *ActorHub) ProcessMessage(conn *Connection, msg *Message) {
-	log.Printf("ActorHub: ProcessMessage called for connection %s, message type: %s", conn.ID, msg.Type)
+	conn.Logf("ActorHub: ProcessMessage called for connection %s, message type: %s", conn.ID, msg.Type)
 	response := make(chan interface{})
 	h.hubChannel <- HubMessage{
 		Type:       "process_message",
@@ -296,11 +401,6 @@ func (h *ActorHub) ProcessMessage(conn *Connection, msg *Message) {
 // JoinRoom adds a connection to a room
 func (h *ActorHub) JoinRoom(connectionID, room string) error {
 	response := make(chan interface{})
-	h.hubChannel <- HubMessage{
-		Type:     "join_room",
-		Room:     room,
-		Response: response,
-	}
 	h.hubChannel <- HubMessage{
 		Type:       "join_room",
 		Connection: &Connection{ID: connectionID},
@@ -345,9 +445,10 @@ func (h *ActorHub) BroadcastToRoom(room string, msg *Message) {
 	}
 	<-response // Wait for completion
 	close(response)
+	h.publish(backplaneEnvelope{Kind: backplaneRoom, Room: room, Message: msg})
 }
 
-// BroadcastToUser sends a message to a specific user
+// BroadcastToUser sends a message to every connection of a user
 func (h *ActorHub) BroadcastToUser(userID string, msg *Message) {
 	response := make(chan interface{})
 	h.hubChannel <- HubMessage{
@@ -358,6 +459,7 @@ func (h *ActorHub) BroadcastToUser(userID string, msg *Message) {
 	}
 	<-response // Wait for completion
 	close(response)
+	h.publish(backplaneEnvelope{Kind: backplaneUser, UserID: userID, Message: msg})
 }
 
 // BroadcastToAll sends a message to all connections
@@ -370,6 +472,94 @@ func (h *ActorHub) BroadcastToAll(msg *Message) {
 	}
 	<-response // Wait for completion
 	close(response)
+	h.publish(backplaneEnvelope{Kind: backplaneAll, Message: msg})
+}
+
+// SubscribeTopic adds a connection to a server-published topic. Callers are
+// responsible for authorizing the subscription.
+func (h *ActorHub) SubscribeTopic(connectionID, topic string) error {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:       "subscribe_topic",
+		Connection: &Connection{ID: connectionID},
+		Room:       topic,
+		Response:   response,
+	}
+	result := <-response
+	close(response)
+
+	if err, ok := result.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// UnsubscribeTopic removes a connection from a topic
+func (h *ActorHub) UnsubscribeTopic(connectionID, topic string) {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:       "unsubscribe_topic",
+		Connection: &Connection{ID: connectionID},
+		Room:       topic,
+		Response:   response,
+	}
+	<-response // Wait for completion
+	close(response)
+}
+
+// PublishToTopic sends a message to a topic's subscribers, returning how
+// many connections it was sent to. Topic messages are not kept in outboxes.
+func (h *ActorHub) PublishToTopic(topic string, msg *Message) int {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:     "publish_to_topic",
+		Room:     topic,
+		Message:  msg,
+		Response: response,
+	}
+	result := <-response
+	close(response)
+
+	if count, ok := result.(int); ok {
+		return count
+	}
+	return 0
+}
+
+// Stats returns a snapshot of the hub's connections, rooms and topics
+func (h *ActorHub) Stats() HubStats {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:     "get_stats",
+		Response: response,
+	}
+	result := <-response
+	close(response)
+
+	stats, _ := result.(HubStats)
+	stats.OpenCircuits = h.breaker.OpenCircuits()
+	stats.RateLimits = h.exemptions.Stats()
+	return stats
+}
+
+// Penalties returns the tracker escalating penalties for rate-limit violators
+func (h *ActorHub) Penalties() *PenaltyTracker {
+	return h.penalties
+}
+
+// RateLimitExemptions returns the system actors exempt from rate limits
+func (h *ActorHub) RateLimitExemptions() *RateLimitExemptions {
+	return h.exemptions
+}
+
+// ChatControls returns the per-room chat settings
+func (h *ActorHub) ChatControls() *ChatControls {
+	return h.chat
+}
+
+// MailboxDepth returns how many messages wait in the hub's channel
+func (h *ActorHub) MailboxDepth() int {
+	return len(h.hubChannel)
 }
 
 // GetConnectionCount returns the number of active connections
@@ -393,18 +583,56 @@ func (h *ActorHub) SetAuthHandler(handler AuthHandler) {
 	h.authHandler = handler
 }
 
+// SetPresenceHandler sets the hook told when users come online or go offline
+func (h *ActorHub) SetPresenceHandler(handler PresenceHandler) {
+	h.presenceHandler.Store(handler)
+}
+
+// SetHandlerTimeout sets how long custom handlers may run before the client
+// receives a timeout error
+func (h *ActorHub) SetHandlerTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		atomic.StoreInt64(&h.handlerTimeout, int64(timeout))
+	}
+}
+
+// HandlerBreaker returns the circuit breaker guarding custom handlers
+func (h *ActorHub) HandlerBreaker() *CircuitBreaker {
+	return h.breaker
+}
+
 // RegisterMessageHandler registers a message handler
 func (h *ActorHub) RegisterMessageHandler(messageType string, handler MessageHandler) {
 	h.messageHandlers[messageType] = handler
 }
 
+// Outbox returns the per-user outbox that user-addressed messages are recorded in
+func (h *ActorHub) Outbox() *OutboxStore {
+	return h.outbox
+}
+
+// Sessions returns the resumable sessions of authenticated connections
+func (h *ActorHub) Sessions() *SessionStore {
+	return h.sessions
+}
+
 // Start starts the hub (actor is already running)
 func (h *ActorHub) Start() {
 	// Actor is already started in NewActorHub
 	log.Printf("ActorHub: Hub is ready")
 }
 
-// Stop gracefully stops the hub
+// Stop gracefully stops the hub, telling the rest of the cluster its users
+// are gone from this instance
 func (h *ActorHub) Stop() {
+	if backplane := h.Backplane(); backplane != nil {
+		if payload, err := json.Marshal(backplaneEnvelope{Origin: h.instanceID, Kind: backplaneLeave}); err == nil {
+			if err := backplane.Publish(payload); err != nil {
+				log.Printf("ActorHub: Failed to announce shutdown: %v", err)
+			}
+		}
+		backplane.Close()
+	}
 	h.cancel()
+	h.broadcasts.Stop()
 }
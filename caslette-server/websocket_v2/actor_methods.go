@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,7 +15,7 @@ import (
 // actorRegisterConnection registers a connection (actor method)
 func (h *ActorHub) actorRegisterConnection(conn *Connection, response chan interface{}) {
 	h.connections[conn.ID] = conn
-	log.Printf("ActorHub: Connection %s registered", conn.ID)
+	conn.Logf("ActorHub: Connection %s registered", conn.ID)
 
 	// Send welcome message
 	welcome := &Message{
@@ -22,6 +23,7 @@ func (h *ActorHub) actorRegisterConnection(conn *Connection, response chan inter
 		Event: "welcome",
 		Data: map[string]interface{}{
 			"connectionID": conn.ID,
+			"traceID":      conn.TraceID,
 			"message":      "Connected to Caslette WebSocket server",
 		},
 	}
@@ -36,22 +38,35 @@ func (h *ActorHub) actorUnregisterConnection(conn *Connection, response chan int
 		// Remove from connections
 		delete(h.connections, conn.ID)
 
-		// Remove from user mapping
-		if conn.UserID != "" {
-			delete(h.users, conn.UserID)
+		// Tell the presence handler when the user's last connection goes
+		if conn.UserID != "" && h.actorRemoveUserConnection(conn) {
+			h.localPresence(conn.UserID, false)
 		}
 
+		// Keep the session, with its rooms, for a reconnect to resume
+		h.sessions.Detach(conn, conn.GetRooms())
+
 		// Remove from all rooms
 		for room := range conn.Rooms {
 			if h.rooms[room] != nil {
 				delete(h.rooms[room], conn.ID)
 				if len(h.rooms[room]) == 0 {
 					delete(h.rooms, room)
+					delete(h.pooledAudiences, roomAudience(room))
+					h.chat.RemoveRoom(room)
 				}
 			}
 		}
 
-		log.Printf("ActorHub: Connection %s (%s) unregistered", conn.ID, conn.Username)
+		// Remove from all topics
+		for topic, subscribers := range h.topics {
+			delete(subscribers, conn.ID)
+			if len(subscribers) == 0 {
+				delete(h.topics, topic)
+			}
+		}
+
+		conn.Logf("ActorHub: Connection %s (%s) unregistered", conn.ID, conn.Username)
 	}
 
 	response <- nil
@@ -59,15 +74,36 @@ func (h *ActorHub) actorUnregisterConnection(conn *Connection, response chan int
 
 // actorProcessMessage processes an incoming message (actor method)
 func (h *ActorHub) actorProcessMessage(conn *Connection, msg *Message, response chan interface{}) {
-	log.Printf("ActorHub: actorProcessMessage started for connection %s, message type: %s", conn.ID, msg.Type)
+	conn.Logf("ActorHub: actorProcessMessage started for connection %s, message type: %s", conn.ID, msg.Type)
+
+	// Banned violators are refused outright
+	subject := penaltySubject(conn)
+	if until, banned := h.penalties.BannedUntil(subject); banned {
+		err := fmt.Errorf("connection banned until %s due to repeated rate limit violations", until.UTC().Format(time.RFC3339))
+		conn.SendMessage(&Message{
+			Type:      "error",
+			RequestID: msg.RequestID,
+			Error:     err.Error(),
+			Success:   false,
+		})
+		conn.disconnectAfter(banDisconnectDelay)
+		response <- err
+		return
+	}
 
-	// Check rate limiting first - call actor method directly to avoid deadlock
-	log.Printf("ActorHub: About to check rate limit for connection %s", conn.ID)
+	// Check rate limiting first - call actor method directly to avoid deadlock.
+	// Exempt system actors skip it and are counted separately.
+	conn.Logf("ActorHub: About to check rate limit for connection %s", conn.ID)
 	rateLimitResponse := make(chan interface{}, 1)
-	h.actorCheckRateLimit(conn.ID, rateLimitResponse)
+	if h.exemptions.allow(conn.UserID) {
+		rateLimitResponse <- nil
+	} else {
+		h.actorCheckRateLimit(conn.ID, rateLimitResponse)
+	}
 	if rateLimitResult := <-rateLimitResponse; rateLimitResult != nil {
 		if err, ok := rateLimitResult.(error); ok {
-			log.Printf("ActorHub: Rate limit exceeded for connection %s: %v", conn.ID, err)
+			conn.Logf("ActorHub: Rate limit exceeded for connection %s: %v", conn.ID, err)
+			h.exemptions.recordLimited()
 			errorResponse := &Message{
 				Type:      "error",
 				RequestID: msg.RequestID,
@@ -75,14 +111,17 @@ func (h *ActorHub) actorProcessMessage(conn *Connection, msg *Message, response
 				Success:   false,
 			}
 			conn.SendMessage(errorResponse)
+			// A burst counts as one violation, not one per dropped message
+			if limitErr, ok := err.(*rateLimitError); ok && limitErr.firstInWindow {
+				h.actorPenalise(conn, subject)
+			}
 			response <- err
 			return
 		}
 	}
-	log.Printf("ActorHub: Rate limit check passed for connection %s", conn.ID)
+	conn.Logf("ActorHub: Rate limit check passed for connection %s", conn.ID)
 
-	ctx := context.Background()
-	log.Printf("ActorHub: Processing message type: %s from connection %s (UserID: %s)", msg.Type, conn.ID, conn.UserID)
+	conn.Logf("ActorHub: Processing message type: %s from connection %s (UserID: %s)", msg.Type, conn.ID, conn.UserID)
 
 	// Handle authentication messages
 	if msg.Type == "auth" {
@@ -90,6 +129,11 @@ func (h *ActorHub) actorProcessMessage(conn *Connection, msg *Message, response
 		response <- nil
 		return
 	}
+	if msg.Type == "resume" {
+		h.actorHandleResume(conn, msg)
+		response <- nil
+		return
+	}
 
 	// Handle built-in message types with input validation
 	switch msg.Type {
@@ -99,7 +143,7 @@ func (h *ActorHub) actorProcessMessage(conn *Connection, msg *Message, response
 		return
 
 	case "test_echo":
-		log.Printf("ActorHub: Received test_echo, sending test_echo_response")
+		conn.Logf("ActorHub: Received test_echo, sending test_echo_response")
 		echoResponse := &Message{
 			Type:      "test_echo_response",
 			RequestID: msg.RequestID,
@@ -133,16 +177,14 @@ func (h *ActorHub) actorProcessMessage(conn *Connection, msg *Message, response
 	default:
 		// Check for custom message handlers
 		if handler, exists := h.messageHandlers[msg.Type]; exists {
-			handlerResponse := handler(ctx, conn, msg)
-			if handlerResponse != nil {
-				conn.SendMessage(handlerResponse)
-			}
-			response <- nil
+			// Run off the actor loop so a slow handler cannot stall the hub.
+			// The reader still waits for completion, keeping per-connection order.
+			go h.runMessageHandler(conn, msg, handler, response)
 			return
 		}
 
 		// Unknown message type
-		log.Printf("ActorHub: Unknown message type: %s", msg.Type)
+		conn.Logf("ActorHub: Unknown message type: %s", msg.Type)
 		errorResponse := &Message{
 			Type:      "error",
 			RequestID: msg.RequestID,
@@ -154,12 +196,93 @@ func (h *ActorHub) actorProcessMessage(conn *Connection, msg *Message, response
 	}
 }
 
+// runMessageHandler runs a custom handler with a per-message deadline. Timeouts
+// and panics are reported to the client and counted by the circuit breaker;
+// a handler that keeps failing is rejected until its circuit cools down. The
+// connection's next message waits until the handler returns, even past its
+// deadline, so handlers must give up once their context is done.
+func (h *ActorHub) runMessageHandler(conn *Connection, msg *Message, handler MessageHandler, response chan interface{}) {
+	if allowed, retryAfter := h.breaker.Allow(msg.Type); !allowed {
+		conn.SendMessage(handlerFailureReply(msg, "HANDLER_UNAVAILABLE",
+			"Service temporarily unavailable, please retry later", map[string]interface{}{
+				"retry_after_ms": retryAfter.Milliseconds(),
+			}))
+		response <- fmt.Errorf("circuit open for %s", msg.Type)
+		return
+	}
+
+	timeout := time.Duration(atomic.LoadInt64(&h.handlerTimeout))
+	ctx, cancel := context.WithTimeout(WithTraceID(h.ctx, conn.TraceID), timeout)
+	defer cancel()
+
+	type handlerResult struct {
+		reply    *Message
+		panicked bool
+	}
+	done := make(chan handlerResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				conn.Logf("ActorHub: handler %s panicked: %v", msg.Type, r)
+				done <- handlerResult{panicked: true}
+			}
+		}()
+		done <- handlerResult{reply: handler(ctx, conn, msg)}
+	}()
+
+	select {
+	case result := <-done:
+		if result.panicked {
+			h.breaker.RecordFailure(msg.Type)
+			conn.SendMessage(handlerFailureReply(msg, "HANDLER_ERROR", "Internal error processing request", nil))
+			response <- fmt.Errorf("handler %s panicked", msg.Type)
+			return
+		}
+		h.breaker.RecordSuccess(msg.Type)
+		if result.reply != nil {
+			conn.SendMessage(result.reply)
+		}
+		response <- nil
+
+	case <-ctx.Done():
+		h.breaker.RecordFailure(msg.Type)
+		conn.Logf("ActorHub: handler %s timed out after %v for connection %s", msg.Type, timeout, conn.ID)
+		conn.SendMessage(handlerFailureReply(msg, "HANDLER_TIMEOUT", "Request timed out", map[string]interface{}{
+			"timeout_ms": timeout.Milliseconds(),
+		}))
+		// Whatever the handler still does lands before the connection's next
+		// message; the client was told it failed, so a late reply is dropped
+		if result := <-done; result.reply != nil {
+			conn.Logf("ActorHub: dropped the late reply of handler %s for connection %s", msg.Type, conn.ID)
+		}
+		response <- ctx.Err()
+	}
+}
+
+// handlerFailureReply builds the structured error sent when a handler cannot answer
+func handlerFailureReply(msg *Message, code, errMsg string, details map[string]interface{}) *Message {
+	data := map[string]interface{}{
+		"code":         code,
+		"message_type": msg.Type,
+	}
+	for key, value := range details {
+		data[key] = value
+	}
+	return &Message{
+		Type:      msg.Type + "_response",
+		RequestID: msg.RequestID,
+		Success:   false,
+		Error:     errMsg,
+		Data:      data,
+	}
+}
+
 // actorHandleAuth handles authentication (actor method)
 func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
-	log.Printf("ActorHub: handleAuth called for connection %s", conn.ID)
+	conn.Logf("ActorHub: handleAuth called for connection %s", conn.ID)
 
 	if h.authHandler == nil {
-		log.Printf("ActorHub: AuthHandler is nil")
+		conn.Logf("ActorHub: AuthHandler is nil")
 		response := &Message{
 			Type:      "auth_response",
 			RequestID: msg.RequestID,
@@ -170,13 +293,13 @@ func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
 		return
 	}
 
-	log.Printf("ActorHub: Received auth message data: %+v", msg.Data)
+	conn.Logf("ActorHub: Received auth message data: %+v", msg.Data)
 
 	var authMsg AuthMessage
 	if dataBytes, err := json.Marshal(msg.Data); err == nil {
-		log.Printf("ActorHub: Marshaled data: %s", string(dataBytes))
+		conn.Logf("ActorHub: Marshaled data: %s", string(dataBytes))
 		if err := json.Unmarshal(dataBytes, &authMsg); err != nil {
-			log.Printf("ActorHub: Failed to unmarshal auth message: %v", err)
+			conn.Logf("ActorHub: Failed to unmarshal auth message: %v", err)
 			response := &Message{
 				Type:      "auth_response",
 				RequestID: msg.RequestID,
@@ -187,7 +310,7 @@ func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
 			return
 		}
 	} else {
-		log.Printf("ActorHub: Failed to marshal message data: %v", err)
+		conn.Logf("ActorHub: Failed to marshal message data: %v", err)
 		response := &Message{
 			Type:      "auth_response",
 			RequestID: msg.RequestID,
@@ -198,11 +321,21 @@ func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
 		return
 	}
 
-	log.Printf("ActorHub: Extracted token: %s", authMsg.Token)
+	conn.Logf("ActorHub: Extracted token: %s", authMsg.Token)
+
+	if authMsg.Device != "" && !validDevice.MatchString(authMsg.Device) {
+		conn.SendMessage(&Message{
+			Type:      "auth_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Invalid device name",
+		})
+		return
+	}
 
 	authResult, err := h.authHandler(authMsg.Token)
 	if err != nil {
-		log.Printf("ActorHub: AuthHandler returned error: %v", err)
+		conn.Logf("ActorHub: AuthHandler returned error: %v", err)
 		response := &Message{
 			Type:      "auth_response",
 			RequestID: msg.RequestID,
@@ -213,13 +346,13 @@ func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
 		return
 	}
 
-	log.Printf("ActorHub: AuthHandler result: %+v", authResult)
+	conn.Logf("ActorHub: AuthHandler result: %+v", authResult)
 
 	if authResult.Success {
 		// Validate username
 		validatedUsername, err := validateInput(authResult.Username, "username")
 		if err != nil {
-			log.Printf("ActorHub: Invalid username: %v", err)
+			conn.Logf("ActorHub: Invalid username: %v", err)
 			response := &Message{
 				Type:      "auth_response",
 				RequestID: msg.RequestID,
@@ -230,25 +363,52 @@ func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
 			return
 		}
 
+		// Bans follow the account across connections
+		if until, banned := h.penalties.BannedUntil("user:" + authResult.UserID); banned {
+			conn.SendMessage(&Message{
+				Type:      "auth_response",
+				RequestID: msg.RequestID,
+				Success:   false,
+				Error:     "Account temporarily banned until " + until.UTC().Format(time.RFC3339) + " due to repeated rate limit violations",
+			})
+			conn.disconnectAfter(banDisconnectDelay)
+			return
+		}
+
 		// Update connection with user info
 		conn.UserID = authResult.UserID
 		conn.Username = validatedUsername
+		conn.Bot = authResult.Bot
+		conn.Device = authMsg.Device
 
-		// Add to user mapping
-		h.users[authResult.UserID] = conn
+		// Add to the user's connections; the user is back with the first
+		h.actorAddUserConnection(conn)
+
+		data := map[string]interface{}{
+			"userID":   authResult.UserID,
+			"username": validatedUsername,
+			"bot":      authResult.Bot != nil,
+			"device":   conn.Device,
+		}
+		// A client reconnecting within the window resumes with the token
+		if window := h.sessions.Window(); window > 0 {
+			if session, err := h.sessions.Open(conn); err != nil {
+				conn.Logf("ActorHub: Failed to open session for connection %s: %v", conn.ID, err)
+			} else {
+				data["sessionToken"] = session.Token
+				data["resumeWindowMs"] = window.Milliseconds()
+			}
+		}
 
 		response := &Message{
 			Type:      "auth_response",
 			RequestID: msg.RequestID,
 			Success:   true,
-			Data: map[string]interface{}{
-				"userID":   authResult.UserID,
-				"username": validatedUsername,
-			},
+			Data:      data,
 		}
 		conn.SendMessage(response)
 
-		log.Printf("ActorHub: User %s (%s) authenticated on connection %s", authResult.UserID, validatedUsername, conn.ID)
+		conn.Logf("ActorHub: User %s (%s) authenticated on connection %s", authResult.UserID, validatedUsername, conn.ID)
 	} else {
 		response := &Message{
 			Type:      "auth_response",
@@ -262,18 +422,26 @@ func (h *ActorHub) actorHandleAuth(conn *Connection, msg *Message) {
 
 // actorHandleLogout handles user logout (actor method)
 func (h *ActorHub) actorHandleLogout(conn *Connection, msg *Message) {
-	log.Printf("ActorHub: handleLogout called for connection %s (UserID: %s)", conn.ID, conn.UserID)
+	conn.Logf("ActorHub: handleLogout called for connection %s (UserID: %s)", conn.ID, conn.UserID)
 
 	// Clear user authentication
 	if conn.UserID != "" {
-		// Remove from user mapping
-		delete(h.users, conn.UserID)
-		log.Printf("ActorHub: Removed user %s from user mapping", conn.UserID)
+		// Remove from the user's connections; logging out the last one
+		// takes the user offline
+		if h.actorRemoveUserConnection(conn) {
+			h.localPresence(conn.UserID, false)
+		}
+		conn.Logf("ActorHub: Removed connection %s from user %s", conn.ID, conn.UserID)
 	}
 
+	// A logged out session cannot be resumed
+	h.sessions.Close(conn)
+
 	// Clear connection authentication info
 	conn.UserID = ""
 	conn.Username = ""
+	conn.Bot = nil
+	conn.Device = ""
 
 	// Send logout response
 	response := &Message{
@@ -284,17 +452,17 @@ func (h *ActorHub) actorHandleLogout(conn *Connection, msg *Message) {
 	}
 	conn.SendMessage(response)
 
-	log.Printf("ActorHub: User logged out from connection %s", conn.ID)
+	conn.Logf("ActorHub: User logged out from connection %s", conn.ID)
 }
 
 // actorHandleCreateRoom handles room creation (actor method)
 func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
-	log.Printf("ActorHub: handleCreateRoom called - msg.Data: %+v", msg.Data)
+	conn.Logf("ActorHub: handleCreateRoom called - msg.Data: %+v", msg.Data)
 
 	// Extract room name
 	roomData, ok := msg.Data.(map[string]interface{})
 	if !ok {
-		log.Printf("ActorHub: Invalid create_room message data format")
+		conn.Logf("ActorHub: Invalid create_room message data format")
 		response := &Message{
 			Type:      "create_room_response",
 			RequestID: msg.RequestID,
@@ -307,7 +475,7 @@ func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
 
 	roomName, ok := roomData["room"].(string)
 	if !ok {
-		log.Printf("ActorHub: Room name not provided or invalid type")
+		conn.Logf("ActorHub: Room name not provided or invalid type")
 		response := &Message{
 			Type:      "create_room_response",
 			RequestID: msg.RequestID,
@@ -318,12 +486,12 @@ func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
 		return
 	}
 
-	log.Printf("ActorHub: Extracted room name: '%s'", roomName)
+	conn.Logf("ActorHub: Extracted room name: '%s'", roomName)
 
 	// Validate and sanitize room name
 	validatedRoomName, err := validateInput(roomName, "room")
 	if err != nil {
-		log.Printf("ActorHub: Invalid room name: %v", err)
+		conn.Logf("ActorHub: Invalid room name: %v", err)
 		response := &Message{
 			Type:      "create_room_response",
 			RequestID: msg.RequestID,
@@ -336,7 +504,7 @@ func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
 
 	// Check if user is authenticated
 	if conn.UserID == "" {
-		log.Printf("ActorHub: User not authenticated, cannot create room")
+		conn.Logf("ActorHub: User not authenticated, cannot create room")
 		response := &Message{
 			Type:      "create_room_response",
 			RequestID: msg.RequestID,
@@ -347,11 +515,11 @@ func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
 		return
 	}
 
-	log.Printf("ActorHub: User authenticated (UserID: %s), proceeding with room creation", conn.UserID)
+	conn.Logf("ActorHub: User authenticated (UserID: %s), proceeding with room creation", conn.UserID)
 
 	// Check if room already exists
 	if _, exists := h.rooms[validatedRoomName]; exists {
-		log.Printf("ActorHub: Room '%s' already exists", validatedRoomName)
+		conn.Logf("ActorHub: Room '%s' already exists", validatedRoomName)
 		response := &Message{
 			Type:      "create_room_response",
 			RequestID: msg.RequestID,
@@ -364,7 +532,8 @@ func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
 
 	// Create the room
 	h.rooms[validatedRoomName] = make(map[string]*Connection)
-	log.Printf("ActorHub: Room created: %s by user %s", validatedRoomName, conn.UserID)
+	h.chat.SetOwner(validatedRoomName, conn.UserID)
+	conn.Logf("ActorHub: Room created: %s by user %s", validatedRoomName, conn.UserID)
 
 	// Send success response
 	response := &Message{
@@ -390,12 +559,12 @@ func (h *ActorHub) actorHandleCreateRoom(conn *Connection, msg *Message) {
 	}
 	h.actorBroadcastToAll(roomCreatedEvent, nil)
 
-	log.Printf("ActorHub: Room creation completed successfully")
+	conn.Logf("ActorHub: Room creation completed successfully")
 }
 
 // actorHandleJoinRoom handles joining a room (actor method)
 func (h *ActorHub) actorHandleJoinRoom(conn *Connection, msg *Message) {
-	log.Printf("ActorHub: handleJoinRoom called - msg.Data: %+v, RequestID: %s", msg.Data, msg.RequestID)
+	conn.Logf("ActorHub: handleJoinRoom called - msg.Data: %+v, RequestID: %s", msg.Data, msg.RequestID)
 
 	// Extract room name
 	roomData, ok := msg.Data.(map[string]interface{})
@@ -435,7 +604,31 @@ func (h *ActorHub) actorHandleJoinRoom(conn *Connection, msg *Message) {
 		return
 	}
 
-	log.Printf("ActorHub: About to join room '%s'", validatedRoomName)
+	// Visitors following the public lobby may not join rooms
+	if conn.UserID == "" {
+		response := &Message{
+			Type:      "join_room_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Authentication required to join room",
+		}
+		conn.SendMessage(response)
+		return
+	}
+
+	// Rooms managed outside the hub, such as table rooms, may turn users away
+	if err := h.chat.CanJoin(validatedRoomName, conn.UserID); err != nil {
+		response := &Message{
+			Type:      "join_room_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     err.Error(),
+		}
+		conn.SendMessage(response)
+		return
+	}
+
+	conn.Logf("ActorHub: About to join room '%s'", validatedRoomName)
 	h.actorJoinRoom(conn.ID, validatedRoomName, nil)
 
 	// Get room users for response
@@ -450,7 +643,7 @@ func (h *ActorHub) actorHandleJoinRoom(conn *Connection, msg *Message) {
 		}
 	}
 
-	log.Printf("ActorHub: Sending join_room_response: RequestID=%s, Success=true, Room=%s", msg.RequestID, validatedRoomName)
+	conn.Logf("ActorHub: Sending join_room_response: RequestID=%s, Success=true, Room=%s", msg.RequestID, validatedRoomName)
 	response := &Message{
 		Type:      "join_room_response",
 		RequestID: msg.RequestID,
@@ -461,7 +654,7 @@ func (h *ActorHub) actorHandleJoinRoom(conn *Connection, msg *Message) {
 		},
 	}
 	conn.SendMessage(response)
-	log.Printf("ActorHub: Response sent for RequestID=%s", msg.RequestID)
+	conn.Logf("ActorHub: Response sent for RequestID=%s", msg.RequestID)
 }
 
 // actorHandleLeaveRoom handles leaving a room (actor method)
@@ -518,7 +711,7 @@ func (h *ActorHub) actorHandleLeaveRoom(conn *Connection, msg *Message) {
 
 // actorHandleListRooms handles listing rooms (actor method)
 func (h *ActorHub) actorHandleListRooms(conn *Connection, msg *Message) {
-	log.Printf("ActorHub: handleListRooms called from connection %s", conn.ID)
+	conn.Logf("ActorHub: handleListRooms called from connection %s", conn.ID)
 
 	roomList := []map[string]interface{}{}
 	for roomName, roomConnections := range h.rooms {
@@ -576,7 +769,7 @@ func (h *ActorHub) actorJoinRoom(connectionID, room string, response chan interf
 	h.rooms[validatedRoom][connectionID] = conn
 	conn.Rooms[validatedRoom] = true
 
-	log.Printf("ActorHub: Connection %s (%s) joined room %s", connectionID, conn.Username, validatedRoom)
+	conn.Logf("ActorHub: Connection %s (%s) joined room %s", connectionID, conn.Username, validatedRoom)
 
 	// Notify other users in the room
 	userJoinedEvent := &Message{
@@ -591,9 +784,8 @@ func (h *ActorHub) actorJoinRoom(connectionID, room string, response chan interf
 	}
 
 	// Send to all connections in the room
-	for _, roomConn := range h.rooms[validatedRoom] {
-		roomConn.SendMessage(userJoinedEvent)
-	}
+	userJoinedEvent.Timestamp = time.Now().Unix()
+	h.actorFanout(roomAudience(validatedRoom), h.roomConnections(validatedRoom), userJoinedEvent)
 
 	if response != nil {
 		response <- nil
@@ -625,9 +817,11 @@ func (h *ActorHub) actorLeaveRoom(connectionID, room string, response chan inter
 
 		if len(h.rooms[validatedRoom]) == 0 {
 			delete(h.rooms, validatedRoom)
+			delete(h.pooledAudiences, roomAudience(validatedRoom))
+			h.chat.RemoveRoom(validatedRoom)
 		}
 
-		log.Printf("ActorHub: Connection %s (%s) left room %s", connectionID, conn.Username, validatedRoom)
+		conn.Logf("ActorHub: Connection %s (%s) left room %s", connectionID, conn.Username, validatedRoom)
 
 		// Notify other users in the room
 		if len(h.rooms[validatedRoom]) > 0 {
@@ -642,9 +836,8 @@ func (h *ActorHub) actorLeaveRoom(connectionID, room string, response chan inter
 				},
 			}
 
-			for _, roomConn := range h.rooms[validatedRoom] {
-				roomConn.SendMessage(userLeftEvent)
-			}
+			userLeftEvent.Timestamp = time.Now().Unix()
+			h.actorFanout(roomAudience(validatedRoom), h.roomConnections(validatedRoom), userLeftEvent)
 		}
 	}
 
@@ -657,16 +850,23 @@ func (h *ActorHub) actorLeaveRoom(connectionID, room string, response chan inter
 
 // actorBroadcastToRoom broadcasts to all connections in a room (actor method)
 func (h *ActorHub) actorBroadcastToRoom(room string, msg *Message, response chan interface{}) {
-	roomConnections, exists := h.rooms[room]
-	if !exists {
-		if response != nil {
-			response <- nil
+	// Stamped once here, since fan-out workers and long-poll readers share it
+	msg.Timestamp = time.Now().Unix()
+	conns := h.roomConnections(room)
+	h.actorFanout(roomAudience(room), conns, msg)
+
+	// Record the message once per user, including long-poll followers of the room
+	recipients := make(map[string]bool)
+	for _, conn := range conns {
+		if conn.UserID != "" {
+			recipients[conn.UserID] = true
 		}
-		return
 	}
-
-	for _, conn := range roomConnections {
-		conn.SendMessage(msg)
+	for _, userID := range h.outbox.RoomSubscribers(room) {
+		recipients[userID] = true
+	}
+	for userID := range recipients {
+		h.outbox.Append(userID, msg)
 	}
 
 	if response != nil {
@@ -676,8 +876,9 @@ func (h *ActorHub) actorBroadcastToRoom(room string, msg *Message, response chan
 
 // actorBroadcastToUser broadcasts to a specific user (actor method)
 func (h *ActorHub) actorBroadcastToUser(userID string, msg *Message, response chan interface{}) {
-	conn, exists := h.users[userID]
-	if exists {
+	msg.Timestamp = time.Now().Unix()
+	h.outbox.Append(userID, msg)
+	for _, conn := range h.users[userID] {
 		conn.SendMessage(msg)
 	}
 
@@ -686,10 +887,24 @@ func (h *ActorHub) actorBroadcastToUser(userID string, msg *Message, response ch
 	}
 }
 
-// actorBroadcastToAll broadcasts to all authenticated connections (actor method)
+// actorBroadcastToAll broadcasts to all authenticated connections and
+// long-poll clients (actor method)
 func (h *ActorHub) actorBroadcastToAll(msg *Message, response chan interface{}) {
-	for _, conn := range h.users {
-		conn.SendMessage(msg)
+	msg.Timestamp = time.Now().Unix()
+	conns := make([]*Connection, 0, len(h.users))
+	recipients := make(map[string]bool)
+	for userID, userConns := range h.users {
+		for _, conn := range userConns {
+			conns = append(conns, conn)
+		}
+		recipients[userID] = true
+	}
+	h.actorFanout(allAudience, conns, msg)
+	for _, userID := range h.outbox.ActiveUsers() {
+		recipients[userID] = true
+	}
+	for userID := range recipients {
+		h.outbox.Append(userID, msg)
 	}
 
 	if response != nil {
@@ -697,6 +912,60 @@ func (h *ActorHub) actorBroadcastToAll(msg *Message, response chan interface{})
 	}
 }
 
+// actorSubscribeTopic adds a connection to a topic (actor method)
+func (h *ActorHub) actorSubscribeTopic(connectionID, topic string, response chan interface{}) {
+	conn, exists := h.connections[connectionID]
+	if !exists {
+		response <- fmt.Errorf("connection not found")
+		return
+	}
+
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[string]*Connection)
+	}
+	h.topics[topic][connectionID] = conn
+	response <- nil
+}
+
+// actorUnsubscribeTopic removes a connection from a topic (actor method)
+func (h *ActorHub) actorUnsubscribeTopic(connectionID, topic string, response chan interface{}) {
+	if subscribers := h.topics[topic]; subscribers != nil {
+		delete(subscribers, connectionID)
+		if len(subscribers) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	response <- nil
+}
+
+// actorPublishToTopic sends a message to a topic's subscribers (actor method)
+func (h *ActorHub) actorPublishToTopic(topic string, msg *Message, response chan interface{}) {
+	msg.Timestamp = time.Now().Unix()
+	conns := make([]*Connection, 0, len(h.topics[topic]))
+	for _, conn := range h.topics[topic] {
+		conns = append(conns, conn)
+	}
+	h.actorFanout(topicAudience(topic), conns, msg)
+	response <- len(conns)
+}
+
+// actorGetStats returns connection, room and topic totals (actor method)
+func (h *ActorHub) actorGetStats(response chan interface{}) {
+	stats := HubStats{
+		Connections:        len(h.connections),
+		AuthenticatedUsers: len(h.users),
+		Rooms:              len(h.rooms),
+		TopicSubscribers:   make(map[string]int, len(h.topics)),
+	}
+	for _, members := range h.rooms {
+		stats.RoomMembers += len(members)
+	}
+	for topic, subscribers := range h.topics {
+		stats.TopicSubscribers[topic] = len(subscribers)
+	}
+	response <- stats
+}
+
 // actorGetConnectionCount returns connection count (actor method)
 func (h *ActorHub) actorGetConnectionCount(response chan interface{}) {
 	response <- len(h.connections)
@@ -721,42 +990,18 @@ func (h *ActorHub) actorCheckRateLimit(connectionID string, response chan interf
 		h.rateLimiter.connectionLimits[connectionID] = &ConnectionLimit{
 			messageCount:    1,
 			lastMessageTime: now,
-			violations:      0,
-			blocked:         false,
 		}
 		response <- nil
 		return
 	}
 
-	// Check if connection is currently blocked
-	if limit.blocked && now.Before(limit.blockUntil) {
-		response <- fmt.Errorf("connection temporarily blocked due to rate limiting")
-		return
-	}
-
-	// Reset block status if block period has expired
-	if limit.blocked && now.After(limit.blockUntil) {
-		limit.blocked = false
-		limit.violations = 0
-		limit.messageCount = 0
-	}
-
 	// Check rate limiting
 	timeSinceLastMessage := now.Sub(limit.lastMessageTime)
 	if timeSinceLastMessage < time.Second {
 		limit.messageCount++
 		if limit.messageCount > MaxMessagesPerSecond {
-			limit.violations++
-			log.Printf("Rate limit violation for connection %s (violation %d)", connectionID, limit.violations)
-
-			if limit.violations >= MaxViolations {
-				limit.blocked = true
-				limit.blockUntil = now.Add(BlockDuration)
-				response <- fmt.Errorf("connection blocked for %v due to repeated rate limit violations", BlockDuration)
-				return
-			}
-
-			response <- fmt.Errorf("rate limit exceeded: max %d messages per second", MaxMessagesPerSecond)
+			log.Printf("Rate limit violation for connection %s", connectionID)
+			response <- &rateLimitError{firstInWindow: limit.messageCount == MaxMessagesPerSecond+1}
 			return
 		}
 	} else {
@@ -768,3 +1013,58 @@ func (h *ActorHub) actorCheckRateLimit(connectionID string, response chan interf
 	limit.lastMessageTime = now
 	response <- nil
 }
+
+// rateLimitError reports a message over the per-second limit
+type rateLimitError struct {
+	firstInWindow bool // The first excess message of this window
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded: max %d messages per second", MaxMessagesPerSecond)
+}
+
+// actorPenalise records a rate-limit violation and tells the client when
+// its penalty escalates; bans also drop the connection (actor method)
+func (h *ActorHub) actorPenalise(conn *Connection, subject string) {
+	penalty, escalated := h.penalties.RecordViolation(subject, conn.UserID)
+	if !escalated {
+		return
+	}
+
+	conn.Logf("ActorHub: %s escalated to %s after %d rate limit violations", subject, penalty.Level, penalty.Violations)
+	notice := map[string]interface{}{
+		"level":      penalty.Level,
+		"violations": penalty.Violations,
+	}
+	if !penalty.MutedUntil.IsZero() {
+		notice["muted_until"] = penalty.MutedUntil.UTC().Format(time.RFC3339)
+	}
+	if !penalty.BannedUntil.IsZero() {
+		notice["banned_until"] = penalty.BannedUntil.UTC().Format(time.RFC3339)
+	}
+	conn.SendMessage(&Message{
+		Type:    "rate_limit_penalty",
+		Success: false,
+		Error:   penaltyNoticeText(penalty.Level),
+		Data:    notice,
+	})
+
+	if penalty.Level == PenaltyBan || penalty.Level == PenaltyFlag {
+		conn.disconnectAfter(banDisconnectDelay)
+	}
+}
+
+// penaltyNoticeText explains a penalty level to the client
+func penaltyNoticeText(level PenaltyLevel) string {
+	switch level {
+	case PenaltyWarning:
+		return "You are sending messages too quickly; further violations will mute chat"
+	case PenaltyMute:
+		return "Chat muted due to repeated rate limit violations"
+	case PenaltyBan:
+		return "Connection temporarily banned due to repeated rate limit violations"
+	case PenaltyFlag:
+		return "Connection banned and account flagged for review due to repeated rate limit violations"
+	}
+	return "Rate limit penalty applied"
+}
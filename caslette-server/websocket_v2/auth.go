@@ -2,7 +2,6 @@ package websocket_v2
 
 import (
 	"caslette-server/auth"
-	"context"
 	"errors"
 	"log"
 	"strconv"
@@ -61,21 +60,6 @@ func CreateWebSocketAuthHandler(authService *auth.AuthService) AuthHandler {
 	}
 }
 
-// RequireAuth is a middleware that ensures a connection is authenticated
-func RequireAuth(handler MessageHandler) MessageHandler {
-	return func(ctx context.Context, conn *Connection, msg *Message) *Message {
-		if conn.UserID == "" {
-			return &Message{
-				Type:      "error",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Authentication required",
-			}
-		}
-		return handler(ctx, conn, msg)
-	}
-}
-
 // extractRoomFromMessage extracts room information from a message
 func extractRoomFromMessage(msg *Message) string {
 	if msg.Room != "" {
@@ -0,0 +1,267 @@
+package websocket_v2
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+)
+
+// backplaneQueueSize is how many outgoing backplane envelopes may wait to
+// be published before new ones are dropped
+const backplaneQueueSize = 1000
+
+// Backplane relays hub traffic between the server instances of a cluster.
+// Every instance publishes what its local connections cannot see on their
+// own and receives what the others publish, its own envelopes included.
+type Backplane interface {
+	Publish(payload []byte) error
+	Subscribe(handler func(payload []byte)) error
+	Close() error
+}
+
+// Backplane envelope kinds
+const (
+	backplaneRoom         = "room"          // Room broadcast
+	backplaneUser         = "user"          // Message to one user
+	backplaneDevice       = "device"        // Message to one user's connections on a device
+	backplaneSession      = "session"       // The user logged in at the origin under the single session policy
+	backplaneAll          = "all"           // Message to every user
+	backplanePresence     = "presence"      // A user came online or went offline at the origin
+	backplanePresenceSync = "presence_sync" // A new instance asks who is online
+	backplaneLeave        = "leave"         // The origin is shutting down
+)
+
+// backplaneEnvelope is one message between hub instances
+type backplaneEnvelope struct {
+	Origin  string   `json:"origin"`
+	Kind    string   `json:"kind"`
+	Room    string   `json:"room,omitempty"`
+	UserID  string   `json:"user_id,omitempty"`
+	Device  string   `json:"device,omitempty"`
+	Online  bool     `json:"online,omitempty"`
+	Message *Message `json:"message,omitempty"`
+}
+
+// newInstanceID returns a random ID telling this hub's envelopes apart
+func newInstanceID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// SetBackplane connects the hub to the other instances of a cluster, so room
+// broadcasts, user messages and presence reach connections held elsewhere.
+// It is set once, before connections are served.
+func (h *ActorHub) SetBackplane(backplane Backplane) error {
+	if backplane == nil {
+		return errors.New("backplane is required")
+	}
+	if !h.backplane.CompareAndSwap(nil, backplane) {
+		return errors.New("backplane already set")
+	}
+
+	if err := backplane.Subscribe(h.receiveFromBackplane); err != nil {
+		return err
+	}
+	go h.backplaneLoop(backplane)
+
+	// Learn who is already online on the other instances
+	h.publish(backplaneEnvelope{Kind: backplanePresenceSync})
+	return nil
+}
+
+// Backplane returns the cluster backplane, or nil for a standalone hub
+func (h *ActorHub) Backplane() Backplane {
+	if backplane, ok := h.backplane.Load().(Backplane); ok {
+		return backplane
+	}
+	return nil
+}
+
+// publish queues an envelope for the other instances without blocking
+func (h *ActorHub) publish(envelope backplaneEnvelope) {
+	if h.Backplane() == nil {
+		return
+	}
+	envelope.Origin = h.instanceID
+	if envelope.Message != nil {
+		// Each receiving connection numbers the message itself
+		relayed := *envelope.Message
+		relayed.Seq = 0
+		envelope.Message = &relayed
+	}
+	select {
+	case h.backplaneOut <- envelope:
+	default:
+		log.Printf("ActorHub: Backplane queue full, dropping %s envelope", envelope.Kind)
+	}
+}
+
+// backplaneLoop publishes queued envelopes in order
+func (h *ActorHub) backplaneLoop(backplane Backplane) {
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case envelope := <-h.backplaneOut:
+			payload, err := json.Marshal(envelope)
+			if err != nil {
+				log.Printf("ActorHub: Failed to encode %s envelope: %v", envelope.Kind, err)
+				continue
+			}
+			if err := backplane.Publish(payload); err != nil {
+				log.Printf("ActorHub: Failed to publish %s envelope: %v", envelope.Kind, err)
+			}
+		}
+	}
+}
+
+// receiveFromBackplane hands another instance's envelope to the actor
+func (h *ActorHub) receiveFromBackplane(payload []byte) {
+	var envelope backplaneEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		log.Printf("ActorHub: Ignoring malformed backplane envelope: %v", err)
+		return
+	}
+	if envelope.Origin == h.instanceID {
+		return
+	}
+
+	select {
+	case h.hubChannel <- HubMessage{Type: "backplane", Data: envelope}:
+	case <-h.ctx.Done():
+	}
+}
+
+// actorHandleBackplane delivers another instance's envelope to the local
+// connections it concerns (actor method)
+func (h *ActorHub) actorHandleBackplane(envelope backplaneEnvelope) {
+	switch envelope.Kind {
+	case backplaneRoom:
+		if envelope.Message != nil {
+			h.actorBroadcastToRoom(envelope.Room, envelope.Message, nil)
+		}
+	case backplaneUser:
+		_, connected := h.users[envelope.UserID]
+		if envelope.Message != nil && (connected || h.outbox.Has(envelope.UserID)) {
+			h.actorBroadcastToUser(envelope.UserID, envelope.Message, nil)
+		}
+	case backplaneDevice:
+		if envelope.Message != nil {
+			h.actorSendToDevice(envelope.UserID, envelope.Device, envelope.Message, nil)
+		}
+	case backplaneSession:
+		if h.SessionPolicy() == SessionsSingle {
+			h.actorReplaceSessions(envelope.UserID, nil)
+		}
+	case backplaneAll:
+		if envelope.Message != nil {
+			h.actorBroadcastToAll(envelope.Message, nil)
+		}
+	case backplanePresence:
+		h.setRemotePresence(envelope.Origin, envelope.UserID, envelope.Online)
+	case backplanePresenceSync:
+		for userID := range h.users {
+			h.publish(backplaneEnvelope{Kind: backplanePresence, UserID: userID, Online: true})
+		}
+	case backplaneLeave:
+		for userID, origins := range h.remoteUsers {
+			if origins[envelope.Origin] {
+				h.setRemotePresence(envelope.Origin, userID, false)
+			}
+		}
+	}
+}
+
+// clusterOnline reports whether the user has a connection on any instance
+// (actor method)
+func (h *ActorHub) clusterOnline(userID string) bool {
+	_, local := h.users[userID]
+	return local || len(h.remoteUsers[userID]) > 0
+}
+
+// setRemotePresence records a user coming online or going offline on another
+// instance, telling the presence handler when that changes whether they are
+// online anywhere (actor method)
+func (h *ActorHub) setRemotePresence(origin, userID string, online bool) {
+	if userID == "" {
+		return
+	}
+	wasOnline := h.clusterOnline(userID)
+	if online {
+		if h.remoteUsers[userID] == nil {
+			h.remoteUsers[userID] = make(map[string]bool)
+		}
+		h.remoteUsers[userID][origin] = true
+	} else {
+		delete(h.remoteUsers[userID], origin)
+		if len(h.remoteUsers[userID]) == 0 {
+			delete(h.remoteUsers, userID)
+		}
+	}
+	if isOnline := h.clusterOnline(userID); isOnline != wasOnline {
+		h.notifyPresence(userID, isOnline)
+	}
+}
+
+// localPresence tells the cluster that a user's first local connection
+// authenticated or their last one went away, and the presence handler when
+// they are not online on another instance (actor method)
+func (h *ActorHub) localPresence(userID string, online bool) {
+	h.publish(backplaneEnvelope{Kind: backplanePresence, UserID: userID, Online: online})
+	if len(h.remoteUsers[userID]) == 0 {
+		h.notifyPresence(userID, online)
+	}
+}
+
+// MemoryBus is an in-process backplane joining hubs in one process, for
+// tests and for running several hubs side by side
+type MemoryBus struct {
+	mu       sync.RWMutex
+	handlers map[int]func(payload []byte)
+	nextID   int
+}
+
+// NewMemoryBus creates an empty in-process bus
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{handlers: make(map[int]func(payload []byte))}
+}
+
+// Join returns a backplane attached to the bus
+func (b *MemoryBus) Join() Backplane {
+	return &memoryBackplane{bus: b, id: -1}
+}
+
+// memoryBackplane is one hub's attachment to a MemoryBus
+type memoryBackplane struct {
+	bus *MemoryBus
+	id  int
+}
+
+func (m *memoryBackplane) Publish(payload []byte) error {
+	m.bus.mu.RLock()
+	defer m.bus.mu.RUnlock()
+	for _, handler := range m.bus.handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+func (m *memoryBackplane) Subscribe(handler func(payload []byte)) error {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	m.id = m.bus.nextID
+	m.bus.nextID++
+	m.bus.handlers[m.id] = handler
+	return nil
+}
+
+func (m *memoryBackplane) Close() error {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	delete(m.bus.handlers, m.id)
+	return nil
+}
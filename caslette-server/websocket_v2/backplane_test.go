@@ -0,0 +1,185 @@
+package websocket_v2
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClusterHub creates a hub on the bus authenticating every token as the
+// given user
+func newClusterHub(t *testing.T, bus *MemoryBus, userID string) (*ActorHub, *presenceLog) {
+	hub := NewActorHub()
+	t.Cleanup(hub.Stop)
+	hub.SetAuthHandler(func(token string) (*AuthResult, error) {
+		return &AuthResult{Success: true, UserID: userID, Username: "player" + userID}, nil
+	})
+	presence := &presenceLog{}
+	hub.SetPresenceHandler(presence.record)
+	require.NoError(t, hub.SetBackplane(bus.Join()))
+	return hub, presence
+}
+
+func TestBackplaneRelaysBroadcastsBetweenHubs(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, _ := newClusterHub(t, bus, "7")
+	hubB, _ := newClusterHub(t, bus, "8")
+
+	member := authenticate(hubA, "c1")
+	readReplyOfType(t, member, "auth_response")
+	hubA.ProcessMessage(member, &Message{Type: "join_room", Data: map[string]interface{}{"room": "lounge"}})
+	readReplyOfType(t, member, "join_room_response")
+	other := authenticate(hubB, "c2")
+	readReplyOfType(t, other, "auth_response")
+
+	hubB.BroadcastToRoom("lounge", &Message{Type: "room_news", Room: "lounge"})
+	assert.Equal(t, "lounge", readReplyOfType(t, member, "room_news").Room)
+
+	hubB.BroadcastToUser("7", &Message{Type: "notice", Data: "hello"})
+	assert.Equal(t, "hello", readReplyOfType(t, member, "notice").Data)
+
+	hubA.BroadcastToAll(&Message{Type: "announcement"})
+	readReplyOfType(t, member, "announcement")
+	readReplyOfType(t, other, "announcement")
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, member.Send, "a hub does not receive its own broadcasts back")
+	assert.Empty(t, other.Send, "nothing addressed to user 7 or the lounge reaches user 8")
+}
+
+func TestBackplaneReportsPresenceOnceAcrossTheCluster(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, presenceA := newClusterHub(t, bus, "7")
+	hubB, presenceB := newClusterHub(t, bus, "7")
+
+	first := authenticate(hubA, "c1")
+	assert.Equal(t, []string{"7:true"}, presenceA.waitFor(t, 1))
+	assert.Equal(t, []string{"7:true"}, presenceB.waitFor(t, 1), "other instances learn the user is online")
+
+	second := authenticate(hubB, "c2")
+	readReplyOfType(t, second, "auth_response")
+	time.Sleep(20 * time.Millisecond) // Let the second arrival reach hub A
+	hubA.Unregister(first)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, presenceA.waitFor(t, 1), 1, "the user is still online through the other instance")
+	assert.Len(t, presenceB.waitFor(t, 1), 1)
+
+	hubB.Unregister(second)
+	assert.Equal(t, []string{"7:true", "7:false"}, presenceA.waitFor(t, 2))
+	assert.Equal(t, []string{"7:true", "7:false"}, presenceB.waitFor(t, 2))
+}
+
+func TestBackplaneDropsUsersOfAStoppedInstance(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, presenceA := newClusterHub(t, bus, "7")
+	hubB := NewActorHub()
+	hubB.SetAuthHandler(func(token string) (*AuthResult, error) {
+		return &AuthResult{Success: true, UserID: "8", Username: "player8"}, nil
+	})
+	require.NoError(t, hubB.SetBackplane(bus.Join()))
+
+	authenticate(hubB, "c1")
+	assert.Equal(t, []string{"8:true"}, presenceA.waitFor(t, 1))
+
+	hubB.Stop()
+	assert.Equal(t, []string{"8:true", "8:false"}, presenceA.waitFor(t, 2))
+
+	// A hub joining later learns who is already online
+	authenticate(hubA, "c2")
+	_, presenceC := newClusterHub(t, bus, "9")
+	assert.Equal(t, []string{"7:true"}, presenceC.waitFor(t, 1))
+}
+
+func TestSetBackplaneOnlyOnce(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	bus := NewMemoryBus()
+
+	assert.Error(t, hub.SetBackplane(nil))
+	require.NoError(t, hub.SetBackplane(bus.Join()))
+	assert.Error(t, hub.SetBackplane(bus.Join()))
+}
+
+// fakeRedis serves AUTH, SUBSCRIBE and PUBLISH, relaying published payloads
+// to every subscribed connection as channel messages
+func fakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	var subscribers []net.Conn
+	bulk := func(s string) string { return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n" }
+
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		rc := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+		for {
+			reply, err := rc.read()
+			args, _ := reply.([]interface{})
+			if err != nil || len(args) < 2 {
+				return
+			}
+			arg := func(i int) string { return args[i].(string) }
+			mu.Lock()
+			switch strings.ToUpper(arg(0)) {
+			case "AUTH":
+				if arg(1) == "hunter2" {
+					conn.Write([]byte("+OK\r\n"))
+				} else {
+					conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+				}
+			case "SUBSCRIBE":
+				conn.Write([]byte("*3\r\n" + bulk("subscribe") + bulk(arg(1)) + ":1\r\n"))
+				subscribers = append(subscribers, conn)
+			case "PUBLISH":
+				for _, subscriber := range subscribers {
+					subscriber.Write([]byte("*3\r\n" + bulk("message") + bulk(arg(1)) + bulk(arg(2))))
+				}
+				conn.Write([]byte(":" + strconv.Itoa(len(subscribers)) + "\r\n"))
+			}
+			mu.Unlock()
+		}
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestRedisBackplanePublishesThroughPubSub(t *testing.T) {
+	addr := fakeRedis(t)
+
+	_, err := NewRedisBackplane(RedisConfig{})
+	assert.Error(t, err, "an address is required")
+
+	wrong, err := NewRedisBackplane(RedisConfig{Addr: addr, Password: "wrong"})
+	require.NoError(t, err)
+	assert.Error(t, wrong.Subscribe(func([]byte) {}))
+
+	backplane, err := NewRedisBackplane(RedisConfig{Addr: addr, Password: "hunter2"})
+	require.NoError(t, err)
+	defer backplane.Close()
+	received := make(chan string, 1)
+	require.NoError(t, backplane.Subscribe(func(payload []byte) { received <- string(payload) }))
+
+	require.NoError(t, backplane.Publish([]byte(`{"kind":"room"}`)))
+	select {
+	case payload := <-received:
+		assert.Equal(t, `{"kind":"room"}`, payload)
+	case <-time.After(time.Second):
+		t.Fatal("published payload not received")
+	}
+}
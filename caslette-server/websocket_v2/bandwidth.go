@@ -0,0 +1,339 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BandwidthTier groups users that share a bandwidth budget
+type BandwidthTier string
+
+// Bandwidth tiers
+const (
+	BandwidthTierStandard   BandwidthTier = "standard"
+	BandwidthTierTrusted    BandwidthTier = "trusted"
+	BandwidthTierRestricted BandwidthTier = "restricted"
+)
+
+// BandwidthWindow is the accounting window budgets are measured over
+const BandwidthWindow = time.Minute
+
+// BandwidthBudget limits the bytes a connection may move per window. A zero
+// limit is unlimited. Inbound overruns throttle (drop) client messages;
+// outbound overruns downgrade verbosity by dropping verbose events.
+type BandwidthBudget struct {
+	InboundBytesPerWindow  int64 `json:"inbound_bytes_per_window"`
+	OutboundBytesPerWindow int64 `json:"outbound_bytes_per_window"`
+}
+
+// defaultBandwidthBudgets are the budgets applied until overridden
+var defaultBandwidthBudgets = map[BandwidthTier]BandwidthBudget{
+	BandwidthTierStandard:   {InboundBytesPerWindow: 256 * 1024, OutboundBytesPerWindow: 4 * 1024 * 1024},
+	BandwidthTierTrusted:    {},
+	BandwidthTierRestricted: {InboundBytesPerWindow: 32 * 1024, OutboundBytesPerWindow: 512 * 1024},
+}
+
+// defaultVerboseEvents are the message types dropped first when a connection
+// exceeds its outbound budget; responses and errors are always delivered
+var defaultVerboseEvents = []string{"room_message", "user_joined_room", "user_left_room", "room_created"}
+
+// ConnectionBandwidth is the accounting snapshot for one connection
+type ConnectionBandwidth struct {
+	ConnectionID string        `json:"connection_id"`
+	UserID       string        `json:"user_id,omitempty"`
+	Tier         BandwidthTier `json:"tier"`
+	BytesIn      int64         `json:"bytes_in"`
+	BytesOut     int64         `json:"bytes_out"`
+	MessagesIn   int64         `json:"messages_in"`
+	MessagesOut  int64         `json:"messages_out"`
+	DroppedIn    int64         `json:"dropped_in"`
+	DroppedOut   int64         `json:"dropped_out"`
+	Throttled    bool          `json:"throttled"`
+	Downgraded   bool          `json:"downgraded"`
+	ConnectedAt  time.Time     `json:"connected_at"`
+
+	Compressed    bool  `json:"compressed"`     // permessage-deflate was negotiated
+	CompressedOut int64 `json:"compressed_out"` // Messages sent compressed
+	WireBytesOut  int64 `json:"wire_bytes_out"` // Bytes written to the socket, frame headers included
+}
+
+// UserBandwidth is the cumulative accounting for one user across connections
+type UserBandwidth struct {
+	UserID      string        `json:"user_id"`
+	Tier        BandwidthTier `json:"tier"`
+	Connections int           `json:"connections"`
+	BytesIn     int64         `json:"bytes_in"`
+	BytesOut    int64         `json:"bytes_out"`
+	DroppedIn   int64         `json:"dropped_in"`
+	DroppedOut  int64         `json:"dropped_out"`
+}
+
+// CompressionUsage totals outbound traffic of the live connections that
+// negotiated compression; BytesOut against WireBytesOut is the saving
+type CompressionUsage struct {
+	Connections   int   `json:"connections"`
+	BytesOut      int64 `json:"bytes_out"`
+	WireBytesOut  int64 `json:"wire_bytes_out"`
+	CompressedOut int64 `json:"compressed_out"`
+}
+
+// BandwidthDiagnostics is the admin view of bandwidth usage
+type BandwidthDiagnostics struct {
+	Budgets     map[BandwidthTier]BandwidthBudget `json:"budgets"`
+	Connections []ConnectionBandwidth             `json:"connections"`
+	Users       []UserBandwidth                   `json:"users"`
+	Compression CompressionUsage                  `json:"compression"`
+}
+
+// connectionUsage tracks a live connection's counters and current window
+type connectionUsage struct {
+	stats       ConnectionBandwidth
+	windowStart time.Time
+	windowIn    int64
+	windowOut   int64
+}
+
+// BandwidthMonitor tracks bytes in and out per connection and per user and
+// enforces per-tier budgets. It is safe for concurrent use from the read and
+// write paths of every connection.
+type BandwidthMonitor struct {
+	mu            sync.Mutex
+	budgets       map[BandwidthTier]BandwidthBudget
+	userTiers     map[string]BandwidthTier
+	verboseEvents map[string]bool
+	connections   map[*Connection]*connectionUsage
+	users         map[string]*UserBandwidth
+	now           func() time.Time
+}
+
+// NewBandwidthMonitor creates a monitor with the default tier budgets
+func NewBandwidthMonitor() *BandwidthMonitor {
+	m := &BandwidthMonitor{
+		budgets:       make(map[BandwidthTier]BandwidthBudget, len(defaultBandwidthBudgets)),
+		userTiers:     make(map[string]BandwidthTier),
+		verboseEvents: make(map[string]bool, len(defaultVerboseEvents)),
+		connections:   make(map[*Connection]*connectionUsage),
+		users:         make(map[string]*UserBandwidth),
+		now:           time.Now,
+	}
+	for tier, budget := range defaultBandwidthBudgets {
+		m.budgets[tier] = budget
+	}
+	for _, eventType := range defaultVerboseEvents {
+		m.verboseEvents[eventType] = true
+	}
+	return m
+}
+
+// SetBudget sets the budget for a tier, creating the tier if needed
+func (m *BandwidthMonitor) SetBudget(tier BandwidthTier, budget BandwidthBudget) error {
+	if tier == "" {
+		return fmt.Errorf("tier name is required")
+	}
+	if budget.InboundBytesPerWindow < 0 || budget.OutboundBytesPerWindow < 0 {
+		return fmt.Errorf("budgets cannot be negative")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budgets[tier] = budget
+	return nil
+}
+
+// SetUserTier moves a user to a tier; the standard tier clears the override
+func (m *BandwidthMonitor) SetUserTier(userID string, tier BandwidthTier) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.budgets[tier]; !ok {
+		return fmt.Errorf("unknown bandwidth tier: %s", tier)
+	}
+	if tier == BandwidthTierStandard {
+		delete(m.userTiers, userID)
+	} else {
+		m.userTiers[userID] = tier
+	}
+	return nil
+}
+
+// SetVerboseEvents replaces the message types dropped when downgrading
+func (m *BandwidthMonitor) SetVerboseEvents(eventTypes []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verboseEvents = make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		m.verboseEvents[eventType] = true
+	}
+}
+
+// RecordInbound accounts a message read from the client and reports whether
+// it may be processed. Bytes are always counted since they already arrived.
+// The second result is true the first time a window throttles the connection,
+// so the caller can notify the client once rather than per message.
+func (m *BandwidthMonitor) RecordInbound(conn *Connection, size int) (allowed bool, firstThrottle bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := m.usageLocked(conn)
+	user := m.userLocked(conn.UserID)
+	budget := m.budgets[usage.stats.Tier]
+
+	usage.stats.BytesIn += int64(size)
+	usage.windowIn += int64(size)
+	if user != nil {
+		user.BytesIn += int64(size)
+	}
+
+	if budget.InboundBytesPerWindow > 0 && usage.windowIn > budget.InboundBytesPerWindow {
+		firstThrottle = !usage.stats.Throttled
+		usage.stats.Throttled = true
+		usage.stats.DroppedIn++
+		if user != nil {
+			user.DroppedIn++
+		}
+		return false, firstThrottle
+	}
+
+	usage.stats.MessagesIn++
+	return true, false
+}
+
+// RecordOutbound accounts a message about to be queued for the client and
+// reports whether it should be sent. Over budget, verbose events are dropped
+// while responses (messages carrying a request ID) and errors still go out.
+func (m *BandwidthMonitor) RecordOutbound(conn *Connection, msg *Message, size int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := m.usageLocked(conn)
+	user := m.userLocked(conn.UserID)
+	budget := m.budgets[usage.stats.Tier]
+
+	overBudget := budget.OutboundBytesPerWindow > 0 && usage.windowOut+int64(size) > budget.OutboundBytesPerWindow
+	if overBudget {
+		usage.stats.Downgraded = true
+		if msg.RequestID == "" && m.verboseEvents[msg.Type] {
+			usage.stats.DroppedOut++
+			if user != nil {
+				user.DroppedOut++
+			}
+			return false
+		}
+	}
+
+	usage.stats.BytesOut += int64(size)
+	usage.stats.MessagesOut++
+	if conn.compressesFrame(size) {
+		usage.stats.CompressedOut++
+	}
+	usage.windowOut += int64(size)
+	if user != nil {
+		user.BytesOut += int64(size)
+	}
+	return true
+}
+
+// Remove stops tracking a closed connection; user totals are kept
+func (m *BandwidthMonitor) Remove(conn *Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.connections, conn)
+}
+
+// Diagnostics returns a snapshot of budgets and usage sorted by bytes out
+func (m *BandwidthMonitor) Diagnostics() BandwidthDiagnostics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	diagnostics := BandwidthDiagnostics{
+		Budgets:     make(map[BandwidthTier]BandwidthBudget, len(m.budgets)),
+		Connections: make([]ConnectionBandwidth, 0, len(m.connections)),
+		Users:       make([]UserBandwidth, 0, len(m.users)),
+	}
+	for tier, budget := range m.budgets {
+		diagnostics.Budgets[tier] = budget
+	}
+
+	active := make(map[string]int)
+	for conn, usage := range m.connections {
+		m.rollWindowLocked(conn, usage)
+		diagnostics.Connections = append(diagnostics.Connections, usage.stats)
+		if usage.stats.UserID != "" {
+			active[usage.stats.UserID]++
+		}
+		if usage.stats.Compressed {
+			diagnostics.Compression.Connections++
+			diagnostics.Compression.BytesOut += usage.stats.BytesOut
+			diagnostics.Compression.WireBytesOut += usage.stats.WireBytesOut
+			diagnostics.Compression.CompressedOut += usage.stats.CompressedOut
+		}
+	}
+	for userID, user := range m.users {
+		snapshot := *user
+		snapshot.Tier = m.tierLocked(userID)
+		snapshot.Connections = active[userID]
+		diagnostics.Users = append(diagnostics.Users, snapshot)
+	}
+
+	sort.Slice(diagnostics.Connections, func(i, j int) bool {
+		return diagnostics.Connections[i].BytesOut > diagnostics.Connections[j].BytesOut
+	})
+	sort.Slice(diagnostics.Users, func(i, j int) bool {
+		return diagnostics.Users[i].BytesOut > diagnostics.Users[j].BytesOut
+	})
+	return diagnostics
+}
+
+// usageLocked returns the connection's usage with its window rolled forward
+func (m *BandwidthMonitor) usageLocked(conn *Connection) *connectionUsage {
+	usage, ok := m.connections[conn]
+	if !ok {
+		now := m.now()
+		usage = &connectionUsage{
+			stats:       ConnectionBandwidth{ConnectedAt: now},
+			windowStart: now,
+		}
+		m.connections[conn] = usage
+	}
+	m.rollWindowLocked(conn, usage)
+	return usage
+}
+
+// rollWindowLocked resets window counters once the window elapses and picks
+// up identity changes such as authentication or a tier reassignment
+func (m *BandwidthMonitor) rollWindowLocked(conn *Connection, usage *connectionUsage) {
+	usage.stats.ConnectionID = conn.ID
+	usage.stats.UserID = conn.UserID
+	usage.stats.Tier = m.tierLocked(conn.UserID)
+	usage.stats.Compressed = conn.compressed
+	usage.stats.WireBytesOut = conn.wireBytesOut.Load()
+
+	if now := m.now(); now.Sub(usage.windowStart) >= BandwidthWindow {
+		usage.windowStart = now
+		usage.windowIn = 0
+		usage.windowOut = 0
+		usage.stats.Throttled = false
+		usage.stats.Downgraded = false
+	}
+}
+
+// userLocked returns the cumulative record for an authenticated user
+func (m *BandwidthMonitor) userLocked(userID string) *UserBandwidth {
+	if userID == "" {
+		return nil
+	}
+	user, ok := m.users[userID]
+	if !ok {
+		user = &UserBandwidth{UserID: userID}
+		m.users[userID] = user
+	}
+	return user
+}
+
+// tierLocked resolves a user's tier; anonymous connections are standard
+func (m *BandwidthMonitor) tierLocked(userID string) BandwidthTier {
+	if tier, ok := m.userTiers[userID]; ok {
+		return tier
+	}
+	return BandwidthTierStandard
+}
@@ -0,0 +1,105 @@
+package websocket_v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBandwidthMonitor(now *time.Time) *BandwidthMonitor {
+	m := NewBandwidthMonitor()
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func TestBandwidthMonitorAccountsPerConnectionAndUser(t *testing.T) {
+	now := time.Now()
+	m := newTestBandwidthMonitor(&now)
+
+	first := &Connection{ID: "c1", UserID: "7"}
+	second := &Connection{ID: "c2", UserID: "7"}
+
+	allowed, _ := m.RecordInbound(first, 100)
+	assert.True(t, allowed)
+	assert.True(t, m.RecordOutbound(first, &Message{Type: "auth_response"}, 300))
+	assert.True(t, m.RecordOutbound(second, &Message{Type: "room_message"}, 50))
+
+	diagnostics := m.Diagnostics()
+	require.Len(t, diagnostics.Connections, 2)
+	assert.Equal(t, "c1", diagnostics.Connections[0].ConnectionID)
+	assert.Equal(t, int64(100), diagnostics.Connections[0].BytesIn)
+	assert.Equal(t, int64(300), diagnostics.Connections[0].BytesOut)
+
+	require.Len(t, diagnostics.Users, 1)
+	assert.Equal(t, int64(350), diagnostics.Users[0].BytesOut)
+	assert.Equal(t, 2, diagnostics.Users[0].Connections)
+
+	// User totals survive disconnects
+	m.Remove(first)
+	m.Remove(second)
+	diagnostics = m.Diagnostics()
+	assert.Empty(t, diagnostics.Connections)
+	assert.Equal(t, int64(100), diagnostics.Users[0].BytesIn)
+	assert.Zero(t, diagnostics.Users[0].Connections)
+}
+
+func TestBandwidthMonitorThrottlesInbound(t *testing.T) {
+	now := time.Now()
+	m := newTestBandwidthMonitor(&now)
+	require.NoError(t, m.SetBudget(BandwidthTierRestricted, BandwidthBudget{InboundBytesPerWindow: 1000}))
+	require.NoError(t, m.SetUserTier("9", BandwidthTierRestricted))
+
+	conn := &Connection{ID: "c1", UserID: "9"}
+	allowed, _ := m.RecordInbound(conn, 900)
+	assert.True(t, allowed)
+
+	allowed, first := m.RecordInbound(conn, 200)
+	assert.False(t, allowed)
+	assert.True(t, first)
+
+	allowed, first = m.RecordInbound(conn, 10)
+	assert.False(t, allowed)
+	assert.False(t, first, "clients are notified once per window")
+
+	// A new window restores the budget
+	now = now.Add(BandwidthWindow)
+	allowed, _ = m.RecordInbound(conn, 10)
+	assert.True(t, allowed)
+
+	stats := m.Diagnostics().Connections[0]
+	assert.Equal(t, int64(2), stats.DroppedIn)
+	assert.False(t, stats.Throttled)
+	assert.Equal(t, BandwidthTierRestricted, stats.Tier)
+}
+
+func TestBandwidthMonitorDowngradesVerboseEvents(t *testing.T) {
+	now := time.Now()
+	m := newTestBandwidthMonitor(&now)
+	require.NoError(t, m.SetBudget(BandwidthTierStandard, BandwidthBudget{OutboundBytesPerWindow: 500}))
+
+	conn := &Connection{ID: "c1", UserID: "3"}
+	assert.True(t, m.RecordOutbound(conn, &Message{Type: "room_message"}, 400))
+
+	// Over budget: verbose events are dropped, responses and errors are not
+	assert.False(t, m.RecordOutbound(conn, &Message{Type: "room_message"}, 200))
+	assert.True(t, m.RecordOutbound(conn, &Message{Type: "poker_action_response", RequestID: "r1"}, 200))
+	assert.True(t, m.RecordOutbound(conn, &Message{Type: "error"}, 50))
+
+	stats := m.Diagnostics().Connections[0]
+	assert.True(t, stats.Downgraded)
+	assert.Equal(t, int64(1), stats.DroppedOut)
+	assert.Equal(t, int64(650), stats.BytesOut)
+}
+
+func TestBandwidthMonitorTierValidation(t *testing.T) {
+	m := NewBandwidthMonitor()
+	assert.Error(t, m.SetUserTier("1", BandwidthTier("gold")))
+	assert.Error(t, m.SetBudget("", BandwidthBudget{}))
+	assert.Error(t, m.SetBudget(BandwidthTierStandard, BandwidthBudget{InboundBytesPerWindow: -1}))
+
+	require.NoError(t, m.SetUserTier("1", BandwidthTierTrusted))
+	require.NoError(t, m.SetUserTier("1", BandwidthTierStandard))
+	assert.Empty(t, m.userTiers)
+}
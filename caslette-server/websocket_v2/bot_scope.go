@@ -0,0 +1,45 @@
+package websocket_v2
+
+// BotTokenPrefix marks bot API tokens so they can be told apart from JWTs
+const BotTokenPrefix = "cbt_"
+
+// botRateLimitBudgets replaces the class budgets for bot connections. Bots
+// also get a budget on the default class, which humans do not.
+var botRateLimitBudgets = map[RateLimitClass]int{
+	RateLimitDefault: 5,
+	RateLimitRead:    5,
+	RateLimitWrite:   2,
+	RateLimitStrict:  1,
+}
+
+// BotScope restricts what a connection authenticated with a bot token may do
+type BotScope struct {
+	TokenID   uint     `json:"token_id"`
+	TableIDs  []string `json:"table_ids,omitempty"`
+	GameTypes []string `json:"game_types,omitempty"`
+}
+
+// AllowsTable reports whether the scope covers a table by ID or game type
+func (s *BotScope) AllowsTable(tableID, gameType string) bool {
+	if s == nil {
+		return false
+	}
+	for _, id := range s.TableIDs {
+		if id == tableID {
+			return true
+		}
+	}
+	for _, allowed := range s.GameTypes {
+		if allowed == gameType {
+			return true
+		}
+	}
+	return false
+}
+
+// BotTokenValidator authenticates a bot token; results must carry a Bot scope
+type BotTokenValidator func(token string) (*AuthResult, error)
+
+// BotAccessChecker decides whether a bot connection may send a message to a
+// handler that allows bots, e.g. by checking the addressed table
+type BotAccessChecker func(conn *Connection, msg *Message) error
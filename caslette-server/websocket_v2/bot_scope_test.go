@@ -0,0 +1,107 @@
+package websocket_v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotScopeAllowsTable(t *testing.T) {
+	scope := &BotScope{TableIDs: []string{"table_1"}, GameTypes: []string{"texas_holdem"}}
+
+	assert.True(t, scope.AllowsTable("table_1", "omaha"), "whitelisted table")
+	assert.True(t, scope.AllowsTable("table_2", "texas_holdem"), "whitelisted game type")
+	assert.False(t, scope.AllowsTable("table_2", "omaha"))
+
+	var nilScope *BotScope
+	assert.False(t, nilScope.AllowsTable("table_1", "texas_holdem"))
+}
+
+func TestHandlerRegistryDeniesBotsByDefault(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "human_only", RequireAuth: true, Handler: okHandler}))
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "bot_ok", RequireAuth: true, AllowBots: true, Handler: okHandler}))
+
+	bot := &Connection{ID: "c1", UserID: "7", Bot: &BotScope{TokenID: 1}}
+
+	resp := registry.Wrap("human_only")(context.Background(), bot, &Message{Type: "human_only"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "Bot tokens cannot use this handler", resp.Error)
+
+	resp = registry.Wrap("bot_ok")(context.Background(), bot, &Message{Type: "bot_ok"})
+	assert.True(t, resp.Success)
+
+	// Human connections are unaffected by AllowBots
+	resp = registry.Wrap("human_only")(context.Background(), &Connection{ID: "c2", UserID: "8"}, &Message{Type: "human_only"})
+	assert.True(t, resp.Success)
+}
+
+func TestHandlerRegistryRunsBotAccessChecker(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "bot_ok", RequireAuth: true, AllowBots: true, Handler: okHandler}))
+	handler := registry.Wrap("bot_ok")
+
+	calls := 0
+	registry.SetBotAccessChecker(func(conn *Connection, msg *Message) error {
+		calls++
+		return errors.New("bots are not allowed at this table")
+	})
+
+	resp := handler(context.Background(), &Connection{ID: "c1", UserID: "7", Bot: &BotScope{}}, &Message{Type: "bot_ok"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "bots are not allowed at this table", resp.Error)
+
+	resp = handler(context.Background(), &Connection{ID: "c2", UserID: "8"}, &Message{Type: "bot_ok"})
+	assert.True(t, resp.Success)
+	assert.Equal(t, 1, calls, "checker only runs for bot connections")
+}
+
+func TestHandlerRegistryAppliesBotBudgets(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{
+		Name: "act", RequireAuth: true, AllowBots: true, RateLimitClass: RateLimitWrite, Handler: okHandler,
+	}))
+	handler := registry.Wrap("act")
+
+	bot := &Connection{ID: "bot", UserID: "7", Bot: &BotScope{}}
+	for i := 0; i < botRateLimitBudgets[RateLimitWrite]; i++ {
+		assert.True(t, handler(context.Background(), bot, &Message{Type: "act"}).Success)
+	}
+	assert.False(t, handler(context.Background(), bot, &Message{Type: "act"}).Success, "bots get the stricter write budget")
+
+	human := &Connection{ID: "human", UserID: "8"}
+	for i := 0; i < rateLimitClassBudgets[RateLimitWrite]; i++ {
+		assert.True(t, handler(context.Background(), human, &Message{Type: "act"}).Success)
+	}
+}
+
+func TestServerAuthenticateRoutesBotTokens(t *testing.T) {
+	server := &Server{
+		jwtAuth: func(token string) (*AuthResult, error) {
+			return &AuthResult{UserID: "1", Success: true}, nil
+		},
+	}
+
+	_, err := server.authenticate(BotTokenPrefix + "abc")
+	assert.Error(t, err, "bot tokens are rejected until a validator is set")
+
+	server.SetBotTokenValidator(func(token string) (*AuthResult, error) {
+		return &AuthResult{UserID: "2", Success: true}, nil
+	})
+	_, err = server.authenticate(BotTokenPrefix + "abc")
+	assert.Error(t, err, "a bot result without a scope is rejected")
+
+	server.SetBotTokenValidator(func(token string) (*AuthResult, error) {
+		return &AuthResult{UserID: "2", Success: true, Bot: &BotScope{TokenID: 3}}, nil
+	})
+	result, err := server.authenticate(BotTokenPrefix + "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), result.Bot.TokenID)
+
+	result, err = server.authenticate("jwt.token.value")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", result.UserID)
+	assert.Nil(t, result.Bot)
+}
@@ -0,0 +1,143 @@
+package websocket_v2
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// Broadcast pool defaults
+const (
+	DefaultBroadcastWorkers = 8  // Goroutines fanning out large broadcasts
+	DefaultPooledFanout     = 64 // Audiences at least this large are fanned out by the pool
+	broadcastQueueSize      = 256
+)
+
+// broadcastJob is one message for the connections a worker owns
+type broadcastJob struct {
+	msg   *Message
+	conns []*Connection
+}
+
+// BroadcastPool fans messages out to large audiences off the hub's actor
+// goroutine. Each connection belongs to one worker, so messages reach it in
+// the order they were dispatched.
+type BroadcastPool struct {
+	workers  []chan broadcastJob
+	pending  atomic.Int64 // Jobs dispatched and not yet delivered
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// deliver sends one message to one connection; tests slow it down
+	deliver func(conn *Connection, msg *Message)
+}
+
+// NewBroadcastPool starts a pool with the given number of workers
+func NewBroadcastPool(workers int) *BroadcastPool {
+	if workers <= 0 {
+		workers = DefaultBroadcastWorkers
+	}
+	p := &BroadcastPool{
+		workers: make([]chan broadcastJob, workers),
+		done:    make(chan struct{}),
+		deliver: func(conn *Connection, msg *Message) { conn.SendMessage(msg) },
+	}
+	for i := range p.workers {
+		p.workers[i] = make(chan broadcastJob, broadcastQueueSize)
+		go p.work(p.workers[i])
+	}
+	return p
+}
+
+// Dispatch queues a message for every connection, blocking only while the
+// workers' queues are full
+func (p *BroadcastPool) Dispatch(msg *Message, conns []*Connection) {
+	shards := make([][]*Connection, len(p.workers))
+	for _, conn := range conns {
+		i := p.shard(conn)
+		shards[i] = append(shards[i], conn)
+	}
+	for i, shard := range shards {
+		if len(shard) == 0 {
+			continue
+		}
+		p.pending.Add(1)
+		select {
+		case p.workers[i] <- broadcastJob{msg: msg, conns: shard}:
+		case <-p.done:
+			p.pending.Add(-1)
+			return
+		}
+	}
+}
+
+// Idle reports whether every dispatched message has been delivered
+func (p *BroadcastPool) Idle() bool {
+	return p.pending.Load() == 0
+}
+
+// Pending returns how many dispatched jobs are waiting or being delivered
+func (p *BroadcastPool) Pending() int {
+	return int(p.pending.Load())
+}
+
+// Stop ends the workers; queued jobs are dropped
+func (p *BroadcastPool) Stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+}
+
+// shard picks the worker owning a connection
+func (p *BroadcastPool) shard(conn *Connection) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(conn.ID))
+	return int(hash.Sum32() % uint32(len(p.workers)))
+}
+
+func (p *BroadcastPool) work(jobs chan broadcastJob) {
+	for {
+		select {
+		case <-p.done:
+			return
+		case job := <-jobs:
+			for _, conn := range job.conns {
+				p.deliver(conn, job.msg)
+			}
+			p.pending.Add(-1)
+		}
+	}
+}
+
+// Audience keys of pooled fan-outs
+const allAudience = "all"
+
+func roomAudience(room string) string   { return "room:" + room }
+func topicAudience(topic string) string { return "topic:" + topic }
+
+// roomConnections lists a room's connections (actor method)
+func (h *ActorHub) roomConnections(room string) []*Connection {
+	conns := make([]*Connection, 0, len(h.rooms[room]))
+	for _, conn := range h.rooms[room] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// actorFanout sends a message to an audience, handing large ones to the
+// broadcast pool. Once an audience has gone through the pool it keeps doing
+// so until the pool is idle, so a shrinking room's messages stay in order
+// (actor method).
+func (h *ActorHub) actorFanout(audience string, conns []*Connection, msg *Message) {
+	if len(conns) >= h.pooledFanout {
+		h.pooledAudiences[audience] = true
+	} else if h.pooledAudiences[audience] && h.broadcasts.Idle() {
+		delete(h.pooledAudiences, audience)
+	}
+
+	if h.pooledAudiences[audience] {
+		h.broadcasts.Dispatch(msg, conns)
+		return
+	}
+	for _, conn := range conns {
+		conn.SendMessage(msg)
+	}
+}
@@ -0,0 +1,86 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fillRoom registers count connections in a room and drains their join
+// notices
+func fillRoom(t *testing.T, hub *ActorHub, room string, count int) []*Connection {
+	conns := make([]*Connection, count)
+	for i := range conns {
+		conns[i] = &Connection{Send: make(chan []byte, 512), Rooms: make(map[string]bool)}
+		hub.Register(conns[i])
+		require.NoError(t, hub.JoinRoom(conns[i].ID, room))
+	}
+	require.Eventually(t, hub.broadcasts.Idle, time.Second, time.Millisecond)
+	for _, conn := range conns {
+		for len(conn.Send) > 0 {
+			<-conn.Send
+		}
+	}
+	return conns
+}
+
+func TestLargeRoomBroadcastDoesNotStallTheHub(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	hub.pooledFanout = 10
+	spectators := fillRoom(t, hub, "final_table", 50)
+
+	release := make(chan struct{})
+	hub.broadcasts.deliver = func(conn *Connection, msg *Message) {
+		<-release
+		conn.SendMessage(msg)
+	}
+	hub.BroadcastToRoom("final_table", &Message{Type: "game_event", Room: "final_table"})
+	assert.False(t, hub.broadcasts.Idle(), "the broadcast is still being delivered")
+
+	other := registerTestConnection(t, hub, "")
+	hub.ProcessMessage(other, &Message{Type: "test_echo"})
+	assert.Equal(t, "test_echo_response", readReply(t, other).Type, "unrelated messages are handled meanwhile")
+
+	close(release)
+	for _, conn := range spectators {
+		assert.Equal(t, "game_event", readReply(t, conn).Type)
+	}
+}
+
+func TestPooledBroadcastsKeepTheirOrderPerConnection(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	hub.pooledFanout = 40
+	spectators := fillRoom(t, hub, "final_table", 40)
+
+	for i := 0; i < 100; i++ {
+		hub.BroadcastToRoom("final_table", &Message{Type: "game_event", Data: fmt.Sprint(i)})
+		if i == 50 {
+			// The room shrinking below the threshold does not reorder anything
+			hub.LeaveRoom(spectators[0].ID, "final_table")
+			spectators = spectators[1:]
+		}
+	}
+	for _, conn := range spectators {
+		for i := 0; i < 100; i++ {
+			reply := readReplyOfType(t, conn, "game_event")
+			require.Equal(t, fmt.Sprint(i), reply.Data, conn.ID)
+		}
+	}
+}
+
+func TestSmallRoomBroadcastIsDeliveredInline(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	members := fillRoom(t, hub, "table_1", 3)
+
+	hub.BroadcastToRoom("table_1", &Message{Type: "game_event"})
+	for _, conn := range members {
+		assert.Len(t, conn.Send, 1, "delivered before BroadcastToRoom returns")
+	}
+	assert.True(t, hub.broadcasts.Idle())
+}
@@ -0,0 +1,266 @@
+package websocket_v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Chat control limits
+const (
+	MaxChatSlowMode = 10 * time.Minute
+)
+
+// Chat control errors
+var (
+	ErrChatObserversMuted = errors.New("observers cannot chat in this room")
+	ErrChatLinksBlocked   = errors.New("links are not allowed in this room")
+	ErrNotRoomOwner       = errors.New("only the room owner can change chat settings")
+)
+
+// chatLinkPattern matches URLs and bare web addresses in chat text
+var chatLinkPattern = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|net|org|io|gg|ly|me|co|tv|xyz)\b`)
+
+// RoomChatSettings controls who may chat in a room and how often
+type RoomChatSettings struct {
+	SlowModeSeconds int  `json:"slow_mode_seconds"` // Minimum gap between one user's messages; zero for none
+	MuteObservers   bool `json:"mute_observers"`    // Only players may chat at table rooms
+	LinksAllowed    bool `json:"links_allowed"`
+}
+
+// DefaultRoomChatSettings leaves chat unrestricted
+func DefaultRoomChatSettings() RoomChatSettings {
+	return RoomChatSettings{LinksAllowed: true}
+}
+
+// slowMode returns the settings' slow mode interval
+func (s RoomChatSettings) slowMode() time.Duration {
+	return time.Duration(s.SlowModeSeconds) * time.Second
+}
+
+// RoomDirectory answers who owns a room and who only watches it, for rooms
+// managed outside the hub such as table rooms
+type RoomDirectory interface {
+	RoomOwner(room string) (ownerID string, ok bool)
+	IsObserver(room, userID string) bool
+}
+
+// RoomGate is implemented by room directories that also decide who may join
+// the rooms they manage with join_room
+type RoomGate interface {
+	CanJoinRoom(room, userID string) error
+}
+
+// ChatControls holds each room's chat settings and enforces them before a
+// message is broadcast. Rooms made with create_room are owned by their
+// creator; other owners come from the room directory.
+type ChatControls struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	settings  map[string]RoomChatSettings
+	owners    map[string]string               // Room -> creator, for hub rooms
+	lastSent  map[string]map[string]time.Time // Room -> user ID -> last accepted message
+	directory RoomDirectory
+}
+
+// NewChatControls creates controls with every room unrestricted
+func NewChatControls() *ChatControls {
+	return &ChatControls{
+		now:      time.Now,
+		settings: make(map[string]RoomChatSettings),
+		owners:   make(map[string]string),
+		lastSent: make(map[string]map[string]time.Time),
+	}
+}
+
+// SetDirectory sets where owners and observers of outside rooms are looked up
+func (cc *ChatControls) SetDirectory(directory RoomDirectory) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.directory = directory
+}
+
+// SetOwner records the creator of a hub room
+func (cc *ChatControls) SetOwner(room, userID string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.owners[room] = userID
+}
+
+// Owner returns who may change a room's chat settings
+func (cc *ChatControls) Owner(room string) (string, bool) {
+	cc.mu.Lock()
+	owner, ok := cc.owners[room]
+	directory := cc.directory
+	cc.mu.Unlock()
+	if ok {
+		return owner, true
+	}
+	if directory == nil {
+		return "", false
+	}
+	return directory.RoomOwner(room)
+}
+
+// CanJoin asks the room directory, if it gates rooms, whether the user may
+// join the room
+func (cc *ChatControls) CanJoin(room, userID string) error {
+	cc.mu.Lock()
+	gate, ok := cc.directory.(RoomGate)
+	cc.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return gate.CanJoinRoom(room, userID)
+}
+
+// Settings returns a room's chat settings
+func (cc *ChatControls) Settings(room string) RoomChatSettings {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if settings, ok := cc.settings[room]; ok {
+		return settings
+	}
+	return DefaultRoomChatSettings()
+}
+
+// Configure changes a room's chat settings on behalf of its owner
+func (cc *ChatControls) Configure(room, userID string, settings RoomChatSettings) error {
+	if owner, ok := cc.Owner(room); !ok || owner != userID {
+		return ErrNotRoomOwner
+	}
+	if settings.SlowModeSeconds < 0 || settings.slowMode() > MaxChatSlowMode {
+		return fmt.Errorf("slow mode must be between 0 and %d seconds", int(MaxChatSlowMode/time.Second))
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.settings[room] = settings
+	return nil
+}
+
+// Allow checks a message against the room's settings and, if it may be
+// broadcast, starts the sender's slow mode wait. Owners are exempt.
+func (cc *ChatControls) Allow(room, userID string, message interface{}) error {
+	owner, _ := cc.Owner(room)
+	settings := cc.Settings(room)
+	if owner == userID {
+		return nil
+	}
+
+	cc.mu.Lock()
+	directory := cc.directory
+	cc.mu.Unlock()
+	if settings.MuteObservers && directory != nil && directory.IsObserver(room, userID) {
+		return ErrChatObserversMuted
+	}
+	if !settings.LinksAllowed && containsChatLink(message) {
+		return ErrChatLinksBlocked
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	now := cc.now()
+	if wait := settings.slowMode(); wait > 0 {
+		if last, ok := cc.lastSent[room][userID]; ok && now.Sub(last) < wait {
+			remaining := (wait - now.Sub(last) + time.Second - 1) / time.Second
+			return fmt.Errorf("slow mode is on: wait %d more seconds", remaining)
+		}
+	}
+	if cc.lastSent[room] == nil {
+		cc.lastSent[room] = make(map[string]time.Time)
+	}
+	cc.lastSent[room][userID] = now
+	return nil
+}
+
+// RemoveRoom forgets a room that no longer exists
+func (cc *ChatControls) RemoveRoom(room string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	delete(cc.settings, room)
+	delete(cc.owners, room)
+	delete(cc.lastSent, room)
+}
+
+// containsChatLink reports whether any text in a chat message is a link
+func containsChatLink(message interface{}) bool {
+	switch value := message.(type) {
+	case string:
+		return chatLinkPattern.MatchString(strings.TrimSpace(value))
+	case map[string]interface{}:
+		for _, field := range value {
+			if containsChatLink(field) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if containsChatLink(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RoomChatSettingsRequest names the room whose chat settings are read
+type RoomChatSettingsRequest struct {
+	Room string `json:"room" binding:"required"`
+}
+
+// SetRoomChatSettingsRequest changes the given settings of a room and keeps
+// the others
+type SetRoomChatSettingsRequest struct {
+	Room            string `json:"room" binding:"required"`
+	SlowModeSeconds *int   `json:"slow_mode_seconds,omitempty" description:"Minimum seconds between one user's messages; 0 turns slow mode off"`
+	MuteObservers   *bool  `json:"mute_observers,omitempty"`
+	LinksAllowed    *bool  `json:"links_allowed,omitempty"`
+}
+
+// RoomChatSettingsResponse is a room's chat settings
+type RoomChatSettingsResponse struct {
+	Room     string           `json:"room"`
+	Owner    string           `json:"owner,omitempty"`
+	Settings RoomChatSettings `json:"settings"`
+}
+
+// handleGetRoomChatSettings returns a room's chat settings to its members
+func (s *Server) handleGetRoomChatSettings(ctx context.Context, conn *Connection, req *RoomChatSettingsRequest) (*RoomChatSettingsResponse, error) {
+	if !conn.IsInRoom(req.Room) {
+		return nil, errors.New("You are not in this room")
+	}
+
+	owner, _ := s.chat.Owner(req.Room)
+	return &RoomChatSettingsResponse{Room: req.Room, Owner: owner, Settings: s.chat.Settings(req.Room)}, nil
+}
+
+// handleSetRoomChatSettings lets a room's owner change its chat settings and
+// tells the room
+func (s *Server) handleSetRoomChatSettings(ctx context.Context, conn *Connection, req *SetRoomChatSettingsRequest) (*RoomChatSettingsResponse, error) {
+	settings := s.chat.Settings(req.Room)
+	if req.SlowModeSeconds != nil {
+		settings.SlowModeSeconds = *req.SlowModeSeconds
+	}
+	if req.MuteObservers != nil {
+		settings.MuteObservers = *req.MuteObservers
+	}
+	if req.LinksAllowed != nil {
+		settings.LinksAllowed = *req.LinksAllowed
+	}
+
+	if err := s.chat.Configure(req.Room, conn.UserID, settings); err != nil {
+		return nil, err
+	}
+
+	s.hub.BroadcastToRoom(req.Room, &Message{
+		Type: "room_chat_settings",
+		Data: map[string]interface{}{"room": req.Room, "settings": settings},
+		Room: req.Room,
+	})
+	return &RoomChatSettingsResponse{Room: req.Room, Settings: settings}, nil
+}
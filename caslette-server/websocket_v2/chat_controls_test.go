@@ -0,0 +1,138 @@
+package websocket_v2
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRoomDirectory owns room "table_1" for user "1" and treats "3" as an observer
+type fakeRoomDirectory struct{}
+
+func (fakeRoomDirectory) RoomOwner(room string) (string, bool) {
+	return "1", room == "table_1"
+}
+
+func (fakeRoomDirectory) IsObserver(room, userID string) bool {
+	return userID == "3"
+}
+
+func TestChatControlsOnlyOwnersConfigure(t *testing.T) {
+	chat := NewChatControls()
+	chat.SetDirectory(fakeRoomDirectory{})
+	chat.SetOwner("lounge", "7")
+
+	assert.ErrorIs(t, chat.Configure("lounge", "8", RoomChatSettings{SlowModeSeconds: 5}), ErrNotRoomOwner)
+	assert.ErrorIs(t, chat.Configure("unowned", "7", RoomChatSettings{}), ErrNotRoomOwner)
+	assert.Error(t, chat.Configure("lounge", "7", RoomChatSettings{SlowModeSeconds: int(MaxChatSlowMode/time.Second) + 1}))
+	require.NoError(t, chat.Configure("lounge", "7", RoomChatSettings{SlowModeSeconds: 5}))
+	require.NoError(t, chat.Configure("table_1", "1", RoomChatSettings{MuteObservers: true}), "table creators own their rooms")
+	assert.True(t, chat.Settings("table_1").MuteObservers)
+
+	chat.RemoveRoom("lounge")
+	assert.Equal(t, DefaultRoomChatSettings(), chat.Settings("lounge"))
+	_, owned := chat.Owner("lounge")
+	assert.False(t, owned)
+}
+
+func TestChatControlsEnforceSettings(t *testing.T) {
+	chat := NewChatControls()
+	chat.SetDirectory(fakeRoomDirectory{})
+	now := time.Now()
+	chat.now = func() time.Time { return now }
+	require.NoError(t, chat.Configure("table_1", "1", RoomChatSettings{SlowModeSeconds: 10, MuteObservers: true}))
+
+	assert.NoError(t, chat.Allow("table_1", "2", "nice hand"))
+	assert.ErrorContains(t, chat.Allow("table_1", "2", "again"), "wait 10 more seconds")
+	assert.NoError(t, chat.Allow("table_1", "1", "owners are exempt"))
+	assert.NoError(t, chat.Allow("table_1", "1", "twice"))
+	assert.ErrorIs(t, chat.Allow("table_1", "3", "hello"), ErrChatObserversMuted)
+
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, chat.Allow("table_1", "2", "after the wait"))
+
+	assert.ErrorIs(t, chat.Allow("table_1", "4", map[string]interface{}{"text": "visit https://spam.example"}), ErrChatLinksBlocked)
+	assert.ErrorIs(t, chat.Allow("table_1", "4", "free chips at cheapchips.gg"), ErrChatLinksBlocked)
+	assert.NoError(t, chat.Allow("lounge", "4", "www.example.com"), "rooms allow links by default")
+}
+
+// readReplyOfType skips broadcasts until the connection receives a reply of
+// the given type
+func readReplyOfType(t *testing.T, conn *Connection, replyType string) *Message {
+	t.Helper()
+	for {
+		if reply := readReply(t, conn); reply.Type == replyType {
+			return reply
+		}
+	}
+}
+
+func TestSendToRoomAppliesChatSettings(t *testing.T) {
+	server := NewServer(nil)
+	hub := server.GetHub().(*ActorHub)
+	defer hub.Stop()
+
+	owner := registerTestConnection(t, hub, "7")
+	member := registerTestConnection(t, hub, "8")
+	hub.ProcessMessage(owner, &Message{Type: "create_room", Data: map[string]interface{}{"room": "lounge"}})
+	readReply(t, owner)
+	for _, conn := range []*Connection{owner, member} {
+		hub.ProcessMessage(conn, &Message{Type: "join_room", Data: map[string]interface{}{"room": "lounge"}})
+		readReplyOfType(t, conn, "join_room_response")
+	}
+	drain := func(conn *Connection) {
+		for len(conn.Send) > 0 {
+			<-conn.Send
+		}
+	}
+	drain(owner)
+	drain(member)
+
+	hub.ProcessMessage(member, &Message{Type: "set_room_chat_settings", Data: map[string]interface{}{"room": "lounge", "links_allowed": false}})
+	assert.False(t, readReply(t, member).Success, "members cannot change the settings")
+
+	hub.ProcessMessage(owner, &Message{Type: "set_room_chat_settings", Data: map[string]interface{}{"room": "lounge", "links_allowed": false}})
+	require.True(t, readReplyOfType(t, owner, "set_room_chat_settings_response").Success)
+	assert.Equal(t, "room_chat_settings", readReply(t, member).Type, "the room hears about the change")
+
+	hub.ProcessMessage(member, &Message{Type: "send_to_room", Data: map[string]interface{}{"room": "lounge", "message": "see http://example.com"}})
+	reply := readReply(t, member)
+	assert.False(t, reply.Success)
+	assert.Equal(t, ErrChatLinksBlocked.Error(), reply.Error)
+	assert.Empty(t, owner.Send, "the blocked message was not broadcast")
+}
+
+// gatedRoomDirectory only lets user "1" join room "table_1"
+type gatedRoomDirectory struct {
+	fakeRoomDirectory
+}
+
+func (gatedRoomDirectory) CanJoinRoom(room, userID string) error {
+	if room == "table_1" && userID != "1" {
+		return errors.New("observers watch this table from room table_1_observers")
+	}
+	return nil
+}
+
+func TestJoinRoomAsksTheRoomGate(t *testing.T) {
+	server := NewServer(nil)
+	hub := server.GetHub().(*ActorHub)
+	defer hub.Stop()
+	server.SetRoomDirectory(gatedRoomDirectory{})
+
+	player := registerTestConnection(t, hub, "1")
+	observer := registerTestConnection(t, hub, "3")
+
+	hub.ProcessMessage(observer, &Message{Type: "join_room", Data: map[string]interface{}{"room": "table_1"}})
+	reply := readReplyOfType(t, observer, "join_room_response")
+	assert.False(t, reply.Success)
+	assert.Contains(t, reply.Error, "table_1_observers")
+
+	hub.ProcessMessage(player, &Message{Type: "join_room", Data: map[string]interface{}{"room": "table_1"}})
+	assert.True(t, readReplyOfType(t, player, "join_room_response").Success)
+	hub.ProcessMessage(observer, &Message{Type: "join_room", Data: map[string]interface{}{"room": "table_1_observers"}})
+	assert.True(t, readReplyOfType(t, observer, "join_room_response").Success)
+}
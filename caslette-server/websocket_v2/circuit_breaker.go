@@ -0,0 +1,149 @@
+package websocket_v2
+
+import (
+	"sync"
+	"time"
+)
+
+// Handler execution limits
+const (
+	DefaultHandlerTimeout   = 10 * time.Second // Longest a custom handler may run
+	DefaultBreakerThreshold = 5                // Consecutive failures that open a circuit
+	DefaultBreakerCooldown  = 30 * time.Second // How long an open circuit rejects requests
+)
+
+// BreakerState is the state of a handler's circuit
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Requests flow normally
+	BreakerOpen     BreakerState = "open"      // Requests are rejected until the cooldown ends
+	BreakerHalfOpen BreakerState = "half_open" // One trial request decides whether to close
+)
+
+// handlerCircuit tracks failures for one message type
+type handlerCircuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool // A half-open trial request is in flight
+}
+
+// CircuitBreaker stops dispatching message types whose handlers keep timing
+// out or panicking, so one broken dependency cannot tie up every connection
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	circuits  map[string]*handlerCircuit
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+// NewCircuitBreaker creates a breaker that opens after threshold consecutive
+// failures and retries after cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &CircuitBreaker{
+		circuits:  make(map[string]*handlerCircuit),
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// circuit returns the circuit for a message type, creating it if needed. Callers hold b.mu.
+func (b *CircuitBreaker) circuit(name string) *handlerCircuit {
+	circuit, exists := b.circuits[name]
+	if !exists {
+		circuit = &handlerCircuit{state: BreakerClosed}
+		b.circuits[name] = circuit
+	}
+	return circuit
+}
+
+// Allow reports whether a request for the message type may run. When it may
+// not, it returns how long until the circuit will accept a trial request.
+func (b *CircuitBreaker) Allow(name string) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit := b.circuit(name)
+	switch circuit.state {
+	case BreakerOpen:
+		elapsed := b.now().Sub(circuit.openedAt)
+		if elapsed < b.cooldown {
+			return false, b.cooldown - elapsed
+		}
+		circuit.state = BreakerHalfOpen
+		circuit.trial = true
+		return true, 0
+	case BreakerHalfOpen:
+		if circuit.trial {
+			return false, b.cooldown
+		}
+		circuit.trial = true
+		return true, 0
+	}
+	return true, 0
+}
+
+// RecordSuccess closes the circuit for a message type
+func (b *CircuitBreaker) RecordSuccess(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit := b.circuit(name)
+	circuit.state = BreakerClosed
+	circuit.failures = 0
+	circuit.trial = false
+}
+
+// RecordFailure counts a timeout or panic, opening the circuit once the
+// threshold is reached or when a half-open trial fails
+func (b *CircuitBreaker) RecordFailure(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit := b.circuit(name)
+	circuit.failures++
+	circuit.trial = false
+	if circuit.state == BreakerHalfOpen || circuit.failures >= b.threshold {
+		circuit.state = BreakerOpen
+		circuit.openedAt = b.now()
+	}
+}
+
+// State returns the current state of a message type's circuit
+func (b *CircuitBreaker) State(name string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, exists := b.circuits[name]
+	if !exists {
+		return BreakerClosed
+	}
+	if circuit.state == BreakerOpen && b.now().Sub(circuit.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return circuit.state
+}
+
+// OpenCircuits returns the message types currently rejecting requests
+func (b *CircuitBreaker) OpenCircuits() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	names := make([]string, 0)
+	now := b.now()
+	for name, circuit := range b.circuits {
+		if circuit.state == BreakerOpen && now.Sub(circuit.openedAt) < b.cooldown {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,187 @@
+package websocket_v2
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	breaker := NewCircuitBreaker(3, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		breaker.RecordFailure("slow")
+	}
+	allowed, _ := breaker.Allow("slow")
+	assert.True(t, allowed)
+
+	breaker.RecordFailure("slow")
+	allowed, retryAfter := breaker.Allow("slow")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+	assert.Equal(t, BreakerOpen, breaker.State("slow"))
+	assert.Equal(t, []string{"slow"}, breaker.OpenCircuits())
+
+	// Other message types are unaffected
+	allowed, _ = breaker.Allow("fast")
+	assert.True(t, allowed)
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+
+	breaker.RecordFailure("flaky")
+	breaker.RecordSuccess("flaky")
+	breaker.RecordFailure("flaky")
+
+	assert.Equal(t, BreakerClosed, breaker.State("flaky"))
+}
+
+func TestCircuitBreakerHalfOpenTrial(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure("slow")
+	now = now.Add(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, breaker.State("slow"))
+
+	// Only one trial request is let through
+	allowed, _ := breaker.Allow("slow")
+	assert.True(t, allowed)
+	allowed, _ = breaker.Allow("slow")
+	assert.False(t, allowed)
+
+	// A failed trial reopens the circuit
+	breaker.RecordFailure("slow")
+	allowed, _ = breaker.Allow("slow")
+	assert.False(t, allowed)
+
+	now = now.Add(time.Minute)
+	allowed, _ = breaker.Allow("slow")
+	require.True(t, allowed)
+	breaker.RecordSuccess("slow")
+	assert.Equal(t, BreakerClosed, breaker.State("slow"))
+}
+
+// readReply waits for the next message queued on a test connection
+func readReply(t *testing.T, conn *Connection) *Message {
+	t.Helper()
+	select {
+	case data := <-conn.Send:
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return &msg
+	case <-time.After(time.Second):
+		t.Fatal("no reply sent")
+		return nil
+	}
+}
+
+func TestActorHubHandlerTimeout(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	hub.SetHandlerTimeout(20 * time.Millisecond)
+
+	var finished atomic.Bool
+	hub.RegisterMessageHandler("hang", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+		return &Message{Type: "hang_response", Success: true}
+	})
+	hub.RegisterMessageHandler("echo", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		assert.True(t, finished.Load(), "the next message waits for the timed out handler to return")
+		return &Message{Type: "echo_response", RequestID: msg.RequestID, Success: true}
+	})
+
+	conn := &Connection{ID: "c1", UserID: "7", Send: make(chan []byte, 16)}
+	hub.ProcessMessage(conn, &Message{Type: "hang", RequestID: "r1"})
+
+	reply := readReply(t, conn)
+	assert.Equal(t, "hang_response", reply.Type)
+	assert.Equal(t, "r1", reply.RequestID)
+	assert.False(t, reply.Success)
+	data, ok := reply.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "HANDLER_TIMEOUT", data["code"])
+	assert.Equal(t, float64(20), data["timeout_ms"])
+
+	hub.ProcessMessage(conn, &Message{Type: "echo", RequestID: "r2"})
+	reply = readReply(t, conn)
+	assert.Equal(t, "echo_response", reply.Type, "the late reply is dropped")
+	assert.True(t, reply.Success)
+}
+
+func TestActorHubServesOtherConnectionsWhileAHandlerIsStuck(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	hub.SetHandlerTimeout(20 * time.Millisecond)
+
+	release := make(chan struct{})
+	hub.RegisterMessageHandler("stuck", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		<-release
+		return nil
+	})
+	hub.RegisterMessageHandler("echo", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		return &Message{Type: "echo_response", RequestID: msg.RequestID, Success: true}
+	})
+
+	stuck := &Connection{ID: "c1", UserID: "7", Send: make(chan []byte, 16)}
+	returned := make(chan struct{})
+	go func() {
+		hub.ProcessMessage(stuck, &Message{Type: "stuck", RequestID: "r1"})
+		close(returned)
+	}()
+	assert.Equal(t, "HANDLER_TIMEOUT", readReply(t, stuck).Data.(map[string]interface{})["code"])
+
+	other := &Connection{ID: "c2", UserID: "8", Send: make(chan []byte, 16)}
+	hub.ProcessMessage(other, &Message{Type: "echo", RequestID: "r2"})
+	assert.Equal(t, "echo_response", readReply(t, other).Type)
+	select {
+	case <-returned:
+		t.Fatal("the stuck connection moved on before its handler returned")
+	default:
+	}
+
+	close(release)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("the connection stayed blocked after its handler returned")
+	}
+}
+
+func TestActorHubTripsBreakerForFailingHandler(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+
+	calls := 0
+	hub.RegisterMessageHandler("broken", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		calls++
+		panic("database unavailable")
+	})
+
+	conn := &Connection{ID: "c1", UserID: "7", Send: make(chan []byte, 16)}
+	for i := 0; i < DefaultBreakerThreshold; i++ {
+		hub.ProcessMessage(conn, &Message{Type: "broken"})
+		reply := readReply(t, conn)
+		data := reply.Data.(map[string]interface{})
+		assert.Equal(t, "HANDLER_ERROR", data["code"])
+	}
+
+	hub.ProcessMessage(conn, &Message{Type: "broken"})
+	reply := readReply(t, conn)
+	data := reply.Data.(map[string]interface{})
+	assert.Equal(t, "HANDLER_UNAVAILABLE", data["code"])
+	assert.Contains(t, data, "retry_after_ms")
+	assert.Equal(t, DefaultBreakerThreshold, calls)
+	assert.Equal(t, BreakerOpen, hub.HandlerBreaker().State("broken"))
+}
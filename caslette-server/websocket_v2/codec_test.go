@@ -0,0 +1,203 @@
+package websocket_v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// seatState is shaped like the players of a game state update
+type seatState struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Chips    int    `json:"chips"`
+	Bet      int    `json:"bet"`
+	Position int    `json:"position"`
+	Folded   bool   `json:"folded,omitempty"`
+}
+
+// gameEvent is a typical high-frequency table broadcast: a player action
+// with the resulting table state
+func gameEvent() *Message {
+	players := make([]seatState, 6)
+	for i := range players {
+		players[i] = seatState{ID: string(rune('a' + i)), Username: "player" + string(rune('a'+i)), Chips: 1000 + i*150, Bet: 200, Position: i, Folded: i%3 == 0}
+	}
+	return &Message{
+		Type:      "game_event",
+		Event:     "player_action",
+		Room:      "table_5f1c2a",
+		Timestamp: 1791981483,
+		Seq:       4211,
+		Data: map[string]interface{}{
+			"table_id":        "5f1c2a",
+			"player_id":       "c",
+			"action":          "raise",
+			"amount":          200,
+			"pot":             1450,
+			"current_bet":     200,
+			"next_player":     "d",
+			"community_cards": []string{"Ah", "Kd", "7c"},
+			"players":         players,
+		},
+	}
+}
+
+// asJSON is the data a handler would see had the message arrived as JSON
+func asJSON(t testing.TB, msg *Message) *Message {
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	var decoded Message
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return &decoded
+}
+
+func TestCodecsDecodeWhatJSONWould(t *testing.T) {
+	msg := gameEvent()
+	msg.RequestID = "r1"
+	msg.TraceID = "trace"
+	msg.Success = true
+	msg.Error = "none"
+	want := asJSON(t, msg)
+
+	for _, c := range []Codec{JSONCodec, MsgpackCodec, ProtobufCodec} {
+		data, err := c.Marshal(msg)
+		require.NoError(t, err, c.Name())
+		var decoded Message
+		require.NoError(t, c.Unmarshal(data, &decoded), c.Name())
+		assert.Equal(t, want, &decoded, c.Name())
+	}
+}
+
+func TestProtobufCodecSkipsUnknownFields(t *testing.T) {
+	data, err := ProtobufCodec.Marshal(&Message{Type: "ping", Seq: 3})
+	require.NoError(t, err)
+	data = protowire.AppendTag(data, 99, protowire.BytesType)
+	data = protowire.AppendString(data, "from a newer client")
+
+	var decoded Message
+	require.NoError(t, ProtobufCodec.Unmarshal(data, &decoded))
+	assert.Equal(t, Message{Type: "ping", Seq: 3}, decoded)
+	assert.Error(t, ProtobufCodec.Unmarshal([]byte{0x0a, 0x05, 'a'}, &decoded), "truncated frames are refused")
+}
+
+// dialCodecServer opens a connection through newConnection offering the
+// given subprotocols and returns the server and client ends
+func dialCodecServer(t *testing.T, subprotocols ...string) (*Connection, *websocket.Conn) {
+	accepted := make(chan *Connection, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := newConnection(nil, w, r, CompressionPolicy{})
+		require.NoError(t, err)
+		conn.ID = "c1"
+		go conn.writePump()
+		accepted <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+	client, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case conn := <-accepted:
+		return conn, client
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not accepted")
+		return nil, nil
+	}
+}
+
+func TestHandshakeNegotiatesTheFirstKnownCodec(t *testing.T) {
+	conn, client := dialCodecServer(t, "graphql-ws", SubprotocolMsgpack, SubprotocolProtobuf)
+	assert.Equal(t, SubprotocolMsgpack, client.Subprotocol())
+	assert.Equal(t, MsgpackCodec, conn.Codec())
+
+	conn.SendMessage(&Message{Type: "pong", Data: map[string]interface{}{"n": 1}})
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frameType, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, frameType)
+	var decoded Message
+	require.NoError(t, MsgpackCodec.Unmarshal(data, &decoded))
+	assert.Equal(t, "pong", decoded.Type)
+	assert.Equal(t, map[string]interface{}{"n": float64(1)}, decoded.Data)
+}
+
+func TestHandshakeWithoutKnownCodecKeepsJSON(t *testing.T) {
+	conn, client := dialCodecServer(t, "graphql-ws")
+	assert.Empty(t, client.Subprotocol())
+	assert.Equal(t, JSONCodec, conn.Codec())
+
+	conn.SendMessage(&Message{Type: "pong"})
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frameType, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, frameType)
+	assert.Contains(t, string(data), `"type":"pong"`)
+}
+
+func TestResumeReencodesReplayForTheNewCodec(t *testing.T) {
+	hub := newResumeHub(t, NewSessionStore(time.Minute, 16))
+	first := authenticate(hub, "c1")
+	token := sessionToken(t, first)
+	hub.BroadcastToUser("7", &Message{Type: "notice", Data: "missed"})
+	hub.Unregister(first)
+
+	second := &Connection{Send: make(chan []byte, 16), Rooms: make(map[string]bool), codec: ProtobufCodec}
+	hub.Register(second)
+	hub.ProcessMessage(second, &Message{Type: "resume", Data: map[string]interface{}{"sessionToken": token, "lastSeq": 1}})
+
+	var replayed Message
+	for replayed.Type != "notice" {
+		select {
+		case data := <-second.Send:
+			replayed = Message{}
+			require.NoError(t, ProtobufCodec.Unmarshal(data, &replayed))
+		case <-time.After(time.Second):
+			t.Fatal("missed message not replayed")
+		}
+	}
+	assert.Equal(t, "missed", replayed.Data)
+	assert.Equal(t, uint64(2), replayed.Seq)
+}
+
+func BenchmarkCodecMarshal(b *testing.B) {
+	for _, c := range []Codec{JSONCodec, MsgpackCodec, ProtobufCodec} {
+		b.Run(c.Name(), func(b *testing.B) {
+			msg := gameEvent()
+			data, _ := c.Marshal(msg)
+			b.ReportMetric(float64(len(data)), "bytes/msg")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Marshal(msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCodecUnmarshal(b *testing.B) {
+	for _, c := range []Codec{JSONCodec, MsgpackCodec, ProtobufCodec} {
+		b.Run(c.Name(), func(b *testing.B) {
+			data, err := c.Marshal(asJSON(b, gameEvent()))
+			require.NoError(b, err)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var msg Message
+				if err := c.Unmarshal(data, &msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
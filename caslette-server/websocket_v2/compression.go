@@ -0,0 +1,111 @@
+package websocket_v2
+
+import (
+	"bufio"
+	"compress/flate"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// CompressionOptInParam is the query parameter a client sets to ask for
+// permessage-deflate, e.g. /ws?compress=1. The client must also offer the
+// extension in its handshake; connections that do not opt in stay
+// uncompressed so small clients avoid the inflate cost.
+const CompressionOptInParam = "compress"
+
+// CompressionPolicy controls permessage-deflate negotiation and which
+// outbound frames are compressed
+type CompressionPolicy struct {
+	Enabled  bool `json:"enabled"`
+	MinBytes int  `json:"min_bytes"` // Frames smaller than this are sent uncompressed
+	Level    int  `json:"level"`     // flate level, from HuffmanOnly (-2) to BestCompression (9)
+}
+
+// DefaultCompressionPolicy compresses frames of 1 KiB and up at the fastest
+// level, which covers lobby snapshots without spending CPU on small events
+func DefaultCompressionPolicy() CompressionPolicy {
+	return CompressionPolicy{Enabled: true, MinBytes: 1024, Level: flate.BestSpeed}
+}
+
+// Validate checks the threshold and level
+func (p CompressionPolicy) Validate() error {
+	if p.MinBytes < 0 {
+		return fmt.Errorf("compression threshold cannot be negative")
+	}
+	if p.Level < flate.HuffmanOnly || p.Level > flate.BestCompression {
+		return fmt.Errorf("compression level must be between %d and %d", flate.HuffmanOnly, flate.BestCompression)
+	}
+	return nil
+}
+
+// negotiates reports whether a handshake should get permessage-deflate: the
+// policy allows it, the client opted in and its handshake offers the extension
+func (p CompressionPolicy) negotiates(r *http.Request) bool {
+	if !p.Enabled {
+		return false
+	}
+	switch strings.ToLower(r.URL.Query().Get(CompressionOptInParam)) {
+	case "1", "true", "deflate":
+	default:
+		return false
+	}
+	for _, header := range r.Header.Values("Sec-WebSocket-Extensions") {
+		for _, offer := range strings.Split(header, ",") {
+			name, _, _ := strings.Cut(offer, ";")
+			if strings.TrimSpace(name) == "permessage-deflate" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compressingUpgrader negotiates permessage-deflate with clients that offer it
+var compressingUpgrader = func() websocket.Upgrader {
+	u := upgrader
+	u.EnableCompression = true
+	return u
+}()
+
+// countingResponseWriter counts the bytes written to the hijacked socket so
+// bandwidth diagnostics can report what compression actually saves
+type countingResponseWriter struct {
+	http.ResponseWriter
+	written *atomic.Int64
+}
+
+// Hijack hands the upgrader a socket whose writes are counted
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response does not implement http.Hijacker")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &countingConn{Conn: conn, written: w.written}, rw, nil
+}
+
+// countingConn adds every byte written to the socket to a counter
+type countingConn struct {
+	net.Conn
+	written *atomic.Int64
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(int64(n))
+	return n, err
+}
+
+// compressesFrame reports whether an outbound frame of size bytes is sent
+// compressed on this connection
+func (c *Connection) compressesFrame(size int) bool {
+	return c.compressed && size >= c.compressMinBytes
+}
@@ -0,0 +1,110 @@
+package websocket_v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionPolicyNegotiation(t *testing.T) {
+	offer := "permessage-deflate; client_max_window_bits"
+	cases := []struct {
+		name   string
+		policy CompressionPolicy
+		query  string
+		offer  string
+		want   bool
+	}{
+		{"opted in and offered", DefaultCompressionPolicy(), "compress=1", offer, true},
+		{"not opted in", DefaultCompressionPolicy(), "", offer, false},
+		{"opted out", DefaultCompressionPolicy(), "compress=0", offer, false},
+		{"extension not offered", DefaultCompressionPolicy(), "compress=true", "x-webkit-deflate-frame", false},
+		{"disabled by the server", CompressionPolicy{}, "compress=deflate", offer, false},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest("GET", "/ws?"+tc.query, nil)
+		r.Header.Set("Sec-WebSocket-Extensions", tc.offer)
+		assert.Equal(t, tc.want, tc.policy.negotiates(r), tc.name)
+	}
+
+	assert.Error(t, CompressionPolicy{MinBytes: -1}.Validate())
+	assert.Error(t, CompressionPolicy{Level: 10}.Validate())
+	assert.NoError(t, DefaultCompressionPolicy().Validate())
+}
+
+// dialCompressionServer opens a connection through newConnection and returns
+// the server and client ends
+func dialCompressionServer(t *testing.T, policy CompressionPolicy, query string) (*Connection, *websocket.Conn) {
+	accepted := make(chan *Connection, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := newConnection(nil, w, r, policy)
+		require.NoError(t, err)
+		conn.ID = "c1"
+		conn.bandwidth = NewBandwidthMonitor()
+		go conn.writePump()
+		accepted <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{EnableCompression: true}
+	client, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?"+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case conn := <-accepted:
+		return conn, client
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not accepted")
+		return nil, nil
+	}
+}
+
+func TestCompressedConnectionSendsLargeFramesCompressed(t *testing.T) {
+	conn, client := dialCompressionServer(t, CompressionPolicy{Enabled: true, MinBytes: 512, Level: 1}, "compress=1")
+	require.True(t, conn.compressed)
+
+	large := strings.Repeat("lobby table row ", 512)
+	conn.SendMessage(&Message{Type: "lobby_snapshot", Data: large})
+	conn.SendMessage(&Message{Type: "pong"})
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, first, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Contains(t, string(first), large)
+	_, second, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "pong")
+
+	diagnostics := conn.bandwidth.Diagnostics()
+	require.Len(t, diagnostics.Connections, 1)
+	stats := diagnostics.Connections[0]
+	assert.True(t, stats.Compressed)
+	assert.Equal(t, int64(1), stats.CompressedOut, "only the frame above the threshold is compressed")
+	assert.Less(t, stats.WireBytesOut, stats.BytesOut/4)
+	assert.Equal(t, 1, diagnostics.Compression.Connections)
+	assert.Equal(t, stats.WireBytesOut, diagnostics.Compression.WireBytesOut)
+}
+
+func TestConnectionWithoutOptInStaysUncompressed(t *testing.T) {
+	conn, client := dialCompressionServer(t, DefaultCompressionPolicy(), "")
+	require.False(t, conn.compressed)
+
+	large := strings.Repeat("lobby table row ", 512)
+	conn.SendMessage(&Message{Type: "lobby_snapshot", Data: large})
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, payload, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), large)
+
+	stats := conn.bandwidth.Diagnostics().Connections[0]
+	assert.False(t, stats.Compressed)
+	assert.Zero(t, stats.CompressedOut)
+	assert.GreaterOrEqual(t, stats.WireBytesOut, stats.BytesOut)
+}
@@ -1,10 +1,12 @@
 package websocket_v2
 
 import (
-	"encoding/json"
-	"log"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -19,7 +21,50 @@ type Connection struct {
 	Send     chan []byte
 	Hub      HubInterface
 	Rooms    map[string]bool
+	Bot      *BotScope // Non-nil for connections authenticated with a bot token
+	RemoteIP string    // Client address, honouring headers set by trusted proxies
+	TraceID  string    // Request ID of the upgrade, shared with the REST logs
+	Device   string    // Name the client gave its device on auth, such as "phone"
 	mu       sync.RWMutex
+
+	// bandwidth accounts traffic and enforces budgets when set
+	bandwidth *BandwidthMonitor
+
+	compressed       bool         // permessage-deflate was negotiated
+	compressMinBytes int          // Smallest frame sent compressed
+	wireBytesOut     atomic.Int64 // Bytes written to the socket after the handshake
+
+	// session numbers and keeps outbound messages once authenticated
+	session atomic.Pointer[Session]
+
+	// codec encodes messages in the protocol negotiated on the handshake;
+	// nil means JSON
+	codec Codec
+
+	// Inbound size limit (zero means DefaultMaxMessageBytes) and the size
+	// over which outbound frames are chunked (zero never chunks)
+	maxInboundBytes int
+	chunkBytes      int
+	chunkSeq        atomic.Uint64
+
+	// Send queue bounds and overflow state; slowConsumer is closed to have
+	// the write pump disconnect the client
+	sendQueue    *SendQueueMonitor
+	sendPolicy   SendQueuePolicy
+	sendDrops    atomic.Int64 // Drops in a row
+	sendDropped  atomic.Int64
+	slow         atomic.Bool
+	slowConsumer chan struct{}
+
+	// shutdown is closed to have the write pump flush the queue and close
+	// the socket because the server is shutting down
+	leaving  atomic.Bool
+	shutdown chan struct{}
+
+	// Ping interval and pong timeout, and when the client last answered
+	// (unix nanoseconds, zero until the pumps start)
+	heartbeat HeartbeatPolicy
+	lastPong  atomic.Int64
 }
 
 // Message represents a WebSocket message
@@ -29,14 +74,17 @@ type Message struct {
 	Data      interface{} `json:"data,omitempty"`
 	Room      string      `json:"room,omitempty"`
 	RequestID string      `json:"requestId,omitempty"`
+	TraceID   string      `json:"traceId,omitempty"` // The receiving connection's trace ID
 	Success   bool        `json:"success,omitempty"`
 	Error     string      `json:"error,omitempty"`
 	Timestamp int64       `json:"timestamp"`
+	Seq       uint64      `json:"seq,omitempty"` // Position in the connection's session, for resuming
 }
 
 // AuthMessage represents authentication message
 type AuthMessage struct {
-	Token string `json:"token"`
+	Token  string `json:"token"`
+	Device string `json:"device,omitempty"` // Optional, for addressing one of the user's devices
 }
 
 var upgrader = websocket.Upgrader{
@@ -45,23 +93,125 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// NewConnection creates a new WebSocket connection
+// NewConnection creates a new uncompressed WebSocket connection
 func NewConnection(hub HubInterface, w http.ResponseWriter, r *http.Request) (*Connection, error) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	return newConnection(hub, w, r, CompressionPolicy{})
+}
+
+// newConnection upgrades the request, negotiating permessage-deflate when
+// the policy and the client both allow it
+func newConnection(hub HubInterface, w http.ResponseWriter, r *http.Request, compression CompressionPolicy) (*Connection, error) {
+	connection := &Connection{
+		Send:     make(chan []byte, DefaultSendQueueSize),
+		Hub:      hub,
+		Rooms:    make(map[string]bool),
+		RemoteIP: clientIP(r, nil),
+		TraceID:  traceIDFromRequest(r),
+
+		slowConsumer: make(chan struct{}),
+		shutdown:     make(chan struct{}),
+	}
+
+	responseHeader := http.Header{TraceIDHeader: {connection.TraceID}}
+	if codec, negotiated := negotiateCodec(r); negotiated {
+		connection.codec = codec
+		responseHeader.Set("Sec-WebSocket-Protocol", codec.Name())
+	}
+
+	u := &upgrader
+	if compression.negotiates(r) {
+		u = &compressingUpgrader
+		connection.compressed = true
+		connection.compressMinBytes = compression.MinBytes
+	}
+
+	conn, err := u.Upgrade(&countingResponseWriter{ResponseWriter: w, written: &connection.wireBytesOut}, r, responseHeader)
 	if err != nil {
 		return nil, err
 	}
-
-	connection := &Connection{
-		Conn:  conn,
-		Send:  make(chan []byte, 256),
-		Hub:   hub,
-		Rooms: make(map[string]bool),
+	connection.Conn = conn
+	// Only frames count toward wire bytes, not the handshake response
+	connection.wireBytesOut.Store(0)
+	if connection.compressed {
+		if err := conn.SetCompressionLevel(compression.Level); err != nil {
+			conn.Close()
+			return nil, err
+		}
 	}
 
 	return connection, nil
 }
 
+// clientIP returns the originating address of a request. Forwarding headers
+// are only believed when the socket peer is one of the trusted proxies: the
+// client is then the nearest untrusted X-Forwarded-For entry, or X-Real-IP.
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !isTrustedProxy(peer, trusted) {
+		return peer
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && !isTrustedProxy(hop, trusted) {
+				return hop
+			}
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return peer
+}
+
+// isTrustedProxy reports whether an address belongs to a trusted proxy network
+func isTrustedProxy(address string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses proxy addresses or CIDR ranges. Bare addresses
+// are treated as single-host networks.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
 // Start begins the connection's read and write pumps
 func (c *Connection) Start() {
 	go c.writePump()
@@ -83,29 +233,113 @@ func (c *Connection) Close() {
 		c.Hub.LeaveRoom(c.ID, room)
 	}
 
+	if c.bandwidth != nil {
+		c.bandwidth.Remove(c)
+	}
+	if c.sendQueue != nil {
+		c.sendQueue.Remove(c)
+	}
+
 	// Close the connection
 	c.Conn.Close()
 	close(c.Send)
 }
 
+// disconnectAfter closes the socket after a delay, letting queued messages
+// flush; the read pump then unregisters the connection
+func (c *Connection) disconnectAfter(delay time.Duration) {
+	if c.Conn == nil {
+		return
+	}
+	time.AfterFunc(delay, func() {
+		c.Conn.Close()
+	})
+}
+
 // SendMessage sends a message to this connection
 func (c *Connection) SendMessage(msg *Message) {
-	msg.Timestamp = time.Now().Unix()
-	data, err := json.Marshal(msg)
+	// Broadcasts share one message, so each connection stamps its own copy
+	stamped := *msg
+	stamped.Timestamp = time.Now().Unix()
+	stamped.TraceID = c.TraceID
+	if session := c.session.Load(); session != nil && session.deliver(c, &stamped) {
+		return
+	}
+	data, err := c.Codec().Marshal(&stamped)
 	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
+		c.Logf("Error marshaling message: %v", err)
 		return
 	}
 
-	log.Printf("SendMessage: Sending %s to connection %s (data: %s)", msg.Type, c.ID, string(data))
+	if !c.admit(msg, data) {
+		return
+	}
+	c.enqueue(msg.Type, data)
+}
+
+// admit counts an outbound frame against the bandwidth budget, reporting
+// false when it must be dropped
+func (c *Connection) admit(msg *Message, data []byte) bool {
+	if c.bandwidth != nil && !c.bandwidth.RecordOutbound(c, msg, len(data)) {
+		c.Logf("SendMessage: Dropped %s for connection %s (bandwidth budget exceeded)", msg.Type, c.ID)
+		return false
+	}
+	return true
+}
+
+// enqueue hands a frame to the write pump without blocking. When the send
+// queue is full the overflow policy applies and it reports false. Frames
+// over the chunk size go out as message_chunk frames, all or none.
+func (c *Connection) enqueue(msgType string, data []byte) bool {
+	if c.slow.Load() {
+		return false
+	}
+	chunks, err := c.chunkFrame(data)
+	if err != nil {
+		c.Logf("SendMessage: Failed to chunk %s for connection %s: %v", msgType, c.ID, err)
+		return true
+	}
+	if chunks != nil {
+		c.Logf("SendMessage: Sending %s to connection %s in %d chunks (%d bytes)", msgType, c.ID, len(chunks), len(data))
+		if cap(c.Send)-len(c.Send) < len(chunks) {
+			c.overflow(msgType)
+			return false
+		}
+		for _, chunk := range chunks {
+			select {
+			case c.Send <- chunk:
+			default:
+				c.overflow(msgType)
+				return false
+			}
+		}
+		c.queued()
+		return true
+	}
+
+	if c.Codec().Binary() {
+		c.Logf("SendMessage: Sending %s to connection %s (%d bytes)", msgType, c.ID, len(data))
+	} else {
+		c.Logf("SendMessage: Sending %s to connection %s (data: %s)", msgType, c.ID, string(data))
+	}
 
 	select {
 	case c.Send <- data:
-		log.Printf("SendMessage: Successfully queued %s for connection %s", msg.Type, c.ID)
+		c.Logf("SendMessage: Successfully queued %s for connection %s", msgType, c.ID)
+		c.queued()
+		return true
 	default:
-		log.Printf("Connection %s send channel full, closing connection", c.ID)
-		c.Close()
+		c.overflow(msgType)
+		return false
+	}
+}
+
+// Codec returns how messages on the connection are encoded
+func (c *Connection) Codec() Codec {
+	if c.codec == nil {
+		return JSONCodec
 	}
+	return c.codec
 }
 
 // JoinRoom adds the connection to a room
@@ -152,31 +386,56 @@ func (c *Connection) readPump() {
 		c.Close()
 	}()
 
-	c.Conn.SetReadLimit(4096) // Increased from 512 to handle larger messages like JWT tokens
-	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	c.markAlive()
 	c.Conn.SetPongHandler(func(string) error {
-		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.markAlive()
 		return nil
 	})
 
 	for {
-		_, messageBytes, err := c.Conn.ReadMessage()
+		frameType, messageBytes, size, err := c.readFrame()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				c.Logf("WebSocket error: %v", err)
 			}
 			break
 		}
 
-		log.Printf("Connection %s: Received raw message: %s", c.ID, string(messageBytes))
+		if c.bandwidth != nil {
+			allowed, firstThrottle := c.bandwidth.RecordInbound(c, size)
+			if !allowed {
+				if firstThrottle {
+					c.SendMessage(&Message{
+						Type:  "error",
+						Error: "bandwidth budget exceeded, messages are being dropped",
+					})
+				}
+				continue
+			}
+		}
+
+		if messageBytes == nil {
+			c.Logf("Connection %s: Refused %d byte message over the %d byte limit", c.ID, size, c.maxInbound())
+			c.SendMessage(tooLargeReply(size, c.maxInbound()))
+			continue
+		}
+
+		// Text frames are always JSON, whatever was negotiated
+		codec := JSONCodec
+		if frameType == websocket.BinaryMessage {
+			codec = c.Codec()
+			c.Logf("Connection %s: Received %d byte %s message", c.ID, len(messageBytes), codec.Name())
+		} else {
+			c.Logf("Connection %s: Received raw message: %s", c.ID, string(messageBytes))
+		}
 
 		var msg Message
-		if err := json.Unmarshal(messageBytes, &msg); err != nil {
-			log.Printf("Error unmarshaling message: %v", err)
+		if err := codec.Unmarshal(messageBytes, &msg); err != nil {
+			c.Logf("Error unmarshaling message: %v", err)
 			continue
 		}
 
-		log.Printf("Connection %s: Parsed message type: %s, requestId: %s", c.ID, msg.Type, msg.RequestID)
+		c.Logf("Connection %s: Parsed message type: %s, requestId: %s", c.ID, msg.Type, msg.RequestID)
 
 		msg.Timestamp = time.Now().Unix()
 		c.Hub.ProcessMessage(c, &msg)
@@ -185,7 +444,11 @@ func (c *Connection) readPump() {
 
 // writePump pumps messages from the hub to the websocket connection
 func (c *Connection) writePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	frameType := websocket.TextMessage
+	if c.Codec().Binary() {
+		frameType = websocket.BinaryMessage
+	}
+	ticker := time.NewTicker(c.heartbeatPolicy().PingInterval)
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
@@ -200,8 +463,11 @@ func (c *Connection) writePump() {
 				return
 			}
 
-			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
-				log.Printf("WebSocket write error: %v", err)
+			if c.compressed {
+				c.Conn.EnableWriteCompression(c.compressesFrame(len(message)))
+			}
+			if err := c.Conn.WriteMessage(frameType, message); err != nil {
+				c.Logf("WebSocket write error: %v", err)
 				return
 			}
 
@@ -210,6 +476,22 @@ func (c *Connection) writePump() {
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+
+		case <-c.slowConsumer:
+			// The notice skips the queue the client could not keep up with
+			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			notice := c.slowConsumerNotice()
+			notice.Timestamp = time.Now().Unix()
+			notice.TraceID = c.TraceID
+			if data, err := c.Codec().Marshal(notice); err == nil {
+				c.Conn.WriteMessage(frameType, data)
+			}
+			c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, SlowConsumerCode))
+			return
+
+		case <-c.shutdown:
+			c.flushForShutdown(frameType)
+			return
 		}
 	}
 }
@@ -0,0 +1,523 @@
+package websocket_v2
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateLimitClass groups message types that share a per-connection rate budget
+type RateLimitClass string
+
+const (
+	// RateLimitDefault relies on the hub-wide connection limit only
+	RateLimitDefault RateLimitClass = "default"
+	// RateLimitRead is intended for cheap lookups
+	RateLimitRead RateLimitClass = "read"
+	// RateLimitWrite is intended for state-changing requests
+	RateLimitWrite RateLimitClass = "write"
+	// RateLimitStrict is intended for expensive or abusable requests
+	RateLimitStrict RateLimitClass = "strict"
+)
+
+// maxClassWindows bounds tracked windows before expired entries are pruned
+const maxClassWindows = 10000
+
+// rateLimitClassBudgets defines messages allowed per second for each class.
+// The default class has no additional budget beyond the hub limit.
+var rateLimitClassBudgets = map[RateLimitClass]int{
+	RateLimitRead:   MaxMessagesPerSecond,
+	RateLimitWrite:  5,
+	RateLimitStrict: 1,
+}
+
+// FieldSpec describes a single field of a handler payload
+type FieldSpec struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // string, number, bool, object, array, any
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// HandlerSpec declares a message handler together with its enforcement metadata
+type HandlerSpec struct {
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	RequireAuth    bool            `json:"require_auth"`
+	Permissions    []string        `json:"permissions,omitempty"`
+	Schema         []FieldSpec     `json:"schema,omitempty"`
+	Strict         bool            `json:"strict"`                    // Fields missing from the schema are refused
+	ResponseSchema []FieldSpec     `json:"response_schema,omitempty"` // Documents the reply data of typed handlers
+	RateLimitClass RateLimitClass  `json:"rate_limit_class"`
+	ResponseType   string          `json:"response_type"` // Defaults to the name with a _response suffix
+	AllowBots      bool            `json:"allow_bots"`    // Bot-token connections are denied otherwise
+	Chat           bool            `json:"chat"`          // Refused while the sender is muted for rate-limit violations
+	MovesMoney     bool            `json:"moves_money"`   // Requires a fresh nonce and expires_at so the frame cannot be replayed
+	Priority       MessagePriority `json:"priority"`      // Low-priority messages are shed first when the server is overloaded
+	Middleware     []Middleware    `json:"-"`             // Run inside the declared checks, first outermost
+	Handler        MessageHandler  `json:"-"`
+}
+
+// PermissionChecker reports whether a user holds the named permission
+type PermissionChecker func(userID, permission string) (bool, error)
+
+// AccessChecker decides whether any connection may send a message to a
+// handler, e.g. by checking the client's jurisdiction against the table
+type AccessChecker func(conn *Connection, msg *Message) error
+
+// HandlerRegistry holds declared handlers and applies their metadata uniformly
+type HandlerRegistry struct {
+	mu                sync.RWMutex
+	specs             map[string]*HandlerSpec
+	permissionChecker PermissionChecker
+	botAccessChecker  BotAccessChecker
+	accessChecker     AccessChecker
+	penalties         *PenaltyTracker
+	exemptions        *RateLimitExemptions
+	replays           *ReplayGuard
+	shedder           *LoadShedder
+	middleware        []Middleware // Run around every handler
+
+	// Per connection and class request windows
+	classWindows map[string]*classWindow
+}
+
+// classWindow tracks requests within the current one-second window
+type classWindow struct {
+	windowStart time.Time
+	count       int
+}
+
+// NewHandlerRegistry creates an empty handler registry
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{
+		specs:        make(map[string]*HandlerSpec),
+		classWindows: make(map[string]*classWindow),
+		replays:      NewReplayGuard(DefaultNonceWindow),
+	}
+}
+
+// SetPermissionChecker sets the function used to resolve declared permissions
+func (r *HandlerRegistry) SetPermissionChecker(checker PermissionChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.permissionChecker = checker
+}
+
+// SetBotAccessChecker sets the extra check applied to bot connections
+func (r *HandlerRegistry) SetBotAccessChecker(checker BotAccessChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.botAccessChecker = checker
+}
+
+// SetAccessChecker sets the extra check applied to every connection
+func (r *HandlerRegistry) SetAccessChecker(checker AccessChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accessChecker = checker
+}
+
+// SetPenaltyTracker sets the tracker consulted for chat mutes
+func (r *HandlerRegistry) SetPenaltyTracker(tracker *PenaltyTracker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.penalties = tracker
+}
+
+// SetRateLimitExemptions sets the system actors that skip class budgets
+func (r *HandlerRegistry) SetRateLimitExemptions(exemptions *RateLimitExemptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exemptions = exemptions
+}
+
+// SetReplayWindow sets how far ahead money-moving requests may expire
+func (r *HandlerRegistry) SetReplayWindow(window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replays = NewReplayGuard(window)
+}
+
+// SetLoadShedder sets the shedder that refuses low-priority messages while
+// the server is overloaded
+func (r *HandlerRegistry) SetLoadShedder(shedder *LoadShedder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shedder = shedder
+}
+
+// Register validates and stores a handler spec; a name can only be declared once
+func (r *HandlerRegistry) Register(spec HandlerSpec) error {
+	if err := normalizeSpec(&spec); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.specs[spec.Name]; exists {
+		return fmt.Errorf("handler %s is already registered", spec.Name)
+	}
+	r.specs[spec.Name] = &spec
+	return nil
+}
+
+// Override swaps the handler function registered under a name, keeping the
+// declared metadata so the replacement is guarded the same way. Options add
+// checks on top of the declared ones but never remove any; middleware among
+// them replaces the declared middleware. Names that were never declared are
+// registered with the options' checks only.
+func (r *HandlerRegistry) Override(name string, handler MessageHandler, options ...HandlerOption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spec := HandlerSpec{Name: name}
+	if existing, exists := r.specs[name]; exists {
+		spec = *existing
+	}
+	spec.Handler = handler
+
+	var declared HandlerSpec
+	for _, option := range options {
+		option.applyTo(&declared)
+	}
+	spec.RequireAuth = spec.RequireAuth || declared.RequireAuth
+	for _, permission := range declared.Permissions {
+		if !slices.Contains(spec.Permissions, permission) {
+			spec.Permissions = append(spec.Permissions, permission)
+		}
+	}
+	if len(declared.Middleware) > 0 {
+		spec.Middleware = declared.Middleware
+	}
+	if err := normalizeSpec(&spec); err != nil {
+		return err
+	}
+	r.specs[name] = &spec
+	return nil
+}
+
+// normalizeSpec validates a spec and fills in its defaults
+func normalizeSpec(spec *HandlerSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("handler name is required")
+	}
+	if spec.Handler == nil {
+		return fmt.Errorf("handler %s has no handler function", spec.Name)
+	}
+	if spec.RateLimitClass == "" {
+		spec.RateLimitClass = RateLimitDefault
+	}
+	if _, ok := rateLimitClassBudgets[spec.RateLimitClass]; !ok && spec.RateLimitClass != RateLimitDefault {
+		return fmt.Errorf("handler %s has unknown rate limit class: %s", spec.Name, spec.RateLimitClass)
+	}
+	if spec.ResponseType == "" {
+		spec.ResponseType = spec.Name + "_response"
+	}
+	if spec.Priority == "" {
+		spec.Priority = PriorityNormal
+	}
+	if _, ok := priorityRanks[spec.Priority]; !ok {
+		return fmt.Errorf("handler %s has unknown priority: %s", spec.Name, spec.Priority)
+	}
+	// Declaring permissions or moving money implies the handler needs an identity
+	if len(spec.Permissions) > 0 || spec.MovesMoney {
+		spec.RequireAuth = true
+	}
+	return nil
+}
+
+// Get returns the spec registered under name
+func (r *HandlerRegistry) Get(name string) (HandlerSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[name]
+	if !ok {
+		return HandlerSpec{}, false
+	}
+	return *spec, true
+}
+
+// Specs returns all registered specs sorted by name, suitable for documentation
+func (r *HandlerRegistry) Specs() []HandlerSpec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	specs := make([]HandlerSpec, 0, len(r.specs))
+	for _, spec := range r.specs {
+		specs = append(specs, *spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// Use adds middleware run around every handler, outside the declared
+// checks, in the order given
+func (r *HandlerRegistry) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Wrap returns a MessageHandler that runs the registry's middleware, then
+// enforces the spec, then runs the spec's own middleware and its handler
+func (r *HandlerRegistry) Wrap(name string) MessageHandler {
+	return func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		spec, ok := r.Get(name)
+		if !ok {
+			return errorReply(msg, "error", "Unknown message type: "+msg.Type)
+		}
+
+		r.mu.RLock()
+		chain := append([]Middleware(nil), r.middleware...)
+		r.mu.RUnlock()
+		chain = append(chain, r.guards(spec)...)
+		chain = append(chain, spec.Middleware...)
+		return Chain(spec.Handler, chain...)(ctx, conn, msg)
+	}
+}
+
+// guards returns the checks a spec declares, in the order they run. Each
+// refuses on the handler's own reply type.
+func (r *HandlerRegistry) guards(spec HandlerSpec) []Middleware {
+	responseType := spec.ResponseType
+	guards := []Middleware{
+		// Shed before any other work, which is what an overloaded server lacks
+		func(next MessageHandler) MessageHandler {
+			return func(ctx context.Context, conn *Connection, msg *Message) *Message {
+				if shedder := r.loadShedder(); shedder != nil && shedder.ShedMessage(spec.Priority) {
+					return busyReply(msg, responseType, shedder.Policy().CheckInterval)
+				}
+				return next(ctx, conn, msg)
+			}
+		},
+	}
+
+	if spec.RequireAuth {
+		guards = append(guards, check(func(conn *Connection, msg *Message) *Message {
+			if conn.UserID == "" {
+				return errorReply(msg, responseType, "Authentication required")
+			}
+			return nil
+		}))
+	}
+
+	if len(spec.Permissions) > 0 {
+		guards = append(guards, check(func(conn *Connection, msg *Message) *Message {
+			for _, permission := range spec.Permissions {
+				allowed, err := r.checkPermission(conn.UserID, permission)
+				if err != nil {
+					conn.Logf("HandlerRegistry: permission check failed for %s: %v", spec.Name, err)
+					return errorReply(msg, responseType, "Failed to check permissions")
+				}
+				if !allowed {
+					return errorReply(msg, responseType, "Insufficient permissions")
+				}
+			}
+			return nil
+		}))
+	}
+
+	guards = append(guards, check(func(conn *Connection, msg *Message) *Message {
+		if conn.Bot != nil {
+			if !spec.AllowBots {
+				return errorReply(msg, responseType, "Bot tokens cannot use this handler")
+			}
+			if err := r.checkBotAccess(conn, msg); err != nil {
+				return errorReply(msg, responseType, err.Error())
+			}
+		}
+		if err := r.checkAccess(conn, msg); err != nil {
+			return errorReply(msg, responseType, err.Error())
+		}
+		return nil
+	}))
+
+	if spec.Chat {
+		guards = append(guards, check(func(conn *Connection, msg *Message) *Message {
+			if err := r.checkMute(conn); err != nil {
+				return errorReply(msg, responseType, err.Error())
+			}
+			return nil
+		}))
+	}
+
+	guards = append(guards, check(func(conn *Connection, msg *Message) *Message {
+		if !r.exempt(conn.UserID) {
+			if err := r.checkClassLimit(conn.ID, spec.RateLimitClass, conn.Bot != nil); err != nil {
+				return errorReply(msg, responseType, err.Error())
+			}
+		}
+		if errs := validatePayload(spec.Schema, spec.Strict, spec.MovesMoney, msg.Data); len(errs) > 0 {
+			return validationReply(msg, responseType, errs)
+		}
+		return nil
+	}))
+
+	// Checked last so a refused request does not use up its nonce
+	if spec.MovesMoney {
+		guards = append(guards, check(func(conn *Connection, msg *Message) *Message {
+			if err := r.checkReplay(conn.UserID, msg.Data); err != nil {
+				return errorReply(msg, responseType, "Replay protection: "+err.Error())
+			}
+			return nil
+		}))
+	}
+	return guards
+}
+
+// loadShedder returns the configured shedder, if any
+func (r *HandlerRegistry) loadShedder() *LoadShedder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.shedder
+}
+
+// checkPermission resolves a permission through the configured checker.
+// Without a checker, permission-gated handlers are denied.
+func (r *HandlerRegistry) checkPermission(userID, permission string) (bool, error) {
+	r.mu.RLock()
+	checker := r.permissionChecker
+	r.mu.RUnlock()
+	if checker == nil {
+		return false, nil
+	}
+	return checker(userID, permission)
+}
+
+// checkBotAccess runs the configured bot access checker, if any
+func (r *HandlerRegistry) checkBotAccess(conn *Connection, msg *Message) error {
+	r.mu.RLock()
+	checker := r.botAccessChecker
+	r.mu.RUnlock()
+	if checker == nil {
+		return nil
+	}
+	return checker(conn, msg)
+}
+
+// checkAccess runs the configured access checker, if any
+func (r *HandlerRegistry) checkAccess(conn *Connection, msg *Message) error {
+	r.mu.RLock()
+	checker := r.accessChecker
+	r.mu.RUnlock()
+	if checker == nil {
+		return nil
+	}
+	return checker(conn, msg)
+}
+
+// checkMute refuses chat from connections serving a rate-limit mute
+func (r *HandlerRegistry) checkMute(conn *Connection) error {
+	r.mu.RLock()
+	tracker := r.penalties
+	r.mu.RUnlock()
+	if tracker == nil {
+		return nil
+	}
+	if until, muted := tracker.MutedUntil(penaltySubject(conn)); muted {
+		return fmt.Errorf("chat muted until %s due to repeated rate limit violations", until.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// exempt reports whether a user is a system actor exempt from class budgets
+func (r *HandlerRegistry) exempt(userID string) bool {
+	r.mu.RLock()
+	exemptions := r.exemptions
+	r.mu.RUnlock()
+	if exemptions == nil {
+		return false
+	}
+	_, ok := exemptions.Actor(userID)
+	return ok
+}
+
+// checkReplay verifies a money-moving request's nonce and expiry
+func (r *HandlerRegistry) checkReplay(userID string, data interface{}) error {
+	r.mu.RLock()
+	guard := r.replays
+	r.mu.RUnlock()
+	return guard.Check(userID, data)
+}
+
+// checkClassLimit applies the per-class budget for a connection; bots use
+// the stricter bot budgets
+func (r *HandlerRegistry) checkClassLimit(connectionID string, class RateLimitClass, bot bool) error {
+	budgets := rateLimitClassBudgets
+	if bot {
+		budgets = botRateLimitBudgets
+	}
+	budget, ok := budgets[class]
+	if !ok {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := connectionID + ":" + string(class)
+	now := time.Now()
+	window := r.classWindows[key]
+	if window == nil || now.Sub(window.windowStart) >= time.Second {
+		if window == nil && len(r.classWindows) >= maxClassWindows {
+			r.pruneClassWindows(now)
+		}
+		r.classWindows[key] = &classWindow{windowStart: now, count: 1}
+		return nil
+	}
+
+	window.count++
+	if window.count > budget {
+		return fmt.Errorf("rate limit exceeded: max %d %s messages per second", budget, class)
+	}
+	return nil
+}
+
+// pruneClassWindows drops expired windows so closed connections do not accumulate
+func (r *HandlerRegistry) pruneClassWindows(now time.Time) {
+	for key, window := range r.classWindows {
+		if now.Sub(window.windowStart) >= time.Second {
+			delete(r.classWindows, key)
+		}
+	}
+}
+
+// matchesFieldType reports whether a decoded JSON value matches a schema type
+func matchesFieldType(fieldType string, value interface{}) bool {
+	switch fieldType {
+	case "", "any":
+		return true
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int64, int32, uint, uint64:
+			return true
+		}
+		return false
+	case "bool":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	default:
+		return false
+	}
+}
+
+// errorReply builds a failed response for a request
+func errorReply(msg *Message, responseType, errMsg string) *Message {
+	return &Message{
+		Type:      responseType,
+		RequestID: msg.RequestID,
+		Success:   false,
+		Error:     errMsg,
+	}
+}
@@ -0,0 +1,212 @@
+package websocket_v2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func okHandler(ctx context.Context, conn *Connection, msg *Message) *Message {
+	return &Message{Type: msg.Type + "_response", RequestID: msg.RequestID, Success: true}
+}
+
+func TestHandlerRegistryRegisterValidation(t *testing.T) {
+	registry := NewHandlerRegistry()
+
+	assert.Error(t, registry.Register(HandlerSpec{Handler: okHandler}), "name is required")
+	assert.Error(t, registry.Register(HandlerSpec{Name: "no_handler"}), "handler is required")
+	assert.Error(t, registry.Register(HandlerSpec{Name: "bad_class", Handler: okHandler, RateLimitClass: "bogus"}))
+
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "ping", Handler: okHandler}))
+	assert.Error(t, registry.Register(HandlerSpec{Name: "ping", Handler: okHandler}), "duplicates are rejected")
+
+	spec, ok := registry.Get("ping")
+	assert.True(t, ok)
+	assert.Equal(t, RateLimitDefault, spec.RateLimitClass)
+}
+
+func TestHandlerRegistryEnforcesAuth(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "secret", RequireAuth: true, Handler: okHandler}))
+	handler := registry.Wrap("secret")
+
+	resp := handler(context.Background(), &Connection{ID: "c1"}, &Message{Type: "secret", RequestID: "r1"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "secret_response", resp.Type)
+	assert.Equal(t, "r1", resp.RequestID)
+	assert.Equal(t, "Authentication required", resp.Error)
+
+	resp = handler(context.Background(), &Connection{ID: "c1", UserID: "7"}, &Message{Type: "secret"})
+	assert.True(t, resp.Success)
+}
+
+func TestHandlerRegistryEnforcesPermissions(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "admin_op", Permissions: []string{"admin.access"}, Handler: okHandler}))
+
+	spec, _ := registry.Get("admin_op")
+	assert.True(t, spec.RequireAuth, "permissions imply authentication")
+
+	handler := registry.Wrap("admin_op")
+	conn := &Connection{ID: "c1", UserID: "7"}
+
+	// Without a checker permission-gated handlers are denied
+	resp := handler(context.Background(), conn, &Message{Type: "admin_op"})
+	assert.Equal(t, "Insufficient permissions", resp.Error)
+
+	registry.SetPermissionChecker(func(userID, permission string) (bool, error) {
+		return userID == "7" && permission == "admin.access", nil
+	})
+	resp = handler(context.Background(), conn, &Message{Type: "admin_op"})
+	assert.True(t, resp.Success)
+
+	resp = handler(context.Background(), &Connection{ID: "c2", UserID: "8"}, &Message{Type: "admin_op"})
+	assert.False(t, resp.Success)
+}
+
+func TestHandlerRegistryValidatesSchema(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{
+		Name: "act",
+		Schema: []FieldSpec{
+			{Name: "table_id", Type: "string", Required: true},
+			{Name: "amount", Type: "number"},
+		},
+		Handler: okHandler,
+	}))
+	handler := registry.Wrap("act")
+	conn := &Connection{ID: "c1"}
+
+	resp := handler(context.Background(), conn, &Message{Type: "act"})
+	assert.Equal(t, "Invalid request data: table_id is required", resp.Error)
+
+	resp = handler(context.Background(), conn, &Message{Type: "act", Data: map[string]interface{}{"table_id": 5.0}})
+	assert.Equal(t, "Invalid request data: table_id must be of type string", resp.Error)
+
+	resp = handler(context.Background(), conn, &Message{Type: "act", Data: "table-1"})
+	assert.False(t, resp.Success)
+
+	resp = handler(context.Background(), conn, &Message{Type: "act", Data: map[string]interface{}{"table_id": "t1", "amount": 20.0}})
+	assert.True(t, resp.Success)
+}
+
+func TestHandlerRegistryRateLimitClass(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "costly", RateLimitClass: RateLimitStrict, Handler: okHandler}))
+	handler := registry.Wrap("costly")
+
+	resp := handler(context.Background(), &Connection{ID: "c1"}, &Message{Type: "costly"})
+	assert.True(t, resp.Success)
+
+	resp = handler(context.Background(), &Connection{ID: "c1"}, &Message{Type: "costly"})
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "rate limit exceeded")
+
+	// Budgets are tracked per connection
+	resp = handler(context.Background(), &Connection{ID: "c2"}, &Message{Type: "costly"})
+	assert.True(t, resp.Success)
+}
+
+func TestServerHandlerSpecsIncludesBuiltins(t *testing.T) {
+	server := NewServer(nil)
+	defer server.hub.(*ActorHub).Stop()
+
+	names := make(map[string]bool)
+	for _, spec := range server.HandlerSpecs() {
+		names[spec.Name] = true
+	}
+	for _, name := range []string{"echo", "get_room_info", "get_users", "send_to_room", "request"} {
+		assert.True(t, names[name], "missing built-in handler %s", name)
+	}
+}
+
+func TestServerRegisterHandlerOverridesBuiltin(t *testing.T) {
+	server := NewServer(nil)
+	defer server.hub.(*ActorHub).Stop()
+
+	server.RegisterHandler("request", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		return &Message{Type: "response", RequestID: msg.RequestID, Success: true, Data: "custom"}
+	})
+	handler := server.registry.Wrap("request")
+	conn := &Connection{ID: "c1"}
+
+	resp := handler(context.Background(), conn, &Message{Type: "request", Data: map[string]interface{}{"action": "anything"}})
+	assert.True(t, resp.Success)
+	assert.Equal(t, "custom", resp.Data, "the replacement handles the request")
+
+	// The declared schema still guards the replacement, and refusals use the
+	// handler's own reply type
+	resp = handler(context.Background(), conn, &Message{Type: "request", RequestID: "r1"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "response", resp.Type)
+	assert.Equal(t, "Invalid request data: action is required", resp.Error)
+
+	server.RegisterHandler("custom_type", okHandler)
+	spec, ok := server.registry.Get("custom_type")
+	require.True(t, ok)
+	assert.Equal(t, "custom_type_response", spec.ResponseType)
+}
+
+func TestServerMustRegisterPanicsOnInvalidSpec(t *testing.T) {
+	server := NewServer(nil)
+	defer server.hub.(*ActorHub).Stop()
+
+	assert.Panics(t, func() { server.mustRegister(HandlerSpec{Name: "echo", Handler: okHandler}) }, "built-ins cannot be declared twice")
+	assert.Panics(t, func() { server.mustRegister(HandlerSpec{Name: "broken"}) })
+}
+
+func TestHandlerRegistryRunsAccessChecker(t *testing.T) {
+	registry := NewHandlerRegistry()
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "play", RequireAuth: true, AllowBots: true, Handler: okHandler}))
+	handler := registry.Wrap("play")
+
+	registry.SetAccessChecker(func(conn *Connection, msg *Message) error {
+		if conn.RemoteIP == "203.0.113.9" {
+			return errors.New("real-money play is not available in your region (US)")
+		}
+		return nil
+	})
+
+	resp := handler(context.Background(), &Connection{ID: "c1", UserID: "7", RemoteIP: "203.0.113.9"}, &Message{Type: "play"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "real-money play is not available in your region (US)", resp.Error)
+
+	resp = handler(context.Background(), &Connection{ID: "c2", UserID: "7", RemoteIP: "203.0.113.9", Bot: &BotScope{}}, &Message{Type: "play"})
+	assert.False(t, resp.Success, "checker also applies to bot connections")
+
+	resp = handler(context.Background(), &Connection{ID: "c3", UserID: "8", RemoteIP: "198.51.100.1"}, &Message{Type: "play"})
+	assert.True(t, resp.Success)
+}
+
+func TestClientIP(t *testing.T) {
+	request := func(remote string, headers map[string]string) *http.Request {
+		r := httptest.NewRequest("GET", "/ws", nil)
+		r.RemoteAddr = remote
+		for key, value := range headers {
+			r.Header.Set(key, value)
+		}
+		return r
+	}
+
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.50"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "192.0.2.1", clientIP(request("192.0.2.1:5000", nil), trusted))
+	assert.Equal(t, "203.0.113.9", clientIP(request("10.0.0.1:5000", map[string]string{"X-Forwarded-For": "203.0.113.9, 10.0.0.2"}), trusted))
+	assert.Equal(t, "198.51.100.7", clientIP(request("192.0.2.50:5000", map[string]string{"X-Real-IP": "198.51.100.7"}), trusted))
+
+	// A spoofed entry in front of the real client is ignored
+	assert.Equal(t, "203.0.113.9", clientIP(request("10.0.0.1:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.9"}), trusted))
+
+	// Clients that do not come through a trusted proxy cannot set their address
+	assert.Equal(t, "192.0.2.1", clientIP(request("192.0.2.1:5000", map[string]string{"X-Forwarded-For": "203.0.113.9"}), trusted))
+	assert.Equal(t, "192.0.2.1", clientIP(request("192.0.2.1:5000", map[string]string{"X-Real-IP": "203.0.113.9"}), nil))
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// HeartbeatTimeoutCode is the close reason given to connections reaped for
+// not answering pings
+const HeartbeatTimeoutCode = "heartbeat_timeout"
+
+// Heartbeat defaults
+const (
+	DefaultPingInterval = 54 * time.Second // How often the server pings each client
+	DefaultPongTimeout  = 60 * time.Second // Silence after which a client counts as gone
+	reapInterval        = time.Second      // How often the hub looks for dead connections
+)
+
+// HeartbeatPolicy sets how often connections are pinged and how long a
+// client may go without answering before the hub reaps the connection
+type HeartbeatPolicy struct {
+	PingInterval time.Duration `json:"ping_interval"`
+	PongTimeout  time.Duration `json:"pong_timeout"` // Must exceed PingInterval so one ping can be answered
+}
+
+// DefaultHeartbeatPolicy pings every 54 seconds and gives up on clients
+// silent for a minute
+func DefaultHeartbeatPolicy() HeartbeatPolicy {
+	return HeartbeatPolicy{PingInterval: DefaultPingInterval, PongTimeout: DefaultPongTimeout}
+}
+
+// Validate checks the intervals
+func (p HeartbeatPolicy) Validate() error {
+	if p.PingInterval <= 0 {
+		return fmt.Errorf("ping interval must be positive")
+	}
+	if p.PongTimeout <= p.PingInterval {
+		return fmt.Errorf("pong timeout must be longer than the ping interval")
+	}
+	return nil
+}
+
+// heartbeatPolicy returns the connection's policy, the default if none was set
+func (c *Connection) heartbeatPolicy() HeartbeatPolicy {
+	if c.heartbeat.PingInterval <= 0 {
+		return DefaultHeartbeatPolicy()
+	}
+	return c.heartbeat
+}
+
+// markAlive records that the client answered and extends the read deadline
+func (c *Connection) markAlive() {
+	now := time.Now()
+	c.lastPong.Store(now.UnixNano())
+	c.Conn.SetReadDeadline(now.Add(c.heartbeatPolicy().PongTimeout))
+}
+
+// missedHeartbeats reports whether the client has been silent past its pong
+// timeout; connections whose pumps have not started are never counted dead
+func (c *Connection) missedHeartbeats(now time.Time) bool {
+	last := c.lastPong.Load()
+	return last != 0 && now.Sub(time.Unix(0, last)) > c.heartbeatPolicy().PongTimeout
+}
+
+// closeDead tells a client that may still be listening why it is dropped,
+// then closes the socket
+func (c *Connection) closeDead() {
+	if c.Conn == nil {
+		return
+	}
+	reason := websocket.FormatCloseMessage(websocket.CloseGoingAway, HeartbeatTimeoutCode)
+	c.Conn.WriteControl(websocket.CloseMessage, reason, time.Now().Add(time.Second))
+	c.Conn.Close()
+}
+
+// reapLoop has the actor look for dead connections every reapInterval
+func (h *ActorHub) reapLoop() {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+			select {
+			case h.hubChannel <- HubMessage{Type: "reap_dead_connections"}:
+			case <-h.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// reapDeadConnections unregisters connections that missed their heartbeats
+// and returns how many there were
+func (h *ActorHub) reapDeadConnections() int {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:     "reap_dead_connections",
+		Response: response,
+	}
+	result := <-response
+	close(response)
+
+	reaped, _ := result.(int)
+	return reaped
+}
+
+// actorReapDeadConnections unregisters every connection silent past its pong
+// timeout, so its user leaves rooms, topics and presence at once rather than
+// lingering until the socket errors; the socket is closed off the actor
+// (actor method)
+func (h *ActorHub) actorReapDeadConnections(response chan interface{}) {
+	now := time.Now()
+	reaped := 0
+	for _, conn := range h.connections {
+		if !conn.missedHeartbeats(now) {
+			continue
+		}
+		conn.Logf("ActorHub: Connection %s (%s) missed its heartbeats, reaping it", conn.ID, conn.Username)
+		h.actorUnregisterConnection(conn, make(chan interface{}, 1))
+		h.reaped.Add(1)
+		reaped++
+		go conn.closeDead()
+	}
+	if response != nil {
+		response <- reaped
+	}
+}
+
+// ReapedConnections returns how many connections have been dropped for
+// missing heartbeats
+func (h *ActorHub) ReapedConnections() int64 {
+	return h.reaped.Load()
+}
@@ -0,0 +1,80 @@
+package websocket_v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatPolicyValidate(t *testing.T) {
+	assert.NoError(t, DefaultHeartbeatPolicy().Validate())
+	assert.Error(t, HeartbeatPolicy{PingInterval: 0, PongTimeout: time.Second}.Validate())
+	assert.Error(t, HeartbeatPolicy{PingInterval: time.Second, PongTimeout: time.Second}.Validate(), "a ping must have time to be answered")
+}
+
+func TestHubReapsConnectionsThatMissHeartbeats(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	hub.SetAuthHandler(func(token string) (*AuthResult, error) {
+		return &AuthResult{Success: true, UserID: "7", Username: "player7"}, nil
+	})
+	presence := &presenceLog{}
+	hub.SetPresenceHandler(presence.record)
+
+	ghost := authenticate(hub, "c1")
+	live := authenticate(hub, "c2")
+	unstarted := &Connection{ID: "c3", Send: make(chan []byte, 16), Rooms: make(map[string]bool)}
+	hub.Register(unstarted)
+	ghost.lastPong.Store(time.Now().Add(-2 * DefaultPongTimeout).UnixNano())
+	live.lastPong.Store(time.Now().UnixNano())
+
+	assert.Equal(t, 1, hub.reapDeadConnections())
+	assert.Equal(t, 2, hub.GetConnectionCount(), "connections still answering, or not started, are kept")
+	assert.Equal(t, []string{"7:true"}, presence.waitFor(t, 1), "the user is still online through the live connection")
+
+	live.lastPong.Store(time.Now().Add(-2 * DefaultPongTimeout).UnixNano())
+	assert.Equal(t, 1, hub.reapDeadConnections())
+	assert.Equal(t, []string{"7:true", "7:false"}, presence.waitFor(t, 2))
+	assert.Equal(t, int64(2), hub.ReapedConnections())
+}
+
+func TestSilentClientsAreDroppedAndAnsweringOnesKept(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := newConnection(hub, w, r, CompressionPolicy{})
+		require.NoError(t, err)
+		conn.heartbeat = HeartbeatPolicy{PingInterval: 10 * time.Millisecond, PongTimeout: 50 * time.Millisecond}
+		hub.Register(conn)
+		conn.Start()
+	}))
+	defer server.Close()
+	dial := func() *websocket.Conn {
+		client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+
+	// Reading answers pings; a client that never reads never answers them
+	answering := dial()
+	go func() {
+		for {
+			if _, _, err := answering.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	dial()
+	require.Eventually(t, func() bool { return hub.GetConnectionCount() == 2 }, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool { return hub.GetConnectionCount() == 1 }, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, 1, hub.GetConnectionCount(), "a client answering pings outlives several pong timeouts")
+}
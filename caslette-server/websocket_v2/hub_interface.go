@@ -1,5 +1,7 @@
 package websocket_v2
 
+import "context"
+
 // HubInterface defines the interface that both Hub and ActorHub implement
 type HubInterface interface {
 	// Connection management
@@ -16,15 +18,40 @@ type HubInterface interface {
 	// Broadcasting
 	BroadcastToRoom(room string, msg *Message)
 	BroadcastToUser(userID string, msg *Message)
+	SendToDevice(userID, device string, msg *Message)
 	BroadcastToAll(msg *Message)
 
+	// Topics are server-published streams joined only through authorized handlers
+	SubscribeTopic(connectionID, topic string) error
+	UnsubscribeTopic(connectionID, topic string)
+	PublishToTopic(topic string, msg *Message) int
+
+	// Outbox records user-addressed messages for long-poll clients
+	Outbox() *OutboxStore
+
+	// Sessions lets dropped connections resume within a window
+	Sessions() *SessionStore
+
+	// SetBackplane joins the hub to the other instances of a cluster
+	SetBackplane(backplane Backplane) error
+
+	// Penalties escalates sanctions against rate-limit violators
+	Penalties() *PenaltyTracker
+
+	// RateLimitExemptions lets system actors bypass rate limits
+	RateLimitExemptions() *RateLimitExemptions
+
 	// Configuration
 	SetAuthHandler(handler AuthHandler)
+	SetSessionPolicy(policy SessionPolicy) error
 	RegisterMessageHandler(messageType string, handler MessageHandler)
 
 	// Lifecycle
 	Start()
+	Shutdown(ctx context.Context) error
+	SetShutdownDrainer(drainer ShutdownDrainer)
 	GetConnectionCount() int
+	Stats() HubStats
 }
 
 // Ensure ActorHub satisfies the interface
@@ -0,0 +1,349 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ServerBusyCode is the error code of requests and connections refused
+// while the server sheds load
+const ServerBusyCode = "server_busy"
+
+// MessagePriority decides which messages are shed first when the server is
+// overloaded
+type MessagePriority string
+
+const (
+	// PriorityLow covers browsing, chat, stats and history lookups
+	PriorityLow MessagePriority = "low"
+	// PriorityNormal is the default
+	PriorityNormal MessagePriority = "normal"
+	// PriorityCritical covers play in hands under way; it is never shed
+	PriorityCritical MessagePriority = "critical"
+)
+
+// priorityRanks orders priorities from the first shed to the last
+var priorityRanks = map[MessagePriority]int{
+	PriorityLow:      0,
+	PriorityNormal:   1,
+	PriorityCritical: 2,
+}
+
+// ShedPolicy sets when the server counts as overloaded and what it refuses
+// while it is. A zero threshold ignores that signal.
+type ShedPolicy struct {
+	Enabled           bool            `json:"enabled"`
+	MaxMailboxDepth   int             `json:"max_mailbox_depth"` // Commands queued in the deepest actor mailbox
+	MaxCPU            float64         `json:"max_cpu"`           // Share of GOMAXPROCS busy, from 0 to 1
+	MaxDBLatency      time.Duration   `json:"max_db_latency"`    // Round trip of a database ping
+	ShedPriority      MessagePriority `json:"shed_priority"`     // Messages of this priority and lower are refused
+	RejectConnections bool            `json:"reject_connections"`
+	CheckInterval     time.Duration   `json:"check_interval"`
+}
+
+// DefaultShedPolicy sheds low-priority messages and new connections once a
+// mailbox backs up, the CPUs are nearly saturated or the database slows down
+func DefaultShedPolicy() ShedPolicy {
+	return ShedPolicy{
+		Enabled:           true,
+		MaxMailboxDepth:   80,
+		MaxCPU:            0.9,
+		MaxDBLatency:      500 * time.Millisecond,
+		ShedPriority:      PriorityLow,
+		RejectConnections: true,
+		CheckInterval:     2 * time.Second,
+	}
+}
+
+// Validate checks the thresholds and that critical messages are never shed
+func (p ShedPolicy) Validate() error {
+	if p.MaxMailboxDepth < 0 || p.MaxCPU < 0 || p.MaxDBLatency < 0 {
+		return fmt.Errorf("load shedding thresholds cannot be negative")
+	}
+	if p.MaxCPU > 1 {
+		return fmt.Errorf("load shedding CPU threshold must be between 0 and 1")
+	}
+	if p.ShedPriority != PriorityLow && p.ShedPriority != PriorityNormal {
+		return fmt.Errorf("load shedding priority must be %s or %s", PriorityLow, PriorityNormal)
+	}
+	if p.CheckInterval <= 0 {
+		return fmt.Errorf("load shedding check interval must be positive")
+	}
+	return nil
+}
+
+// LoadStatus is the latest health reading and what has been shed
+type LoadStatus struct {
+	Overloaded      bool           `json:"overloaded"`
+	Reasons         []string       `json:"reasons"`
+	MailboxDepths   map[string]int `json:"mailbox_depths"`
+	CPU             float64        `json:"cpu"`
+	DBLatencyMs     int64          `json:"db_latency_ms"`
+	CheckedAt       time.Time      `json:"checked_at"`
+	ShedMessages    int64          `json:"shed_messages"`
+	ShedConnections int64          `json:"shed_connections"`
+	Policy          ShedPolicy     `json:"policy"`
+}
+
+// LoadShedder watches mailbox depth, CPU and database latency and, while any
+// is over its threshold, refuses low-priority messages and new connections
+// with ServerBusyCode. Critical messages, such as actions in hands under
+// way, always go through.
+type LoadShedder struct {
+	mu        sync.RWMutex
+	policy    ShedPolicy
+	mailboxes map[string]func() int
+	dbProbe   func() (time.Duration, error)
+	cpuProbe  func() float64
+	status    LoadStatus
+	stop      chan struct{}
+
+	overloaded      atomic.Bool
+	shedMessages    atomic.Int64
+	shedConnections atomic.Int64
+}
+
+// NewLoadShedder creates a shedder with the default policy, reading CPU use
+// from the Go runtime
+func NewLoadShedder() *LoadShedder {
+	return &LoadShedder{
+		policy:    DefaultShedPolicy(),
+		mailboxes: make(map[string]func() int),
+		cpuProbe:  newCPUSampler(),
+		status:    LoadStatus{Reasons: make([]string, 0), MailboxDepths: make(map[string]int)},
+	}
+}
+
+// SetPolicy changes the thresholds and what is shed; the next check applies them
+func (s *LoadShedder) SetPolicy(policy ShedPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+	if !policy.Enabled {
+		s.overloaded.Store(false)
+	}
+	return nil
+}
+
+// Policy returns the policy in force
+func (s *LoadShedder) Policy() ShedPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// AddMailbox watches the depth of an actor mailbox, e.g. the hub's or the
+// deepest table's
+func (s *LoadShedder) AddMailbox(name string, depth func() int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mailboxes[name] = depth
+}
+
+// SetDBProbe sets the function timing a database round trip
+func (s *LoadShedder) SetDBProbe(probe func() (time.Duration, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dbProbe = probe
+}
+
+// Check takes a health reading and decides whether to shed load until the
+// next one
+func (s *LoadShedder) Check() LoadStatus {
+	s.mu.RLock()
+	policy := s.policy
+	mailboxes := make(map[string]func() int, len(s.mailboxes))
+	for name, depth := range s.mailboxes {
+		mailboxes[name] = depth
+	}
+	dbProbe, cpuProbe := s.dbProbe, s.cpuProbe
+	s.mu.RUnlock()
+
+	status := LoadStatus{
+		Reasons:       make([]string, 0),
+		MailboxDepths: make(map[string]int, len(mailboxes)),
+		CheckedAt:     time.Now(),
+		Policy:        policy,
+	}
+	names := make([]string, 0, len(mailboxes))
+	for name := range mailboxes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		depth := mailboxes[name]()
+		status.MailboxDepths[name] = depth
+		if policy.MaxMailboxDepth > 0 && depth > policy.MaxMailboxDepth {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("%s mailbox holds %d commands", name, depth))
+		}
+	}
+	if cpuProbe != nil {
+		status.CPU = cpuProbe()
+		if policy.MaxCPU > 0 && status.CPU > policy.MaxCPU {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("CPU at %.0f%%", status.CPU*100))
+		}
+	}
+	if dbProbe != nil {
+		latency, err := dbProbe()
+		status.DBLatencyMs = latency.Milliseconds()
+		switch {
+		case err != nil:
+			status.Reasons = append(status.Reasons, "database unreachable: "+err.Error())
+		case policy.MaxDBLatency > 0 && latency > policy.MaxDBLatency:
+			status.Reasons = append(status.Reasons, fmt.Sprintf("database latency %dms", status.DBLatencyMs))
+		}
+	}
+	status.Overloaded = policy.Enabled && len(status.Reasons) > 0
+
+	if was := s.overloaded.Swap(status.Overloaded); was != status.Overloaded {
+		if status.Overloaded {
+			log.Printf("LoadShedder: shedding %s-priority load: %v", policy.ShedPriority, status.Reasons)
+		} else {
+			log.Printf("LoadShedder: load back to normal, no longer shedding")
+		}
+	}
+
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+	return s.Status()
+}
+
+// Status returns the latest reading with the shed counters
+func (s *LoadShedder) Status() LoadStatus {
+	s.mu.RLock()
+	status := s.status
+	status.Policy = s.policy
+	s.mu.RUnlock()
+	status.ShedMessages = s.shedMessages.Load()
+	status.ShedConnections = s.shedConnections.Load()
+	return status
+}
+
+// Shedding reports whether load is being shed
+func (s *LoadShedder) Shedding() bool {
+	return s.overloaded.Load()
+}
+
+// ShedMessage reports whether a message of the given priority is refused
+// now, counting it if so
+func (s *LoadShedder) ShedMessage(priority MessagePriority) bool {
+	if !s.overloaded.Load() {
+		return false
+	}
+	if priority == "" {
+		priority = PriorityNormal
+	}
+	if priorityRanks[priority] > priorityRanks[s.Policy().ShedPriority] {
+		return false
+	}
+	s.shedMessages.Add(1)
+	return true
+}
+
+// ShedConnection reports whether a new connection is refused now, counting
+// it if so
+func (s *LoadShedder) ShedConnection() bool {
+	if !s.overloaded.Load() || !s.Policy().RejectConnections {
+		return false
+	}
+	s.shedConnections.Add(1)
+	return true
+}
+
+// Start checks the load every policy interval until Stop is called
+func (s *LoadShedder) Start() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	stop := s.stop
+	s.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(s.Policy().CheckInterval):
+				s.Check()
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic checks
+func (s *LoadShedder) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// busyReply refuses a shed message
+func busyReply(msg *Message, responseType string, retryAfter time.Duration) *Message {
+	return &Message{
+		Type:      responseType,
+		RequestID: msg.RequestID,
+		Success:   false,
+		Error:     "Server busy, please retry later",
+		Data: map[string]interface{}{
+			"code":           ServerBusyCode,
+			"message_type":   msg.Type,
+			"retry_after_ms": retryAfter.Milliseconds(),
+		},
+	}
+}
+
+// refuseBusyConnection answers a WebSocket upgrade refused while shedding
+func refuseBusyConnection(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", max(int(retryAfter/time.Second), 1)))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	fmt.Fprintf(w, `{"success":false,"code":%q,"error":"Server busy, please retry later"}`, ServerBusyCode)
+}
+
+// newCPUSampler returns a probe giving the share of GOMAXPROCS the process
+// kept busy since the previous call, from the runtime's CPU metrics
+func newCPUSampler() func() float64 {
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+	}
+	read := func() float64 {
+		metrics.Read(samples)
+		if samples[0].Value.Kind() != metrics.KindFloat64 || samples[1].Value.Kind() != metrics.KindFloat64 {
+			return 0
+		}
+		return samples[0].Value.Float64() - samples[1].Value.Float64()
+	}
+
+	var mu sync.Mutex
+	lastBusy, lastAt := read(), time.Now()
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		busy, now := read(), time.Now()
+		elapsed := now.Sub(lastAt).Seconds() * float64(runtime.GOMAXPROCS(0))
+		share := 0.0
+		if elapsed > 0 {
+			share = min(max((busy-lastBusy)/elapsed, 0), 1)
+		}
+		lastBusy, lastAt = busy, now
+		return share
+	}
+}
@@ -0,0 +1,134 @@
+package websocket_v2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestShedder returns a shedder whose mailbox depth and database latency
+// the test controls, with the CPU reading idle
+func newTestShedder(t *testing.T, depth *int, latency *time.Duration) *LoadShedder {
+	shedder := NewLoadShedder()
+	shedder.cpuProbe = func() float64 { return 0 }
+	shedder.AddMailbox("tables", func() int { return *depth })
+	shedder.SetDBProbe(func() (time.Duration, error) { return *latency, nil })
+	return shedder
+}
+
+func TestShedPolicyValidate(t *testing.T) {
+	assert.NoError(t, DefaultShedPolicy().Validate())
+
+	for name, change := range map[string]func(*ShedPolicy){
+		"negative mailbox": func(p *ShedPolicy) { p.MaxMailboxDepth = -1 },
+		"CPU over 1":       func(p *ShedPolicy) { p.MaxCPU = 1.5 },
+		"critical shed":    func(p *ShedPolicy) { p.ShedPriority = PriorityCritical },
+		"unknown priority": func(p *ShedPolicy) { p.ShedPriority = "bulk" },
+		"no interval":      func(p *ShedPolicy) { p.CheckInterval = 0 },
+	} {
+		policy := DefaultShedPolicy()
+		change(&policy)
+		assert.Error(t, policy.Validate(), name)
+	}
+}
+
+func TestLoadShedderShedsByPriorityUntilLoadDrops(t *testing.T) {
+	depth, latency := 0, 10*time.Millisecond
+	shedder := newTestShedder(t, &depth, &latency)
+
+	status := shedder.Check()
+	assert.False(t, status.Overloaded)
+	assert.False(t, shedder.ShedMessage(PriorityLow))
+
+	depth = 90
+	status = shedder.Check()
+	assert.True(t, status.Overloaded)
+	assert.Equal(t, []string{"tables mailbox holds 90 commands"}, status.Reasons)
+	assert.True(t, shedder.ShedMessage(PriorityLow))
+	assert.False(t, shedder.ShedMessage(PriorityNormal), "only low priority is shed by default")
+	assert.False(t, shedder.ShedMessage(PriorityCritical))
+
+	policy := DefaultShedPolicy()
+	policy.ShedPriority = PriorityNormal
+	require.NoError(t, shedder.SetPolicy(policy))
+	assert.True(t, shedder.ShedMessage(PriorityNormal))
+	assert.True(t, shedder.ShedMessage(""), "an undeclared priority counts as normal")
+	assert.False(t, shedder.ShedMessage(PriorityCritical), "hands under way are never shed")
+
+	depth, latency = 0, 800*time.Millisecond
+	assert.Equal(t, []string{"database latency 800ms"}, shedder.Check().Reasons)
+
+	latency = 10 * time.Millisecond
+	status = shedder.Check()
+	assert.False(t, status.Overloaded)
+	assert.False(t, shedder.ShedMessage(PriorityLow), "shedding stops once the load drops")
+	assert.Equal(t, int64(3), status.ShedMessages)
+}
+
+func TestLoadShedderTreatsDatabaseErrorsAsOverload(t *testing.T) {
+	shedder := NewLoadShedder()
+	shedder.cpuProbe = func() float64 { return 0.95 }
+	shedder.SetDBProbe(func() (time.Duration, error) { return 0, errors.New("connection refused") })
+
+	status := shedder.Check()
+	assert.True(t, status.Overloaded)
+	assert.Equal(t, []string{"CPU at 95%", "database unreachable: connection refused"}, status.Reasons)
+
+	policy := DefaultShedPolicy()
+	policy.Enabled = false
+	require.NoError(t, shedder.SetPolicy(policy))
+	assert.False(t, shedder.Check().Overloaded, "a disabled policy only reports")
+	assert.False(t, shedder.ShedMessage(PriorityLow))
+}
+
+func TestHandlerRegistryShedsLowPriorityMessages(t *testing.T) {
+	depth, latency := 200, time.Duration(0)
+	shedder := newTestShedder(t, &depth, &latency)
+	shedder.Check()
+
+	registry := NewHandlerRegistry()
+	registry.SetLoadShedder(shedder)
+	require.NoError(t, registry.Register(HandlerSpec{Name: "browse", Priority: PriorityLow, Handler: okHandler}))
+	require.NoError(t, registry.Register(HandlerSpec{Name: "act", Priority: PriorityCritical, Handler: okHandler}))
+	assert.Error(t, registry.Register(HandlerSpec{Name: "bad", Priority: "urgent", Handler: okHandler}))
+
+	resp := registry.Wrap("browse")(context.Background(), &Connection{ID: "c1"}, &Message{Type: "browse", RequestID: "r1"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "browse_response", resp.Type)
+	assert.Equal(t, "r1", resp.RequestID)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, ServerBusyCode, data["code"])
+	assert.Equal(t, DefaultShedPolicy().CheckInterval.Milliseconds(), data["retry_after_ms"])
+
+	resp = registry.Wrap("act")(context.Background(), &Connection{ID: "c1"}, &Message{Type: "act"})
+	assert.True(t, resp.Success, "critical messages go through while shedding")
+}
+
+func TestServerRefusesConnectionsWhileShedding(t *testing.T) {
+	server := NewServer(nil)
+	defer server.hub.(*ActorHub).Stop()
+	depth, latency := 200, time.Duration(0)
+	server.shedder = newTestShedder(t, &depth, &latency)
+	server.shedder.Check()
+
+	w := httptest.NewRecorder()
+	server.HandleWebSocket(w, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"code":"server_busy"`)
+	assert.Equal(t, int64(1), server.shedder.Status().ShedConnections)
+	assert.Equal(t, "overloaded", server.HealthStatus()["status"])
+
+	policy := DefaultShedPolicy()
+	policy.RejectConnections = false
+	require.NoError(t, server.SetShedPolicy(policy))
+	w = httptest.NewRecorder()
+	server.HandleWebSocket(w, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the upgrade is attempted once connections are no longer shed")
+}
@@ -0,0 +1,131 @@
+package websocket_v2
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Lobby topic settings
+const (
+	LobbyTopic           = "lobby"
+	LobbyUpdateType      = "lobby_stats_update"
+	DefaultLobbyInterval = 10 * time.Second
+)
+
+// LobbyPublisher periodically pushes the lobby's per-stake-level stats to
+// subscribers of the lobby topic. Unlike the stats topic it is open to any
+// signed-in player. Stats are only collected while someone is subscribed.
+type LobbyPublisher struct {
+	hub HubInterface
+
+	mu     sync.Mutex
+	source StatsSource
+	stop   chan struct{}
+}
+
+// NewLobbyPublisher creates a publisher for the hub's lobby topic
+func NewLobbyPublisher(hub HubInterface) *LobbyPublisher {
+	return &LobbyPublisher{hub: hub}
+}
+
+// SetSource sets what each lobby update carries
+func (p *LobbyPublisher) SetSource(source StatsSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.source = source
+}
+
+// Snapshot returns the current lobby stats, or an empty list without a source
+func (p *LobbyPublisher) Snapshot() interface{} {
+	p.mu.Lock()
+	source := p.source
+	p.mu.Unlock()
+	if source == nil {
+		return []interface{}{}
+	}
+	return source()
+}
+
+// Publish sends the lobby stats to the lobby topic, returning how many
+// connections received them. Nothing is collected without subscribers.
+func (p *LobbyPublisher) Publish() int {
+	if p.hub.Stats().TopicSubscribers[LobbyTopic] == 0 {
+		return 0
+	}
+	return p.hub.PublishToTopic(LobbyTopic, &Message{
+		Type:    LobbyUpdateType,
+		Event:   "lobby_stats",
+		Success: true,
+		Data:    map[string]interface{}{"stakes": p.Snapshot()},
+	})
+}
+
+// Start publishes the lobby stats on an interval until Stop is called
+func (p *LobbyPublisher) Start(interval time.Duration) {
+	if interval < MinStatsInterval {
+		interval = MinStatsInterval
+	}
+
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	p.stop = make(chan struct{})
+	stop := p.stop
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.Publish()
+			}
+		}
+	}()
+}
+
+// Stop halts periodic publishing
+func (p *LobbyPublisher) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// handleSubscribeLobby adds the connection to the lobby topic and replies
+// with the current stats so the lobby can render before the first push
+func (s *Server) handleSubscribeLobby(ctx context.Context, conn *Connection, msg *Message) *Message {
+	if err := s.hub.SubscribeTopic(conn.ID, LobbyTopic); err != nil {
+		return &Message{
+			Type:      "subscribe_lobby_stats_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     err.Error(),
+		}
+	}
+
+	return &Message{
+		Type:      "subscribe_lobby_stats_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data:      map[string]interface{}{"stakes": s.lobby.Snapshot()},
+	}
+}
+
+// handleUnsubscribeLobby removes the connection from the lobby topic
+func (s *Server) handleUnsubscribeLobby(ctx context.Context, conn *Connection, msg *Message) *Message {
+	s.hub.UnsubscribeTopic(conn.ID, LobbyTopic)
+	return &Message{
+		Type:      "unsubscribe_lobby_stats_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+	}
+}
@@ -0,0 +1,34 @@
+package websocket_v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLobbyTopicIsOpenToSignedInPlayers(t *testing.T) {
+	server := NewServer(nil)
+	hub := server.GetHub().(*ActorHub)
+	defer hub.Stop()
+	server.Lobby().SetSource(func() interface{} {
+		return []map[string]interface{}{{"stakes": "1/2", "tables_running": 3}}
+	})
+	assert.Equal(t, 0, server.Lobby().Publish(), "nothing is sent without subscribers")
+
+	conn := registerTestConnection(t, hub, "7")
+	hub.ProcessMessage(conn, &Message{Type: "subscribe_lobby_stats", RequestID: "r1"})
+	reply := readReply(t, conn)
+	require.True(t, reply.Success)
+	assert.Len(t, reply.Data.(map[string]interface{})["stakes"], 1, "the reply carries the current stats")
+
+	assert.Equal(t, 1, server.Lobby().Publish())
+	update := readReply(t, conn)
+	assert.Equal(t, LobbyUpdateType, update.Type)
+	stakes := update.Data.(map[string]interface{})["stakes"].([]interface{})
+	assert.Equal(t, float64(3), stakes[0].(map[string]interface{})["tables_running"])
+
+	hub.ProcessMessage(conn, &Message{Type: "unsubscribe_lobby_stats", RequestID: "r2"})
+	assert.True(t, readReply(t, conn).Success)
+	assert.Equal(t, 0, hub.Stats().TopicSubscribers[LobbyTopic])
+}
@@ -0,0 +1,141 @@
+package websocket_v2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageTooLargeCode is the error code sent for inbound messages over the
+// connection's limit
+const MessageTooLargeCode = "message_too_large"
+
+// Message size defaults
+const (
+	DefaultMaxMessageBytes = 4096      // Largest inbound message handled
+	DefaultChunkBytes      = 64 * 1024 // Outbound frames larger than this are split
+	hardReadLimitFactor    = 16        // Past this many times the limit the connection is dropped
+)
+
+// MessageSizePolicy limits inbound messages and splits large outbound ones,
+// such as full hand histories, into message_chunk frames
+type MessageSizePolicy struct {
+	MaxInboundBytes int `json:"max_inbound_bytes"` // Larger messages are answered with message_too_large
+	ChunkBytes      int `json:"chunk_bytes"`       // Outbound frames over this are chunked; zero never chunks
+}
+
+// DefaultMessageSizePolicy keeps the historical inbound limit and chunks
+// frames of more than 64 KiB
+func DefaultMessageSizePolicy() MessageSizePolicy {
+	return MessageSizePolicy{MaxInboundBytes: DefaultMaxMessageBytes, ChunkBytes: DefaultChunkBytes}
+}
+
+// Validate checks the limits
+func (p MessageSizePolicy) Validate() error {
+	if p.MaxInboundBytes <= 0 {
+		return fmt.Errorf("inbound message limit must be positive")
+	}
+	if p.ChunkBytes < 0 {
+		return fmt.Errorf("chunk size cannot be negative")
+	}
+	return nil
+}
+
+// maxInbound returns the connection's inbound message limit
+func (c *Connection) maxInbound() int {
+	if c.maxInboundBytes <= 0 {
+		return DefaultMaxMessageBytes
+	}
+	return c.maxInboundBytes
+}
+
+// readFrame reads the next message. One over the limit is discarded so the
+// connection survives it, and comes back with nil data and its full size;
+// only frames beyond hardReadLimitFactor times the limit drop the connection.
+func (c *Connection) readFrame() (frameType int, data []byte, size int, err error) {
+	limit := c.maxInbound()
+	c.Conn.SetReadLimit(int64(limit) * hardReadLimitFactor)
+
+	frameType, reader, err := c.Conn.NextReader()
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	data, err = io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	if len(data) <= limit {
+		return frameType, data, len(data), nil
+	}
+	rest, err := io.Copy(io.Discard, reader)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	return frameType, nil, len(data) + int(rest), nil
+}
+
+// tooLargeReply tells the client an inbound message was refused for its size
+func tooLargeReply(size, limit int) *Message {
+	return &Message{
+		Type:    "error",
+		Success: false,
+		Error:   "Message too large",
+		Data: map[string]interface{}{
+			"code":      MessageTooLargeCode,
+			"size":      size,
+			"max_bytes": limit,
+		},
+	}
+}
+
+// chunkFrame splits an encoded frame larger than the connection's chunk size
+// into message_chunk frames, returning nil when it fits as is. Clients join
+// the payloads of chunks sharing a chunk_id in index order and decode the
+// result as one message; with binary codecs each payload is base64 on its own.
+func (c *Connection) chunkFrame(data []byte) ([][]byte, error) {
+	if c.chunkBytes <= 0 || len(data) <= c.chunkBytes {
+		return nil, nil
+	}
+
+	codec := c.Codec()
+	parts := make([]string, 0, len(data)/c.chunkBytes+1)
+	for len(data) > 0 {
+		cut := min(c.chunkBytes, len(data))
+		if codec.Binary() {
+			parts = append(parts, base64.StdEncoding.EncodeToString(data[:cut]))
+		} else {
+			// Text payloads are cut between characters
+			for cut < len(data) && cut > 0 && !utf8.RuneStart(data[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				_, cut = utf8.DecodeRune(data)
+			}
+			parts = append(parts, string(data[:cut]))
+		}
+		data = data[cut:]
+	}
+
+	id := strconv.FormatUint(c.chunkSeq.Add(1), 10)
+	frames := make([][]byte, 0, len(parts))
+	for index, payload := range parts {
+		frame, err := codec.Marshal(&Message{
+			Type: "message_chunk",
+			Data: map[string]interface{}{
+				"chunk_id": id,
+				"index":    index,
+				"count":    len(parts),
+				"payload":  payload,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
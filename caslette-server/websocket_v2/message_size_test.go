@@ -0,0 +1,100 @@
+package websocket_v2
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// joinChunks reads message_chunk frames off the connection and returns the
+// frame they carried
+func joinChunks(t *testing.T, conn *Connection) []byte {
+	t.Helper()
+	var joined strings.Builder
+	for index := 0; ; index++ {
+		var chunk Message
+		require.NoError(t, conn.Codec().Unmarshal(<-conn.Send, &chunk))
+		require.Equal(t, "message_chunk", chunk.Type)
+		data := chunk.Data.(map[string]interface{})
+		assert.Equal(t, float64(index), data["index"])
+		payload := data["payload"].(string)
+		if conn.Codec().Binary() {
+			decoded, err := base64.StdEncoding.DecodeString(payload)
+			require.NoError(t, err)
+			payload = string(decoded)
+		}
+		joined.WriteString(payload)
+		if float64(index+1) == data["count"] {
+			break
+		}
+	}
+	return []byte(joined.String())
+}
+
+func TestLargeFramesAreSentInChunks(t *testing.T) {
+	history := strings.Repeat("♠A ♥K raise 200; ", 40)
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec, ProtobufCodec} {
+		conn := &Connection{ID: "c1", Send: make(chan []byte, 64), Rooms: make(map[string]bool), codec: codec, chunkBytes: 100}
+
+		conn.SendMessage(&Message{Type: "hand_history", Data: history})
+		var msg Message
+		require.NoError(t, codec.Unmarshal(joinChunks(t, conn), &msg), codec.Name())
+		assert.Equal(t, "hand_history", msg.Type, codec.Name())
+		assert.Equal(t, history, msg.Data, codec.Name())
+
+		conn.SendMessage(&Message{Type: "pong"})
+		require.NoError(t, codec.Unmarshal(<-conn.Send, &msg))
+		assert.Equal(t, "pong", msg.Type, "small frames go out whole")
+	}
+}
+
+func TestOversizedMessageGetsAnErrorAndKeepsTheConnection(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := newConnection(hub, w, r, CompressionPolicy{})
+		require.NoError(t, err)
+		conn.maxInboundBytes = 256
+		hub.Register(conn)
+		conn.Start()
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer client.Close()
+	read := func() Message {
+		client.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg Message
+		require.NoError(t, client.ReadJSON(&msg))
+		return msg
+	}
+	assert.Equal(t, "connected", read().Type)
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"type":"test_echo","data":"`+strings.Repeat("x", 1000)+`"}`)))
+	reply := read()
+	assert.Equal(t, "error", reply.Type)
+	assert.False(t, reply.Success)
+	data := reply.Data.(map[string]interface{})
+	assert.Equal(t, MessageTooLargeCode, data["code"])
+	assert.Equal(t, float64(256), data["max_bytes"])
+	assert.Greater(t, data["size"], float64(1000))
+
+	require.NoError(t, client.WriteJSON(&Message{Type: "test_echo", RequestID: "r1"}))
+	assert.Equal(t, "test_echo_response", read().Type, "the connection survives the oversized message")
+}
+
+func TestMessageSizePolicyValidation(t *testing.T) {
+	assert.NoError(t, DefaultMessageSizePolicy().Validate())
+	assert.NoError(t, MessageSizePolicy{MaxInboundBytes: 1}.Validate(), "chunking can be off")
+	assert.Error(t, MessageSizePolicy{}.Validate())
+	assert.Error(t, MessageSizePolicy{MaxInboundBytes: 1, ChunkBytes: -1}.Validate())
+	assert.Error(t, NewServer(nil).SetMessageSizePolicy(MessageSizePolicy{}))
+}
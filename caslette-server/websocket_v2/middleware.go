@@ -0,0 +1,98 @@
+package websocket_v2
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+// Middleware wraps a message handler with behaviour shared across handlers,
+// such as logging or recovery; it may answer without calling next
+type Middleware func(next MessageHandler) MessageHandler
+
+// Chain wraps a handler in middleware; the first middleware runs outermost
+func Chain(handler MessageHandler, middleware ...Middleware) MessageHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// check turns a function returning a refusal, or nil to go on, into middleware
+func check(refuse func(conn *Connection, msg *Message) *Message) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, conn *Connection, msg *Message) *Message {
+			if reply := refuse(conn, msg); reply != nil {
+				return reply
+			}
+			return next(ctx, conn, msg)
+		}
+	}
+}
+
+// Recover answers a handler that panics with a HANDLER_ERROR reply and logs
+// the stack. The hub recovers handler panics anyway and counts them against
+// the handler's circuit breaker; a panic recovered here is not counted, so
+// use it for handlers whose failures should not open their circuit.
+func Recover() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, conn *Connection, msg *Message) (reply *Message) {
+			defer func() {
+				if r := recover(); r != nil {
+					conn.Logf("WebSocket: handler %s panicked: %v\n%s", msg.Type, r, debug.Stack())
+					reply = handlerFailureReply(msg, "HANDLER_ERROR", "Internal error processing request", nil)
+				}
+			}()
+			return next(ctx, conn, msg)
+		}
+	}
+}
+
+// LogRequests logs each request with its sender, outcome and duration
+func LogRequests() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, conn *Connection, msg *Message) *Message {
+			started := time.Now()
+			reply := next(ctx, conn, msg)
+			switch {
+			case reply == nil:
+				conn.Logf("WebSocket: %s from connection %s (user %q) handled without reply in %v", msg.Type, conn.ID, conn.UserID, time.Since(started))
+			case reply.Success:
+				conn.Logf("WebSocket: %s from connection %s (user %q) succeeded in %v", msg.Type, conn.ID, conn.UserID, time.Since(started))
+			default:
+				conn.Logf("WebSocket: %s from connection %s (user %q) failed in %v: %s", msg.Type, conn.ID, conn.UserID, time.Since(started), reply.Error)
+			}
+			return reply
+		}
+	}
+}
+
+// HandlerOption declares a check or middleware for a handler installed with
+// RegisterHandler; the registry enforces it before the handler runs
+type HandlerOption interface {
+	applyTo(spec *HandlerSpec)
+}
+
+// handlerOption is a HandlerOption changing the spec directly
+type handlerOption func(spec *HandlerSpec)
+
+func (o handlerOption) applyTo(spec *HandlerSpec) { o(spec) }
+
+// applyTo lets middleware be passed as a handler option
+func (m Middleware) applyTo(spec *HandlerSpec) {
+	spec.Middleware = append(spec.Middleware, m)
+}
+
+// RequireAuth refuses the handler to connections that have not authenticated
+func RequireAuth() HandlerOption {
+	return handlerOption(func(spec *HandlerSpec) { spec.RequireAuth = true })
+}
+
+// RequirePermission refuses the handler to users lacking any of the
+// permissions, as resolved by the server's permission checker; it implies
+// RequireAuth
+func RequirePermission(permissions ...string) HandlerOption {
+	return handlerOption(func(spec *HandlerSpec) {
+		spec.Permissions = append(spec.Permissions, permissions...)
+	})
+}
@@ -0,0 +1,112 @@
+package websocket_v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tracing records the order middleware runs in
+func tracing(name string, calls *[]string) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, conn *Connection, msg *Message) *Message {
+			*calls = append(*calls, name)
+			return next(ctx, conn, msg)
+		}
+	}
+}
+
+func TestMiddlewareRunsAroundTheDeclaredChecks(t *testing.T) {
+	var calls []string
+	registry := NewHandlerRegistry()
+	registry.Use(tracing("global", &calls))
+	require.NoError(t, registry.Register(HandlerSpec{
+		Name:        "secret",
+		RequireAuth: true,
+		Middleware:  []Middleware{tracing("first", &calls), tracing("second", &calls)},
+		Handler: func(ctx context.Context, conn *Connection, msg *Message) *Message {
+			calls = append(calls, "handler")
+			return okHandler(ctx, conn, msg)
+		},
+	}))
+	handler := registry.Wrap("secret")
+
+	resp := handler(context.Background(), &Connection{ID: "c1"}, &Message{Type: "secret"})
+	assert.Equal(t, "Authentication required", resp.Error)
+	assert.Equal(t, []string{"global"}, calls, "handler middleware runs only once the checks pass")
+
+	calls = nil
+	resp = handler(context.Background(), &Connection{ID: "c1", UserID: "7"}, &Message{Type: "secret"})
+	assert.True(t, resp.Success)
+	assert.Equal(t, []string{"global", "first", "second", "handler"}, calls)
+}
+
+func TestRegisterHandlerMiddlewareCanAnswerInstead(t *testing.T) {
+	server := NewServer(nil)
+	defer server.hub.(*ActorHub).Stop()
+
+	closed := Middleware(func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, conn *Connection, msg *Message) *Message {
+			return errorReply(msg, "maintenance_response", "Down for maintenance")
+		}
+	})
+	server.RegisterHandler("maintenance", okHandler, closed)
+
+	resp := server.registry.Wrap("maintenance")(context.Background(), &Connection{ID: "c1"}, &Message{Type: "maintenance"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "Down for maintenance", resp.Error)
+
+	// Replacing the handler without middleware keeps the declared middleware
+	server.RegisterHandler("maintenance", okHandler)
+	resp = server.registry.Wrap("maintenance")(context.Background(), &Connection{ID: "c1"}, &Message{Type: "maintenance"})
+	assert.False(t, resp.Success)
+}
+
+func TestRecoverAnswersAPanickingHandler(t *testing.T) {
+	handler := Chain(func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		panic("boom")
+	}, LogRequests(), Recover())
+
+	resp := handler(context.Background(), &Connection{ID: "c1"}, &Message{Type: "explode", RequestID: "r1"})
+	require.NotNil(t, resp)
+	assert.False(t, resp.Success)
+	assert.Equal(t, "explode_response", resp.Type)
+	assert.Equal(t, "r1", resp.RequestID)
+	assert.Equal(t, "HANDLER_ERROR", resp.Data.(map[string]interface{})["code"])
+}
+
+func TestRegisterHandlerOptionsAreEnforcedByTheRegistry(t *testing.T) {
+	server := NewServer(nil)
+	defer server.hub.(*ActorHub).Stop()
+	server.SetPermissionChecker(func(userID, permission string) (bool, error) {
+		return userID == "7" && permission == "poker.play", nil
+	})
+
+	server.RegisterHandler("whoami", okHandler, RequireAuth())
+	server.RegisterHandler("poker_move", okHandler, RequirePermission("poker.play"))
+	whoami := server.registry.Wrap("whoami")
+	move := server.registry.Wrap("poker_move")
+
+	resp := whoami(context.Background(), &Connection{ID: "c1"}, &Message{Type: "whoami"})
+	assert.Equal(t, "whoami_response", resp.Type)
+	assert.Equal(t, "Authentication required", resp.Error)
+	assert.True(t, whoami(context.Background(), &Connection{ID: "c1", UserID: "8"}, &Message{Type: "whoami"}).Success)
+
+	assert.Equal(t, "Authentication required", move(context.Background(), &Connection{ID: "c1"}, &Message{Type: "poker_move"}).Error)
+	assert.Equal(t, "Insufficient permissions", move(context.Background(), &Connection{ID: "c1", UserID: "8"}, &Message{Type: "poker_move"}).Error)
+	assert.True(t, move(context.Background(), &Connection{ID: "c1", UserID: "7"}, &Message{Type: "poker_move"}).Success)
+
+	spec, ok := server.registry.Get("poker_move")
+	require.True(t, ok)
+	assert.True(t, spec.RequireAuth, "listed in the handler specs")
+	assert.Equal(t, []string{"poker.play"}, spec.Permissions)
+
+	// Replacing a handler never drops the checks it was declared with
+	server.RegisterHandler("poker_move", okHandler, RequirePermission("poker.play"), RequireAuth())
+	server.RegisterHandler("poker_move", okHandler)
+	spec, _ = server.registry.Get("poker_move")
+	assert.True(t, spec.RequireAuth)
+	assert.Equal(t, []string{"poker.play"}, spec.Permissions)
+}
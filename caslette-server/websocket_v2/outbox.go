@@ -0,0 +1,245 @@
+package websocket_v2
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Outbox limits
+const (
+	DefaultOutboxSize     = 256              // Messages retained per user
+	DefaultOutboxIdleTTL  = 2 * time.Minute  // Idle outboxes are dropped after this long
+	MaxLongPollTimeout    = 30 * time.Second // Longest a poll may wait for messages
+	MaxPollSubscriptions  = 10               // Rooms a long-poll client may follow
+	outboxPruneEveryWrite = 1024
+)
+
+// OutboxEntry is a message addressed to a user, numbered in delivery order
+type OutboxEntry struct {
+	Seq     uint64   `json:"seq"`
+	Message *Message `json:"message"`
+}
+
+// PollResult is returned by a long poll
+type PollResult struct {
+	Messages []OutboxEntry `json:"messages"`
+	Cursor   uint64        `json:"cursor"`           // Pass back to receive only newer messages
+	Missed   bool          `json:"missed,omitempty"` // Older messages were dropped; resync state
+	TimedOut bool          `json:"timed_out,omitempty"`
+}
+
+// userOutbox holds the recent messages for one user
+type userOutbox struct {
+	entries    []OutboxEntry
+	lastSeq    uint64
+	lastActive time.Time
+	rooms      map[string]bool // Rooms followed by long-poll clients
+	notify     chan struct{}   // Closed and replaced whenever a message arrives
+}
+
+// OutboxStore keeps a bounded, sequenced outbox per user. The hub appends
+// every message it delivers to a user, so clients that cannot hold a
+// websocket can drain the same stream over HTTP long polling.
+type OutboxStore struct {
+	mu      sync.Mutex
+	boxes   map[string]*userOutbox
+	size    int
+	idleTTL time.Duration
+	writes  int
+	now     func() time.Time
+}
+
+// NewOutboxStore creates an outbox store retaining size messages per user
+func NewOutboxStore(size int) *OutboxStore {
+	if size <= 0 {
+		size = DefaultOutboxSize
+	}
+	return &OutboxStore{
+		boxes:   make(map[string]*userOutbox),
+		size:    size,
+		idleTTL: DefaultOutboxIdleTTL,
+		now:     time.Now,
+	}
+}
+
+// box returns the outbox for a user, creating it if needed. Callers hold s.mu.
+func (s *OutboxStore) box(userID string) *userOutbox {
+	box, exists := s.boxes[userID]
+	if !exists {
+		box = &userOutbox{
+			rooms:      make(map[string]bool),
+			notify:     make(chan struct{}),
+			lastActive: s.now(),
+		}
+		s.boxes[userID] = box
+	}
+	return box
+}
+
+// Append records a message for a user and wakes any waiting poll
+func (s *OutboxStore) Append(userID string, msg *Message) uint64 {
+	if userID == "" || msg == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	box := s.box(userID)
+	box.lastSeq++
+	box.entries = append(box.entries, OutboxEntry{Seq: box.lastSeq, Message: msg})
+	if len(box.entries) > s.size {
+		box.entries = append([]OutboxEntry(nil), box.entries[len(box.entries)-s.size:]...)
+	}
+	box.lastActive = s.now()
+	close(box.notify)
+	box.notify = make(chan struct{})
+
+	s.writes++
+	if s.writes%outboxPruneEveryWrite == 0 {
+		s.pruneLocked()
+	}
+	return box.lastSeq
+}
+
+// Poll returns messages newer than cursor, waiting up to timeout for the
+// first one to arrive. A zero cursor starts from the oldest retained message.
+func (s *OutboxStore) Poll(ctx context.Context, userID string, cursor uint64, timeout time.Duration) *PollResult {
+	if timeout > MaxLongPollTimeout {
+		timeout = MaxLongPollTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		box := s.box(userID)
+		box.lastActive = s.now()
+		result := box.since(cursor)
+		notify := box.notify
+		s.mu.Unlock()
+
+		if len(result.Messages) > 0 || timeout <= 0 {
+			return result
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			result.TimedOut = true
+			return result
+		case <-ctx.Done():
+			result.TimedOut = true
+			return result
+		}
+	}
+}
+
+// since collects entries after cursor. Callers hold the store lock.
+func (b *userOutbox) since(cursor uint64) *PollResult {
+	result := &PollResult{Messages: []OutboxEntry{}, Cursor: cursor}
+	if cursor > b.lastSeq {
+		// The outbox was recreated since the client last polled
+		result.Missed = true
+		cursor = 0
+	}
+	if len(b.entries) > 0 && cursor > 0 && b.entries[0].Seq > cursor+1 {
+		result.Missed = true
+	}
+	for _, entry := range b.entries {
+		if entry.Seq > cursor {
+			result.Messages = append(result.Messages, entry)
+		}
+	}
+	if b.lastSeq > result.Cursor || result.Missed {
+		result.Cursor = b.lastSeq
+	}
+	return result
+}
+
+// Subscribe makes a long-poll client follow a room's broadcasts
+func (s *OutboxStore) Subscribe(userID, room string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	box := s.box(userID)
+	if !box.rooms[room] && len(box.rooms) >= MaxPollSubscriptions {
+		return fmt.Errorf("cannot follow more than %d rooms", MaxPollSubscriptions)
+	}
+	box.rooms[room] = true
+	box.lastActive = s.now()
+	return nil
+}
+
+// Unsubscribe stops a long-poll client following a room
+func (s *OutboxStore) Unsubscribe(userID, room string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if box, exists := s.boxes[userID]; exists {
+		delete(box.rooms, room)
+	}
+}
+
+// Subscriptions returns the rooms a user follows by long polling
+func (s *OutboxStore) Subscriptions(userID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rooms := make([]string, 0)
+	if box, exists := s.boxes[userID]; exists {
+		for room := range box.rooms {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms
+}
+
+// RoomSubscribers returns the users following a room by long polling
+func (s *OutboxStore) RoomSubscribers(room string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := make([]string, 0)
+	for userID, box := range s.boxes {
+		if box.rooms[room] {
+			users = append(users, userID)
+		}
+	}
+	return users
+}
+
+// Has reports whether the user has a live outbox
+func (s *OutboxStore) Has(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.boxes[userID]
+	return exists
+}
+
+// ActiveUsers returns every user with a live outbox
+func (s *OutboxStore) ActiveUsers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := make([]string, 0, len(s.boxes))
+	for userID := range s.boxes {
+		users = append(users, userID)
+	}
+	return users
+}
+
+// Prune drops outboxes that have been idle longer than the idle TTL
+func (s *OutboxStore) Prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+}
+
+// pruneLocked drops idle outboxes. Callers hold s.mu.
+func (s *OutboxStore) pruneLocked() {
+	cutoff := s.now().Add(-s.idleTTL)
+	for userID, box := range s.boxes {
+		if box.lastActive.Before(cutoff) {
+			delete(s.boxes, userID)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package websocket_v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxPollReturnsMessagesAfterCursor(t *testing.T) {
+	store := NewOutboxStore(10)
+	store.Append("7", &Message{Type: "a"})
+	store.Append("7", &Message{Type: "b"})
+
+	result := store.Poll(context.Background(), "7", 0, 0)
+	require.Len(t, result.Messages, 2)
+	assert.Equal(t, uint64(2), result.Cursor)
+	assert.Equal(t, "a", result.Messages[0].Message.Type)
+
+	result = store.Poll(context.Background(), "7", 1, 0)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "b", result.Messages[0].Message.Type)
+
+	result = store.Poll(context.Background(), "7", 2, 0)
+	assert.Empty(t, result.Messages)
+	assert.Equal(t, uint64(2), result.Cursor)
+}
+
+func TestOutboxPollWaitsForNextMessage(t *testing.T) {
+	store := NewOutboxStore(10)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		store.Append("7", &Message{Type: "your_turn"})
+	}()
+
+	start := time.Now()
+	result := store.Poll(context.Background(), "7", 0, time.Second)
+	assert.Less(t, time.Since(start), time.Second)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "your_turn", result.Messages[0].Message.Type)
+	assert.False(t, result.TimedOut)
+}
+
+func TestOutboxPollTimesOut(t *testing.T) {
+	store := NewOutboxStore(10)
+	result := store.Poll(context.Background(), "7", 0, 10*time.Millisecond)
+	assert.True(t, result.TimedOut)
+	assert.Empty(t, result.Messages)
+}
+
+func TestOutboxReportsMissedMessages(t *testing.T) {
+	store := NewOutboxStore(2)
+	for i := 0; i < 5; i++ {
+		store.Append("7", &Message{Type: "update"})
+	}
+
+	result := store.Poll(context.Background(), "7", 1, 0)
+	assert.True(t, result.Missed, "messages 2 and 3 were dropped")
+	assert.Len(t, result.Messages, 2)
+	assert.Equal(t, uint64(5), result.Cursor)
+
+	result = store.Poll(context.Background(), "7", 3, 0)
+	assert.False(t, result.Missed)
+
+	// A cursor ahead of the outbox means it was recreated
+	result = store.Poll(context.Background(), "7", 99, 0)
+	assert.True(t, result.Missed)
+	assert.Equal(t, uint64(5), result.Cursor)
+}
+
+func TestOutboxSubscriptionsAndPrune(t *testing.T) {
+	store := NewOutboxStore(10)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Subscribe("7", "table_abc"))
+	assert.Equal(t, []string{"7"}, store.RoomSubscribers("table_abc"))
+	assert.Equal(t, []string{"table_abc"}, store.Subscriptions("7"))
+
+	for i := 1; i < MaxPollSubscriptions; i++ {
+		require.NoError(t, store.Subscribe("7", "room_"+string(rune('a'+i))))
+	}
+	assert.Error(t, store.Subscribe("7", "one_too_many"))
+
+	store.Unsubscribe("7", "table_abc")
+	assert.Empty(t, store.RoomSubscribers("table_abc"))
+
+	now = now.Add(DefaultOutboxIdleTTL + time.Second)
+	store.Prune()
+	assert.Empty(t, store.ActiveUsers())
+}
+
+func TestActorHubRecordsRoomBroadcastsForPollers(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+
+	require.NoError(t, hub.Outbox().Subscribe("7", "table_abc"))
+	hub.BroadcastToRoom("table_abc", &Message{Type: "player_joined", Room: "table_abc"})
+	hub.BroadcastToRoom("table_other", &Message{Type: "player_joined", Room: "table_other"})
+	hub.BroadcastToUser("7", &Message{Type: "your_turn"})
+
+	result := hub.Outbox().Poll(context.Background(), "7", 0, 0)
+	require.Len(t, result.Messages, 2)
+	assert.Equal(t, "player_joined", result.Messages[0].Message.Type)
+	assert.Equal(t, "your_turn", result.Messages[1].Message.Type)
+}
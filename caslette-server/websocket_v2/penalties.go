@@ -0,0 +1,231 @@
+package websocket_v2
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// PenaltyLevel is how severely a rate-limit violator is being held back
+type PenaltyLevel string
+
+const (
+	PenaltyNone    PenaltyLevel = ""
+	PenaltyWarning PenaltyLevel = "warning" // Told to slow down
+	PenaltyMute    PenaltyLevel = "mute"    // Chat handlers refused
+	PenaltyBan     PenaltyLevel = "ban"     // Connection dropped and messages refused
+	PenaltyFlag    PenaltyLevel = "flag"    // Banned and the account flagged for review
+)
+
+// PenaltyStep applies a level once a subject's violations reach a threshold
+type PenaltyStep struct {
+	Violations int           `json:"violations"`
+	Level      PenaltyLevel  `json:"level"`
+	Duration   time.Duration `json:"duration"` // How long a mute or ban lasts
+}
+
+// DefaultPenaltySteps escalates from a warning to flagging the account
+var DefaultPenaltySteps = []PenaltyStep{
+	{Violations: 1, Level: PenaltyWarning},
+	{Violations: 3, Level: PenaltyMute, Duration: 2 * time.Minute},
+	{Violations: 5, Level: PenaltyBan, Duration: 5 * time.Minute},
+	{Violations: 8, Level: PenaltyFlag, Duration: 30 * time.Minute},
+}
+
+// Penalty decay and bookkeeping limits
+const (
+	DefaultPenaltyDecay = 30 * time.Minute // Violations are forgotten after this long without a new one
+	maxPenalties        = 10000
+	banDisconnectDelay  = 100 * time.Millisecond // Lets the penalty notice flush before the socket closes
+)
+
+// Penalty is the current standing of one violator
+type Penalty struct {
+	Subject       string       `json:"subject"` // "user:<id>" or "conn:<id>" for unauthenticated connections
+	UserID        string       `json:"user_id,omitempty"`
+	Level         PenaltyLevel `json:"level"`
+	Violations    int          `json:"violations"`
+	FirstSeen     time.Time    `json:"first_seen"`
+	LastViolation time.Time    `json:"last_violation"`
+	MutedUntil    time.Time    `json:"muted_until,omitempty"`
+	BannedUntil   time.Time    `json:"banned_until,omitempty"`
+	Flagged       bool         `json:"flagged"`
+}
+
+// AccountFlagger is told when a user reaches the flag level, e.g. to tag the
+// account for moderator review. It runs off the hub actor.
+type AccountFlagger func(userID string, penalty Penalty)
+
+// PenaltyTracker escalates penalties for repeated rate-limit violations,
+// replacing a flat block with warnings, chat mutes, temporary bans and
+// finally an account flag
+type PenaltyTracker struct {
+	mu        sync.Mutex
+	steps     []PenaltyStep
+	decay     time.Duration
+	penalties map[string]*Penalty
+	flagger   AccountFlagger
+	now       func() time.Time
+}
+
+// NewPenaltyTracker creates a tracker with the default escalation steps
+func NewPenaltyTracker() *PenaltyTracker {
+	return &PenaltyTracker{
+		steps:     DefaultPenaltySteps,
+		decay:     DefaultPenaltyDecay,
+		penalties: make(map[string]*Penalty),
+		now:       time.Now,
+	}
+}
+
+// penaltySubject keys penalties by account so reconnecting does not reset
+// them, falling back to the connection before authentication
+func penaltySubject(conn *Connection) string {
+	if conn.UserID != "" {
+		return "user:" + conn.UserID
+	}
+	return "conn:" + conn.ID
+}
+
+// SetAccountFlagger sets the hook called when a user reaches the flag level
+func (t *PenaltyTracker) SetAccountFlagger(flagger AccountFlagger) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.flagger = flagger
+}
+
+// Steps returns the escalation steps in order
+func (t *PenaltyTracker) Steps() []PenaltyStep {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]PenaltyStep(nil), t.steps...)
+}
+
+// RecordViolation counts a violation and applies the step it reaches. It
+// returns the updated penalty and whether the level escalated.
+func (t *PenaltyTracker) RecordViolation(subject, userID string) (Penalty, bool) {
+	t.mu.Lock()
+
+	now := t.now()
+	penalty := t.penalties[subject]
+	if penalty != nil && t.expired(penalty, now) {
+		delete(t.penalties, subject)
+		penalty = nil
+	}
+	if penalty == nil {
+		if len(t.penalties) >= maxPenalties {
+			t.prune(now)
+		}
+		penalty = &Penalty{Subject: subject, FirstSeen: now}
+		t.penalties[subject] = penalty
+	}
+	if userID != "" {
+		penalty.UserID = userID
+	}
+	penalty.Violations++
+	penalty.LastViolation = now
+
+	escalated := false
+	for _, step := range t.steps {
+		if penalty.Violations != step.Violations {
+			continue
+		}
+		escalated = true
+		penalty.Level = step.Level
+		switch step.Level {
+		case PenaltyMute:
+			penalty.MutedUntil = now.Add(step.Duration)
+		case PenaltyBan, PenaltyFlag:
+			penalty.BannedUntil = now.Add(step.Duration)
+			penalty.MutedUntil = laterOf(penalty.MutedUntil, penalty.BannedUntil)
+		}
+	}
+
+	var flag AccountFlagger
+	if escalated && penalty.Level == PenaltyFlag && !penalty.Flagged && penalty.UserID != "" {
+		penalty.Flagged = true
+		flag = t.flagger
+	}
+	snapshot := *penalty
+	t.mu.Unlock()
+
+	if flag != nil {
+		go flag(snapshot.UserID, snapshot)
+	}
+	return snapshot, escalated
+}
+
+// MutedUntil returns when a subject's chat mute ends, if one is in force
+func (t *PenaltyTracker) MutedUntil(subject string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	penalty := t.penalties[subject]
+	if penalty == nil || !t.now().Before(penalty.MutedUntil) {
+		return time.Time{}, false
+	}
+	return penalty.MutedUntil, true
+}
+
+// BannedUntil returns when a subject's ban ends, if one is in force
+func (t *PenaltyTracker) BannedUntil(subject string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	penalty := t.penalties[subject]
+	if penalty == nil || !t.now().Before(penalty.BannedUntil) {
+		return time.Time{}, false
+	}
+	return penalty.BannedUntil, true
+}
+
+// List returns current penalties, most severe and most recent first
+func (t *PenaltyTracker) List() []Penalty {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.prune(now)
+	list := make([]Penalty, 0, len(t.penalties))
+	for _, penalty := range t.penalties {
+		list = append(list, *penalty)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Violations != list[j].Violations {
+			return list[i].Violations > list[j].Violations
+		}
+		return list[i].LastViolation.After(list[j].LastViolation)
+	})
+	return list
+}
+
+// Clear lifts a subject's penalty; it reports whether one existed
+func (t *PenaltyTracker) Clear(subject string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, exists := t.penalties[subject]
+	delete(t.penalties, subject)
+	return exists
+}
+
+// expired reports whether a penalty has decayed: no violation within the
+// decay window and no mute or ban still in force
+func (t *PenaltyTracker) expired(penalty *Penalty, now time.Time) bool {
+	return now.Sub(penalty.LastViolation) >= t.decay &&
+		!now.Before(penalty.MutedUntil) && !now.Before(penalty.BannedUntil)
+}
+
+// prune drops decayed penalties; callers hold the lock
+func (t *PenaltyTracker) prune(now time.Time) {
+	for subject, penalty := range t.penalties {
+		if t.expired(penalty, now) {
+			delete(t.penalties, subject)
+		}
+	}
+}
+
+// laterOf returns the later of two times
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
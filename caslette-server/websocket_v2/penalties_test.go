@@ -0,0 +1,165 @@
+package websocket_v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPenaltyTracker(now *time.Time) *PenaltyTracker {
+	tracker := NewPenaltyTracker()
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestPenaltyTrackerEscalates(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestPenaltyTracker(&now)
+
+	penalty, escalated := tracker.RecordViolation("user:7", "7")
+	assert.True(t, escalated)
+	assert.Equal(t, PenaltyWarning, penalty.Level)
+
+	_, escalated = tracker.RecordViolation("user:7", "7")
+	assert.False(t, escalated, "no step at two violations")
+
+	penalty, escalated = tracker.RecordViolation("user:7", "7")
+	assert.True(t, escalated)
+	assert.Equal(t, PenaltyMute, penalty.Level)
+	until, muted := tracker.MutedUntil("user:7")
+	assert.True(t, muted)
+	assert.Equal(t, now.Add(2*time.Minute), until)
+	_, banned := tracker.BannedUntil("user:7")
+	assert.False(t, banned)
+
+	tracker.RecordViolation("user:7", "7")
+	penalty, _ = tracker.RecordViolation("user:7", "7")
+	assert.Equal(t, PenaltyBan, penalty.Level)
+	until, banned = tracker.BannedUntil("user:7")
+	assert.True(t, banned)
+	assert.Equal(t, now.Add(5*time.Minute), until)
+	_, muted = tracker.MutedUntil("user:7")
+	assert.True(t, muted, "a ban also mutes chat")
+
+	now = now.Add(6 * time.Minute)
+	_, banned = tracker.BannedUntil("user:7")
+	assert.False(t, banned, "bans lapse")
+}
+
+func TestPenaltyTrackerFlagsAccountOnce(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestPenaltyTracker(&now)
+
+	flagged := make(chan string, 2)
+	tracker.SetAccountFlagger(func(userID string, penalty Penalty) {
+		flagged <- userID
+	})
+
+	var penalty Penalty
+	for i := 0; i < 10; i++ {
+		penalty, _ = tracker.RecordViolation("user:9", "9")
+	}
+	assert.Equal(t, PenaltyFlag, penalty.Level)
+	assert.True(t, penalty.Flagged)
+
+	select {
+	case userID := <-flagged:
+		assert.Equal(t, "9", userID)
+	case <-time.After(time.Second):
+		t.Fatal("flagger not called")
+	}
+	select {
+	case <-flagged:
+		t.Fatal("flagger called twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPenaltyTrackerSkipsFlagForAnonymousConnections(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestPenaltyTracker(&now)
+	tracker.SetAccountFlagger(func(userID string, penalty Penalty) {
+		t.Error("anonymous connections have no account to flag")
+	})
+
+	var penalty Penalty
+	for i := 0; i < 8; i++ {
+		penalty, _ = tracker.RecordViolation("conn:abc", "")
+	}
+	assert.Equal(t, PenaltyFlag, penalty.Level)
+	assert.False(t, penalty.Flagged)
+}
+
+func TestPenaltyTrackerDecay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestPenaltyTracker(&now)
+
+	tracker.RecordViolation("user:7", "7")
+	tracker.RecordViolation("user:7", "7")
+	assert.Len(t, tracker.List(), 1)
+
+	now = now.Add(DefaultPenaltyDecay)
+	assert.Empty(t, tracker.List(), "quiet subjects are forgotten")
+
+	penalty, escalated := tracker.RecordViolation("user:7", "7")
+	assert.True(t, escalated)
+	assert.Equal(t, 1, penalty.Violations, "escalation restarts after decay")
+}
+
+func TestPenaltyTrackerListAndClear(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestPenaltyTracker(&now)
+
+	tracker.RecordViolation("user:1", "1")
+	for i := 0; i < 3; i++ {
+		tracker.RecordViolation("user:2", "2")
+	}
+
+	list := tracker.List()
+	assert.Len(t, list, 2)
+	assert.Equal(t, "user:2", list[0].Subject, "most violations first")
+
+	assert.True(t, tracker.Clear("user:2"))
+	assert.False(t, tracker.Clear("user:2"))
+	_, muted := tracker.MutedUntil("user:2")
+	assert.False(t, muted)
+}
+
+func TestHandlerRegistryRefusesChatWhileMuted(t *testing.T) {
+	registry := NewHandlerRegistry()
+	tracker := NewPenaltyTracker()
+	registry.SetPenaltyTracker(tracker)
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "say", RequireAuth: true, Chat: true, Handler: okHandler}))
+	assert.NoError(t, registry.Register(HandlerSpec{Name: "act", RequireAuth: true, Handler: okHandler}))
+
+	conn := &Connection{ID: "c1", UserID: "7"}
+	for i := 0; i < 3; i++ {
+		tracker.RecordViolation(penaltySubject(conn), conn.UserID)
+	}
+
+	resp := registry.Wrap("say")(context.Background(), conn, &Message{Type: "say"})
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "chat muted")
+
+	resp = registry.Wrap("act")(context.Background(), conn, &Message{Type: "act"})
+	assert.True(t, resp.Success, "mutes only apply to chat handlers")
+}
+
+func TestActorHubCountsOneViolationPerBurst(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	conn := registerTestConnection(t, hub, "")
+
+	for i := 0; i < MaxMessagesPerSecond+5; i++ {
+		hub.ProcessMessage(conn, &Message{Type: "test_echo"})
+	}
+
+	penalties := hub.Penalties().List()
+	if assert.Len(t, penalties, 1) {
+		assert.Equal(t, "conn:"+conn.ID, penalties[0].Subject)
+		assert.Equal(t, 1, penalties[0].Violations)
+		assert.Equal(t, PenaltyWarning, penalties[0].Level)
+	}
+}
@@ -0,0 +1,64 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type presenceLog struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (l *presenceLog) record(userID string, online bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, fmt.Sprintf("%s:%v", userID, online))
+}
+
+func (l *presenceLog) waitFor(t *testing.T, count int) []string {
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.changes) >= count
+	}, time.Second, 5*time.Millisecond)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.changes...)
+}
+
+func authenticate(hub *ActorHub, connID string) *Connection {
+	conn := &Connection{ID: connID, Send: make(chan []byte, 16), Rooms: make(map[string]bool)}
+	hub.Register(conn)
+	hub.ProcessMessage(conn, &Message{Type: "auth", Data: map[string]interface{}{"token": "token"}})
+	return conn
+}
+
+func TestActorHubReportsPresenceOfLastConnection(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	hub.SetAuthHandler(func(token string) (*AuthResult, error) {
+		return &AuthResult{Success: true, UserID: "7", Username: "player7"}, nil
+	})
+	presence := &presenceLog{}
+	hub.SetPresenceHandler(presence.record)
+
+	first := authenticate(hub, "c1")
+	second := authenticate(hub, "c2")
+	assert.Equal(t, []string{"7:true"}, presence.waitFor(t, 1), "a second connection is not a new arrival")
+
+	hub.Unregister(second)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, presence.waitFor(t, 1), 1, "the user is still online through the first connection")
+
+	hub.Unregister(first)
+	assert.Equal(t, []string{"7:true", "7:false"}, presence.waitFor(t, 2))
+
+	authenticate(hub, "c3")
+	assert.Equal(t, "7:true", presence.waitFor(t, 3)[2], "reconnecting brings the user back online")
+}
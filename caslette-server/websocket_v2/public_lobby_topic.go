@@ -0,0 +1,161 @@
+package websocket_v2
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Public lobby topic settings
+const (
+	PublicLobbyTopic           = "public_lobby"
+	PublicLobbyUpdateType      = "public_lobby_update"
+	DefaultPublicLobbyInterval = 10 * time.Second
+)
+
+// PublicLobbyPublisher periodically pushes a read-only view of the lobby,
+// such as open tables and running tournaments, to subscribers of the public
+// lobby topic. It needs no sign-in, so a marketing site can embed a live
+// lobby; sources must only hand out what anyone may see. Sections are only
+// collected while someone is subscribed.
+type PublicLobbyPublisher struct {
+	hub HubInterface
+	now func() time.Time
+
+	mu      sync.Mutex
+	sources map[string]StatsSource
+	stop    chan struct{}
+}
+
+// NewPublicLobbyPublisher creates a publisher for the hub's public lobby topic
+func NewPublicLobbyPublisher(hub HubInterface) *PublicLobbyPublisher {
+	return &PublicLobbyPublisher{
+		hub:     hub,
+		now:     time.Now,
+		sources: make(map[string]StatsSource),
+	}
+}
+
+// AddSource adds a named section to every update, replacing any source
+// already registered under that name
+func (p *PublicLobbyPublisher) AddSource(name string, source StatsSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources[name] = source
+}
+
+// Snapshot collects every registered section
+func (p *PublicLobbyPublisher) Snapshot() map[string]interface{} {
+	p.mu.Lock()
+	sources := make(map[string]StatsSource, len(p.sources))
+	names := make([]string, 0, len(p.sources))
+	for name, source := range p.sources {
+		sources[name] = source
+		names = append(names, name)
+	}
+	p.mu.Unlock()
+	sort.Strings(names)
+
+	snapshot := map[string]interface{}{"generated_at": p.now().UTC()}
+	for _, name := range names {
+		snapshot[name] = p.collect(name, sources[name])
+	}
+	return snapshot
+}
+
+// collect runs one source, reporting a panic as an error section rather
+// than taking the publisher down
+func (p *PublicLobbyPublisher) collect(name string, source StatsSource) (section interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("PublicLobbyPublisher: source %s panicked: %v", name, r)
+			section = map[string]interface{}{"error": "source unavailable"}
+		}
+	}()
+	return source()
+}
+
+// Publish sends the lobby to the public lobby topic, returning how many
+// connections received it. Nothing is collected without subscribers.
+func (p *PublicLobbyPublisher) Publish() int {
+	if p.hub.Stats().TopicSubscribers[PublicLobbyTopic] == 0 {
+		return 0
+	}
+	return p.hub.PublishToTopic(PublicLobbyTopic, &Message{
+		Type:    PublicLobbyUpdateType,
+		Event:   "public_lobby",
+		Success: true,
+		Data:    p.Snapshot(),
+	})
+}
+
+// Start publishes the lobby on an interval until Stop is called
+func (p *PublicLobbyPublisher) Start(interval time.Duration) {
+	if interval < MinStatsInterval {
+		interval = MinStatsInterval
+	}
+
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	p.stop = make(chan struct{})
+	stop := p.stop
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.Publish()
+			}
+		}
+	}()
+}
+
+// Stop halts periodic publishing
+func (p *PublicLobbyPublisher) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// handleSubscribePublicLobby adds the connection, signed in or not, to the
+// public lobby topic and replies with the current lobby
+func (s *Server) handleSubscribePublicLobby(ctx context.Context, conn *Connection, msg *Message) *Message {
+	if err := s.hub.SubscribeTopic(conn.ID, PublicLobbyTopic); err != nil {
+		return &Message{
+			Type:      "subscribe_public_lobby_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     err.Error(),
+		}
+	}
+
+	return &Message{
+		Type:      "subscribe_public_lobby_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data:      s.publicLobby.Snapshot(),
+	}
+}
+
+// handleUnsubscribePublicLobby removes the connection from the public lobby topic
+func (s *Server) handleUnsubscribePublicLobby(ctx context.Context, conn *Connection, msg *Message) *Message {
+	s.hub.UnsubscribeTopic(conn.ID, PublicLobbyTopic)
+	return &Message{
+		Type:      "unsubscribe_public_lobby_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+	}
+}
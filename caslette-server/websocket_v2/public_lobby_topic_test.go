@@ -0,0 +1,58 @@
+package websocket_v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicLobbyIsOpenWithoutSignIn(t *testing.T) {
+	server := NewServer(nil)
+	hub := server.GetHub().(*ActorHub)
+	defer hub.Stop()
+	server.PublicLobby().AddSource("tables", func() interface{} {
+		return []map[string]interface{}{{"id": "t1", "players": 4}}
+	})
+	server.PublicLobby().AddSource("tournaments", func() interface{} { panic("boom") })
+	assert.Equal(t, 0, server.PublicLobby().Publish(), "nothing is sent without subscribers")
+
+	visitor := registerTestConnection(t, hub, "")
+	hub.ProcessMessage(visitor, &Message{Type: "subscribe_public_lobby", RequestID: "r1"})
+	reply := readReply(t, visitor)
+	require.True(t, reply.Success, reply.Error)
+	snapshot := reply.Data.(map[string]interface{})
+	assert.Len(t, snapshot["tables"], 1, "the reply carries the current lobby")
+	assert.Equal(t, map[string]interface{}{"error": "source unavailable"}, snapshot["tournaments"])
+	assert.Contains(t, snapshot, "generated_at")
+
+	assert.Equal(t, 1, server.PublicLobby().Publish())
+	update := readReply(t, visitor)
+	assert.Equal(t, PublicLobbyUpdateType, update.Type)
+	tables := update.Data.(map[string]interface{})["tables"].([]interface{})
+	assert.Equal(t, float64(4), tables[0].(map[string]interface{})["players"])
+
+	hub.ProcessMessage(visitor, &Message{Type: "unsubscribe_public_lobby", RequestID: "r2"})
+	assert.True(t, readReply(t, visitor).Success)
+	assert.Equal(t, 0, hub.Stats().TopicSubscribers[PublicLobbyTopic])
+}
+
+func TestPublicLobbyVisitorsCannotMutate(t *testing.T) {
+	server := NewServer(nil)
+	hub := server.GetHub().(*ActorHub)
+	defer hub.Stop()
+	visitor := registerTestConnection(t, hub, "")
+
+	hub.ProcessMessage(visitor, &Message{Type: "join_room", RequestID: "r1", Data: map[string]interface{}{"room": "lounge"}})
+	reply := readReplyOfType(t, visitor, "join_room_response")
+	assert.False(t, reply.Success)
+	assert.Equal(t, "Authentication required to join room", reply.Error)
+
+	hub.ProcessMessage(visitor, &Message{Type: "send_to_room", RequestID: "r2", Data: map[string]interface{}{"room": "lounge", "message": "hi"}})
+	reply = readReplyOfType(t, visitor, "send_to_room_response")
+	assert.False(t, reply.Success)
+	assert.Equal(t, "Authentication required", reply.Error)
+
+	hub.ProcessMessage(visitor, &Message{Type: "subscribe_lobby_stats", RequestID: "r3"})
+	assert.False(t, readReplyOfType(t, visitor, "subscribe_lobby_stats_response").Success)
+}
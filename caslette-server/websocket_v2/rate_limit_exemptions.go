@@ -0,0 +1,128 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// System actors that may be exempted from message rate limits
+const (
+	SystemActorTournament = "tournament" // Tournament controller
+	SystemActorBot        = "bot"        // House bots
+	SystemActorAdmin      = "admin"      // Admin tools
+	SystemActorOther      = "system"     // Any other internal service
+)
+
+// RateLimitExemption lets one user's connections bypass the hub and class
+// rate limits
+type RateLimitExemption struct {
+	UserID string `json:"user_id"`
+	Actor  string `json:"actor"`
+}
+
+// RateLimitStats splits message counts between limited and exempt traffic
+type RateLimitStats struct {
+	LimitedMessages int64            `json:"limited_messages"` // Refused by the hub limiter
+	ExemptMessages  map[string]int64 `json:"exempt_messages"`  // Let through unlimited, by actor
+}
+
+// RateLimitExemptions holds the users whose connections skip rate limiting,
+// such as the tournament controller, house bots and admin tools. Exemptions
+// can be granted and revoked at runtime; exempt traffic is counted per actor
+// so it stays visible in metrics.
+type RateLimitExemptions struct {
+	mu      sync.RWMutex
+	users   map[string]string // User ID to actor
+	exempt  map[string]int64
+	limited int64
+}
+
+// NewRateLimitExemptions creates an empty exemption list
+func NewRateLimitExemptions() *RateLimitExemptions {
+	return &RateLimitExemptions{
+		users:  make(map[string]string),
+		exempt: make(map[string]int64),
+	}
+}
+
+// Exempt lets a user's connections bypass rate limits as the given actor
+func (e *RateLimitExemptions) Exempt(userID, actor string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID is required")
+	}
+	if actor == "" {
+		return fmt.Errorf("actor is required")
+	}
+	e.mu.Lock()
+	e.users[userID] = actor
+	e.mu.Unlock()
+	return nil
+}
+
+// Revoke puts a user back under rate limits, reporting whether it was exempt
+func (e *RateLimitExemptions) Revoke(userID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.users[userID]; !ok {
+		return false
+	}
+	delete(e.users, userID)
+	return true
+}
+
+// Actor returns the actor a user is exempt as, if any
+func (e *RateLimitExemptions) Actor(userID string) (string, bool) {
+	if userID == "" {
+		return "", false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	actor, ok := e.users[userID]
+	return actor, ok
+}
+
+// List returns the current exemptions ordered by user ID
+func (e *RateLimitExemptions) List() []RateLimitExemption {
+	e.mu.RLock()
+	exemptions := make([]RateLimitExemption, 0, len(e.users))
+	for userID, actor := range e.users {
+		exemptions = append(exemptions, RateLimitExemption{UserID: userID, Actor: actor})
+	}
+	e.mu.RUnlock()
+	sort.Slice(exemptions, func(i, j int) bool { return exemptions[i].UserID < exemptions[j].UserID })
+	return exemptions
+}
+
+// Stats returns how many messages were limited and let through exempt
+func (e *RateLimitExemptions) Stats() RateLimitStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	stats := RateLimitStats{LimitedMessages: e.limited, ExemptMessages: make(map[string]int64, len(e.exempt))}
+	for actor, count := range e.exempt {
+		stats.ExemptMessages[actor] = count
+	}
+	return stats
+}
+
+// allow reports whether a user's message skips rate limiting, counting it
+// against the user's actor when it does
+func (e *RateLimitExemptions) allow(userID string) bool {
+	if userID == "" {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	actor, ok := e.users[userID]
+	if ok {
+		e.exempt[actor]++
+	}
+	return ok
+}
+
+// recordLimited counts a message refused by the hub limiter
+func (e *RateLimitExemptions) recordLimited() {
+	e.mu.Lock()
+	e.limited++
+	e.mu.Unlock()
+}
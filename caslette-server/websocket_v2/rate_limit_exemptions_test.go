@@ -0,0 +1,74 @@
+package websocket_v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitExemptionsGrantAndRevoke(t *testing.T) {
+	exemptions := NewRateLimitExemptions()
+	assert.Error(t, exemptions.Exempt("", SystemActorBot))
+	assert.Error(t, exemptions.Exempt("7", ""))
+
+	require.NoError(t, exemptions.Exempt("9", SystemActorAdmin))
+	require.NoError(t, exemptions.Exempt("7", SystemActorTournament))
+	actor, ok := exemptions.Actor("7")
+	assert.True(t, ok)
+	assert.Equal(t, SystemActorTournament, actor)
+	assert.Equal(t, []RateLimitExemption{
+		{UserID: "7", Actor: SystemActorTournament},
+		{UserID: "9", Actor: SystemActorAdmin},
+	}, exemptions.List())
+
+	assert.True(t, exemptions.Revoke("7"))
+	assert.False(t, exemptions.Revoke("7"))
+	_, ok = exemptions.Actor("7")
+	assert.False(t, ok)
+	_, ok = exemptions.Actor("")
+	assert.False(t, ok, "anonymous connections are never exempt")
+}
+
+func TestActorHubLetsExemptActorsThrough(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	require.NoError(t, hub.RateLimitExemptions().Exempt("7", SystemActorTournament))
+	controller := registerTestConnection(t, hub, "7")
+	player := registerTestConnection(t, hub, "8")
+
+	burst := MaxMessagesPerSecond + 5
+	for i := 0; i < burst; i++ {
+		hub.ProcessMessage(controller, &Message{Type: "test_echo"})
+		hub.ProcessMessage(player, &Message{Type: "test_echo"})
+	}
+
+	penalties := hub.Penalties().List()
+	require.Len(t, penalties, 1)
+	assert.Equal(t, "user:8", penalties[0].Subject, "only the player is penalised")
+
+	stats := hub.Stats().RateLimits
+	assert.Equal(t, int64(burst), stats.ExemptMessages[SystemActorTournament])
+	assert.Equal(t, int64(burst-MaxMessagesPerSecond), stats.LimitedMessages)
+}
+
+func TestHandlerRegistrySkipsClassBudgetsForExemptActors(t *testing.T) {
+	registry := NewHandlerRegistry()
+	exemptions := NewRateLimitExemptions()
+	registry.SetRateLimitExemptions(exemptions)
+	require.NoError(t, exemptions.Exempt("7", SystemActorBot))
+	require.NoError(t, registry.Register(HandlerSpec{
+		Name: "act", RequireAuth: true, AllowBots: true, RateLimitClass: RateLimitStrict, Handler: okHandler,
+	}))
+	handler := registry.Wrap("act")
+
+	houseBot := &Connection{ID: "house", UserID: "7", Bot: &BotScope{}}
+	for i := 0; i < 5; i++ {
+		assert.True(t, handler(context.Background(), houseBot, &Message{Type: "act"}).Success)
+	}
+
+	human := &Connection{ID: "human", UserID: "8"}
+	assert.True(t, handler(context.Background(), human, &Message{Type: "act"}).Success)
+	assert.False(t, handler(context.Background(), human, &Message{Type: "act"}).Success, "others keep the class budget")
+}
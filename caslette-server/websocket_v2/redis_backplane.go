@@ -0,0 +1,284 @@
+package websocket_v2
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Redis backplane defaults
+const (
+	DefaultRedisChannel      = "caslette:hub"
+	redisDialTimeout         = 5 * time.Second
+	redisWriteTimeout        = 5 * time.Second
+	redisMaxReconnectBackoff = 10 * time.Second
+)
+
+// RedisConfig locates the Redis server a backplane publishes through
+type RedisConfig struct {
+	Addr     string // host:port
+	Password string // Sent with AUTH when set
+	Channel  string // Pub/sub channel shared by the cluster
+}
+
+// RedisBackplane is a Backplane over Redis pub/sub. It publishes on one
+// connection and listens on another, redialing either after a failure;
+// envelopes published while the listener is down are not seen by this
+// instance.
+type RedisBackplane struct {
+	config RedisConfig
+
+	pubMu sync.Mutex
+	pub   *redisConn
+
+	mu     sync.Mutex
+	sub    *redisConn
+	closed bool
+	done   chan struct{}
+}
+
+// NewRedisBackplane creates a backplane publishing on config.Channel
+func NewRedisBackplane(config RedisConfig) (*RedisBackplane, error) {
+	if config.Addr == "" {
+		return nil, errors.New("redis address is required")
+	}
+	if config.Channel == "" {
+		config.Channel = DefaultRedisChannel
+	}
+	return &RedisBackplane{config: config, done: make(chan struct{})}, nil
+}
+
+// Publish sends a payload to every instance listening on the channel
+func (r *RedisBackplane) Publish(payload []byte) error {
+	r.pubMu.Lock()
+	defer r.pubMu.Unlock()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if r.pub == nil {
+			conn, err := dialRedis(r.config)
+			if err != nil {
+				return err
+			}
+			r.pub = conn
+		}
+		if _, err := r.pub.do("PUBLISH", r.config.Channel, string(payload)); err != nil {
+			// A dropped connection is redialed once
+			r.pub.close()
+			r.pub = nil
+			if attempt == 1 {
+				return err
+			}
+			continue
+		}
+		return nil
+	}
+	return nil
+}
+
+// Subscribe listens on the channel until the backplane is closed. The first
+// subscription must succeed; later failures are retried with backoff.
+func (r *RedisBackplane) Subscribe(handler func(payload []byte)) error {
+	conn, err := r.subscribe()
+	if err != nil {
+		return err
+	}
+	go r.listen(conn, handler)
+	return nil
+}
+
+// subscribe dials a connection subscribed to the channel
+func (r *RedisBackplane) subscribe() (*redisConn, error) {
+	conn, err := dialRedis(r.config)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.send("SUBSCRIBE", r.config.Channel); err != nil {
+		conn.close()
+		return nil, err
+	}
+	reply, err := conn.read()
+	if err != nil {
+		conn.close()
+		return nil, err
+	}
+	if parts, ok := reply.([]interface{}); !ok || len(parts) < 1 || parts[0] != "subscribe" {
+		conn.close()
+		return nil, fmt.Errorf("unexpected reply to SUBSCRIBE: %v", reply)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		conn.close()
+		return nil, errors.New("backplane closed")
+	}
+	r.sub = conn
+	return conn, nil
+}
+
+// listen delivers channel messages to the handler, resubscribing when the
+// connection drops
+func (r *RedisBackplane) listen(conn *redisConn, handler func(payload []byte)) {
+	backoff := 100 * time.Millisecond
+	for {
+		for {
+			reply, err := conn.read()
+			if err != nil {
+				break
+			}
+			backoff = 100 * time.Millisecond
+			parts, ok := reply.([]interface{})
+			if !ok || len(parts) != 3 || parts[0] != "message" {
+				continue
+			}
+			if payload, ok := parts[2].(string); ok {
+				handler([]byte(payload))
+			}
+		}
+		conn.close()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-time.After(backoff):
+			}
+			var err error
+			if conn, err = r.subscribe(); err == nil {
+				break
+			}
+			log.Printf("RedisBackplane: Failed to resubscribe: %v", err)
+			backoff = min(backoff*2, redisMaxReconnectBackoff)
+		}
+	}
+}
+
+// Close stops listening and closes both connections
+func (r *RedisBackplane) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+	if r.sub != nil {
+		r.sub.close()
+		r.sub = nil
+	}
+	r.mu.Unlock()
+
+	r.pubMu.Lock()
+	defer r.pubMu.Unlock()
+	if r.pub != nil {
+		r.pub.close()
+		r.pub = nil
+	}
+	return nil
+}
+
+// redisConn speaks just enough RESP for AUTH, PUBLISH and SUBSCRIBE
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dialRedis connects and authenticates
+func dialRedis(config RedisConfig) (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", config.Addr, redisDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if config.Password != "" {
+		if _, err := rc.do("AUTH", config.Password); err != nil {
+			rc.close()
+			return nil, fmt.Errorf("redis AUTH failed: %w", err)
+		}
+	}
+	return rc, nil
+}
+
+// do sends a command and reads its reply
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// send writes a command as an array of bulk strings
+func (c *redisConn) send(args ...string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(redisWriteTimeout))
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// read parses one reply. Error replies are returned as errors.
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, errors.New(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unknown redis reply type %q", kind)
+}
+
+func (c *redisConn) close() {
+	c.conn.Close()
+}
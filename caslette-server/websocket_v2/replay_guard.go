@@ -0,0 +1,101 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Replay protection limits for money-moving messages
+const (
+	DefaultNonceWindow = 2 * time.Minute // Furthest ahead a request may set its expiry
+	MinNonceLength     = 16
+	MaxNonceLength     = 128
+	MaxPendingNonces   = 1000 // Unexpired nonces remembered per user
+)
+
+// ReplayGuard refuses money-moving requests that were already seen or have
+// expired. Each request carries a client-chosen nonce and an expires_at unix
+// time no further ahead than the window; the guard remembers a user's nonces
+// until they expire, so a captured frame cannot be sent again while it is
+// still valid and is refused as expired afterwards.
+type ReplayGuard struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]map[string]time.Time // User ID -> nonce -> expiry
+	now    func() time.Time
+}
+
+// NewReplayGuard creates a guard accepting expiries up to window ahead
+func NewReplayGuard(window time.Duration) *ReplayGuard {
+	if window <= 0 {
+		window = DefaultNonceWindow
+	}
+	return &ReplayGuard{
+		window: window,
+		seen:   make(map[string]map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Check verifies the nonce and expiry in a message payload and records the
+// nonce as used
+func (g *ReplayGuard) Check(userID string, data interface{}) error {
+	payload, _ := data.(map[string]interface{})
+	nonce, _ := payload["nonce"].(string)
+	if len(nonce) < MinNonceLength || len(nonce) > MaxNonceLength {
+		return fmt.Errorf("nonce must be between %d and %d characters", MinNonceLength, MaxNonceLength)
+	}
+	rawExpiry, ok := payload["expires_at"].(float64)
+	if !ok {
+		return fmt.Errorf("expires_at must be a unix timestamp")
+	}
+	expiresAt := time.Unix(int64(rawExpiry), 0)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if !expiresAt.After(now) {
+		return fmt.Errorf("request has expired")
+	}
+	if expiresAt.After(now.Add(g.window)) {
+		return fmt.Errorf("expires_at cannot be more than %s ahead", g.window)
+	}
+
+	nonces := g.seen[userID]
+	if nonces == nil {
+		if len(g.seen) >= maxClassWindows {
+			g.pruneLocked(now)
+		}
+		nonces = make(map[string]time.Time)
+		g.seen[userID] = nonces
+	}
+	for seenNonce, expiry := range nonces {
+		if !expiry.After(now) {
+			delete(nonces, seenNonce)
+		}
+	}
+	if _, used := nonces[nonce]; used {
+		return fmt.Errorf("nonce has already been used")
+	}
+	if len(nonces) >= MaxPendingNonces {
+		return fmt.Errorf("too many pending requests, retry shortly")
+	}
+	nonces[nonce] = expiresAt
+	return nil
+}
+
+// pruneLocked forgets expired nonces so users who went quiet do not accumulate
+func (g *ReplayGuard) pruneLocked(now time.Time) {
+	for userID, nonces := range g.seen {
+		for nonce, expiry := range nonces {
+			if !expiry.After(now) {
+				delete(nonces, nonce)
+			}
+		}
+		if len(nonces) == 0 {
+			delete(g.seen, userID)
+		}
+	}
+}
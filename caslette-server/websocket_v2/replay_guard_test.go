@@ -0,0 +1,99 @@
+package websocket_v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayPayload builds a money-moving payload expiring after ttl
+func replayPayload(nonce string, now time.Time, ttl time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		"nonce":      nonce,
+		"expires_at": float64(now.Add(ttl).Unix()),
+	}
+}
+
+func newTestReplayGuard(now *time.Time) *ReplayGuard {
+	guard := NewReplayGuard(time.Minute)
+	guard.now = func() time.Time { return *now }
+	return guard
+}
+
+func TestReplayGuardRefusesReusedNonce(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	guard := newTestReplayGuard(&now)
+	nonce := strings.Repeat("a", MinNonceLength)
+
+	require.NoError(t, guard.Check("7", replayPayload(nonce, now, 30*time.Second)))
+	err := guard.Check("7", replayPayload(nonce, now, 30*time.Second))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already been used")
+
+	// Nonces are tracked per user
+	assert.NoError(t, guard.Check("8", replayPayload(nonce, now, 30*time.Second)))
+}
+
+func TestReplayGuardValidatesNonceAndExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	guard := newTestReplayGuard(&now)
+	nonce := strings.Repeat("b", MinNonceLength)
+
+	assert.Error(t, guard.Check("7", map[string]interface{}{"expires_at": float64(now.Unix() + 10)}), "nonce is required")
+	assert.Error(t, guard.Check("7", replayPayload("short", now, 10*time.Second)))
+	assert.Error(t, guard.Check("7", replayPayload(strings.Repeat("x", MaxNonceLength+1), now, 10*time.Second)))
+	assert.Error(t, guard.Check("7", map[string]interface{}{"nonce": nonce}), "expires_at is required")
+	assert.Error(t, guard.Check("7", replayPayload(nonce, now, -time.Second)), "expired requests are refused")
+	assert.Error(t, guard.Check("7", replayPayload(nonce, now, 2*time.Minute)), "expiry beyond the window is refused")
+	assert.NoError(t, guard.Check("7", replayPayload(nonce, now, time.Minute)))
+}
+
+func TestReplayGuardForgetsNoncesOnceExpired(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	guard := newTestReplayGuard(&now)
+	nonce := strings.Repeat("c", MinNonceLength)
+	frame := replayPayload(nonce, now, 10*time.Second)
+
+	require.NoError(t, guard.Check("7", frame))
+	now = now.Add(11 * time.Second)
+	assert.Error(t, guard.Check("7", frame), "the captured frame has expired")
+	assert.NoError(t, guard.Check("7", replayPayload(nonce, now, 10*time.Second)))
+	assert.Len(t, guard.seen["7"], 1, "expired nonces are dropped")
+}
+
+func TestReplayGuardCapsPendingNonces(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	guard := newTestReplayGuard(&now)
+	for i := 0; i < MaxPendingNonces; i++ {
+		require.NoError(t, guard.Check("7", replayPayload(fmt.Sprintf("nonce-%016d", i), now, 30*time.Second)))
+	}
+	err := guard.Check("7", replayPayload(strings.Repeat("z", MinNonceLength), now, 30*time.Second))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many pending requests")
+}
+
+func TestHandlerRegistryRequiresNonceForMoneyMovingHandlers(t *testing.T) {
+	registry := NewHandlerRegistry()
+	require.NoError(t, registry.Register(HandlerSpec{Name: "buy_in", MovesMoney: true, Handler: okHandler}))
+	spec, _ := registry.Get("buy_in")
+	assert.True(t, spec.RequireAuth, "moving money implies authentication")
+	handler := registry.Wrap("buy_in")
+	conn := &Connection{ID: "c1", UserID: "7"}
+
+	resp := handler(context.Background(), conn, &Message{Type: "buy_in", Data: map[string]interface{}{}})
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "Replay protection")
+
+	data := replayPayload(strings.Repeat("f", MinNonceLength), time.Now(), 30*time.Second)
+	resp = handler(context.Background(), conn, &Message{Type: "buy_in", Data: data})
+	assert.True(t, resp.Success)
+
+	resp = handler(context.Background(), conn, &Message{Type: "buy_in", Data: data})
+	assert.False(t, resp.Success, "the same frame cannot be replayed")
+	assert.Contains(t, resp.Error, "already been used")
+}
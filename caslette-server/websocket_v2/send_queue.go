@@ -0,0 +1,230 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens when a connection's send queue is full
+type OverflowPolicy string
+
+// Overflow policies
+const (
+	OverflowDisconnect OverflowPolicy = "disconnect" // Close the connection, telling the client why
+	OverflowDrop       OverflowPolicy = "drop"       // Drop the message; disconnect after MaxDrops in a row
+)
+
+// SlowConsumerCode is the reason given to clients disconnected for not
+// reading their messages fast enough
+const SlowConsumerCode = "slow_consumer"
+
+// DefaultSendQueueSize is how many outbound frames a connection may have
+// waiting for the socket
+const DefaultSendQueueSize = 256
+
+// slowestConnectionsShown is how many of the fullest queues diagnostics list
+const slowestConnectionsShown = 10
+
+// SendQueuePolicy bounds each connection's outbound queue
+type SendQueuePolicy struct {
+	Size     int            `json:"size"`
+	Overflow OverflowPolicy `json:"overflow"`
+	MaxDrops int            `json:"max_drops"` // With OverflowDrop, drops in a row before disconnecting; zero never disconnects
+}
+
+// DefaultSendQueuePolicy disconnects clients as soon as 256 frames are
+// waiting, since a client that far behind shows stale tables anyway
+func DefaultSendQueuePolicy() SendQueuePolicy {
+	return SendQueuePolicy{Size: DefaultSendQueueSize, Overflow: OverflowDisconnect}
+}
+
+// Validate checks the size and policy
+func (p SendQueuePolicy) Validate() error {
+	if p.Size <= 0 {
+		return fmt.Errorf("send queue size must be positive")
+	}
+	switch p.Overflow {
+	case OverflowDisconnect, OverflowDrop:
+	default:
+		return fmt.Errorf("unknown send queue overflow policy %q", p.Overflow)
+	}
+	if p.MaxDrops < 0 {
+		return fmt.Errorf("max drops cannot be negative")
+	}
+	return nil
+}
+
+// ConnectionSendQueue is one connection's queue in diagnostics
+type ConnectionSendQueue struct {
+	ConnectionID string `json:"connection_id"`
+	UserID       string `json:"user_id,omitempty"`
+	Depth        int    `json:"depth"`
+	Capacity     int    `json:"capacity"`
+	Dropped      int64  `json:"dropped"`
+}
+
+// SendQueueDiagnostics is the admin view of outbound queues
+type SendQueueDiagnostics struct {
+	Policy       SendQueuePolicy       `json:"policy"`
+	Connections  int                   `json:"connections"`
+	QueuedFrames int                   `json:"queued_frames"` // Frames waiting across all connections
+	MaxDepth     int                   `json:"max_depth"`     // Fullest queue right now
+	HighWater    int                   `json:"high_water"`    // Fullest queue seen since start
+	Dropped      int64                 `json:"dropped"`       // Messages dropped on full queues
+	Disconnected int64                 `json:"disconnected"`  // Connections closed as slow consumers
+	Slowest      []ConnectionSendQueue `json:"slowest"`
+}
+
+// SendQueueMonitor applies the send queue policy to new connections and
+// tracks queue depth and overflows across the live ones
+type SendQueueMonitor struct {
+	mu           sync.Mutex
+	policy       SendQueuePolicy
+	connections  map[*Connection]bool
+	highWater    atomic.Int64
+	dropped      atomic.Int64
+	disconnected atomic.Int64
+}
+
+// NewSendQueueMonitor creates a monitor with the default policy
+func NewSendQueueMonitor() *SendQueueMonitor {
+	return &SendQueueMonitor{
+		policy:      DefaultSendQueuePolicy(),
+		connections: make(map[*Connection]bool),
+	}
+}
+
+// SetPolicy changes the policy of connections opened afterwards
+func (m *SendQueueMonitor) SetPolicy(policy SendQueuePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+	return nil
+}
+
+// Policy returns the policy applied to new connections
+func (m *SendQueueMonitor) Policy() SendQueuePolicy {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.policy
+}
+
+// Add gives a connection that has not sent anything yet a queue of the
+// policy's size and starts tracking it
+func (m *SendQueueMonitor) Add(conn *Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	conn.Send = make(chan []byte, m.policy.Size)
+	conn.sendPolicy = m.policy
+	conn.sendQueue = m
+	m.connections[conn] = true
+}
+
+// Remove stops tracking a closed connection
+func (m *SendQueueMonitor) Remove(conn *Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.connections, conn)
+}
+
+// Diagnostics returns queue depths, fullest first, and overflow counts
+func (m *SendQueueMonitor) Diagnostics() SendQueueDiagnostics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	diagnostics := SendQueueDiagnostics{
+		Policy:       m.policy,
+		Connections:  len(m.connections),
+		HighWater:    int(m.highWater.Load()),
+		Dropped:      m.dropped.Load(),
+		Disconnected: m.disconnected.Load(),
+		Slowest:      make([]ConnectionSendQueue, 0, len(m.connections)),
+	}
+	for conn := range m.connections {
+		depth := len(conn.Send)
+		diagnostics.QueuedFrames += depth
+		diagnostics.MaxDepth = max(diagnostics.MaxDepth, depth)
+		diagnostics.Slowest = append(diagnostics.Slowest, ConnectionSendQueue{
+			ConnectionID: conn.ID,
+			UserID:       conn.UserID,
+			Depth:        depth,
+			Capacity:     cap(conn.Send),
+			Dropped:      conn.sendDropped.Load(),
+		})
+	}
+	sort.Slice(diagnostics.Slowest, func(i, j int) bool {
+		return diagnostics.Slowest[i].Depth > diagnostics.Slowest[j].Depth
+	})
+	if len(diagnostics.Slowest) > slowestConnectionsShown {
+		diagnostics.Slowest = diagnostics.Slowest[:slowestConnectionsShown]
+	}
+	return diagnostics
+}
+
+// queued records a frame going out, tracking the deepest queue seen
+func (c *Connection) queued() {
+	c.sendDrops.Store(0)
+	if c.sendQueue == nil {
+		return
+	}
+	depth := int64(len(c.Send))
+	for {
+		high := c.sendQueue.highWater.Load()
+		if depth <= high || c.sendQueue.highWater.CompareAndSwap(high, depth) {
+			return
+		}
+	}
+}
+
+// overflow applies the overflow policy to a message that found the send
+// queue full
+func (c *Connection) overflow(msgType string) {
+	if c.sendPolicy.Overflow == OverflowDrop {
+		c.sendDropped.Add(1)
+		if c.sendQueue != nil {
+			c.sendQueue.dropped.Add(1)
+		}
+		drops := c.sendDrops.Add(1)
+		c.Logf("SendMessage: Dropped %s for connection %s (send queue full)", msgType, c.ID)
+		if c.sendPolicy.MaxDrops == 0 || drops < int64(c.sendPolicy.MaxDrops) {
+			return
+		}
+	}
+	c.disconnectSlowConsumer()
+}
+
+// disconnectSlowConsumer has the write pump tell the client it is being
+// disconnected for falling behind, then close the socket; the read pump
+// then unregisters the connection
+func (c *Connection) disconnectSlowConsumer() {
+	if !c.slow.CompareAndSwap(false, true) {
+		return
+	}
+	c.Logf("Connection %s send queue full, disconnecting slow consumer", c.ID)
+	if c.sendQueue != nil {
+		c.sendQueue.disconnected.Add(1)
+	}
+	if c.slowConsumer != nil {
+		close(c.slowConsumer)
+	}
+}
+
+// slowConsumerNotice is the last message a slow consumer receives, sent
+// ahead of its queue
+func (c *Connection) slowConsumerNotice() *Message {
+	return &Message{
+		Type:    "connection_closed",
+		Success: false,
+		Error:   "Disconnected for not reading messages fast enough",
+		Data: map[string]interface{}{
+			"code":       SlowConsumerCode,
+			"queue_size": cap(c.Send),
+			"dropped":    c.sendDropped.Load(),
+		},
+	}
+}
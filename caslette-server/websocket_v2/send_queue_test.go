@@ -0,0 +1,101 @@
+package websocket_v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQueuedConnection creates a connection whose queue follows the policy
+func newQueuedConnection(t *testing.T, policy SendQueuePolicy) (*Connection, *SendQueueMonitor) {
+	monitor := NewSendQueueMonitor()
+	require.NoError(t, monitor.SetPolicy(policy))
+	conn := &Connection{ID: "c1", UserID: "7", Rooms: make(map[string]bool), slowConsumer: make(chan struct{})}
+	monitor.Add(conn)
+	return conn, monitor
+}
+
+func disconnected(conn *Connection) bool {
+	select {
+	case <-conn.slowConsumer:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestDropPolicyDisconnectsAfterDropsInARow(t *testing.T) {
+	conn, monitor := newQueuedConnection(t, SendQueuePolicy{Size: 2, Overflow: OverflowDrop, MaxDrops: 2})
+
+	for i := 0; i < 3; i++ {
+		conn.SendMessage(&Message{Type: "game_event"})
+	}
+	assert.False(t, disconnected(conn), "one drop is tolerated")
+	diagnostics := monitor.Diagnostics()
+	assert.Equal(t, int64(1), diagnostics.Dropped)
+	assert.Equal(t, 2, diagnostics.MaxDepth)
+	assert.Equal(t, 2, diagnostics.HighWater)
+	require.Len(t, diagnostics.Slowest, 1)
+	assert.Equal(t, ConnectionSendQueue{ConnectionID: "c1", UserID: "7", Depth: 2, Capacity: 2, Dropped: 1}, diagnostics.Slowest[0])
+
+	<-conn.Send
+	conn.SendMessage(&Message{Type: "game_event"})
+	conn.SendMessage(&Message{Type: "game_event"})
+	assert.False(t, disconnected(conn), "a delivered message resets the count")
+
+	conn.SendMessage(&Message{Type: "game_event"})
+	assert.True(t, disconnected(conn))
+	assert.Equal(t, int64(1), monitor.Diagnostics().Disconnected)
+
+	conn.SendMessage(&Message{Type: "game_event"})
+	assert.Equal(t, int64(3), monitor.Diagnostics().Dropped, "nothing more is counted once disconnecting")
+}
+
+func TestSlowConsumerIsToldWhyBeforeDisconnecting(t *testing.T) {
+	accepted := make(chan *Connection, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := newConnection(nil, w, r, CompressionPolicy{})
+		require.NoError(t, err)
+		monitor := NewSendQueueMonitor()
+		require.NoError(t, monitor.SetPolicy(SendQueuePolicy{Size: 1, Overflow: OverflowDisconnect}))
+		monitor.Add(conn)
+		accepted <- conn
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer client.Close()
+	conn := <-accepted
+
+	conn.SendMessage(&Message{Type: "game_event"})
+	conn.SendMessage(&Message{Type: "game_event"})
+	require.True(t, disconnected(conn))
+	go conn.writePump()
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var notice Message
+	for notice.Type != "connection_closed" {
+		require.NoError(t, client.ReadJSON(&notice))
+	}
+	assert.Equal(t, SlowConsumerCode, notice.Data.(map[string]interface{})["code"])
+	_, _, err = client.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	assert.Equal(t, SlowConsumerCode, closeErr.Text)
+}
+
+func TestSendQueuePolicyValidation(t *testing.T) {
+	assert.NoError(t, DefaultSendQueuePolicy().Validate())
+	assert.NoError(t, SendQueuePolicy{Size: 8, Overflow: OverflowDrop}.Validate())
+	assert.Error(t, SendQueuePolicy{Overflow: OverflowDrop}.Validate())
+	assert.Error(t, SendQueuePolicy{Size: 8, Overflow: "block"}.Validate())
+	assert.Error(t, SendQueuePolicy{Size: 8, Overflow: OverflowDrop, MaxDrops: -1}.Validate())
+}
@@ -4,14 +4,40 @@ import (
 	"caslette-server/auth"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Server wraps the WebSocket hub with additional functionality
 type Server struct {
 	hub         HubInterface
 	authService *auth.AuthService
+	registry    *HandlerRegistry
+	bandwidth   *BandwidthMonitor
+	stats       *StatsPublisher
+	lobby       *LobbyPublisher
+	publicLobby *PublicLobbyPublisher
+	chat        *ChatControls
+	shedder     *LoadShedder
+	sendQueues  *SendQueueMonitor
+
+	// Set by Shutdown; new connections are refused from then on
+	shuttingDown atomic.Bool
+
+	jwtAuth        AuthHandler
+	botValidator   BotTokenValidator
+	trustedProxies []*net.IPNet
+	compression    CompressionPolicy
+	messageSize    MessageSizePolicy
+	heartbeat      HeartbeatPolicy
+	mu             sync.RWMutex
 }
 
 // NewServer creates a new WebSocket server
@@ -20,10 +46,28 @@ func NewServer(authService *auth.AuthService) *Server {
 	server := &Server{
 		hub:         hub,
 		authService: authService,
+		registry:    NewHandlerRegistry(),
+		bandwidth:   NewBandwidthMonitor(),
+		stats:       NewStatsPublisher(hub),
+		lobby:       NewLobbyPublisher(hub),
+		publicLobby: NewPublicLobbyPublisher(hub),
+		chat:        hub.ChatControls(),
+		shedder:     NewLoadShedder(),
+		sendQueues:  NewSendQueueMonitor(),
+		compression: DefaultCompressionPolicy(),
+		messageSize: DefaultMessageSizePolicy(),
+		heartbeat:   DefaultHeartbeatPolicy(),
 	}
 
-	// Set up authentication handler once
-	hub.SetAuthHandler(CreateWebSocketAuthHandler(authService))
+	server.registry.SetPenaltyTracker(hub.Penalties())
+	server.registry.SetRateLimitExemptions(hub.RateLimitExemptions())
+	server.registry.SetLoadShedder(server.shedder)
+	server.shedder.AddMailbox("hub", hub.MailboxDepth)
+	server.shedder.AddMailbox("broadcasts", hub.broadcasts.Pending)
+
+	// Set up authentication handler once; bot tokens are routed separately
+	server.jwtAuth = CreateWebSocketAuthHandler(authService)
+	hub.SetAuthHandler(server.authenticate)
 	log.Printf("WebSocket server created with authentication handler")
 
 	// Register built-in handlers
@@ -68,14 +112,38 @@ func (s *Server) GetActiveRooms() []string {
 
 // HandleWebSocket handles WebSocket connections
 func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := NewConnection(s.hub, w, r)
+	if s.shuttingDown.Load() {
+		refuseShuttingDownConnection(w)
+		return
+	}
+	if s.shedder.ShedConnection() {
+		log.Printf("WebSocket connection from %s refused: %s", r.RemoteAddr, ServerBusyCode)
+		refuseBusyConnection(w, s.shedder.Policy().CheckInterval)
+		return
+	}
+
+	s.mu.RLock()
+	compression := s.compression
+	messageSize := s.messageSize
+	heartbeat := s.heartbeat
+	s.mu.RUnlock()
+
+	conn, err := newConnection(s.hub, w, r, compression)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		http.Error(w, "Could not open websocket connection", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("New WebSocket connection established: %s", conn.ID)
+	conn.Logf("New WebSocket connection established: %s", conn.ID)
+	conn.bandwidth = s.bandwidth
+	s.sendQueues.Add(conn)
+	conn.maxInboundBytes = messageSize.MaxInboundBytes
+	conn.chunkBytes = messageSize.ChunkBytes
+	conn.heartbeat = heartbeat
+	s.mu.RLock()
+	conn.RemoteIP = clientIP(r, s.trustedProxies)
+	s.mu.RUnlock()
 
 	// Register the connection
 	s.hub.Register(conn)
@@ -84,14 +152,290 @@ func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn.Start()
 }
 
-// RegisterHandler registers a custom message handler
-func (s *Server) RegisterHandler(messageType string, handler MessageHandler) {
-	s.hub.RegisterMessageHandler(messageType, handler)
+// Shutdown stops accepting connections, then shuts the hub down: clients
+// are told with a server_shutting_down message, the drainer finishes what
+// it must, send queues flush and connections close with 1001 Going Away
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+	return s.hub.Shutdown(ctx)
+}
+
+// SetShutdownDrainer sets what Shutdown waits for before disconnecting
+// clients, such as hands in progress
+func (s *Server) SetShutdownDrainer(drainer ShutdownDrainer) {
+	s.hub.SetShutdownDrainer(drainer)
+}
+
+// Register declares a message handler with its auth, permission, schema and
+// rate-limit metadata. The hub dispatches to a wrapper that enforces them.
+func (s *Server) Register(spec HandlerSpec) error {
+	if err := s.registry.Register(spec); err != nil {
+		return err
+	}
+	s.hub.RegisterMessageHandler(spec.Name, s.registry.Wrap(spec.Name))
+	return nil
+}
+
+// RegisterHandler installs a custom message handler, replacing any handler
+// already registered for the type. A replaced handler keeps the declared
+// auth, permission, schema and rate-limit metadata; new types get only what
+// the options declare, such as RequireAuth() and RequirePermission(...), so
+// prefer Register for anything more. Middleware given as an option runs
+// around the handler after the checks pass.
+func (s *Server) RegisterHandler(messageType string, handler MessageHandler, options ...HandlerOption) {
+	if err := s.registry.Override(messageType, handler, options...); err != nil {
+		log.Printf("WebSocket: failed to register handler %s: %v", messageType, err)
+		return
+	}
+	s.hub.RegisterMessageHandler(messageType, s.registry.Wrap(messageType))
+}
+
+// Use adds middleware run around every registered handler, including those
+// registered earlier, ahead of their declared checks
+func (s *Server) Use(middleware ...Middleware) {
+	s.registry.Use(middleware...)
+}
+
+// HandlerSpecs returns the declared metadata of every registered handler
+func (s *Server) HandlerSpecs() []HandlerSpec {
+	return s.registry.Specs()
+}
+
+// SetPermissionChecker sets how declared handler permissions are resolved
+func (s *Server) SetPermissionChecker(checker PermissionChecker) {
+	s.registry.SetPermissionChecker(checker)
+}
+
+// Penalties returns the tracker escalating sanctions against rate-limit violators
+func (s *Server) Penalties() *PenaltyTracker {
+	return s.hub.Penalties()
+}
+
+// RateLimitExemptions returns the system actors exempt from rate limits
+func (s *Server) RateLimitExemptions() *RateLimitExemptions {
+	return s.hub.RateLimitExemptions()
+}
+
+// Bandwidth returns the monitor that accounts connection traffic
+func (s *Server) Bandwidth() *BandwidthMonitor {
+	return s.bandwidth
+}
+
+// Outbox returns the per-user outbox drained by long-poll clients
+func (s *Server) Outbox() *OutboxStore {
+	return s.hub.Outbox()
+}
+
+// Stats returns the publisher behind the admin stats topic
+func (s *Server) Stats() *StatsPublisher {
+	return s.stats
+}
+
+// SetRoomDirectory sets where chat controls look up the owners and
+// observers of rooms the hub did not create, such as table rooms
+func (s *Server) SetRoomDirectory(directory RoomDirectory) {
+	s.chat.SetDirectory(directory)
+}
+
+// Lobby returns the publisher behind the lobby stats topic
+func (s *Server) Lobby() *LobbyPublisher {
+	return s.lobby
+}
+
+// PublicLobby returns the publisher behind the public lobby topic, which
+// connections may follow without signing in
+func (s *Server) PublicLobby() *PublicLobbyPublisher {
+	return s.publicLobby
+}
+
+// SetHandlerTimeout sets how long custom handlers may run before timing out
+func (s *Server) SetHandlerTimeout(timeout time.Duration) {
+	if hub, ok := s.hub.(*ActorHub); ok {
+		hub.SetHandlerTimeout(timeout)
+	}
+}
+
+// SetPresenceHandler sets the hook told when a user's first connection
+// authenticates and when their last connection goes away
+func (s *Server) SetPresenceHandler(handler PresenceHandler) {
+	if hub, ok := s.hub.(*ActorHub); ok {
+		hub.SetPresenceHandler(handler)
+	}
+}
+
+// HandlerBreaker returns the circuit breaker guarding custom handlers, or nil
+// when the hub does not dispatch through one
+func (s *Server) HandlerBreaker() *CircuitBreaker {
+	if hub, ok := s.hub.(*ActorHub); ok {
+		return hub.HandlerBreaker()
+	}
+	return nil
+}
+
+// SetBotTokenValidator enables authentication with bot tokens
+func (s *Server) SetBotTokenValidator(validator BotTokenValidator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.botValidator = validator
+}
+
+// SetTrustedProxies sets the proxies whose forwarding headers identify the
+// client; connections from anywhere else are identified by their peer address
+func (s *Server) SetTrustedProxies(proxies []string) error {
+	networks, err := ParseTrustedProxies(proxies)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trustedProxies = networks
+	return nil
+}
+
+// SetReplayWindow sets how far ahead money-moving requests may expire
+func (s *Server) SetReplayWindow(window time.Duration) {
+	s.registry.SetReplayWindow(window)
+}
+
+// SetResumeWindow sets how long a dropped connection's session can be
+// resumed with its token; zero turns resuming off
+func (s *Server) SetResumeWindow(window time.Duration) {
+	s.hub.Sessions().SetWindow(window)
 }
 
-// SetAuthHandler sets the authentication handler
+// SetSessionPolicy sets whether users may stay connected from several
+// devices or a new login replaces their other connections
+func (s *Server) SetSessionPolicy(policy SessionPolicy) error {
+	return s.hub.SetSessionPolicy(policy)
+}
+
+// SetBackplane relays room broadcasts, user messages and presence through
+// the backplane to the other server instances sharing it
+func (s *Server) SetBackplane(backplane Backplane) error {
+	return s.hub.SetBackplane(backplane)
+}
+
+// SetCompressionPolicy controls permessage-deflate for connections opened
+// afterwards; existing connections keep what they negotiated
+func (s *Server) SetCompressionPolicy(policy CompressionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.compression = policy
+	return nil
+}
+
+// CompressionPolicy returns the policy applied to new connections
+func (s *Server) CompressionPolicy() CompressionPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.compression
+}
+
+// SetMessageSizePolicy sets the inbound message limit and outbound chunk
+// size of connections opened afterwards
+func (s *Server) SetMessageSizePolicy(policy MessageSizePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messageSize = policy
+	return nil
+}
+
+// MessageSizePolicy returns the size limits applied to new connections
+func (s *Server) MessageSizePolicy() MessageSizePolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.messageSize
+}
+
+// SetHeartbeatPolicy sets how often connections opened afterwards are
+// pinged and how long they may go unanswered before being reaped
+func (s *Server) SetHeartbeatPolicy(policy HeartbeatPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeat = policy
+	return nil
+}
+
+// HeartbeatPolicy returns the ping interval and pong timeout applied to new
+// connections
+func (s *Server) HeartbeatPolicy() HeartbeatPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.heartbeat
+}
+
+// SetSendQueuePolicy bounds the send queues of connections opened
+// afterwards and sets what happens when one fills up
+func (s *Server) SetSendQueuePolicy(policy SendQueuePolicy) error {
+	return s.sendQueues.SetPolicy(policy)
+}
+
+// SendQueues returns the outbound queue depths and overflow counts
+func (s *Server) SendQueues() *SendQueueMonitor {
+	return s.sendQueues
+}
+
+// LoadShedder returns the shedder guarding new messages and connections, so
+// callers can add mailbox and database probes
+func (s *Server) LoadShedder() *LoadShedder {
+	return s.shedder
+}
+
+// SetShedPolicy sets when the server counts as overloaded and what it sheds
+func (s *Server) SetShedPolicy(policy ShedPolicy) error {
+	return s.shedder.SetPolicy(policy)
+}
+
+// SetAccessChecker sets the extra check applied to every connection on
+// each message, after authentication and permissions
+func (s *Server) SetAccessChecker(checker AccessChecker) {
+	s.registry.SetAccessChecker(checker)
+}
+
+// SetBotAccessChecker sets the extra check applied to bot connections on
+// handlers that allow bots
+func (s *Server) SetBotAccessChecker(checker BotAccessChecker) {
+	s.registry.SetBotAccessChecker(checker)
+}
+
+// authenticate dispatches bot tokens to the bot validator and everything
+// else to JWT validation
+func (s *Server) authenticate(token string) (*AuthResult, error) {
+	s.mu.RLock()
+	jwtAuth, validator := s.jwtAuth, s.botValidator
+	s.mu.RUnlock()
+
+	if !strings.HasPrefix(token, BotTokenPrefix) {
+		return jwtAuth(token)
+	}
+	if validator == nil {
+		return nil, errors.New("bot tokens are not enabled")
+	}
+
+	result, err := validator(token)
+	if err != nil {
+		return result, err
+	}
+	if result != nil && result.Success && result.Bot == nil {
+		return nil, errors.New("bot token validator returned no scope")
+	}
+	return result, nil
+}
+
+// SetAuthHandler replaces the handler for non-bot tokens
 func (s *Server) SetAuthHandler(handler AuthHandler) {
-	s.hub.SetAuthHandler(handler)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwtAuth = handler
 }
 
 // BroadcastToRoom broadcasts a message to all users in a room
@@ -104,7 +448,7 @@ func (s *Server) BroadcastToRoom(room, messageType string, data interface{}) {
 	s.hub.BroadcastToRoom(room, msg)
 }
 
-// BroadcastToUser sends a message to a specific user
+// BroadcastToUser sends a message to every connection of a user
 func (s *Server) BroadcastToUser(userID, messageType string, data interface{}) {
 	msg := &Message{
 		Type: messageType,
@@ -113,6 +457,16 @@ func (s *Server) BroadcastToUser(userID, messageType string, data interface{}) {
 	s.hub.BroadcastToUser(userID, msg)
 }
 
+// SendToDevice sends a message only to a user's connections authenticated
+// with the device name
+func (s *Server) SendToDevice(userID, device, messageType string, data interface{}) {
+	msg := &Message{
+		Type: messageType,
+		Data: data,
+	}
+	s.hub.SendToDevice(userID, device, msg)
+}
+
 // GetRoomUsers returns users in a specific room
 func (s *Server) GetRoomUsers(room string) []map[string]interface{} {
 	// For now, return empty slice since this would need to be implemented
@@ -123,170 +477,340 @@ func (s *Server) GetRoomUsers(room string) []map[string]interface{} {
 // registerBuiltinHandlers registers built-in message handlers
 func (s *Server) registerBuiltinHandlers() {
 	// Echo handler for testing
-	s.RegisterHandler("echo", func(ctx context.Context, conn *Connection, msg *Message) *Message {
-		return &Message{
-			Type:      "echo_response",
-			RequestID: msg.RequestID,
-			Success:   true,
-			Data:      msg.Data,
-		}
+	s.mustRegister(HandlerSpec{
+		Name:           "echo",
+		Description:    "Echoes the request payload back to the sender",
+		RateLimitClass: RateLimitRead,
+		Priority:       PriorityLow,
+		Handler: func(ctx context.Context, conn *Connection, msg *Message) *Message {
+			return &Message{
+				Type:      "echo_response",
+				RequestID: msg.RequestID,
+				Success:   true,
+				Data:      msg.Data,
+			}
+		},
 	})
 
 	// Get room info handler
-	s.RegisterHandler("get_room_info", func(ctx context.Context, conn *Connection, msg *Message) *Message {
-		room, ok := msg.Data.(string)
-		if !ok {
-			if dataMap, ok := msg.Data.(map[string]interface{}); ok {
-				if roomStr, ok := dataMap["room"].(string); ok {
-					room = roomStr
-				}
-			}
+	s.mustRegister(HandlerSpec{
+		Name:           "get_room_info",
+		Description:    "Returns the users in a room",
+		RateLimitClass: RateLimitRead,
+		Priority:       PriorityLow,
+		Handler:        s.handleGetRoomInfo,
+	})
+
+	// Get user list handler
+	s.mustRegister(HandlerSpec{
+		Name:           "get_users",
+		Description:    "Returns connected users and connection totals",
+		RateLimitClass: RateLimitRead,
+		Priority:       PriorityLow,
+		Handler:        s.handleGetUsers,
+	})
+
+	// Send message to room handler
+	s.mustRegister(HandlerSpec{
+		Name:        "send_to_room",
+		Description: "Broadcasts a message to a room the sender has joined",
+		RequireAuth: true,
+		Schema: []FieldSpec{
+			{Name: "room", Type: "string", Required: true},
+			{Name: "message", Type: "any", Required: true},
+		},
+		RateLimitClass: RateLimitWrite,
+		Priority:       PriorityLow,
+		Handler:        s.handleSendToRoom,
+	})
+
+	// Room chat settings, changed by room owners
+	s.mustRegister(Typed(HandlerSpec{
+		Name:           "get_room_chat_settings",
+		Description:    "Returns a room's slow mode, observer mute and link settings",
+		RequireAuth:    true,
+		RateLimitClass: RateLimitRead,
+		Priority:       PriorityLow,
+	}, s.handleGetRoomChatSettings))
+
+	s.mustRegister(Typed(HandlerSpec{
+		Name:           "set_room_chat_settings",
+		Description:    "Changes a room's chat settings; only the room owner or table creator may",
+		RequireAuth:    true,
+		RateLimitClass: RateLimitWrite,
+	}, s.handleSetRoomChatSettings))
+
+	// Live metrics for ops dashboards
+	s.mustRegister(HandlerSpec{
+		Name:           "subscribe_stats",
+		Description:    "Streams periodic hub, table and wallet metrics to an admin connection",
+		Permissions:    []string{"admin.access"},
+		RateLimitClass: RateLimitStrict,
+		Handler:        s.handleSubscribeStats,
+	})
+
+	s.mustRegister(HandlerSpec{
+		Name:           "unsubscribe_stats",
+		Description:    "Stops the stats stream for this connection",
+		RequireAuth:    true,
+		RateLimitClass: RateLimitRead,
+		Handler:        s.handleUnsubscribeStats,
+	})
+
+	// Per-stake-level action for lobby screens
+	s.mustRegister(HandlerSpec{
+		Name:           "subscribe_lobby_stats",
+		Description:    "Streams per-stake-level lobby stats: tables running, average players and pot, waitlists",
+		RequireAuth:    true,
+		RateLimitClass: RateLimitRead,
+		Priority:       PriorityLow,
+		Handler:        s.handleSubscribeLobby,
+	})
+
+	s.mustRegister(HandlerSpec{
+		Name:           "unsubscribe_lobby_stats",
+		Description:    "Stops the lobby stats stream for this connection",
+		RequireAuth:    true,
+		RateLimitClass: RateLimitRead,
+		Handler:        s.handleUnsubscribeLobby,
+	})
+
+	// Read-only lobby for visitors who have not signed in
+	s.mustRegister(HandlerSpec{
+		Name:           "subscribe_public_lobby",
+		Description:    "Streams the public lobby without sign-in: open tables and tournaments registering or running",
+		RateLimitClass: RateLimitRead,
+		Priority:       PriorityLow,
+		Handler:        s.handleSubscribePublicLobby,
+	})
+
+	s.mustRegister(HandlerSpec{
+		Name:           "unsubscribe_public_lobby",
+		Description:    "Stops the public lobby stream for this connection",
+		RateLimitClass: RateLimitRead,
+		Handler:        s.handleUnsubscribePublicLobby,
+	})
+
+	// Request-response pattern handler
+	s.mustRegister(HandlerSpec{
+		Name:        "request",
+		Description: "Generic request/response dispatcher keyed by action",
+		Schema: []FieldSpec{
+			{Name: "action", Type: "string", Required: true},
+		},
+		RateLimitClass: RateLimitRead,
+		ResponseType:   "response",
+		Handler:        s.handleRequest,
+	})
+}
+
+// mustRegister registers a built-in handler; a rejected built-in spec is a
+// programming error, so it panics rather than serving without the handler
+func (s *Server) mustRegister(spec HandlerSpec) {
+	if err := s.Register(spec); err != nil {
+		panic(fmt.Sprintf("WebSocket: failed to register built-in handler %s: %v", spec.Name, err))
+	}
+}
+
+// handleSubscribeStats adds the connection to the stats topic and replies
+// with a current snapshot so the dashboard can render before the first push
+func (s *Server) handleSubscribeStats(ctx context.Context, conn *Connection, msg *Message) *Message {
+	if err := s.hub.SubscribeTopic(conn.ID, StatsTopic); err != nil {
+		return &Message{
+			Type:      "subscribe_stats_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     err.Error(),
 		}
+	}
 
-		if room == "" {
-			return &Message{
-				Type:      "get_room_info_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Room name is required",
+	return &Message{
+		Type:      "subscribe_stats_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data:      s.stats.Snapshot(),
+	}
+}
+
+// handleUnsubscribeStats removes the connection from the stats topic
+func (s *Server) handleUnsubscribeStats(ctx context.Context, conn *Connection, msg *Message) *Message {
+	s.hub.UnsubscribeTopic(conn.ID, StatsTopic)
+	return &Message{
+		Type:      "unsubscribe_stats_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+	}
+}
+
+// handleGetRoomInfo returns information about a room
+func (s *Server) handleGetRoomInfo(ctx context.Context, conn *Connection, msg *Message) *Message {
+	room, ok := msg.Data.(string)
+	if !ok {
+		if dataMap, ok := msg.Data.(map[string]interface{}); ok {
+			if roomStr, ok := dataMap["room"].(string); ok {
+				room = roomStr
 			}
 		}
+	}
 
+	if room == "" {
 		return &Message{
 			Type:      "get_room_info_response",
 			RequestID: msg.RequestID,
-			Success:   true,
-			Data: map[string]interface{}{
-				"room":      room,
-				"users":     s.GetRoomUsers(room),
-				"userCount": len(s.GetRoomUsers(room)),
-			},
+			Success:   false,
+			Error:     "Room name is required",
 		}
-	})
+	}
 
-	// Get user list handler
-	s.RegisterHandler("get_users", func(ctx context.Context, conn *Connection, msg *Message) *Message {
+	return &Message{
+		Type:      "get_room_info_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data: map[string]interface{}{
+			"room":      room,
+			"users":     s.GetRoomUsers(room),
+			"userCount": len(s.GetRoomUsers(room)),
+		},
+	}
+}
+
+// handleGetUsers returns connected users
+func (s *Server) handleGetUsers(ctx context.Context, conn *Connection, msg *Message) *Message {
+	return &Message{
+		Type:      "get_users_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data: map[string]interface{}{
+			"users":            s.GetConnectedUsers(),
+			"totalUsers":       len(s.GetConnectedUsers()),
+			"totalConnections": s.GetConnectionCount(),
+		},
+	}
+}
+
+// handleSendToRoom broadcasts a message to a room the sender is in
+func (s *Server) handleSendToRoom(ctx context.Context, conn *Connection, msg *Message) *Message {
+	var data map[string]interface{}
+	if dataBytes, err := json.Marshal(msg.Data); err == nil {
+		json.Unmarshal(dataBytes, &data)
+	}
+
+	room, ok := data["room"].(string)
+	if !ok || room == "" {
 		return &Message{
-			Type:      "get_users_response",
+			Type:      "send_to_room_response",
 			RequestID: msg.RequestID,
-			Success:   true,
-			Data: map[string]interface{}{
-				"users":            s.GetConnectedUsers(),
-				"totalUsers":       len(s.GetConnectedUsers()),
-				"totalConnections": s.GetConnectionCount(),
-			},
+			Success:   false,
+			Error:     "Room name is required",
 		}
-	})
+	}
 
-	// Send message to room handler
-	s.RegisterHandler("send_to_room", func(ctx context.Context, conn *Connection, msg *Message) *Message {
-		var data map[string]interface{}
-		if dataBytes, err := json.Marshal(msg.Data); err == nil {
-			json.Unmarshal(dataBytes, &data)
+	message, ok := data["message"]
+	if !ok {
+		return &Message{
+			Type:      "send_to_room_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Message is required",
 		}
+	}
 
-		room, ok := data["room"].(string)
-		if !ok || room == "" {
-			return &Message{
-				Type:      "send_to_room_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Room name is required",
-			}
+	// Check if user is in the room
+	if !conn.IsInRoom(room) {
+		return &Message{
+			Type:      "send_to_room_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "You are not in this room",
 		}
+	}
 
-		message, ok := data["message"]
-		if !ok {
-			return &Message{
-				Type:      "send_to_room_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Message is required",
-			}
+	// Enforce the room's chat settings before anyone sees the message
+	if err := s.chat.Allow(room, conn.UserID, message); err != nil {
+		return &Message{
+			Type:      "send_to_room_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     err.Error(),
 		}
+	}
 
-		// Check if user is in the room
-		if !conn.IsInRoom(room) {
-			return &Message{
-				Type:      "send_to_room_response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "You are not in this room",
-			}
-		}
+	// Broadcast the message to the room
+	broadcastMsg := &Message{
+		Type: "room_message",
+		Data: map[string]interface{}{
+			"room":     room,
+			"message":  message,
+			"userID":   conn.UserID,
+			"username": conn.Username,
+		},
+		Room: room,
+	}
+	s.hub.BroadcastToRoom(room, broadcastMsg)
+
+	return &Message{
+		Type:      "send_to_room_response",
+		RequestID: msg.RequestID,
+		Success:   true,
+		Data: map[string]interface{}{
+			"room":    room,
+			"message": message,
+		},
+	}
+}
 
-		// Broadcast the message to the room
-		broadcastMsg := &Message{
-			Type: "room_message",
-			Data: map[string]interface{}{
-				"room":     room,
-				"message":  message,
-				"userID":   conn.UserID,
-				"username": conn.Username,
-			},
-			Room: room,
+// handleRequest routes generic request/response actions
+func (s *Server) handleRequest(ctx context.Context, conn *Connection, msg *Message) *Message {
+	// Applications can replace this dispatcher with RegisterHandler("request", ...)
+	// Extract the action from the data
+	var data map[string]interface{}
+	if dataBytes, err := json.Marshal(msg.Data); err == nil {
+		json.Unmarshal(dataBytes, &data)
+	}
+
+	action, ok := data["action"].(string)
+	if !ok {
+		return &Message{
+			Type:      "response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Action is required",
 		}
-		s.hub.BroadcastToRoom(room, broadcastMsg)
+	}
 
+	// Route to specific action handlers
+	switch action {
+	case "ping":
 		return &Message{
-			Type:      "send_to_room_response",
+			Type:      "response",
 			RequestID: msg.RequestID,
 			Success:   true,
 			Data: map[string]interface{}{
-				"room":    room,
-				"message": message,
+				"action": "pong",
+				"time":   msg.Timestamp,
 			},
 		}
-	})
-
-	// Request-response pattern handler
-	s.RegisterHandler("request", func(ctx context.Context, conn *Connection, msg *Message) *Message {
-		// This is a generic request handler that other handlers can override
-		// Extract the action from the data
-		var data map[string]interface{}
-		if dataBytes, err := json.Marshal(msg.Data); err == nil {
-			json.Unmarshal(dataBytes, &data)
-		}
-
-		action, ok := data["action"].(string)
-		if !ok {
-			return &Message{
-				Type:      "response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Action is required",
-			}
-		}
-
-		// Route to specific action handlers
-		switch action {
-		case "ping":
-			return &Message{
-				Type:      "response",
-				RequestID: msg.RequestID,
-				Success:   true,
-				Data: map[string]interface{}{
-					"action": "pong",
-					"time":   msg.Timestamp,
-				},
-			}
-		default:
-			return &Message{
-				Type:      "response",
-				RequestID: msg.RequestID,
-				Success:   false,
-				Error:     "Unknown action: " + action,
-			}
+	default:
+		return &Message{
+			Type:      "response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     "Unknown action: " + action,
 		}
-	})
+	}
 }
 
 // HealthStatus returns server health information
 func (s *Server) HealthStatus() map[string]interface{} {
+	status := "healthy"
+	if s.shedder.Shedding() {
+		status = "overloaded"
+	}
 	return map[string]interface{}{
-		"status":            "healthy",
+		"status":            status,
 		"connected_users":   len(s.GetConnectedUsers()),
 		"total_connections": s.GetConnectionCount(),
 		"active_rooms":      len(s.GetActiveRooms()),
+		"load":              s.shedder.Status(),
 	}
 }
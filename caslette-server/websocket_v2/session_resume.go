@@ -0,0 +1,389 @@
+package websocket_v2
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Session resume limits
+const (
+	DefaultResumeWindow     = 2 * time.Minute // How long a dropped session can be resumed
+	DefaultReplayBufferSize = 128             // Outbound messages kept per session for replay
+	sessionTokenBytes       = 32
+)
+
+// ErrSessionNotFound is returned for unknown or expired session tokens
+var ErrSessionNotFound = errors.New("session not found or expired")
+
+// sessionFrame is an encoded outbound message kept for replay
+type sessionFrame struct {
+	seq   uint64
+	data  []byte
+	codec Codec // How data is encoded, in case the session resumes with another
+}
+
+// Session outlives the connection that opened it. Every message sent on the
+// connection is numbered, and the latest are kept, so a client reconnecting
+// within the resume window picks up where it left off: it gets its identity
+// and rooms back and is sent whatever it missed.
+type Session struct {
+	Token    string
+	UserID   string
+	Username string
+	Bot      *BotScope
+	Device   string
+
+	mu        sync.Mutex
+	conn      *Connection // Connection the session is running on; nil once dropped
+	lastSeq   uint64
+	frames    []sessionFrame
+	size      int
+	rooms     []string  // Rooms to re-enter, saved when the connection dropped
+	expiresAt time.Time // When a dropped session can no longer be resumed
+}
+
+// deliver numbers a message, keeps it for replay and queues it on the
+// connection. It reports false when the session no longer runs on conn, so
+// the message goes out unnumbered.
+func (s *Session) deliver(conn *Connection, msg *Message) bool {
+	s.mu.Lock()
+	if s.conn != conn {
+		s.mu.Unlock()
+		return false
+	}
+
+	msg.Seq = s.lastSeq + 1
+	data, err := conn.Codec().Marshal(msg)
+	if err != nil {
+		s.mu.Unlock()
+		conn.Logf("Error marshaling message: %v", err)
+		return true
+	}
+	if !conn.admit(msg, data) {
+		s.mu.Unlock()
+		return true
+	}
+	s.lastSeq = msg.Seq
+	s.frames = append(s.frames, sessionFrame{seq: msg.Seq, data: data, codec: conn.Codec()})
+	if len(s.frames) > s.size {
+		s.frames = append([]sessionFrame(nil), s.frames[len(s.frames)-s.size:]...)
+	}
+	conn.enqueue(msg.Type, data)
+	s.mu.Unlock()
+	return true
+}
+
+// ResumeResult describes a resumed session
+type ResumeResult struct {
+	Session  *Session
+	Rooms    []string    // Rooms the session was in
+	Previous *Connection // Connection the session was still running on, if any
+	Replayed int         // Messages sent again
+	Missed   bool        // Messages were lost beyond the replay buffer; resync state
+}
+
+// SessionStore keeps the sessions of open connections and, for the resume
+// window, of dropped ones
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	window   time.Duration
+	size     int
+	now      func() time.Time
+}
+
+// NewSessionStore creates a session store keeping size messages per session
+// for replay and dropped sessions for window
+func NewSessionStore(window time.Duration, size int) *SessionStore {
+	if window <= 0 {
+		window = DefaultResumeWindow
+	}
+	if size <= 0 {
+		size = DefaultReplayBufferSize
+	}
+	return &SessionStore{
+		sessions: make(map[string]*Session),
+		window:   window,
+		size:     size,
+		now:      time.Now,
+	}
+}
+
+// SetWindow changes how long dropped sessions can be resumed; zero turns
+// resuming off
+func (s *SessionStore) SetWindow(window time.Duration) {
+	if window < 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.window = window
+}
+
+// Window returns how long dropped sessions can be resumed
+func (s *SessionStore) Window() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.window
+}
+
+// Open starts a session for an authenticated connection, ending any session
+// it had before
+func (s *SessionStore) Open(conn *Connection) (*Session, error) {
+	tokenBytes := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, err
+	}
+	session := &Session{
+		Token:    hex.EncodeToString(tokenBytes),
+		UserID:   conn.UserID,
+		Username: conn.Username,
+		Bot:      conn.Bot,
+		Device:   conn.Device,
+		conn:     conn,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+	if previous := conn.session.Load(); previous != nil {
+		delete(s.sessions, previous.Token)
+	}
+	session.size = s.size
+	s.sessions[session.Token] = session
+	conn.session.Store(session)
+	return session, nil
+}
+
+// Close ends the connection's session so it cannot be resumed
+func (s *SessionStore) Close(conn *Connection) {
+	session := conn.session.Swap(nil)
+	if session == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.sessions, session.Token)
+	s.mu.Unlock()
+
+	session.mu.Lock()
+	session.conn = nil
+	session.mu.Unlock()
+}
+
+// Detach keeps a dropped connection's session, with the rooms it was in,
+// for the resume window
+func (s *SessionStore) Detach(conn *Connection, rooms []string) {
+	session := conn.session.Load()
+	if session == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.conn != conn {
+		// Already resumed on another connection
+		return
+	}
+	session.conn = nil
+	session.rooms = rooms
+	session.expiresAt = s.now().Add(s.window)
+	if s.window == 0 {
+		delete(s.sessions, session.Token)
+	}
+}
+
+// Resume moves a session to a new connection. The reply is queued first,
+// followed by every kept message numbered after lastSeq, before anything
+// else can be sent on the connection. An error from reply leaves the session
+// where it was.
+func (s *SessionStore) Resume(token string, lastSeq uint64, conn *Connection, reply func(*ResumeResult) (*Message, error)) (*ResumeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, exists := s.sessions[token]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.conn == nil && !s.now().Before(session.expiresAt) {
+		delete(s.sessions, token)
+		return nil, ErrSessionNotFound
+	}
+
+	result := &ResumeResult{Session: session, Rooms: session.rooms, Previous: session.conn}
+	if result.Previous != nil {
+		// The old connection has not noticed it is gone yet
+		result.Rooms = result.Previous.GetRooms()
+	}
+
+	replay := make([]sessionFrame, 0)
+	switch {
+	case lastSeq > session.lastSeq:
+		// The client holds numbers this session never sent
+		result.Missed = true
+	case lastSeq < session.lastSeq:
+		if len(session.frames) == 0 || session.frames[0].seq > lastSeq+1 {
+			result.Missed = true
+		}
+		for _, frame := range session.frames {
+			if frame.seq > lastSeq {
+				replay = append(replay, frame)
+			}
+		}
+	}
+	result.Replayed = len(replay)
+
+	// The reply goes out unnumbered, ahead of the replay
+	msg, err := reply(result)
+	if err != nil {
+		return nil, err
+	}
+	if msg != nil {
+		msg.Timestamp = time.Now().Unix()
+		msg.TraceID = conn.TraceID
+		if data, err := conn.Codec().Marshal(msg); err == nil {
+			conn.enqueue(msg.Type, data)
+		}
+	}
+	for _, frame := range replay {
+		data, err := frame.encodedFor(conn.Codec())
+		if err != nil || !conn.enqueue("replay", data) {
+			result.Missed = true
+			break
+		}
+	}
+
+	session.conn = conn
+	session.rooms = nil
+	session.expiresAt = time.Time{}
+	conn.session.Store(session)
+	return result, nil
+}
+
+// encodedFor returns the frame encoded with codec
+func (f sessionFrame) encodedFor(codec Codec) ([]byte, error) {
+	if f.codec == nil || f.codec == codec {
+		return f.data, nil
+	}
+	var msg Message
+	if err := f.codec.Unmarshal(f.data, &msg); err != nil {
+		return nil, err
+	}
+	return codec.Marshal(&msg)
+}
+
+// pruneLocked drops sessions whose resume window has passed. Callers hold s.mu.
+func (s *SessionStore) pruneLocked() {
+	now := s.now()
+	for token, session := range s.sessions {
+		session.mu.Lock()
+		expired := session.conn == nil && !now.Before(session.expiresAt)
+		session.mu.Unlock()
+		if expired {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// resumeMessage is the data of a resume request
+type resumeMessage struct {
+	SessionToken string `json:"sessionToken"`
+	LastSeq      uint64 `json:"lastSeq"`
+}
+
+// actorHandleResume resumes a dropped session on a new connection in place
+// of authenticating it, re-entering its rooms (actor method)
+func (h *ActorHub) actorHandleResume(conn *Connection, msg *Message) {
+	fail := func(errMsg string) {
+		conn.SendMessage(&Message{
+			Type:      "resume_response",
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     errMsg,
+		})
+	}
+
+	if conn.UserID != "" {
+		fail("Connection is already authenticated")
+		return
+	}
+	var req resumeMessage
+	dataBytes, err := json.Marshal(msg.Data)
+	if err == nil {
+		err = json.Unmarshal(dataBytes, &req)
+	}
+	if err != nil || req.SessionToken == "" {
+		fail("Session token is required")
+		return
+	}
+
+	banned := false
+	result, err := h.sessions.Resume(req.SessionToken, req.LastSeq, conn, func(result *ResumeResult) (*Message, error) {
+		session := result.Session
+		// Bans follow the account across connections
+		if until, isBanned := h.penalties.BannedUntil("user:" + session.UserID); isBanned {
+			banned = true
+			return nil, errors.New("Account temporarily banned until " + until.UTC().Format(time.RFC3339) + " due to repeated rate limit violations")
+		}
+		result.Rooms = h.rejoinableRooms(session.UserID, result.Rooms)
+		return &Message{
+			Type:      "resume_response",
+			RequestID: msg.RequestID,
+			Success:   true,
+			Data: map[string]interface{}{
+				"userID":       session.UserID,
+				"username":     session.Username,
+				"bot":          session.Bot != nil,
+				"sessionToken": session.Token,
+				"rooms":        result.Rooms,
+				"replayed":     result.Replayed,
+				"missed":       result.Missed,
+				"lastSeq":      session.lastSeq,
+			},
+		}, nil
+	})
+	if err != nil {
+		fail(err.Error())
+		if banned {
+			conn.disconnectAfter(banDisconnectDelay)
+		}
+		return
+	}
+	session := result.Session
+
+	conn.UserID = session.UserID
+	conn.Username = session.Username
+	conn.Bot = session.Bot
+	conn.Device = session.Device
+	h.actorAddUserConnection(conn)
+
+	// The connection the session was taken from stops receiving the user's
+	// messages at once
+	if previous := result.Previous; previous != nil && previous != conn {
+		h.actorRemoveUserConnection(previous)
+		previous.disconnectAfter(0)
+	}
+	for _, room := range result.Rooms {
+		h.actorJoinRoom(conn.ID, room, nil)
+	}
+	conn.Logf("ActorHub: User %s resumed session on connection %s (%d replayed)", session.UserID, conn.ID, result.Replayed)
+}
+
+// rejoinableRooms filters the rooms a resumed session re-enters by the same
+// rules as join_room
+func (h *ActorHub) rejoinableRooms(userID string, rooms []string) []string {
+	allowed := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		if h.chat.CanJoin(room, userID) == nil {
+			allowed = append(allowed, room)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,130 @@
+package websocket_v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResumeHub creates a hub authenticating every token as user 7 with the
+// given session store
+func newResumeHub(t *testing.T, sessions *SessionStore) *ActorHub {
+	hub := NewActorHub()
+	t.Cleanup(hub.Stop)
+	hub.sessions = sessions
+	hub.SetAuthHandler(func(token string) (*AuthResult, error) {
+		return &AuthResult{Success: true, UserID: "7", Username: "player7"}, nil
+	})
+	return hub
+}
+
+// sessionToken reads the welcome and auth replies of a new connection and
+// returns the session token it was given
+func sessionToken(t *testing.T, conn *Connection) string {
+	readReplyOfType(t, conn, "connected")
+	auth := readReplyOfType(t, conn, "auth_response")
+	require.True(t, auth.Success)
+	assert.Equal(t, uint64(1), auth.Seq, "messages are numbered from authentication on")
+	token, _ := auth.Data.(map[string]interface{})["sessionToken"].(string)
+	require.NotEmpty(t, token)
+	return token
+}
+
+func resume(hub *ActorHub, token string, lastSeq uint64) *Connection {
+	conn := &Connection{Send: make(chan []byte, 16), Rooms: make(map[string]bool)}
+	hub.Register(conn)
+	hub.ProcessMessage(conn, &Message{Type: "resume", Data: map[string]interface{}{"sessionToken": token, "lastSeq": lastSeq}})
+	return conn
+}
+
+func TestResumedSessionRejoinsRoomsAndReplaysMissedMessages(t *testing.T) {
+	hub := newResumeHub(t, NewSessionStore(time.Minute, 16))
+	first := authenticate(hub, "c1")
+	token := sessionToken(t, first)
+	hub.ProcessMessage(first, &Message{Type: "join_room", Data: map[string]interface{}{"room": "lounge"}})
+	joined := readReplyOfType(t, first, "join_room_response")
+	hub.BroadcastToUser("7", &Message{Type: "notice", Data: "one"})
+	hub.BroadcastToUser("7", &Message{Type: "notice", Data: "two"})
+	hub.Unregister(first)
+
+	second := resume(hub, token, joined.Seq)
+	readReplyOfType(t, second, "connected")
+	reply := readReply(t, second)
+	require.Equal(t, "resume_response", reply.Type)
+	require.True(t, reply.Success, reply.Error)
+	data := reply.Data.(map[string]interface{})
+	assert.Equal(t, "7", data["userID"])
+	assert.Equal(t, []interface{}{"lounge"}, data["rooms"])
+	assert.Equal(t, float64(2), data["replayed"])
+	assert.Equal(t, false, data["missed"])
+
+	for _, text := range []string{"one", "two"} {
+		replayed := readReply(t, second)
+		assert.Equal(t, "notice", replayed.Type)
+		assert.Equal(t, text, replayed.Data)
+	}
+	rejoined := readReplyOfType(t, second, "user_joined_room")
+	assert.Equal(t, joined.Seq+3, rejoined.Seq, "numbering carries on")
+	assert.True(t, second.IsInRoom("lounge"))
+	assert.Equal(t, "7", second.UserID)
+
+	hub.BroadcastToRoom("lounge", &Message{Type: "room_news", Room: "lounge"})
+	assert.Equal(t, joined.Seq+4, readReplyOfType(t, second, "room_news").Seq)
+}
+
+func TestResumeReportsMessagesLostBeyondTheBuffer(t *testing.T) {
+	hub := newResumeHub(t, NewSessionStore(time.Minute, 2))
+	first := authenticate(hub, "c1")
+	token := sessionToken(t, first)
+	for i := 0; i < 5; i++ {
+		hub.BroadcastToUser("7", &Message{Type: "notice"})
+	}
+	hub.Unregister(first)
+
+	second := resume(hub, token, 1)
+	reply := readReplyOfType(t, second, "resume_response")
+	require.True(t, reply.Success, reply.Error)
+	data := reply.Data.(map[string]interface{})
+	assert.Equal(t, true, data["missed"], "the client must resync")
+	assert.Equal(t, float64(2), data["replayed"])
+	assert.Equal(t, uint64(5), readReply(t, second).Seq)
+	assert.Equal(t, uint64(6), readReply(t, second).Seq)
+}
+
+func TestResumeRefusesExpiredAndEndedSessions(t *testing.T) {
+	now := time.Now()
+	sessions := NewSessionStore(time.Minute, 16)
+	sessions.now = func() time.Time { return now }
+	hub := newResumeHub(t, sessions)
+
+	expiring := authenticate(hub, "c1")
+	expiredToken := sessionToken(t, expiring)
+	hub.Unregister(expiring)
+	now = now.Add(time.Minute)
+	reply := readReplyOfType(t, resume(hub, expiredToken, 0), "resume_response")
+	assert.False(t, reply.Success)
+	assert.Equal(t, ErrSessionNotFound.Error(), reply.Error)
+
+	loggedOut := authenticate(hub, "c2")
+	loggedOutToken := sessionToken(t, loggedOut)
+	hub.ProcessMessage(loggedOut, &Message{Type: "logout"})
+	hub.Unregister(loggedOut)
+	assert.False(t, readReplyOfType(t, resume(hub, loggedOutToken, 0), "resume_response").Success)
+
+	assert.False(t, readReplyOfType(t, resume(hub, "not-a-token", 0), "resume_response").Success)
+}
+
+func TestResumeTakesOverAConnectionStillOpen(t *testing.T) {
+	hub := newResumeHub(t, NewSessionStore(time.Minute, 16))
+	stale := authenticate(hub, "c1")
+	token := sessionToken(t, stale)
+
+	fresh := resume(hub, token, 1)
+	require.True(t, readReplyOfType(t, fresh, "resume_response").Success)
+
+	hub.BroadcastToUser("7", &Message{Type: "notice"})
+	assert.Equal(t, uint64(2), readReplyOfType(t, fresh, "notice").Seq)
+	assert.Empty(t, stale.Send, "the stale connection no longer receives the session's messages")
+}
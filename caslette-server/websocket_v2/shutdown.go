@@ -0,0 +1,180 @@
+package websocket_v2
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ShuttingDownCode tells clients the server is going away; they should
+// reconnect, to another instance or once it is back
+const ShuttingDownCode = "server_shutting_down"
+
+// Shutdown timing
+const (
+	DefaultShutdownTimeout = 30 * time.Second // Time given to hands and clients to finish
+	shutdownPollInterval   = 20 * time.Millisecond
+	shutdownRetryAfter     = 5 * time.Second // Suggested to clients refused while shutting down
+)
+
+// ShutdownDrainer finishes what must end before clients are disconnected,
+// such as hands being played; it returns by the time ctx is done
+type ShutdownDrainer func(ctx context.Context)
+
+// SetShutdownDrainer sets what Shutdown waits for after telling clients
+func (h *ActorHub) SetShutdownDrainer(drainer ShutdownDrainer) {
+	h.drainer.Store(drainer)
+}
+
+// Shutdown tells every connection the server is shutting down, runs the
+// drainer, lets send queues flush and closes the connections cleanly
+// before stopping the hub. When ctx ends first, connections still open are
+// dropped and ctx's error is returned.
+func (h *ActorHub) Shutdown(ctx context.Context) error {
+	if !h.shuttingDown.CompareAndSwap(false, true) {
+		return fmt.Errorf("hub is already shutting down")
+	}
+	defer h.Stop()
+
+	// Every connection hears, signed in or not; only this instance is going
+	// away, so the notice is neither relayed nor kept in outboxes
+	conns := h.listConnections()
+	log.Printf("ActorHub: Shutting down %d connections", len(conns))
+	notice := shutdownNotice()
+	for _, conn := range conns {
+		conn.SendMessage(notice)
+	}
+
+	if drainer, _ := h.drainer.Load().(ShutdownDrainer); drainer != nil {
+		drainer(ctx)
+	}
+
+	conns = h.listConnections()
+	h.waitFor(ctx, func() bool {
+		if !h.broadcasts.Idle() {
+			return false
+		}
+		for _, conn := range conns {
+			if len(conn.Send) > 0 {
+				return false
+			}
+		}
+		return true
+	})
+	for _, conn := range conns {
+		conn.goAway()
+	}
+
+	if err := h.waitFor(ctx, func() bool { return h.GetConnectionCount() == 0 }); err != nil {
+		for _, conn := range h.listConnections() {
+			if conn.Conn != nil {
+				conn.Conn.Close()
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// ShuttingDown reports whether Shutdown has begun
+func (h *ActorHub) ShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
+
+// waitFor polls until done reports true or ctx ends
+func (h *ActorHub) waitFor(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// listConnections returns the registered connections
+func (h *ActorHub) listConnections() []*Connection {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:     "list_connections",
+		Response: response,
+	}
+	result := <-response
+	close(response)
+
+	conns, _ := result.([]*Connection)
+	return conns
+}
+
+// actorListConnections lists the registered connections (actor method)
+func (h *ActorHub) actorListConnections(response chan interface{}) {
+	conns := make([]*Connection, 0, len(h.connections))
+	for _, conn := range h.connections {
+		conns = append(conns, conn)
+	}
+	response <- conns
+}
+
+// shutdownNotice warns clients before their connections are closed
+func shutdownNotice() *Message {
+	return &Message{
+		Type:  "server_shutting_down",
+		Event: "shutdown",
+		Data: map[string]interface{}{
+			"code":           ShuttingDownCode,
+			"message":        "The server is shutting down; reconnect shortly",
+			"retry_after_ms": shutdownRetryAfter.Milliseconds(),
+		},
+	}
+}
+
+// goAway has the write pump send what is still queued, then close the
+// socket with 1001 Going Away
+func (c *Connection) goAway() {
+	if !c.leaving.CompareAndSwap(false, true) {
+		return
+	}
+	if c.shutdown != nil {
+		close(c.shutdown)
+	}
+}
+
+// flushForShutdown writes the frames left in the send queue without
+// waiting for more, then the close frame (write pump only)
+func (c *Connection) flushForShutdown(frameType int) {
+	for {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				return
+			}
+			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if c.compressed {
+				c.Conn.EnableWriteCompression(c.compressesFrame(len(message)))
+			}
+			if err := c.Conn.WriteMessage(frameType, message); err != nil {
+				return
+			}
+		default:
+			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ShuttingDownCode))
+			return
+		}
+	}
+}
+
+// refuseShuttingDownConnection answers an upgrade request arriving during
+// shutdown
+func refuseShuttingDownConnection(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", int(shutdownRetryAfter/time.Second)))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	fmt.Fprintf(w, `{"success":false,"code":%q,"error":"Server is shutting down, please reconnect shortly"}`, ShuttingDownCode)
+}
@@ -0,0 +1,98 @@
+package websocket_v2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialHub connects a client to the hub through a real socket and reads its
+// welcome
+func dialHub(t *testing.T, hub *ActorHub) *websocket.Conn {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := newConnection(hub, w, r, CompressionPolicy{})
+		require.NoError(t, err)
+		hub.Register(conn)
+		conn.Start()
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var welcome Message
+	require.NoError(t, client.ReadJSON(&welcome))
+	require.Equal(t, "connected", welcome.Type)
+	return client
+}
+
+func TestShutdownWarnsDrainsAndClosesConnections(t *testing.T) {
+	hub := NewActorHub()
+	client := dialHub(t, hub)
+	hub.SetShutdownDrainer(func(ctx context.Context) {
+		for _, conn := range hub.listConnections() {
+			conn.SendMessage(&Message{Type: "hand_complete"})
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- hub.Shutdown(ctx) }()
+
+	var notice Message
+	require.NoError(t, client.ReadJSON(&notice))
+	assert.Equal(t, "server_shutting_down", notice.Type)
+	assert.Equal(t, ShuttingDownCode, notice.Data.(map[string]interface{})["code"])
+
+	var drained Message
+	require.NoError(t, client.ReadJSON(&drained))
+	assert.Equal(t, "hand_complete", drained.Type, "messages sent while draining are still delivered")
+
+	_, _, err := client.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	assert.Equal(t, ShuttingDownCode, closeErr.Text)
+
+	require.NoError(t, <-shutdown)
+	assert.True(t, hub.ShuttingDown())
+	assert.Error(t, hub.Shutdown(ctx), "a hub shuts down once")
+}
+
+func TestShutdownDropsConnectionsWhenTimeRunsOut(t *testing.T) {
+	hub := NewActorHub()
+	client := dialHub(t, hub)
+	hub.SetShutdownDrainer(func(ctx context.Context) { <-ctx.Done() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, hub.Shutdown(ctx), context.DeadlineExceeded)
+
+	for {
+		if _, _, err := client.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+func TestServerRefusesConnectionsWhileShuttingDown(t *testing.T) {
+	server := NewServer(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+
+	recorder := httptest.NewRecorder()
+	server.HandleWebSocket(recorder, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "5", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), ShuttingDownCode)
+}
@@ -0,0 +1,147 @@
+package websocket_v2
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stats topic settings
+const (
+	StatsTopic           = "stats"
+	StatsUpdateType      = "stats_update"
+	DefaultStatsInterval = 5 * time.Second
+	MinStatsInterval     = time.Second
+)
+
+// HubStats is a point-in-time view of the hub
+type HubStats struct {
+	Connections        int            `json:"connections"`
+	AuthenticatedUsers int            `json:"authenticated_users"`
+	Rooms              int            `json:"rooms"`
+	RoomMembers        int            `json:"room_members"`
+	TopicSubscribers   map[string]int `json:"topic_subscribers"`
+	OpenCircuits       []string       `json:"open_circuits"`
+	RateLimits         RateLimitStats `json:"rate_limits"`
+}
+
+// StatsSource supplies one section of the stats snapshot, e.g. table or
+// wallet metrics owned by another package
+type StatsSource func() interface{}
+
+// StatsPublisher periodically pushes metrics snapshots to subscribers of the
+// stats topic so ops dashboards stay live without polling REST endpoints.
+// Snapshots are only built while someone is subscribed.
+type StatsPublisher struct {
+	hub HubInterface
+	now func() time.Time
+
+	mu      sync.Mutex
+	sources map[string]StatsSource
+	stop    chan struct{}
+}
+
+// NewStatsPublisher creates a publisher for the hub's stats topic
+func NewStatsPublisher(hub HubInterface) *StatsPublisher {
+	return &StatsPublisher{
+		hub:     hub,
+		now:     time.Now,
+		sources: make(map[string]StatsSource),
+	}
+}
+
+// AddSource adds a named section to every snapshot, replacing any source
+// already registered under that name
+func (p *StatsPublisher) AddSource(name string, source StatsSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources[name] = source
+}
+
+// Snapshot collects the hub metrics and every registered source
+func (p *StatsPublisher) Snapshot() map[string]interface{} {
+	p.mu.Lock()
+	sources := make(map[string]StatsSource, len(p.sources))
+	names := make([]string, 0, len(p.sources))
+	for name, source := range p.sources {
+		sources[name] = source
+		names = append(names, name)
+	}
+	p.mu.Unlock()
+	sort.Strings(names)
+
+	snapshot := map[string]interface{}{
+		"generated_at": p.now().UTC(),
+		"hub":          p.hub.Stats(),
+	}
+	for _, name := range names {
+		snapshot[name] = p.collect(name, sources[name])
+	}
+	return snapshot
+}
+
+// collect runs one source, reporting a panic as an error section rather
+// than taking the publisher down
+func (p *StatsPublisher) collect(name string, source StatsSource) (section interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("StatsPublisher: source %s panicked: %v", name, r)
+			section = map[string]interface{}{"error": "source unavailable"}
+		}
+	}()
+	return source()
+}
+
+// Publish sends a snapshot to the stats topic, returning how many
+// connections received it. Nothing is collected without subscribers.
+func (p *StatsPublisher) Publish() int {
+	if p.hub.Stats().TopicSubscribers[StatsTopic] == 0 {
+		return 0
+	}
+	return p.hub.PublishToTopic(StatsTopic, &Message{
+		Type:    StatsUpdateType,
+		Event:   "stats",
+		Success: true,
+		Data:    p.Snapshot(),
+	})
+}
+
+// Start publishes snapshots on an interval until Stop is called
+func (p *StatsPublisher) Start(interval time.Duration) {
+	if interval < MinStatsInterval {
+		interval = MinStatsInterval
+	}
+
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	p.stop = make(chan struct{})
+	stop := p.stop
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.Publish()
+			}
+		}
+	}()
+}
+
+// Stop halts periodic publishing
+func (p *StatsPublisher) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
@@ -0,0 +1,101 @@
+package websocket_v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerTestConnection registers a connection and discards its welcome message
+func registerTestConnection(t *testing.T, hub *ActorHub, userID string) *Connection {
+	conn := &Connection{UserID: userID, Send: make(chan []byte, 16), Rooms: make(map[string]bool)}
+	hub.Register(conn)
+	readReply(t, conn)
+	return conn
+}
+
+func TestStatsPublisherSkipsWithoutSubscribers(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	publisher := NewStatsPublisher(hub)
+
+	calls := 0
+	publisher.AddSource("tables", func() interface{} {
+		calls++
+		return map[string]interface{}{"total_tables": 1}
+	})
+
+	assert.Equal(t, 0, publisher.Publish())
+	assert.Equal(t, 0, calls)
+}
+
+func TestStatsPublisherPushesSnapshotToSubscribers(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+	publisher := NewStatsPublisher(hub)
+	publisher.AddSource("tables", func() interface{} {
+		return map[string]interface{}{"total_tables": 3}
+	})
+	publisher.AddSource("wallet", func() interface{} {
+		panic("database unavailable")
+	})
+
+	admin := registerTestConnection(t, hub, "1")
+	registerTestConnection(t, hub, "2")
+	require.NoError(t, hub.SubscribeTopic(admin.ID, StatsTopic))
+
+	assert.Equal(t, 1, publisher.Publish())
+	update := readReply(t, admin)
+	assert.Equal(t, StatsUpdateType, update.Type)
+
+	data := update.Data.(map[string]interface{})
+	hubStats := data["hub"].(map[string]interface{})
+	assert.Equal(t, float64(2), hubStats["connections"])
+	assert.Equal(t, float64(1), hubStats["topic_subscribers"].(map[string]interface{})[StatsTopic])
+	assert.Equal(t, float64(3), data["tables"].(map[string]interface{})["total_tables"])
+	// A failing source is reported without dropping the rest of the snapshot
+	assert.Equal(t, "source unavailable", data["wallet"].(map[string]interface{})["error"])
+}
+
+func TestStatsTopicSubscriptionEndsWithConnection(t *testing.T) {
+	hub := NewActorHub()
+	defer hub.Stop()
+
+	conn := registerTestConnection(t, hub, "1")
+	require.NoError(t, hub.SubscribeTopic(conn.ID, StatsTopic))
+	assert.Equal(t, 1, hub.Stats().TopicSubscribers[StatsTopic])
+
+	hub.Unregister(conn)
+	assert.Equal(t, 0, hub.Stats().TopicSubscribers[StatsTopic])
+	assert.Equal(t, 0, hub.PublishToTopic(StatsTopic, &Message{Type: StatsUpdateType}))
+
+	assert.Error(t, hub.SubscribeTopic("missing", StatsTopic))
+}
+
+func TestSubscribeStatsRequiresAdminPermission(t *testing.T) {
+	server := NewServer(nil)
+	hub := server.GetHub().(*ActorHub)
+	defer hub.Stop()
+
+	conn := registerTestConnection(t, hub, "7")
+	hub.ProcessMessage(conn, &Message{Type: "subscribe_stats", RequestID: "r1"})
+	reply := readReply(t, conn)
+	assert.False(t, reply.Success)
+	assert.Equal(t, 0, hub.Stats().TopicSubscribers[StatsTopic])
+
+	server.SetPermissionChecker(func(userID, permission string) (bool, error) {
+		return userID == "7" && permission == "admin.access", nil
+	})
+	hub.ProcessMessage(conn, &Message{Type: "subscribe_stats", RequestID: "r2"})
+	reply = readReply(t, conn)
+	require.True(t, reply.Success)
+	assert.Equal(t, "subscribe_stats_response", reply.Type)
+	assert.Contains(t, reply.Data, "hub")
+	assert.Equal(t, 1, hub.Stats().TopicSubscribers[StatsTopic])
+
+	hub.ProcessMessage(conn, &Message{Type: "unsubscribe_stats", RequestID: "r3"})
+	reply = readReply(t, conn)
+	assert.True(t, reply.Success)
+	assert.Equal(t, 0, hub.Stats().TopicSubscribers[StatsTopic])
+}
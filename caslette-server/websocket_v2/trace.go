@@ -0,0 +1,55 @@
+package websocket_v2
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// TraceIDHeader carries the request ID the REST API logs, so a connection
+// upgraded from a request shares its ID
+const TraceIDHeader = "X-Request-ID"
+
+// maxTraceIDLength bounds the client-supplied IDs written to every log line
+const maxTraceIDLength = 128
+
+type traceIDKey struct{}
+
+// traceIDFromRequest returns the ID of the upgrade request, set by the request
+// ID middleware or the client, or a new one when there is none fit for logs
+func traceIDFromRequest(r *http.Request) string {
+	id := strings.TrimSpace(r.Header.Get(TraceIDHeader))
+	if id == "" || len(id) > maxTraceIDLength {
+		return uuid.New().String()
+	}
+	for _, ch := range id {
+		if ch <= ' ' || ch > '~' {
+			return uuid.New().String()
+		}
+	}
+	return id
+}
+
+// WithTraceID returns a context carrying a connection's trace ID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID of the connection a handler is
+// serving, or "" outside one
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// Logf logs a line about the connection, prefixed with its trace ID
+func (c *Connection) Logf(format string, args ...interface{}) {
+	if c.TraceID == "" {
+		log.Printf(format, args...)
+		return
+	}
+	log.Printf("[trace %s] "+format, append([]interface{}{c.TraceID}, args...)...)
+}
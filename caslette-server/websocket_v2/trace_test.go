@@ -0,0 +1,71 @@
+package websocket_v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionCarriesUpgradeRequestID(t *testing.T) {
+	accepted := make(chan *Connection, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := NewConnection(nil, w, r)
+		require.NoError(t, err)
+		conn.ID = "c1"
+		go conn.writePump()
+		accepted <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	header := http.Header{TraceIDHeader: {"rest-req-42"}}
+	client, response, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	assert.Equal(t, "rest-req-42", response.Header.Get(TraceIDHeader), "the handshake echoes the ID")
+
+	var conn *Connection
+	select {
+	case conn = <-accepted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not accepted")
+	}
+	assert.Equal(t, "rest-req-42", conn.TraceID)
+
+	// Broadcasts share a message; each connection stamps its own copy
+	shared := &Message{Type: "table_update"}
+	conn.SendMessage(shared)
+	assert.Empty(t, shared.TraceID)
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	var received Message
+	require.NoError(t, json.Unmarshal(data, &received))
+	assert.Equal(t, "rest-req-42", received.TraceID)
+}
+
+func TestTraceIDFromRequestReplacesUnfitIDs(t *testing.T) {
+	r := httptest.NewRequest("GET", "/ws", nil)
+	generated := traceIDFromRequest(r)
+	assert.Len(t, generated, 36, "a missing ID is generated")
+	assert.NotEqual(t, generated, traceIDFromRequest(r))
+
+	for _, unfit := range []string{"has space", "line\nbreak", strings.Repeat("a", maxTraceIDLength+1)} {
+		r.Header.Set(TraceIDHeader, unfit)
+		assert.NotEqual(t, unfit, traceIDFromRequest(r))
+	}
+	r.Header.Set(TraceIDHeader, " kept-id ")
+	assert.Equal(t, "kept-id", traceIDFromRequest(r))
+
+	ctx := WithTraceID(context.Background(), "kept-id")
+	assert.Equal(t, "kept-id", TraceIDFromContext(ctx))
+	assert.Empty(t, TraceIDFromContext(context.Background()))
+}
@@ -0,0 +1,234 @@
+package websocket_v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationFailedCode is the reply code of requests refused by a handler's
+// declared schema; the reply's errors list every offending field
+const ValidationFailedCode = "validation_failed"
+
+// Field error codes
+const (
+	FieldRequired    = "required"
+	FieldInvalidType = "invalid_type"
+	FieldUnknown     = "unknown_field"
+	FieldNotAnObject = "not_an_object"
+)
+
+// payloadFieldLabel names the payload as a whole in field errors
+const payloadFieldLabel = "data"
+
+// replayFields are the envelope fields money-moving requests carry for the
+// replay guard; strict schemas accept them without declaring them
+var replayFields = map[string]bool{"nonce": true, "expires_at": true}
+
+// FieldError is one reason a request payload does not match its schema
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// TypedHandler handles a request decoded into its declared struct and
+// returns the response struct; an error fails the request with its text
+type TypedHandler[Req, Resp any] func(ctx context.Context, conn *Connection, req *Req) (*Resp, error)
+
+// Typed completes a spec from a typed handler. The request struct's fields
+// become the spec's strict schema, so unknown fields are refused along with
+// missing and mistyped ones, and the payload is unmarshalled into the struct
+// before the handler runs. Fields are named by their json tags and are
+// required when tagged binding:"required"; a description tag documents them.
+// The response struct is documented the same way and sent as the reply data.
+func Typed[Req, Resp any](spec HandlerSpec, handler TypedHandler[Req, Resp]) HandlerSpec {
+	spec.Schema = schemaOf(reflect.TypeFor[Req]())
+	spec.ResponseSchema = schemaOf(reflect.TypeFor[Resp]())
+	spec.Strict = true
+	responseType := spec.ResponseType
+	if responseType == "" {
+		responseType = spec.Name + "_response"
+	}
+	movesMoney := spec.MovesMoney
+
+	spec.Handler = func(ctx context.Context, conn *Connection, msg *Message) *Message {
+		var req Req
+		if errs := decodeRequest(msg.Data, &req, movesMoney); len(errs) > 0 {
+			return validationReply(msg, responseType, errs)
+		}
+		resp, err := handler(ctx, conn, &req)
+		if err != nil {
+			return errorReply(msg, responseType, err.Error())
+		}
+		return &Message{
+			Type:      responseType,
+			RequestID: msg.RequestID,
+			Success:   true,
+			Data:      resp,
+		}
+	}
+	return spec
+}
+
+// schemaOf describes the exported fields of a struct type as a schema
+func schemaOf(t reflect.Type) []FieldSpec {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var schema []FieldSpec
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema = append(schema, FieldSpec{
+			Name:        name,
+			Type:        schemaType(field.Type),
+			Required:    strings.Contains(field.Tag.Get("binding"), "required"),
+			Description: field.Tag.Get("description"),
+		})
+	}
+	return schema
+}
+
+// schemaType maps a Go type onto the schema's JSON types
+func schemaType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "any"
+	}
+}
+
+// decodeRequest unmarshals a payload into a request struct, refusing fields
+// the struct does not declare
+func decodeRequest(data interface{}, req interface{}, movesMoney bool) []FieldError {
+	if fields, ok := data.(map[string]interface{}); ok && movesMoney {
+		stripped := make(map[string]interface{}, len(fields))
+		for name, value := range fields {
+			if !replayFields[name] {
+				stripped[name] = value
+			}
+		}
+		data = stripped
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return []FieldError{{Field: payloadFieldLabel, Code: FieldInvalidType, Message: "payload cannot be encoded"}}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		return []FieldError{decodeError(err)}
+	}
+	return nil
+}
+
+// decodeError converts a JSON decoding error into a field error
+func decodeError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return FieldError{Field: payloadFieldLabel, Code: FieldNotAnObject, Message: "payload must be an object"}
+		}
+		return FieldError{Field: typeErr.Field, Code: FieldInvalidType, Message: typeErr.Field + " must be of type " + typeErr.Type.String()}
+	}
+	// The decoder reports unknown fields only as text
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name = strings.Trim(name, `"`)
+		return FieldError{Field: name, Code: FieldUnknown, Message: name + " is not a known field"}
+	}
+	return FieldError{Field: payloadFieldLabel, Code: FieldInvalidType, Message: err.Error()}
+}
+
+// validatePayload checks message data against the declared schema, listing
+// fields in schema order followed by any unknown fields of a strict schema
+func validatePayload(schema []FieldSpec, strict, movesMoney bool, data interface{}) []FieldError {
+	if len(schema) == 0 && !strict {
+		return nil
+	}
+
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		if data == nil {
+			dataMap = map[string]interface{}{}
+		} else {
+			return []FieldError{{Field: payloadFieldLabel, Code: FieldNotAnObject, Message: "payload must be an object"}}
+		}
+	}
+
+	var errs []FieldError
+	declared := make(map[string]bool, len(schema))
+	for _, field := range schema {
+		declared[field.Name] = true
+		value, exists := dataMap[field.Name]
+		if !exists || value == nil {
+			if field.Required {
+				errs = append(errs, FieldError{Field: field.Name, Code: FieldRequired, Message: field.Name + " is required"})
+			}
+			continue
+		}
+		if !matchesFieldType(field.Type, value) {
+			errs = append(errs, FieldError{Field: field.Name, Code: FieldInvalidType, Message: field.Name + " must be of type " + field.Type})
+		}
+	}
+
+	if strict {
+		var unknown []string
+		for name := range dataMap {
+			if !declared[name] && !(movesMoney && replayFields[name]) {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			errs = append(errs, FieldError{Field: name, Code: FieldUnknown, Message: name + " is not a known field"})
+		}
+	}
+	return errs
+}
+
+// validationReply refuses a request whose payload does not match its schema.
+// The error text names the first problem; the data lists all of them.
+func validationReply(msg *Message, responseType string, errs []FieldError) *Message {
+	reply := errorReply(msg, responseType, "Invalid request data: "+errs[0].Message)
+	reply.Data = map[string]interface{}{
+		"code":   ValidationFailedCode,
+		"errors": errs,
+	}
+	return reply
+}
@@ -0,0 +1,118 @@
+package websocket_v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tipRequest struct {
+	TableID string   `json:"table_id" binding:"required"`
+	Amount  int      `json:"amount" binding:"required" description:"Chips for the dealer"`
+	Note    string   `json:"note,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+type tipResponse struct {
+	Balance int `json:"balance"`
+}
+
+func tipSpec(handler TypedHandler[tipRequest, tipResponse]) HandlerSpec {
+	return Typed(HandlerSpec{Name: "tip_dealer"}, handler)
+}
+
+// fieldErrors returns the structured errors of a validation reply
+func fieldErrors(t *testing.T, reply *Message) []FieldError {
+	t.Helper()
+	require.False(t, reply.Success)
+	data := reply.Data.(map[string]interface{})
+	assert.Equal(t, ValidationFailedCode, data["code"])
+	return data["errors"].([]FieldError)
+}
+
+func TestTypedHandlerReceivesTheDecodedRequest(t *testing.T) {
+	var got tipRequest
+	registry := NewHandlerRegistry()
+	require.NoError(t, registry.Register(tipSpec(func(ctx context.Context, conn *Connection, req *tipRequest) (*tipResponse, error) {
+		got = *req
+		return &tipResponse{Balance: 100 - req.Amount}, nil
+	})))
+
+	reply := registry.Wrap("tip_dealer")(context.Background(), &Connection{ID: "c1"}, &Message{
+		Type:      "tip_dealer",
+		RequestID: "r1",
+		Data:      map[string]interface{}{"table_id": "t1", "amount": 5.0, "tags": []interface{}{"thanks"}},
+	})
+	require.True(t, reply.Success, reply.Error)
+	assert.Equal(t, "tip_dealer_response", reply.Type)
+	assert.Equal(t, "r1", reply.RequestID)
+	assert.Equal(t, &tipResponse{Balance: 95}, reply.Data)
+	assert.Equal(t, tipRequest{TableID: "t1", Amount: 5, Tags: []string{"thanks"}}, got)
+}
+
+func TestTypedHandlerRefusesUnknownFieldsWithStructuredErrors(t *testing.T) {
+	registry := NewHandlerRegistry()
+	require.NoError(t, registry.Register(tipSpec(func(ctx context.Context, conn *Connection, req *tipRequest) (*tipResponse, error) {
+		t.Fatal("invalid requests never reach the handler")
+		return nil, nil
+	})))
+	handler := registry.Wrap("tip_dealer")
+	conn := &Connection{ID: "c1"}
+
+	reply := handler(context.Background(), conn, &Message{Type: "tip_dealer", Data: map[string]interface{}{
+		"amount": "five", "tabel_id": "t1", "colour": "red",
+	}})
+	assert.Equal(t, "Invalid request data: table_id is required", reply.Error)
+	assert.Equal(t, []FieldError{
+		{Field: "table_id", Code: FieldRequired, Message: "table_id is required"},
+		{Field: "amount", Code: FieldInvalidType, Message: "amount must be of type number"},
+		{Field: "colour", Code: FieldUnknown, Message: "colour is not a known field"},
+		{Field: "tabel_id", Code: FieldUnknown, Message: "tabel_id is not a known field"},
+	}, fieldErrors(t, reply))
+
+	reply = handler(context.Background(), conn, &Message{Type: "tip_dealer", Data: "t1"})
+	assert.Equal(t, []FieldError{{Field: "data", Code: FieldNotAnObject, Message: "payload must be an object"}}, fieldErrors(t, reply))
+
+	// Numbers the struct cannot hold are caught while decoding
+	reply = handler(context.Background(), conn, &Message{Type: "tip_dealer", Data: map[string]interface{}{"table_id": "t1", "amount": 2.5}})
+	assert.Equal(t, []FieldError{{Field: "amount", Code: FieldInvalidType, Message: "amount must be of type int"}}, fieldErrors(t, reply))
+}
+
+func TestTypedHandlerErrorsFailTheRequest(t *testing.T) {
+	registry := NewHandlerRegistry()
+	require.NoError(t, registry.Register(tipSpec(func(ctx context.Context, conn *Connection, req *tipRequest) (*tipResponse, error) {
+		return nil, errors.New("insufficient balance")
+	})))
+
+	reply := registry.Wrap("tip_dealer")(context.Background(), &Connection{ID: "c1"}, &Message{Type: "tip_dealer", Data: map[string]interface{}{"table_id": "t1", "amount": 500.0}})
+	assert.False(t, reply.Success)
+	assert.Equal(t, "tip_dealer_response", reply.Type)
+	assert.Equal(t, "insufficient balance", reply.Error)
+}
+
+func TestTypedSpecDocumentsRequestAndResponse(t *testing.T) {
+	spec := tipSpec(func(ctx context.Context, conn *Connection, req *tipRequest) (*tipResponse, error) { return nil, nil })
+
+	assert.True(t, spec.Strict)
+	assert.Equal(t, []FieldSpec{
+		{Name: "table_id", Type: "string", Required: true},
+		{Name: "amount", Type: "number", Required: true, Description: "Chips for the dealer"},
+		{Name: "note", Type: "string"},
+		{Name: "tags", Type: "array"},
+	}, spec.Schema)
+	assert.Equal(t, []FieldSpec{{Name: "balance", Type: "number"}}, spec.ResponseSchema)
+}
+
+func TestTypedMoneyMovingSpecAcceptsReplayFields(t *testing.T) {
+	spec := Typed(HandlerSpec{Name: "tip_dealer", MovesMoney: true}, func(ctx context.Context, conn *Connection, req *tipRequest) (*tipResponse, error) {
+		return &tipResponse{}, nil
+	})
+	data := map[string]interface{}{"table_id": "t1", "amount": 5.0, "nonce": "n", "expires_at": 1.0}
+
+	assert.Empty(t, validatePayload(spec.Schema, spec.Strict, spec.MovesMoney, data))
+	reply := spec.Handler(context.Background(), &Connection{ID: "c1"}, &Message{Type: "tip_dealer", Data: data})
+	assert.True(t, reply.Success, reply.Error)
+}
@@ -8,10 +8,15 @@ type MessageHandler func(ctx context.Context, conn *Connection, msg *Message) *M
 // AuthHandler defines the signature for authentication
 type AuthHandler func(token string) (*AuthResult, error)
 
+// PresenceHandler is told when a user's first connection authenticates
+// and when their last connection goes away
+type PresenceHandler func(userID string, online bool)
+
 // AuthResult contains authentication result
 type AuthResult struct {
 	UserID   string
 	Username string
 	Success  bool
 	Error    string
+	Bot      *BotScope // Set when authenticated with a bot token
 }
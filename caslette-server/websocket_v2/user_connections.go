@@ -0,0 +1,150 @@
+package websocket_v2
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SessionPolicy decides whether a user may be connected from several
+// devices at once
+type SessionPolicy string
+
+// Session policies
+const (
+	SessionsMultiple SessionPolicy = "multiple" // Every connection of a user receives their messages
+	SessionsSingle   SessionPolicy = "single"   // A new login replaces the user's other connections
+)
+
+// SessionReplacedCode is the reason given to connections closed because the
+// user logged in elsewhere under the single session policy
+const SessionReplacedCode = "session_replaced"
+
+// validDevice is what clients may name their device on auth
+var validDevice = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,30}$`)
+
+// Validate checks the policy is known
+func (p SessionPolicy) Validate() error {
+	switch p {
+	case SessionsMultiple, SessionsSingle:
+		return nil
+	}
+	return fmt.Errorf("unknown session policy %q", p)
+}
+
+// SetSessionPolicy sets whether users keep their other connections when
+// they log in again; it applies to logins from then on
+func (h *ActorHub) SetSessionPolicy(policy SessionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	h.sessionPolicy.Store(policy)
+	return nil
+}
+
+// SessionPolicy returns the policy applied to logins
+func (h *ActorHub) SessionPolicy() SessionPolicy {
+	if policy, ok := h.sessionPolicy.Load().(SessionPolicy); ok {
+		return policy
+	}
+	return SessionsMultiple
+}
+
+// SendToDevice sends a message only to the user's connections authenticated
+// with the given device name, on every instance
+func (h *ActorHub) SendToDevice(userID, device string, msg *Message) {
+	response := make(chan interface{})
+	h.hubChannel <- HubMessage{
+		Type:     "send_to_device",
+		UserID:   userID,
+		Room:     device,
+		Message:  msg,
+		Response: response,
+	}
+	<-response // Wait for completion
+	close(response)
+	h.publish(backplaneEnvelope{Kind: backplaneDevice, UserID: userID, Device: device, Message: msg})
+}
+
+// actorSendToDevice sends to the user's local connections on a device
+// (actor method)
+func (h *ActorHub) actorSendToDevice(userID, device string, msg *Message, response chan interface{}) {
+	for _, conn := range h.users[userID] {
+		if conn.Device == device {
+			conn.SendMessage(msg)
+		}
+	}
+	if response != nil {
+		response <- nil
+	}
+}
+
+// actorAddUserConnection maps an authenticated connection to its user,
+// reporting the user online with their first connection. Under the single
+// session policy the user's other connections, here and on other instances,
+// are replaced (actor method)
+func (h *ActorHub) actorAddUserConnection(conn *Connection) {
+	conns := h.users[conn.UserID]
+	if len(conns) == 0 {
+		conns = make(map[string]*Connection)
+		h.users[conn.UserID] = conns
+		h.localPresence(conn.UserID, true)
+	}
+	conns[conn.ID] = conn
+
+	if h.SessionPolicy() == SessionsSingle {
+		h.actorReplaceSessions(conn.UserID, conn)
+		h.publish(backplaneEnvelope{Kind: backplaneSession, UserID: conn.UserID})
+	}
+}
+
+// actorRemoveUserConnection unmaps a connection from its user and reports
+// whether it was the user's last one (actor method)
+func (h *ActorHub) actorRemoveUserConnection(conn *Connection) bool {
+	conns := h.users[conn.UserID]
+	if conns[conn.ID] != conn {
+		return false
+	}
+	delete(conns, conn.ID)
+	if len(conns) > 0 {
+		return false
+	}
+	delete(h.users, conn.UserID)
+	return true
+}
+
+// actorReplaceSessions takes the user's connections other than keep off
+// their user at once, so nothing more is routed to them, tells them why and
+// closes them once the notice has flushed. Their sessions end so they cannot
+// be resumed. keep is nil when the login was on another instance, and the
+// user then goes offline here. (actor method)
+func (h *ActorHub) actorReplaceSessions(userID string, keep *Connection) {
+	conns, online := h.users[userID]
+	if !online {
+		return
+	}
+	for id, conn := range conns {
+		if conn == keep {
+			continue
+		}
+		delete(conns, id)
+		h.sessions.Close(conn)
+		conn.SendMessage(sessionReplacedNotice())
+		conn.disconnectAfter(banDisconnectDelay)
+		conn.Logf("ActorHub: Connection %s of user %s replaced by a new login", conn.ID, userID)
+	}
+	if len(conns) == 0 {
+		delete(h.users, userID)
+		h.localPresence(userID, false)
+	}
+}
+
+// sessionReplacedNotice tells a client it was logged out by a login elsewhere
+func sessionReplacedNotice() *Message {
+	return &Message{
+		Type:    "session_replaced",
+		Event:   "session_replaced",
+		Success: false,
+		Error:   "You logged in from another device",
+		Data:    map[string]interface{}{"code": SessionReplacedCode},
+	}
+}
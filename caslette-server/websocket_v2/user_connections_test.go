@@ -0,0 +1,98 @@
+package websocket_v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authenticateDevice authenticates a new connection as user 7 on a device
+func authenticateDevice(hub *ActorHub, connID, device string) *Connection {
+	conn := &Connection{ID: connID, Send: make(chan []byte, 16), Rooms: make(map[string]bool)}
+	hub.Register(conn)
+	hub.ProcessMessage(conn, &Message{Type: "auth", Data: map[string]interface{}{"token": "token", "device": device}})
+	return conn
+}
+
+func newUserHub(t *testing.T) (*ActorHub, *presenceLog) {
+	hub := NewActorHub()
+	t.Cleanup(hub.Stop)
+	hub.SetAuthHandler(func(token string) (*AuthResult, error) {
+		return &AuthResult{Success: true, UserID: "7", Username: "player7"}, nil
+	})
+	presence := &presenceLog{}
+	hub.SetPresenceHandler(presence.record)
+	return hub, presence
+}
+
+func TestUserMessagesReachEveryConnection(t *testing.T) {
+	hub, presence := newUserHub(t)
+	phone := authenticateDevice(hub, "c1", "phone")
+	laptop := authenticateDevice(hub, "c2", "laptop")
+	assert.Equal(t, "phone", readReplyOfType(t, phone, "auth_response").Data.(map[string]interface{})["device"])
+	readReplyOfType(t, laptop, "auth_response")
+
+	hub.BroadcastToUser("7", &Message{Type: "balance_update"})
+	readReplyOfType(t, phone, "balance_update")
+	readReplyOfType(t, laptop, "balance_update")
+
+	hub.SendToDevice("7", "laptop", &Message{Type: "hand_history"})
+	readReplyOfType(t, laptop, "hand_history")
+	assert.Empty(t, phone.Send, "other devices are not sent device messages")
+
+	hub.Unregister(phone)
+	hub.BroadcastToUser("7", &Message{Type: "balance_update"})
+	readReplyOfType(t, laptop, "balance_update")
+	assert.Equal(t, []string{"7:true"}, presence.waitFor(t, 1))
+
+	hub.ProcessMessage(laptop, &Message{Type: "logout"})
+	assert.Equal(t, []string{"7:true", "7:false"}, presence.waitFor(t, 2), "logging out the last connection takes the user offline")
+}
+
+func TestAuthRefusesInvalidDeviceNames(t *testing.T) {
+	hub, _ := newUserHub(t)
+	conn := authenticateDevice(hub, "c1", "<script>")
+	reply := readReplyOfType(t, conn, "auth_response")
+	assert.False(t, reply.Success)
+	assert.Equal(t, "Invalid device name", reply.Error)
+}
+
+func TestSingleSessionPolicyReplacesOtherConnections(t *testing.T) {
+	hub, presence := newUserHub(t)
+	assert.Error(t, hub.SetSessionPolicy("some"))
+	require.NoError(t, hub.SetSessionPolicy(SessionsSingle))
+
+	old := authenticate(hub, "c1")
+	token := sessionToken(t, old)
+	replacing := authenticate(hub, "c2")
+	readReplyOfType(t, replacing, "auth_response")
+
+	notice := readReplyOfType(t, old, "session_replaced")
+	assert.Equal(t, SessionReplacedCode, notice.Data.(map[string]interface{})["code"])
+	hub.BroadcastToUser("7", &Message{Type: "balance_update"})
+	readReplyOfType(t, replacing, "balance_update")
+	assert.Empty(t, old.Send, "nothing more is routed to the replaced connection")
+
+	hub.Unregister(old)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, []string{"7:true"}, presence.waitFor(t, 1), "the user stays online through the new login")
+	assert.False(t, readReplyOfType(t, resume(hub, token, 0), "resume_response").Success, "a replaced session cannot be resumed")
+}
+
+func TestSingleSessionPolicyReplacesConnectionsOnOtherInstances(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, presenceA := newClusterHub(t, bus, "7")
+	hubB, _ := newClusterHub(t, bus, "7")
+	require.NoError(t, hubA.SetSessionPolicy(SessionsSingle))
+	require.NoError(t, hubB.SetSessionPolicy(SessionsSingle))
+
+	old := authenticate(hubA, "c1")
+	readReplyOfType(t, old, "auth_response")
+	authenticate(hubB, "c2")
+
+	readReplyOfType(t, old, "session_replaced")
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, []string{"7:true"}, presenceA.waitFor(t, 1), "the user is still online on the other instance")
+}